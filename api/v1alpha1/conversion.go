@@ -0,0 +1,46 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// v1alpha1 is the conversion hub and storage version; v1beta1 converts to and from it.
+
+// Hub marks NatsAuthConfig as a conversion hub
+func (*NatsAuthConfig) Hub() {}
+
+// Hub marks NatsAccount as a conversion hub
+func (*NatsAccount) Hub() {}
+
+// Hub marks NatsUser as a conversion hub
+func (*NatsUser) Hub() {}
+
+// SetupWebhookWithManager registers the NatsAuthConfig conversion webhook with the manager
+func (r *NatsAuthConfig) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(r).
+		Complete()
+}
+
+// SetupWebhookWithManager registers the NatsAccount conversion webhook with the manager
+func (r *NatsAccount) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(r).
+		Complete()
+}
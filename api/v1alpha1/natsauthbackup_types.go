@@ -0,0 +1,118 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// NatsAuthBackupSpec defines the desired state of NatsAuthBackup
+type NatsAuthBackupSpec struct {
+	// AuthConfigRef references the NatsAuthConfig whose hierarchy is backed up
+	// +kubebuilder:validation:Required
+	AuthConfigRef NatsAuthConfigRef `json:"authConfigRef"`
+
+	// Recipients are the curve (xkey) public keys the archive is sealed to. Any of their seeds
+	// can open it; create one with "natsauthctl backup keygen".
+	// +kubebuilder:validation:MinItems=1
+	Recipients []string `json:"recipients"`
+
+	// SecretName is the Secret in the namespace of the backup the sealed archive is written to
+	// (defaults to <name>-backup)
+	// +optional
+	SecretName string `json:"secretName,omitempty"`
+
+	// Interval is how often the hierarchy is checked for changes; a new archive is only written
+	// when something changed. Set to "0s" to back up on spec changes only.
+	// +kubebuilder:default="1h"
+	Interval *metav1.Duration `json:"interval,omitempty"`
+
+	// Paused stops writing archives, e.g. during a migration. The Paused condition reports it.
+	// +optional
+	Paused bool `json:"paused,omitempty"`
+}
+
+// NatsAuthBackupStatus defines the observed state of NatsAuthBackup
+type NatsAuthBackupStatus struct {
+	// SecretRef references the Secret holding the sealed archive
+	SecretRef SecretRef `json:"secretRef,omitempty"`
+
+	// ArchiveHash is the hash of the content of the last archive
+	ArchiveHash string `json:"archiveHash,omitempty"`
+
+	// LastBackupTime is when the last archive was written
+	LastBackupTime *metav1.Time `json:"lastBackupTime,omitempty"`
+
+	// Accounts is the number of NatsAccounts in the last archive
+	Accounts int `json:"accounts,omitempty"`
+
+	// Users is the number of NatsUsers in the last archive
+	Users int `json:"users,omitempty"`
+
+	// Secrets is the number of Secrets in the last archive
+	Secrets int `json:"secrets,omitempty"`
+
+	// Phase summarizes the Ready condition (Pending, Ready or Error)
+	Phase Phase `json:"phase,omitempty"`
+
+	// Reason is the machine-readable reason of the Ready condition
+	Reason ReasonCode `json:"reason,omitempty"`
+
+	// Message is the human-readable message of the Ready condition
+	Message string `json:"message,omitempty"`
+
+	// Conditions represent the latest available observations of the object's state
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// ObservedGeneration reflects the generation of the most recently observed NatsAuthBackup
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// LastReconciled is the timestamp of the last reconciliation
+	LastReconciled *metav1.Time `json:"lastReconciled,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Namespaced
+// +kubebuilder:printcolumn:name="AuthConfig",type=string,JSONPath=`.spec.authConfigRef.name`
+// +kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
+// +kubebuilder:printcolumn:name="Last Backup",type=date,JSONPath=`.status.lastBackupTime`
+// +kubebuilder:printcolumn:name="Secret",type=string,JSONPath=`.status.secretRef.name`,priority=1
+// +kubebuilder:printcolumn:name="Message",type=string,JSONPath=`.status.message`,priority=1
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// NatsAuthBackup is the Schema for the natsauthbackups API
+type NatsAuthBackup struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   NatsAuthBackupSpec   `json:"spec,omitempty"`
+	Status NatsAuthBackupStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// NatsAuthBackupList contains a list of NatsAuthBackup
+type NatsAuthBackupList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []NatsAuthBackup `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&NatsAuthBackup{}, &NatsAuthBackupList{})
+}
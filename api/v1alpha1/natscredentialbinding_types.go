@@ -0,0 +1,137 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// WorkloadKind defines the kind of workload a credential binding targets
+// +kubebuilder:validation:Enum=Deployment;StatefulSet
+type WorkloadKind string
+
+const (
+	WorkloadKindDeployment  WorkloadKind = "Deployment"
+	WorkloadKindStatefulSet WorkloadKind = "StatefulSet"
+)
+
+// NatsUserRef references a NatsUser
+type NatsUserRef struct {
+	// Name of the NatsUser
+	// +kubebuilder:validation:Required
+	Name string `json:"name"`
+
+	// Namespace of the NatsUser (defaults to same namespace)
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// WorkloadRef references a workload in the same namespace as the binding
+type WorkloadRef struct {
+	// Kind of the workload (Deployment or StatefulSet)
+	// +kubebuilder:validation:Required
+	Kind WorkloadKind `json:"kind"`
+
+	// Name of the workload
+	// +kubebuilder:validation:Required
+	Name string `json:"name"`
+}
+
+// NatsCredentialBindingSpec defines the desired state of NatsCredentialBinding
+type NatsCredentialBindingSpec struct {
+	// UserRef references the NatsUser whose credentials the workload consumes
+	// +kubebuilder:validation:Required
+	UserRef NatsUserRef `json:"userRef"`
+
+	// WorkloadRef references the workload consuming the credentials
+	// +kubebuilder:validation:Required
+	WorkloadRef WorkloadRef `json:"workloadRef"`
+
+	// PauseRollout pauses the Deployment rollout until the credentials are verified.
+	// Has no effect on StatefulSets.
+	// +kubebuilder:default=true
+	PauseRollout bool `json:"pauseRollout,omitempty"`
+
+	// RestartOnChange stamps a hash of the credentials on the pod template
+	// so that the workload rolls out whenever the credentials change
+	// +kubebuilder:default=true
+	RestartOnChange bool `json:"restartOnChange,omitempty"`
+
+	// ResyncInterval overrides the operator-wide periodic resync interval for this resource.
+	// Set to "0s" to disable periodic resync and rely on watches only.
+	ResyncInterval *metav1.Duration `json:"resyncInterval,omitempty"`
+
+	// Paused stops checking the credentials and patching the workload. The Paused condition
+	// reports it.
+	// +optional
+	Paused bool `json:"paused,omitempty"`
+}
+
+// NatsCredentialBindingStatus defines the observed state of NatsCredentialBinding
+type NatsCredentialBindingStatus struct {
+	// SecretRef references the verified credentials Secret
+	SecretRef SecretRef `json:"secretRef,omitempty"`
+
+	// CredentialsHash is the hash of the verified credentials stamped on the workload
+	CredentialsHash string `json:"credentialsHash,omitempty"`
+
+	// Phase summarizes the Ready condition (Pending, Ready or Error)
+	Phase Phase `json:"phase,omitempty"`
+
+	// Message is the human-readable message of the Ready condition
+	Message string `json:"message,omitempty"`
+
+	// Conditions represent the latest available observations of the object's state
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// ObservedGeneration reflects the generation of the most recently observed NatsCredentialBinding
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// LastReconciled is the timestamp of the last reconciliation
+	LastReconciled *metav1.Time `json:"lastReconciled,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Namespaced
+// +kubebuilder:printcolumn:name="User",type=string,JSONPath=`.spec.userRef.name`
+// +kubebuilder:printcolumn:name="Workload",type=string,JSONPath=`.spec.workloadRef.name`
+// +kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
+// +kubebuilder:printcolumn:name="Secret",type=string,JSONPath=`.status.secretRef.name`,priority=1
+// +kubebuilder:printcolumn:name="Message",type=string,JSONPath=`.status.message`,priority=1
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// NatsCredentialBinding is the Schema for the natscredentialbindings API
+type NatsCredentialBinding struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   NatsCredentialBindingSpec   `json:"spec,omitempty"`
+	Status NatsCredentialBindingStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// NatsCredentialBindingList contains a list of NatsCredentialBinding
+type NatsCredentialBindingList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []NatsCredentialBinding `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&NatsCredentialBinding{}, &NatsCredentialBindingList{})
+}
@@ -0,0 +1,191 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// IdentitySource is where the identities and their group memberships are read from. Exactly one
+// source must be set. Both serve a JSON directory:
+// {"identities": [{"subject": "...", "name": "...", "groups": ["..."]}]}
+type IdentitySource struct {
+	// ConfigMap holds the directory, e.g. written by a job syncing an LDAP or OIDC group
+	ConfigMap *IdentityConfigMapSource `json:"configMap,omitempty"`
+
+	// HTTP serves the directory, e.g. a bridge in front of an identity provider
+	HTTP *IdentityHTTPSource `json:"http,omitempty"`
+}
+
+// IdentityConfigMapSource references a ConfigMap in the namespace of the binding
+type IdentityConfigMapSource struct {
+	// Name of the ConfigMap
+	// +kubebuilder:validation:Required
+	Name string `json:"name"`
+
+	// Key holding the directory
+	// +kubebuilder:default="identities.json"
+	Key string `json:"key,omitempty"`
+}
+
+// IdentityHTTPSource is an endpoint the directory is fetched from with GET
+type IdentityHTTPSource struct {
+	// URL of the directory
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Pattern=`^https?://.*`
+	URL string `json:"url"`
+
+	// CABundle is a PEM encoded CA bundle used to verify an https endpoint (defaults to the system roots)
+	CABundle string `json:"caBundle,omitempty"`
+
+	// TimeoutSeconds bounds one request
+	// +kubebuilder:default=10
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=60
+	TimeoutSeconds int32 `json:"timeoutSeconds,omitempty"`
+}
+
+// IdentityUserTemplate describes the NatsUser provisioned for each bound identity. Users are
+// issued JWTs; every subject in permissions is a Go template that sees the identity as
+// {{ .Name }} and {{ .Subject }}, each reduced to a single subject token, e.g. "users.{{ .Name }}.>".
+type IdentityUserTemplate struct {
+	// AccountRef references the NatsAccount the users belong to
+	// +kubebuilder:validation:Required
+	AccountRef NatsAccountRef `json:"accountRef"`
+
+	// AllowedConnectionTypes restricts the connection types of the users
+	AllowedConnectionTypes []ConnectionType `json:"allowedConnectionTypes,omitempty"`
+
+	// Permissions are templates of the publish and subscribe permissions
+	Permissions *Permissions `json:"permissions,omitempty"`
+
+	// Limits of the users
+	Limits *UserLimits `json:"limits,omitempty"`
+
+	// SigningKeyRole selects the account signing key the user JWTs are signed with
+	SigningKeyRole string `json:"signingKeyRole,omitempty"`
+
+	// Expiry is the lifetime of the user JWTs
+	Expiry *metav1.Duration `json:"expiry,omitempty"`
+
+	// Output selects the layout of the credentials Secrets
+	Output *CredentialsOutput `json:"output,omitempty"`
+
+	// RevokeOnDelete revokes the user JWT when an identity leaves the binding
+	RevokeOnDelete bool `json:"revokeOnDelete,omitempty"`
+}
+
+// NatsIdentityBindingSpec defines the desired state of NatsIdentityBinding
+type NatsIdentityBindingSpec struct {
+	// AuthConfigRef references the NatsAuthConfig the users are issued under
+	// +kubebuilder:validation:Required
+	AuthConfigRef NatsAuthConfigRef `json:"authConfigRef"`
+
+	// Source of the identities
+	// +kubebuilder:validation:Required
+	Source IdentitySource `json:"source"`
+
+	// Subjects binds identities by subject, e.g. OIDC sub claims or LDAP DNs
+	Subjects []string `json:"subjects,omitempty"`
+
+	// Groups binds the members of the groups
+	Groups []string `json:"groups,omitempty"`
+
+	// Template describes the NatsUser provisioned for each bound identity
+	// +kubebuilder:validation:Required
+	Template IdentityUserTemplate `json:"template"`
+
+	// Interval is how often the source is read for membership changes
+	// +kubebuilder:default="5m"
+	Interval *metav1.Duration `json:"interval,omitempty"`
+
+	// Paused stops provisioning, leaving the NatsUsers of the identities as they are. The Paused
+	// condition reports it.
+	// +optional
+	Paused bool `json:"paused,omitempty"`
+}
+
+// BoundIdentity is an identity a NatsUser was provisioned for
+type BoundIdentity struct {
+	// Subject of the identity
+	Subject string `json:"subject"`
+
+	// User is the name of the provisioned NatsUser
+	User string `json:"user"`
+}
+
+// NatsIdentityBindingStatus defines the observed state of NatsIdentityBinding
+type NatsIdentityBindingStatus struct {
+	// Identities lists the bound identities and their NatsUsers, ordered by subject
+	Identities []BoundIdentity `json:"identities,omitempty"`
+
+	// UserCount is the number of provisioned NatsUsers
+	UserCount int32 `json:"userCount,omitempty"`
+
+	// LastSyncTime is when the source was last read
+	LastSyncTime *metav1.Time `json:"lastSyncTime,omitempty"`
+
+	// Phase summarizes the Ready condition (Pending, Ready or Error)
+	Phase Phase `json:"phase,omitempty"`
+
+	// Reason is the machine-readable reason of the Ready condition
+	Reason ReasonCode `json:"reason,omitempty"`
+
+	// Message is the human-readable message of the Ready condition
+	Message string `json:"message,omitempty"`
+
+	// Conditions represent the latest available observations of the object's state
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// ObservedGeneration reflects the generation of the most recently observed NatsIdentityBinding
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// LastReconciled is the timestamp of the last reconciliation
+	LastReconciled *metav1.Time `json:"lastReconciled,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Namespaced
+// +kubebuilder:printcolumn:name="Account",type=string,JSONPath=`.spec.template.accountRef.name`
+// +kubebuilder:printcolumn:name="Users",type=integer,JSONPath=`.status.userCount`
+// +kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
+// +kubebuilder:printcolumn:name="Last Sync",type=date,JSONPath=`.status.lastSyncTime`
+// +kubebuilder:printcolumn:name="Message",type=string,JSONPath=`.status.message`,priority=1
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// NatsIdentityBinding is the Schema for the natsidentitybindings API
+type NatsIdentityBinding struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   NatsIdentityBindingSpec   `json:"spec,omitempty"`
+	Status NatsIdentityBindingStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// NatsIdentityBindingList contains a list of NatsIdentityBinding
+type NatsIdentityBindingList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []NatsIdentityBinding `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&NatsIdentityBinding{}, &NatsIdentityBindingList{})
+}
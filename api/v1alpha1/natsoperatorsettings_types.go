@@ -0,0 +1,119 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// UserDefaults apply to NatsUsers that do not set the fields themselves
+type UserDefaults struct {
+	// Expiry is the lifetime of issued user JWTs (JWT mode); unset issues JWTs that do not expire
+	Expiry *metav1.Duration `json:"expiry,omitempty"`
+
+	// ClockSkewTolerance widens the validity of issued user JWTs on both sides (JWT mode), for
+	// NATS servers whose clocks drift from the operator's; at most 1h
+	ClockSkewTolerance *metav1.Duration `json:"clockSkewTolerance,omitempty"`
+
+	// Limits caps the subscriptions, data and payload size of users without spec.limits (JWT mode)
+	Limits *UserLimits `json:"limits,omitempty"`
+
+	// UsernameTemplate is a Go template for the usernames of token users without spec.username
+	// (default "{{ .Name }}-{{ .Hash }}"). The template sees the NatsUser as {{ .Name }} and
+	// {{ .Namespace }} and a DNS-safe hash of its UID as {{ .Hash }}; the result must be a DNS-1123
+	// subdomain. Users keep the username they hold.
+	UsernameTemplate string `json:"usernameTemplate,omitempty"`
+
+	// CredentialsSecretType is the type of the credentials Secrets of users without
+	// spec.output.secretType (default Opaque)
+	// +kubebuilder:validation:MaxLength=253
+	// +kubebuilder:validation:Pattern=`^(Opaque|[a-z0-9]([-a-z0-9.]*[a-z0-9])?/[A-Za-z0-9]([-A-Za-z0-9_.]*[A-Za-z0-9])?)$`
+	CredentialsSecretType string `json:"credentialsSecretType,omitempty"`
+}
+
+// AccountDefaults apply to NatsAccounts that do not set the fields themselves
+type AccountDefaults struct {
+	// Limits apply to accounts without spec.limits (JWT mode)
+	Limits *AccountLimits `json:"limits,omitempty"`
+}
+
+// SecretNameTemplates are Go templates for the names of generated Secrets.
+// The templates see the resource as {{ .Name }} and {{ .Namespace }}. Resources that already
+// have a Secret keep its name.
+type SecretNameTemplates struct {
+	// UserCredentials names the NatsUser credentials Secret (default "{{ .Name }}-user-creds")
+	UserCredentials string `json:"userCredentials,omitempty"`
+
+	// AccountJWT names the NatsAccount JWT Secret (default "{{ .Name }}-account-jwt")
+	AccountJWT string `json:"accountJWT,omitempty"`
+}
+
+// PropagationRules select the labels and annotations copied from a resource to its generated Secrets.
+// Entries are keys; an entry ending in "*" matches all keys with that prefix.
+type PropagationRules struct {
+	// Labels to copy, e.g. app.kubernetes.io/part-of or cost-center
+	Labels []string `json:"labels,omitempty"`
+
+	// Annotations to copy
+	Annotations []string `json:"annotations,omitempty"`
+}
+
+// NatsOperatorSettingsSpec defines operator-wide defaults consumed by all reconcilers
+type NatsOperatorSettingsSpec struct {
+	// ResyncInterval is the periodic resync interval for resources without their own,
+	// overriding the --resync-interval flag. Set to "0s" to disable periodic resync.
+	ResyncInterval *metav1.Duration `json:"resyncInterval,omitempty"`
+
+	// UserDefaults apply to NatsUsers
+	UserDefaults *UserDefaults `json:"userDefaults,omitempty"`
+
+	// AccountDefaults apply to NatsAccounts
+	AccountDefaults *AccountDefaults `json:"accountDefaults,omitempty"`
+
+	// SecretNames are templates for the names of generated Secrets
+	SecretNames *SecretNameTemplates `json:"secretNames,omitempty"`
+
+	// Propagation selects the labels and annotations copied to generated Secrets
+	Propagation *PropagationRules `json:"propagation,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Cluster,shortName=natssettings
+// +kubebuilder:printcolumn:name="Resync",type=string,JSONPath=`.spec.resyncInterval`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// NatsOperatorSettings holds cluster-wide defaults for the operator. The operator reads
+// the object named by its --settings-name flag ("default" unless set).
+type NatsOperatorSettings struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec NatsOperatorSettingsSpec `json:"spec,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// NatsOperatorSettingsList contains a list of NatsOperatorSettings
+type NatsOperatorSettingsList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []NatsOperatorSettings `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&NatsOperatorSettings{}, &NatsOperatorSettingsList{})
+}
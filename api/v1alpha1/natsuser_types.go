@@ -17,6 +17,7 @@ limitations under the License.
 package v1alpha1
 
 import (
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -30,6 +31,45 @@ const (
 	UserAuthTypeInherit UserAuthType = "inherit"
 )
 
+// UserPurpose defines what the user credentials are used for
+// +kubebuilder:validation:Enum=client;leafnode;jetstream-controller
+type UserPurpose string
+
+const (
+	UserPurposeClient   UserPurpose = "client"
+	UserPurposeLeafNode UserPurpose = "leafnode"
+	// UserPurposeJetStreamController issues credentials for a JetStream controller such as NACK
+	UserPurposeJetStreamController UserPurpose = "jetstream-controller"
+)
+
+// ConnectionType is a client connection type a user JWT can be restricted to
+// +kubebuilder:validation:Enum=STANDARD;WEBSOCKET;MQTT
+type ConnectionType string
+
+const (
+	ConnectionTypeStandard  ConnectionType = "STANDARD"
+	ConnectionTypeWebsocket ConnectionType = "WEBSOCKET"
+	ConnectionTypeMQTT      ConnectionType = "MQTT"
+)
+
+// LeafNodeRemote describes the upstream a leafnode user connects to
+type LeafNodeRemote struct {
+	// URLs of the upstream leafnode listeners (e.g. nats-leaf://hub:7422)
+	// +kubebuilder:validation:MinItems=1
+	URLs []string `json:"urls"`
+
+	// LocalAccount binds the remote to an account on the edge server (optional)
+	LocalAccount string `json:"localAccount,omitempty"`
+
+	// CredentialsPath is where the edge server mounts user.creds from the credentials Secret
+	// +kubebuilder:default="/etc/nats/leafnode/user.creds"
+	CredentialsPath string `json:"credentialsPath,omitempty"`
+
+	// ConfigMapName is the ConfigMap receiving the rendered leafnodes block
+	// (key leafnodes.conf, defaults to <name>-leafnode in the credentials Secret namespace)
+	ConfigMapName string `json:"configMapName,omitempty"`
+}
+
 // NatsAccountRef references a NatsAccount
 type NatsAccountRef struct {
 	// Name of the NatsAccount
@@ -46,7 +86,53 @@ type PasswordSource struct {
 	Generate bool `json:"generate,omitempty"`
 
 	// SecretRef references an existing Secret containing the password
-	SecretRef *SecretRef `json:"secretRef,omitempty"`
+	SecretRef *PasswordSecretRef `json:"secretRef,omitempty"`
+
+	// Policy overrides the operator-wide password policy for this password
+	Policy *PasswordPolicy `json:"policy,omitempty"`
+}
+
+// PasswordSecretRef references the key of a Secret holding a password
+type PasswordSecretRef struct {
+	// Name of the Secret
+	Name string `json:"name,omitempty"`
+
+	// Namespace of the Secret (defaults to the namespace of the referencing resource)
+	Namespace string `json:"namespace,omitempty"`
+
+	// Key within the Secret
+	// +kubebuilder:default="password"
+	// +optional
+	Key string `json:"key,omitempty"`
+}
+
+// PasswordPolicy controls generated passwords and the minimum strength of supplied ones.
+// Unset fields fall back to the operator defaults; changing the policy regenerates a
+// generated password that no longer satisfies it.
+type PasswordPolicy struct {
+	// Length is the number of characters in a generated password
+	// +kubebuilder:validation:Minimum=12
+	// +kubebuilder:validation:Maximum=256
+	// +optional
+	Length int `json:"length,omitempty"`
+
+	// Charset is the character set generated passwords are drawn from
+	// +kubebuilder:validation:Enum=alphanumeric;base64url;ascii
+	// +optional
+	Charset string `json:"charset,omitempty"`
+
+	// RequireSymbols guarantees at least one symbol in a generated password
+	// +optional
+	RequireSymbols bool `json:"requireSymbols,omitempty"`
+
+	// ExcludeAmbiguous drops easily confused characters (0, O, 1, l, I) from generated passwords
+	// +optional
+	ExcludeAmbiguous bool `json:"excludeAmbiguous,omitempty"`
+
+	// MinEntropyBits is the minimum estimated entropy of a password read from secretRef
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MinEntropyBits int `json:"minEntropyBits,omitempty"`
 }
 
 // Permissions defines publish/subscribe permissions
@@ -64,6 +150,34 @@ type Permissions struct {
 	SubscribeDeny []string `json:"subscribeDeny,omitempty"`
 }
 
+// UserLimits defines limits for a NATS user
+type UserLimits struct {
+	// Subs is the maximum number of subscriptions (-1 for unlimited)
+	// +kubebuilder:default=-1
+	Subs int64 `json:"subs,omitempty"`
+
+	// Data is the maximum number of bytes the user may send (-1 for unlimited)
+	// +kubebuilder:default=-1
+	Data int64 `json:"data,omitempty"`
+
+	// DataSize is the maximum data size as a quantity such as 10Gi; overrides data
+	DataSize *resource.Quantity `json:"dataSize,omitempty"`
+
+	// Payload is the maximum message payload size in bytes (-1 for unlimited)
+	// +kubebuilder:default=-1
+	Payload int64 `json:"payload,omitempty"`
+
+	// PayloadSize is the maximum message payload size as a quantity such as 1Mi; overrides payload
+	PayloadSize *resource.Quantity `json:"payloadSize,omitempty"`
+
+	// Conn is the maximum number of connections of the user (-1 for unlimited). NATS has no
+	// per-user connection limit: it is enforced as the connection limit of the NatsAccount when
+	// the user is the only user of that account (JWT mode). Otherwise the ConnectionLimit
+	// condition reports it as not enforced.
+	// +kubebuilder:default=-1
+	Conn int64 `json:"conn,omitempty"`
+}
+
 // NatsUserSpec defines the desired state of NatsUser
 type NatsUserSpec struct {
 	// AuthConfigRef references the NatsAuthConfig
@@ -74,7 +188,28 @@ type NatsUserSpec struct {
 	// +kubebuilder:default="inherit"
 	AuthType UserAuthType `json:"authType,omitempty"`
 
-	// AccountRef references the NatsAccount (required for JWT mode)
+	// Purpose of the credentials. Leafnode users may only connect as leafnodes
+	// and get a leafnodes remote config block rendered for the edge server (JWT mode).
+	// JetStream controller users default to the JetStream API permissions and always get the
+	// creds file under the nats.creds key, the layout NACK expects (JWT mode).
+	// +kubebuilder:default="client"
+	Purpose UserPurpose `json:"purpose,omitempty"`
+
+	// LeafNode describes the upstream remote (required when purpose is leafnode)
+	LeafNode *LeafNodeRemote `json:"leafNode,omitempty"`
+
+	// AllowedConnectionTypes restricts the connection types the user can connect with
+	// (e.g. WEBSOCKET and MQTT for browser and device clients). All types are allowed when empty.
+	// Set on the user JWT, or rendered as allowed_connection_types for token users.
+	AllowedConnectionTypes []ConnectionType `json:"allowedConnectionTypes,omitempty"`
+
+	// BearerToken issues a bearer JWT that connects without signing the server nonce,
+	// so websocket clients can authenticate with the JWT alone (e.g. via jwt_cookie).
+	// The seed is still written to the credentials Secret.
+	BearerToken bool `json:"bearerToken,omitempty"`
+
+	// AccountRef references the NatsAccount (JWT mode). Defaults to the defaultAccountRef of the
+	// NatsAuthConfig; one of the two is required for JWT mode.
 	AccountRef *NatsAccountRef `json:"accountRef,omitempty"`
 
 	// Username for token-based auth
@@ -86,8 +221,139 @@ type NatsUserSpec struct {
 	// Permissions defines publish/subscribe permissions
 	Permissions *Permissions `json:"permissions,omitempty"`
 
+	// Limits caps the subscriptions, data and payload size of the user (JWT mode)
+	Limits *UserLimits `json:"limits,omitempty"`
+
+	// SigningKeyRole selects the account signing key that signs the user JWT instead of the
+	// account key (JWT mode). Users of a scoped key get the permissions of its scope and must not
+	// set permissions, limits, allowedConnectionTypes or bearerToken.
+	// +kubebuilder:validation:MaxLength=63
+	SigningKeyRole string `json:"signingKeyRole,omitempty"`
+
+	// Expiry is the lifetime of the issued user JWT (JWT mode). The JWT is re-issued when a third
+	// of the lifetime remains. Defaults to the userDefaults of the NatsOperatorSettings.
+	Expiry *metav1.Duration `json:"expiry,omitempty"`
+
+	// NotBefore is when the user JWT becomes valid (JWT mode), so credentials can be provisioned
+	// ahead of their activation. A JWT with an expiry is valid for the expiry from NotBefore.
+	NotBefore *metav1.Time `json:"notBefore,omitempty"`
+
+	// ClockSkewTolerance widens the validity of the user JWT on both sides (JWT mode): NotBefore is
+	// moved earlier and the expiry later by it, so NATS servers whose clocks drift from the
+	// operator's accept freshly issued credentials. Defaults to the userDefaults of the
+	// NatsOperatorSettings; at most 1h.
+	ClockSkewTolerance *metav1.Duration `json:"clockSkewTolerance,omitempty"`
+
+	// TokenExchange lets pods running as the listed ServiceAccounts exchange their projected
+	// ServiceAccount token for short-lived credentials with the claims of this user (JWT mode).
+	// Requires the operator's token exchange endpoint (--token-exchange-bind-address).
+	TokenExchange *UserTokenExchange `json:"tokenExchange,omitempty"`
+
 	// ExistingSeedSecret references an existing user seed (optional, JWT mode)
-	ExistingSeedSecret *SecretRef `json:"existingSeedSecret,omitempty"`
+	ExistingSeedSecret *SeedSecretRef `json:"existingSeedSecret,omitempty"`
+
+	// ExistingJWTSecret references a Secret holding a user JWT issued outside the operator
+	// (key user.jwt, JWT mode). The seed is read from the same Secret (user.seed or seed.nk)
+	// or from existingSeedSecret. The JWT must be signed by the account or one of its signing keys.
+	// Permissions are taken from the JWT; spec.permissions is not applied.
+	ExistingJWTSecret *SecretRef `json:"existingJWTSecret,omitempty"`
+
+	// Output defines the layout of the credentials Secret
+	Output *CredentialsOutput `json:"output,omitempty"`
+
+	// SecretName is the name of the credentials Secret (defaults to the name template of the
+	// NatsOperatorSettings, <name>-user-creds). An existing Secret of that name that does not belong
+	// to this NatsUser is left untouched and reported with reason SecretNameCollision.
+	// +kubebuilder:validation:MaxLength=253
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9.]*[a-z0-9])?$`
+	// +optional
+	SecretName string `json:"secretName,omitempty"`
+
+	// SecretNamespace is the namespace the credentials Secret is written to (defaults to the NatsUser namespace).
+	// Secrets in another namespace carry tracking labels instead of an owner reference and are
+	// removed by the operator when the NatsUser is deleted.
+	SecretNamespace string `json:"secretNamespace,omitempty"`
+
+	// RevokeOnDelete adds the user's public key to the parent account's
+	// revocation list when the NatsUser is deleted (JWT mode)
+	RevokeOnDelete bool `json:"revokeOnDelete,omitempty"`
+
+	// PropagateLabels selects labels copied from this resource to the NatsUser credentials Secret,
+	// e.g. app.kubernetes.io/part-of. An entry ending in "*" matches all keys with that prefix.
+	// Adds to the propagation rules of the NatsOperatorSettings.
+	PropagateLabels []string `json:"propagateLabels,omitempty"`
+
+	// PropagateAnnotations selects annotations copied to the NatsUser credentials Secret, like propagateLabels
+	PropagateAnnotations []string `json:"propagateAnnotations,omitempty"`
+
+	// ResyncInterval overrides the operator-wide periodic resync interval for this resource.
+	// Set to "0s" to disable periodic resync and rely on watches only.
+	ResyncInterval *metav1.Duration `json:"resyncInterval,omitempty"`
+
+	// Paused freezes the credentials of the user, which are not issued, rotated or rewritten
+	// until it is unset. The Paused condition reports it.
+	// +optional
+	Paused bool `json:"paused,omitempty"`
+}
+
+// UserTokenExchange defines who may exchange a ServiceAccount token for credentials of a user
+type UserTokenExchange struct {
+	// ServiceAccounts are the names of the ServiceAccounts, in the NatsUser namespace, whose tokens
+	// are exchanged for credentials of this user
+	// +kubebuilder:validation:MinItems=1
+	ServiceAccounts []string `json:"serviceAccounts"`
+
+	// TTL is the lifetime of the exchanged credentials. Credentials never outlive the user JWT
+	// they are derived from, nor the --token-exchange-max-ttl of the operator.
+	// +kubebuilder:default="15m"
+	TTL *metav1.Duration `json:"ttl,omitempty"`
+}
+
+// CredentialsFormat defines the layout of the credentials Secret
+// +kubebuilder:validation:Enum=creds;split;env;bundle
+type CredentialsFormat string
+
+const (
+	// CredentialsFormatCreds writes a NATS creds file (user.creds) plus user.jwt and seed.nk
+	CredentialsFormatCreds CredentialsFormat = "creds"
+	// CredentialsFormatSplit writes the JWT and seed as separate files (user.jwt, user.seed)
+	CredentialsFormatSplit CredentialsFormat = "split"
+	// CredentialsFormatEnv writes NATS_JWT and NATS_NKEY_SEED for use with envFrom
+	CredentialsFormatEnv CredentialsFormat = "env"
+	// CredentialsFormatBundle writes a JSON document (bundle.json) with url, jwt, seed and tls
+	CredentialsFormatBundle CredentialsFormat = "bundle"
+)
+
+// CredentialsOutput defines how credentials are written to the Secret
+type CredentialsOutput struct {
+	// Format of the credentials Secret (JWT mode). Token users always get USERNAME/PASSWORD.
+	// +kubebuilder:default="creds"
+	Format CredentialsFormat `json:"format,omitempty"`
+
+	// TLSSecretRef references a Secret with ca.crt and optionally tls.crt/tls.key
+	// to embed in the bundle format
+	TLSSecretRef *SecretRef `json:"tlsSecretRef,omitempty"`
+
+	// WellKnownKey also writes the creds file under the stable nats.creds key and records
+	// the recommended file path in the nats.jradikk/creds-path annotation (JWT mode)
+	WellKnownKey bool `json:"wellKnownKey,omitempty"`
+
+	// MountPath is the recommended directory to mount the credentials Secret at
+	// +kubebuilder:default="/etc/nats/creds"
+	MountPath string `json:"mountPath,omitempty"`
+
+	// MountSnippet writes a <secret>-mount ConfigMap (key snippet.yaml) with a ready-to-paste
+	// volume, volumeMount and NATS_CREDS_PATH env snippet. Requires wellKnownKey.
+	MountSnippet bool `json:"mountSnippet,omitempty"`
+
+	// SecretType is the type of the credentials Secret, e.g. nats.jradikk/creds, so admission
+	// policies and secret scanners can select NATS credentials. Defaults to the userDefaults of
+	// the NatsOperatorSettings, then Opaque. Types under kubernetes.io/ require keys the
+	// credentials Secret does not hold and are rejected. The type of a Secret cannot be changed,
+	// so a change recreates the Secret.
+	// +kubebuilder:validation:MaxLength=253
+	// +kubebuilder:validation:Pattern=`^(Opaque|[a-z0-9]([-a-z0-9.]*[a-z0-9])?/[A-Za-z0-9]([-A-Za-z0-9_.]*[A-Za-z0-9])?)$`
+	SecretType string `json:"secretType,omitempty"`
 }
 
 // UserState represents the state of the user
@@ -106,15 +372,54 @@ type NatsUserStatus struct {
 	// +kubebuilder:default="Pending"
 	State UserState `json:"state,omitempty"`
 
-	// Reason provides more detail about the current state
-	Reason string `json:"reason,omitempty"`
+	// Reason is the machine-readable reason of the current state; Message holds the details
+	Reason ReasonCode `json:"reason,omitempty"`
 
 	// SecretRef references the Secret containing user credentials
 	SecretRef SecretRef `json:"secretRef,omitempty"`
 
+	// AccountRef is the NatsAccount the user belongs to (JWT mode): spec.accountRef, or the
+	// defaultAccountRef of the NatsAuthConfig when unset. The namespace is always set.
+	AccountRef *NatsAccountRef `json:"accountRef,omitempty"`
+
 	// PublicKey is the public key of the user (JWT mode)
 	PublicKey string `json:"publicKey,omitempty"`
 
+	// Username is the username the user holds in the NatsAuthConfig (token mode). A username is
+	// held by the oldest NatsUser claiming it; later claims fail with reason UsernameConflict.
+	Username string `json:"username,omitempty"`
+
+	// PermissionsHash is a hash of the effective permissions in the issued user JWT (JWT mode).
+	// The JWT is re-signed whenever the spec no longer matches it.
+	PermissionsHash string `json:"permissionsHash,omitempty"`
+
+	// PasswordSourceVersion is the resourceVersion of the passwordFrom Secret the current
+	// password was read from (token mode). The credentials Secret is re-rendered when it changes.
+	PasswordSourceVersion string `json:"passwordSourceVersion,omitempty"`
+
+	// LastCompletedStep is the last checkpoint reached while issuing the user JWT (JWT mode).
+	// A reconcile interrupted before SecretWritten resumes from the persisted seed.
+	LastCompletedStep ReconcileStep `json:"lastCompletedStep,omitempty"`
+
+	// JWTFingerprint is the hex SHA-256 of the user JWT in the credentials Secret (JWT mode).
+	// A Secret holding another JWT for the same key has drifted and is repaired.
+	JWTFingerprint string `json:"jwtFingerprint,omitempty"`
+
+	// IssuedAt is the issue time of the user JWT (JWT mode)
+	IssuedAt *metav1.Time `json:"issuedAt,omitempty"`
+
+	// ExpiresAt is the expiry of the issued user JWT (JWT mode); unset when the JWT does not expire
+	ExpiresAt *metav1.Time `json:"expiresAt,omitempty"`
+
+	// NotBefore is when the issued user JWT becomes valid (JWT mode); unset when it is valid from issuance
+	NotBefore *metav1.Time `json:"notBefore,omitempty"`
+
+	// Phase summarizes the Ready condition (Pending, Ready or Error)
+	Phase Phase `json:"phase,omitempty"`
+
+	// Message is the human-readable message of the Ready condition
+	Message string `json:"message,omitempty"`
+
 	// Conditions represent the latest available observations of the object's state
 	Conditions []metav1.Condition `json:"conditions,omitempty"`
 
@@ -127,10 +432,15 @@ type NatsUserStatus struct {
 
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
+// +kubebuilder:storageversion
 // +kubebuilder:resource:scope=Namespaced
 // +kubebuilder:printcolumn:name="Auth Type",type=string,JSONPath=`.spec.authType`
-// +kubebuilder:printcolumn:name="State",type=string,JSONPath=`.status.state`
-// +kubebuilder:printcolumn:name="Account",type=string,JSONPath=`.spec.accountRef.name`
+// +kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
+// +kubebuilder:printcolumn:name="Account",type=string,JSONPath=`.status.accountRef.name`
+// +kubebuilder:printcolumn:name="Secret",type=string,JSONPath=`.status.secretRef.name`
+// +kubebuilder:printcolumn:name="Expires",type=string,JSONPath=`.status.expiresAt`
+// +kubebuilder:printcolumn:name="Public Key",type=string,JSONPath=`.status.publicKey`,priority=1
+// +kubebuilder:printcolumn:name="Message",type=string,JSONPath=`.status.message`,priority=1
 // +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
 
 // NatsUser is the Schema for the natsusers API
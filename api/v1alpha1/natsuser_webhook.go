@@ -0,0 +1,64 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// log is for logging in this package.
+var natsuserlog = logf.Log.WithName("natsuser-resource")
+
+// SetupWebhookWithManager registers the NatsUser validating and conversion webhooks with the manager.
+// A non-nil validator replaces the validation of the NatsUser methods below, e.g. to add warnings
+// that need the API server.
+func (r *NatsUser) SetupWebhookWithManager(mgr ctrl.Manager, validator admission.CustomValidator) error {
+	builder := ctrl.NewWebhookManagedBy(mgr).For(r)
+	if validator != nil {
+		builder = builder.WithValidator(validator)
+	}
+	return builder.Complete()
+}
+
+// +kubebuilder:webhook:path=/validate-nats-jradikk-v1alpha1-natsuser,mutating=false,failurePolicy=fail,sideEffects=None,groups=nats.jradikk,resources=natsusers,verbs=create;update,versions=v1alpha1,name=vnatsuser.kb.io,admissionReviewVersions=v1
+
+var _ webhook.Validator = &NatsUser{}
+
+// ValidateCreate implements webhook.Validator so a webhook will be registered for the type
+func (r *NatsUser) ValidateCreate() (admission.Warnings, error) {
+	natsuserlog.Info("validate create", "name", r.Name)
+	return nil, r.validateNatsUser()
+}
+
+// ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
+func (r *NatsUser) ValidateUpdate(old runtime.Object) (admission.Warnings, error) {
+	natsuserlog.Info("validate update", "name", r.Name)
+	return nil, r.validateNatsUser()
+}
+
+// ValidateDelete implements webhook.Validator so a webhook will be registered for the type
+func (r *NatsUser) ValidateDelete() (admission.Warnings, error) {
+	return nil, nil
+}
+
+func (r *NatsUser) validateNatsUser() error {
+	return r.Spec.Validate()
+}
@@ -0,0 +1,52 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// ObjectKey returns the key of the referenced Secret; an empty namespace defaults to namespace,
+// the namespace of the referencing resource
+func (r SecretRef) ObjectKey(namespace string) types.NamespacedName {
+	return refKey(r.Namespace, r.Name, namespace)
+}
+
+// ObjectKey returns the key of the referenced Secret; an empty namespace defaults to namespace,
+// the namespace of the referencing resource
+func (r SeedSecretRef) ObjectKey(namespace string) types.NamespacedName {
+	return refKey(r.Namespace, r.Name, namespace)
+}
+
+// ObjectKey returns the key of the referenced Secret; an empty namespace defaults to namespace,
+// the namespace of the referencing resource
+func (r PasswordSecretRef) ObjectKey(namespace string) types.NamespacedName {
+	return refKey(r.Namespace, r.Name, namespace)
+}
+
+// ObjectKey returns the key of the referenced NatsAuthConfig; an empty namespace defaults to
+// namespace, the namespace of the referencing resource
+func (r NatsAuthConfigRef) ObjectKey(namespace string) types.NamespacedName {
+	return refKey(r.Namespace, r.Name, namespace)
+}
+
+func refKey(namespace, name, defaultNamespace string) types.NamespacedName {
+	if namespace == "" {
+		namespace = defaultNamespace
+	}
+	return types.NamespacedName{Namespace: namespace, Name: name}
+}
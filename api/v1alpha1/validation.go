@@ -0,0 +1,308 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/jradikk/nats-auth-operator/internal/subject"
+)
+
+// metadataKeyPattern matches account metadata keys, which become the key of a "key:value" JWT tag
+var metadataKeyPattern = regexp.MustCompile(`^[a-z0-9]([a-z0-9._-]*[a-z0-9])?$`)
+
+// jetStreamTierPattern matches the tier names the NATS server derives from stream replicas
+var jetStreamTierPattern = regexp.MustCompile(`^R[1-9][0-9]*$`)
+
+// MaxClockSkewTolerance is the largest clockSkewTolerance of user JWTs
+const MaxClockSkewTolerance = time.Hour
+
+// ValidateClockSkewTolerance checks the clock skew tolerance of user JWTs
+func ValidateClockSkewTolerance(tolerance time.Duration) error {
+	if tolerance < 0 || tolerance > MaxClockSkewTolerance {
+		return fmt.Errorf("must be between 0 and %s", MaxClockSkewTolerance)
+	}
+	return nil
+}
+
+// Validate checks that all permission subjects are well formed
+func (p *Permissions) Validate() error {
+	if p == nil {
+		return nil
+	}
+	if err := subject.ValidateList("publishAllow", p.PublishAllow); err != nil {
+		return err
+	}
+	if err := subject.ValidateList("publishDeny", p.PublishDeny); err != nil {
+		return err
+	}
+	if err := subject.ValidateList("subscribeAllow", p.SubscribeAllow); err != nil {
+		return err
+	}
+	if err := subject.ValidateList("subscribeDeny", p.SubscribeDeny); err != nil {
+		return err
+	}
+	return nil
+}
+
+// ValidateSecretType checks the type of a generated credentials Secret. Types under kubernetes.io/
+// are validated by the API server against keys the credentials Secret does not hold.
+func ValidateSecretType(secretType string) error {
+	if strings.HasPrefix(secretType, "kubernetes.io/") {
+		return fmt.Errorf("%s is a built-in Secret type; use Opaque or a type of your own, e.g. nats.jradikk/creds", secretType)
+	}
+	return nil
+}
+
+// Validate checks the NatsUser spec for errors the CRD schema cannot express
+func (s *NatsUserSpec) Validate() error {
+	if err := s.Permissions.Validate(); err != nil {
+		return fmt.Errorf("invalid permissions: %w", err)
+	}
+
+	if s.Output != nil && s.Output.MountSnippet && !s.Output.WellKnownKey && s.Purpose != UserPurposeJetStreamController {
+		return fmt.Errorf("output.mountSnippet requires output.wellKnownKey")
+	}
+	if s.Output != nil {
+		if err := ValidateSecretType(s.Output.SecretType); err != nil {
+			return fmt.Errorf("invalid output.secretType: %w", err)
+		}
+	}
+
+	if s.Expiry != nil && s.Expiry.Duration < time.Minute {
+		return fmt.Errorf("expiry must be at least 1m")
+	}
+
+	if s.Purpose == UserPurposeLeafNode {
+		if s.LeafNode == nil || len(s.LeafNode.URLs) == 0 {
+			return fmt.Errorf("leafNode.urls is required when purpose is leafnode")
+		}
+		if s.AuthType == UserAuthTypeToken {
+			return fmt.Errorf("leafnode users require JWT auth")
+		}
+		if s.Output != nil && s.Output.Format != "" && s.Output.Format != CredentialsFormatCreds {
+			return fmt.Errorf("leafnode users require the creds output format")
+		}
+		if len(s.AllowedConnectionTypes) > 0 || s.BearerToken {
+			return fmt.Errorf("leafnode users cannot set allowedConnectionTypes or bearerToken")
+		}
+	}
+
+	if s.Purpose == UserPurposeJetStreamController {
+		if s.AuthType == UserAuthTypeToken {
+			return fmt.Errorf("jetstream-controller users require JWT auth")
+		}
+		if s.BearerToken {
+			return fmt.Errorf("jetstream-controller users cannot set bearerToken")
+		}
+	}
+
+	if err := s.Limits.Validate(); err != nil {
+		return fmt.Errorf("invalid limits: %w", err)
+	}
+
+	if s.SigningKeyRole != "" && s.AuthType == UserAuthTypeToken {
+		return fmt.Errorf("signingKeyRole requires JWT auth")
+	}
+
+	if s.NotBefore != nil || s.ClockSkewTolerance != nil {
+		if s.AuthType == UserAuthTypeToken {
+			return fmt.Errorf("notBefore and clockSkewTolerance require JWT auth")
+		}
+		if s.ExistingJWTSecret != nil {
+			return fmt.Errorf("notBefore and clockSkewTolerance cannot be combined with existingJWTSecret")
+		}
+	}
+	if s.ClockSkewTolerance != nil {
+		if err := ValidateClockSkewTolerance(s.ClockSkewTolerance.Duration); err != nil {
+			return fmt.Errorf("invalid clockSkewTolerance: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// Validate checks the NatsAccount metadata, limits, exports, imports and signing keys for errors the CRD schema cannot express
+func (s *NatsAccountSpec) Validate() error {
+	for key := range s.Metadata {
+		if !metadataKeyPattern.MatchString(key) {
+			return fmt.Errorf("invalid metadata key %q: must be lowercase alphanumeric, '.', '_' or '-'", key)
+		}
+		if key == "contact" {
+			return fmt.Errorf("metadata key \"contact\" is reserved, use spec.contact")
+		}
+	}
+	if s.Limits != nil && len(s.Limits.JetStreamTiers) > 0 {
+		if s.Limits.JetStream != nil {
+			return fmt.Errorf("limits.jetstream and limits.jetstreamTiers are mutually exclusive")
+		}
+		for tier := range s.Limits.JetStreamTiers {
+			if !jetStreamTierPattern.MatchString(tier) {
+				return fmt.Errorf("invalid limits.jetstreamTiers key %q: must be a replication tier such as R1 or R3", tier)
+			}
+		}
+	}
+	if err := s.Limits.Validate(); err != nil {
+		return fmt.Errorf("invalid limits: %w", err)
+	}
+	for i, export := range s.Exports {
+		if err := subject.Validate(export.Subject); err != nil {
+			return fmt.Errorf("invalid exports[%d].subject: %w", i, err)
+		}
+		if export.Type == ExportTypeStream && (export.ResponseType != "" || export.Latency != nil) {
+			return fmt.Errorf("exports[%d].responseType and latency are only valid for service exports", i)
+		}
+		if export.Latency != nil {
+			if err := subject.Validate(export.Latency.Results); err != nil {
+				return fmt.Errorf("invalid exports[%d].latency.results: %w", i, err)
+			}
+			if strings.ContainsAny(export.Latency.Results, "*>") {
+				return fmt.Errorf("exports[%d].latency.results cannot contain wildcards", i)
+			}
+		}
+	}
+	mapped := map[string]bool{}
+	for i, mapping := range s.Mappings {
+		if err := subject.Validate(mapping.Subject); err != nil {
+			return fmt.Errorf("invalid mappings[%d].subject: %w", i, err)
+		}
+		if mapped[mapping.Subject] {
+			return fmt.Errorf("mappings[%d].subject %q is mapped more than once", i, mapping.Subject)
+		}
+		mapped[mapping.Subject] = true
+
+		var total int32
+		for j, dest := range mapping.Destinations {
+			if strings.TrimSpace(dest.Subject) == "" {
+				return fmt.Errorf("mappings[%d].destinations[%d].subject is empty", i, j)
+			}
+			if dest.Weight == 0 {
+				total += 100
+			} else {
+				total += dest.Weight
+			}
+		}
+		if total > 100 {
+			return fmt.Errorf("mappings[%d] destination weights add up to %d%%, more than 100%%", i, total)
+		}
+	}
+	for i, imp := range s.Imports {
+		if err := subject.Validate(imp.Subject); err != nil {
+			return fmt.Errorf("invalid imports[%d].subject: %w", i, err)
+		}
+		if imp.Type == ExportTypeStream && imp.To != "" {
+			return fmt.Errorf("imports[%d].to is only valid for service imports", i)
+		}
+		if imp.Type == ExportTypeService && imp.Prefix != "" {
+			return fmt.Errorf("imports[%d].prefix is only valid for stream imports", i)
+		}
+		if imp.Prefix != "" {
+			if err := subject.Validate(imp.Prefix); err != nil {
+				return fmt.Errorf("invalid imports[%d].prefix: %w", i, err)
+			}
+		}
+		if imp.To != "" {
+			if err := subject.Validate(imp.To); err != nil {
+				return fmt.Errorf("invalid imports[%d].to: %w", i, err)
+			}
+		}
+	}
+	for i, key := range s.SigningKeys {
+		if key.Scope == nil {
+			continue
+		}
+		if err := key.Scope.Permissions.Validate(); err != nil {
+			return fmt.Errorf("invalid signingKeys[%d].scope.permissions: %w", i, err)
+		}
+		if err := key.Scope.Limits.Validate(); err != nil {
+			return fmt.Errorf("invalid signingKeys[%d].scope.limits: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// Validate checks that the size fields of the limits are whole byte counts
+func (l *UserLimits) Validate() error {
+	if l == nil {
+		return nil
+	}
+	if err := validateSize("dataSize", l.DataSize); err != nil {
+		return err
+	}
+	return validateSize("payloadSize", l.PayloadSize)
+}
+
+// Validate checks that the size fields of the limits, including the JetStream limits, are whole byte counts
+func (l *AccountLimits) Validate() error {
+	if l == nil {
+		return nil
+	}
+	if err := validateSize("payloadSize", l.PayloadSize); err != nil {
+		return err
+	}
+	if err := validateSize("dataSize", l.DataSize); err != nil {
+		return err
+	}
+	if err := l.JetStream.Validate(); err != nil {
+		return fmt.Errorf("jetstream.%w", err)
+	}
+	for tier, limits := range l.JetStreamTiers {
+		limits := limits
+		if err := limits.Validate(); err != nil {
+			return fmt.Errorf("jetstreamTiers[%s].%w", tier, err)
+		}
+	}
+	return nil
+}
+
+// Validate checks that the size fields of the JetStream limits are whole byte counts
+func (l *JetStreamLimits) Validate() error {
+	if l == nil {
+		return nil
+	}
+	if err := validateSize("memoryStorageSize", l.MemoryStorageSize); err != nil {
+		return err
+	}
+	if err := validateSize("diskStorageSize", l.DiskStorageSize); err != nil {
+		return err
+	}
+	if err := validateSize("memoryMaxStreamSize", l.MemoryMaxStreamSize); err != nil {
+		return err
+	}
+	return validateSize("diskMaxStreamSize", l.DiskMaxStreamSize)
+}
+
+// validateSize checks that a size is -1 (unlimited) or a whole, non-negative number of bytes
+// that fits a JWT limit
+func validateSize(field string, size *resource.Quantity) error {
+	if size == nil {
+		return nil
+	}
+	bytes, ok := size.AsInt64()
+	if !ok {
+		return fmt.Errorf("%s %s must be a whole number of bytes", field, size.String())
+	}
+	if bytes < -1 {
+		return fmt.Errorf("%s %s must be -1 for unlimited or at least 0", field, size.String())
+	}
+	return nil
+}
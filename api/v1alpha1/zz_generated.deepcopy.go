@@ -1,4 +1,4 @@
-// +build !ignore_autogenerated
+//go:build !ignore_autogenerated
 
 /*
 Copyright 2024.
@@ -22,466 +22,2023 @@ package v1alpha1
 
 import (
 	"k8s.io/apimachinery/pkg/apis/meta/v1"
-	runtime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime"
 )
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *AccountLimits) DeepCopyInto(out *AccountLimits) {
+func (in *AccountDefaults) DeepCopyInto(out *AccountDefaults) {
 	*out = *in
+	if in.Limits != nil {
+		in, out := &in.Limits, &out.Limits
+		*out = new(AccountLimits)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AccountLimits.
-func (in *AccountLimits) DeepCopy() *AccountLimits {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AccountDefaults.
+func (in *AccountDefaults) DeepCopy() *AccountDefaults {
 	if in == nil {
 		return nil
 	}
-	out := new(AccountLimits)
+	out := new(AccountDefaults)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *JWTConfig) DeepCopyInto(out *JWTConfig) {
+func (in *AccountExport) DeepCopyInto(out *AccountExport) {
 	*out = *in
-	if in.OperatorSeedSecret != nil {
-		in, out := &in.OperatorSeedSecret, &out.OperatorSeedSecret
-		*out = new(OperatorSeedSecretRef)
+	if in.Accounts != nil {
+		in, out := &in.Accounts, &out.Accounts
+		*out = make([]NatsAccountRef, len(*in))
+		copy(*out, *in)
+	}
+	if in.Latency != nil {
+		in, out := &in.Latency, &out.Latency
+		*out = new(ServiceLatency)
 		**out = **in
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new JWTConfig.
-func (in *JWTConfig) DeepCopy() *JWTConfig {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AccountExport.
+func (in *AccountExport) DeepCopy() *AccountExport {
 	if in == nil {
 		return nil
 	}
-	out := new(JWTConfig)
+	out := new(AccountExport)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *NatsAccount) DeepCopyInto(out *NatsAccount) {
+func (in *AccountImport) DeepCopyInto(out *AccountImport) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	in.Spec.DeepCopyInto(&out.Spec)
-	in.Status.DeepCopyInto(&out.Status)
+	out.AccountRef = in.AccountRef
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NatsAccount.
-func (in *NatsAccount) DeepCopy() *NatsAccount {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AccountImport.
+func (in *AccountImport) DeepCopy() *AccountImport {
 	if in == nil {
 		return nil
 	}
-	out := new(NatsAccount)
+	out := new(AccountImport)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *NatsAccount) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
-	}
-	return nil
-}
-
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *NatsAccountList) DeepCopyInto(out *NatsAccountList) {
+func (in *AccountLimits) DeepCopyInto(out *AccountLimits) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ListMeta.DeepCopyInto(&out.ListMeta)
-	if in.Items != nil {
-		in, out := &in.Items, &out.Items
-		*out = make([]NatsAccount, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
+	if in.PayloadSize != nil {
+		in, out := &in.PayloadSize, &out.PayloadSize
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.DataSize != nil {
+		in, out := &in.DataSize, &out.DataSize
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.JetStream != nil {
+		in, out := &in.JetStream, &out.JetStream
+		*out = new(JetStreamLimits)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.JetStreamTiers != nil {
+		in, out := &in.JetStreamTiers, &out.JetStreamTiers
+		*out = make(map[string]JetStreamLimits, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NatsAccountList.
-func (in *NatsAccountList) DeepCopy() *NatsAccountList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AccountLimits.
+func (in *AccountLimits) DeepCopy() *AccountLimits {
 	if in == nil {
 		return nil
 	}
-	out := new(NatsAccountList)
+	out := new(AccountLimits)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *NatsAccountList) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
-	}
-	return nil
-}
-
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *NatsAccountSpec) DeepCopyInto(out *NatsAccountSpec) {
+func (in *AccountPublishing) DeepCopyInto(out *AccountPublishing) {
 	*out = *in
-	out.AuthConfigRef = in.AuthConfigRef
-	if in.Limits != nil {
-		in, out := &in.Limits, &out.Limits
-		*out = new(AccountLimits)
+	if in.AccountServer != nil {
+		in, out := &in.AccountServer, &out.AccountServer
+		*out = new(AccountServerTarget)
 		**out = **in
 	}
-	if in.ExistingSeedSecret != nil {
-		in, out := &in.ExistingSeedSecret, &out.ExistingSeedSecret
-		*out = new(SecretRef)
+	if in.Bucket != nil {
+		in, out := &in.Bucket, &out.Bucket
+		*out = new(BucketTarget)
 		**out = **in
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NatsAccountSpec.
-func (in *NatsAccountSpec) DeepCopy() *NatsAccountSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AccountPublishing.
+func (in *AccountPublishing) DeepCopy() *AccountPublishing {
 	if in == nil {
 		return nil
 	}
-	out := new(NatsAccountSpec)
+	out := new(AccountPublishing)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *NatsAccountStatus) DeepCopyInto(out *NatsAccountStatus) {
+func (in *AccountServerTarget) DeepCopyInto(out *AccountServerTarget) {
 	*out = *in
-	out.JWTSecretRef = in.JWTSecretRef
-	if in.Conditions != nil {
-		in, out := &in.Conditions, &out.Conditions
-		*out = make([]v1.Condition, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
-	if in.LastReconciled != nil {
-		in, out := &in.LastReconciled, &out.LastReconciled
-		*out = (*in).DeepCopy()
-	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NatsAccountStatus.
-func (in *NatsAccountStatus) DeepCopy() *NatsAccountStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AccountServerTarget.
+func (in *AccountServerTarget) DeepCopy() *AccountServerTarget {
 	if in == nil {
 		return nil
 	}
-	out := new(NatsAccountStatus)
+	out := new(AccountServerTarget)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *NatsAuthConfig) DeepCopyInto(out *NatsAuthConfig) {
+func (in *AccountSigningKey) DeepCopyInto(out *AccountSigningKey) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	in.Spec.DeepCopyInto(&out.Spec)
-	in.Status.DeepCopyInto(&out.Status)
+	if in.Scope != nil {
+		in, out := &in.Scope, &out.Scope
+		*out = new(SigningKeyScope)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NatsAuthConfig.
-func (in *NatsAuthConfig) DeepCopy() *NatsAuthConfig {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AccountSigningKey.
+func (in *AccountSigningKey) DeepCopy() *AccountSigningKey {
 	if in == nil {
 		return nil
 	}
-	out := new(NatsAuthConfig)
+	out := new(AccountSigningKey)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *NatsAuthConfig) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
-	}
-	return nil
-}
-
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *NatsAuthConfigList) DeepCopyInto(out *NatsAuthConfigList) {
+func (in *AccountUser) DeepCopyInto(out *AccountUser) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ListMeta.DeepCopyInto(&out.ListMeta)
-	if in.Items != nil {
-		in, out := &in.Items, &out.Items
-		*out = make([]NatsAuthConfig, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NatsAuthConfigList.
-func (in *NatsAuthConfigList) DeepCopy() *NatsAuthConfigList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AccountUser.
+func (in *AccountUser) DeepCopy() *AccountUser {
 	if in == nil {
 		return nil
 	}
-	out := new(NatsAuthConfigList)
+	out := new(AccountUser)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *NatsAuthConfigList) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
-	}
-	return nil
-}
-
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *NatsAccountRef) DeepCopyInto(out *NatsAccountRef) {
+func (in *BootstrapConfig) DeepCopyInto(out *BootstrapConfig) {
 	*out = *in
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NatsAccountRef.
-func (in *NatsAccountRef) DeepCopy() *NatsAccountRef {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BootstrapConfig.
+func (in *BootstrapConfig) DeepCopy() *BootstrapConfig {
 	if in == nil {
 		return nil
 	}
-	out := new(NatsAccountRef)
+	out := new(BootstrapConfig)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *NatsAuthConfigRef) DeepCopyInto(out *NatsAuthConfigRef) {
+func (in *BoundIdentity) DeepCopyInto(out *BoundIdentity) {
 	*out = *in
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NatsAuthConfigRef.
-func (in *NatsAuthConfigRef) DeepCopy() *NatsAuthConfigRef {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BoundIdentity.
+func (in *BoundIdentity) DeepCopy() *BoundIdentity {
 	if in == nil {
 		return nil
 	}
-	out := new(NatsAuthConfigRef)
+	out := new(BoundIdentity)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *NatsAuthConfigSpec) DeepCopyInto(out *NatsAuthConfigSpec) {
+func (in *BucketTarget) DeepCopyInto(out *BucketTarget) {
 	*out = *in
-	out.ServerAuthConfig = in.ServerAuthConfig
-	if in.JWT != nil {
-		in, out := &in.JWT, &out.JWT
-		*out = new(JWTConfig)
-		(*in).DeepCopyInto(*out)
-	}
+	out.CredentialsSecret = in.CredentialsSecret
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NatsAuthConfigSpec.
-func (in *NatsAuthConfigSpec) DeepCopy() *NatsAuthConfigSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BucketTarget.
+func (in *BucketTarget) DeepCopy() *BucketTarget {
 	if in == nil {
 		return nil
 	}
-	out := new(NatsAuthConfigSpec)
+	out := new(BucketTarget)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *NatsAuthConfigStatus) DeepCopyInto(out *NatsAuthConfigStatus) {
+func (in *ChildrenSummary) DeepCopyInto(out *ChildrenSummary) {
 	*out = *in
-	if in.LastReconciled != nil {
-		in, out := &in.LastReconciled, &out.LastReconciled
-		*out = (*in).DeepCopy()
-	}
-	if in.Conditions != nil {
-		in, out := &in.Conditions, &out.Conditions
-		*out = make([]v1.Condition, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
+	if in.UsersByAuthType != nil {
+		in, out := &in.UsersByAuthType, &out.UsersByAuthType
+		*out = make(map[string]int32, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NatsAuthConfigStatus.
-func (in *NatsAuthConfigStatus) DeepCopy() *NatsAuthConfigStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ChildrenSummary.
+func (in *ChildrenSummary) DeepCopy() *ChildrenSummary {
 	if in == nil {
 		return nil
 	}
-	out := new(NatsAuthConfigStatus)
+	out := new(ChildrenSummary)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *NatsUser) DeepCopyInto(out *NatsUser) {
+func (in *ClaimHook) DeepCopyInto(out *ClaimHook) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	in.Spec.DeepCopyInto(&out.Spec)
-	in.Status.DeepCopyInto(&out.Status)
+	if in.Kinds != nil {
+		in, out := &in.Kinds, &out.Kinds
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NatsUser.
-func (in *NatsUser) DeepCopy() *NatsUser {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClaimHook.
+func (in *ClaimHook) DeepCopy() *ClaimHook {
 	if in == nil {
 		return nil
 	}
-	out := new(NatsUser)
+	out := new(ClaimHook)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *NatsUser) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
-	}
-	return nil
-}
-
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *NatsUserList) DeepCopyInto(out *NatsUserList) {
+func (in *ConfigRolloutCheck) DeepCopyInto(out *ConfigRolloutCheck) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ListMeta.DeepCopyInto(&out.ListMeta)
-	if in.Items != nil {
-		in, out := &in.Items, &out.Items
-		*out = make([]NatsUser, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
+	in.PodSelector.DeepCopyInto(&out.PodSelector)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NatsUserList.
-func (in *NatsUserList) DeepCopy() *NatsUserList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConfigRolloutCheck.
+func (in *ConfigRolloutCheck) DeepCopy() *ConfigRolloutCheck {
 	if in == nil {
 		return nil
 	}
-	out := new(NatsUserList)
+	out := new(ConfigRolloutCheck)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *NatsUserList) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
-	}
-	return nil
-}
-
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *NatsUserSpec) DeepCopyInto(out *NatsUserSpec) {
+func (in *CredentialsOutput) DeepCopyInto(out *CredentialsOutput) {
 	*out = *in
-	out.AuthConfigRef = in.AuthConfigRef
-	if in.AccountRef != nil {
-		in, out := &in.AccountRef, &out.AccountRef
-		*out = new(NatsAccountRef)
-		**out = **in
-	}
-	if in.PasswordFrom != nil {
-		in, out := &in.PasswordFrom, &out.PasswordFrom
-		*out = new(PasswordSource)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.Permissions != nil {
-		in, out := &in.Permissions, &out.Permissions
-		*out = new(Permissions)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.ExistingSeedSecret != nil {
-		in, out := &in.ExistingSeedSecret, &out.ExistingSeedSecret
+	if in.TLSSecretRef != nil {
+		in, out := &in.TLSSecretRef, &out.TLSSecretRef
 		*out = new(SecretRef)
 		**out = **in
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NatsUserSpec.
-func (in *NatsUserSpec) DeepCopy() *NatsUserSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CredentialsOutput.
+func (in *CredentialsOutput) DeepCopy() *CredentialsOutput {
 	if in == nil {
 		return nil
 	}
-	out := new(NatsUserSpec)
+	out := new(CredentialsOutput)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *NatsUserStatus) DeepCopyInto(out *NatsUserStatus) {
+func (in *ExternalSigner) DeepCopyInto(out *ExternalSigner) {
 	*out = *in
-	if in.Conditions != nil {
-		in, out := &in.Conditions, &out.Conditions
-		*out = make([]v1.Condition, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
-	if in.LastReconciled != nil {
-		in, out := &in.LastReconciled, &out.LastReconciled
-		*out = (*in).DeepCopy()
-	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NatsUserStatus.
-func (in *NatsUserStatus) DeepCopy() *NatsUserStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExternalSigner.
+func (in *ExternalSigner) DeepCopy() *ExternalSigner {
 	if in == nil {
 		return nil
 	}
-	out := new(NatsUserStatus)
+	out := new(ExternalSigner)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *OperatorSeedSecretRef) DeepCopyInto(out *OperatorSeedSecretRef) {
+func (in *IdentityConfigMapSource) DeepCopyInto(out *IdentityConfigMapSource) {
 	*out = *in
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OperatorSeedSecretRef.
-func (in *OperatorSeedSecretRef) DeepCopy() *OperatorSeedSecretRef {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IdentityConfigMapSource.
+func (in *IdentityConfigMapSource) DeepCopy() *IdentityConfigMapSource {
 	if in == nil {
 		return nil
 	}
-	out := new(OperatorSeedSecretRef)
+	out := new(IdentityConfigMapSource)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PasswordSource) DeepCopyInto(out *PasswordSource) {
+func (in *IdentityHTTPSource) DeepCopyInto(out *IdentityHTTPSource) {
 	*out = *in
-	if in.SecretRef != nil {
-		in, out := &in.SecretRef, &out.SecretRef
-		*out = new(SecretRef)
-		**out = **in
-	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PasswordSource.
-func (in *PasswordSource) DeepCopy() *PasswordSource {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IdentityHTTPSource.
+func (in *IdentityHTTPSource) DeepCopy() *IdentityHTTPSource {
 	if in == nil {
 		return nil
 	}
-	out := new(PasswordSource)
+	out := new(IdentityHTTPSource)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *SecretRef) DeepCopyInto(out *SecretRef) {
+func (in *IdentitySource) DeepCopyInto(out *IdentitySource) {
 	*out = *in
+	if in.ConfigMap != nil {
+		in, out := &in.ConfigMap, &out.ConfigMap
+		*out = new(IdentityConfigMapSource)
+		**out = **in
+	}
+	if in.HTTP != nil {
+		in, out := &in.HTTP, &out.HTTP
+		*out = new(IdentityHTTPSource)
+		**out = **in
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SecretRef.
-func (in *SecretRef) DeepCopy() *SecretRef {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IdentitySource.
+func (in *IdentitySource) DeepCopy() *IdentitySource {
 	if in == nil {
 		return nil
 	}
-	out := new(SecretRef)
+	out := new(IdentitySource)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ServerAuthConfigRef) DeepCopyInto(out *ServerAuthConfigRef) {
+func (in *IdentityUserTemplate) DeepCopyInto(out *IdentityUserTemplate) {
 	*out = *in
+	out.AccountRef = in.AccountRef
+	if in.AllowedConnectionTypes != nil {
+		in, out := &in.AllowedConnectionTypes, &out.AllowedConnectionTypes
+		*out = make([]ConnectionType, len(*in))
+		copy(*out, *in)
+	}
+	if in.Permissions != nil {
+		in, out := &in.Permissions, &out.Permissions
+		*out = new(Permissions)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Limits != nil {
+		in, out := &in.Limits, &out.Limits
+		*out = new(UserLimits)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Expiry != nil {
+		in, out := &in.Expiry, &out.Expiry
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.Output != nil {
+		in, out := &in.Output, &out.Output
+		*out = new(CredentialsOutput)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IdentityUserTemplate.
+func (in *IdentityUserTemplate) DeepCopy() *IdentityUserTemplate {
+	if in == nil {
+		return nil
+	}
+	out := new(IdentityUserTemplate)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InfraAuthConfig) DeepCopyInto(out *InfraAuthConfig) {
+	*out = *in
+	if in.Cluster != nil {
+		in, out := &in.Cluster, &out.Cluster
+		*out = new(InfraAuthEndpoint)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Gateway != nil {
+		in, out := &in.Gateway, &out.Gateway
+		*out = new(InfraAuthEndpoint)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InfraAuthConfig.
+func (in *InfraAuthConfig) DeepCopy() *InfraAuthConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(InfraAuthConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InfraAuthEndpoint) DeepCopyInto(out *InfraAuthEndpoint) {
+	*out = *in
+	if in.Password != nil {
+		in, out := &in.Password, &out.Password
+		*out = new(PasswordSource)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InfraAuthEndpoint.
+func (in *InfraAuthEndpoint) DeepCopy() *InfraAuthEndpoint {
+	if in == nil {
+		return nil
+	}
+	out := new(InfraAuthEndpoint)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *JWTConfig) DeepCopyInto(out *JWTConfig) {
+	*out = *in
+	if in.OperatorSeedSecret != nil {
+		in, out := &in.OperatorSeedSecret, &out.OperatorSeedSecret
+		*out = new(OperatorSeedSecretRef)
+		**out = **in
+	}
+	if in.OperatorServiceURLs != nil {
+		in, out := &in.OperatorServiceURLs, &out.OperatorServiceURLs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Tags != nil {
+		in, out := &in.Tags, &out.Tags
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.PinnedAccounts != nil {
+		in, out := &in.PinnedAccounts, &out.PinnedAccounts
+		*out = new(PinnedAccountsConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Signer != nil {
+		in, out := &in.Signer, &out.Signer
+		*out = new(ExternalSigner)
+		**out = **in
+	}
+	if in.Publish != nil {
+		in, out := &in.Publish, &out.Publish
+		*out = new(AccountPublishing)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Resolver != nil {
+		in, out := &in.Resolver, &out.Resolver
+		*out = new(ResolverConfig)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new JWTConfig.
+func (in *JWTConfig) DeepCopy() *JWTConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(JWTConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *JetStreamLimits) DeepCopyInto(out *JetStreamLimits) {
+	*out = *in
+	if in.MemoryStorageSize != nil {
+		in, out := &in.MemoryStorageSize, &out.MemoryStorageSize
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.DiskStorageSize != nil {
+		in, out := &in.DiskStorageSize, &out.DiskStorageSize
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.MemoryMaxStreamSize != nil {
+		in, out := &in.MemoryMaxStreamSize, &out.MemoryMaxStreamSize
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.DiskMaxStreamSize != nil {
+		in, out := &in.DiskMaxStreamSize, &out.DiskMaxStreamSize
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new JetStreamLimits.
+func (in *JetStreamLimits) DeepCopy() *JetStreamLimits {
+	if in == nil {
+		return nil
+	}
+	out := new(JetStreamLimits)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KVResolverTarget) DeepCopyInto(out *KVResolverTarget) {
+	*out = *in
+	if in.MetadataRecipients != nil {
+		in, out := &in.MetadataRecipients, &out.MetadataRecipients
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KVResolverTarget.
+func (in *KVResolverTarget) DeepCopy() *KVResolverTarget {
+	if in == nil {
+		return nil
+	}
+	out := new(KVResolverTarget)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LeafNodeRemote) DeepCopyInto(out *LeafNodeRemote) {
+	*out = *in
+	if in.URLs != nil {
+		in, out := &in.URLs, &out.URLs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LeafNodeRemote.
+func (in *LeafNodeRemote) DeepCopy() *LeafNodeRemote {
+	if in == nil {
+		return nil
+	}
+	out := new(LeafNodeRemote)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MQTTConfig) DeepCopyInto(out *MQTTConfig) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MQTTConfig.
+func (in *MQTTConfig) DeepCopy() *MQTTConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(MQTTConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MappingDestination) DeepCopyInto(out *MappingDestination) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MappingDestination.
+func (in *MappingDestination) DeepCopy() *MappingDestination {
+	if in == nil {
+		return nil
+	}
+	out := new(MappingDestination)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ModeMigration) DeepCopyInto(out *ModeMigration) {
+	*out = *in
+	if in.Users != nil {
+		in, out := &in.Users, &out.Users
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.StartedAt != nil {
+		in, out := &in.StartedAt, &out.StartedAt
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ModeMigration.
+func (in *ModeMigration) DeepCopy() *ModeMigration {
+	if in == nil {
+		return nil
+	}
+	out := new(ModeMigration)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OrphanedResource) DeepCopyInto(out *OrphanedResource) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OrphanedResource.
+func (in *OrphanedResource) DeepCopy() *OrphanedResource {
+	if in == nil {
+		return nil
+	}
+	out := new(OrphanedResource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MonitoringConfig) DeepCopyInto(out *MonitoringConfig) {
+	*out = *in
+	out.SystemAccountRef = in.SystemAccountRef
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MonitoringConfig.
+func (in *MonitoringConfig) DeepCopy() *MonitoringConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(MonitoringConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NATSResolverTarget) DeepCopyInto(out *NATSResolverTarget) {
+	*out = *in
+	out.CredentialsSecret = in.CredentialsSecret
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NATSResolverTarget.
+func (in *NATSResolverTarget) DeepCopy() *NATSResolverTarget {
+	if in == nil {
+		return nil
+	}
+	out := new(NATSResolverTarget)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NatsAccount) DeepCopyInto(out *NatsAccount) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NatsAccount.
+func (in *NatsAccount) DeepCopy() *NatsAccount {
+	if in == nil {
+		return nil
+	}
+	out := new(NatsAccount)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NatsAccount) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NatsAccountList) DeepCopyInto(out *NatsAccountList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]NatsAccount, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NatsAccountList.
+func (in *NatsAccountList) DeepCopy() *NatsAccountList {
+	if in == nil {
+		return nil
+	}
+	out := new(NatsAccountList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NatsAccountList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NatsAccountRef) DeepCopyInto(out *NatsAccountRef) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NatsAccountRef.
+func (in *NatsAccountRef) DeepCopy() *NatsAccountRef {
+	if in == nil {
+		return nil
+	}
+	out := new(NatsAccountRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NatsAccountSpec) DeepCopyInto(out *NatsAccountSpec) {
+	*out = *in
+	out.AuthConfigRef = in.AuthConfigRef
+	if in.Metadata != nil {
+		in, out := &in.Metadata, &out.Metadata
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Limits != nil {
+		in, out := &in.Limits, &out.Limits
+		*out = new(AccountLimits)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ExistingSeedSecret != nil {
+		in, out := &in.ExistingSeedSecret, &out.ExistingSeedSecret
+		*out = new(SeedSecretRef)
+		**out = **in
+	}
+	if in.Exports != nil {
+		in, out := &in.Exports, &out.Exports
+		*out = make([]AccountExport, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Imports != nil {
+		in, out := &in.Imports, &out.Imports
+		*out = make([]AccountImport, len(*in))
+		copy(*out, *in)
+	}
+	if in.Mappings != nil {
+		in, out := &in.Mappings, &out.Mappings
+		*out = make([]SubjectMapping, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.SigningKeys != nil {
+		in, out := &in.SigningKeys, &out.SigningKeys
+		*out = make([]AccountSigningKey, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.PropagateLabels != nil {
+		in, out := &in.PropagateLabels, &out.PropagateLabels
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.PropagateAnnotations != nil {
+		in, out := &in.PropagateAnnotations, &out.PropagateAnnotations
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ResyncInterval != nil {
+		in, out := &in.ResyncInterval, &out.ResyncInterval
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NatsAccountSpec.
+func (in *NatsAccountSpec) DeepCopy() *NatsAccountSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(NatsAccountSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NatsAccountStatus) DeepCopyInto(out *NatsAccountStatus) {
+	*out = *in
+	out.JWTSecretRef = in.JWTSecretRef
+	if in.IssuedAt != nil {
+		in, out := &in.IssuedAt, &out.IssuedAt
+		*out = (*in).DeepCopy()
+	}
+	if in.ExpiresAt != nil {
+		in, out := &in.ExpiresAt, &out.ExpiresAt
+		*out = (*in).DeepCopy()
+	}
+	if in.Users != nil {
+		in, out := &in.Users, &out.Users
+		*out = make([]AccountUser, len(*in))
+		copy(*out, *in)
+	}
+	if in.RevokedUsers != nil {
+		in, out := &in.RevokedUsers, &out.RevokedUsers
+		*out = make(map[string]int64, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.SigningKeys != nil {
+		in, out := &in.SigningKeys, &out.SigningKeys
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.LastReconciled != nil {
+		in, out := &in.LastReconciled, &out.LastReconciled
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NatsAccountStatus.
+func (in *NatsAccountStatus) DeepCopy() *NatsAccountStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(NatsAccountStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NatsAuthBackup) DeepCopyInto(out *NatsAuthBackup) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NatsAuthBackup.
+func (in *NatsAuthBackup) DeepCopy() *NatsAuthBackup {
+	if in == nil {
+		return nil
+	}
+	out := new(NatsAuthBackup)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NatsAuthBackup) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NatsAuthBackupList) DeepCopyInto(out *NatsAuthBackupList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]NatsAuthBackup, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NatsAuthBackupList.
+func (in *NatsAuthBackupList) DeepCopy() *NatsAuthBackupList {
+	if in == nil {
+		return nil
+	}
+	out := new(NatsAuthBackupList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NatsAuthBackupList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NatsAuthBackupSpec) DeepCopyInto(out *NatsAuthBackupSpec) {
+	*out = *in
+	out.AuthConfigRef = in.AuthConfigRef
+	if in.Recipients != nil {
+		in, out := &in.Recipients, &out.Recipients
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Interval != nil {
+		in, out := &in.Interval, &out.Interval
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NatsAuthBackupSpec.
+func (in *NatsAuthBackupSpec) DeepCopy() *NatsAuthBackupSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(NatsAuthBackupSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NatsAuthBackupStatus) DeepCopyInto(out *NatsAuthBackupStatus) {
+	*out = *in
+	out.SecretRef = in.SecretRef
+	if in.LastBackupTime != nil {
+		in, out := &in.LastBackupTime, &out.LastBackupTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.LastReconciled != nil {
+		in, out := &in.LastReconciled, &out.LastReconciled
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NatsAuthBackupStatus.
+func (in *NatsAuthBackupStatus) DeepCopy() *NatsAuthBackupStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(NatsAuthBackupStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NatsAuthConfig) DeepCopyInto(out *NatsAuthConfig) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NatsAuthConfig.
+func (in *NatsAuthConfig) DeepCopy() *NatsAuthConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(NatsAuthConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NatsAuthConfig) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NatsAuthConfigList) DeepCopyInto(out *NatsAuthConfigList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]NatsAuthConfig, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NatsAuthConfigList.
+func (in *NatsAuthConfigList) DeepCopy() *NatsAuthConfigList {
+	if in == nil {
+		return nil
+	}
+	out := new(NatsAuthConfigList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NatsAuthConfigList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NatsAuthConfigRef) DeepCopyInto(out *NatsAuthConfigRef) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NatsAuthConfigRef.
+func (in *NatsAuthConfigRef) DeepCopy() *NatsAuthConfigRef {
+	if in == nil {
+		return nil
+	}
+	out := new(NatsAuthConfigRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NatsAuthConfigSpec) DeepCopyInto(out *NatsAuthConfigSpec) {
+	*out = *in
+	in.ServerAuthConfig.DeepCopyInto(&out.ServerAuthConfig)
+	if in.JWT != nil {
+		in, out := &in.JWT, &out.JWT
+		*out = new(JWTConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Websocket != nil {
+		in, out := &in.Websocket, &out.Websocket
+		*out = new(WebsocketConfig)
+		**out = **in
+	}
+	if in.MQTT != nil {
+		in, out := &in.MQTT, &out.MQTT
+		*out = new(MQTTConfig)
+		**out = **in
+	}
+	if in.Bootstrap != nil {
+		in, out := &in.Bootstrap, &out.Bootstrap
+		*out = new(BootstrapConfig)
+		**out = **in
+	}
+	if in.InfraAuth != nil {
+		in, out := &in.InfraAuth, &out.InfraAuth
+		*out = new(InfraAuthConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PublicCatalog != nil {
+		in, out := &in.PublicCatalog, &out.PublicCatalog
+		*out = new(PublicCatalogConfig)
+		**out = **in
+	}
+	if in.ClaimHooks != nil {
+		in, out := &in.ClaimHooks, &out.ClaimHooks
+		*out = make([]ClaimHook, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.UserClaims != nil {
+		in, out := &in.UserClaims, &out.UserClaims
+		*out = new(UserClaimPolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Monitoring != nil {
+		in, out := &in.Monitoring, &out.Monitoring
+		*out = new(MonitoringConfig)
+		**out = **in
+	}
+	if in.DefaultAccountRef != nil {
+		in, out := &in.DefaultAccountRef, &out.DefaultAccountRef
+		*out = new(NatsAccountRef)
+		**out = **in
+	}
+	if in.NoAuthUser != nil {
+		in, out := &in.NoAuthUser, &out.NoAuthUser
+		*out = new(NatsUserRef)
+		**out = **in
+	}
+	if in.ResyncInterval != nil {
+		in, out := &in.ResyncInterval, &out.ResyncInterval
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NatsAuthConfigSpec.
+func (in *NatsAuthConfigSpec) DeepCopy() *NatsAuthConfigSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(NatsAuthConfigSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NatsAuthConfigStatus) DeepCopyInto(out *NatsAuthConfigStatus) {
+	*out = *in
+	if in.PublishedAccounts != nil {
+		in, out := &in.PublishedAccounts, &out.PublishedAccounts
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Migration != nil {
+		in, out := &in.Migration, &out.Migration
+		*out = new(ModeMigration)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.LastReconciled != nil {
+		in, out := &in.LastReconciled, &out.LastReconciled
+		*out = (*in).DeepCopy()
+	}
+	in.Children.DeepCopyInto(&out.Children)
+	if in.Orphans != nil {
+		in, out := &in.Orphans, &out.Orphans
+		*out = make([]OrphanedResource, len(*in))
+		copy(*out, *in)
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NatsAuthConfigStatus.
+func (in *NatsAuthConfigStatus) DeepCopy() *NatsAuthConfigStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(NatsAuthConfigStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NatsCredentialBinding) DeepCopyInto(out *NatsCredentialBinding) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NatsCredentialBinding.
+func (in *NatsCredentialBinding) DeepCopy() *NatsCredentialBinding {
+	if in == nil {
+		return nil
+	}
+	out := new(NatsCredentialBinding)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NatsCredentialBinding) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NatsCredentialBindingList) DeepCopyInto(out *NatsCredentialBindingList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]NatsCredentialBinding, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NatsCredentialBindingList.
+func (in *NatsCredentialBindingList) DeepCopy() *NatsCredentialBindingList {
+	if in == nil {
+		return nil
+	}
+	out := new(NatsCredentialBindingList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NatsCredentialBindingList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NatsCredentialBindingSpec) DeepCopyInto(out *NatsCredentialBindingSpec) {
+	*out = *in
+	out.UserRef = in.UserRef
+	out.WorkloadRef = in.WorkloadRef
+	if in.ResyncInterval != nil {
+		in, out := &in.ResyncInterval, &out.ResyncInterval
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NatsCredentialBindingSpec.
+func (in *NatsCredentialBindingSpec) DeepCopy() *NatsCredentialBindingSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(NatsCredentialBindingSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NatsCredentialBindingStatus) DeepCopyInto(out *NatsCredentialBindingStatus) {
+	*out = *in
+	out.SecretRef = in.SecretRef
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.LastReconciled != nil {
+		in, out := &in.LastReconciled, &out.LastReconciled
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NatsCredentialBindingStatus.
+func (in *NatsCredentialBindingStatus) DeepCopy() *NatsCredentialBindingStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(NatsCredentialBindingStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NatsIdentityBinding) DeepCopyInto(out *NatsIdentityBinding) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NatsIdentityBinding.
+func (in *NatsIdentityBinding) DeepCopy() *NatsIdentityBinding {
+	if in == nil {
+		return nil
+	}
+	out := new(NatsIdentityBinding)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NatsIdentityBinding) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NatsIdentityBindingList) DeepCopyInto(out *NatsIdentityBindingList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]NatsIdentityBinding, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NatsIdentityBindingList.
+func (in *NatsIdentityBindingList) DeepCopy() *NatsIdentityBindingList {
+	if in == nil {
+		return nil
+	}
+	out := new(NatsIdentityBindingList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NatsIdentityBindingList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NatsIdentityBindingSpec) DeepCopyInto(out *NatsIdentityBindingSpec) {
+	*out = *in
+	out.AuthConfigRef = in.AuthConfigRef
+	in.Source.DeepCopyInto(&out.Source)
+	if in.Subjects != nil {
+		in, out := &in.Subjects, &out.Subjects
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Groups != nil {
+		in, out := &in.Groups, &out.Groups
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	in.Template.DeepCopyInto(&out.Template)
+	if in.Interval != nil {
+		in, out := &in.Interval, &out.Interval
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NatsIdentityBindingSpec.
+func (in *NatsIdentityBindingSpec) DeepCopy() *NatsIdentityBindingSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(NatsIdentityBindingSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NatsIdentityBindingStatus) DeepCopyInto(out *NatsIdentityBindingStatus) {
+	*out = *in
+	if in.Identities != nil {
+		in, out := &in.Identities, &out.Identities
+		*out = make([]BoundIdentity, len(*in))
+		copy(*out, *in)
+	}
+	if in.LastSyncTime != nil {
+		in, out := &in.LastSyncTime, &out.LastSyncTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.LastReconciled != nil {
+		in, out := &in.LastReconciled, &out.LastReconciled
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NatsIdentityBindingStatus.
+func (in *NatsIdentityBindingStatus) DeepCopy() *NatsIdentityBindingStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(NatsIdentityBindingStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NatsOperatorSettings) DeepCopyInto(out *NatsOperatorSettings) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NatsOperatorSettings.
+func (in *NatsOperatorSettings) DeepCopy() *NatsOperatorSettings {
+	if in == nil {
+		return nil
+	}
+	out := new(NatsOperatorSettings)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NatsOperatorSettings) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NatsOperatorSettingsList) DeepCopyInto(out *NatsOperatorSettingsList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]NatsOperatorSettings, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NatsOperatorSettingsList.
+func (in *NatsOperatorSettingsList) DeepCopy() *NatsOperatorSettingsList {
+	if in == nil {
+		return nil
+	}
+	out := new(NatsOperatorSettingsList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NatsOperatorSettingsList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NatsOperatorSettingsSpec) DeepCopyInto(out *NatsOperatorSettingsSpec) {
+	*out = *in
+	if in.ResyncInterval != nil {
+		in, out := &in.ResyncInterval, &out.ResyncInterval
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.UserDefaults != nil {
+		in, out := &in.UserDefaults, &out.UserDefaults
+		*out = new(UserDefaults)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AccountDefaults != nil {
+		in, out := &in.AccountDefaults, &out.AccountDefaults
+		*out = new(AccountDefaults)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SecretNames != nil {
+		in, out := &in.SecretNames, &out.SecretNames
+		*out = new(SecretNameTemplates)
+		**out = **in
+	}
+	if in.Propagation != nil {
+		in, out := &in.Propagation, &out.Propagation
+		*out = new(PropagationRules)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NatsOperatorSettingsSpec.
+func (in *NatsOperatorSettingsSpec) DeepCopy() *NatsOperatorSettingsSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(NatsOperatorSettingsSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NatsUser) DeepCopyInto(out *NatsUser) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NatsUser.
+func (in *NatsUser) DeepCopy() *NatsUser {
+	if in == nil {
+		return nil
+	}
+	out := new(NatsUser)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NatsUser) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NatsUserList) DeepCopyInto(out *NatsUserList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]NatsUser, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NatsUserList.
+func (in *NatsUserList) DeepCopy() *NatsUserList {
+	if in == nil {
+		return nil
+	}
+	out := new(NatsUserList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NatsUserList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NatsUserRef) DeepCopyInto(out *NatsUserRef) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NatsUserRef.
+func (in *NatsUserRef) DeepCopy() *NatsUserRef {
+	if in == nil {
+		return nil
+	}
+	out := new(NatsUserRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NatsUserSpec) DeepCopyInto(out *NatsUserSpec) {
+	*out = *in
+	out.AuthConfigRef = in.AuthConfigRef
+	if in.LeafNode != nil {
+		in, out := &in.LeafNode, &out.LeafNode
+		*out = new(LeafNodeRemote)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AllowedConnectionTypes != nil {
+		in, out := &in.AllowedConnectionTypes, &out.AllowedConnectionTypes
+		*out = make([]ConnectionType, len(*in))
+		copy(*out, *in)
+	}
+	if in.AccountRef != nil {
+		in, out := &in.AccountRef, &out.AccountRef
+		*out = new(NatsAccountRef)
+		**out = **in
+	}
+	if in.PasswordFrom != nil {
+		in, out := &in.PasswordFrom, &out.PasswordFrom
+		*out = new(PasswordSource)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Permissions != nil {
+		in, out := &in.Permissions, &out.Permissions
+		*out = new(Permissions)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Limits != nil {
+		in, out := &in.Limits, &out.Limits
+		*out = new(UserLimits)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Expiry != nil {
+		in, out := &in.Expiry, &out.Expiry
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.NotBefore != nil {
+		in, out := &in.NotBefore, &out.NotBefore
+		*out = (*in).DeepCopy()
+	}
+	if in.ClockSkewTolerance != nil {
+		in, out := &in.ClockSkewTolerance, &out.ClockSkewTolerance
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.TokenExchange != nil {
+		in, out := &in.TokenExchange, &out.TokenExchange
+		*out = new(UserTokenExchange)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ExistingSeedSecret != nil {
+		in, out := &in.ExistingSeedSecret, &out.ExistingSeedSecret
+		*out = new(SeedSecretRef)
+		**out = **in
+	}
+	if in.ExistingJWTSecret != nil {
+		in, out := &in.ExistingJWTSecret, &out.ExistingJWTSecret
+		*out = new(SecretRef)
+		**out = **in
+	}
+	if in.Output != nil {
+		in, out := &in.Output, &out.Output
+		*out = new(CredentialsOutput)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PropagateLabels != nil {
+		in, out := &in.PropagateLabels, &out.PropagateLabels
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.PropagateAnnotations != nil {
+		in, out := &in.PropagateAnnotations, &out.PropagateAnnotations
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ResyncInterval != nil {
+		in, out := &in.ResyncInterval, &out.ResyncInterval
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NatsUserSpec.
+func (in *NatsUserSpec) DeepCopy() *NatsUserSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(NatsUserSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NatsUserStatus) DeepCopyInto(out *NatsUserStatus) {
+	*out = *in
+	out.SecretRef = in.SecretRef
+	if in.AccountRef != nil {
+		in, out := &in.AccountRef, &out.AccountRef
+		*out = new(NatsAccountRef)
+		**out = **in
+	}
+	if in.IssuedAt != nil {
+		in, out := &in.IssuedAt, &out.IssuedAt
+		*out = (*in).DeepCopy()
+	}
+	if in.ExpiresAt != nil {
+		in, out := &in.ExpiresAt, &out.ExpiresAt
+		*out = (*in).DeepCopy()
+	}
+	if in.NotBefore != nil {
+		in, out := &in.NotBefore, &out.NotBefore
+		*out = (*in).DeepCopy()
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.LastReconciled != nil {
+		in, out := &in.LastReconciled, &out.LastReconciled
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NatsUserStatus.
+func (in *NatsUserStatus) DeepCopy() *NatsUserStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(NatsUserStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OperatorSeedSecretRef) DeepCopyInto(out *OperatorSeedSecretRef) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OperatorSeedSecretRef.
+func (in *OperatorSeedSecretRef) DeepCopy() *OperatorSeedSecretRef {
+	if in == nil {
+		return nil
+	}
+	out := new(OperatorSeedSecretRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PasswordPolicy) DeepCopyInto(out *PasswordPolicy) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PasswordPolicy.
+func (in *PasswordPolicy) DeepCopy() *PasswordPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(PasswordPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PasswordSecretRef) DeepCopyInto(out *PasswordSecretRef) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PasswordSecretRef.
+func (in *PasswordSecretRef) DeepCopy() *PasswordSecretRef {
+	if in == nil {
+		return nil
+	}
+	out := new(PasswordSecretRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PasswordSource) DeepCopyInto(out *PasswordSource) {
+	*out = *in
+	if in.SecretRef != nil {
+		in, out := &in.SecretRef, &out.SecretRef
+		*out = new(PasswordSecretRef)
+		**out = **in
+	}
+	if in.Policy != nil {
+		in, out := &in.Policy, &out.Policy
+		*out = new(PasswordPolicy)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PasswordSource.
+func (in *PasswordSource) DeepCopy() *PasswordSource {
+	if in == nil {
+		return nil
+	}
+	out := new(PasswordSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Permissions) DeepCopyInto(out *Permissions) {
+	*out = *in
+	if in.PublishAllow != nil {
+		in, out := &in.PublishAllow, &out.PublishAllow
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.PublishDeny != nil {
+		in, out := &in.PublishDeny, &out.PublishDeny
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.SubscribeAllow != nil {
+		in, out := &in.SubscribeAllow, &out.SubscribeAllow
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.SubscribeDeny != nil {
+		in, out := &in.SubscribeDeny, &out.SubscribeDeny
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Permissions.
+func (in *Permissions) DeepCopy() *Permissions {
+	if in == nil {
+		return nil
+	}
+	out := new(Permissions)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PinnedAccountsConfig) DeepCopyInto(out *PinnedAccountsConfig) {
+	*out = *in
+	if in.Tags != nil {
+		in, out := &in.Tags, &out.Tags
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.AccountIDs != nil {
+		in, out := &in.AccountIDs, &out.AccountIDs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PinnedAccountsConfig.
+func (in *PinnedAccountsConfig) DeepCopy() *PinnedAccountsConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(PinnedAccountsConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PropagationRules) DeepCopyInto(out *PropagationRules) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PropagationRules.
+func (in *PropagationRules) DeepCopy() *PropagationRules {
+	if in == nil {
+		return nil
+	}
+	out := new(PropagationRules)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PublicCatalogConfig) DeepCopyInto(out *PublicCatalogConfig) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PublicCatalogConfig.
+func (in *PublicCatalogConfig) DeepCopy() *PublicCatalogConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(PublicCatalogConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResolverConfig) DeepCopyInto(out *ResolverConfig) {
+	*out = *in
+	if in.Targets != nil {
+		in, out := &in.Targets, &out.Targets
+		*out = make([]ResolverTarget, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResolverConfig.
+func (in *ResolverConfig) DeepCopy() *ResolverConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(ResolverConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResolverTarget) DeepCopyInto(out *ResolverTarget) {
+	*out = *in
+	if in.NATS != nil {
+		in, out := &in.NATS, &out.NATS
+		*out = new(NATSResolverTarget)
+		**out = **in
+	}
+	if in.KV != nil {
+		in, out := &in.KV, &out.KV
+		*out = new(KVResolverTarget)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResolverTarget.
+func (in *ResolverTarget) DeepCopy() *ResolverTarget {
+	if in == nil {
+		return nil
+	}
+	out := new(ResolverTarget)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SecretNameTemplates) DeepCopyInto(out *SecretNameTemplates) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SecretNameTemplates.
+func (in *SecretNameTemplates) DeepCopy() *SecretNameTemplates {
+	if in == nil {
+		return nil
+	}
+	out := new(SecretNameTemplates)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SecretRef) DeepCopyInto(out *SecretRef) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SecretRef.
+func (in *SecretRef) DeepCopy() *SecretRef {
+	if in == nil {
+		return nil
+	}
+	out := new(SecretRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SeedSecretRef) DeepCopyInto(out *SeedSecretRef) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SeedSecretRef.
+func (in *SeedSecretRef) DeepCopy() *SeedSecretRef {
+	if in == nil {
+		return nil
+	}
+	out := new(SeedSecretRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ServerAuthConfigRef) DeepCopyInto(out *ServerAuthConfigRef) {
+	*out = *in
+	if in.RolloutCheck != nil {
+		in, out := &in.RolloutCheck, &out.RolloutCheck
+		*out = new(ConfigRolloutCheck)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Includes != nil {
+		in, out := &in.Includes, &out.Includes
+		*out = make([]ConfigInclude, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServerAuthConfigRef.
@@ -495,36 +2052,211 @@ func (in *ServerAuthConfigRef) DeepCopy() *ServerAuthConfigRef {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *Permissions) DeepCopyInto(out *Permissions) {
+func (in *ServiceLatency) DeepCopyInto(out *ServiceLatency) {
 	*out = *in
-	if in.PublishAllow != nil {
-		in, out := &in.PublishAllow, &out.PublishAllow
-		*out = make([]string, len(*in))
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServiceLatency.
+func (in *ServiceLatency) DeepCopy() *ServiceLatency {
+	if in == nil {
+		return nil
+	}
+	out := new(ServiceLatency)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SigningKeyScope) DeepCopyInto(out *SigningKeyScope) {
+	*out = *in
+	if in.Permissions != nil {
+		in, out := &in.Permissions, &out.Permissions
+		*out = new(Permissions)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Limits != nil {
+		in, out := &in.Limits, &out.Limits
+		*out = new(UserLimits)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AllowedConnectionTypes != nil {
+		in, out := &in.AllowedConnectionTypes, &out.AllowedConnectionTypes
+		*out = make([]ConnectionType, len(*in))
 		copy(*out, *in)
 	}
-	if in.PublishDeny != nil {
-		in, out := &in.PublishDeny, &out.PublishDeny
-		*out = make([]string, len(*in))
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SigningKeyScope.
+func (in *SigningKeyScope) DeepCopy() *SigningKeyScope {
+	if in == nil {
+		return nil
+	}
+	out := new(SigningKeyScope)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SubjectMapping) DeepCopyInto(out *SubjectMapping) {
+	*out = *in
+	if in.Destinations != nil {
+		in, out := &in.Destinations, &out.Destinations
+		*out = make([]MappingDestination, len(*in))
 		copy(*out, *in)
 	}
-	if in.SubscribeAllow != nil {
-		in, out := &in.SubscribeAllow, &out.SubscribeAllow
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SubjectMapping.
+func (in *SubjectMapping) DeepCopy() *SubjectMapping {
+	if in == nil {
+		return nil
+	}
+	out := new(SubjectMapping)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UserClaimPolicy) DeepCopyInto(out *UserClaimPolicy) {
+	*out = *in
+	if in.SourceNetworks != nil {
+		in, out := &in.SourceNetworks, &out.SourceNetworks
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
-	if in.SubscribeDeny != nil {
-		in, out := &in.SubscribeDeny, &out.SubscribeDeny
+	if in.DefaultConnectionTypes != nil {
+		in, out := &in.DefaultConnectionTypes, &out.DefaultConnectionTypes
+		*out = make([]ConnectionType, len(*in))
+		copy(*out, *in)
+	}
+	if in.AllowedConnectionTypes != nil {
+		in, out := &in.AllowedConnectionTypes, &out.AllowedConnectionTypes
+		*out = make([]ConnectionType, len(*in))
+		copy(*out, *in)
+	}
+	if in.MaxExpiry != nil {
+		in, out := &in.MaxExpiry, &out.MaxExpiry
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UserClaimPolicy.
+func (in *UserClaimPolicy) DeepCopy() *UserClaimPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(UserClaimPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UserDefaults) DeepCopyInto(out *UserDefaults) {
+	*out = *in
+	if in.Expiry != nil {
+		in, out := &in.Expiry, &out.Expiry
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.ClockSkewTolerance != nil {
+		in, out := &in.ClockSkewTolerance, &out.ClockSkewTolerance
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.Limits != nil {
+		in, out := &in.Limits, &out.Limits
+		*out = new(UserLimits)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UserDefaults.
+func (in *UserDefaults) DeepCopy() *UserDefaults {
+	if in == nil {
+		return nil
+	}
+	out := new(UserDefaults)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UserLimits) DeepCopyInto(out *UserLimits) {
+	*out = *in
+	if in.DataSize != nil {
+		in, out := &in.DataSize, &out.DataSize
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.PayloadSize != nil {
+		in, out := &in.PayloadSize, &out.PayloadSize
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UserLimits.
+func (in *UserLimits) DeepCopy() *UserLimits {
+	if in == nil {
+		return nil
+	}
+	out := new(UserLimits)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UserTokenExchange) DeepCopyInto(out *UserTokenExchange) {
+	*out = *in
+	if in.ServiceAccounts != nil {
+		in, out := &in.ServiceAccounts, &out.ServiceAccounts
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.TTL != nil {
+		in, out := &in.TTL, &out.TTL
+		*out = new(v1.Duration)
+		**out = **in
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Permissions.
-func (in *Permissions) DeepCopy() *Permissions {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UserTokenExchange.
+func (in *UserTokenExchange) DeepCopy() *UserTokenExchange {
 	if in == nil {
 		return nil
 	}
-	out := new(Permissions)
+	out := new(UserTokenExchange)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WebsocketConfig) DeepCopyInto(out *WebsocketConfig) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WebsocketConfig.
+func (in *WebsocketConfig) DeepCopy() *WebsocketConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(WebsocketConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WorkloadRef) DeepCopyInto(out *WorkloadRef) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WorkloadRef.
+func (in *WorkloadRef) DeepCopy() *WorkloadRef {
+	if in == nil {
+		return nil
+	}
+	out := new(WorkloadRef)
 	in.DeepCopyInto(out)
 	return out
 }
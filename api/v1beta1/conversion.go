@@ -0,0 +1,175 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+
+	natsv1alpha1 "github.com/jradikk/nats-auth-operator/api/v1alpha1"
+)
+
+// defaultOperatorSeedKey is the key v1alpha1 defaults operatorSeedSecret.key to
+const defaultOperatorSeedKey = "operator.seed"
+
+// convertFields copies every field with the same JSON name from src to dst. Renamed
+// fields are dropped and must be set explicitly by the caller.
+func convertFields(src, dst interface{}) error {
+	data, err := json.Marshal(src)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %T: %w", src, err)
+	}
+	if err := json.Unmarshal(data, dst); err != nil {
+		return fmt.Errorf("failed to unmarshal into %T: %w", dst, err)
+	}
+	return nil
+}
+
+func seedSecretRefToHub(ref *SeedSecretRef) *natsv1alpha1.SeedSecretRef {
+	if ref == nil {
+		return nil
+	}
+	return &natsv1alpha1.SeedSecretRef{Name: ref.Name, Namespace: ref.Namespace, Key: ref.Key}
+}
+
+func seedSecretRefFromHub(ref *natsv1alpha1.SeedSecretRef) *SeedSecretRef {
+	if ref == nil {
+		return nil
+	}
+	return &SeedSecretRef{Name: ref.Name, Namespace: ref.Namespace, Key: ref.Key}
+}
+
+var _ conversion.Convertible = &NatsAuthConfig{}
+
+// ConvertTo converts this NatsAuthConfig to the hub version (v1alpha1)
+func (src *NatsAuthConfig) ConvertTo(dstRaw conversion.Hub) error {
+	dst := dstRaw.(*natsv1alpha1.NatsAuthConfig)
+	dst.ObjectMeta = src.ObjectMeta
+	if err := convertFields(&src.Spec, &dst.Spec); err != nil {
+		return err
+	}
+	if err := convertFields(&src.Status, &dst.Status); err != nil {
+		return err
+	}
+
+	// v1alpha1 requires an explicit namespace for the operator seed Secret
+	if src.Spec.JWT != nil && src.Spec.JWT.SeedSecretRef != nil {
+		ref := src.Spec.JWT.SeedSecretRef
+		dst.Spec.JWT.OperatorSeedSecret = &natsv1alpha1.OperatorSeedSecretRef{
+			Name:      ref.Name,
+			Namespace: ref.Namespace,
+			Key:       ref.Key,
+		}
+		if ref.Namespace == "" {
+			dst.Spec.JWT.OperatorSeedSecret.Namespace = src.Namespace
+		}
+		if ref.Key == "" {
+			dst.Spec.JWT.OperatorSeedSecret.Key = defaultOperatorSeedKey
+		}
+	}
+	return nil
+}
+
+// ConvertFrom converts from the hub version (v1alpha1) to this version
+func (dst *NatsAuthConfig) ConvertFrom(srcRaw conversion.Hub) error {
+	src := srcRaw.(*natsv1alpha1.NatsAuthConfig)
+	dst.ObjectMeta = src.ObjectMeta
+	if err := convertFields(&src.Spec, &dst.Spec); err != nil {
+		return err
+	}
+	if err := convertFields(&src.Status, &dst.Status); err != nil {
+		return err
+	}
+
+	if src.Spec.JWT != nil && src.Spec.JWT.OperatorSeedSecret != nil {
+		ref := src.Spec.JWT.OperatorSeedSecret
+		dst.Spec.JWT.SeedSecretRef = &SeedSecretRef{Name: ref.Name, Namespace: ref.Namespace, Key: ref.Key}
+	}
+	return nil
+}
+
+var _ conversion.Convertible = &NatsAccount{}
+
+// ConvertTo converts this NatsAccount to the hub version (v1alpha1)
+func (src *NatsAccount) ConvertTo(dstRaw conversion.Hub) error {
+	dst := dstRaw.(*natsv1alpha1.NatsAccount)
+	dst.ObjectMeta = src.ObjectMeta
+	if err := convertFields(&src.Spec, &dst.Spec); err != nil {
+		return err
+	}
+	if err := convertFields(&src.Status, &dst.Status); err != nil {
+		return err
+	}
+
+	dst.Spec.ExistingSeedSecret = seedSecretRefToHub(src.Spec.SeedSecretRef)
+	return nil
+}
+
+// ConvertFrom converts from the hub version (v1alpha1) to this version
+func (dst *NatsAccount) ConvertFrom(srcRaw conversion.Hub) error {
+	src := srcRaw.(*natsv1alpha1.NatsAccount)
+	dst.ObjectMeta = src.ObjectMeta
+	if err := convertFields(&src.Spec, &dst.Spec); err != nil {
+		return err
+	}
+	if err := convertFields(&src.Status, &dst.Status); err != nil {
+		return err
+	}
+
+	dst.Spec.SeedSecretRef = seedSecretRefFromHub(src.Spec.ExistingSeedSecret)
+	return nil
+}
+
+var _ conversion.Convertible = &NatsUser{}
+
+// ConvertTo converts this NatsUser to the hub version (v1alpha1)
+func (src *NatsUser) ConvertTo(dstRaw conversion.Hub) error {
+	dst := dstRaw.(*natsv1alpha1.NatsUser)
+	dst.ObjectMeta = src.ObjectMeta
+	if err := convertFields(&src.Spec, &dst.Spec); err != nil {
+		return err
+	}
+	if err := convertFields(&src.Status, &dst.Status); err != nil {
+		return err
+	}
+
+	dst.Spec.ExistingSeedSecret = seedSecretRefToHub(src.Spec.SeedSecretRef)
+	if ref := src.Spec.JWTSecretRef; ref != nil {
+		dst.Spec.ExistingJWTSecret = &natsv1alpha1.SecretRef{Name: ref.Name, Namespace: ref.Namespace}
+	}
+	return nil
+}
+
+// ConvertFrom converts from the hub version (v1alpha1) to this version
+func (dst *NatsUser) ConvertFrom(srcRaw conversion.Hub) error {
+	src := srcRaw.(*natsv1alpha1.NatsUser)
+	dst.ObjectMeta = src.ObjectMeta
+	if err := convertFields(&src.Spec, &dst.Spec); err != nil {
+		return err
+	}
+	if err := convertFields(&src.Status, &dst.Status); err != nil {
+		return err
+	}
+
+	dst.Spec.SeedSecretRef = seedSecretRefFromHub(src.Spec.ExistingSeedSecret)
+	if ref := src.Spec.ExistingJWTSecret; ref != nil {
+		dst.Spec.JWTSecretRef = &SecretRef{Name: ref.Name, Namespace: ref.Namespace}
+	}
+	return nil
+}
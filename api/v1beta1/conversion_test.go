@@ -0,0 +1,130 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+
+	natsv1alpha1 "github.com/jradikk/nats-auth-operator/api/v1alpha1"
+)
+
+var (
+	timeType     = reflect.TypeOf(metav1.Time{})
+	quantityType = reflect.TypeOf(resource.Quantity{})
+)
+
+// fill sets every exported field reachable from v to a non-zero value derived from its path, so a
+// field dropped by a conversion shows up as a difference after a round trip
+func fill(v reflect.Value, path string) {
+	switch v.Type() {
+	case timeType:
+		v.Set(reflect.ValueOf(metav1.NewTime(time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC))))
+		return
+	case quantityType:
+		v.Set(reflect.ValueOf(resource.MustParse("1Gi")))
+		return
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString("x-" + path)
+	case reflect.Bool:
+		v.SetBool(true)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		v.SetInt(int64(len(path)%100 + 1))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		v.SetUint(uint64(len(path)%100 + 1))
+	case reflect.Float32, reflect.Float64:
+		v.SetFloat(0.5)
+	case reflect.Pointer:
+		v.Set(reflect.New(v.Type().Elem()))
+		fill(v.Elem(), path)
+	case reflect.Slice:
+		v.Set(reflect.MakeSlice(v.Type(), 1, 1))
+		fill(v.Index(0), path+"[0]")
+	case reflect.Map:
+		v.Set(reflect.MakeMap(v.Type()))
+		key := reflect.New(v.Type().Key()).Elem()
+		fill(key, path+".key")
+		elem := reflect.New(v.Type().Elem()).Elem()
+		fill(elem, path+".value")
+		v.SetMapIndex(key, elem)
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Type().Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			fill(v.Field(i), path+"."+field.Name)
+		}
+	}
+}
+
+// roundTrip converts a populated hub object to v1beta1 and back and reports what was lost
+func roundTrip(t *testing.T, hub conversion.Hub, spoke conversion.Convertible, back conversion.Hub) {
+	t.Helper()
+
+	v := reflect.ValueOf(hub).Elem()
+	fill(v.FieldByName("Spec"), "spec")
+	fill(v.FieldByName("Status"), "status")
+	hub.(metav1.Object).SetName("example")
+	hub.(metav1.Object).SetNamespace("nats")
+
+	if err := spoke.ConvertFrom(hub); err != nil {
+		t.Fatalf("ConvertFrom() error = %v", err)
+	}
+	if err := spoke.ConvertTo(back); err != nil {
+		t.Fatalf("ConvertTo() error = %v", err)
+	}
+
+	for _, field := range []string{"Spec", "Status"} {
+		want := v.FieldByName(field).Interface()
+		got := reflect.ValueOf(back).Elem().FieldByName(field).Interface()
+		if !equality.Semantic.DeepEqual(want, got) {
+			t.Errorf("%s changed in the v1alpha1 -> v1beta1 -> v1alpha1 round trip:\nwant %s\ngot  %s", field, toJSON(want), toJSON(got))
+		}
+	}
+}
+
+func toJSON(v interface{}) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%+v", v)
+	}
+	return string(data)
+}
+
+func TestNatsAuthConfigRoundTrip(t *testing.T) {
+	roundTrip(t, &natsv1alpha1.NatsAuthConfig{}, &NatsAuthConfig{}, &natsv1alpha1.NatsAuthConfig{})
+}
+
+func TestNatsAccountRoundTrip(t *testing.T) {
+	roundTrip(t, &natsv1alpha1.NatsAccount{}, &NatsAccount{}, &natsv1alpha1.NatsAccount{})
+}
+
+func TestNatsUserRoundTrip(t *testing.T) {
+	roundTrip(t, &natsv1alpha1.NatsUser{}, &NatsUser{}, &natsv1alpha1.NatsUser{})
+}
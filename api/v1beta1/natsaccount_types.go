@@ -0,0 +1,524 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// AccountLimits defines limits for a NATS account
+type AccountLimits struct {
+	// Conn is the maximum number of connections (-1 for unlimited)
+	// +kubebuilder:default=-1
+	Conn int64 `json:"conn,omitempty"`
+
+	// Subs is the maximum number of subscriptions (-1 for unlimited)
+	// +kubebuilder:default=-1
+	Subs int64 `json:"subs,omitempty"`
+
+	// Payload is the maximum message payload size in bytes (-1 for unlimited)
+	// +kubebuilder:default=-1
+	Payload int64 `json:"payload,omitempty"`
+
+	// PayloadSize is the maximum message payload size as a quantity such as 1Mi; overrides payload
+	PayloadSize *resource.Quantity `json:"payloadSize,omitempty"`
+
+	// Data is the maximum data size in bytes (-1 for unlimited)
+	// +kubebuilder:default=-1
+	Data int64 `json:"data,omitempty"`
+
+	// DataSize is the maximum data size as a quantity such as 10Gi; overrides data
+	DataSize *resource.Quantity `json:"dataSize,omitempty"`
+
+	// Exports is the maximum number of exports (-1 for unlimited)
+	// +kubebuilder:default=-1
+	Exports int64 `json:"exports,omitempty"`
+
+	// Imports is the maximum number of imports (-1 for unlimited)
+	// +kubebuilder:default=-1
+	Imports int64 `json:"imports,omitempty"`
+
+	// WildcardExports whether wildcards are allowed in exports
+	// +kubebuilder:default=true
+	WildcardExports bool `json:"wildcardExports,omitempty"`
+
+	// JetStream defines JetStream-specific limits
+	JetStream *JetStreamLimits `json:"jetstream,omitempty"`
+
+	// JetStreamTiers defines JetStream limits per replication tier, keyed by tier name (R1, R3, ...).
+	// Servers apply tiered limits instead of the global jetstream limits, so the two are mutually exclusive.
+	JetStreamTiers map[string]JetStreamLimits `json:"jetstreamTiers,omitempty"`
+}
+
+// JetStreamLimits defines JetStream resource limits for an account
+type JetStreamLimits struct {
+	// MemoryStorage is the max number of bytes stored in memory across all streams (-1 for unlimited, 0 to disable)
+	MemoryStorage int64 `json:"memoryStorage,omitempty"`
+
+	// MemoryStorageSize is memoryStorage as a quantity such as 512Mi; overrides memoryStorage
+	MemoryStorageSize *resource.Quantity `json:"memoryStorageSize,omitempty"`
+
+	// DiskStorage is the max number of bytes stored on disk across all streams (-1 for unlimited, 0 to disable)
+	DiskStorage int64 `json:"diskStorage,omitempty"`
+
+	// DiskStorageSize is diskStorage as a quantity such as 10Gi; overrides diskStorage
+	DiskStorageSize *resource.Quantity `json:"diskStorageSize,omitempty"`
+
+	// Streams is the maximum number of streams (-1 for unlimited)
+	Streams int64 `json:"streams,omitempty"`
+
+	// Consumer is the maximum number of consumers (-1 for unlimited)
+	Consumer int64 `json:"consumer,omitempty"`
+
+	// MaxAckPending is the maximum number of outstanding acks per stream (-1 for unlimited)
+	MaxAckPending int64 `json:"maxAckPending,omitempty"`
+
+	// MemoryMaxStreamBytes is the max bytes a memory backed stream can have (-1 for unlimited, 0 to disable)
+	MemoryMaxStreamBytes int64 `json:"memoryMaxStreamBytes,omitempty"`
+
+	// MemoryMaxStreamSize is memoryMaxStreamBytes as a quantity such as 256Mi; overrides memoryMaxStreamBytes
+	MemoryMaxStreamSize *resource.Quantity `json:"memoryMaxStreamSize,omitempty"`
+
+	// DiskMaxStreamBytes is the max bytes a disk backed stream can have (-1 for unlimited, 0 to disable)
+	DiskMaxStreamBytes int64 `json:"diskMaxStreamBytes,omitempty"`
+
+	// DiskMaxStreamSize is diskMaxStreamBytes as a quantity such as 1Gi; overrides diskMaxStreamBytes
+	DiskMaxStreamSize *resource.Quantity `json:"diskMaxStreamSize,omitempty"`
+
+	// MaxBytesRequired requires max_bytes to be set when creating streams
+	MaxBytesRequired bool `json:"maxBytesRequired,omitempty"`
+}
+
+// ReconcileStep is a checkpoint in the key issuing flow of an account or user
+// +kubebuilder:validation:Enum=SeedCreated;ClaimsBuilt;Signed;SecretWritten;ResolverUpdated
+type ReconcileStep string
+
+const (
+	// ReconcileStepSeedCreated means the seed is persisted in the JWT/credentials Secret
+	ReconcileStepSeedCreated ReconcileStep = "SeedCreated"
+	// ReconcileStepClaimsBuilt means the claims were built from the spec
+	ReconcileStepClaimsBuilt ReconcileStep = "ClaimsBuilt"
+	// ReconcileStepSigned means the JWT was signed
+	ReconcileStepSigned ReconcileStep = "Signed"
+	// ReconcileStepSecretWritten means the signed JWT was written to the Secret
+	ReconcileStepSecretWritten ReconcileStep = "SecretWritten"
+	// ReconcileStepResolverUpdated means the server auth config was refreshed (accounts only)
+	ReconcileStepResolverUpdated ReconcileStep = "ResolverUpdated"
+)
+
+// Phase summarizes the Ready condition of a resource for kubectl output
+// +kubebuilder:validation:Enum=Pending;Ready;Error
+type Phase string
+
+const (
+	// PhasePending means the resource waits for a dependency
+	PhasePending Phase = "Pending"
+	// PhaseReady means the resource was reconciled successfully
+	PhaseReady Phase = "Ready"
+	// PhaseError means the last reconcile failed
+	PhaseError Phase = "Error"
+)
+
+// ReasonCode is the machine-readable reason of the Ready condition, mirrored in status.reason.
+// Permanent reasons need a change to the resource or its inputs and are retried slowly;
+// the others are retried with backoff.
+type ReasonCode string
+
+const (
+	// ReasonReconcileSuccess means the resource was reconciled successfully
+	ReasonReconcileSuccess ReasonCode = "ReconcileSuccess"
+	// ReasonDependencyNotReady means a referenced resource is missing or not ready yet (transient)
+	ReasonDependencyNotReady ReasonCode = "DependencyNotReady"
+	// ReasonAuthConfigNotFound means the referenced NatsAuthConfig does not exist (transient)
+	ReasonAuthConfigNotFound ReasonCode = "AuthConfigNotFound"
+	// ReasonConflict means a write raced with another update (transient)
+	ReasonConflict ReasonCode = "Conflict"
+	// ReasonReconcileError is any other failure, such as an unreachable API server or hook (transient)
+	ReasonReconcileError ReasonCode = "ReconcileError"
+	// ReasonInvalidSpec means the spec is invalid (permanent)
+	ReasonInvalidSpec ReasonCode = "InvalidSpec"
+	// ReasonIncompatibleAuthMode means the spec does not fit the mode of the NatsAuthConfig (permanent)
+	ReasonIncompatibleAuthMode ReasonCode = "IncompatibleAuthMode"
+	// ReasonInvalidSeed means a referenced Secret does not hold a valid nkey seed (permanent)
+	ReasonInvalidSeed ReasonCode = "InvalidSeed"
+	// ReasonIssuerMismatch means an externally issued JWT is signed by the wrong account (permanent)
+	ReasonIssuerMismatch ReasonCode = "IssuerMismatch"
+	// ReasonClaimsRejected means a claim hook rejected the claims (permanent)
+	ReasonClaimsRejected ReasonCode = "ClaimsRejected"
+	// ReasonConfigDrift means the server auth config was edited under driftPolicy Alert (permanent)
+	ReasonConfigDrift ReasonCode = "ConfigDrift"
+	// ReasonFieldManagerConflict means another field manager owns keys of a written object (permanent)
+	ReasonFieldManagerConflict ReasonCode = "FieldManagerConflict"
+	// ReasonServerAuthConfigConflict means another NatsAuthConfig writes the same server auth config (permanent)
+	ReasonServerAuthConfigConflict ReasonCode = "ServerAuthConfigConflict"
+	// ReasonSecretNameCollision means a Secret to be written exists and belongs to something else (permanent)
+	ReasonSecretNameCollision ReasonCode = "SecretNameCollision"
+	// ReasonUsernameConflict means an older NatsUser of the NatsAuthConfig holds the username (permanent)
+	ReasonUsernameConflict ReasonCode = "UsernameConflict"
+	// ReasonConfigRolloutPending means the NATS server pods have not loaded the written server auth config yet (transient)
+	ReasonConfigRolloutPending ReasonCode = "ConfigRolloutPending"
+	// ReasonConfigTooLarge means the server auth config exceeds the size limit of a Secret or ConfigMap (permanent)
+	ReasonConfigTooLarge ReasonCode = "ConfigTooLarge"
+	// ReasonConfigSyntaxError means the rendered server auth config does not parse, e.g. after a template override (permanent)
+	ReasonConfigSyntaxError ReasonCode = "ConfigSyntaxError"
+	// ReasonShutdownPending means the operator stopped before the latest changes were written; the next operator instance writes them (transient)
+	ReasonShutdownPending ReasonCode = "ShutdownPending"
+)
+
+// SecretRef references a Kubernetes Secret
+type SecretRef struct {
+	// Name of the Secret
+	Name string `json:"name,omitempty"`
+
+	// Namespace of the Secret (defaults to the namespace of the referencing resource)
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// SeedSecretRef references an nkey seed stored in a Secret
+type SeedSecretRef struct {
+	// Name of the Secret
+	Name string `json:"name,omitempty"`
+
+	// Namespace of the Secret (defaults to the namespace of the referencing resource)
+	Namespace string `json:"namespace,omitempty"`
+
+	// Key within the Secret. Without a key the canonical key (account.seed or user.seed) is used,
+	// falling back to the legacy keys seed.nk, seed, nkey and <type>.nk.
+	// +optional
+	Key string `json:"key,omitempty"`
+}
+
+// NatsAuthConfigRef references a NatsAuthConfig
+type NatsAuthConfigRef struct {
+	// Name of the NatsAuthConfig
+	// +kubebuilder:validation:Required
+	Name string `json:"name"`
+
+	// Namespace of the NatsAuthConfig (defaults to same namespace)
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// ExportType is the kind of subject an account exports or imports
+// +kubebuilder:validation:Enum=stream;service
+type ExportType string
+
+const (
+	// ExportTypeStream shares messages published on the subject
+	ExportTypeStream ExportType = "stream"
+	// ExportTypeService shares a request/reply service on the subject
+	ExportTypeService ExportType = "service"
+)
+
+// ResponseType is how a service export answers requests
+// +kubebuilder:validation:Enum=Singleton;Stream;Chunked
+type ResponseType string
+
+const (
+	// ResponseTypeSingleton answers each request with a single message
+	ResponseTypeSingleton ResponseType = "Singleton"
+	// ResponseTypeStream answers each request with any number of messages
+	ResponseTypeStream ResponseType = "Stream"
+	// ResponseTypeChunked answers each request with a single response sent in several messages
+	ResponseTypeChunked ResponseType = "Chunked"
+)
+
+// ServiceLatency enables latency tracking of a service export
+type ServiceLatency struct {
+	// Sampling is the percentage of requests tracked, e.g. "100" or "10%", or "headers" to track
+	// only requests carrying tracing headers
+	// +kubebuilder:validation:Pattern=`^(headers|100%?|[1-9][0-9]?%?)$`
+	// +kubebuilder:default="100"
+	Sampling string `json:"sampling,omitempty"`
+
+	// Results is the subject latency metrics are published on (no wildcards)
+	// +kubebuilder:validation:Required
+	Results string `json:"results"`
+}
+
+// AccountExport makes a subject of the account available to other accounts
+type AccountExport struct {
+	// Type is stream or service
+	// +kubebuilder:validation:Required
+	Type ExportType `json:"type"`
+
+	// Subject is the exported subject (wildcards allowed)
+	// +kubebuilder:validation:Required
+	Subject string `json:"subject"`
+
+	// Accounts restricts the export to these NatsAccounts (public when empty). In JWT mode a
+	// restricted export requires an activation token, which the importing account is issued.
+	Accounts []NatsAccountRef `json:"accounts,omitempty"`
+
+	// ResponseType is how the service answers requests (service exports only, defaults to Singleton)
+	ResponseType ResponseType `json:"responseType,omitempty"`
+
+	// Latency tracks the latency of service requests (service exports only)
+	Latency *ServiceLatency `json:"latency,omitempty"`
+
+	// Advertise lists the export in the account JWT for discovery by other accounts (JWT mode)
+	Advertise bool `json:"advertise,omitempty"`
+}
+
+// AccountImport brings a subject exported by another account into this account
+type AccountImport struct {
+	// Type is stream or service
+	// +kubebuilder:validation:Required
+	Type ExportType `json:"type"`
+
+	// AccountRef references the exporting NatsAccount
+	// +kubebuilder:validation:Required
+	AccountRef NatsAccountRef `json:"accountRef"`
+
+	// Subject is the subject exported by the other account
+	// +kubebuilder:validation:Required
+	Subject string `json:"subject"`
+
+	// Prefix is prepended to imported stream subjects (stream imports only)
+	Prefix string `json:"prefix,omitempty"`
+
+	// To is the local subject the service is imported as (service imports only)
+	To string `json:"to,omitempty"`
+}
+
+// AccountSigningKey is an account signing key NatsUsers select by role
+type AccountSigningKey struct {
+	// Role names the key; NatsUsers are signed with it by setting spec.signingKeyRole
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MaxLength=63
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	Role string `json:"role"`
+
+	// Scope makes the key a scoped signing key: users it signs get the permissions of the
+	// scope from the account JWT and must not set permissions of their own (optional)
+	Scope *SigningKeyScope `json:"scope,omitempty"`
+}
+
+// SigningKeyScope is the user permission template of a scoped signing key
+type SigningKeyScope struct {
+	// Permissions of the users signed with the key
+	Permissions *Permissions `json:"permissions,omitempty"`
+
+	// Limits of the users signed with the key
+	Limits *UserLimits `json:"limits,omitempty"`
+
+	// AllowedConnectionTypes restricts the connection types of the users signed with the key
+	AllowedConnectionTypes []ConnectionType `json:"allowedConnectionTypes,omitempty"`
+}
+
+// SubjectMapping routes messages published on a subject to one or more destination subjects
+type SubjectMapping struct {
+	// Subject is the published subject (wildcards allowed)
+	// +kubebuilder:validation:Required
+	Subject string `json:"subject"`
+
+	// Destinations receive the messages, each for its weight of the traffic. Weights add up to
+	// at most 100; the remaining traffic keeps the original subject.
+	// +kubebuilder:validation:MinItems=1
+	Destinations []MappingDestination `json:"destinations"`
+}
+
+// MappingDestination is a destination subject of a subject mapping
+type MappingDestination struct {
+	// Subject is the destination subject; it may reference wildcards of the mapped subject,
+	// e.g. "orders.v2.{{wildcard(1)}}"
+	// +kubebuilder:validation:Required
+	Subject string `json:"subject"`
+
+	// Weight is the percentage of messages sent to this destination (defaults to 100)
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	Weight int32 `json:"weight,omitempty"`
+
+	// Cluster applies the destination only to messages published in this cluster
+	Cluster string `json:"cluster,omitempty"`
+}
+
+// NatsAccountSpec defines the desired state of NatsAccount
+type NatsAccountSpec struct {
+	// AuthConfigRef references the NatsAuthConfig
+	// +kubebuilder:validation:Required
+	AuthConfigRef NatsAuthConfigRef `json:"authConfigRef"`
+
+	// Description of the account
+	Description string `json:"description,omitempty"`
+
+	// InfoURL links to documentation or an owner page for the account (JWT mode)
+	// +kubebuilder:validation:Pattern=`^https?://.+`
+	InfoURL string `json:"infoURL,omitempty"`
+
+	// Contact identifies the owner of the account, e.g. a team or email address (JWT mode).
+	// Stored as the "contact:<value>" tag of the account JWT.
+	Contact string `json:"contact,omitempty"`
+
+	// Metadata is stored as "key:value" tags of the account JWT (JWT mode).
+	// NATS lowercases tags, so keys must be lowercase and values are stored lowercased.
+	Metadata map[string]string `json:"metadata,omitempty"`
+
+	// Limits defines resource limits for this account
+	Limits *AccountLimits `json:"limits,omitempty"`
+
+	// SeedSecretRef references an existing account seed (optional, key defaults to account.seed)
+	SeedSecretRef *SeedSecretRef `json:"seedSecretRef,omitempty"`
+
+	// Exports lists the streams and services shared with other accounts
+	Exports []AccountExport `json:"exports,omitempty"`
+
+	// Imports lists the streams and services taken from other accounts. In JWT mode the
+	// exporting account must belong to the same NatsAuthConfig and export the subject.
+	Imports []AccountImport `json:"imports,omitempty"`
+
+	// Mappings route published subjects to weighted destination subjects, e.g. to send a share
+	// of the traffic to a canary version of a service
+	Mappings []SubjectMapping `json:"mappings,omitempty"`
+
+	// SigningKeys are account signing keys NatsUsers can be issued with instead of the account
+	// key (JWT mode). Their seeds are generated and kept in the account JWT Secret.
+	// +listType=map
+	// +listMapKey=role
+	SigningKeys []AccountSigningKey `json:"signingKeys,omitempty"`
+
+	// PropagateLabels selects labels copied from this resource to the NatsAccount JWT Secret,
+	// e.g. app.kubernetes.io/part-of. An entry ending in "*" matches all keys with that prefix.
+	// Adds to the propagation rules of the NatsOperatorSettings.
+	PropagateLabels []string `json:"propagateLabels,omitempty"`
+
+	// PropagateAnnotations selects annotations copied to the NatsAccount JWT Secret, like propagateLabels
+	PropagateAnnotations []string `json:"propagateAnnotations,omitempty"`
+
+	// ResyncInterval overrides the operator-wide periodic resync interval for this resource.
+	// Set to "0s" to disable periodic resync and rely on watches only.
+	ResyncInterval *metav1.Duration `json:"resyncInterval,omitempty"`
+
+	// Paused stops reconciling the NatsAccount, so its JWT is neither re-signed nor rewritten
+	// until it is unset. The Paused condition reports it.
+	// +optional
+	Paused bool `json:"paused,omitempty"`
+}
+
+// AccountUser identifies a NatsUser issued under the account
+type AccountUser struct {
+	// Name of the NatsUser
+	Name string `json:"name"`
+
+	// Namespace of the NatsUser
+	Namespace string `json:"namespace"`
+
+	// PublicKey of the user (empty until the user JWT is issued)
+	PublicKey string `json:"publicKey,omitempty"`
+}
+
+// NatsAccountStatus defines the observed state of NatsAccount
+type NatsAccountStatus struct {
+	// AccountID is the public key of the account
+	AccountID string `json:"accountId,omitempty"`
+
+	// PublicKey is the public key of the account (same as AccountID)
+	PublicKey string `json:"publicKey,omitempty"`
+
+	// JWTSecretRef references the Secret containing the account JWT
+	JWTSecretRef SecretRef `json:"jwtSecretRef,omitempty"`
+
+	// ClaimsHash is a hash of the name, description and limits in the issued account JWT.
+	// The JWT is re-signed and re-pushed whenever the spec no longer matches it.
+	ClaimsHash string `json:"claimsHash,omitempty"`
+
+	// OperatorKeyHash is the NatsAuthConfig operatorKeyHash the account JWT was last signed under.
+	// The JWT is re-signed when the operator keys change.
+	OperatorKeyHash string `json:"operatorKeyHash,omitempty"`
+
+	// OperatorClaimsHash is the NatsAuthConfig operatorClaimsHash the account JWT was last signed
+	// under. The JWT is re-signed when the operator claims change.
+	OperatorClaimsHash string `json:"operatorClaimsHash,omitempty"`
+
+	// JWTFingerprint is the hex SHA-256 of the account JWT in the JWT Secret. A Secret holding
+	// another JWT has drifted and the JWT is re-signed.
+	JWTFingerprint string `json:"jwtFingerprint,omitempty"`
+
+	// IssuedAt is the issue time of the account JWT
+	IssuedAt *metav1.Time `json:"issuedAt,omitempty"`
+
+	// ExpiresAt is the expiry of the account JWT; unset when the JWT does not expire
+	ExpiresAt *metav1.Time `json:"expiresAt,omitempty"`
+
+	// LastCompletedStep is the last checkpoint reached while issuing the account JWT.
+	// A reconcile interrupted before ResolverUpdated resumes from the persisted seed.
+	LastCompletedStep ReconcileStep `json:"lastCompletedStep,omitempty"`
+
+	// Users lists the NatsUsers referencing this account, ordered by namespace and name
+	Users []AccountUser `json:"users,omitempty"`
+
+	// UserCount is the number of NatsUsers referencing this account
+	UserCount int32 `json:"userCount,omitempty"`
+
+	// RevokedUsers maps revoked user public keys to the unix time of revocation.
+	// Entries are carried into the account JWT revocation list.
+	RevokedUsers map[string]int64 `json:"revokedUsers,omitempty"`
+
+	// SigningKeys maps the role of each account signing key to its public key
+	SigningKeys map[string]string `json:"signingKeys,omitempty"`
+
+	// Phase summarizes the Ready condition (Pending, Ready or Error)
+	Phase Phase `json:"phase,omitempty"`
+
+	// Reason is the machine-readable reason of the Ready condition
+	Reason ReasonCode `json:"reason,omitempty"`
+
+	// Message is the human-readable message of the Ready condition
+	Message string `json:"message,omitempty"`
+
+	// Conditions represent the latest available observations of the object's state
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// ObservedGeneration reflects the generation of the most recently observed NatsAccount
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// LastReconciled is the timestamp of the last reconciliation
+	LastReconciled *metav1.Time `json:"lastReconciled,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Namespaced
+// +kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
+// +kubebuilder:printcolumn:name="Account ID",type=string,JSONPath=`.status.accountId`
+// +kubebuilder:printcolumn:name="Users",type=integer,JSONPath=`.status.userCount`
+// +kubebuilder:printcolumn:name="Secret",type=string,JSONPath=`.status.jwtSecretRef.name`,priority=1
+// +kubebuilder:printcolumn:name="Message",type=string,JSONPath=`.status.message`,priority=1
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// NatsAccount is the Schema for the natsaccounts API
+type NatsAccount struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   NatsAccountSpec   `json:"spec,omitempty"`
+	Status NatsAccountStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// NatsAccountList contains a list of NatsAccount
+type NatsAccountList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []NatsAccount `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&NatsAccount{}, &NatsAccountList{})
+}
@@ -0,0 +1,777 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// AuthMode defines the authentication mode for NATS
+// +kubebuilder:validation:Enum=token;jwt;mixed
+type AuthMode string
+
+const (
+	AuthModeToken AuthMode = "token"
+	AuthModeJWT   AuthMode = "jwt"
+	// AuthModeMixed renders the JWT config like AuthModeJWT.
+	//
+	// Deprecated: servers configured with an operator refuse users defined in the config, so
+	// token users cannot be served next to JWT accounts. Use AuthModeJWT.
+	AuthModeMixed AuthMode = "mixed"
+)
+
+// AccountKeyFormat defines how account JWTs are keyed in the server auth Secret
+// +kubebuilder:validation:Enum=Name;PublicKey;NameAndPublicKey
+type AccountKeyFormat string
+
+const (
+	// AccountKeyFormatName keys account JWTs by NatsAccount name
+	AccountKeyFormatName AccountKeyFormat = "Name"
+	// AccountKeyFormatPublicKey keys account JWTs by account public key
+	AccountKeyFormatPublicKey AccountKeyFormat = "PublicKey"
+	// AccountKeyFormatNameAndPublicKey writes both keys, for migrating from Name to PublicKey
+	AccountKeyFormatNameAndPublicKey AccountKeyFormat = "NameAndPublicKey"
+)
+
+// DriftPolicy defines how a server auth config that no longer matches its signature is handled
+// +kubebuilder:validation:Enum=Restore;Alert
+type DriftPolicy string
+
+const (
+	// DriftPolicyRestore rewrites the tampered config and emits a ConfigDriftDetected event
+	DriftPolicyRestore DriftPolicy = "Restore"
+	// DriftPolicyAlert leaves the tampered config in place and stops writing until it is resolved
+	DriftPolicyAlert DriftPolicy = "Alert"
+)
+
+// DeletionPolicy defines what happens to the server auth config when the NatsAuthConfig is deleted
+// +kubebuilder:validation:Enum=Retain;Delete
+type DeletionPolicy string
+
+const (
+	// DeletionPolicyRetain leaves the server auth config in place
+	DeletionPolicyRetain DeletionPolicy = "Retain"
+	// DeletionPolicyDelete deletes the server auth config with the NatsAuthConfig
+	DeletionPolicyDelete DeletionPolicy = "Delete"
+)
+
+// SizePolicy defines what happens when the JWT-mode server auth Secret outgrows the 1MiB limit
+// of a Secret
+// +kubebuilder:validation:Enum=Fail;Chunk
+type SizePolicy string
+
+const (
+	// SizePolicyFail leaves the live Secret in place and sets Ready to false with ConfigTooLarge
+	SizePolicyFail SizePolicy = "Fail"
+	// SizePolicyChunk moves the account JWTs that do not fit into overflow Secrets
+	SizePolicyChunk SizePolicy = "Chunk"
+)
+
+// ConfigInclude names a split include file of the server auth config
+// +kubebuilder:validation:Enum=operator;resolver;preload;authorization
+type ConfigInclude string
+
+const (
+	// ConfigIncludeOperator writes the operator setting to operator.conf (JWT and mixed mode)
+	ConfigIncludeOperator ConfigInclude = "operator"
+	// ConfigIncludeResolver writes a full resolver block using jwt.resolverDir to resolver.conf (JWT and mixed mode)
+	ConfigIncludeResolver ConfigInclude = "resolver"
+	// ConfigIncludePreload writes resolver_preload with every account JWT to preload.conf (JWT and mixed mode)
+	ConfigIncludePreload ConfigInclude = "preload"
+	// ConfigIncludeAuthorization writes the authorization block of the users outside any account
+	// to authorization.conf (token mode)
+	ConfigIncludeAuthorization ConfigInclude = "authorization"
+)
+
+// ServerAuthConfigRef defines where to write the server auth configuration
+type ServerAuthConfigRef struct {
+	// Name of the ConfigMap or Secret
+	// +kubebuilder:validation:Required
+	Name string `json:"name"`
+
+	// Namespace of the ConfigMap or Secret
+	// +kubebuilder:validation:Required
+	Namespace string `json:"namespace"`
+
+	// Key within the ConfigMap or Secret
+	// +kubebuilder:default="auth.conf"
+	Key string `json:"key,omitempty"`
+
+	// Type of the resource (ConfigMap or Secret)
+	// +kubebuilder:validation:Enum=ConfigMap;Secret
+	// +kubebuilder:default="ConfigMap"
+	Type string `json:"type,omitempty"`
+
+	// AccountKeyFormat defines how account JWTs are keyed in the server auth Secret (JWT mode).
+	// Name keys collide when accounts in different namespaces share a name; PublicKey avoids this.
+	// The nats.jradikk/account-index annotation maps namespace/name to public key.
+	// +kubebuilder:default="Name"
+	AccountKeyFormat AccountKeyFormat `json:"accountKeyFormat,omitempty"`
+
+	// DriftPolicy defines what happens when the written config no longer matches the signature
+	// stored next to it under <key>.sig, e.g. after a manual edit. Restore rewrites it; Alert
+	// leaves it in place, sets Ready to false and stops writing until the drift is resolved.
+	// +kubebuilder:default="Restore"
+	DriftPolicy DriftPolicy `json:"driftPolicy,omitempty"`
+
+	// DeletionPolicy defines what happens to the ConfigMap or Secret when the NatsAuthConfig is
+	// deleted. Retain leaves it in place for the running servers; Delete removes it, through an
+	// owner reference in the NatsAuthConfig namespace or the NatsAuthConfig finalizer elsewhere.
+	// +kubebuilder:default="Retain"
+	DeletionPolicy DeletionPolicy `json:"deletionPolicy,omitempty"`
+
+	// RolloutCheck holds back ResolverReady and Ready until the NATS server pods run the written
+	// config (optional)
+	RolloutCheck *ConfigRolloutCheck `json:"rolloutCheck,omitempty"`
+
+	// Includes writes the listed settings to separate keys next to the usual ones, so the config of
+	// each server role can include only what it needs, e.g. leafnodes without preload.conf (optional)
+	// +listType=set
+	Includes []ConfigInclude `json:"includes,omitempty"`
+
+	// SizePolicy defines what happens once the server auth Secret nears the 1MiB Secret limit (JWT
+	// and mixed mode). Fail stops writing it when it exceeds the limit; Chunk spreads the account
+	// JWTs over overflow Secrets <name>-1, <name>-2, ... whose keys never collide, so a projected
+	// volume can mount them all into one directory.
+	// +kubebuilder:default="Fail"
+	SizePolicy SizePolicy `json:"sizePolicy,omitempty"`
+
+	// TemplatesConfigMap names a ConfigMap in the NatsAuthConfig namespace whose keys replace the
+	// built-in Go templates of the token-mode auth config: auth.conf, no_auth_user, authorization,
+	// accounts and users. Other keys add templates the replacements can include (optional).
+	// +kubebuilder:validation:MaxLength=253
+	TemplatesConfigMap string `json:"templatesConfigMap,omitempty"`
+}
+
+// ConfigRolloutCheck confirms the NATS server pods loaded the written server auth config. Each
+// selected pod must be ready and carry the config hash the operator records in the
+// nats.jradikk/config-hash annotation of the server auth config.
+type ConfigRolloutCheck struct {
+	// PodSelector selects the NATS server pods in the namespace of the server auth config
+	// +kubebuilder:validation:Required
+	PodSelector metav1.LabelSelector `json:"podSelector"`
+
+	// Annotation is the pod annotation holding the hash of the config the pod loaded
+	// +kubebuilder:default="nats.jradikk/config-hash"
+	Annotation string `json:"annotation,omitempty"`
+}
+
+// JWTConfig defines JWT-specific configuration
+type JWTConfig struct {
+	// ResolverDir is the directory path where the resolver is stored
+	// +kubebuilder:default="/var/lib/nats-resolver"
+	ResolverDir string `json:"resolverDir,omitempty"`
+
+	// SeedSecretRef references an existing operator seed (optional, key defaults to operator.seed)
+	SeedSecretRef *SeedSecretRef `json:"seedSecretRef,omitempty"`
+
+	// OperatorName is the name of the NATS operator
+	// +kubebuilder:default="NATS Operator"
+	OperatorName string `json:"operatorName,omitempty"`
+
+	// AccountServerURL is set on the operator claims so nsc and the nats-resolver
+	// know where accounts are pushed (e.g. nats://nats:4222)
+	AccountServerURL string `json:"accountServerURL,omitempty"`
+
+	// OperatorServiceURLs are the nats:// or tls:// URLs of the servers run by this operator
+	OperatorServiceURLs []string `json:"operatorServiceURLs,omitempty"`
+
+	// StrictSigningKeyUsage requires account JWTs to be signed by an operator signing key.
+	// The operator then generates a signing key (stored in <name>-operator-signing-key)
+	// and signs accounts with it instead of the operator identity key.
+	StrictSigningKeyUsage bool `json:"strictSigningKeyUsage,omitempty"`
+
+	// Tags are set on the operator claims
+	Tags []string `json:"tags,omitempty"`
+
+	// PinnedAccounts renders resolver_pinned_accounts under the pinned-accounts.conf key of the
+	// server auth config, so servers including it only accept the listed accounts (optional)
+	PinnedAccounts *PinnedAccountsConfig `json:"pinnedAccounts,omitempty"`
+
+	// Signer sends every signature made with the operator and account keys to an external
+	// signing service, e.g. in front of an HSM, so their seeds never reach the operator (optional).
+	// It cannot be combined with a seed Secret, strictSigningKeyUsage or account signing keys.
+	Signer *ExternalSigner `json:"signer,omitempty"`
+
+	// Publish mirrors every signed account JWT to the store of an external account resolver,
+	// e.g. a nats-account-server or an object storage bucket (optional)
+	Publish *AccountPublishing `json:"publish,omitempty"`
+
+	// Resolver writes account JWTs to several resolver destinations at once, e.g. the server auth
+	// config Secret for a cache resolver and a full-resolver directory. Without it account JWTs are
+	// only written to the server auth config Secret (optional).
+	Resolver *ResolverConfig `json:"resolver,omitempty"`
+}
+
+// AccountPublishing defines where account JWTs are mirrored to. A JWT is published again when it
+// changes or the targets change.
+type AccountPublishing struct {
+	// AccountServer posts account JWTs to a nats-account-server
+	AccountServer *AccountServerTarget `json:"accountServer,omitempty"`
+
+	// Bucket uploads account JWTs to an S3-compatible bucket
+	Bucket *BucketTarget `json:"bucket,omitempty"`
+}
+
+// ResolverTargetType names a destination of account JWTs
+// +kubebuilder:validation:Enum=Secret;Directory;NATS;Agent;KV
+type ResolverTargetType string
+
+const (
+	// ResolverTargetSecret writes account JWTs as keys of the server auth config Secret and to the
+	// preload include, for servers running a memory or cache resolver
+	ResolverTargetSecret ResolverTargetType = "Secret"
+	// ResolverTargetDirectory writes account JWTs to a full-resolver directory mounted into the operator
+	ResolverTargetDirectory ResolverTargetType = "Directory"
+	// ResolverTargetNATS pushes account JWTs to running servers over the system account
+	ResolverTargetNATS ResolverTargetType = "NATS"
+	// ResolverTargetAgent writes account JWTs as keys of the server auth config Secret, without
+	// the preload include, for the resolver agent sidecar to materialize in the servers'
+	// full-resolver directory
+	ResolverTargetAgent ResolverTargetType = "Agent"
+	// ResolverTargetKV puts account JWTs into a JetStream key-value bucket, for the resolver
+	// agent of clusters that cannot reach the Kubernetes API
+	ResolverTargetKV ResolverTargetType = "KV"
+)
+
+// ResolverConfig lists the destinations account JWTs are written to in one reconcile, e.g. the
+// preload Secret of a cache resolver and the directory of a full resolver at once
+type ResolverConfig struct {
+	// Targets account JWTs are written to. The server auth config Secret only holds the account
+	// JWTs when a Secret or Agent target is listed, and the preload include when a Secret target
+	// is listed.
+	// +kubebuilder:validation:MinItems=1
+	Targets []ResolverTarget `json:"targets"`
+}
+
+// ResolverTarget is one destination of account JWTs
+type ResolverTarget struct {
+	// Type of the target
+	// +kubebuilder:validation:Required
+	Type ResolverTargetType `json:"type"`
+
+	// Dir is the full-resolver directory as mounted into the operator pod, for the Directory
+	// type (defaults to jwt.resolverDir)
+	Dir string `json:"dir,omitempty"`
+
+	// NATS is the server account JWTs are pushed to, required for the NATS type, or the server
+	// the bucket is reached through, required for the KV type
+	NATS *NATSResolverTarget `json:"nats,omitempty"`
+
+	// KV is the key-value bucket account JWTs are put into, required for the KV type
+	KV *KVResolverTarget `json:"kv,omitempty"`
+}
+
+// KVResolverTarget is a JetStream key-value bucket holding the operator JWT under operator and
+// each account JWT under accounts.<public key>
+type KVResolverTarget struct {
+	// Bucket is the name of the bucket, which must exist; the user of nats.credentialsSecret
+	// needs to publish to it
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Pattern=`^[a-zA-Z0-9_-]+$`
+	Bucket string `json:"bucket"`
+
+	// MetadataRecipients are curve (xkey) public keys the metadata of each account, its
+	// NatsAccount namespace and name and its JWT fingerprint, is sealed to under
+	// metadata.<public key>; no metadata is put when empty
+	MetadataRecipients []string `json:"metadataRecipients,omitempty"`
+}
+
+// NATSResolverTarget is a NATS server running a full resolver, which stores pushed account JWTs
+// and propagates them to the rest of the cluster. Servers only delete accounts on a request
+// signed by the operator key, so deleted accounts are not removed from them. For the KV type
+// it is the server the bucket is reached through.
+type NATSResolverTarget struct {
+	// URL of the server, e.g. nats://nats:4222 or tls://nats:4222
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Pattern=`^(nats|tls)://.*`
+	URL string `json:"url"`
+
+	// CredentialsSecret holds the creds of a system account user under user.creds, such as the
+	// credentials Secret of a NatsUser of the system account (namespace defaults to the
+	// NatsAuthConfig namespace)
+	// +kubebuilder:validation:Required
+	CredentialsSecret SecretRef `json:"credentialsSecret"`
+
+	// CABundle is a PEM encoded CA bundle used to verify a TLS server (defaults to the system roots)
+	CABundle string `json:"caBundle,omitempty"`
+
+	// TimeoutSeconds bounds one push
+	// +kubebuilder:default=10
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=60
+	TimeoutSeconds int32 `json:"timeoutSeconds,omitempty"`
+}
+
+// AccountServerTarget is a nats-account-server accepting account JWTs on
+// POST /jwt/v1/accounts/<public key>
+type AccountServerTarget struct {
+	// URL of the account server, e.g. http://nats-account-server:9090
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Pattern=`^https?://.*`
+	URL string `json:"url"`
+
+	// CABundle is a PEM encoded CA bundle used to verify an https endpoint (defaults to the system roots)
+	CABundle string `json:"caBundle,omitempty"`
+
+	// TimeoutSeconds bounds one call to the account server
+	// +kubebuilder:default=10
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=60
+	TimeoutSeconds int32 `json:"timeoutSeconds,omitempty"`
+}
+
+// BucketTarget is an S3-compatible bucket, such as Amazon S3, MinIO or Google Cloud Storage with
+// HMAC keys. Each account JWT is stored as <prefix><account public key>.jwt and deleted when the
+// account is.
+type BucketTarget struct {
+	// Endpoint is the base URL of the storage service, e.g. https://s3.eu-west-1.amazonaws.com or
+	// https://storage.googleapis.com. The bucket is addressed path-style.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Pattern=`^https?://.*`
+	Endpoint string `json:"endpoint"`
+
+	// Name of the bucket
+	// +kubebuilder:validation:Required
+	Name string `json:"name"`
+
+	// Region the requests are signed for ("auto" for Google Cloud Storage)
+	// +kubebuilder:default="us-east-1"
+	Region string `json:"region,omitempty"`
+
+	// Prefix of the object keys
+	// +kubebuilder:default="accounts/"
+	Prefix string `json:"prefix,omitempty"`
+
+	// CredentialsSecret holds the access key pair under accessKeyID and secretAccessKey
+	// (namespace defaults to the NatsAuthConfig namespace)
+	// +kubebuilder:validation:Required
+	CredentialsSecret SecretRef `json:"credentialsSecret"`
+
+	// CABundle is a PEM encoded CA bundle used to verify an https endpoint (defaults to the system roots)
+	CABundle string `json:"caBundle,omitempty"`
+
+	// TimeoutSeconds bounds one call to the storage service
+	// +kubebuilder:default=10
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=60
+	TimeoutSeconds int32 `json:"timeoutSeconds,omitempty"`
+}
+
+// ExternalSigner is an HTTP service holding the operator and account keys of a NatsAuthConfig
+type ExternalSigner struct {
+	// URL the public key and signing requests are POSTed to
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Pattern=`^https?://.*`
+	URL string `json:"url"`
+
+	// CABundle is a PEM encoded CA bundle used to verify an https endpoint (defaults to the system roots)
+	CABundle string `json:"caBundle,omitempty"`
+
+	// TimeoutSeconds bounds one call to the signer
+	// +kubebuilder:default=10
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=60
+	TimeoutSeconds int32 `json:"timeoutSeconds,omitempty"`
+}
+
+// PinnedAccountsConfig selects the accounts servers are pinned to
+type PinnedAccountsConfig struct {
+	// Tags selects the accounts of this NatsAuthConfig whose JWT carries all of the tags
+	// (e.g. "env:prod" from spec.metadata). Every account is pinned when empty.
+	Tags []string `json:"tags,omitempty"`
+
+	// AccountIDs are pinned in addition to the selected accounts, e.g. the system account or
+	// accounts managed outside the operator
+	// +kubebuilder:validation:items:Pattern=`^A[A-Z2-7]{55}$`
+	AccountIDs []string `json:"accountIDs,omitempty"`
+}
+
+// PublicCatalogConfig defines the ConfigMap the public catalog is written to
+type PublicCatalogConfig struct {
+	// ConfigMapName of the ConfigMap, in the NatsAuthConfig namespace (defaults to <name>-public)
+	ConfigMapName string `json:"configMapName,omitempty"`
+}
+
+// NatsUserRef references a NatsUser
+type NatsUserRef struct {
+	// Name of the NatsUser
+	// +kubebuilder:validation:Required
+	Name string `json:"name"`
+
+	// Namespace of the NatsUser (defaults to same namespace)
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// MonitoringConfig provisions a system-account user for metrics collectors such as
+// prometheus-nats-exporter and nats-surveyor
+type MonitoringConfig struct {
+	// SystemAccountRef is the NatsAccount the NATS servers use as system_account.
+	// The namespace defaults to the NatsAuthConfig namespace.
+	// +kubebuilder:validation:Required
+	SystemAccountRef NatsAccountRef `json:"systemAccountRef"`
+
+	// UserName of the NatsUser created in the NatsAuthConfig namespace (defaults to <name>-monitoring)
+	UserName string `json:"userName,omitempty"`
+
+	// SecretName of the credentials Secret of the user (defaults to the user name)
+	SecretName string `json:"secretName,omitempty"`
+}
+
+// UserClaimPolicy constrains the user JWTs issued under a NatsAuthConfig. Users may narrow what
+// the policy allows but not widen it.
+type UserClaimPolicy struct {
+	// Audience is set as the aud claim of every user JWT
+	Audience string `json:"audience,omitempty"`
+
+	// SourceNetworks are the CIDR blocks users may connect from, set as the src claim of every
+	// user JWT. Users of scoped signing keys get their source networks from the scope.
+	SourceNetworks []string `json:"sourceNetworks,omitempty"`
+
+	// DefaultConnectionTypes apply to users without allowedConnectionTypes
+	DefaultConnectionTypes []ConnectionType `json:"defaultConnectionTypes,omitempty"`
+
+	// AllowedConnectionTypes are the connection types users may request. Users requesting
+	// another type fail with InvalidSpec; users requesting none are restricted to these.
+	AllowedConnectionTypes []ConnectionType `json:"allowedConnectionTypes,omitempty"`
+
+	// MaxExpiry caps the lifetime of user JWTs; users without expiry or with a longer one get MaxExpiry
+	MaxExpiry *metav1.Duration `json:"maxExpiry,omitempty"`
+
+	// DenyBearerTokens rejects users with bearerToken, so every client must prove its nkey
+	DenyBearerTokens bool `json:"denyBearerTokens,omitempty"`
+}
+
+// InfraAuthEndpoint defines the credentials cluster routes or gateways authenticate with
+type InfraAuthEndpoint struct {
+	// Username for the authorization block (defaults to "route" for cluster, "gateway" for gateway)
+	Username string `json:"username,omitempty"`
+
+	// Password source (generated and kept stable when omitted)
+	Password *PasswordSource `json:"password,omitempty"`
+
+	// Timeout in seconds for the authorization handshake (server default when omitted)
+	// +kubebuilder:validation:Minimum=1
+	Timeout int32 `json:"timeout,omitempty"`
+}
+
+// InfraAuthConfig defines route and gateway authorization managed alongside client auth
+type InfraAuthConfig struct {
+	// Cluster renders a cluster { authorization { ... } } block for routes
+	Cluster *InfraAuthEndpoint `json:"cluster,omitempty"`
+
+	// Gateway renders a gateway { authorization { ... } } block for gateways
+	Gateway *InfraAuthEndpoint `json:"gateway,omitempty"`
+
+	// SecretName of the Secret receiving the rendered blocks and credentials, in the
+	// serverAuthConfig namespace (defaults to <name>-infra-auth)
+	SecretName string `json:"secretName,omitempty"`
+}
+
+// WebsocketConfig defines the websocket listener rendered next to the auth config
+type WebsocketConfig struct {
+	// Port of the websocket listener
+	// +kubebuilder:default=8080
+	Port int32 `json:"port,omitempty"`
+
+	// NoTLS allows plain websocket connections (e.g. behind a TLS-terminating ingress)
+	NoTLS bool `json:"noTLS,omitempty"`
+
+	// JWTCookie is the cookie name browser clients send their bearer user JWT in (JWT mode)
+	JWTCookie string `json:"jwtCookie,omitempty"`
+}
+
+// MQTTConfig defines the MQTT listener rendered next to the auth config
+type MQTTConfig struct {
+	// Port of the MQTT listener
+	// +kubebuilder:default=1883
+	Port int32 `json:"port,omitempty"`
+}
+
+// BootstrapConfig defines a minimal nats-server.conf generated by the operator
+type BootstrapConfig struct {
+	// ConfigMapName of the ConfigMap receiving nats-server.conf, in the serverAuthConfig namespace
+	// (defaults to <name>-bootstrap)
+	ConfigMapName string `json:"configMapName,omitempty"`
+
+	// Port of the client listener
+	// +kubebuilder:default=4222
+	Port int32 `json:"port,omitempty"`
+
+	// JetStreamStoreDir enables JetStream with the given store directory (disabled when empty)
+	JetStreamStoreDir string `json:"jetStreamStoreDir,omitempty"`
+}
+
+// ClaimHookFailurePolicy defines how a failing claim hook call is handled
+// +kubebuilder:validation:Enum=Fail;Ignore
+type ClaimHookFailurePolicy string
+
+const (
+	// ClaimHookFailurePolicyFail stops signing when the hook cannot be called
+	ClaimHookFailurePolicyFail ClaimHookFailurePolicy = "Fail"
+	// ClaimHookFailurePolicyIgnore signs the claims unchanged when the hook cannot be called
+	ClaimHookFailurePolicyIgnore ClaimHookFailurePolicy = "Ignore"
+)
+
+// ClaimHook is an HTTP endpoint that can mutate or reject account and user claims before they are signed
+type ClaimHook struct {
+	// Name identifies the hook in errors and logs
+	// +kubebuilder:validation:Required
+	Name string `json:"name"`
+
+	// URL the claims are POSTed to
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Pattern=`^https?://.*`
+	URL string `json:"url"`
+
+	// CABundle is a PEM encoded CA bundle used to verify an https endpoint (defaults to the system roots)
+	CABundle string `json:"caBundle,omitempty"`
+
+	// Kinds of claims sent to the hook: Account, User (defaults to both)
+	// +kubebuilder:validation:items:Enum=Account;User
+	Kinds []string `json:"kinds,omitempty"`
+
+	// TimeoutSeconds bounds one call to the hook
+	// +kubebuilder:default=5
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=30
+	TimeoutSeconds int32 `json:"timeoutSeconds,omitempty"`
+
+	// FailurePolicy defines whether an unreachable or failing hook stops signing (Fail) or is skipped (Ignore).
+	// A hook rejecting the claims always stops signing.
+	// +kubebuilder:default="Fail"
+	FailurePolicy ClaimHookFailurePolicy `json:"failurePolicy,omitempty"`
+}
+
+// NatsAuthConfigSpec defines the desired state of NatsAuthConfig
+type NatsAuthConfigSpec struct {
+	// NatsURL is the URL for NATS clients to connect
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Pattern=`^nats://.*`
+	NatsURL string `json:"natsURL"`
+
+	// Mode defines the authentication mode (token or jwt). The deprecated mixed mode behaves
+	// like jwt, and token users are refused under it.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:default="jwt"
+	Mode AuthMode `json:"mode"`
+
+	// ServerAuthConfig defines where to write the server auth configuration
+	// +kubebuilder:validation:Required
+	ServerAuthConfig ServerAuthConfigRef `json:"serverAuthConfig"`
+
+	// JWT configuration (required if mode is jwt or mixed)
+	JWT *JWTConfig `json:"jwt,omitempty"`
+
+	// Websocket renders a websocket block under the websocket.conf key of the server auth config (optional)
+	Websocket *WebsocketConfig `json:"websocket,omitempty"`
+
+	// MQTT renders an mqtt block under the mqtt.conf key of the server auth config (optional)
+	MQTT *MQTTConfig `json:"mqtt,omitempty"`
+
+	// Bootstrap generates a complete minimal nats-server.conf in a ConfigMap, so a NATS
+	// server can boot solely from operator-produced config (optional)
+	Bootstrap *BootstrapConfig `json:"bootstrap,omitempty"`
+
+	// InfraAuth defines cluster route and gateway authorization (optional)
+	InfraAuth *InfraAuthConfig `json:"infraAuth,omitempty"`
+
+	// PublicCatalog publishes non-sensitive connection information (NATS URL, operator JWT,
+	// account public keys and exported subjects) in a ConfigMap, so app teams can discover it
+	// without read access to Secrets (optional)
+	PublicCatalog *PublicCatalogConfig `json:"publicCatalog,omitempty"`
+
+	// ClaimHooks are called in order to mutate or reject the claims of every account and user JWT
+	// issued under this NatsAuthConfig before it is signed, e.g. to add tags or cap the expiry (optional)
+	ClaimHooks []ClaimHook `json:"claimHooks,omitempty"`
+
+	// UserClaims constrains every user JWT issued under this NatsAuthConfig (JWT and mixed mode, optional)
+	UserClaims *UserClaimPolicy `json:"userClaims,omitempty"`
+
+	// Monitoring creates a NatsUser in the system account that may send the $SYS server and
+	// account requests metrics collectors rely on, with a creds file Secret to mount into them
+	// (JWT and mixed mode, optional)
+	Monitoring *MonitoringConfig `json:"monitoring,omitempty"`
+
+	// DefaultAccountRef is the NatsAccount of JWT users that set no accountRef, so single-account
+	// clusters need not repeat it on every NatsUser. The namespace defaults to the namespace of
+	// this NatsAuthConfig (JWT and mixed mode, optional).
+	DefaultAccountRef *NatsAccountRef `json:"defaultAccountRef,omitempty"`
+
+	// NoAuthUser names a token-mode NatsUser of this NatsAuthConfig that clients connecting
+	// without credentials are authenticated as, rendered as no_auth_user (token mode, optional)
+	NoAuthUser *NatsUserRef `json:"noAuthUser,omitempty"`
+
+	// ResyncInterval overrides the operator-wide periodic resync interval for this resource.
+	// Set to "0s" to disable periodic resync and rely on watches only.
+	ResyncInterval *metav1.Duration `json:"resyncInterval,omitempty"`
+
+	// Paused stops reconciling the NatsAuthConfig, leaving the server auth config and the other
+	// objects it generates as they are, e.g. while an incident is investigated. The Paused
+	// condition reports it.
+	// +optional
+	Paused bool `json:"paused,omitempty"`
+}
+
+// ChildrenSummary aggregates the NatsAccounts and NatsUsers referencing a NatsAuthConfig
+type ChildrenSummary struct {
+	// Accounts is the number of NatsAccounts referencing the NatsAuthConfig
+	Accounts int32 `json:"accounts"`
+
+	// ReadyAccounts is the number of those NatsAccounts with a true Ready condition
+	ReadyAccounts int32 `json:"readyAccounts"`
+
+	// Users is the number of NatsUsers referencing the NatsAuthConfig
+	Users int32 `json:"users"`
+
+	// UsersByAuthType counts users by effective auth type (inherit resolved to the mode)
+	UsersByAuthType map[string]int32 `json:"usersByAuthType,omitempty"`
+
+	// LastError is the most recent Ready=False message across the children,
+	// prefixed with the child's kind and namespace/name
+	LastError string `json:"lastError,omitempty"`
+}
+
+// OrphanedResource is a generated resource whose owner no longer exists
+type OrphanedResource struct {
+	// Kind is Secret for a generated Secret, or PreloadEntry for an account still listed in
+	// the server auth config
+	Kind string `json:"kind"`
+
+	// Namespace of the Secret, or of the deleted NatsAccount of a preload entry
+	Namespace string `json:"namespace"`
+
+	// Name of the Secret, or the public key of the preloaded account
+	Name string `json:"name"`
+
+	// Owner is the kind and namespace/name of the missing owner
+	Owner string `json:"owner"`
+}
+
+// ModeMigration reports a change of spec.mode held back by users still requiring the current mode
+type ModeMigration struct {
+	// From is the mode the server auth config is still rendered in
+	From AuthMode `json:"from"`
+
+	// To is the mode requested by spec.mode
+	To AuthMode `json:"to"`
+
+	// PendingUsers is the number of NatsUsers that have to be converted before the mode changes
+	PendingUsers int32 `json:"pendingUsers"`
+
+	// Users lists up to ten of the pending NatsUsers as namespace/name
+	Users []string `json:"users,omitempty"`
+
+	// StartedAt is when the change of spec.mode was first observed
+	StartedAt *metav1.Time `json:"startedAt,omitempty"`
+}
+
+// NatsAuthConfigStatus defines the observed state of NatsAuthConfig
+type NatsAuthConfigStatus struct {
+	// OperatorPubKey is the public key of the NATS operator (JWT mode)
+	OperatorPubKey string `json:"operatorPubKey,omitempty"`
+
+	// ResolverReady indicates if the resolver is ready (JWT mode)
+	ResolverReady bool `json:"resolverReady,omitempty"`
+
+	// OperatorKeyHash is a hash of the operator public key and the keys signing account JWTs.
+	// When it changes, every account JWT under this NatsAuthConfig is re-signed.
+	OperatorKeyHash string `json:"operatorKeyHash,omitempty"`
+
+	// OperatorClaimsHash is a hash of the claims in the operator JWT, such as the operator name,
+	// service URLs and tags. The operator JWT is only re-issued when it changes; every account JWT
+	// is then re-signed as well.
+	OperatorClaimsHash string `json:"operatorClaimsHash,omitempty"`
+
+	// PublishedAccounts maps the public key of every account JWT mirrored by spec.jwt.publish to
+	// the fingerprint of the JWT last published
+	PublishedAccounts map[string]string `json:"publishedAccounts,omitempty"`
+
+	// PublishTargetsHash is a hash of spec.jwt.publish when the accounts were last published.
+	// When it changes, every account JWT is published again.
+	PublishTargetsHash string `json:"publishTargetsHash,omitempty"`
+
+	// Mode is the mode the server auth config is rendered in. It follows spec.mode once no
+	// NatsUser requires the previous mode any more.
+	Mode AuthMode `json:"mode,omitempty"`
+
+	// Migration reports a change of spec.mode that is still in progress
+	Migration *ModeMigration `json:"migration,omitempty"`
+
+	// ConfigHash is a hash of the server auth config last written. It changes, together
+	// with a ServerAuthConfigUpdated event, only when the written content changes.
+	ConfigHash string `json:"configHash,omitempty"`
+
+	// LastReconciled is the timestamp of the last reconciliation
+	LastReconciled *metav1.Time `json:"lastReconciled,omitempty"`
+
+	// Children aggregates the NatsAccounts and NatsUsers referencing this NatsAuthConfig
+	Children ChildrenSummary `json:"children,omitempty"`
+
+	// Orphans lists the generated Secrets and preload entries of this NatsAuthConfig whose
+	// owner no longer exists, as found by the startup scan under --orphan-policy=report
+	Orphans []OrphanedResource `json:"orphans,omitempty"`
+
+	// Phase summarizes the Ready condition (Pending, Ready or Error)
+	Phase Phase `json:"phase,omitempty"`
+
+	// Reason is the machine-readable reason of the Ready condition
+	Reason ReasonCode `json:"reason,omitempty"`
+
+	// Message is the human-readable message of the Ready condition
+	Message string `json:"message,omitempty"`
+
+	// Conditions represent the latest available observations of the object's state
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// ObservedGeneration reflects the generation of the most recently observed NatsAuthConfig
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Namespaced
+// +kubebuilder:printcolumn:name="Mode",type=string,JSONPath=`.spec.mode`
+// +kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
+// +kubebuilder:printcolumn:name="NATS URL",type=string,JSONPath=`.spec.natsURL`
+// +kubebuilder:printcolumn:name="Accounts",type=integer,JSONPath=`.status.children.accounts`
+// +kubebuilder:printcolumn:name="Users",type=integer,JSONPath=`.status.children.users`
+// +kubebuilder:printcolumn:name="Operator",type=string,JSONPath=`.status.operatorPubKey`,priority=1
+// +kubebuilder:printcolumn:name="Message",type=string,JSONPath=`.status.message`,priority=1
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// NatsAuthConfig is the Schema for the natsauthconfigs API
+type NatsAuthConfig struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   NatsAuthConfigSpec   `json:"spec,omitempty"`
+	Status NatsAuthConfigStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// NatsAuthConfigList contains a list of NatsAuthConfig
+type NatsAuthConfigList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []NatsAuthConfig `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&NatsAuthConfig{}, &NatsAuthConfigList{})
+}
@@ -0,0 +1,177 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/jradikk/nats-auth-operator/internal/backup"
+)
+
+func newBackupCommand(opts *options) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "backup",
+		Short: "Export and restore an auth hierarchy as a sealed archive",
+		Long: `Export a NatsAuthConfig with its NatsAccounts, NatsUsers and seed Secrets as an archive sealed
+to one or more curve (xkey) recipients, and restore it into a cluster with the same public keys.`,
+	}
+	cmd.AddCommand(
+		newBackupKeygenCommand(),
+		newBackupCreateCommand(opts),
+		newBackupRestoreCommand(opts),
+	)
+	return cmd
+}
+
+func newBackupKeygenCommand() *cobra.Command {
+	var output string
+
+	cmd := &cobra.Command{
+		Use:   "keygen",
+		Short: "Create a curve key pair to seal archives to",
+		Long: `Create a curve (xkey) key pair. The public key (X...) is printed and goes into the recipients
+of a NatsAuthBackup or --recipient; the seed (SX...) opens the archives and must be kept offline.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			seed, pub, err := backup.NewRecipientKey()
+			if err != nil {
+				return err
+			}
+			if output == "" {
+				fmt.Fprintf(cmd.OutOrStdout(), "%s\n", seed)
+			} else {
+				if err := os.WriteFile(output, append(seed, '\n'), 0o600); err != nil {
+					return fmt.Errorf("failed to write %s: %w", output, err)
+				}
+				fmt.Fprintf(cmd.ErrOrStderr(), "wrote %s\n", output)
+			}
+			fmt.Fprintf(cmd.ErrOrStderr(), "recipient: %s\n", pub)
+			return nil
+		},
+	}
+	cmd.Flags().StringVarP(&output, "output", "o", "", "Write the seed here instead of stdout (mode 0600)")
+	return cmd
+}
+
+func newBackupCreateCommand(opts *options) *cobra.Command {
+	var recipients []string
+	var output string
+
+	cmd := &cobra.Command{
+		Use:   "create NATSAUTHCONFIG",
+		Short: "Export a NatsAuthConfig and everything under it as a sealed archive",
+		Long: `Export a NatsAuthConfig, the NatsAccounts and NatsUsers referencing it and the Secrets holding
+their seeds, JWTs and passwords. The archive is checked to hold the seed behind every public key
+in status and is sealed to each --recipient.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := opts.client()
+			if err != nil {
+				return err
+			}
+			namespace, err := opts.resolveNamespace()
+			if err != nil {
+				return err
+			}
+
+			archive, err := backup.Collect(cmd.Context(), c, client.ObjectKey{Namespace: namespace, Name: args[0]})
+			if err != nil {
+				return err
+			}
+			data, err := backup.Marshal(archive)
+			if err != nil {
+				return err
+			}
+			sealed, err := backup.Seal(data, recipients)
+			if err != nil {
+				return err
+			}
+
+			if output == "" {
+				_, err := cmd.OutOrStdout().Write(sealed)
+				return err
+			}
+			if err := os.WriteFile(output, sealed, 0o600); err != nil {
+				return fmt.Errorf("failed to write %s: %w", output, err)
+			}
+			fmt.Fprintf(cmd.ErrOrStderr(), "wrote %s: %d accounts, %d users, %d secrets\n",
+				output, len(archive.Accounts), len(archive.Users), len(archive.Secrets))
+			return nil
+		},
+	}
+	cmd.Flags().StringArrayVar(&recipients, "recipient", nil, "Curve public key (X...) to seal the archive to; repeatable")
+	cmd.Flags().StringVarP(&output, "output", "o", "", "Write the archive here instead of stdout (mode 0600)")
+	_ = cmd.MarkFlagRequired("recipient")
+	return cmd
+}
+
+func newBackupRestoreCommand(opts *options) *cobra.Command {
+	var identity string
+	var dryRun bool
+
+	cmd := &cobra.Command{
+		Use:   "restore FILE",
+		Short: "Restore a sealed archive into the cluster",
+		Long: `Open a sealed archive with the seed of one of its recipients and create its Secrets, then its
+NatsAuthConfig, NatsAccounts and NatsUsers with their status. Resources that already exist are
+left alone; a Secret that exists with different data stops the restore.
+
+The archive may also be read from the Secret a NatsAuthBackup writes:
+
+  kubectl get secret <name>-backup -o jsonpath='{.data.backup\.sealed}' | base64 -d > archive`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			sealed, err := os.ReadFile(args[0])
+			if err != nil {
+				return fmt.Errorf("failed to read %s: %w", args[0], err)
+			}
+			seed, err := os.ReadFile(identity)
+			if err != nil {
+				return fmt.Errorf("failed to read %s: %w", identity, err)
+			}
+			data, err := backup.Open(sealed, seed)
+			if err != nil {
+				return err
+			}
+			archive, err := backup.Unmarshal(data)
+			if err != nil {
+				return err
+			}
+
+			c, err := opts.client()
+			if err != nil {
+				return err
+			}
+			changes, err := backup.Restore(cmd.Context(), c, archive, dryRun)
+			for _, change := range changes {
+				if dryRun {
+					change += " (dry run)"
+				}
+				fmt.Fprintln(cmd.OutOrStdout(), change)
+			}
+			return err
+		},
+	}
+	cmd.Flags().StringVar(&identity, "identity", "", "File holding the curve seed (SX...) of a recipient")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Only report what would be restored")
+	_ = cmd.MarkFlagRequired("identity")
+	return cmd
+}
@@ -0,0 +1,132 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/nats-io/jwt/v2"
+	"github.com/nats-io/nkeys"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	natsv1alpha1 "github.com/jradikk/nats-auth-operator/api/v1alpha1"
+	jwtpkg "github.com/jradikk/nats-auth-operator/internal/jwt"
+)
+
+func newCredsCommand(opts *options) *cobra.Command {
+	var output string
+	var resign bool
+
+	cmd := &cobra.Command{
+		Use:   "creds NATSUSER",
+		Short: "Regenerate the creds file of a JWT NatsUser locally",
+		Long: `Regenerate the creds file of a JWT NatsUser from the seed and JWT in its credentials Secret,
+whatever output format the Secret uses. Nothing is written back to the cluster.
+
+With --resign the stored user claims are re-signed with the account seed, which repairs a
+creds file whose JWT was lost or signed by an outdated account key.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := opts.client()
+			if err != nil {
+				return err
+			}
+			namespace, err := opts.resolveNamespace()
+			if err != nil {
+				return err
+			}
+			user, secret, err := getUser(cmd.Context(), c, namespace, args[0])
+			if err != nil {
+				return err
+			}
+			if secret == nil {
+				return fmt.Errorf("NatsUser %s/%s has no credentials secret yet", namespace, user.Name)
+			}
+
+			userJWT, seed := jwtpkg.ExtractCredentials(secret.Data)
+			if len(seed) == 0 {
+				return fmt.Errorf("credentials secret %s/%s holds no user seed", secret.Namespace, secret.Name)
+			}
+			if resign {
+				if userJWT, err = resignUserJWT(cmd, c, user, userJWT); err != nil {
+					return err
+				}
+			}
+			if userJWT == "" {
+				return fmt.Errorf("credentials secret %s/%s holds no user JWT; use --resign", secret.Namespace, secret.Name)
+			}
+
+			creds := jwtpkg.GenerateCredsFile(userJWT, seed)
+			if output == "" {
+				fmt.Fprint(cmd.OutOrStdout(), creds)
+				return nil
+			}
+			if err := os.WriteFile(output, []byte(creds), 0o600); err != nil {
+				return fmt.Errorf("failed to write %s: %w", output, err)
+			}
+			fmt.Fprintf(cmd.ErrOrStderr(), "wrote %s\n", output)
+			return nil
+		},
+	}
+	cmd.Flags().StringVarP(&output, "output", "o", "", "Write the creds file here instead of stdout (mode 0600)")
+	cmd.Flags().BoolVar(&resign, "resign", false, "Re-sign the user claims with the account seed")
+	return cmd
+}
+
+// resignUserJWT signs the claims of the stored user JWT with the seed of the user's account
+func resignUserJWT(cmd *cobra.Command, c client.Client, user *natsv1alpha1.NatsUser, userJWT string) (string, error) {
+	if !hasAccountRef(user) {
+		return "", fmt.Errorf("NatsUser %s/%s has no account", user.Namespace, user.Name)
+	}
+	if userJWT == "" {
+		return "", fmt.Errorf("no stored user JWT to take the claims from")
+	}
+	claims, err := jwt.DecodeUserClaims(userJWT)
+	if err != nil {
+		return "", fmt.Errorf("failed to decode user JWT: %w", err)
+	}
+
+	account := &natsv1alpha1.NatsAccount{}
+	if err := c.Get(cmd.Context(), accountRefKey(user), account); err != nil {
+		return "", fmt.Errorf("failed to get NatsAccount %s: %w", accountRefKey(user), err)
+	}
+	ref := account.Status.JWTSecretRef
+	if ref.Name == "" {
+		return "", fmt.Errorf("NatsAccount %s has no account JWT secret yet", accountRefKey(user))
+	}
+	accountSecret := &corev1.Secret{}
+	if err := c.Get(cmd.Context(), ref.ObjectKey(account.Namespace), accountSecret); err != nil {
+		return "", fmt.Errorf("failed to get account JWT secret: %w", err)
+	}
+
+	accountSeed, _, err := jwtpkg.FindSeed(accountSecret.Data, nkeys.PrefixByteAccount, "")
+	if err != nil {
+		return "", fmt.Errorf("failed to read account seed: %w", err)
+	}
+	accountMgr, err := jwtpkg.NewAccountManager(accountSeed)
+	if err != nil {
+		return "", fmt.Errorf("failed to load account seed: %w", err)
+	}
+	signed, err := accountMgr.SignUserJWT(claims)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign user JWT: %w", err)
+	}
+	return signed, nil
+}
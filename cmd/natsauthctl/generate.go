@@ -0,0 +1,123 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"crypto/rand"
+	"fmt"
+	"os"
+
+	"github.com/nats-io/nkeys"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/yaml"
+
+	jwtpkg "github.com/jradikk/nats-auth-operator/internal/jwt"
+	"github.com/jradikk/nats-auth-operator/internal/sealedsecret"
+	"github.com/jradikk/nats-auth-operator/internal/secrets"
+)
+
+// seedPrefixes maps the generate subcommands to the nkey type they create
+var seedPrefixes = map[string]nkeys.PrefixByte{
+	"operator": nkeys.PrefixByteOperator,
+	"account":  nkeys.PrefixByteAccount,
+	"user":     nkeys.PrefixByteUser,
+}
+
+// seedReferences names the field that references a seed Secret of each type
+var seedReferences = map[nkeys.PrefixByte]string{
+	nkeys.PrefixByteOperator: "NatsAuthConfig spec.jwt.operatorSeedSecret",
+	nkeys.PrefixByteAccount:  "NatsAccount spec.existingSeedSecret",
+	nkeys.PrefixByteUser:     "NatsUser spec.existingSeedSecret",
+}
+
+func newGenerateCommand(opts *options) *cobra.Command {
+	var sealCert string
+
+	cmd := &cobra.Command{
+		Use:   "generate (operator | account | user) NAME",
+		Short: "Generate an nkey seed Secret offline",
+		Long: `Generate an operator, account or user seed and print the Secret NAME holding it as YAML,
+without contacting the cluster. Apply the Secret and reference it with existingSeedSecret (or
+operatorSeedSecret for an operator) so the operator uses the seed instead of creating one.
+
+With --seal-cert the output is a SealedSecret encrypted for the sealed-secrets controller owning
+the certificate (kubeseal --fetch-cert), which can be committed to Git. To encrypt with SOPS
+instead, pipe the Secret to sops --encrypt --encrypted-regex '^(data|stringData)$'.
+
+The public key is printed to stderr.`,
+		Args:      cobra.ExactArgs(2),
+		ValidArgs: []string{"operator", "account", "user"},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			prefix, ok := seedPrefixes[args[0]]
+			if !ok {
+				return fmt.Errorf("unknown seed type %q, want operator, account or user", args[0])
+			}
+			namespace, err := opts.resolveNamespace()
+			if err != nil {
+				return err
+			}
+
+			kp, err := nkeys.CreatePair(prefix)
+			if err != nil {
+				return fmt.Errorf("failed to create %s key: %w", args[0], err)
+			}
+			seed, err := kp.Seed()
+			if err != nil {
+				return fmt.Errorf("failed to read seed: %w", err)
+			}
+			pub, err := kp.PublicKey()
+			if err != nil {
+				return fmt.Errorf("failed to read public key: %w", err)
+			}
+
+			secret := secrets.New(namespace, args[1], map[string][]byte{jwtpkg.CanonicalSeedKey(prefix): seed})
+			secret.APIVersion = "v1"
+			secret.Kind = "Secret"
+			secret.Type = corev1.SecretTypeOpaque
+			secret.Labels = map[string]string{jwtpkg.SeedTypeLabel: jwtpkg.SeedType(prefix)}
+
+			var out any = secret
+			if sealCert != "" {
+				data, err := os.ReadFile(sealCert)
+				if err != nil {
+					return fmt.Errorf("failed to read certificate: %w", err)
+				}
+				cert, err := sealedsecret.ParseCertificate(data)
+				if err != nil {
+					return err
+				}
+				if out, err = sealedsecret.Seal(rand.Reader, cert, secret); err != nil {
+					return err
+				}
+			}
+
+			data, err := yaml.Marshal(out)
+			if err != nil {
+				return fmt.Errorf("failed to encode Secret: %w", err)
+			}
+			if _, err := cmd.OutOrStdout().Write(data); err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.ErrOrStderr(), "Public key: %s\nReference the Secret %s/%s from %s\n",
+				pub, namespace, args[1], seedReferences[prefix])
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&sealCert, "seal-cert", "", "Seal the Secret with the sealed-secrets certificate in this PEM file")
+	return cmd
+}
@@ -0,0 +1,151 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/jradikk/nats-auth-operator/internal/authconf"
+)
+
+func newLintCommand(opts *options) *cobra.Command {
+	var from, key string
+
+	cmd := &cobra.Command{
+		Use:   "lint --from (secret/NAMESPACE/NAME | configmap/NAMESPACE/NAME | FILE)",
+		Short: "Check a rendered server auth config for syntax errors",
+		Long: `Check a rendered server auth config for syntax errors before the server loads it.
+
+The config is read from a key of a Secret or ConfigMap, or from a file. Includes are
+resolved among the other keys of the object, the overflow Secrets of a chunked server
+auth Secret included, or relative to the file. Includes that cannot be resolved, e.g.
+files from other mounts, are skipped.
+
+Only the syntax of the NATS config format is checked, not option names or values.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			var err error
+			if kind, ref, ok := strings.Cut(from, "/"); ok && (kind == "secret" || kind == "configmap") {
+				err = lintObject(cmd.Context(), opts, kind, ref, key)
+			} else {
+				err = lintFile(from)
+			}
+			if err != nil {
+				return err
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), "ok")
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&from, "from", "", "Config to lint: secret/NAMESPACE/NAME, configmap/NAMESPACE/NAME or a file")
+	cmd.Flags().StringVar(&key, "key", authconf.AuthConfTemplate, "Key of the Secret or ConfigMap holding the config")
+	_ = cmd.MarkFlagRequired("from")
+	return cmd
+}
+
+// lintObject lints a key of the Secret or ConfigMap NAMESPACE/NAME, or NAME in the namespace flag
+func lintObject(ctx context.Context, opts *options, kind, ref, key string) error {
+	namespace, name, ok := strings.Cut(ref, "/")
+	if !ok {
+		var err error
+		if namespace, err = opts.resolveNamespace(); err != nil {
+			return err
+		}
+		name = ref
+	}
+	c, err := opts.client()
+	if err != nil {
+		return err
+	}
+
+	data := map[string][]byte{}
+	objKey := types.NamespacedName{Namespace: namespace, Name: name}
+	if kind == "configmap" {
+		cm := &corev1.ConfigMap{}
+		if err := c.Get(ctx, objKey, cm); err != nil {
+			return fmt.Errorf("failed to get ConfigMap %s: %w", objKey, err)
+		}
+		for k, v := range cm.Data {
+			data[k] = []byte(v)
+		}
+	} else {
+		secret := &corev1.Secret{}
+		if err := c.Get(ctx, objKey, secret); err != nil {
+			return fmt.Errorf("failed to get Secret %s: %w", objKey, err)
+		}
+		data = secret.Data
+		if err := mergeConfigChunks(ctx, c, objKey, data); err != nil {
+			return err
+		}
+	}
+
+	if err := authconf.LintConfigData(data, key); err != nil {
+		return fmt.Errorf("%s %s: %w", kind, objKey, err)
+	}
+	return nil
+}
+
+// mergeConfigChunks adds the keys of the overflow Secrets of a chunked server auth Secret to data
+func mergeConfigChunks(ctx context.Context, c client.Client, key types.NamespacedName, data map[string][]byte) error {
+	for i := 1; ; i++ {
+		chunk := &corev1.Secret{}
+		chunkKey := types.NamespacedName{Namespace: key.Namespace, Name: fmt.Sprintf("%s-%d", key.Name, i)}
+		if err := c.Get(ctx, chunkKey, chunk); err != nil {
+			if errors.IsNotFound(err) {
+				return nil
+			}
+			return fmt.Errorf("failed to get Secret %s: %w", chunkKey, err)
+		}
+		if chunk.Labels[authconf.ConfigChunkLabel] != strconv.Itoa(i) {
+			return nil
+		}
+		for k, v := range chunk.Data {
+			data[k] = v
+		}
+	}
+}
+
+// lintFile lints a config file, resolving includes relative to its directory
+func lintFile(path string) error {
+	conf, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config: %w", err)
+	}
+	dir := filepath.Dir(path)
+	err = authconf.LintConfig(string(conf), func(name string) (string, bool) {
+		if !filepath.IsAbs(name) {
+			name = filepath.Join(dir, name)
+		}
+		included, err := os.ReadFile(name)
+		return string(included), err == nil
+	})
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	natsv1alpha1 "github.com/jradikk/nats-auth-operator/api/v1alpha1"
+)
+
+// versionedResources are the CRDs served in more than one API version
+var versionedResources = []string{"natsauthconfigs", "natsaccounts", "natsusers"}
+
+func newMigrateStorageCommand(opts *options) *cobra.Command {
+	var dryRun bool
+
+	cmd := &cobra.Command{
+		Use:   "migrate-storage",
+		Short: "Rewrite stored custom resources in the current storage version",
+		Long: `Rewrite every NatsAuthConfig, NatsAccount and NatsUser in the storage version of its CRD and
+drop older versions from the CRD's status.storedVersions.
+
+Run this after the storage version of a CRD changed, so an older API version can later be
+removed without stranding objects in etcd. Objects are rewritten with a no-op update, which
+goes through the conversion webhook; the operator must be running with webhooks enabled.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := opts.client()
+			if err != nil {
+				return err
+			}
+			for _, resource := range versionedResources {
+				if err := migrateStorage(cmd, c, resource, dryRun); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Only report what would be migrated")
+	return cmd
+}
+
+// migrateStorage rewrites all objects of one CRD and records the storage version as the only stored version
+func migrateStorage(cmd *cobra.Command, c client.Client, resource string, dryRun bool) error {
+	ctx := cmd.Context()
+	out := cmd.OutOrStdout()
+
+	crd := &apiextensionsv1.CustomResourceDefinition{}
+	name := resource + "." + natsv1alpha1.GroupVersion.Group
+	if err := c.Get(ctx, client.ObjectKey{Name: name}, crd); err != nil {
+		return fmt.Errorf("failed to get CRD %s: %w", name, err)
+	}
+
+	storageVersion := ""
+	for _, v := range crd.Spec.Versions {
+		if v.Storage {
+			storageVersion = v.Name
+		}
+	}
+	if storageVersion == "" {
+		return fmt.Errorf("CRD %s has no storage version", name)
+	}
+	if len(crd.Status.StoredVersions) == 1 && crd.Status.StoredVersions[0] == storageVersion {
+		fmt.Fprintf(out, "%s: already stored as %s only\n", name, storageVersion)
+		return nil
+	}
+
+	list := &unstructured.UnstructuredList{}
+	list.SetGroupVersionKind(schema.GroupVersionKind{
+		Group:   crd.Spec.Group,
+		Version: storageVersion,
+		Kind:    crd.Spec.Names.ListKind,
+	})
+	if err := c.List(ctx, list); err != nil {
+		return fmt.Errorf("failed to list %s: %w", name, err)
+	}
+
+	fmt.Fprintf(out, "%s: rewriting %d objects stored as %v in %s\n", name, len(list.Items), crd.Status.StoredVersions, storageVersion)
+	if dryRun {
+		return nil
+	}
+
+	for i := range list.Items {
+		obj := &list.Items[i]
+		err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+			err := c.Update(ctx, obj)
+			if err != nil && client.IgnoreNotFound(err) == nil {
+				return nil
+			}
+			if err != nil {
+				// Refresh the object before the next attempt
+				if getErr := c.Get(ctx, client.ObjectKeyFromObject(obj), obj); getErr != nil {
+					return client.IgnoreNotFound(getErr)
+				}
+			}
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("failed to rewrite %s %s/%s: %w", crd.Spec.Names.Kind, obj.GetNamespace(), obj.GetName(), err)
+		}
+	}
+
+	crd.Status.StoredVersions = []string{storageVersion}
+	if err := c.Status().Update(ctx, crd); err != nil {
+		return fmt.Errorf("failed to update stored versions of CRD %s: %w", name, err)
+	}
+	fmt.Fprintf(out, "%s: stored versions set to [%s]\n", name, storageVersion)
+	return nil
+}
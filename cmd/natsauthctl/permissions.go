@@ -0,0 +1,113 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/nats-io/jwt/v2"
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/yaml"
+
+	natsv1alpha1 "github.com/jradikk/nats-auth-operator/api/v1alpha1"
+	jwtpkg "github.com/jradikk/nats-auth-operator/internal/jwt"
+)
+
+// effectivePermissions is the permissions view printed by the permissions command
+type effectivePermissions struct {
+	User                   string   `json:"user"`
+	Source                 string   `json:"source"`
+	PublicKey              string   `json:"publicKey,omitempty"`
+	Issuer                 string   `json:"issuer,omitempty"`
+	Expires                string   `json:"expires,omitempty"`
+	PublishAllow           []string `json:"publishAllow,omitempty"`
+	PublishDeny            []string `json:"publishDeny,omitempty"`
+	SubscribeAllow         []string `json:"subscribeAllow,omitempty"`
+	SubscribeDeny          []string `json:"subscribeDeny,omitempty"`
+	AllowedConnectionTypes []string `json:"allowedConnectionTypes,omitempty"`
+	BearerToken            bool     `json:"bearerToken,omitempty"`
+}
+
+func newPermissionsCommand(opts *options) *cobra.Command {
+	return &cobra.Command{
+		Use:   "permissions NATSUSER",
+		Short: "Show the effective permissions of a NatsUser",
+		Long: `Show the effective permissions of a NatsUser.
+
+For JWT users the permissions are read from the issued user JWT, so they reflect what the
+server enforces. Token users show the permissions rendered into the auth config.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := opts.client()
+			if err != nil {
+				return err
+			}
+			namespace, err := opts.resolveNamespace()
+			if err != nil {
+				return err
+			}
+			user, secret, err := getUser(cmd.Context(), c, namespace, args[0])
+			if err != nil {
+				return err
+			}
+
+			view := effectivePermissions{User: namespace + "/" + user.Name}
+			var userJWT string
+			if secret != nil {
+				userJWT, _ = jwtpkg.ExtractCredentials(secret.Data)
+			}
+
+			if userJWT != "" {
+				claims, err := jwt.DecodeUserClaims(userJWT)
+				if err != nil {
+					return fmt.Errorf("failed to decode user JWT: %w", err)
+				}
+				view.Source = "jwt"
+				view.PublicKey = claims.Subject
+				view.Issuer = claims.Issuer
+				if claims.Expires > 0 {
+					view.Expires = time.Unix(claims.Expires, 0).UTC().Format(time.RFC3339)
+				}
+				view.PublishAllow = claims.Pub.Allow
+				view.PublishDeny = claims.Pub.Deny
+				view.SubscribeAllow = claims.Sub.Allow
+				view.SubscribeDeny = claims.Sub.Deny
+				view.AllowedConnectionTypes = claims.AllowedConnectionTypes
+				view.BearerToken = claims.BearerToken
+			} else {
+				if user.Spec.AuthType == natsv1alpha1.UserAuthTypeJWT {
+					fmt.Fprintln(cmd.ErrOrStderr(), "warning: no user JWT issued yet, showing the spec")
+				}
+				view.Source = "spec"
+				if p := user.Spec.Permissions; p != nil {
+					view.PublishAllow = p.PublishAllow
+					view.PublishDeny = p.PublishDeny
+					view.SubscribeAllow = p.SubscribeAllow
+					view.SubscribeDeny = p.SubscribeDeny
+				}
+			}
+
+			out, err := yaml.Marshal(view)
+			if err != nil {
+				return fmt.Errorf("failed to encode permissions: %w", err)
+			}
+			fmt.Fprint(cmd.OutOrStdout(), string(out))
+			return nil
+		},
+	}
+}
@@ -0,0 +1,103 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/clientcmd"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	natsv1alpha1 "github.com/jradikk/nats-auth-operator/api/v1alpha1"
+)
+
+// options holds the flags shared by the commands talking to the cluster
+type options struct {
+	kubeconfig string
+	context    string
+	namespace  string
+}
+
+func newRootCommand() *cobra.Command {
+	opts := &options{}
+
+	cmd := &cobra.Command{
+		Use:          "natsauthctl",
+		Short:        "Inspect and repair credentials managed by the NATS auth operator",
+		SilenceUsage: true,
+	}
+	cmd.PersistentFlags().StringVar(&opts.kubeconfig, "kubeconfig", "", "Path to the kubeconfig file (defaults to $KUBECONFIG or ~/.kube/config)")
+	cmd.PersistentFlags().StringVar(&opts.context, "context", "", "Kubeconfig context to use")
+	cmd.PersistentFlags().StringVarP(&opts.namespace, "namespace", "n", "", "Namespace of the resource (defaults to the context namespace)")
+
+	cmd.AddCommand(
+		newDecodeCommand(),
+		newPermissionsCommand(opts),
+		newCredsCommand(opts),
+		newRotateCommand(opts),
+		newMigrateStorageCommand(opts),
+		newMigrateSeedsCommand(opts),
+		newBackupCommand(opts),
+		newGenerateCommand(opts),
+		newLintCommand(opts),
+	)
+	return cmd
+}
+
+// clientConfig loads the kubeconfig the same way kubectl does
+func (o *options) clientConfig() clientcmd.ClientConfig {
+	rules := clientcmd.NewDefaultClientConfigLoadingRules()
+	rules.ExplicitPath = o.kubeconfig
+	overrides := &clientcmd.ConfigOverrides{CurrentContext: o.context}
+	return clientcmd.NewNonInteractiveDeferredLoadingClientConfig(rules, overrides)
+}
+
+// client returns a client that understands the operator's API types
+func (o *options) client() (client.Client, error) {
+	config, err := o.clientConfig().ClientConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load kubeconfig: %w", err)
+	}
+
+	scheme := runtime.NewScheme()
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(apiextensionsv1.AddToScheme(scheme))
+	utilruntime.Must(natsv1alpha1.AddToScheme(scheme))
+
+	c, err := client.New(config, client.Options{Scheme: scheme})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create client: %w", err)
+	}
+	return c, nil
+}
+
+// resolveNamespace returns the namespace flag or the namespace of the current context
+func (o *options) resolveNamespace() (string, error) {
+	if o.namespace != "" {
+		return o.namespace, nil
+	}
+	namespace, _, err := o.clientConfig().Namespace()
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve namespace: %w", err)
+	}
+	return namespace, nil
+}
@@ -0,0 +1,101 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	natsv1alpha1 "github.com/jradikk/nats-auth-operator/api/v1alpha1"
+)
+
+func newRotateCommand(opts *options) *cobra.Command {
+	var revoke bool
+
+	cmd := &cobra.Command{
+		Use:   "rotate NATSUSER",
+		Short: "Force the operator to issue new credentials for a NatsUser",
+		Long: `Force the operator to issue new credentials for a NatsUser by deleting its credentials Secret.
+
+JWT users get a new user key; with --revoke (the default) the old public key is added to the
+account's revocation list so it stops working once the account JWT is re-signed. Token users
+with a generated password get a new password. Users whose seed, JWT or password comes from a
+Secret outside the operator cannot be rotated here.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := opts.client()
+			if err != nil {
+				return err
+			}
+			namespace, err := opts.resolveNamespace()
+			if err != nil {
+				return err
+			}
+			user, secret, err := getUser(cmd.Context(), c, namespace, args[0])
+			if err != nil {
+				return err
+			}
+
+			switch {
+			case user.Spec.ExistingSeedSecret != nil || user.Spec.ExistingJWTSecret != nil:
+				return fmt.Errorf("NatsUser %s/%s uses an existing seed or JWT; rotate it at the source", namespace, user.Name)
+			case user.Spec.PasswordFrom != nil && !user.Spec.PasswordFrom.Generate && user.Spec.PasswordFrom.SecretRef != nil:
+				return fmt.Errorf("NatsUser %s/%s reads its password from a Secret; rotate it there", namespace, user.Name)
+			}
+
+			if revoke && user.Status.PublicKey != "" && hasAccountRef(user) {
+				if err := revokeUserKey(cmd, c, user); err != nil {
+					return err
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "revoked user key %s in NatsAccount %s\n", user.Status.PublicKey, accountRefKey(user))
+			}
+
+			if secret == nil {
+				fmt.Fprintf(cmd.OutOrStdout(), "NatsUser %s/%s has no credentials secret; the operator will issue new credentials\n", namespace, user.Name)
+				return nil
+			}
+			if err := c.Delete(cmd.Context(), secret); client.IgnoreNotFound(err) != nil {
+				return fmt.Errorf("failed to delete credentials secret: %w", err)
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "deleted credentials secret %s/%s; the operator will issue new credentials\n", secret.Namespace, secret.Name)
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&revoke, "revoke", true, "Revoke the old user key in the account (JWT users)")
+	return cmd
+}
+
+// revokeUserKey records the user's current public key in the account's revocation list
+func revokeUserKey(cmd *cobra.Command, c client.Client, user *natsv1alpha1.NatsUser) error {
+	account := &natsv1alpha1.NatsAccount{}
+	if err := c.Get(cmd.Context(), accountRefKey(user), account); err != nil {
+		return fmt.Errorf("failed to get NatsAccount %s: %w", accountRefKey(user), err)
+	}
+
+	patch := client.MergeFrom(account.DeepCopy())
+	if account.Status.RevokedUsers == nil {
+		account.Status.RevokedUsers = map[string]int64{}
+	}
+	account.Status.RevokedUsers[user.Status.PublicKey] = time.Now().Unix()
+	if err := c.Status().Patch(cmd.Context(), account, patch); err != nil {
+		return fmt.Errorf("failed to revoke user key: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,186 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/nats-io/nkeys"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	natsv1alpha1 "github.com/jradikk/nats-auth-operator/api/v1alpha1"
+	jwtpkg "github.com/jradikk/nats-auth-operator/internal/jwt"
+)
+
+// seedSecret is a Secret holding an nkey seed of a known type
+type seedSecret struct {
+	key    client.ObjectKey
+	prefix nkeys.PrefixByte
+
+	// seedKey is an explicit key set on the referencing resource; the seed is then not moved
+	seedKey string
+}
+
+func newMigrateSeedsCommand(opts *options) *cobra.Command {
+	var dryRun, keepLegacy bool
+
+	cmd := &cobra.Command{
+		Use:   "migrate-seeds",
+		Short: "Move nkey seeds to their canonical Secret keys and label the seed Secrets",
+		Long: `Find every Secret holding an operator, account or user seed used by a NatsAuthConfig,
+NatsAccount or NatsUser, move seeds stored under legacy keys (seed.nk, seed, nkey, <type>.nk) to
+the canonical key (operator.seed, account.seed or user.seed) and label the Secret with
+nats.jradikk/seed-type.
+
+Seeds referenced with an explicit key other than the canonical one are only labeled. Use
+--keep-legacy-keys when other consumers still read a seed under its legacy key.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := opts.client()
+			if err != nil {
+				return err
+			}
+			secrets, err := listSeedSecrets(cmd, c)
+			if err != nil {
+				return err
+			}
+			for _, s := range secrets {
+				if err := migrateSeedSecret(cmd, c, s, dryRun, keepLegacy); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Only report what would be migrated")
+	cmd.Flags().BoolVar(&keepLegacy, "keep-legacy-keys", false, "Copy seeds to the canonical key instead of moving them")
+	return cmd
+}
+
+// listSeedSecrets returns the seed Secrets of all resources in the cluster, each Secret once
+func listSeedSecrets(cmd *cobra.Command, c client.Client) ([]seedSecret, error) {
+	ctx := cmd.Context()
+	var secrets []seedSecret
+	seen := map[client.ObjectKey]bool{}
+	add := func(s seedSecret) {
+		if s.key.Name == "" || seen[s.key] {
+			return
+		}
+		seen[s.key] = true
+		secrets = append(secrets, s)
+	}
+
+	authConfigs := &natsv1alpha1.NatsAuthConfigList{}
+	if err := c.List(ctx, authConfigs); err != nil {
+		return nil, fmt.Errorf("failed to list NatsAuthConfigs: %w", err)
+	}
+	for _, authConfig := range authConfigs.Items {
+		if authConfig.Spec.Mode != natsv1alpha1.AuthModeJWT {
+			continue
+		}
+		if authConfig.Spec.JWT != nil && authConfig.Spec.JWT.OperatorSeedSecret != nil {
+			ref := authConfig.Spec.JWT.OperatorSeedSecret
+			add(seedSecret{key: client.ObjectKey{Namespace: ref.Namespace, Name: ref.Name}, prefix: nkeys.PrefixByteOperator, seedKey: ref.Key})
+			continue
+		}
+		add(seedSecret{
+			key:    client.ObjectKey{Namespace: authConfig.Namespace, Name: authConfig.Name + "-operator-seed"},
+			prefix: nkeys.PrefixByteOperator,
+		})
+	}
+
+	accounts := &natsv1alpha1.NatsAccountList{}
+	if err := c.List(ctx, accounts); err != nil {
+		return nil, fmt.Errorf("failed to list NatsAccounts: %w", err)
+	}
+	for _, account := range accounts.Items {
+		if ref := account.Spec.ExistingSeedSecret; ref != nil {
+			add(seedSecret{key: ref.ObjectKey(account.Namespace), prefix: nkeys.PrefixByteAccount, seedKey: ref.Key})
+		}
+		add(seedSecret{key: account.Status.JWTSecretRef.ObjectKey(account.Namespace), prefix: nkeys.PrefixByteAccount})
+	}
+
+	users := &natsv1alpha1.NatsUserList{}
+	if err := c.List(ctx, users); err != nil {
+		return nil, fmt.Errorf("failed to list NatsUsers: %w", err)
+	}
+	for _, user := range users.Items {
+		if ref := user.Spec.ExistingSeedSecret; ref != nil {
+			add(seedSecret{key: ref.ObjectKey(user.Namespace), prefix: nkeys.PrefixByteUser, seedKey: ref.Key})
+		}
+	}
+
+	return secrets, nil
+}
+
+// migrateSeedSecret normalizes the seed key and the label of one Secret
+func migrateSeedSecret(cmd *cobra.Command, c client.Client, s seedSecret, dryRun, keepLegacy bool) error {
+	ctx := cmd.Context()
+	out := cmd.OutOrStdout()
+	seedType := jwtpkg.SeedType(s.prefix)
+	pinned := s.seedKey != "" && s.seedKey != jwtpkg.CanonicalSeedKey(s.prefix)
+
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		secret := &corev1.Secret{}
+		if err := c.Get(ctx, s.key, secret); err != nil {
+			if client.IgnoreNotFound(err) == nil {
+				fmt.Fprintf(out, "%s: not found, skipped\n", s.key)
+				return nil
+			}
+			return fmt.Errorf("failed to get secret %s: %w", s.key, err)
+		}
+
+		var changes []string
+		if !pinned {
+			canonical := jwtpkg.CanonicalSeedKey(s.prefix)
+			from, err := jwtpkg.NormalizeSeedData(secret.Data, s.prefix)
+			if err != nil {
+				fmt.Fprintf(out, "%s: %v, skipped\n", s.key, err)
+				return nil
+			}
+			switch {
+			case from == canonical:
+				changes = append(changes, "trimmed whitespace around seed")
+			case from != "" && keepLegacy:
+				secret.Data[from] = secret.Data[canonical]
+				changes = append(changes, fmt.Sprintf("seed copied from %q to %q", from, canonical))
+			case from != "":
+				changes = append(changes, fmt.Sprintf("seed moved from %q to %q", from, canonical))
+			}
+		}
+		if secret.Labels[jwtpkg.SeedTypeLabel] != seedType {
+			if secret.Labels == nil {
+				secret.Labels = map[string]string{}
+			}
+			secret.Labels[jwtpkg.SeedTypeLabel] = seedType
+			changes = append(changes, fmt.Sprintf("labeled %s=%s", jwtpkg.SeedTypeLabel, seedType))
+		}
+
+		if len(changes) == 0 {
+			fmt.Fprintf(out, "%s: %s seed already migrated\n", s.key, seedType)
+			return nil
+		}
+		fmt.Fprintf(out, "%s: %v\n", s.key, changes)
+		if dryRun {
+			return nil
+		}
+		return c.Update(ctx, secret)
+	})
+}
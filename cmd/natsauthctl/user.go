@@ -0,0 +1,70 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	natsv1alpha1 "github.com/jradikk/nats-auth-operator/api/v1alpha1"
+)
+
+// getUser fetches a NatsUser and its credentials Secret; the Secret is nil when it
+// has not been written yet
+func getUser(ctx context.Context, c client.Client, namespace, name string) (*natsv1alpha1.NatsUser, *corev1.Secret, error) {
+	user := &natsv1alpha1.NatsUser{}
+	if err := c.Get(ctx, client.ObjectKey{Namespace: namespace, Name: name}, user); err != nil {
+		return nil, nil, fmt.Errorf("failed to get NatsUser %s/%s: %w", namespace, name, err)
+	}
+
+	ref := user.Status.SecretRef
+	if ref.Name == "" {
+		return user, nil, nil
+	}
+
+	secret := &corev1.Secret{}
+	key := ref.ObjectKey(user.Namespace)
+	if err := c.Get(ctx, key, secret); err != nil {
+		if errors.IsNotFound(err) {
+			return user, nil, nil
+		}
+		return nil, nil, fmt.Errorf("failed to get credentials secret %s: %w", key, err)
+	}
+	return user, secret, nil
+}
+
+// hasAccountRef reports whether the user belongs to a NatsAccount, through spec.accountRef or the
+// NatsAuthConfig default recorded in status.accountRef
+func hasAccountRef(user *natsv1alpha1.NatsUser) bool {
+	return user.Spec.AccountRef != nil || user.Status.AccountRef != nil
+}
+
+// accountRefKey returns the key of the NatsAccount of the user
+func accountRefKey(user *natsv1alpha1.NatsUser) client.ObjectKey {
+	if user.Spec.AccountRef == nil {
+		return client.ObjectKey{Namespace: user.Status.AccountRef.Namespace, Name: user.Status.AccountRef.Name}
+	}
+	namespace := user.Spec.AccountRef.Namespace
+	if namespace == "" {
+		namespace = user.Namespace
+	}
+	return client.ObjectKey{Namespace: namespace, Name: user.Spec.AccountRef.Name}
+}
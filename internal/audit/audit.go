@@ -0,0 +1,113 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// Actions recorded in the audit log
+const (
+	// ActionIssued is a credential issued for a new key or password
+	ActionIssued = "issued"
+	// ActionReissued is a credential signed again for an existing key, e.g. after a permissions change
+	ActionReissued = "reissued"
+	// ActionImported is an externally issued JWT packaged into a credentials Secret
+	ActionImported = "imported"
+	// ActionRevoked is a user key added to the revocation list of its account
+	ActionRevoked = "revoked"
+	// ActionExchanged is a short-lived user JWT issued for a ServiceAccount token
+	ActionExchanged = "exchanged"
+)
+
+// Credential types recorded in the audit log
+const (
+	CredentialUserJWT    = "user-jwt"
+	CredentialAccountJWT = "account-jwt"
+	CredentialPassword   = "password"
+)
+
+// Event is one credential issuance in the audit log
+type Event struct {
+	// Time the credential was issued
+	Time time.Time `json:"time"`
+
+	// Action is one of issued, reissued, imported, revoked or exchanged
+	Action string `json:"action"`
+
+	// Credential is the type of credential: user-jwt, account-jwt or password
+	Credential string `json:"credential"`
+
+	// Resource is the custom resource the credential belongs to, as Kind/namespace/name
+	Resource string `json:"resource"`
+
+	// Actor identifies the component that issued the credential
+	Actor string `json:"actor"`
+
+	// TraceID is the reconcileID of the reconcile that issued the credential
+	TraceID string `json:"traceID,omitempty"`
+
+	// PublicKey of the user or account
+	PublicKey string `json:"publicKey,omitempty"`
+
+	// Issuer is the public key that signed the JWT
+	Issuer string `json:"issuer,omitempty"`
+
+	// Username of a token user
+	Username string `json:"username,omitempty"`
+
+	// ExpiresAt is the expiry of the JWT, unset when it does not expire
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+
+	// PermissionsHash identifies the user permissions the JWT was signed with
+	PermissionsHash string `json:"permissionsHash,omitempty"`
+
+	// ClaimsHash identifies the account claims the JWT was signed with
+	ClaimsHash string `json:"claimsHash,omitempty"`
+
+	// Secret the credential was written to, as namespace/name
+	Secret string `json:"secret,omitempty"`
+}
+
+// Sink receives audit events
+type Sink interface {
+	Record(ctx context.Context, event Event) error
+}
+
+// JSONSink writes each event as one line of JSON, e.g. to stdout
+type JSONSink struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewJSONSink returns a sink writing JSON lines to w
+func NewJSONSink(w io.Writer) *JSONSink {
+	return &JSONSink{w: w}
+}
+
+// Record writes the event as a JSON line
+func (s *JSONSink) Record(_ context.Context, event Event) error {
+	line, err := marshalLine(event)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := s.w.Write(line); err != nil {
+		return fmt.Errorf("failed to write audit event: %w", err)
+	}
+	return nil
+}
+
+// marshalLine encodes an event as a newline-terminated JSON line
+func marshalLine(event Event) ([]byte, error) {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode audit event: %w", err)
+	}
+	return append(data, '\n'), nil
+}
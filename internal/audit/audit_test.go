@@ -0,0 +1,90 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestJSONSink(t *testing.T) {
+	var buf bytes.Buffer
+	sink := NewJSONSink(&buf)
+
+	event := Event{
+		Time:       time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+		Action:     ActionIssued,
+		Credential: CredentialUserJWT,
+		Resource:   "NatsUser/apps/orders",
+		Actor:      "nats-auth-operator",
+		PublicKey:  "UABC",
+	}
+	for i := 0; i < 2; i++ {
+		if err := sink.Record(context.Background(), event); err != nil {
+			t.Fatalf("Record() error = %v", err)
+		}
+	}
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Record() wrote %d lines, want 2", len(lines))
+	}
+	var got Event
+	if err := json.Unmarshal([]byte(lines[0]), &got); err != nil {
+		t.Fatalf("Record() wrote invalid JSON: %v", err)
+	}
+	if got.Resource != event.Resource || got.PublicKey != event.PublicKey || !got.Time.Equal(event.Time) {
+		t.Errorf("Record() = %+v, want %+v", got, event)
+	}
+	if strings.Contains(lines[0], "expiresAt") {
+		t.Errorf("Record() wrote unset fields: %s", lines[0])
+	}
+}
+
+func TestAppendRing(t *testing.T) {
+	tests := []struct {
+		name   string
+		buffer string
+		line   string
+		size   int
+		want   string
+	}{
+		{
+			name: "Empty buffer",
+			line: "a\n",
+			size: 3,
+			want: "a\n",
+		},
+		{
+			name:   "Below size",
+			buffer: "a\nb\n",
+			line:   "c\n",
+			size:   3,
+			want:   "a\nb\nc\n",
+		},
+		{
+			name:   "Oldest dropped",
+			buffer: "a\nb\nc\n",
+			line:   "d\n",
+			size:   3,
+			want:   "b\nc\nd\n",
+		},
+		{
+			name:   "Shrunk size",
+			buffer: "a\nb\nc\n",
+			line:   "d\n",
+			size:   1,
+			want:   "d\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := appendRing(tt.buffer, tt.line, tt.size); got != tt.want {
+				t.Errorf("appendRing() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package audit
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/jradikk/nats-auth-operator/pkg/labels"
+)
+
+const (
+	// ConfigMapKey holds the events of the ConfigMap ring buffer as JSON lines, oldest first
+	ConfigMapKey = "events.jsonl"
+
+	// DefaultConfigMapSize is the number of events kept in the ConfigMap ring buffer
+	DefaultConfigMapSize = 500
+)
+
+// ConfigMapSink keeps the most recent events in a ConfigMap
+type ConfigMapSink struct {
+	Client client.Client
+
+	// Key of the ConfigMap, created when missing
+	Key client.ObjectKey
+
+	// Size is the number of events kept (defaults to DefaultConfigMapSize)
+	Size int
+}
+
+// Record appends the event to the ConfigMap, dropping the oldest events beyond the size
+func (s *ConfigMapSink) Record(ctx context.Context, event Event) error {
+	line, err := marshalLine(event)
+	if err != nil {
+		return err
+	}
+	size := s.Size
+	if size <= 0 {
+		size = DefaultConfigMapSize
+	}
+
+	err = retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		cm := &corev1.ConfigMap{}
+		err := s.Client.Get(ctx, s.Key, cm)
+		if errors.IsNotFound(err) {
+			cm = &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: s.Key.Name, Namespace: s.Key.Namespace, Labels: labels.Owners{}.Labels()},
+				Data:       map[string]string{ConfigMapKey: string(line)},
+			}
+			if err := s.Client.Create(ctx, cm); errors.IsAlreadyExists(err) {
+				// Created concurrently; retry as an update
+				return errors.NewConflict(corev1.Resource("configmaps"), s.Key.Name, err)
+			} else if err != nil {
+				return err
+			}
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if cm.Data == nil {
+			cm.Data = map[string]string{}
+		}
+		cm.Data[ConfigMapKey] = appendRing(cm.Data[ConfigMapKey], string(line), size)
+		return s.Client.Update(ctx, cm)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to record audit event in ConfigMap %s: %w", s.Key, err)
+	}
+	return nil
+}
+
+// appendRing appends a JSON line to the buffer and keeps at most size lines
+func appendRing(buffer, line string, size int) string {
+	lines := strings.SplitAfter(buffer+line, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	if len(lines) > size {
+		lines = lines[len(lines)-size:]
+	}
+	return strings.Join(lines, "")
+}
@@ -0,0 +1,193 @@
+package audit
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/nats-io/jwt/v2"
+)
+
+// DefaultNATSTimeout bounds connecting to NATS and publishing one event
+const DefaultNATSTimeout = 5 * time.Second
+
+// NATSSink publishes each event to a NATS subject. It opens a connection per event and waits for
+// the server to process the publish, which keeps the sink free of reconnect state; issuance is rare.
+type NATSSink struct {
+	// URL of the server, nats://host:port or tls://host:port
+	URL string
+
+	// Subject events are published to
+	Subject string
+
+	// Creds is the content of a creds file to authenticate with (optional)
+	Creds []byte
+
+	// TLSConfig is used for tls:// URLs and servers requiring TLS (optional)
+	TLSConfig *tls.Config
+
+	// Timeout bounds one publish (defaults to DefaultNATSTimeout)
+	Timeout time.Duration
+}
+
+// serverInfo holds the INFO fields the sink needs
+type serverInfo struct {
+	Nonce       string `json:"nonce"`
+	TLSRequired bool   `json:"tls_required"`
+}
+
+// connectOptions is the CONNECT payload
+type connectOptions struct {
+	Verbose  bool   `json:"verbose"`
+	Pedantic bool   `json:"pedantic"`
+	Name     string `json:"name"`
+	Lang     string `json:"lang"`
+	Protocol int    `json:"protocol"`
+	JWT      string `json:"jwt,omitempty"`
+	Sig      string `json:"sig,omitempty"`
+}
+
+// Record publishes the event as JSON
+func (s *NATSSink) Record(ctx context.Context, event Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit event: %w", err)
+	}
+	if err := s.publish(ctx, payload); err != nil {
+		return fmt.Errorf("failed to publish audit event to %s: %w", s.Subject, err)
+	}
+	return nil
+}
+
+// Ping connects and authenticates to the server without publishing, so the readiness of the
+// sink can be checked before an event has to be recorded
+func (s *NATSSink) Ping(ctx context.Context) error {
+	if err := s.publish(ctx, nil); err != nil {
+		return fmt.Errorf("NATS server %s: %w", s.URL, err)
+	}
+	return nil
+}
+
+// publish sends payload to the subject and waits for the server to process it; a nil payload
+// only connects
+func (s *NATSSink) publish(ctx context.Context, payload []byte) error {
+	u, err := url.Parse(s.URL)
+	if err != nil {
+		return fmt.Errorf("invalid NATS URL: %w", err)
+	}
+	host := u.Host
+	if u.Port() == "" {
+		host = net.JoinHostPort(u.Hostname(), "4222")
+	}
+
+	timeout := s.Timeout
+	if timeout <= 0 {
+		timeout = DefaultNATSTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", host)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	deadline, _ := ctx.Deadline()
+	if err := conn.SetDeadline(deadline); err != nil {
+		return err
+	}
+
+	r := bufio.NewReader(conn)
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return fmt.Errorf("failed to read server INFO: %w", err)
+	}
+	infoJSON, ok := strings.CutPrefix(line, "INFO ")
+	if !ok {
+		return fmt.Errorf("unexpected server greeting %q", strings.TrimSpace(line))
+	}
+	var info serverInfo
+	if err := json.Unmarshal([]byte(infoJSON), &info); err != nil {
+		return fmt.Errorf("invalid server INFO: %w", err)
+	}
+
+	if u.Scheme == "tls" || info.TLSRequired {
+		config := &tls.Config{MinVersion: tls.VersionTLS12}
+		if s.TLSConfig != nil {
+			config = s.TLSConfig.Clone()
+		}
+		if config.ServerName == "" {
+			config.ServerName = u.Hostname()
+		}
+		tlsConn := tls.Client(conn, config)
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			return fmt.Errorf("TLS handshake failed: %w", err)
+		}
+		conn = tlsConn
+		r = bufio.NewReader(conn)
+	}
+
+	connect := connectOptions{Name: "nats-auth-operator-audit", Lang: "go", Protocol: 1}
+	if len(s.Creds) > 0 {
+		if connect.JWT, connect.Sig, err = signNonce(s.Creds, info.Nonce); err != nil {
+			return err
+		}
+	}
+	connectJSON, err := json.Marshal(connect)
+	if err != nil {
+		return fmt.Errorf("failed to encode CONNECT: %w", err)
+	}
+
+	msg := fmt.Sprintf("CONNECT %s\r\n", connectJSON)
+	if payload != nil {
+		msg += fmt.Sprintf("PUB %s %d\r\n%s\r\n", s.Subject, len(payload), payload)
+	}
+	msg += "PING\r\n"
+	if _, err := conn.Write([]byte(msg)); err != nil {
+		return err
+	}
+
+	// The server answers PONG once CONNECT and PUB are processed, or -ERR on failure
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return fmt.Errorf("failed to read server response: %w", err)
+		}
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "PONG":
+			return nil
+		case strings.HasPrefix(line, "-ERR"):
+			return fmt.Errorf("server error: %s", strings.TrimSpace(strings.TrimPrefix(line, "-ERR")))
+		case line == "PING":
+			if _, err := conn.Write([]byte("PONG\r\n")); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// signNonce returns the user JWT of a creds file and the signature of the server nonce
+func signNonce(creds []byte, nonce string) (string, string, error) {
+	userJWT, err := jwt.ParseDecoratedJWT(creds)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid audit creds: %w", err)
+	}
+	kp, err := jwt.ParseDecoratedNKey(creds)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid audit creds: %w", err)
+	}
+	sig, err := kp.Sign([]byte(nonce))
+	if err != nil {
+		return "", "", fmt.Errorf("failed to sign server nonce: %w", err)
+	}
+	return userJWT, base64.RawURLEncoding.EncodeToString(sig), nil
+}
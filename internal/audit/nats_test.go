@@ -0,0 +1,168 @@
+package audit
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nats-io/jwt/v2"
+	"github.com/nats-io/nkeys"
+)
+
+// fakeServer accepts one connection, speaks enough of the NATS protocol for a publish
+// and returns the CONNECT options and published payload
+func fakeServer(t *testing.T, nonce, reply string) (string, <-chan [2]string) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	got := make(chan [2]string, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		fmt.Fprintf(conn, "INFO {\"server_id\":\"test\",\"nonce\":%q}\r\n", nonce)
+
+		r := bufio.NewReader(conn)
+		connect, _ := r.ReadString('\n')
+		pub, _ := r.ReadString('\n')
+		payload, _ := r.ReadString('\n')
+		ping, _ := r.ReadString('\n')
+		if !strings.HasPrefix(pub, "PUB audit.credentials ") || ping != "PING\r\n" {
+			fmt.Fprintf(conn, "-ERR 'Unknown Protocol Operation'\r\n")
+			return
+		}
+		fmt.Fprint(conn, reply)
+		got <- [2]string{strings.TrimPrefix(strings.TrimSpace(connect), "CONNECT "), strings.TrimSpace(payload)}
+	}()
+	return "nats://" + ln.Addr().String(), got
+}
+
+func testCreds(t *testing.T) ([]byte, string) {
+	t.Helper()
+	account, _ := nkeys.CreateAccount()
+	user, _ := nkeys.CreateUser()
+	userPub, _ := user.PublicKey()
+	claims := jwt.NewUserClaims(userPub)
+	token, err := claims.Encode(account)
+	if err != nil {
+		t.Fatalf("Failed to encode user JWT: %v", err)
+	}
+	seed, _ := user.Seed()
+	creds, err := jwt.FormatUserConfig(token, seed)
+	if err != nil {
+		t.Fatalf("Failed to format creds: %v", err)
+	}
+	return creds, userPub
+}
+
+func TestNATSSinkRecord(t *testing.T) {
+	creds, userPub := testCreds(t)
+
+	tests := []struct {
+		name    string
+		creds   []byte
+		reply   string
+		wantErr bool
+	}{
+		{
+			name:  "Without credentials",
+			reply: "PONG\r\n",
+		},
+		{
+			name:  "With credentials",
+			creds: creds,
+			reply: "PONG\r\n",
+		},
+		{
+			name:    "Server error",
+			reply:   "-ERR 'Authorization Violation'\r\n",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			url, got := fakeServer(t, "nonce-123", tt.reply)
+			sink := &NATSSink{URL: url, Subject: "audit.credentials", Creds: tt.creds, Timeout: 2 * time.Second}
+
+			err := sink.Record(context.Background(), Event{Action: ActionIssued, Resource: "NatsUser/apps/orders"})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Record() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			received := <-got
+			var connect connectOptions
+			if err := json.Unmarshal([]byte(received[0]), &connect); err != nil {
+				t.Fatalf("Invalid CONNECT: %v", err)
+			}
+			if !strings.Contains(received[1], `"resource":"NatsUser/apps/orders"`) {
+				t.Errorf("Published payload = %s", received[1])
+			}
+			if tt.creds == nil {
+				if connect.JWT != "" || connect.Sig != "" {
+					t.Errorf("CONNECT carries credentials without creds: %+v", connect)
+				}
+				return
+			}
+
+			sig, err := base64.RawURLEncoding.DecodeString(connect.Sig)
+			if err != nil {
+				t.Fatalf("Invalid signature encoding: %v", err)
+			}
+			kp, _ := nkeys.FromPublicKey(userPub)
+			if err := kp.Verify([]byte("nonce-123"), sig); err != nil {
+				t.Errorf("Nonce signature does not verify: %v", err)
+			}
+		})
+	}
+}
+
+func TestNATSSinkPing(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		fmt.Fprint(conn, "INFO {\"server_id\":\"test\"}\r\n")
+
+		r := bufio.NewReader(conn)
+		_, _ = r.ReadString('\n')
+		if ping, _ := r.ReadString('\n'); ping != "PING\r\n" {
+			fmt.Fprintf(conn, "-ERR 'Unexpected %s'\r\n", strings.TrimSpace(ping))
+			return
+		}
+		fmt.Fprint(conn, "PONG\r\n")
+	}()
+
+	sink := &NATSSink{URL: "nats://" + ln.Addr().String(), Subject: "audit.credentials", Timeout: 2 * time.Second}
+	if err := sink.Ping(context.Background()); err != nil {
+		t.Fatalf("Ping() error = %v", err)
+	}
+
+	ln.Close()
+	if err := sink.Ping(context.Background()); err == nil {
+		t.Error("Ping() succeeded without a server")
+	}
+}
@@ -0,0 +1,55 @@
+package authconf
+
+// TokenAccount represents a static account in the accounts block of a token-mode config
+type TokenAccount struct {
+	Name     string
+	Users    []TokenUser
+	Exports  []TokenExport
+	Imports  []TokenImport
+	Mappings []TokenMapping
+}
+
+// TokenExport is a stream or service an account shares; Accounts restricts the importers.
+// ResponseType and Latency apply to services only.
+type TokenExport struct {
+	Type         string
+	Subject      string
+	Accounts     []string
+	ResponseType string
+	Latency      *TokenLatency
+}
+
+// TokenLatency enables latency tracking of a service export
+type TokenLatency struct {
+	// Sampling is the percentage of requests tracked; 0 tracks requests carrying tracing headers
+	Sampling int
+	Subject  string
+}
+
+// TokenMapping routes a published subject to weighted destinations
+type TokenMapping struct {
+	Subject      string
+	Destinations []TokenMappingDestination
+}
+
+// TokenMappingDestination is a destination of a subject mapping; a zero weight means 100%
+type TokenMappingDestination struct {
+	Subject string
+	Weight  int
+	Cluster string
+}
+
+// TokenImport is a stream or service taken from another account
+type TokenImport struct {
+	Type    string
+	Account string
+	Subject string
+	Prefix  string
+	To      string
+}
+
+// RenderAccountsConf generates the accounts block for multi-tenant token-mode setups with the
+// built-in templates
+func RenderAccountsConf(accounts []TokenAccount) string {
+	return defaultRenderer.mustExecute("accounts", accounts)
+}
@@ -0,0 +1,113 @@
+package authconf
+
+import (
+	"testing"
+)
+
+func TestRenderAccountsConf(t *testing.T) {
+	tests := []struct {
+		name     string
+		accounts []TokenAccount
+		want     string
+	}{
+		{
+			name:     "No accounts",
+			accounts: nil,
+			want:     "",
+		},
+		{
+			name: "Account with users only",
+			accounts: []TokenAccount{
+				{
+					Name:  "team-a",
+					Users: []TokenUser{{Username: "alice", Password: "secret"}},
+				},
+			},
+			want: `accounts {
+  "team-a": {
+    users = [
+      {
+        user: "alice"
+        password: "secret"
+      }
+    ]
+  }
+}
+`,
+		},
+		{
+			name: "Exports and imports between accounts",
+			accounts: []TokenAccount{
+				{
+					Name: "provider",
+					Exports: []TokenExport{
+						{Type: "stream", Subject: "events.>"},
+						{Type: "service", Subject: "api.time", Accounts: []string{"consumer"}},
+						{Type: "service", Subject: "api.feed", ResponseType: "Stream", Latency: &TokenLatency{Sampling: 50, Subject: "latency.feed"}},
+						{Type: "service", Subject: "api.trace", Latency: &TokenLatency{Subject: "latency.trace"}},
+					},
+				},
+				{
+					Name: "consumer",
+					Imports: []TokenImport{
+						{Type: "stream", Account: "provider", Subject: "events.>", Prefix: "provider"},
+						{Type: "service", Account: "provider", Subject: "api.time", To: "time"},
+					},
+				},
+			},
+			want: `accounts {
+  "provider": {
+    exports = [
+      {stream: "events.>"}
+      {service: "api.time", accounts: ["consumer"]}
+      {service: "api.feed", response_type: "stream", latency: {sampling: 50, subject: "latency.feed"}}
+      {service: "api.trace", latency: {sampling: "headers", subject: "latency.trace"}}
+    ]
+  }
+  "consumer": {
+    imports = [
+      {stream: {account: "provider", subject: "events.>"}, prefix: "provider"}
+      {service: {account: "provider", subject: "api.time"}, to: "time"}
+    ]
+  }
+}
+`,
+		},
+		{
+			name: "Weighted subject mappings",
+			accounts: []TokenAccount{
+				{
+					Name: "orders",
+					Mappings: []TokenMapping{
+						{
+							Subject: "orders.>",
+							Destinations: []TokenMappingDestination{
+								{Subject: "orders.v2.>", Weight: 10},
+								{Subject: "orders.v1.>", Weight: 90, Cluster: "east"},
+							},
+						},
+					},
+				},
+			},
+			want: `accounts {
+  "orders": {
+    mappings = {
+      "orders.>": [
+        {destination: "orders.v2.>", weight: 10}
+        {destination: "orders.v1.>", weight: 90, cluster: "east"}
+      ]
+    }
+  }
+}
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := RenderAccountsConf(tt.accounts); got != tt.want {
+				t.Errorf("RenderAccountsConf() =\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package authconf
+
+import (
+	"fmt"
+	"strings"
+)
+
+// BootstrapConfKey is the ConfigMap key holding the generated server config
+const BootstrapConfKey = "nats-server.conf"
+
+// DefaultClientPort is the default client listener port
+const DefaultClientPort = 4222
+
+// BootstrapOptions represents a minimal nats-server.conf
+type BootstrapOptions struct {
+	Port              int32
+	JetStreamStoreDir string
+	// AuthConf is inlined as-is (JWT mode operator and resolver settings)
+	AuthConf string
+	// Includes are files included relative to the config file (token mode auth.conf, listener blocks)
+	Includes []string
+}
+
+// RenderBootstrapConf generates a minimal nats-server.conf a fresh server can boot from
+func RenderBootstrapConf(opts BootstrapOptions) string {
+	port := opts.Port
+	if port == 0 {
+		port = DefaultClientPort
+	}
+
+	var sb strings.Builder
+
+	sb.WriteString("# Generated by nats-auth-operator\n")
+	sb.WriteString(fmt.Sprintf("listen: \"0.0.0.0:%d\"\n", port))
+
+	if opts.JetStreamStoreDir != "" {
+		sb.WriteString("\njetstream {\n")
+		sb.WriteString(fmt.Sprintf("  store_dir: %s\n", quote(opts.JetStreamStoreDir)))
+		sb.WriteString("}\n")
+	}
+
+	if opts.AuthConf != "" {
+		sb.WriteString("\n")
+		sb.WriteString(opts.AuthConf)
+	}
+
+	if len(opts.Includes) > 0 {
+		sb.WriteString("\n")
+		for _, include := range opts.Includes {
+			sb.WriteString(fmt.Sprintf("include %s\n", quote(include)))
+		}
+	}
+
+	return sb.String()
+}
+
+// RenderMemoryResolverConf generates operator mode settings with a memory resolver
+// preloaded with the given account JWTs
+func RenderMemoryResolverConf(operatorJWT string, accounts []AccountJWT) string {
+	var sb strings.Builder
+
+	sb.WriteString(RenderJWTAuthConfWithPreload(operatorJWT, accounts))
+	sb.WriteString("resolver: MEMORY\n")
+
+	return sb.String()
+}
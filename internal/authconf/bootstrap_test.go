@@ -0,0 +1,70 @@
+package authconf
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRenderBootstrapConf(t *testing.T) {
+	tests := []struct {
+		name string
+		opts BootstrapOptions
+		want string
+	}{
+		{
+			name: "Defaults",
+			opts: BootstrapOptions{},
+			want: "# Generated by nats-auth-operator\nlisten: \"0.0.0.0:4222\"\n",
+		},
+		{
+			name: "Token mode with JetStream and includes",
+			opts: BootstrapOptions{
+				Port:              4333,
+				JetStreamStoreDir: "/data/jetstream",
+				Includes:          []string{"auth.conf", "websocket.conf"},
+			},
+			want: `# Generated by nats-auth-operator
+listen: "0.0.0.0:4333"
+
+jetstream {
+  store_dir: "/data/jetstream"
+}
+
+include "auth.conf"
+include "websocket.conf"
+`,
+		},
+		{
+			name: "JWT mode inlines the auth config",
+			opts: BootstrapOptions{
+				AuthConf: "operator: OPJWT\nresolver: MEMORY\n",
+			},
+			want: `# Generated by nats-auth-operator
+listen: "0.0.0.0:4222"
+
+operator: OPJWT
+resolver: MEMORY
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := RenderBootstrapConf(tt.opts); got != tt.want {
+				t.Errorf("RenderBootstrapConf() =\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderMemoryResolverConf(t *testing.T) {
+	got := RenderMemoryResolverConf("OPJWT", []AccountJWT{
+		{AccountName: "app", AccountID: "ACAPP", JWT: "ACCJWT"},
+	})
+
+	for _, want := range []string{"operator: OPJWT", `"ACAPP": "ACCJWT"`, "resolver: MEMORY"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("RenderMemoryResolverConf() missing %q in:\n%s", want, got)
+		}
+	}
+}
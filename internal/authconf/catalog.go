@@ -0,0 +1,75 @@
+package authconf
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// Keys of the public catalog ConfigMap
+const (
+	CatalogNatsURLKey     = "natsURL"
+	CatalogOperatorKey    = "operator.pub"
+	CatalogOperatorJWTKey = "operator.jwt"
+	CatalogAccountsKey    = "accounts.json"
+)
+
+// Catalog is the non-sensitive connection information of a NatsAuthConfig published for app teams
+type Catalog struct {
+	NatsURL           string
+	OperatorPublicKey string
+	OperatorJWT       string
+	Accounts          []CatalogAccount
+}
+
+// CatalogAccount is an account listed in the catalog with the subjects it exports
+type CatalogAccount struct {
+	Name      string          `json:"name"`
+	Namespace string          `json:"namespace"`
+	PublicKey string          `json:"publicKey,omitempty"`
+	Exports   []CatalogExport `json:"exports,omitempty"`
+}
+
+// CatalogExport is an exported subject; Restricted exports can only be imported by listed accounts
+type CatalogExport struct {
+	Type       string `json:"type"`
+	Subject    string `json:"subject"`
+	Restricted bool   `json:"restricted,omitempty"`
+}
+
+// RenderCatalog returns the ConfigMap data of the catalog. Accounts are sorted by namespace and
+// name so the data only changes with its content.
+func RenderCatalog(catalog Catalog) (map[string]string, error) {
+	accounts := append([]CatalogAccount(nil), catalog.Accounts...)
+	sort.Slice(accounts, func(i, j int) bool {
+		if accounts[i].Namespace != accounts[j].Namespace {
+			return accounts[i].Namespace < accounts[j].Namespace
+		}
+		return accounts[i].Name < accounts[j].Name
+	})
+	if accounts == nil {
+		accounts = []CatalogAccount{}
+	}
+
+	// Subjects keep their wildcards readable instead of HTML-escaping ">"
+	var encoded bytes.Buffer
+	enc := json.NewEncoder(&encoded)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(accounts); err != nil {
+		return nil, fmt.Errorf("failed to encode account catalog: %w", err)
+	}
+
+	data := map[string]string{
+		CatalogNatsURLKey:  catalog.NatsURL,
+		CatalogAccountsKey: encoded.String(),
+	}
+	if catalog.OperatorPublicKey != "" {
+		data[CatalogOperatorKey] = catalog.OperatorPublicKey
+	}
+	if catalog.OperatorJWT != "" {
+		data[CatalogOperatorJWTKey] = catalog.OperatorJWT
+	}
+	return data, nil
+}
@@ -0,0 +1,84 @@
+package authconf
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRenderCatalog(t *testing.T) {
+	tests := []struct {
+		name    string
+		catalog Catalog
+		want    map[string]string
+	}{
+		{
+			name:    "Token mode without accounts",
+			catalog: Catalog{NatsURL: "nats://nats:4222"},
+			want: map[string]string{
+				CatalogNatsURLKey:  "nats://nats:4222",
+				CatalogAccountsKey: "[]\n",
+			},
+		},
+		{
+			name: "Accounts sorted by namespace and name",
+			catalog: Catalog{
+				NatsURL:           "nats://nats:4222",
+				OperatorPublicKey: "OPUB",
+				OperatorJWT:       "operator-jwt",
+				Accounts: []CatalogAccount{
+					{Name: "orders", Namespace: "team-b", PublicKey: "AORDERS"},
+					{
+						Name:      "time",
+						Namespace: "team-a",
+						PublicKey: "ATIME",
+						Exports: []CatalogExport{
+							{Type: "service", Subject: "api.time"},
+							{Type: "stream", Subject: "ticks.>", Restricted: true},
+						},
+					},
+				},
+			},
+			want: map[string]string{
+				CatalogNatsURLKey:     "nats://nats:4222",
+				CatalogOperatorKey:    "OPUB",
+				CatalogOperatorJWTKey: "operator-jwt",
+				CatalogAccountsKey: `[
+  {
+    "name": "time",
+    "namespace": "team-a",
+    "publicKey": "ATIME",
+    "exports": [
+      {
+        "type": "service",
+        "subject": "api.time"
+      },
+      {
+        "type": "stream",
+        "subject": "ticks.>",
+        "restricted": true
+      }
+    ]
+  },
+  {
+    "name": "orders",
+    "namespace": "team-b",
+    "publicKey": "AORDERS"
+  }
+]
+`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := RenderCatalog(tt.catalog)
+			if err != nil {
+				t.Fatalf("RenderCatalog() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("RenderCatalog() =\n%v\nwant:\n%v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,121 @@
+package authconf
+
+import (
+	"fmt"
+	"strings"
+
+	natsv1alpha1 "github.com/jradikk/nats-auth-operator/api/v1alpha1"
+)
+
+// MaxConfigSize is the limit the API server puts on the data of one Secret or ConfigMap
+const MaxConfigSize = 1 << 20
+
+// ConfigSizeWarning is the size from which a server auth config is reported as approaching
+// MaxConfigSize, and from which a config under the Chunk size policy is chunked
+const ConfigSizeWarning = MaxConfigSize * 9 / 10
+
+// ChunkSize is the size the chunks of a server auth config are filled up to, leaving room for
+// the signature and for accounts to grow before the chunks are redistributed
+const ChunkSize = MaxConfigSize * 3 / 4
+
+// ConfigChunkLabel numbers the overflow Secrets of a chunked server auth Secret, from 1; overflow
+// Secret i is named after the server auth Secret with the suffix "-i"
+const ConfigChunkLabel = "nats.jradikk/config-chunk"
+
+// PreloadChunkKey returns the key of the resolver_preload entries of chunk i
+func PreloadChunkKey(i int) string {
+	return fmt.Sprintf("preload-%d.conf", i)
+}
+
+// DataSize returns the size of data as counted against MaxConfigSize, keys included
+func DataSize(data map[string][]byte) int {
+	size := 0
+	for k, v := range data {
+		size += len(k) + len(v)
+	}
+	return size
+}
+
+// ChunkServerAuthSecretData spreads data, built by BuildServerAuthSecretData for accounts in the
+// given key format, over chunks of at most chunkSize. The first chunk keeps every key that is not
+// an account JWT; the account JWTs fill it and then further chunks, in the order of accounts.
+// When data holds PreloadConfKey, the resolver_preload entries of each chunk are written to
+// PreloadChunkKey(i) of that chunk and PreloadConfKey includes them all, so every chunk must be
+// mounted into one directory. Keys are unique across chunks.
+func ChunkServerAuthSecretData(data map[string][]byte, accounts []AccountJWT, format natsv1alpha1.AccountKeyFormat, chunkSize int) []map[string][]byte {
+	first := make(map[string][]byte, len(data))
+	for k, v := range data {
+		first[k] = v
+	}
+	for _, acc := range accounts {
+		for _, key := range accountKeys(acc, format) {
+			delete(first, key)
+		}
+	}
+	_, preload := first[PreloadConfKey]
+	delete(first, PreloadConfKey)
+
+	chunks := []map[string][]byte{first}
+	var preloads [][]AccountJWT
+	if preload {
+		preloads = [][]AccountJWT{nil}
+	}
+	size := DataSize(first)
+	for _, acc := range accounts {
+		cost := 0
+		for _, key := range accountKeys(acc, format) {
+			cost += len(key) + len(acc.JWT)
+		}
+		if preload {
+			cost += len(renderPreloadEntry(acc))
+		}
+		if size > 0 && size+cost > chunkSize {
+			chunks = append(chunks, map[string][]byte{})
+			if preload {
+				preloads = append(preloads, nil)
+			}
+			size = 0
+		}
+		i := len(chunks) - 1
+		for _, key := range accountKeys(acc, format) {
+			chunks[i][key] = []byte(acc.JWT)
+		}
+		if preload {
+			preloads[i] = append(preloads[i], acc)
+		}
+		size += cost
+	}
+
+	if preload {
+		var sb strings.Builder
+		sb.WriteString("resolver_preload: {\n")
+		for i := range chunks {
+			var entries strings.Builder
+			for _, acc := range preloads[i] {
+				entries.WriteString(renderPreloadEntry(acc))
+			}
+			chunks[i][PreloadChunkKey(i)] = []byte(entries.String())
+			sb.WriteString(fmt.Sprintf("  include %s\n", quote(PreloadChunkKey(i))))
+		}
+		sb.WriteString("}\n")
+		first[PreloadConfKey] = []byte(sb.String())
+	}
+	return chunks
+}
+
+// accountKeys returns the keys BuildServerAuthSecretData writes the JWT of the account to
+func accountKeys(acc AccountJWT, format natsv1alpha1.AccountKeyFormat) []string {
+	switch format {
+	case natsv1alpha1.AccountKeyFormatPublicKey:
+		return []string{acc.AccountID}
+	case natsv1alpha1.AccountKeyFormatNameAndPublicKey:
+		return []string{acc.AccountName, acc.AccountID}
+	default:
+		return []string{acc.AccountName}
+	}
+}
+
+// renderPreloadEntry renders the resolver_preload entry of the account as a line of its own
+func renderPreloadEntry(acc AccountJWT) string {
+	return fmt.Sprintf("%s: %s\n", quote(acc.AccountID), quote(acc.JWT))
+}
@@ -0,0 +1,105 @@
+package authconf
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	natsv1alpha1 "github.com/jradikk/nats-auth-operator/api/v1alpha1"
+)
+
+func TestDataSize(t *testing.T) {
+	data := map[string][]byte{"operator": []byte("jwt"), "app": []byte("jwt-a")}
+	if got := DataSize(data); got != len("operator")+3+len("app")+5 {
+		t.Errorf("DataSize() = %d", got)
+	}
+}
+
+func TestChunkServerAuthSecretData(t *testing.T) {
+	jwt := strings.Repeat("x", 100)
+	accounts := []AccountJWT{
+		{AccountName: "a", AccountNamespace: "ns", AccountID: "ACA", JWT: jwt},
+		{AccountName: "b", AccountNamespace: "ns", AccountID: "ACB", JWT: jwt},
+		{AccountName: "c", AccountNamespace: "ns", AccountID: "ACC", JWT: jwt},
+	}
+
+	tests := []struct {
+		name     string
+		format   natsv1alpha1.AccountKeyFormat
+		preload  bool
+		size     int
+		wantKeys [][]string
+	}{
+		{
+			name:     "Fits one chunk",
+			size:     1000,
+			wantKeys: [][]string{{"a", "b", "c", "operator"}},
+		},
+		{
+			name:     "Name keys",
+			size:     250,
+			wantKeys: [][]string{{"a", "b", "operator"}, {"c"}},
+		},
+		{
+			name:     "Name and public key stay together",
+			format:   natsv1alpha1.AccountKeyFormatNameAndPublicKey,
+			size:     250,
+			wantKeys: [][]string{{"ACA", "a", "operator"}, {"ACB", "b"}, {"ACC", "c"}},
+		},
+		{
+			name:     "Preload",
+			format:   natsv1alpha1.AccountKeyFormatPublicKey,
+			preload:  true,
+			size:     300,
+			wantKeys: [][]string{{"ACA", "operator", "preload-0.conf", "preload.conf"}, {"ACB", "preload-1.conf"}, {"ACC", "preload-2.conf"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, _, err := BuildServerAuthSecretData("operator-jwt", accounts, tt.format)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.preload {
+				data[PreloadConfKey] = []byte(RenderPreloadConf(accounts))
+			}
+
+			chunks := ChunkServerAuthSecretData(data, accounts, tt.format, tt.size)
+
+			var gotKeys [][]string
+			for _, chunk := range chunks {
+				var keys []string
+				for k := range chunk {
+					keys = append(keys, k)
+				}
+				sort.Strings(keys)
+				gotKeys = append(gotKeys, keys)
+			}
+			if !reflect.DeepEqual(gotKeys, tt.wantKeys) {
+				t.Fatalf("keys = %v, want %v", gotKeys, tt.wantKeys)
+			}
+			if !tt.preload {
+				for i, chunk := range chunks {
+					if DataSize(chunk) > tt.size {
+						t.Errorf("chunk %d holds %d bytes, more than %d", i, DataSize(chunk), tt.size)
+					}
+				}
+				return
+			}
+
+			wantPreload := "resolver_preload: {\n" +
+				"  include \"preload-0.conf\"\n" +
+				"  include \"preload-1.conf\"\n" +
+				"  include \"preload-2.conf\"\n" +
+				"}\n"
+			if got := string(chunks[0][PreloadConfKey]); got != wantPreload {
+				t.Errorf("preload.conf =\n%s\nwant\n%s", got, wantPreload)
+			}
+			if got, want := string(chunks[1][PreloadChunkKey(1)]), "\"ACB\": \""+jwt+"\"\n"; got != want {
+				t.Errorf("preload-1.conf = %q, want %q", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package authconf
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Server auth config keys of the split include files, so each server role includes only the
+// settings it needs
+const (
+	OperatorConfKey      = "operator.conf"
+	ResolverConfKey      = "resolver.conf"
+	PreloadConfKey       = "preload.conf"
+	AuthorizationConfKey = "authorization.conf"
+)
+
+// RenderOperatorConf generates the operator setting
+func RenderOperatorConf(operatorJWT string) string {
+	return "operator: " + operatorJWT + "\n"
+}
+
+// RenderResolverConf generates a full account resolver storing account JWTs in resolverDir
+func RenderResolverConf(resolverDir string) string {
+	return fmt.Sprintf(`resolver: {
+  type: full
+  dir: %s
+  allow_delete: false
+  interval: "2m"
+}
+`, quote(resolverDir))
+}
+
+// RenderPreloadConf generates the resolver_preload setting with the account JWTs; empty without
+// accounts, as the server rejects an empty resolver_preload
+func RenderPreloadConf(accounts []AccountJWT) string {
+	if len(accounts) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString("resolver_preload: {\n")
+	for i, acc := range accounts {
+		sb.WriteString(fmt.Sprintf("  %s: %s", quote(acc.AccountID), quote(acc.JWT)))
+		if i < len(accounts)-1 {
+			sb.WriteString(",")
+		}
+		sb.WriteString("\n")
+	}
+	sb.WriteString("}\n")
+	return sb.String()
+}
@@ -0,0 +1,52 @@
+package authconf
+
+import (
+	"testing"
+)
+
+func TestRenderIncludes(t *testing.T) {
+	accounts := []AccountJWT{
+		{AccountName: "app", AccountNamespace: "team-a", AccountID: "ACAAA", JWT: "jwt-a"},
+		{AccountName: "system", AccountNamespace: "nats", AccountID: "ACSYS", JWT: "jwt-sys"},
+	}
+
+	tests := []struct {
+		name string
+		got  string
+		want string
+	}{
+		{name: "Operator", got: RenderOperatorConf("eyJ.operator"), want: "operator: eyJ.operator\n"},
+		{
+			name: "Resolver",
+			got:  RenderResolverConf("/data/resolver"),
+			want: "resolver: {\n  type: full\n  dir: \"/data/resolver\"\n  allow_delete: false\n  interval: \"2m\"\n}\n",
+		},
+		{name: "Preload", got: RenderPreloadConf(accounts), want: "resolver_preload: {\n  \"ACAAA\": \"jwt-a\",\n  \"ACSYS\": \"jwt-sys\"\n}\n"},
+		{name: "Preload without accounts", got: RenderPreloadConf(nil), want: ""},
+		{
+			name: "Includes add up to the JWT auth config",
+			got:  RenderJWTAuthConf("eyJ.operator", "/data/resolver"),
+			want: RenderOperatorConf("eyJ.operator") + "\n" + RenderResolverConf("/data/resolver"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got != tt.want {
+				t.Errorf("got\n%s\nwant\n%s", tt.got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderAuthorizationConf(t *testing.T) {
+	users := []TokenUser{{Username: "admin", Password: "pw"}}
+
+	got, err := DefaultRenderer().RenderAuthorizationConf(users)
+	if err != nil {
+		t.Fatalf("RenderAuthorizationConf() error = %v", err)
+	}
+	if want := RenderTokenAuthConf(users); got != want {
+		t.Errorf("RenderAuthorizationConf() =\n%s\nwant\n%s", got, want)
+	}
+}
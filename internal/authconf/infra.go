@@ -0,0 +1,49 @@
+package authconf
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Secret keys for the rendered cluster and gateway authorization
+const (
+	ClusterConfKey     = "cluster.conf"
+	ClusterUsernameKey = "cluster.username"
+	ClusterPasswordKey = "cluster.password"
+	GatewayConfKey     = "gateway.conf"
+	GatewayUsernameKey = "gateway.username"
+	GatewayPasswordKey = "gateway.password"
+)
+
+// InfraAuth represents the credentials for a cluster or gateway authorization block
+type InfraAuth struct {
+	Username string
+	Password string
+	Timeout  int32
+}
+
+// RenderClusterAuthConf generates the cluster authorization block for routes
+func RenderClusterAuthConf(auth InfraAuth) string {
+	return renderInfraAuthConf("cluster", auth)
+}
+
+// RenderGatewayAuthConf generates the gateway authorization block for gateways
+func RenderGatewayAuthConf(auth InfraAuth) string {
+	return renderInfraAuthConf("gateway", auth)
+}
+
+func renderInfraAuthConf(block string, auth InfraAuth) string {
+	var sb strings.Builder
+
+	sb.WriteString(fmt.Sprintf("%s {\n", block))
+	sb.WriteString("  authorization {\n")
+	sb.WriteString(fmt.Sprintf("    user: %s\n", quote(auth.Username)))
+	sb.WriteString(fmt.Sprintf("    password: %s\n", quote(auth.Password)))
+	if auth.Timeout > 0 {
+		sb.WriteString(fmt.Sprintf("    timeout: %d\n", auth.Timeout))
+	}
+	sb.WriteString("  }\n")
+	sb.WriteString("}\n")
+
+	return sb.String()
+}
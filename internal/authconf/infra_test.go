@@ -0,0 +1,49 @@
+package authconf
+
+import (
+	"testing"
+)
+
+func TestRenderInfraAuthConf(t *testing.T) {
+	tests := []struct {
+		name   string
+		render func(InfraAuth) string
+		auth   InfraAuth
+		want   string
+	}{
+		{
+			name:   "Cluster without timeout",
+			render: RenderClusterAuthConf,
+			auth:   InfraAuth{Username: "route", Password: "s3cret"},
+			want: `cluster {
+  authorization {
+    user: "route"
+    password: "s3cret"
+  }
+}
+`,
+		},
+		{
+			name:   "Gateway with timeout",
+			render: RenderGatewayAuthConf,
+			auth:   InfraAuth{Username: "gateway", Password: "p\"w", Timeout: 2},
+			want: `gateway {
+  authorization {
+    user: "gateway"
+    password: "p\"w"
+    timeout: 2
+  }
+}
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.render(tt.auth)
+			if got != tt.want {
+				t.Errorf("got:\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,129 @@
+package authconf
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/nats-io/nkeys"
+)
+
+// IntegritySuffix is appended to the rendered config key to name the key holding its signature
+const IntegritySuffix = ".sig"
+
+// ErrConfigDrift is returned when a written server auth config no longer matches its signature
+var ErrConfigDrift = errors.New("server auth config does not match its signature")
+
+// Integrity is the signature of a written server auth config, stored as JSON next to it
+type Integrity struct {
+	// Hashes maps each signed data key to the hex SHA-256 of its value
+	Hashes map[string]string `json:"sha256"`
+
+	// Signer is the public key of the operator that signed the hashes; empty for a plain checksum
+	Signer string `json:"signer,omitempty"`
+
+	// Signature of the hashes by the signer, base64url encoded
+	Signature string `json:"signature,omitempty"`
+}
+
+// IntegrityKey returns the data key the signature of the config under key is stored in
+func IntegrityKey(key string) string {
+	return key + IntegritySuffix
+}
+
+// SignConfigData hashes every key of data and signs the hashes with kp. Without a key pair
+// the record is a plain checksum, which detects accidental edits but not deliberate ones.
+func SignConfigData(data map[string][]byte, kp nkeys.KeyPair) ([]byte, error) {
+	integrity := Integrity{Hashes: make(map[string]string, len(data))}
+	for k, v := range data {
+		sum := sha256.Sum256(v)
+		integrity.Hashes[k] = hex.EncodeToString(sum[:])
+	}
+
+	if kp != nil {
+		signer, err := kp.PublicKey()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get signer public key: %w", err)
+		}
+		sig, err := kp.Sign(signingPayload(integrity.Hashes))
+		if err != nil {
+			return nil, fmt.Errorf("failed to sign server auth config: %w", err)
+		}
+		integrity.Signer = signer
+		integrity.Signature = base64.RawURLEncoding.EncodeToString(sig)
+	}
+
+	encoded, err := json.Marshal(integrity)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode server auth config signature: %w", err)
+	}
+	return encoded, nil
+}
+
+// VerifyConfigData checks data against an encoded integrity record. Keys not covered by the
+// record are ignored, since a shared Secret or ConfigMap may hold keys of other writers. When
+// signer is set the record must carry a valid signature by it. Every failure wraps ErrConfigDrift.
+func VerifyConfigData(data map[string][]byte, encoded []byte, signer string) error {
+	var integrity Integrity
+	if err := json.Unmarshal(encoded, &integrity); err != nil {
+		return fmt.Errorf("%w: unreadable signature: %v", ErrConfigDrift, err)
+	}
+
+	if integrity.Signer != "" || signer != "" {
+		if signer != "" && integrity.Signer != signer {
+			return fmt.Errorf("%w: signed by %q, expected %q", ErrConfigDrift, integrity.Signer, signer)
+		}
+		kp, err := nkeys.FromPublicKey(integrity.Signer)
+		if err != nil {
+			return fmt.Errorf("%w: invalid signer: %v", ErrConfigDrift, err)
+		}
+		sig, err := base64.RawURLEncoding.DecodeString(integrity.Signature)
+		if err != nil {
+			return fmt.Errorf("%w: invalid signature encoding: %v", ErrConfigDrift, err)
+		}
+		if err := kp.Verify(signingPayload(integrity.Hashes), sig); err != nil {
+			return fmt.Errorf("%w: signature does not verify", ErrConfigDrift)
+		}
+	}
+
+	var changed []string
+	for k, want := range integrity.Hashes {
+		v, ok := data[k]
+		if !ok {
+			changed = append(changed, k)
+			continue
+		}
+		sum := sha256.Sum256(v)
+		if hex.EncodeToString(sum[:]) != want {
+			changed = append(changed, k)
+		}
+	}
+	if len(changed) > 0 {
+		sort.Strings(changed)
+		return fmt.Errorf("%w: keys %s changed or removed", ErrConfigDrift, strings.Join(changed, ", "))
+	}
+	return nil
+}
+
+// signingPayload serializes the hashes as sorted key=hash lines
+func signingPayload(hashes map[string]string) []byte {
+	keys := make([]string, 0, len(hashes))
+	for k := range hashes {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var sb strings.Builder
+	for _, k := range keys {
+		sb.WriteString(k)
+		sb.WriteByte('=')
+		sb.WriteString(hashes[k])
+		sb.WriteByte('\n')
+	}
+	return []byte(sb.String())
+}
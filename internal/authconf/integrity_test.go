@@ -0,0 +1,113 @@
+package authconf
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/nats-io/nkeys"
+)
+
+func TestVerifyConfigData(t *testing.T) {
+	operator, _ := nkeys.CreateOperator()
+	operatorPub, _ := operator.PublicKey()
+	other, _ := nkeys.CreateOperator()
+	otherPub, _ := other.PublicKey()
+
+	data := map[string][]byte{
+		"operator":  []byte("operator-jwt"),
+		"auth.conf": []byte("authorization {}"),
+	}
+	signed, err := SignConfigData(data, operator)
+	if err != nil {
+		t.Fatalf("SignConfigData() error = %v", err)
+	}
+	checksum, err := SignConfigData(data, nil)
+	if err != nil {
+		t.Fatalf("SignConfigData() error = %v", err)
+	}
+
+	// A record whose hashes were rewritten without re-signing
+	var forged Integrity
+	_ = json.Unmarshal(signed, &forged)
+	forged.Hashes["auth.conf"] = "00"
+	forgedRecord, _ := json.Marshal(forged)
+
+	tests := []struct {
+		name      string
+		data      map[string][]byte
+		integrity []byte
+		signer    string
+		wantDrift bool
+	}{
+		{
+			name:      "Unchanged signed config",
+			data:      data,
+			integrity: signed,
+			signer:    operatorPub,
+		},
+		{
+			name:      "Unchanged checksum",
+			data:      data,
+			integrity: checksum,
+		},
+		{
+			name:      "Keys of other writers ignored",
+			data:      map[string][]byte{"operator": []byte("operator-jwt"), "auth.conf": []byte("authorization {}"), "extra": []byte("x")},
+			integrity: signed,
+			signer:    operatorPub,
+		},
+		{
+			name:      "Changed value",
+			data:      map[string][]byte{"operator": []byte("operator-jwt"), "auth.conf": []byte("authorization { users = [] }")},
+			integrity: signed,
+			signer:    operatorPub,
+			wantDrift: true,
+		},
+		{
+			name:      "Removed key",
+			data:      map[string][]byte{"auth.conf": []byte("authorization {}")},
+			integrity: checksum,
+			wantDrift: true,
+		},
+		{
+			name:      "Unexpected signer",
+			data:      data,
+			integrity: signed,
+			signer:    otherPub,
+			wantDrift: true,
+		},
+		{
+			name:      "Checksum where a signature is expected",
+			data:      data,
+			integrity: checksum,
+			signer:    operatorPub,
+			wantDrift: true,
+		},
+		{
+			name:      "Forged hashes",
+			data:      data,
+			integrity: forgedRecord,
+			signer:    operatorPub,
+			wantDrift: true,
+		},
+		{
+			name:      "Unreadable record",
+			data:      data,
+			integrity: []byte("not json"),
+			wantDrift: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := VerifyConfigData(tt.data, tt.integrity, tt.signer)
+			if (err != nil) != tt.wantDrift {
+				t.Fatalf("VerifyConfigData() error = %v, wantDrift %v", err, tt.wantDrift)
+			}
+			if err != nil && !errors.Is(err, ErrConfigDrift) {
+				t.Errorf("VerifyConfigData() error = %v, want ErrConfigDrift", err)
+			}
+		})
+	}
+}
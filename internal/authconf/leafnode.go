@@ -0,0 +1,54 @@
+package authconf
+
+import (
+	"fmt"
+	"strings"
+)
+
+// LeafNodeConfKey is the ConfigMap key holding the rendered leafnodes block
+const LeafNodeConfKey = "leafnodes.conf"
+
+// LeafNodeRemote represents an upstream remote for the leafnodes block
+type LeafNodeRemote struct {
+	URLs        []string
+	Account     string
+	Credentials string
+}
+
+// RenderLeafNodeConf generates the leafnodes block an edge server includes to connect upstream
+func RenderLeafNodeConf(remotes []LeafNodeRemote) string {
+	if len(remotes) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+
+	sb.WriteString("leafnodes {\n")
+	sb.WriteString("  remotes = [\n")
+
+	for _, remote := range remotes {
+		sb.WriteString("    {\n")
+		sb.WriteString(fmt.Sprintf("      urls: %s\n", formatList(remote.URLs)))
+		if remote.Account != "" {
+			sb.WriteString(fmt.Sprintf("      account: %s\n", quote(remote.Account)))
+		}
+		if remote.Credentials != "" {
+			sb.WriteString(fmt.Sprintf("      credentials: %s\n", quote(remote.Credentials)))
+		}
+		sb.WriteString("    }\n")
+	}
+
+	sb.WriteString("  ]\n")
+	sb.WriteString("}\n")
+
+	return sb.String()
+}
+
+// formatList formats values as a quoted array, even for a single entry
+func formatList(values []string) string {
+	quoted := make([]string, len(values))
+	for i, v := range values {
+		quoted[i] = quote(v)
+	}
+	return "[" + strings.Join(quoted, ", ") + "]"
+}
@@ -0,0 +1,66 @@
+package authconf
+
+import (
+	"testing"
+)
+
+func TestRenderLeafNodeConf(t *testing.T) {
+	tests := []struct {
+		name    string
+		remotes []LeafNodeRemote
+		want    string
+	}{
+		{
+			name:    "No remotes",
+			remotes: nil,
+			want:    "",
+		},
+		{
+			name: "Single URL with credentials",
+			remotes: []LeafNodeRemote{
+				{
+					URLs:        []string{"nats-leaf://hub:7422"},
+					Credentials: "/etc/nats/leafnode/user.creds",
+				},
+			},
+			want: `leafnodes {
+  remotes = [
+    {
+      urls: ["nats-leaf://hub:7422"]
+      credentials: "/etc/nats/leafnode/user.creds"
+    }
+  ]
+}
+`,
+		},
+		{
+			name: "Multiple URLs bound to a local account",
+			remotes: []LeafNodeRemote{
+				{
+					URLs:        []string{"nats-leaf://hub-0:7422", "nats-leaf://hub-1:7422"},
+					Account:     "EDGE",
+					Credentials: "/creds/user.creds",
+				},
+			},
+			want: `leafnodes {
+  remotes = [
+    {
+      urls: ["nats-leaf://hub-0:7422", "nats-leaf://hub-1:7422"]
+      account: "EDGE"
+      credentials: "/creds/user.creds"
+    }
+  ]
+}
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := RenderLeafNodeConf(tt.remotes)
+			if got != tt.want {
+				t.Errorf("RenderLeafNodeConf() =\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,338 @@
+package authconf
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// maxIncludeDepth bounds nested includes, which also stops include cycles
+const maxIncludeDepth = 10
+
+// SyntaxError is a config the NATS server would refuse to parse
+type SyntaxError struct {
+	// File is the include the error is in; empty for the linted config itself
+	File    string
+	Line    int
+	Column  int
+	Message string
+}
+
+func (e *SyntaxError) Error() string {
+	if e.File != "" {
+		return fmt.Sprintf("%s: line %d, column %d: %s", e.File, e.Line, e.Column, e.Message)
+	}
+	return fmt.Sprintf("line %d, column %d: %s", e.Line, e.Column, e.Message)
+}
+
+// LintConfig checks conf against the NATS server config grammar: keys separated from values by
+// '=', ':' or blanks, quoted and bare strings, maps, arrays, block strings, comments and
+// includes. include returns the content of an included file; includes it does not know, e.g.
+// files from other mounts, are not checked. Only syntax is checked, not option names or values.
+func LintConfig(conf string, include func(name string) (string, bool)) error {
+	return lintFile("", conf, include, 0)
+}
+
+// LintConfigData lints data[key], resolving includes among the other keys of data
+func LintConfigData(data map[string][]byte, key string) error {
+	conf, ok := data[key]
+	if !ok {
+		return fmt.Errorf("key %q not found", key)
+	}
+	return LintConfig(string(conf), func(name string) (string, bool) {
+		v, ok := data[strings.TrimPrefix(name, "./")]
+		return string(v), ok
+	})
+}
+
+func lintFile(file, conf string, include func(string) (string, bool), depth int) error {
+	p := &confParser{file: file, src: []rune(conf), line: 1, col: 1, include: include, depth: depth}
+	return p.parseBody(0)
+}
+
+// confParser is a recursive descent parser of the NATS config format that keeps no values
+type confParser struct {
+	file    string
+	src     []rune
+	pos     int
+	line    int
+	col     int
+	include func(string) (string, bool)
+	depth   int
+}
+
+func (p *confParser) errorf(format string, args ...interface{}) error {
+	return &SyntaxError{File: p.file, Line: p.line, Column: p.col, Message: fmt.Sprintf(format, args...)}
+}
+
+func (p *confParser) eof() bool {
+	return p.pos >= len(p.src)
+}
+
+func (p *confParser) peek() rune {
+	if p.eof() {
+		return 0
+	}
+	return p.src[p.pos]
+}
+
+func (p *confParser) peekAt(offset int) rune {
+	if p.pos+offset >= len(p.src) {
+		return 0
+	}
+	return p.src[p.pos+offset]
+}
+
+func (p *confParser) next() rune {
+	r := p.src[p.pos]
+	p.pos++
+	if r == '\n' {
+		p.line++
+		p.col = 1
+	} else {
+		p.col++
+	}
+	return r
+}
+
+// atComment reports whether a comment, '#' or "//", starts at the position
+func (p *confParser) atComment() bool {
+	return p.peek() == '#' || (p.peek() == '/' && p.peekAt(1) == '/')
+}
+
+// skipBlanks skips spaces, tabs and comments, and newlines too when newlines is set
+func (p *confParser) skipBlanks(newlines bool) {
+	for !p.eof() {
+		switch r := p.peek(); {
+		case r == ' ' || r == '\t' || r == '\r':
+			p.next()
+		case r == '\n' && newlines:
+			p.next()
+		case p.atComment():
+			for !p.eof() && p.peek() != '\n' {
+				p.next()
+			}
+		default:
+			return
+		}
+	}
+}
+
+// parseBody parses key-value pairs up to end: EOF at the top level, '}' in a map
+func (p *confParser) parseBody(end rune) error {
+	for {
+		p.skipBlanks(true)
+		for p.peek() == ',' || p.peek() == ';' {
+			p.next()
+			p.skipBlanks(true)
+		}
+		if p.eof() {
+			if end != 0 {
+				return p.errorf("unterminated map, expected %q", end)
+			}
+			return nil
+		}
+		if p.peek() == end {
+			p.next()
+			return nil
+		}
+
+		key, quoted, err := p.parseKey()
+		if err != nil {
+			return err
+		}
+		p.skipBlanks(false)
+		if key == "include" && !quoted {
+			if err := p.parseInclude(); err != nil {
+				return err
+			}
+		} else {
+			if p.peek() == '=' || p.peek() == ':' {
+				p.next()
+				p.skipBlanks(false)
+			}
+			if err := p.parseValue(key); err != nil {
+				return err
+			}
+		}
+		if err := p.endOfValue(end); err != nil {
+			return err
+		}
+	}
+}
+
+// endOfValue checks that a value is followed by a newline, ',', ';', a comment or end
+func (p *confParser) endOfValue(end rune) error {
+	p.skipBlanks(false)
+	switch r := p.peek(); {
+	case p.eof(), r == '\n', r == ',', r == ';', r == end && end != 0:
+		return nil
+	default:
+		return p.errorf("unexpected %q after value", r)
+	}
+}
+
+func isKeyRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || strings.ContainsRune("_-.$/@", r)
+}
+
+func (p *confParser) parseKey() (string, bool, error) {
+	switch p.peek() {
+	case '"', '\'':
+		key, err := p.parseQuoted()
+		return key, true, err
+	}
+	start := p.pos
+	for !p.eof() && isKeyRune(p.peek()) && !p.atComment() {
+		p.next()
+	}
+	if p.pos == start {
+		return "", false, p.errorf("expected a key, found %q", p.peek())
+	}
+	return string(p.src[start:p.pos]), false, nil
+}
+
+// parseQuoted parses a string in double quotes, with escapes, or single quotes, without
+func (p *confParser) parseQuoted() (string, error) {
+	quote := p.next()
+	var sb strings.Builder
+	for {
+		if p.eof() || p.peek() == '\n' {
+			return "", p.errorf("unterminated string")
+		}
+		r := p.next()
+		switch {
+		case r == quote:
+			return sb.String(), nil
+		case r == '\\' && quote == '"':
+			if p.eof() {
+				return "", p.errorf("unterminated string")
+			}
+			switch e := p.next(); e {
+			case 't', 'n', 'r', '"', '\\':
+				sb.WriteRune(e)
+			case 'x':
+				for i := 0; i < 2; i++ {
+					if p.eof() || !strings.ContainsRune("0123456789abcdefABCDEF", p.peek()) {
+						return "", p.errorf("invalid hex escape")
+					}
+					p.next()
+				}
+			default:
+				return "", p.errorf("invalid escape character %q", e)
+			}
+		default:
+			sb.WriteRune(r)
+		}
+	}
+}
+
+// parseInclude parses the file name of an include and lints the file when it is known
+func (p *confParser) parseInclude() error {
+	var name string
+	switch p.peek() {
+	case '"', '\'':
+		quoted, err := p.parseQuoted()
+		if err != nil {
+			return err
+		}
+		name = quoted
+	default:
+		start := p.pos
+		for !p.eof() && !unicode.IsSpace(p.peek()) && p.peek() != ';' && p.peek() != ',' && p.peek() != '}' {
+			p.next()
+		}
+		name = string(p.src[start:p.pos])
+	}
+	if name == "" {
+		return p.errorf("include without a file name")
+	}
+	if p.include == nil {
+		return nil
+	}
+	content, ok := p.include(name)
+	if !ok {
+		return nil
+	}
+	if p.depth >= maxIncludeDepth {
+		return p.errorf("includes nested deeper than %d, e.g. an include cycle through %q", maxIncludeDepth, name)
+	}
+	return lintFile(name, content, p.include, p.depth+1)
+}
+
+func (p *confParser) parseValue(key string) error {
+	if p.eof() || p.peek() == '\n' || p.peek() == ',' || p.peek() == ';' {
+		return p.errorf("missing value for key %q", key)
+	}
+	switch p.peek() {
+	case '{':
+		p.next()
+		return p.parseBody('}')
+	case '[':
+		p.next()
+		return p.parseArray(key)
+	case '"', '\'':
+		_, err := p.parseQuoted()
+		return err
+	case '(':
+		return p.parseBlock()
+	case '}', ']':
+		return p.errorf("missing value for key %q", key)
+	}
+	// A bare string, number, boolean or variable runs up to the end of the value
+	for !p.eof() {
+		r := p.peek()
+		if r == '\n' || r == ',' || r == ';' || r == '}' || r == ']' {
+			break
+		}
+		if r == '#' && p.pos > 0 && unicode.IsSpace(p.src[p.pos-1]) {
+			break
+		}
+		if r == '"' || r == '\'' || r == '{' || r == '[' {
+			return p.errorf("unexpected %q in unquoted value of key %q", r, key)
+		}
+		p.next()
+	}
+	return nil
+}
+
+func (p *confParser) parseArray(key string) error {
+	for {
+		p.skipBlanks(true)
+		for p.peek() == ',' {
+			p.next()
+			p.skipBlanks(true)
+		}
+		if p.eof() {
+			return p.errorf("unterminated array of key %q", key)
+		}
+		if p.peek() == ']' {
+			p.next()
+			return nil
+		}
+		if err := p.parseValue(key); err != nil {
+			return err
+		}
+		if err := p.endOfValue(']'); err != nil {
+			return err
+		}
+	}
+}
+
+// parseBlock parses a block string, which runs from '(' up to a line holding only ')'
+func (p *confParser) parseBlock() error {
+	p.next()
+	atLineStart := false
+	for !p.eof() {
+		r := p.next()
+		switch {
+		case r == '\n':
+			atLineStart = true
+		case r == ')' && atLineStart:
+			return nil
+		case r != ' ' && r != '\t':
+			atLineStart = false
+		}
+	}
+	return p.errorf("unterminated block string")
+}
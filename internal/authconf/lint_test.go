@@ -0,0 +1,188 @@
+package authconf
+
+import (
+	"errors"
+	"testing"
+
+	natsv1alpha1 "github.com/jradikk/nats-auth-operator/api/v1alpha1"
+)
+
+func TestLintConfigRendered(t *testing.T) {
+	users := []TokenUser{
+		{
+			Username: "orders",
+			Password: "p\"w\\\n",
+			Permissions: &natsv1alpha1.Permissions{
+				PublishAllow:  []string{"orders.>", "$JS.API.>"},
+				SubscribeDeny: []string{"_INBOX.>"},
+			},
+			AllowedConnectionTypes: []string{"STANDARD", "WEBSOCKET"},
+		},
+		{Token: "tok-123"},
+	}
+	accounts := []AccountJWT{
+		{AccountName: "a", AccountNamespace: "ns", AccountID: "ACA", JWT: "eyJ.a.sig"},
+		{AccountName: "b", AccountNamespace: "ns", AccountID: "ACB", JWT: "eyJ.b.sig"},
+	}
+	accountsConf, err := DefaultRenderer().RenderTokenAuthConf(TokenAuthConf{
+		NoAuthUser: "orders",
+		Accounts: []TokenAccount{{
+			Name:    "ORDERS",
+			Users:   users,
+			Exports: []TokenExport{{Type: "service", Subject: "orders.get", Accounts: []string{"SHOP"}}},
+			Imports: []TokenImport{{Type: "stream", Account: "SHOP", Subject: "shop.>", Prefix: "shop"}},
+			Mappings: []TokenMapping{{Subject: "orders.new", Destinations: []TokenMappingDestination{
+				{Subject: "orders.new.a", Weight: 50}, {Subject: "orders.new.b", Weight: 50},
+			}}},
+		}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, conf := range map[string]string{
+		"Token":        RenderTokenAuthConf(users),
+		"Accounts":     accountsConf,
+		"JWT":          RenderJWTAuthConf("eyJ.op.sig", "/data/jwt"),
+		"Preload":      RenderJWTAuthConfWithPreload("eyJ.op.sig", accounts),
+		"Mixed":        RenderMixedAuthConf("eyJ.op.sig", "/data/jwt", users),
+		"Memory":       RenderMemoryResolverConf("eyJ.op.sig", accounts),
+		"Cluster auth": RenderClusterAuthConf(InfraAuth{Username: "route", Password: "s3cret", Timeout: 2}),
+		"Leafnodes":    RenderLeafNodeConf([]LeafNodeRemote{{URLs: []string{"nats-leaf://hub:7422"}, Account: "ACA", Credentials: "/etc/leaf.creds"}}),
+		"Websocket":    RenderWebsocketConf(WebsocketOptions{Port: 8080, NoTLS: true, JWTCookie: "jwt"}),
+		"MQTT":         RenderMQTTConf(MQTTOptions{Port: 1883}),
+		"Bootstrap": RenderBootstrapConf(BootstrapOptions{
+			JetStreamStoreDir: "/data",
+			AuthConf:          RenderJWTAuthConf("eyJ.op.sig", "/data/jwt"),
+			Includes:          []string{"auth.conf"},
+		}),
+	} {
+		t.Run(name, func(t *testing.T) {
+			if err := LintConfig(conf, nil); err != nil {
+				t.Errorf("LintConfig() error = %v\n%s", err, conf)
+			}
+		})
+	}
+}
+
+func TestLintConfigDataChunked(t *testing.T) {
+	accounts := []AccountJWT{
+		{AccountName: "a", AccountNamespace: "ns", AccountID: "ACA", JWT: "eyJ.a.sig"},
+		{AccountName: "b", AccountNamespace: "ns", AccountID: "ACB", JWT: "eyJ.b.sig"},
+	}
+	data, _, err := BuildServerAuthSecretData("eyJ.op.sig", accounts, natsv1alpha1.AccountKeyFormatPublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data[PreloadConfKey] = []byte(RenderPreloadConf(accounts))
+	chunks := ChunkServerAuthSecretData(data, accounts, natsv1alpha1.AccountKeyFormatPublicKey, 60)
+
+	merged := map[string][]byte{"auth.conf": []byte("include \"./preload.conf\"\n")}
+	for _, chunk := range chunks {
+		for k, v := range chunk {
+			merged[k] = v
+		}
+	}
+	if err := LintConfigData(merged, "auth.conf"); err != nil {
+		t.Errorf("LintConfigData() error = %v", err)
+	}
+
+	merged[PreloadChunkKey(1)] = []byte("\"ACB\": \"eyJ.b.sig\n")
+	var syntaxErr *SyntaxError
+	if err := LintConfigData(merged, "auth.conf"); !errors.As(err, &syntaxErr) || syntaxErr.File != PreloadChunkKey(1) {
+		t.Errorf("LintConfigData() error = %v, want a syntax error in %s", err, PreloadChunkKey(1))
+	}
+
+	if err := LintConfigData(merged, "missing.conf"); err == nil {
+		t.Error("LintConfigData() of a missing key succeeded")
+	}
+}
+
+func TestLintConfig(t *testing.T) {
+	tests := []struct {
+		name     string
+		conf     string
+		includes map[string]string
+		wantErr  string
+	}{
+		{
+			name: "Separators and comments",
+			conf: "# comment\nport 4222\nhost = 0.0.0.0; debug: true // trailing\n" +
+				"authorization { users = [ {user: a, password: 'b'}, {user: \"c\"; password: \"d\"} ] }\n",
+		},
+		{
+			name: "Variables and block strings",
+			conf: "PASS: \"s3cret\"\nauthorization {\n  password: $PASS\n}\nmotd: (\n  hello\n  world\n)\n",
+		},
+		{
+			name:     "Includes",
+			conf:     "authorization {\n  include ./users.conf\n}\ninclude \"other-mount.conf\"\n",
+			includes: map[string]string{"./users.conf": "users: [{user: a}]\n"},
+		},
+		{
+			name:    "Unterminated string",
+			conf:    "authorization {\n  user: \"orders\n}\n",
+			wantErr: "line 2, column 16: unterminated string",
+		},
+		{
+			name:    "Missing value",
+			conf:    "port:\nhost: localhost\n",
+			wantErr: "line 1, column 6: missing value for key \"port\"",
+		},
+		{
+			name:    "Unterminated map",
+			conf:    "authorization {\n  user: a\n",
+			wantErr: "line 3, column 1: unterminated map, expected '}'",
+		},
+		{
+			name:    "Unterminated array",
+			conf:    "users: [\n  {user: a}\n",
+			wantErr: "line 3, column 1: unterminated array of key \"users\"",
+		},
+		{
+			name:    "Invalid escape",
+			conf:    "password: \"a\\qb\"\n",
+			wantErr: "line 1, column 15: invalid escape character 'q'",
+		},
+		{
+			name:    "Two values",
+			conf:    "user: \"a\" \"b\"\n",
+			wantErr: "line 1, column 11: unexpected '\"' after value",
+		},
+		{
+			name:    "Stray brace",
+			conf:    "port: 4222\n}\n",
+			wantErr: "line 2, column 1: expected a key, found '}'",
+		},
+		{
+			name:     "Error in include",
+			conf:     "include users.conf\n",
+			includes: map[string]string{"users.conf": "users: [\n"},
+			wantErr:  "users.conf: line 2, column 1: unterminated array of key \"users\"",
+		},
+		{
+			name:     "Include cycle",
+			conf:     "include a.conf\n",
+			includes: map[string]string{"a.conf": "include a.conf\n"},
+			wantErr:  "a.conf: line 1, column 15: includes nested deeper than 10, e.g. an include cycle through \"a.conf\"",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := LintConfig(tt.conf, func(name string) (string, bool) {
+				conf, ok := tt.includes[name]
+				return conf, ok
+			})
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("LintConfig() error = %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("LintConfig() error = %v, want %s", err, tt.wantErr)
+			}
+		})
+	}
+}
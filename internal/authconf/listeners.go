@@ -0,0 +1,68 @@
+package authconf
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Server auth config keys for the rendered listener blocks
+const (
+	WebsocketConfKey = "websocket.conf"
+	MQTTConfKey      = "mqtt.conf"
+)
+
+// Default listener ports
+const (
+	DefaultWebsocketPort = 8080
+	DefaultMQTTPort      = 1883
+)
+
+// WebsocketOptions represents the websocket listener block
+type WebsocketOptions struct {
+	Port      int32
+	NoTLS     bool
+	JWTCookie string
+}
+
+// MQTTOptions represents the mqtt listener block
+type MQTTOptions struct {
+	Port int32
+}
+
+// RenderWebsocketConf generates the websocket block for websocket clients
+func RenderWebsocketConf(opts WebsocketOptions) string {
+	port := opts.Port
+	if port == 0 {
+		port = DefaultWebsocketPort
+	}
+
+	var sb strings.Builder
+
+	sb.WriteString("websocket {\n")
+	sb.WriteString(fmt.Sprintf("  port: %d\n", port))
+	if opts.NoTLS {
+		sb.WriteString("  no_tls: true\n")
+	}
+	if opts.JWTCookie != "" {
+		sb.WriteString(fmt.Sprintf("  jwt_cookie: %s\n", quote(opts.JWTCookie)))
+	}
+	sb.WriteString("}\n")
+
+	return sb.String()
+}
+
+// RenderMQTTConf generates the mqtt block for MQTT clients
+func RenderMQTTConf(opts MQTTOptions) string {
+	port := opts.Port
+	if port == 0 {
+		port = DefaultMQTTPort
+	}
+
+	var sb strings.Builder
+
+	sb.WriteString("mqtt {\n")
+	sb.WriteString(fmt.Sprintf("  port: %d\n", port))
+	sb.WriteString("}\n")
+
+	return sb.String()
+}
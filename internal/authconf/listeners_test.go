@@ -0,0 +1,59 @@
+package authconf
+
+import (
+	"testing"
+)
+
+func TestRenderWebsocketConf(t *testing.T) {
+	tests := []struct {
+		name string
+		opts WebsocketOptions
+		want string
+	}{
+		{
+			name: "Defaults",
+			opts: WebsocketOptions{},
+			want: "websocket {\n  port: 8080\n}\n",
+		},
+		{
+			name: "Plain websocket with JWT cookie",
+			opts: WebsocketOptions{Port: 9222, NoTLS: true, JWTCookie: "nats_jwt"},
+			want: "websocket {\n  port: 9222\n  no_tls: true\n  jwt_cookie: \"nats_jwt\"\n}\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := RenderWebsocketConf(tt.opts); got != tt.want {
+				t.Errorf("RenderWebsocketConf() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderMQTTConf(t *testing.T) {
+	tests := []struct {
+		name string
+		opts MQTTOptions
+		want string
+	}{
+		{
+			name: "Defaults",
+			opts: MQTTOptions{},
+			want: "mqtt {\n  port: 1883\n}\n",
+		},
+		{
+			name: "Custom port",
+			opts: MQTTOptions{Port: 8883},
+			want: "mqtt {\n  port: 8883\n}\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := RenderMQTTConf(tt.opts); got != tt.want {
+				t.Errorf("RenderMQTTConf() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
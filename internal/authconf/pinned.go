@@ -0,0 +1,57 @@
+package authconf
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/nats-io/jwt/v2"
+)
+
+// PinnedAccountsConfKey is the server auth config key the resolver_pinned_accounts setting is rendered under
+const PinnedAccountsConfKey = "pinned-accounts.conf"
+
+// PinnedAccountIDs returns the sorted public keys of the accounts whose JWT carries all of the
+// tags, together with the extra account IDs. Every account is selected when tags is empty.
+func PinnedAccountIDs(accounts []AccountJWT, tags []string, extra []string) ([]string, error) {
+	ids := map[string]bool{}
+	for _, id := range extra {
+		ids[id] = true
+	}
+
+	for _, acc := range accounts {
+		if len(tags) > 0 {
+			claims, err := jwt.DecodeAccountClaims(acc.JWT)
+			if err != nil {
+				return nil, fmt.Errorf("failed to decode JWT of account %s/%s: %w", acc.AccountNamespace, acc.AccountName, err)
+			}
+			if !hasAllTags(claims.Tags, tags) {
+				continue
+			}
+		}
+		ids[acc.AccountID] = true
+	}
+
+	pinned := make([]string, 0, len(ids))
+	for id := range ids {
+		pinned = append(pinned, id)
+	}
+	sort.Strings(pinned)
+	return pinned, nil
+}
+
+// hasAllTags reports whether every wanted tag is present; NATS stores tags lowercased
+func hasAllTags(have jwt.TagList, want []string) bool {
+	for _, tag := range want {
+		if !have.Contains(strings.ToLower(tag)) {
+			return false
+		}
+	}
+	return true
+}
+
+// RenderPinnedAccountsConf generates the resolver_pinned_accounts setting.
+// The server only enforces pinning when the list is not empty.
+func RenderPinnedAccountsConf(accountIDs []string) string {
+	return fmt.Sprintf("resolver_pinned_accounts: %s\n", formatList(accountIDs))
+}
@@ -0,0 +1,92 @@
+package authconf
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/nats-io/jwt/v2"
+	"github.com/nats-io/nkeys"
+)
+
+func TestPinnedAccountIDs(t *testing.T) {
+	operator, _ := nkeys.CreateOperator()
+	account := func(name string, tags ...string) AccountJWT {
+		kp, _ := nkeys.CreateAccount()
+		pub, _ := kp.PublicKey()
+		claims := jwt.NewAccountClaims(pub)
+		claims.Tags.Add(tags...)
+		token, err := claims.Encode(operator)
+		if err != nil {
+			t.Fatalf("Encode() error = %v", err)
+		}
+		return AccountJWT{AccountName: name, AccountNamespace: "default", AccountID: pub, JWT: token}
+	}
+	prod := account("prod", "env:prod", "team:a")
+	dev := account("dev", "env:dev")
+	untagged := account("untagged")
+	accounts := []AccountJWT{prod, dev, untagged}
+	system := "ADYJ2PCVPLDPZ3T4LQMWXOLTJPHJCD6UNQBJEWEFQGWLK6BHI5HYUVNA"
+
+	tests := []struct {
+		name  string
+		tags  []string
+		extra []string
+		want  []string
+	}{
+		{
+			name: "All accounts without tags",
+			want: sortedIDs(prod, dev, untagged),
+		},
+		{
+			name: "Accounts carrying the tag",
+			tags: []string{"env:prod"},
+			want: []string{prod.AccountID},
+		},
+		{
+			name: "Tags are matched case-insensitively",
+			tags: []string{"ENV:prod", "team:a"},
+			want: []string{prod.AccountID},
+		},
+		{
+			name: "Every tag is required",
+			tags: []string{"env:dev", "team:a"},
+			want: []string{},
+		},
+		{
+			name:  "Extra account IDs are pinned",
+			tags:  []string{"env:dev"},
+			extra: []string{system},
+			want:  sortedIDs(dev, AccountJWT{AccountID: system}),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := PinnedAccountIDs(accounts, tt.tags, tt.extra)
+			if err != nil {
+				t.Fatalf("PinnedAccountIDs() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("PinnedAccountIDs() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderPinnedAccountsConf(t *testing.T) {
+	got := RenderPinnedAccountsConf([]string{"AAA", "ABB"})
+	want := "resolver_pinned_accounts: [\"AAA\", \"ABB\"]\n"
+	if got != want {
+		t.Errorf("RenderPinnedAccountsConf() = %q, want %q", got, want)
+	}
+}
+
+func sortedIDs(accounts ...AccountJWT) []string {
+	ids := make([]string, 0, len(accounts))
+	for _, acc := range accounts {
+		ids = append(ids, acc.AccountID)
+	}
+	sort.Strings(ids)
+	return ids
+}
@@ -0,0 +1,41 @@
+package authconf
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// quote returns s as a double-quoted string of the NATS config format. The format only knows
+// the escapes \t, \n, \r, \", \\ and \xHH, so Go's %q, which emits \u, \a and others, is not
+// used: every other control character, and every byte that is not valid UTF-8, becomes \xHH.
+// A user-supplied string therefore cannot end the quoted string or span lines.
+func quote(s string) string {
+	var sb strings.Builder
+	sb.Grow(len(s) + 2)
+	sb.WriteByte('"')
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		switch {
+		case r == utf8.RuneError && size <= 1:
+			fmt.Fprintf(&sb, `\x%02x`, s[i])
+		case r == '"':
+			sb.WriteString(`\"`)
+		case r == '\\':
+			sb.WriteString(`\\`)
+		case r == '\t':
+			sb.WriteString(`\t`)
+		case r == '\n':
+			sb.WriteString(`\n`)
+		case r == '\r':
+			sb.WriteString(`\r`)
+		case r < 0x20 || r == 0x7f:
+			fmt.Fprintf(&sb, `\x%02x`, r)
+		default:
+			sb.WriteString(s[i : i+size])
+		}
+		i += size
+	}
+	sb.WriteByte('"')
+	return sb.String()
+}
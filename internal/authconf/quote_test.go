@@ -0,0 +1,141 @@
+package authconf
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"testing"
+
+	natsv1alpha1 "github.com/jradikk/nats-auth-operator/api/v1alpha1"
+)
+
+// unquote reads a double-quoted string at the start of s the way the NATS config lexer does and
+// returns its value and the remainder of s
+func unquote(s string) (string, string, error) {
+	if !strings.HasPrefix(s, `"`) {
+		return "", "", fmt.Errorf("no opening quote in %q", s)
+	}
+	var sb strings.Builder
+	for i := 1; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '"':
+			return sb.String(), s[i+1:], nil
+		case '\n', '\r':
+			return "", "", fmt.Errorf("line break inside quoted string %q", s)
+		case '\\':
+			if i+1 >= len(s) {
+				return "", "", fmt.Errorf("unterminated escape in %q", s)
+			}
+			i++
+			switch s[i] {
+			case 't':
+				sb.WriteByte('\t')
+			case 'n':
+				sb.WriteByte('\n')
+			case 'r':
+				sb.WriteByte('\r')
+			case '"', '\\':
+				sb.WriteByte(s[i])
+			case 'x':
+				if i+2 >= len(s) {
+					return "", "", fmt.Errorf("short hex escape in %q", s)
+				}
+				b, err := hex.DecodeString(s[i+1 : i+3])
+				if err != nil {
+					return "", "", fmt.Errorf("invalid hex escape in %q", s)
+				}
+				sb.Write(b)
+				i += 2
+			default:
+				return "", "", fmt.Errorf("invalid escape character %q in %q", s[i], s)
+			}
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	return "", "", fmt.Errorf("no closing quote in %q", s)
+}
+
+func TestQuote(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{name: "Plain", in: "orders.>", want: `"orders.>"`},
+		{name: "Quote and backslash", in: `a"b\c`, want: `"a\"b\\c"`},
+		{name: "Line breaks", in: "a\nb\rc\td", want: `"a\nb\rc\td"`},
+		{name: "Control characters", in: "a\x00b\x07c\x7f", want: `"a\x00b\x07c\x7f"`},
+		{name: "Invalid UTF-8", in: "a\xffb", want: `"a\xffb"`},
+		{name: "Unicode", in: "zürich", want: `"zürich"`},
+		{name: "Breakout attempt", in: "x\"\n}\nauthorization { users = [{user: admin}] }\n#", want: `"x\"\n}\nauthorization { users = [{user: admin}] }\n#"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := quote(tt.in); got != tt.want {
+				t.Errorf("quote() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func FuzzQuote(f *testing.F) {
+	for _, seed := range []string{"", "orders.>", `"`, `\`, "\n", "a\x00b", "\xff\xfe", "}\n#", "$VAR", "zürich"} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, s string) {
+		quoted := quote(s)
+		got, rest, err := unquote(quoted)
+		if err != nil {
+			t.Fatalf("quote(%q) = %s does not parse: %v", s, quoted, err)
+		}
+		if rest != "" || got != s {
+			t.Fatalf("quote(%q) = %s parses as %q with %q left over", s, quoted, got, rest)
+		}
+	})
+}
+
+// FuzzRenderTokenAuthConf checks that user-supplied strings keep the structure of the config:
+// every value stays on its own line and parses back to the input
+func FuzzRenderTokenAuthConf(f *testing.F) {
+	f.Add("orders", "s3cret", "orders.>")
+	f.Add("x\"\n}", "p\\w\"\n", "a b\n}")
+	f.Add("\xff", "\x00", "\r")
+	f.Fuzz(func(t *testing.T, username, password, subject string) {
+		if username == "" || password == "" || subject == "" {
+			t.Skip()
+		}
+		conf := RenderTokenAuthConf([]TokenUser{{
+			Username:    username,
+			Password:    password,
+			Permissions: &natsv1alpha1.Permissions{PublishAllow: []string{subject}},
+		}})
+
+		if err := LintConfig(conf, nil); err != nil {
+			t.Fatalf("rendered config does not lint: %v\n%s", err, conf)
+		}
+		lines := strings.Split(strings.TrimSuffix(conf, "\n"), "\n")
+		if len(lines) != 13 {
+			t.Fatalf("rendered %d lines, want 13:\n%s", len(lines), conf)
+		}
+		for _, tc := range []struct {
+			line   int
+			prefix string
+			want   string
+		}{
+			{line: 3, prefix: "      user: ", want: username},
+			{line: 4, prefix: "      password: ", want: password},
+			{line: 7, prefix: "          allow: ", want: subject},
+		} {
+			value, ok := strings.CutPrefix(lines[tc.line], tc.prefix)
+			if !ok {
+				t.Fatalf("line %d = %q, want prefix %q", tc.line, lines[tc.line], tc.prefix)
+			}
+			got, rest, err := unquote(value)
+			if err != nil || rest != "" || got != tc.want {
+				t.Fatalf("line %d parses as %q, %q, %v, want %q", tc.line, got, rest, err, tc.want)
+			}
+		}
+	})
+}
@@ -0,0 +1,11 @@
+package authconf
+
+import "regexp"
+
+// secretFieldPattern matches password and token entries in a rendered auth config
+var secretFieldPattern = regexp.MustCompile(`(?m)^(\s*(?:password|token):\s*)".*"$`)
+
+// Redact replaces passwords and tokens in a rendered auth config so it can be logged
+func Redact(conf string) string {
+	return secretFieldPattern.ReplaceAllString(conf, `${1}"<redacted>"`)
+}
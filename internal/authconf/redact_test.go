@@ -0,0 +1,26 @@
+package authconf
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRedact(t *testing.T) {
+	conf := RenderTokenAuthConf([]TokenUser{
+		{Username: "alice", Password: "s3cret"},
+		{Username: "bob", Token: "tok-123"},
+	})
+
+	got := Redact(conf)
+
+	for _, secret := range []string{"s3cret", "tok-123"} {
+		if strings.Contains(got, secret) {
+			t.Errorf("Redact() output still contains %q:\n%s", secret, got)
+		}
+	}
+	for _, kept := range []string{`user: "alice"`, `user: "bob"`, `password: "<redacted>"`, `token: "<redacted>"`} {
+		if !strings.Contains(got, kept) {
+			t.Errorf("Redact() output missing %q:\n%s", kept, got)
+		}
+	}
+}
@@ -52,6 +52,17 @@ func TestRenderTokenAuthConf(t *testing.T) {
 			},
 			want: []string{"permissions", "publish", "allow", "foo.>", "subscribe", "baz.>"},
 		},
+		{
+			name: "User restricted to websocket connections",
+			users: []TokenUser{
+				{
+					Username:               "browser",
+					Password:               "pass",
+					AllowedConnectionTypes: []string{"WEBSOCKET"},
+				},
+			},
+			want: []string{`allowed_connection_types: ["WEBSOCKET"]`},
+		},
 	}
 
 	for _, tt := range tests {
@@ -74,6 +85,25 @@ func TestRenderTokenAuthConf(t *testing.T) {
 	}
 }
 
+func TestRenderNoAuthUser(t *testing.T) {
+	tests := []struct {
+		name     string
+		username string
+		want     string
+	}{
+		{name: "Unset", username: "", want: ""},
+		{name: "Username", username: "anonymous", want: "no_auth_user: \"anonymous\"\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := RenderNoAuthUser(tt.username); got != tt.want {
+				t.Errorf("RenderNoAuthUser() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestRenderJWTAuthConf(t *testing.T) {
 	operatorJWT := "eyJ0eXAiOiJKV1QiLCJhbGciOiJlZDI1NTE5LW5rZXkifQ..."
 	resolverDir := "/var/lib/nats-resolver"
@@ -0,0 +1,54 @@
+package authconf
+
+import (
+	"fmt"
+	"sort"
+
+	natsv1alpha1 "github.com/jradikk/nats-auth-operator/api/v1alpha1"
+)
+
+// OperatorKey is the server auth Secret key holding the operator JWT
+const OperatorKey = "operator"
+
+// BuildServerAuthSecretData builds the server auth Secret data for the given accounts.
+// It returns the data along with an index mapping "namespace/name" to account public key.
+// Name keys that collide with each other or with the operator key are rejected.
+func BuildServerAuthSecretData(operatorJWT string, accounts []AccountJWT, format natsv1alpha1.AccountKeyFormat) (map[string][]byte, map[string]string, error) {
+	data := map[string][]byte{
+		OperatorKey: []byte(operatorJWT),
+	}
+	index := make(map[string]string, len(accounts))
+
+	byName := map[string]string{}
+	for _, acc := range accounts {
+		ref := acc.AccountNamespace + "/" + acc.AccountName
+		index[ref] = acc.AccountID
+
+		if format != natsv1alpha1.AccountKeyFormatPublicKey {
+			if acc.AccountName == OperatorKey {
+				return nil, nil, fmt.Errorf("account %s cannot be keyed by name: %q is reserved for the operator JWT", ref, OperatorKey)
+			}
+			if other, ok := byName[acc.AccountName]; ok {
+				return nil, nil, fmt.Errorf("accounts %s and %s collide on key %q; set serverAuthConfig.accountKeyFormat to PublicKey", other, ref, acc.AccountName)
+			}
+			byName[acc.AccountName] = ref
+			data[acc.AccountName] = []byte(acc.JWT)
+		}
+
+		if format == natsv1alpha1.AccountKeyFormatPublicKey || format == natsv1alpha1.AccountKeyFormatNameAndPublicKey {
+			data[acc.AccountID] = []byte(acc.JWT)
+		}
+	}
+
+	return data, index, nil
+}
+
+// SortAccounts orders accounts by namespace and name for stable output
+func SortAccounts(accounts []AccountJWT) {
+	sort.Slice(accounts, func(i, j int) bool {
+		if accounts[i].AccountNamespace != accounts[j].AccountNamespace {
+			return accounts[i].AccountNamespace < accounts[j].AccountNamespace
+		}
+		return accounts[i].AccountName < accounts[j].AccountName
+	})
+}
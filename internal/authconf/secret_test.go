@@ -0,0 +1,89 @@
+package authconf
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	natsv1alpha1 "github.com/jradikk/nats-auth-operator/api/v1alpha1"
+)
+
+func TestBuildServerAuthSecretData(t *testing.T) {
+	appA := AccountJWT{AccountName: "app", AccountNamespace: "team-a", AccountID: "ACAAA", JWT: "jwt-a"}
+	appB := AccountJWT{AccountName: "app", AccountNamespace: "team-b", AccountID: "ACBBB", JWT: "jwt-b"}
+	sys := AccountJWT{AccountName: "system", AccountNamespace: "nats", AccountID: "ACSYS", JWT: "jwt-sys"}
+
+	tests := []struct {
+		name      string
+		accounts  []AccountJWT
+		format    natsv1alpha1.AccountKeyFormat
+		wantKeys  []string
+		wantIndex map[string]string
+		wantErr   bool
+	}{
+		{
+			name:      "name keys",
+			accounts:  []AccountJWT{appA, sys},
+			format:    natsv1alpha1.AccountKeyFormatName,
+			wantKeys:  []string{"app", "operator", "system"},
+			wantIndex: map[string]string{"team-a/app": "ACAAA", "nats/system": "ACSYS"},
+		},
+		{
+			name:     "empty format defaults to name keys",
+			accounts: []AccountJWT{sys},
+			wantKeys: []string{"operator", "system"},
+		},
+		{
+			name:     "name collision across namespaces",
+			accounts: []AccountJWT{appA, appB},
+			format:   natsv1alpha1.AccountKeyFormatName,
+			wantErr:  true,
+		},
+		{
+			name:     "account named operator",
+			accounts: []AccountJWT{{AccountName: "operator", AccountNamespace: "x", AccountID: "ACOP"}},
+			format:   natsv1alpha1.AccountKeyFormatName,
+			wantErr:  true,
+		},
+		{
+			name:      "public key format avoids collision",
+			accounts:  []AccountJWT{appA, appB},
+			format:    natsv1alpha1.AccountKeyFormatPublicKey,
+			wantKeys:  []string{"ACAAA", "ACBBB", "operator"},
+			wantIndex: map[string]string{"team-a/app": "ACAAA", "team-b/app": "ACBBB"},
+		},
+		{
+			name:     "name and public key",
+			accounts: []AccountJWT{sys},
+			format:   natsv1alpha1.AccountKeyFormatNameAndPublicKey,
+			wantKeys: []string{"ACSYS", "operator", "system"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, index, err := BuildServerAuthSecretData("op-jwt", tt.accounts, tt.format)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("BuildServerAuthSecretData() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			var keys []string
+			for k := range data {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			if !reflect.DeepEqual(keys, tt.wantKeys) {
+				t.Errorf("keys = %v, want %v", keys, tt.wantKeys)
+			}
+			if string(data[OperatorKey]) != "op-jwt" {
+				t.Errorf("operator key = %q, want %q", data[OperatorKey], "op-jwt")
+			}
+			if tt.wantIndex != nil && !reflect.DeepEqual(index, tt.wantIndex) {
+				t.Errorf("index = %v, want %v", index, tt.wantIndex)
+			}
+		})
+	}
+}
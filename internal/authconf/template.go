@@ -0,0 +1,129 @@
+package authconf
+
+import (
+	"embed"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// AuthConfTemplate is the template rendering the token-mode server auth config. It executes the
+// no_auth_user, authorization and accounts templates, which execute users for each users list.
+const AuthConfTemplate = "auth.conf"
+
+//go:embed templates/*.tmpl
+var templateFS embed.FS
+
+// defaultRenderer renders with the built-in templates
+var defaultRenderer = mustRenderer(nil)
+
+// TokenAuthConf is the data the auth.conf template is executed with
+type TokenAuthConf struct {
+	// NoAuthUser is the username clients without credentials authenticate as, if any
+	NoAuthUser string
+	// Users are the users of the global account
+	Users []TokenUser
+	// Accounts are the static accounts and their users
+	Accounts []TokenAccount
+}
+
+// Renderer renders the token-mode server auth config from text/template templates
+type Renderer struct {
+	tmpl *template.Template
+}
+
+// NewRenderer parses the built-in templates, then the overrides keyed by template name, e.g. the
+// data of a ConfigMap. An override replaces the built-in template of the same name; a key naming
+// no built-in template adds a template the others can include.
+func NewRenderer(overrides map[string]string) (*Renderer, error) {
+	var tmpl *template.Template
+	tmpl = template.New("").Option("missingkey=error").Funcs(template.FuncMap{
+		"quote":    quote,
+		"list":     formatList,
+		"subjects": formatSubjectList,
+		"lower":    strings.ToLower,
+		"indent":   indent,
+		"chomp":    func(s string) string { return strings.TrimSuffix(s, "\n") },
+		"last":     last,
+		"include": func(name string, data interface{}) (string, error) {
+			var sb strings.Builder
+			err := tmpl.ExecuteTemplate(&sb, name, data)
+			return sb.String(), err
+		},
+	})
+	if _, err := tmpl.ParseFS(templateFS, "templates/*.tmpl"); err != nil {
+		return nil, fmt.Errorf("failed to parse built-in templates: %w", err)
+	}
+
+	// Parse in a stable order, so a name defined by two overrides resolves the same way each time
+	names := make([]string, 0, len(overrides))
+	for name := range overrides {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if _, err := tmpl.New(name).Parse(overrides[name]); err != nil {
+			return nil, fmt.Errorf("invalid template %q: %w", name, err)
+		}
+	}
+	return &Renderer{tmpl: tmpl}, nil
+}
+
+// DefaultRenderer returns the renderer of the built-in templates
+func DefaultRenderer() *Renderer {
+	return defaultRenderer
+}
+
+func mustRenderer(overrides map[string]string) *Renderer {
+	r, err := NewRenderer(overrides)
+	if err != nil {
+		panic(err)
+	}
+	return r
+}
+
+// RenderTokenAuthConf executes the auth.conf template
+func (r *Renderer) RenderTokenAuthConf(conf TokenAuthConf) (string, error) {
+	return r.execute(AuthConfTemplate, conf)
+}
+
+// RenderAuthorizationConf executes the authorization template, the authorization block of the
+// users outside any account
+func (r *Renderer) RenderAuthorizationConf(users []TokenUser) (string, error) {
+	return r.execute("authorization", users)
+}
+
+func (r *Renderer) execute(name string, data interface{}) (string, error) {
+	var sb strings.Builder
+	if err := r.tmpl.ExecuteTemplate(&sb, name, data); err != nil {
+		return "", fmt.Errorf("failed to render %s: %w", name, err)
+	}
+	return sb.String(), nil
+}
+
+// mustExecute executes a built-in template, which cannot fail on the types it is written for
+func (r *Renderer) mustExecute(name string, data interface{}) string {
+	out, err := r.execute(name, data)
+	if err != nil {
+		panic(err)
+	}
+	return out
+}
+
+// indent prefixes every non-empty line of s
+func indent(prefix, s string) string {
+	lines := strings.SplitAfter(s, "\n")
+	for i, line := range lines {
+		if line != "" && line != "\n" {
+			lines[i] = prefix + line
+		}
+	}
+	return strings.Join(lines, "")
+}
+
+// last reports whether i is the last index of list
+func last(i int, list interface{}) bool {
+	return i == reflect.ValueOf(list).Len()-1
+}
@@ -0,0 +1,95 @@
+package authconf
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRendererRenderTokenAuthConf(t *testing.T) {
+	conf := TokenAuthConf{
+		NoAuthUser: "guest",
+		Users:      []TokenUser{{Username: "guest", Password: "guest"}},
+		Accounts:   []TokenAccount{{Name: "orders", Users: []TokenUser{{Username: "orders", Password: "s3cret"}}}},
+	}
+
+	got, err := DefaultRenderer().RenderTokenAuthConf(conf)
+	if err != nil {
+		t.Fatalf("RenderTokenAuthConf() error = %v", err)
+	}
+	want := RenderNoAuthUser(conf.NoAuthUser) + RenderTokenAuthConf(conf.Users) + RenderAccountsConf(conf.Accounts)
+	if got != want {
+		t.Errorf("RenderTokenAuthConf() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestNewRenderer(t *testing.T) {
+	conf := TokenAuthConf{
+		Users:    []TokenUser{{Username: "admin", Password: "pw"}},
+		Accounts: []TokenAccount{{Name: "orders", Users: []TokenUser{{Username: "orders", Password: "s3cret"}}}},
+	}
+
+	tests := []struct {
+		name      string
+		overrides map[string]string
+		want      []string
+		wantNot   []string
+		wantErr   string
+	}{
+		{
+			name:      "Replaced users template applies to every users list",
+			overrides: map[string]string{"users": "users = [{{ range . }}{{ template \"user\" . }}{{ end }}]\n", "user": "{user: {{ quote .Username }}}"},
+			want:      []string{`  users = [{user: "admin"}]`, `    users = [{user: "orders"}]`},
+			wantNot:   []string{"password"},
+		},
+		{
+			name:      "Replaced top-level template",
+			overrides: map[string]string{"auth.conf": "# managed by nats-auth-operator\n{{ template \"authorization\" .Users }}"},
+			want:      []string{"# managed by nats-auth-operator\nauthorization {"},
+			wantNot:   []string{"accounts {"},
+		},
+		{
+			name:      "Invalid template",
+			overrides: map[string]string{"users": "{{ range . }"},
+			wantErr:   `invalid template "users"`,
+		},
+		{
+			name:      "Unknown field",
+			overrides: map[string]string{"users": "{{ range . }}{{ .Email }}{{ end }}"},
+			wantErr:   "can't evaluate field Email",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := NewRenderer(tt.overrides)
+			var got string
+			if err == nil {
+				got, err = r.RenderTokenAuthConf(conf)
+			}
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("error = %v", err)
+			}
+			for _, s := range tt.want {
+				if !strings.Contains(got, s) {
+					t.Errorf("output missing %q:\n%s", s, got)
+				}
+			}
+			for _, s := range tt.wantNot {
+				if strings.Contains(got, s) {
+					t.Errorf("output contains %q:\n%s", s, got)
+				}
+			}
+		})
+	}
+
+	// Overrides must not leak into the built-in templates
+	if got := RenderTokenAuthConf(conf.Users); !strings.Contains(got, `password: "pw"`) {
+		t.Errorf("built-in templates changed by an override:\n%s", got)
+	}
+}
@@ -0,0 +1,388 @@
+package backup
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/nats-io/nkeys"
+	corev1 "k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	natsv1alpha1 "github.com/jradikk/nats-auth-operator/api/v1alpha1"
+	jwtpkg "github.com/jradikk/nats-auth-operator/internal/jwt"
+)
+
+// Version is the version of the archive format
+const Version = 1
+
+// ArchiveKey is the key of the sealed archive in a NatsAuthBackup Secret
+const ArchiveKey = "backup.sealed"
+
+// Archive is a snapshot of an auth hierarchy: a NatsAuthConfig, its NatsAccounts and NatsUsers
+// with their status, and the Secrets holding their seeds, JWTs and passwords. Restoring it with
+// the Secrets in place before the resources lets the operator resume from the stored seeds, so
+// every public key is preserved.
+type Archive struct {
+	Version    int                         `json:"version"`
+	CreatedAt  metav1.Time                 `json:"createdAt"`
+	AuthConfig natsv1alpha1.NatsAuthConfig `json:"authConfig"`
+	Accounts   []natsv1alpha1.NatsAccount  `json:"accounts,omitempty"`
+	Users      []natsv1alpha1.NatsUser     `json:"users,omitempty"`
+	Secrets    []corev1.Secret             `json:"secrets,omitempty"`
+}
+
+// Marshal encodes an archive as JSON
+func Marshal(a *Archive) ([]byte, error) {
+	return json.Marshal(a)
+}
+
+// Unmarshal decodes an archive written by Marshal
+func Unmarshal(data []byte) (*Archive, error) {
+	a := &Archive{}
+	if err := json.Unmarshal(data, a); err != nil {
+		return nil, fmt.Errorf("failed to decode archive: %w", err)
+	}
+	if a.Version != Version {
+		return nil, fmt.Errorf("unsupported archive version %d", a.Version)
+	}
+	return a, nil
+}
+
+// ContentHash returns a hash of what a restore depends on: the specs, public keys, revocations and
+// Secret data. Reconcile timestamps and conditions are left out, so an unchanged hierarchy hashes
+// the same and is not backed up again.
+func ContentHash(a *Archive) (string, error) {
+	type resource struct {
+		Key          string           `json:"key"`
+		Spec         interface{}      `json:"spec"`
+		PublicKey    string           `json:"publicKey,omitempty"`
+		RevokedUsers map[string]int64 `json:"revokedUsers,omitempty"`
+	}
+	content := struct {
+		Resources []resource      `json:"resources"`
+		Secrets   []corev1.Secret `json:"secrets"`
+	}{Secrets: a.Secrets}
+
+	content.Resources = append(content.Resources, resource{
+		Key:       client.ObjectKeyFromObject(&a.AuthConfig).String(),
+		Spec:      a.AuthConfig.Spec,
+		PublicKey: a.AuthConfig.Status.OperatorPubKey,
+	})
+	for i := range a.Accounts {
+		account := &a.Accounts[i]
+		content.Resources = append(content.Resources, resource{
+			Key:          client.ObjectKeyFromObject(account).String(),
+			Spec:         account.Spec,
+			PublicKey:    account.Status.AccountID,
+			RevokedUsers: account.Status.RevokedUsers,
+		})
+	}
+	for i := range a.Users {
+		user := &a.Users[i]
+		content.Resources = append(content.Resources, resource{
+			Key:       client.ObjectKeyFromObject(user).String(),
+			Spec:      user.Spec,
+			PublicKey: user.Status.PublicKey,
+		})
+	}
+
+	data, err := json.Marshal(content)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// Collect snapshots the NatsAuthConfig with the given key, the NatsAccounts and NatsUsers that
+// reference it and the Secrets they read or write
+func Collect(ctx context.Context, c client.Client, key client.ObjectKey) (*Archive, error) {
+	authConfig := &natsv1alpha1.NatsAuthConfig{}
+	if err := c.Get(ctx, key, authConfig); err != nil {
+		return nil, fmt.Errorf("failed to get NatsAuthConfig %s: %w", key, err)
+	}
+
+	a := &Archive{Version: Version, CreatedAt: metav1.Now(), AuthConfig: *authConfig}
+	cleanMeta(&a.AuthConfig.ObjectMeta)
+	secretKeys := authConfigSecrets(authConfig)
+
+	accounts := &natsv1alpha1.NatsAccountList{}
+	if err := c.List(ctx, accounts); err != nil {
+		return nil, fmt.Errorf("failed to list NatsAccounts: %w", err)
+	}
+	for _, account := range accounts.Items {
+		if refKey(account.Spec.AuthConfigRef.Namespace, account.Spec.AuthConfigRef.Name, account.Namespace) != key {
+			continue
+		}
+		cleanMeta(&account.ObjectMeta)
+		a.Accounts = append(a.Accounts, account)
+		secretKeys = append(secretKeys, accountSecrets(&account)...)
+	}
+
+	users := &natsv1alpha1.NatsUserList{}
+	if err := c.List(ctx, users); err != nil {
+		return nil, fmt.Errorf("failed to list NatsUsers: %w", err)
+	}
+	for _, user := range users.Items {
+		if refKey(user.Spec.AuthConfigRef.Namespace, user.Spec.AuthConfigRef.Name, user.Namespace) != key {
+			continue
+		}
+		cleanMeta(&user.ObjectMeta)
+		a.Users = append(a.Users, user)
+		secretKeys = append(secretKeys, userSecrets(&user)...)
+	}
+
+	seen := map[client.ObjectKey]bool{}
+	for _, secretKey := range secretKeys {
+		if secretKey.Name == "" || seen[secretKey] {
+			continue
+		}
+		seen[secretKey] = true
+		secret := &corev1.Secret{}
+		if err := c.Get(ctx, secretKey, secret); err != nil {
+			if errors.IsNotFound(err) {
+				continue
+			}
+			return nil, fmt.Errorf("failed to get Secret %s: %w", secretKey, err)
+		}
+		cleanMeta(&secret.ObjectMeta)
+		a.Secrets = append(a.Secrets, *secret)
+	}
+	sort.Slice(a.Secrets, func(i, j int) bool {
+		return client.ObjectKeyFromObject(&a.Secrets[i]).String() < client.ObjectKeyFromObject(&a.Secrets[j]).String()
+	})
+
+	return a, Verify(a)
+}
+
+// Verify checks that the archive holds the seed behind every public key recorded in status, so a
+// restore keeps existing clients working
+func Verify(a *Archive) error {
+	secrets := make(map[client.ObjectKey]*corev1.Secret, len(a.Secrets))
+	for i := range a.Secrets {
+		secrets[client.ObjectKeyFromObject(&a.Secrets[i])] = &a.Secrets[i]
+	}
+
+	var problems []string
+	check := func(kind string, obj client.Object, pubKey string, ref seedRef) {
+		if pubKey == "" {
+			return
+		}
+		name := kind + " " + client.ObjectKeyFromObject(obj).String()
+		secret, ok := secrets[ref.key]
+		if !ok {
+			problems = append(problems, fmt.Sprintf("%s: seed Secret %s is missing", name, ref.key))
+			return
+		}
+		seed, _, err := jwtpkg.FindSeed(secret.Data, ref.prefix, ref.seedKey)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %v", name, err))
+			return
+		}
+		kp, err := nkeys.FromSeed(seed)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %v", name, err))
+			return
+		}
+		if got, _ := kp.PublicKey(); got != pubKey {
+			problems = append(problems, fmt.Sprintf("%s: seed in %s is for %s, status has %s", name, ref.key, got, pubKey))
+		}
+	}
+
+	authConfig := &a.AuthConfig
+	check("NatsAuthConfig", authConfig, authConfig.Status.OperatorPubKey, operatorSeedRef(authConfig))
+	for i := range a.Accounts {
+		account := &a.Accounts[i]
+		check("NatsAccount", account, account.Status.AccountID, accountSeedRef(account))
+	}
+	for i := range a.Users {
+		user := &a.Users[i]
+		if user.Spec.ExistingJWTSecret != nil {
+			continue
+		}
+		check("NatsUser", user, user.Status.PublicKey, userSeedRef(user))
+	}
+
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return fmt.Errorf("archive does not preserve all public keys: %v", problems)
+	}
+	return nil
+}
+
+// Restore creates the Secrets and resources of an archive. Secrets are created first so the
+// operator resumes from the stored seeds; the status of accounts and users is restored to keep
+// revocations. Existing resources are left alone, and an existing Secret with different data is
+// an error rather than being overwritten. It returns the changes made, or that would be made
+// with dryRun.
+func Restore(ctx context.Context, c client.Client, a *Archive, dryRun bool) ([]string, error) {
+	if err := Verify(a); err != nil {
+		return nil, err
+	}
+
+	var changes []string
+	for i := range a.Secrets {
+		secret := a.Secrets[i].DeepCopy()
+		key := client.ObjectKeyFromObject(secret)
+		existing := &corev1.Secret{}
+		err := c.Get(ctx, key, existing)
+		switch {
+		case err == nil && apiequality.Semantic.DeepEqual(existing.Data, secret.Data):
+			changes = append(changes, fmt.Sprintf("Secret %s is up to date", key))
+			continue
+		case err == nil:
+			return changes, fmt.Errorf("secret %s exists with different data; delete it or restore into a fresh cluster", key)
+		case !errors.IsNotFound(err):
+			return changes, fmt.Errorf("failed to get Secret %s: %w", key, err)
+		}
+		if !dryRun {
+			if err := c.Create(ctx, secret); err != nil {
+				return changes, fmt.Errorf("failed to create Secret %s: %w", key, err)
+			}
+		}
+		changes = append(changes, fmt.Sprintf("created Secret %s", key))
+	}
+
+	change, err := restoreObject(ctx, c, "NatsAuthConfig", a.AuthConfig.DeepCopy(), &natsv1alpha1.NatsAuthConfig{}, nil, dryRun)
+	if err != nil {
+		return changes, err
+	}
+	changes = append(changes, change)
+	for i := range a.Accounts {
+		account := a.Accounts[i].DeepCopy()
+		status := account.Status
+		change, err := restoreObject(ctx, c, "NatsAccount", account, &natsv1alpha1.NatsAccount{}, func() { account.Status = status }, dryRun)
+		if err != nil {
+			return changes, err
+		}
+		changes = append(changes, change)
+	}
+	for i := range a.Users {
+		user := a.Users[i].DeepCopy()
+		status := user.Status
+		change, err := restoreObject(ctx, c, "NatsUser", user, &natsv1alpha1.NatsUser{}, func() { user.Status = status }, dryRun)
+		if err != nil {
+			return changes, err
+		}
+		changes = append(changes, change)
+	}
+	return changes, nil
+}
+
+// restoreObject creates obj unless it exists, then puts back its archived status with setStatus
+func restoreObject(ctx context.Context, c client.Client, kind string, obj, existing client.Object, setStatus func(), dryRun bool) (string, error) {
+	key := client.ObjectKeyFromObject(obj)
+	if err := c.Get(ctx, key, existing); err == nil {
+		return fmt.Sprintf("%s %s exists, left unchanged", kind, key), nil
+	} else if !errors.IsNotFound(err) {
+		return "", fmt.Errorf("failed to get %s %s: %w", kind, key, err)
+	}
+	if dryRun {
+		return fmt.Sprintf("created %s %s", kind, key), nil
+	}
+
+	if err := c.Create(ctx, obj); err != nil {
+		return "", fmt.Errorf("failed to create %s %s: %w", kind, key, err)
+	}
+	if setStatus != nil {
+		setStatus()
+		if err := c.Status().Update(ctx, obj); err != nil {
+			return "", fmt.Errorf("failed to restore status of %s %s: %w", kind, key, err)
+		}
+	}
+	return fmt.Sprintf("created %s %s", kind, key), nil
+}
+
+// cleanMeta drops the server-populated metadata, owner references and finalizers of an archived
+// object, so it can be created in another cluster
+func cleanMeta(meta *metav1.ObjectMeta) {
+	*meta = metav1.ObjectMeta{
+		Name:        meta.Name,
+		Namespace:   meta.Namespace,
+		Labels:      meta.Labels,
+		Annotations: meta.Annotations,
+	}
+	delete(meta.Annotations, corev1.LastAppliedConfigAnnotation)
+	if len(meta.Annotations) == 0 {
+		meta.Annotations = nil
+	}
+}
+
+// seedRef locates the seed behind a public key
+type seedRef struct {
+	key     client.ObjectKey
+	prefix  nkeys.PrefixByte
+	seedKey string
+}
+
+func refKey(namespace, name, defaultNamespace string) client.ObjectKey {
+	if namespace == "" {
+		namespace = defaultNamespace
+	}
+	return client.ObjectKey{Namespace: namespace, Name: name}
+}
+
+func operatorSeedRef(authConfig *natsv1alpha1.NatsAuthConfig) seedRef {
+	if authConfig.Spec.JWT != nil && authConfig.Spec.JWT.OperatorSeedSecret != nil {
+		ref := authConfig.Spec.JWT.OperatorSeedSecret
+		return seedRef{key: refKey(ref.Namespace, ref.Name, authConfig.Namespace), prefix: nkeys.PrefixByteOperator, seedKey: ref.Key}
+	}
+	return seedRef{key: client.ObjectKey{Namespace: authConfig.Namespace, Name: authConfig.Name + "-operator-seed"}, prefix: nkeys.PrefixByteOperator}
+}
+
+func accountSeedRef(account *natsv1alpha1.NatsAccount) seedRef {
+	if ref := account.Spec.ExistingSeedSecret; ref != nil {
+		return seedRef{key: ref.ObjectKey(account.Namespace), prefix: nkeys.PrefixByteAccount, seedKey: ref.Key}
+	}
+	return seedRef{key: account.Status.JWTSecretRef.ObjectKey(account.Namespace), prefix: nkeys.PrefixByteAccount}
+}
+
+func userSeedRef(user *natsv1alpha1.NatsUser) seedRef {
+	if ref := user.Spec.ExistingSeedSecret; ref != nil {
+		return seedRef{key: ref.ObjectKey(user.Namespace), prefix: nkeys.PrefixByteUser, seedKey: ref.Key}
+	}
+	return seedRef{key: user.Status.SecretRef.ObjectKey(user.Namespace), prefix: nkeys.PrefixByteUser}
+}
+
+// authConfigSecrets returns the operator seed and signing key Secrets of a NatsAuthConfig
+func authConfigSecrets(authConfig *natsv1alpha1.NatsAuthConfig) []client.ObjectKey {
+	if authConfig.Spec.JWT == nil {
+		return nil
+	}
+	keys := []client.ObjectKey{operatorSeedRef(authConfig).key}
+	if authConfig.Spec.JWT.StrictSigningKeyUsage {
+		keys = append(keys, client.ObjectKey{Namespace: authConfig.Namespace, Name: authConfig.Name + "-operator-signing-key"})
+	}
+	return keys
+}
+
+// accountSecrets returns the seed and JWT Secrets of a NatsAccount
+func accountSecrets(account *natsv1alpha1.NatsAccount) []client.ObjectKey {
+	keys := []client.ObjectKey{accountSeedRef(account).key}
+	if account.Spec.ExistingSeedSecret != nil {
+		keys = append(keys, account.Status.JWTSecretRef.ObjectKey(account.Namespace))
+	}
+	return keys
+}
+
+// userSecrets returns the seed, JWT, password and credentials Secrets of a NatsUser
+func userSecrets(user *natsv1alpha1.NatsUser) []client.ObjectKey {
+	keys := []client.ObjectKey{user.Status.SecretRef.ObjectKey(user.Namespace)}
+	if ref := user.Spec.ExistingSeedSecret; ref != nil {
+		keys = append(keys, ref.ObjectKey(user.Namespace))
+	}
+	if ref := user.Spec.ExistingJWTSecret; ref != nil {
+		keys = append(keys, ref.ObjectKey(user.Namespace))
+	}
+	if user.Spec.PasswordFrom != nil && user.Spec.PasswordFrom.SecretRef != nil {
+		keys = append(keys, user.Spec.PasswordFrom.SecretRef.ObjectKey(user.Namespace))
+	}
+	return keys
+}
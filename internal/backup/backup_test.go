@@ -0,0 +1,208 @@
+package backup
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/nats-io/nkeys"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	natsv1alpha1 "github.com/jradikk/nats-auth-operator/api/v1alpha1"
+	jwtpkg "github.com/jradikk/nats-auth-operator/internal/jwt"
+)
+
+func unmarshalEnvelope(t *testing.T, data []byte) envelope {
+	t.Helper()
+	var env envelope
+	if err := json.Unmarshal(data, &env); err != nil {
+		t.Fatalf("Failed to decode envelope: %v", err)
+	}
+	return env
+}
+
+func marshalEnvelope(t *testing.T, env envelope) []byte {
+	t.Helper()
+	data, err := json.Marshal(env)
+	if err != nil {
+		t.Fatalf("Failed to encode envelope: %v", err)
+	}
+	return data
+}
+
+func newKey(t *testing.T, create func() (nkeys.KeyPair, error)) ([]byte, string) {
+	t.Helper()
+	kp, err := create()
+	if err != nil {
+		t.Fatalf("Failed to create key pair: %v", err)
+	}
+	seed, _ := kp.Seed()
+	pub, _ := kp.PublicKey()
+	return seed, pub
+}
+
+// testArchive returns an archive of a NatsAuthConfig with one account and one user
+func testArchive(t *testing.T) *Archive {
+	operatorSeed, operatorPub := newKey(t, nkeys.CreateOperator)
+	accountSeed, accountPub := newKey(t, nkeys.CreateAccount)
+	userSeed, userPub := newKey(t, nkeys.CreateUser)
+
+	authConfig := natsv1alpha1.NatsAuthConfig{ObjectMeta: metav1.ObjectMeta{Namespace: "nats", Name: "auth"}}
+	authConfig.Spec.JWT = &natsv1alpha1.JWTConfig{}
+	authConfig.Status.OperatorPubKey = operatorPub
+
+	account := natsv1alpha1.NatsAccount{ObjectMeta: metav1.ObjectMeta{Namespace: "team", Name: "app"}}
+	account.Status.AccountID = accountPub
+	account.Status.JWTSecretRef = natsv1alpha1.SecretRef{Namespace: "team", Name: "app-account-jwt"}
+	account.Status.RevokedUsers = map[string]int64{"UOLD": 1}
+
+	user := natsv1alpha1.NatsUser{ObjectMeta: metav1.ObjectMeta{Namespace: "team", Name: "worker"}}
+	user.Status.PublicKey = userPub
+	user.Status.SecretRef = natsv1alpha1.SecretRef{Namespace: "team", Name: "worker-user-creds"}
+
+	secret := func(namespace, name, key string, seed []byte) corev1.Secret {
+		return corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+			Data:       map[string][]byte{key: seed},
+		}
+	}
+	return &Archive{
+		Version:    Version,
+		AuthConfig: authConfig,
+		Accounts:   []natsv1alpha1.NatsAccount{account},
+		Users:      []natsv1alpha1.NatsUser{user},
+		Secrets: []corev1.Secret{
+			secret("nats", "auth-operator-seed", jwtpkg.OperatorSeedKey, operatorSeed),
+			secret("team", "app-account-jwt", jwtpkg.AccountSeedKey, accountSeed),
+			secret("team", "worker-user-creds", jwtpkg.UserSeedKey, userSeed),
+		},
+	}
+}
+
+func TestVerify(t *testing.T) {
+	tests := []struct {
+		name    string
+		mutate  func(a *Archive)
+		wantErr string
+	}{
+		{
+			name:   "Complete archive",
+			mutate: func(a *Archive) {},
+		},
+		{
+			name:    "Missing account seed Secret",
+			mutate:  func(a *Archive) { a.Secrets = a.Secrets[:1] },
+			wantErr: "seed Secret team/app-account-jwt is missing",
+		},
+		{
+			name: "Seed does not match the recorded public key",
+			mutate: func(a *Archive) {
+				_, other := newKey(t, nkeys.CreateUser)
+				a.Users[0].Status.PublicKey = other
+			},
+			wantErr: "NatsUser team/worker: seed in team/worker-user-creds is for",
+		},
+		{
+			name: "User with an externally issued JWT is skipped",
+			mutate: func(a *Archive) {
+				a.Users[0].Spec.ExistingJWTSecret = &natsv1alpha1.SecretRef{Name: "external"}
+				a.Secrets = a.Secrets[:2]
+			},
+		},
+		{
+			name: "Resources without public keys are skipped",
+			mutate: func(a *Archive) {
+				a.AuthConfig.Status.OperatorPubKey = ""
+				a.Secrets = a.Secrets[1:]
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := testArchive(t)
+			tt.mutate(a)
+			err := Verify(a)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Verify() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Verify() error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestContentHash(t *testing.T) {
+	a := testArchive(t)
+	base, err := ContentHash(a)
+	if err != nil {
+		t.Fatalf("ContentHash() error = %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		mutate   func(a *Archive)
+		wantSame bool
+	}{
+		{
+			name:     "Creation time and reconcile timestamps",
+			mutate:   func(a *Archive) { now := metav1.Now(); a.CreatedAt = now; a.Accounts[0].Status.LastReconciled = &now },
+			wantSame: true,
+		},
+		{
+			name:   "New revocation",
+			mutate: func(a *Archive) { a.Accounts[0].Status.RevokedUsers["UNEW"] = 2 },
+		},
+		{
+			name:   "Changed Secret data",
+			mutate: func(a *Archive) { a.Secrets[2].Data["user.creds"] = []byte("creds") },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := Marshal(a)
+			if err != nil {
+				t.Fatalf("Marshal() error = %v", err)
+			}
+			changed, err := Unmarshal(data)
+			if err != nil {
+				t.Fatalf("Unmarshal() error = %v", err)
+			}
+			tt.mutate(changed)
+
+			got, err := ContentHash(changed)
+			if err != nil {
+				t.Fatalf("ContentHash() error = %v", err)
+			}
+			if (got == base) != tt.wantSame {
+				t.Errorf("ContentHash() same = %v, want %v", got == base, tt.wantSame)
+			}
+		})
+	}
+}
+
+func TestCleanMeta(t *testing.T) {
+	meta := metav1.ObjectMeta{
+		Namespace:       "team",
+		Name:            "app",
+		UID:             "1234",
+		ResourceVersion: "42",
+		Finalizers:      []string{"nats.jradikk/seed-protection"},
+		OwnerReferences: []metav1.OwnerReference{{Name: "app"}},
+		Labels:          map[string]string{"app": "demo"},
+		Annotations:     map[string]string{corev1.LastAppliedConfigAnnotation: "{}"},
+	}
+	cleanMeta(&meta)
+
+	want := metav1.ObjectMeta{Namespace: "team", Name: "app", Labels: map[string]string{"app": "demo"}}
+	if !reflect.DeepEqual(meta, want) {
+		t.Errorf("cleanMeta() = %+v, want %+v", meta, want)
+	}
+}
@@ -0,0 +1,159 @@
+package backup
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/nats-io/nkeys"
+)
+
+// sealedVersion is the version of the sealed archive envelope
+const sealedVersion = 1
+
+// ErrNotRecipient is returned when an archive is opened with a key it was not sealed to
+var ErrNotRecipient = errors.New("archive is not sealed to this key")
+
+// envelope is the sealed form of an archive. The archive is encrypted with AES-256-GCM under a
+// random data key; the data key is sealed to every recipient xkey with an ephemeral sender xkey.
+type envelope struct {
+	Version    int             `json:"version"`
+	Sender     string          `json:"sender"`
+	Recipients []sealedDataKey `json:"recipients"`
+	Nonce      []byte          `json:"nonce"`
+	Ciphertext []byte          `json:"ciphertext"`
+}
+
+// sealedDataKey is the data key sealed to one recipient
+type sealedDataKey struct {
+	Recipient string `json:"recipient"`
+	Key       []byte `json:"key"`
+}
+
+// NewRecipientKey creates a curve key pair to seal archives to and returns its seed and public key
+func NewRecipientKey() ([]byte, string, error) {
+	kp, err := nkeys.CreateCurveKeys()
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to create curve key: %w", err)
+	}
+	seed, err := kp.Seed()
+	if err != nil {
+		return nil, "", err
+	}
+	pub, err := kp.PublicKey()
+	if err != nil {
+		return nil, "", err
+	}
+	return seed, pub, nil
+}
+
+// ValidateRecipient checks that a recipient is a curve (xkey) public key
+func ValidateRecipient(recipient string) error {
+	if !nkeys.IsValidPublicCurveKey(recipient) {
+		return fmt.Errorf("recipient %q is not a curve public key (X...)", recipient)
+	}
+	return nil
+}
+
+// Seal encrypts data so that it can be opened with the seed of any of the recipient xkeys
+func Seal(data []byte, recipients []string) ([]byte, error) {
+	if len(recipients) == 0 {
+		return nil, fmt.Errorf("at least one recipient is required")
+	}
+	for _, recipient := range recipients {
+		if err := ValidateRecipient(recipient); err != nil {
+			return nil, err
+		}
+	}
+
+	dataKey := make([]byte, 32)
+	if _, err := rand.Read(dataKey); err != nil {
+		return nil, fmt.Errorf("failed to generate data key: %w", err)
+	}
+	gcm, err := newGCM(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	sender, err := nkeys.CreateCurveKeys()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create sender key: %w", err)
+	}
+	defer sender.Wipe()
+	senderPub, err := sender.PublicKey()
+	if err != nil {
+		return nil, err
+	}
+
+	env := envelope{
+		Version:    sealedVersion,
+		Sender:     senderPub,
+		Nonce:      nonce,
+		Ciphertext: gcm.Seal(nil, nonce, data, []byte(senderPub)),
+	}
+	for _, recipient := range recipients {
+		key, err := sender.Seal(dataKey, recipient)
+		if err != nil {
+			return nil, fmt.Errorf("failed to seal data key to %s: %w", recipient, err)
+		}
+		env.Recipients = append(env.Recipients, sealedDataKey{Recipient: recipient, Key: key})
+	}
+	return json.Marshal(env)
+}
+
+// Open decrypts data sealed by Seal with the seed of one of its recipients
+func Open(sealed []byte, identity []byte) ([]byte, error) {
+	var env envelope
+	if err := json.Unmarshal(sealed, &env); err != nil {
+		return nil, fmt.Errorf("failed to decode sealed archive: %w", err)
+	}
+	if env.Version != sealedVersion {
+		return nil, fmt.Errorf("unsupported sealed archive version %d", env.Version)
+	}
+
+	kp, err := nkeys.FromCurveSeed([]byte(strings.TrimSpace(string(identity))))
+	if err != nil {
+		return nil, fmt.Errorf("invalid curve seed: %w", err)
+	}
+	defer kp.Wipe()
+	pub, err := kp.PublicKey()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, r := range env.Recipients {
+		if r.Recipient != pub {
+			continue
+		}
+		dataKey, err := kp.Open(r.Key, env.Sender)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open data key: %w", err)
+		}
+		gcm, err := newGCM(dataKey)
+		if err != nil {
+			return nil, err
+		}
+		data, err := gcm.Open(nil, env.Nonce, env.Ciphertext, []byte(env.Sender))
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt archive: %w", err)
+		}
+		return data, nil
+	}
+	return nil, fmt.Errorf("%w %s", ErrNotRecipient, pub)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,94 @@
+package backup
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestSealOpen(t *testing.T) {
+	seedA, pubA, err := NewRecipientKey()
+	if err != nil {
+		t.Fatalf("NewRecipientKey() error = %v", err)
+	}
+	seedB, pubB, err := NewRecipientKey()
+	if err != nil {
+		t.Fatalf("NewRecipientKey() error = %v", err)
+	}
+	outsider, _, err := NewRecipientKey()
+	if err != nil {
+		t.Fatalf("NewRecipientKey() error = %v", err)
+	}
+
+	data := []byte(`{"version":1}`)
+	sealed, err := Seal(data, []string{pubA, pubB})
+	if err != nil {
+		t.Fatalf("Seal() error = %v", err)
+	}
+	if bytes.Contains(sealed, data) {
+		t.Fatalf("Seal() output contains the plaintext")
+	}
+
+	tests := []struct {
+		name     string
+		identity []byte
+		wantErr  error
+	}{
+		{name: "First recipient", identity: seedA},
+		{name: "Second recipient with trailing newline", identity: append(append([]byte{}, seedB...), '\n')},
+		{name: "Not a recipient", identity: outsider, wantErr: ErrNotRecipient},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Open(sealed, tt.identity)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Open() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Open() error = %v", err)
+			}
+			if !bytes.Equal(got, data) {
+				t.Errorf("Open() = %q, want %q", got, data)
+			}
+		})
+	}
+}
+
+func TestSealRejectsInvalidRecipients(t *testing.T) {
+	tests := []struct {
+		name       string
+		recipients []string
+	}{
+		{name: "No recipients"},
+		{name: "Account key instead of curve key", recipients: []string{"ABJHLOVMPA4CI6R5KLNGOB4GSLNIY7IOUPAJC4YFNDLQVIOBYQGUWVLA"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Seal([]byte("data"), tt.recipients); err == nil {
+				t.Errorf("Seal() error = nil, want an error")
+			}
+		})
+	}
+}
+
+func TestOpenDetectsTampering(t *testing.T) {
+	seed, pub, err := NewRecipientKey()
+	if err != nil {
+		t.Fatalf("NewRecipientKey() error = %v", err)
+	}
+	sealed, err := Seal([]byte("archive"), []string{pub})
+	if err != nil {
+		t.Fatalf("Seal() error = %v", err)
+	}
+
+	env := unmarshalEnvelope(t, sealed)
+	env.Ciphertext[0] ^= 0xff
+	if _, err := Open(marshalEnvelope(t, env), seed); err == nil {
+		t.Errorf("Open() of a tampered archive error = nil, want an error")
+	}
+}
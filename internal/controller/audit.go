@@ -0,0 +1,60 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/jradikk/nats-auth-operator/internal/audit"
+)
+
+// auditActor identifies the operator as the issuer in audit events
+const auditActor = "nats-auth-operator"
+
+// recordAudit sends a credential issuance event for obj to the audit sink. A failing sink is
+// logged but never fails the reconcile, since the credential has already been written.
+func recordAudit(ctx context.Context, sink audit.Sink, kind string, obj client.Object, event audit.Event) {
+	if sink == nil {
+		return
+	}
+	event.Time = time.Now().UTC()
+	event.Actor = auditActor
+	event.TraceID = traceID(ctx)
+	event.Resource = kind + "/" + obj.GetNamespace() + "/" + obj.GetName()
+
+	if err := sink.Record(ctx, event); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to record audit event", "action", event.Action, "credential", event.Credential)
+	}
+}
+
+// auditExpiry converts the exp claim of a JWT to an audit timestamp; 0 means no expiry
+func auditExpiry(expires int64) *time.Time {
+	if expires == 0 {
+		return nil
+	}
+	t := time.Unix(expires, 0).UTC()
+	return &t
+}
+
+// secretKey formats a Secret reference for audit events
+func secretKey(obj client.Object) string {
+	return obj.GetNamespace() + "/" + obj.GetName()
+}
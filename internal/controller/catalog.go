@@ -0,0 +1,95 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	natsv1alpha1 "github.com/jradikk/nats-auth-operator/api/v1alpha1"
+	"github.com/jradikk/nats-auth-operator/internal/authconf"
+	"github.com/jradikk/nats-auth-operator/internal/resolver"
+	natslabels "github.com/jradikk/nats-auth-operator/pkg/labels"
+)
+
+// publicCatalogConfigMapName returns the name of the ConfigMap the public catalog is written to
+func publicCatalogConfigMapName(authConfig *natsv1alpha1.NatsAuthConfig) string {
+	if authConfig.Spec.PublicCatalog != nil && authConfig.Spec.PublicCatalog.ConfigMapName != "" {
+		return authConfig.Spec.PublicCatalog.ConfigMapName
+	}
+	return fmt.Sprintf("%s-public", authConfig.Name)
+}
+
+// reconcilePublicCatalog writes the non-sensitive connection information of the NatsAuthConfig
+// to a ConfigMap app teams can be given read access to. operatorJWT is empty in token mode.
+func (r *NatsAuthConfigReconciler) reconcilePublicCatalog(ctx context.Context, authConfig *natsv1alpha1.NatsAuthConfig, operatorJWT string) error {
+	accountList := &natsv1alpha1.NatsAccountList{}
+	if err := r.List(ctx, accountList, client.MatchingFields{authConfigIndex: client.ObjectKeyFromObject(authConfig).String()}); err != nil {
+		return fmt.Errorf("failed to list accounts: %w", err)
+	}
+
+	catalog := authconf.Catalog{
+		NatsURL:           authConfig.Spec.NatsURL,
+		OperatorPublicKey: authConfig.Status.OperatorPubKey,
+		OperatorJWT:       operatorJWT,
+	}
+	if operatorJWT == "" {
+		catalog.OperatorPublicKey = ""
+	}
+	for _, account := range accountList.Items {
+		entry := authconf.CatalogAccount{
+			Name:      account.Name,
+			Namespace: account.Namespace,
+			PublicKey: account.Status.AccountID,
+		}
+		for _, export := range account.Spec.Exports {
+			entry.Exports = append(entry.Exports, authconf.CatalogExport{
+				Type:       string(export.Type),
+				Subject:    export.Subject,
+				Restricted: len(export.Accounts) > 0,
+			})
+		}
+		catalog.Accounts = append(catalog.Accounts, entry)
+	}
+
+	data, err := authconf.RenderCatalog(catalog)
+	if err != nil {
+		return err
+	}
+
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      publicCatalogConfigMapName(authConfig),
+			Namespace: authConfig.Namespace,
+		},
+		Data: data,
+	}
+	natslabels.Stamp(cm, authConfigOwners(authConfig))
+	if err := authConfigTracking.SetOwner(authConfig, cm, r.Scheme); err != nil {
+		return err
+	}
+	if err := resolver.ApplyConfigMap(ctx, r.Client, cm); err != nil {
+		return fmt.Errorf("failed to apply public catalog ConfigMap: %w", err)
+	}
+	log.FromContext(ctx).V(debugLevel).Info("Applied public catalog ConfigMap", "step", "apply-catalog", "configMap", cm.Namespace+"/"+cm.Name, "accounts", len(catalog.Accounts))
+	return nil
+}
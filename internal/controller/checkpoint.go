@@ -0,0 +1,46 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"github.com/nats-io/nkeys"
+
+	natsv1alpha1 "github.com/jradikk/nats-auth-operator/api/v1alpha1"
+)
+
+// checkpointComplete reports whether the issuing flow reached its final step.
+// Resources reconciled before checkpoints were introduced have no step recorded
+// and are treated as complete.
+func checkpointComplete(step, final natsv1alpha1.ReconcileStep) bool {
+	return step == "" || step == final
+}
+
+// publicKeyFromSeed returns the public key of a stored seed, or "" if the seed is missing or invalid
+func publicKeyFromSeed(seed []byte) string {
+	if len(seed) == 0 {
+		return ""
+	}
+	kp, err := nkeys.FromSeed(seed)
+	if err != nil {
+		return ""
+	}
+	pubKey, err := kp.PublicKey()
+	if err != nil {
+		return ""
+	}
+	return pubKey
+}
@@ -0,0 +1,88 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"fmt"
+	"net"
+	"slices"
+
+	natsv1alpha1 "github.com/jradikk/nats-auth-operator/api/v1alpha1"
+	jwtpkg "github.com/jradikk/nats-auth-operator/internal/jwt"
+)
+
+// userClaimPolicy is the claim policy of the NatsAuthConfig, or an empty one
+func userClaimPolicy(authConfig *natsv1alpha1.NatsAuthConfig) natsv1alpha1.UserClaimPolicy {
+	if authConfig.Spec.UserClaims == nil {
+		return natsv1alpha1.UserClaimPolicy{}
+	}
+	return *authConfig.Spec.UserClaims
+}
+
+// validateUserClaimPolicy checks the source networks of the claim policy are CIDR blocks
+func validateUserClaimPolicy(policy natsv1alpha1.UserClaimPolicy) error {
+	for _, cidr := range policy.SourceNetworks {
+		if _, _, err := net.ParseCIDR(cidr); err != nil {
+			return fmt.Errorf("userClaims.sourceNetworks: %q is not a CIDR block", cidr)
+		}
+	}
+	return nil
+}
+
+// applyUserClaimPolicy merges the claim policy of the NatsAuthConfig into the connection types
+// of a user JWT and returns the constraints to set on its claims; userExpiry applies the expiry
+// cap. Users of scoped signing keys only get the audience: the scope in the account JWT owns
+// everything else.
+func applyUserClaimPolicy(policy natsv1alpha1.UserClaimPolicy, user *natsv1alpha1.NatsUser, connectionTypes []string, scoped bool) ([]string, jwtpkg.UserConstraints, error) {
+	constraints := jwtpkg.UserConstraints{Audience: policy.Audience}
+
+	if policy.DenyBearerTokens && user.Spec.BearerToken {
+		return nil, constraints, permanent(natsv1alpha1.ReasonInvalidSpec, fmt.Errorf("bearer tokens are denied by the userClaims policy of the NatsAuthConfig"))
+	}
+	if scoped {
+		return connectionTypes, constraints, nil
+	}
+	constraints.SourceNetworks = policy.SourceNetworks
+
+	// Leaf node users are restricted to LEAFNODE by their purpose
+	if user.Spec.Purpose == natsv1alpha1.UserPurposeLeafNode {
+		return connectionTypes, constraints, nil
+	}
+	if len(connectionTypes) == 0 {
+		connectionTypes = connectionTypeStrings(policy.DefaultConnectionTypes)
+	}
+	if len(policy.AllowedConnectionTypes) > 0 {
+		allowed := connectionTypeStrings(policy.AllowedConnectionTypes)
+		if len(connectionTypes) == 0 {
+			return allowed, constraints, nil
+		}
+		for _, t := range connectionTypes {
+			if !slices.Contains(allowed, t) {
+				return nil, constraints, permanent(natsv1alpha1.ReasonInvalidSpec, fmt.Errorf("connection type %s is not allowed by the userClaims policy of the NatsAuthConfig", t))
+			}
+		}
+	}
+	return connectionTypes, constraints, nil
+}
+
+func connectionTypeStrings(types []natsv1alpha1.ConnectionType) []string {
+	out := make([]string, 0, len(types))
+	for _, t := range types {
+		out = append(out, string(t))
+	}
+	return out
+}
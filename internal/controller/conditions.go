@@ -0,0 +1,112 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"errors"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	natsv1alpha1 "github.com/jradikk/nats-auth-operator/api/v1alpha1"
+	"github.com/jradikk/nats-auth-operator/internal/authconf"
+	"github.com/jradikk/nats-auth-operator/internal/hooks"
+	jwtpkg "github.com/jradikk/nats-auth-operator/internal/jwt"
+	"github.com/jradikk/nats-auth-operator/internal/resolver"
+)
+
+// permanentRetryInterval is how often a resource failing with a permanent error is retried.
+// Spec changes and changes to watched dependencies trigger a reconcile sooner.
+const permanentRetryInterval = 10 * time.Minute
+
+// permanentError marks a failure that retrying cannot fix until the spec or its inputs change
+type permanentError struct {
+	reason natsv1alpha1.ReasonCode
+	err    error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+
+func (e *permanentError) Unwrap() error { return e.err }
+
+// permanent wraps err as a permanent failure with the given Ready condition reason
+func permanent(reason natsv1alpha1.ReasonCode, err error) error {
+	return &permanentError{reason: reason, err: err}
+}
+
+// classifyError returns the Ready condition reason for a failed reconcile and whether the
+// failure is permanent. Invalid specs and seeds, field manager conflicts on applied Secrets
+// and ConfigMaps, externally issued user JWTs signed by the wrong account, server auth
+// configs left in place after a manual edit and claims rejected by a claim hook are
+// permanent; missing dependencies, API conflicts and everything else are transient.
+func classifyError(err error) (natsv1alpha1.ReasonCode, bool) {
+	var perm *permanentError
+	if errors.As(err, &perm) {
+		return perm.reason, true
+	}
+	var conflict *resolver.ConflictError
+	if errors.As(err, &conflict) {
+		return natsv1alpha1.ReasonFieldManagerConflict, true
+	}
+	if _, ok := asDependencyError(err); ok {
+		return natsv1alpha1.ReasonDependencyNotReady, false
+	}
+	switch {
+	case errors.Is(err, jwtpkg.ErrInvalidSeed):
+		return natsv1alpha1.ReasonInvalidSeed, true
+	case errors.Is(err, jwtpkg.ErrIssuerMismatch):
+		return natsv1alpha1.ReasonIssuerMismatch, true
+	case errors.Is(err, authconf.ErrConfigDrift):
+		return natsv1alpha1.ReasonConfigDrift, true
+	case errors.Is(err, hooks.ErrRejected):
+		return natsv1alpha1.ReasonClaimsRejected, true
+	case apierrors.IsConflict(err):
+		return natsv1alpha1.ReasonConflict, false
+	}
+	return natsv1alpha1.ReasonReconcileError, false
+}
+
+// failureResult returns the result of a failed reconcile. Permanent failures are not returned
+// as errors, so the workqueue does not retry them with backoff, and are retried after
+// permanentRetryInterval instead; transient failures are returned for backoff.
+func failureResult(err error, permanent bool) (ctrl.Result, error) {
+	if permanent {
+		return ctrl.Result{RequeueAfter: permanentRetryInterval}, nil
+	}
+	return ctrl.Result{RequeueAfter: time.Minute}, err
+}
+
+// pendingReasons are Ready condition reasons that mean waiting rather than failing
+var pendingReasons = map[string]bool{
+	string(natsv1alpha1.ReasonDependencyNotReady): true,
+	string(natsv1alpha1.ReasonShutdownPending):    true,
+	"CredentialsNotReady":                         true,
+}
+
+// readyPhase maps a Ready condition to the phase shown in kubectl output
+func readyPhase(condition metav1.Condition) natsv1alpha1.Phase {
+	switch {
+	case condition.Status == metav1.ConditionTrue:
+		return natsv1alpha1.PhaseReady
+	case pendingReasons[condition.Reason]:
+		return natsv1alpha1.PhasePending
+	default:
+		return natsv1alpha1.PhaseError
+	}
+}
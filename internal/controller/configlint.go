@@ -0,0 +1,46 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"fmt"
+	"strings"
+
+	natsv1alpha1 "github.com/jradikk/nats-auth-operator/api/v1alpha1"
+	"github.com/jradikk/nats-auth-operator/internal/authconf"
+)
+
+// lintConfigData checks the configs in the server auth config data about to be written for syntax
+// errors when LintConfig is set: the configured key and every ".conf" key, with includes resolved
+// among the keys of data. Account JWTs and signatures are not configs and are skipped. A config
+// that does not parse is not written: it fails the reconcile with ConfigSyntaxError.
+func (r *NatsAuthConfigReconciler) lintConfigData(authConfig *natsv1alpha1.NatsAuthConfig, data map[string][]byte) error {
+	if !r.LintConfig {
+		return nil
+	}
+	ref := authConfig.Spec.ServerAuthConfig
+	for _, key := range sortedKeys(data) {
+		if key != ref.Key && !strings.HasSuffix(key, ".conf") {
+			continue
+		}
+		if err := authconf.LintConfigData(data, key); err != nil {
+			return permanent(natsv1alpha1.ReasonConfigSyntaxError, fmt.Errorf("leaving %s %s/%s unchanged: rendered key %s does not parse: %w",
+				serverAuthConfigType(authConfig), ref.Namespace, ref.Name, key, err))
+		}
+	}
+	return nil
+}
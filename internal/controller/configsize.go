@@ -0,0 +1,189 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	natsv1alpha1 "github.com/jradikk/nats-auth-operator/api/v1alpha1"
+	"github.com/jradikk/nats-auth-operator/internal/authconf"
+	"github.com/jradikk/nats-auth-operator/internal/secrets"
+)
+
+// configSizeCondition reports how close the server auth config is to the size limit of its object
+const configSizeCondition = "ConfigSize"
+
+// configChunkName returns the name of overflow Secret i of the server auth Secret
+func configChunkName(authConfig *natsv1alpha1.NatsAuthConfig, i int) string {
+	return fmt.Sprintf("%s-%d", authConfig.Spec.ServerAuthConfig.Name, i)
+}
+
+// formatSize formats a size in bytes as KiB
+func formatSize(size int) string {
+	return fmt.Sprintf("%dKiB", (size+1023)/1024)
+}
+
+// checkConfigSize sets the ConfigSize condition for the server auth config data about to be
+// written, which is spread over chunks Secrets when chunked. Data above the limit of a Secret or
+// ConfigMap is not written: it fails the reconcile with ConfigTooLarge.
+func (r *NatsAuthConfigReconciler) checkConfigSize(authConfig *natsv1alpha1.NatsAuthConfig, data map[string][]byte, chunks int) error {
+	size := authconf.DataSize(data)
+	ref := authConfig.Spec.ServerAuthConfig
+	hint := ""
+	if ref.SizePolicy != natsv1alpha1.SizePolicyChunk && usesJWT(effectiveMode(authConfig)) {
+		hint = "; set serverAuthConfig.sizePolicy to Chunk to spread the account JWTs over several Secrets"
+	}
+
+	switch {
+	case size > authconf.MaxConfigSize:
+		message := fmt.Sprintf("Server auth config holds %s, more than the %s limit%s", formatSize(size), formatSize(authconf.MaxConfigSize), hint)
+		r.updateCondition(authConfig, metav1.Condition{
+			Type:    configSizeCondition,
+			Status:  metav1.ConditionFalse,
+			Reason:  "LimitExceeded",
+			Message: message,
+		})
+		return permanent(natsv1alpha1.ReasonConfigTooLarge, fmt.Errorf("leaving %s %s/%s unchanged: %s",
+			serverAuthConfigType(authConfig), ref.Namespace, ref.Name, message))
+	case size > authconf.ConfigSizeWarning:
+		message := fmt.Sprintf("Server auth config holds %s of the %s limit%s", formatSize(size), formatSize(authconf.MaxConfigSize), hint)
+		previous := meta.FindStatusCondition(authConfig.Status.Conditions, configSizeCondition)
+		if r.Recorder != nil && (previous == nil || previous.Reason != "ApproachingLimit") {
+			r.Recorder.Event(authConfig, corev1.EventTypeWarning, "ConfigSizeApproachingLimit", message)
+		}
+		r.updateCondition(authConfig, metav1.Condition{
+			Type:    configSizeCondition,
+			Status:  metav1.ConditionFalse,
+			Reason:  "ApproachingLimit",
+			Message: message,
+		})
+	case chunks > 1:
+		names := []string{ref.Name}
+		for i := 1; i < chunks; i++ {
+			names = append(names, configChunkName(authConfig, i))
+		}
+		r.updateCondition(authConfig, metav1.Condition{
+			Type:    configSizeCondition,
+			Status:  metav1.ConditionTrue,
+			Reason:  "Chunked",
+			Message: fmt.Sprintf("Account JWTs are spread over %d Secrets: %s", chunks, strings.Join(names, ", ")),
+		})
+	default:
+		r.updateCondition(authConfig, metav1.Condition{
+			Type:    configSizeCondition,
+			Status:  metav1.ConditionTrue,
+			Reason:  "WithinLimit",
+			Message: fmt.Sprintf("Server auth config holds %s of the %s limit", formatSize(size), formatSize(authconf.MaxConfigSize)),
+		})
+	}
+	return nil
+}
+
+// applyConfigChunks writes the overflow chunks of a chunked server auth Secret, every chunk but
+// the first, to <name>-1, <name>-2, ... and deletes the overflow Secrets no longer needed
+func (r *NatsAuthConfigReconciler) applyConfigChunks(ctx context.Context, authConfig *natsv1alpha1.NatsAuthConfig, chunks []map[string][]byte) error {
+	ref := authConfig.Spec.ServerAuthConfig
+	desired := map[string]bool{}
+	for i := 1; i < len(chunks); i++ {
+		secret := secrets.New(ref.Namespace, configChunkName(authConfig, i), chunks[i])
+		secret.Labels = map[string]string{authconf.ConfigChunkLabel: strconv.Itoa(i)}
+		secret.Annotations = map[string]string{configHashAnnotation: hashSecretData(chunks[i])}
+		if err := r.setServerAuthConfigOwner(authConfig, secret); err != nil {
+			return err
+		}
+
+		// Never write into a Secret of the same name that belongs to something else
+		existing := &corev1.Secret{}
+		if err := r.Get(ctx, client.ObjectKeyFromObject(secret), existing); err == nil {
+			if existing.Labels[authConfigNameLabel] != authConfig.Name || existing.Labels[authConfigNamespaceLabel] != authConfig.Namespace {
+				return permanent(natsv1alpha1.ReasonSecretNameCollision, fmt.Errorf("overflow Secret %s/%s already exists and does not belong to this NatsAuthConfig", secret.Namespace, secret.Name))
+			}
+		} else if !errors.IsNotFound(err) {
+			return fmt.Errorf("failed to get overflow Secret %s/%s: %w", secret.Namespace, secret.Name, err)
+		}
+
+		if err := secrets.ApplyManaged(ctx, r.Client, secret); err != nil {
+			return fmt.Errorf("failed to apply overflow Secret %s/%s: %w", secret.Namespace, secret.Name, err)
+		}
+		desired[secret.Name] = true
+	}
+
+	found, err := r.listConfigChunks(ctx, authConfig)
+	if err != nil {
+		return err
+	}
+	for key, secret := range found {
+		if desired[key.Name] {
+			continue
+		}
+		if err := r.Delete(ctx, secret); err != nil && !errors.IsNotFound(err) {
+			return fmt.Errorf("failed to delete overflow Secret %s: %w", key, err)
+		}
+		log.FromContext(ctx).Info("Deleted overflow Secret no longer needed", "step", "apply-secret", "secret", key.String())
+	}
+	return nil
+}
+
+// listConfigChunks returns the overflow Secrets written for the server auth Secret. Under
+// restricted Secret access, which cannot list, the chunk names granted by the RBAC manager are
+// read in order up to the first missing one: chunks are written without gaps.
+func (r *NatsAuthConfigReconciler) listConfigChunks(ctx context.Context, authConfig *natsv1alpha1.NatsAuthConfig) (map[client.ObjectKey]*corev1.Secret, error) {
+	if r.SecretAccess.WatchesSecrets() {
+		return secrets.List(ctx, r.Client,
+			client.InNamespace(authConfig.Spec.ServerAuthConfig.Namespace),
+			authConfigSecretLabels(authConfig),
+			client.HasLabels{authconf.ConfigChunkLabel},
+		)
+	}
+
+	found := map[client.ObjectKey]*corev1.Secret{}
+	for i := 1; i <= maxGrantedConfigChunks; i++ {
+		key := client.ObjectKey{Namespace: authConfig.Spec.ServerAuthConfig.Namespace, Name: configChunkName(authConfig, i)}
+		secret, exists, err := secrets.Get(ctx, r.Client, key)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			break
+		}
+		if _, chunk := secret.Labels[authconf.ConfigChunkLabel]; chunk && secret.Labels[authConfigNameLabel] == authConfig.Name && secret.Labels[authConfigNamespaceLabel] == authConfig.Namespace {
+			found[key] = secret
+		}
+	}
+	return found, nil
+}
+
+// mergeConfigChunks returns the data of all chunks in one map; keys are unique across chunks
+func mergeConfigChunks(chunks []map[string][]byte) map[string][]byte {
+	merged := map[string][]byte{}
+	for _, chunk := range chunks {
+		for k, v := range chunk {
+			merged[k] = v
+		}
+	}
+	return merged
+}
@@ -0,0 +1,130 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	natsv1alpha1 "github.com/jradikk/nats-auth-operator/api/v1alpha1"
+)
+
+// connLimitCondition reports whether the connection limit of a NatsUser is enforced
+const connLimitCondition = "ConnectionLimit"
+
+// hasConnLimit reports whether the user asks for a connection limit
+func hasConnLimit(user *natsv1alpha1.NatsUser) bool {
+	return user.Spec.Limits != nil && user.Spec.Limits.Conn >= 0
+}
+
+// listAccountUsers returns the NatsUsers of the account that are not being deleted
+func listAccountUsers(ctx context.Context, c client.Client, account client.ObjectKey) ([]natsv1alpha1.NatsUser, error) {
+	userList := &natsv1alpha1.NatsUserList{}
+	if err := c.List(ctx, userList, client.MatchingFields{userAccountIndex: account.String()}); err != nil {
+		return nil, fmt.Errorf("failed to list users: %w", err)
+	}
+	users := userList.Items[:0]
+	for _, user := range userList.Items {
+		if user.DeletionTimestamp.IsZero() {
+			users = append(users, user)
+		}
+	}
+	return users, nil
+}
+
+// withUserConnLimit returns the account limits with the connection limit lowered to that of the
+// only user of the account. NATS limits connections per account, so this is the one case a
+// per-user connection limit can be enforced.
+func (r *NatsAccountReconciler) withUserConnLimit(ctx context.Context, account *natsv1alpha1.NatsAccount, limits *natsv1alpha1.AccountLimits) (*natsv1alpha1.AccountLimits, error) {
+	users, err := listAccountUsers(ctx, r.Client, client.ObjectKeyFromObject(account))
+	if err != nil {
+		return nil, err
+	}
+	if len(users) != 1 || !hasConnLimit(&users[0]) {
+		return limits, nil
+	}
+	conn := users[0].Spec.Limits.Conn
+	if limits == nil {
+		limits = &natsv1alpha1.AccountLimits{Conn: -1, Subs: -1, Payload: -1, Data: -1, Exports: -1, Imports: -1, WildcardExports: true}
+	} else {
+		limits = limits.DeepCopy()
+	}
+	if limits.Conn < 0 || conn < limits.Conn {
+		limits.Conn = conn
+	}
+	return limits, nil
+}
+
+// updateConnLimitCondition sets the ConnectionLimit condition of a user asking for a connection
+// limit, which is only enforced for the only user of a NatsAccount in JWT mode
+func (r *NatsUserReconciler) updateConnLimitCondition(ctx context.Context, user *natsv1alpha1.NatsUser, authType natsv1alpha1.UserAuthType) error {
+	if !hasConnLimit(user) {
+		meta.RemoveStatusCondition(&user.Status.Conditions, connLimitCondition)
+		return nil
+	}
+	condition := metav1.Condition{Type: connLimitCondition, Status: metav1.ConditionFalse, Reason: "NotRepresentable"}
+	switch {
+	case authType != natsv1alpha1.UserAuthTypeJWT:
+		condition.Message = "Token users have no connection limit; limits.conn is not enforced"
+	case !hasAccount(user):
+		condition.Message = "Users without a NatsAccount have no connection limit; limits.conn is not enforced"
+	default:
+		users, err := listAccountUsers(ctx, r.Client, accountKey(user))
+		if err != nil {
+			return err
+		}
+		if len(users) == 1 {
+			condition.Status = metav1.ConditionTrue
+			condition.Reason = "EnforcedByAccount"
+			condition.Message = fmt.Sprintf("Enforced as the connection limit of NatsAccount %s", accountKey(user))
+		} else {
+			condition.Message = fmt.Sprintf("NatsAccount %s has %d users; limits.conn is only enforced for the only user of an account", accountKey(user), len(users))
+		}
+	}
+	r.updateCondition(user, condition)
+	return nil
+}
+
+// findUsersSharingAccount enqueues the other users of the account of a user that ask for a
+// connection limit, whose ConnectionLimit condition depends on the number of users
+func (r *NatsUserReconciler) findUsersSharingAccount(ctx context.Context, obj client.Object) []reconcile.Request {
+	user, ok := obj.(*natsv1alpha1.NatsUser)
+	if !ok || !hasAccount(user) {
+		return nil
+	}
+	userList := &natsv1alpha1.NatsUserList{}
+	if err := r.List(ctx, userList, client.MatchingFields{userAccountIndex: accountKey(user).String()}); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to list NatsUsers")
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for i := range userList.Items {
+		other := &userList.Items[i]
+		if other.UID == user.UID || !hasConnLimit(other) {
+			continue
+		}
+		requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(other)})
+	}
+	return requests
+}
@@ -0,0 +1,111 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// DefaultAuthConfigDebounce is how long NatsAuthConfig reconciles triggered by child
+// changes are held back, so a burst of account updates results in one auth Secret write
+const DefaultAuthConfigDebounce = 2 * time.Second
+
+// debouncedHandler enqueues the mapped requests after a delay. The workqueue keeps a
+// single pending entry per request, so every event arriving within the delay is
+// handled by the same reconcile.
+type debouncedHandler struct {
+	delay time.Duration
+	mapFn handler.MapFunc
+
+	// flush tracks the queued requests, so those not reconciled before shutdown are recorded
+	flush *shutdownFlush
+}
+
+var _ handler.EventHandler = &debouncedHandler{}
+
+// newDebouncedHandler returns an event handler that batches the requests of mapFn over delay.
+// A zero delay enqueues immediately. flush may be nil.
+func newDebouncedHandler(delay time.Duration, flush *shutdownFlush, mapFn handler.MapFunc) handler.EventHandler {
+	return &debouncedHandler{delay: delay, mapFn: mapFn, flush: flush}
+}
+
+// Create implements handler.EventHandler
+func (h *debouncedHandler) Create(ctx context.Context, evt event.CreateEvent, q workqueue.RateLimitingInterface) {
+	h.enqueue(ctx, evt.Object, q)
+}
+
+// Update implements handler.EventHandler
+func (h *debouncedHandler) Update(ctx context.Context, evt event.UpdateEvent, q workqueue.RateLimitingInterface) {
+	h.enqueue(ctx, evt.ObjectOld, q)
+	h.enqueue(ctx, evt.ObjectNew, q)
+}
+
+// Delete implements handler.EventHandler
+func (h *debouncedHandler) Delete(ctx context.Context, evt event.DeleteEvent, q workqueue.RateLimitingInterface) {
+	h.enqueue(ctx, evt.Object, q)
+}
+
+// Generic implements handler.EventHandler
+func (h *debouncedHandler) Generic(ctx context.Context, evt event.GenericEvent, q workqueue.RateLimitingInterface) {
+	h.enqueue(ctx, evt.Object, q)
+}
+
+func (h *debouncedHandler) enqueue(ctx context.Context, obj client.Object, q workqueue.RateLimitingInterface) {
+	if obj == nil {
+		return
+	}
+	for _, req := range h.mapFn(ctx, obj) {
+		h.flush.queued(req.NamespacedName, fmt.Sprintf("change of %s %s/%s still queued", kindOf(obj), obj.GetNamespace(), obj.GetName()))
+		if h.delay <= 0 {
+			q.Add(req)
+			continue
+		}
+		q.AddAfter(req, h.delay)
+	}
+}
+
+// deletingPredicate passes updates of objects being deleted, so finalizers run even when
+// the update is filtered otherwise
+var deletingPredicate = predicate.Funcs{
+	UpdateFunc: func(e event.UpdateEvent) bool {
+		return !e.ObjectNew.GetDeletionTimestamp().IsZero()
+	},
+}
+
+// requestForObject maps an object to its own reconcile request
+func requestForObject(_ context.Context, obj client.Object) []reconcile.Request {
+	return []reconcile.Request{{NamespacedName: client.ObjectKeyFromObject(obj)}}
+}
+
+// kindOf returns the kind of a typed object, whose TypeMeta is empty when read from the cache
+func kindOf(obj client.Object) string {
+	t := reflect.TypeOf(obj)
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t.Name()
+}
@@ -0,0 +1,66 @@
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	natsv1alpha1 "github.com/jradikk/nats-auth-operator/api/v1alpha1"
+)
+
+// toAuthConfig maps every child to the reconcile of the auth NatsAuthConfig
+func toAuthConfig(context.Context, client.Object) []reconcile.Request {
+	return []reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: "nats", Name: "auth"}}}
+}
+
+func TestDebouncedHandlerCollapsesEvents(t *testing.T) {
+	const delay = 100 * time.Millisecond
+	h := newDebouncedHandler(delay, nil, toAuthConfig)
+	q := workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter())
+	defer q.ShutDown()
+	ctx := context.Background()
+
+	// A burst of child changes within the window
+	start := time.Now()
+	for _, name := range []string{"orders", "billing", "payments"} {
+		account := testAccount(name)
+		h.Create(ctx, event.CreateEvent{Object: account}, q)
+		updated := account.DeepCopy()
+		updated.Spec.Description = name
+		h.Update(ctx, event.UpdateEvent{ObjectOld: account, ObjectNew: updated}, q)
+	}
+	h.Delete(ctx, event.DeleteEvent{Object: testUser("orders-app", natsv1alpha1.UserAuthTypeJWT, "orders")}, q)
+	if q.Len() != 0 {
+		t.Fatalf("queue length right after the burst = %d, want the reconcile held back", q.Len())
+	}
+
+	// is handled by a single reconcile once the window has passed
+	item, _ := q.Get()
+	if elapsed := time.Since(start); elapsed < delay {
+		t.Errorf("reconcile queued after %v, want it held back for %v", elapsed, delay)
+	}
+	if want := toAuthConfig(ctx, nil)[0]; item != want {
+		t.Errorf("queued %v, want %v", item, want)
+	}
+	q.Done(item)
+	time.Sleep(2 * delay)
+	if q.Len() != 0 {
+		t.Errorf("queue length after the window = %d, want the burst collapsed into one reconcile", q.Len())
+	}
+
+	// Without a delay every change is queued at once, still as one pending request
+	h = newDebouncedHandler(0, nil, toAuthConfig)
+	for i := 0; i < 3; i++ {
+		h.Generic(ctx, event.GenericEvent{Object: &natsv1alpha1.NatsAccount{ObjectMeta: metav1.ObjectMeta{Namespace: "apps", Name: "orders"}}}, q)
+	}
+	if q.Len() != 1 {
+		t.Errorf("queue length without a delay = %d, want 1", q.Len())
+	}
+}
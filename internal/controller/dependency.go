@@ -0,0 +1,54 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	// dependencyBackoffBase and dependencyBackoffCap bound the requeue delay while waiting for a dependency
+	dependencyBackoffBase = 5 * time.Second
+	dependencyBackoffCap  = 5 * time.Minute
+)
+
+// dependencyError reports that a referenced resource is missing or not ready yet
+type dependencyError struct {
+	// Kind of the dependency, e.g. NatsAccount
+	Kind string
+	// Name of the dependency as namespace/name
+	Name string
+	// Reason is the condition reason, e.g. AccountNotReady
+	Reason string
+	// Message describes what is missing
+	Message string
+}
+
+func (e *dependencyError) Error() string {
+	return fmt.Sprintf("%s %s: %s", e.Kind, e.Name, e.Message)
+}
+
+// asDependencyError unwraps a dependencyError from err
+func asDependencyError(err error) (*dependencyError, bool) {
+	var depErr *dependencyError
+	if errors.As(err, &depErr) {
+		return depErr, true
+	}
+	return nil, false
+}
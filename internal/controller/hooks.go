@@ -0,0 +1,57 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"fmt"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	natsv1alpha1 "github.com/jradikk/nats-auth-operator/api/v1alpha1"
+	"github.com/jradikk/nats-auth-operator/internal/hooks"
+)
+
+// claimHooks returns the mutators run on claims issued under authConfig: the one registered
+// at manager setup, followed by the HTTP hooks configured on the NatsAuthConfig in order
+func claimHooks(registered hooks.Mutator, authConfig *natsv1alpha1.NatsAuthConfig) (hooks.Chain, error) {
+	chain := hooks.Chain{registered}
+	for _, spec := range authConfig.Spec.ClaimHooks {
+		httpClient, err := hooks.NewHTTPClient([]byte(spec.CABundle), time.Duration(spec.TimeoutSeconds)*time.Second)
+		if err != nil {
+			return nil, fmt.Errorf("invalid claim hook %s: %w", spec.Name, err)
+		}
+		chain = append(chain, &hooks.HTTPHook{
+			Name:          spec.Name,
+			URL:           spec.URL,
+			Kinds:         spec.Kinds,
+			IgnoreFailure: spec.FailurePolicy == natsv1alpha1.ClaimHookFailurePolicyIgnore,
+			Client:        httpClient,
+		})
+	}
+	return chain, nil
+}
+
+// hookRequest describes the resource whose claims are passed to the claim hooks
+func hookRequest(kind string, obj client.Object, authConfig *natsv1alpha1.NatsAuthConfig) hooks.Request {
+	return hooks.Request{
+		Kind:       kind,
+		Namespace:  obj.GetNamespace(),
+		Name:       obj.GetName(),
+		AuthConfig: client.ObjectKeyFromObject(authConfig).String(),
+	}
+}
@@ -0,0 +1,179 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	natsv1alpha1 "github.com/jradikk/nats-auth-operator/api/v1alpha1"
+)
+
+const (
+	// userAccountIndex indexes NatsUsers by the "namespace/name" of their NatsAccount
+	userAccountIndex = "spec.accountRef"
+
+	// authConfigIndex indexes NatsAccounts and NatsUsers by the "namespace/name" of their NatsAuthConfig
+	authConfigIndex = "spec.authConfigRef"
+
+	// passwordSecretIndex indexes NatsUsers by the "namespace/name" of their passwordFrom Secret
+	passwordSecretIndex = "spec.passwordFrom.secretRef"
+
+	// operatorSeedIndex indexes NatsAuthConfigs by the "namespace/name" of their operator seed Secret
+	operatorSeedIndex = "spec.jwt.operatorSeedSecret"
+
+	// templatesConfigMapIndex indexes NatsAuthConfigs by the "namespace/name" of their templates ConfigMap
+	templatesConfigMapIndex = "spec.serverAuthConfig.templatesConfigMap"
+
+	// accountImportIndex indexes NatsAccounts by the "namespace/name" of the accounts they import from
+	accountImportIndex = "spec.imports.accountRef"
+
+	// tokenUsernameIndex indexes NatsUsers by "<NatsAuthConfig namespace/name>/<username>" of the
+	// username they specify or hold in token mode
+	tokenUsernameIndex = "tokenUsername"
+
+	// seedSecretIndex indexes NatsAuthConfigs, NatsAccounts and NatsUsers by the "namespace/name"
+	// of the seed Secrets generated for them
+	seedSecretIndex = "seedSecret"
+)
+
+// seedSecretKeys returns the keys of the seed Secrets generated for a NatsAuthConfig, NatsAccount
+// or NatsUser: the operator seed and signing key, the account JWT Secret or the credentials Secret
+func seedSecretKeys(obj client.Object) []client.ObjectKey {
+	var keys []client.ObjectKey
+	switch o := obj.(type) {
+	case *natsv1alpha1.NatsAuthConfig:
+		if o.Spec.JWT == nil {
+			return nil
+		}
+		if o.Spec.JWT.OperatorSeedSecret == nil {
+			keys = append(keys, client.ObjectKey{Namespace: o.Namespace, Name: operatorSeedSecretName(o)})
+		}
+		if o.Spec.JWT.StrictSigningKeyUsage {
+			keys = append(keys, client.ObjectKey{Namespace: o.Namespace, Name: operatorSigningKeySecretName(o)})
+		}
+	case *natsv1alpha1.NatsAccount:
+		if ref := o.Status.JWTSecretRef; ref.Name != "" {
+			keys = append(keys, client.ObjectKey{Namespace: ref.Namespace, Name: ref.Name})
+		}
+	case *natsv1alpha1.NatsUser:
+		if ref := o.Status.SecretRef; ref.Name != "" && o.Status.PublicKey != "" && o.Spec.ExistingJWTSecret == nil {
+			keys = append(keys, client.ObjectKey{Namespace: ref.Namespace, Name: ref.Name})
+		}
+	}
+	return keys
+}
+
+// indexBySeedSecret extracts the seedSecretIndex values from a NatsAuthConfig, NatsAccount or NatsUser
+func indexBySeedSecret(obj client.Object) []string {
+	var values []string
+	for _, key := range seedSecretKeys(obj) {
+		values = append(values, key.String())
+	}
+	return values
+}
+
+// indexAuthConfigByOperatorSeed extracts the operatorSeedIndex value from a NatsAuthConfig
+func indexAuthConfigByOperatorSeed(obj client.Object) []string {
+	authConfig, ok := obj.(*natsv1alpha1.NatsAuthConfig)
+	if !ok || authConfig.Spec.JWT == nil || authConfig.Spec.JWT.OperatorSeedSecret == nil {
+		return nil
+	}
+	ref := authConfig.Spec.JWT.OperatorSeedSecret
+	return []string{client.ObjectKey{Namespace: ref.Namespace, Name: ref.Name}.String()}
+}
+
+// indexAuthConfigByTemplates extracts the templatesConfigMapIndex value from a NatsAuthConfig
+func indexAuthConfigByTemplates(obj client.Object) []string {
+	authConfig, ok := obj.(*natsv1alpha1.NatsAuthConfig)
+	if !ok || authConfig.Spec.ServerAuthConfig.TemplatesConfigMap == "" {
+		return nil
+	}
+	return []string{client.ObjectKey{Namespace: authConfig.Namespace, Name: authConfig.Spec.ServerAuthConfig.TemplatesConfigMap}.String()}
+}
+
+// indexAccountByImport extracts the accountImportIndex values from a NatsAccount
+func indexAccountByImport(obj client.Object) []string {
+	account, ok := obj.(*natsv1alpha1.NatsAccount)
+	if !ok {
+		return nil
+	}
+	var values []string
+	for _, imp := range account.Spec.Imports {
+		values = append(values, accountRefKey(imp.AccountRef, account.Namespace).String())
+	}
+	return values
+}
+
+// indexUserByAccount extracts the userAccountIndex value from a NatsUser
+func indexUserByAccount(obj client.Object) []string {
+	user, ok := obj.(*natsv1alpha1.NatsUser)
+	if !ok || !hasAccount(user) {
+		return nil
+	}
+	return []string{accountKey(user).String()}
+}
+
+// indexUserByTokenUsername extracts the tokenUsernameIndex values from a NatsUser
+func indexUserByTokenUsername(obj client.Object) []string {
+	user, ok := obj.(*natsv1alpha1.NatsUser)
+	if !ok {
+		return nil
+	}
+	// A username held in status stays claimed until the user takes the one in spec
+	var values []string
+	if user.Spec.Username != "" {
+		values = append(values, tokenUsernameKey(user, user.Spec.Username))
+	}
+	if user.Status.Username != "" && user.Status.Username != user.Spec.Username {
+		values = append(values, tokenUsernameKey(user, user.Status.Username))
+	}
+	return values
+}
+
+// tokenUsernameKey returns the tokenUsernameIndex value of a username claimed by the user
+func tokenUsernameKey(user *natsv1alpha1.NatsUser, username string) string {
+	return authConfigKey(user).String() + "/" + username
+}
+
+// indexUserByPasswordSecret extracts the passwordSecretIndex value from a NatsUser
+func indexUserByPasswordSecret(obj client.Object) []string {
+	user, ok := obj.(*natsv1alpha1.NatsUser)
+	if !ok || user.Spec.PasswordFrom == nil || user.Spec.PasswordFrom.SecretRef == nil {
+		return nil
+	}
+	return []string{user.Spec.PasswordFrom.SecretRef.ObjectKey(user.Namespace).String()}
+}
+
+// indexByAuthConfig extracts the authConfigIndex value from a NatsAccount or NatsUser
+func indexByAuthConfig(obj client.Object) []string {
+	switch o := obj.(type) {
+	case *natsv1alpha1.NatsAccount:
+		return []string{accountAuthConfigKey(o).String()}
+	case *natsv1alpha1.NatsUser:
+		return []string{authConfigKey(o).String()}
+	}
+	return nil
+}
+
+// accountAuthConfigKey returns the key of the NatsAuthConfig referenced by the account
+func accountAuthConfigKey(account *natsv1alpha1.NatsAccount) client.ObjectKey {
+	namespace := account.Spec.AuthConfigRef.Namespace
+	if namespace == "" {
+		namespace = account.Namespace
+	}
+	return client.ObjectKey{Namespace: namespace, Name: account.Spec.AuthConfigRef.Name}
+}
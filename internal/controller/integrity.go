@@ -0,0 +1,191 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/nats-io/nkeys"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	natsv1alpha1 "github.com/jradikk/nats-auth-operator/api/v1alpha1"
+	"github.com/jradikk/nats-auth-operator/internal/authconf"
+)
+
+// configIntegrityCondition reports whether the written server auth config matches its signature
+const configIntegrityCondition = "ConfigIntegrity"
+
+// serverAuthConfigIndex indexes NatsAuthConfigs by the "namespace/name" of their server auth config
+const serverAuthConfigIndex = "spec.serverAuthConfig"
+
+// integrityKey returns the data key the server auth config signature is written to
+func integrityKey(authConfig *natsv1alpha1.NatsAuthConfig) string {
+	return authconf.IntegrityKey(authConfig.Spec.ServerAuthConfig.Key)
+}
+
+// serverAuthConfigType returns the kind of object the server auth config is written to;
+// JWT and mixed mode always write a Secret
+func serverAuthConfigType(authConfig *natsv1alpha1.NatsAuthConfig) string {
+	if effectiveMode(authConfig) == natsv1alpha1.AuthModeToken {
+		return authConfig.Spec.ServerAuthConfig.Type
+	}
+	return "Secret"
+}
+
+// signConfigData adds the signature of data under the integrity key. The operator key signs
+// the config in JWT and mixed mode; token mode has no operator key and writes a plain checksum.
+func signConfigData(authConfig *natsv1alpha1.NatsAuthConfig, data map[string][]byte, operatorKP nkeys.KeyPair) error {
+	key := integrityKey(authConfig)
+	if _, ok := data[key]; ok {
+		return fmt.Errorf("key %q collides with the server auth config signature", key)
+	}
+
+	signature, err := authconf.SignConfigData(data, operatorKP)
+	if err != nil {
+		return err
+	}
+	data[key] = signature
+	return nil
+}
+
+// verifyServerAuthConfig checks the live server auth config against the signature written with it
+// and applies the drift policy. A config without a signature, e.g. before the first write, passes.
+// A deleted config is reported and then recreated by the caller regardless of the drift policy.
+// Under the Alert policy a drift is returned as an error wrapping authconf.ErrConfigDrift so the
+// caller leaves the config in place.
+func (r *NatsAuthConfigReconciler) verifyServerAuthConfig(ctx context.Context, authConfig *natsv1alpha1.NatsAuthConfig, signer string) error {
+	ref := authConfig.Spec.ServerAuthConfig
+	data, err := r.readServerAuthConfig(ctx, authConfig)
+	if err != nil {
+		return err
+	}
+	if data == nil && authConfig.Status.ConfigHash != "" {
+		log.FromContext(ctx).Info("Server auth config was deleted, recreating it", "step", "verify-config",
+			"config", ref.Namespace+"/"+ref.Name)
+		if r.Recorder != nil {
+			r.Recorder.Eventf(authConfig, corev1.EventTypeWarning, "ServerAuthConfigDeleted", "%s %s/%s was deleted, recreating it",
+				serverAuthConfigType(authConfig), ref.Namespace, ref.Name)
+		}
+		return nil
+	}
+	signature, ok := data[integrityKey(authConfig)]
+	if !ok {
+		return nil
+	}
+
+	driftErr := authconf.VerifyConfigData(data, signature, signer)
+	if driftErr == nil {
+		r.updateCondition(authConfig, metav1.Condition{
+			Type:    configIntegrityCondition,
+			Status:  metav1.ConditionTrue,
+			Reason:  "Verified",
+			Message: "Server auth config matches its signature",
+		})
+		return nil
+	}
+
+	log.FromContext(ctx).Info("Server auth config drifted from its signature", "step", "verify-config",
+		"config", ref.Namespace+"/"+ref.Name, "driftPolicy", ref.DriftPolicy, "reason", driftErr.Error())
+	if r.Recorder != nil {
+		r.Recorder.Eventf(authConfig, corev1.EventTypeWarning, "ConfigDriftDetected", "Server auth config %s/%s was modified outside the operator: %v",
+			ref.Namespace, ref.Name, driftErr)
+	}
+
+	if ref.DriftPolicy == natsv1alpha1.DriftPolicyAlert {
+		r.updateCondition(authConfig, metav1.Condition{
+			Type:    configIntegrityCondition,
+			Status:  metav1.ConditionFalse,
+			Reason:  "DriftDetected",
+			Message: driftErr.Error(),
+		})
+		return fmt.Errorf("leaving %s %s/%s in place under driftPolicy Alert: %w", serverAuthConfigType(authConfig), ref.Namespace, ref.Name, driftErr)
+	}
+
+	r.updateCondition(authConfig, metav1.Condition{
+		Type:    configIntegrityCondition,
+		Status:  metav1.ConditionTrue,
+		Reason:  "Restored",
+		Message: "Restored server auth config after drift: " + driftErr.Error(),
+	})
+	return nil
+}
+
+// readServerAuthConfig returns the data of the live server auth config, or nil when it does not exist
+func (r *NatsAuthConfigReconciler) readServerAuthConfig(ctx context.Context, authConfig *natsv1alpha1.NatsAuthConfig) (map[string][]byte, error) {
+	ref := authConfig.Spec.ServerAuthConfig
+	key := client.ObjectKey{Namespace: ref.Namespace, Name: ref.Name}
+	if serverAuthConfigType(authConfig) == "Secret" {
+		secret := &corev1.Secret{}
+		if err := r.Get(ctx, key, secret); err != nil {
+			if errors.IsNotFound(err) {
+				return nil, nil
+			}
+			return nil, fmt.Errorf("failed to get server auth Secret: %w", err)
+		}
+		return secret.Data, nil
+	}
+
+	cm := &corev1.ConfigMap{}
+	if err := r.Get(ctx, key, cm); err != nil {
+		if errors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get server auth ConfigMap: %w", err)
+	}
+	data := make(map[string][]byte, len(cm.Data))
+	for k, v := range cm.Data {
+		data[k] = []byte(v)
+	}
+	return data, nil
+}
+
+// indexAuthConfigByServerConfig extracts the serverAuthConfigIndex value from a NatsAuthConfig
+func indexAuthConfigByServerConfig(obj client.Object) []string {
+	authConfig, ok := obj.(*natsv1alpha1.NatsAuthConfig)
+	if !ok {
+		return nil
+	}
+	ref := authConfig.Spec.ServerAuthConfig
+	return []string{client.ObjectKey{Namespace: ref.Namespace, Name: ref.Name}.String()}
+}
+
+// findAuthConfigForServerConfig maps a changed or deleted Secret or ConfigMap to the NatsAuthConfigs
+// writing their server auth config to it, so a manual edit is verified and a deleted config is
+// recreated right away instead of at the next resync
+func (r *NatsAuthConfigReconciler) findAuthConfigForServerConfig(ctx context.Context, obj client.Object) []reconcile.Request {
+	list := &natsv1alpha1.NatsAuthConfigList{}
+	if err := r.List(ctx, list, client.MatchingFields{serverAuthConfigIndex: client.ObjectKeyFromObject(obj).String()}); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to list NatsAuthConfigs for server auth config")
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for _, authConfig := range list.Items {
+		wantSecret := serverAuthConfigType(&authConfig) == "Secret"
+		if _, isSecret := obj.(*corev1.Secret); isSecret != wantSecret {
+			continue
+		}
+		requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&authConfig)})
+	}
+	return requests
+}
@@ -0,0 +1,102 @@
+package controller
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	natsv1alpha1 "github.com/jradikk/nats-auth-operator/api/v1alpha1"
+	"github.com/jradikk/nats-auth-operator/internal/authconf"
+)
+
+func TestServerAuthConfigTamperRestored(t *testing.T) {
+	tests := []struct {
+		name        string
+		mode        natsv1alpha1.AuthMode
+		key         string
+		driftPolicy natsv1alpha1.DriftPolicy
+		wantRestore bool
+	}{
+		{name: "JWT mode restores", mode: natsv1alpha1.AuthModeJWT, key: authconf.OperatorKey, driftPolicy: natsv1alpha1.DriftPolicyRestore, wantRestore: true},
+		{name: "Token mode restores", mode: natsv1alpha1.AuthModeToken, key: "auth.conf", driftPolicy: natsv1alpha1.DriftPolicyRestore, wantRestore: true},
+		{name: "Alert leaves the tampered config", mode: natsv1alpha1.AuthModeJWT, key: authconf.OperatorKey, driftPolicy: natsv1alpha1.DriftPolicyAlert},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			authConfig := testAuthConfig(tt.mode)
+			authConfig.Spec.ServerAuthConfig.DriftPolicy = tt.driftPolicy
+			c, scheme := newTestClient(authConfig)
+			recorder := record.NewFakeRecorder(10)
+			r := &NatsAuthConfigReconciler{Client: c, Scheme: scheme, Recorder: recorder}
+			ctx := context.Background()
+
+			mustReconcile(t, r, authConfig)
+			written := serverAuthSecret(t, c)
+			drainEvents(recorder)
+
+			// Edit the rendered config behind the operator's back
+			tampered := written.DeepCopy()
+			tampered.Data[tt.key] = []byte("tampered")
+			if err := c.Update(ctx, tampered); err != nil {
+				t.Fatal(err)
+			}
+			_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(authConfig)})
+			if tt.wantRestore && err != nil {
+				t.Fatalf("Reconcile() error = %v", err)
+			}
+
+			if events := drainEvents(recorder); len(events) == 0 || !strings.Contains(events[0], "ConfigDriftDetected") {
+				t.Errorf("Reconcile() recorded %v, want a ConfigDriftDetected event", events)
+			}
+			mustGet(t, c, authConfig)
+			integrity := meta.FindStatusCondition(authConfig.Status.Conditions, configIntegrityCondition)
+			live := serverAuthSecret(t, c)
+			if tt.wantRestore {
+				if integrity == nil || integrity.Status != metav1.ConditionTrue || integrity.Reason != "Restored" {
+					t.Errorf("Reconcile() %s condition = %+v, want Restored", configIntegrityCondition, integrity)
+				}
+				if string(live.Data[tt.key]) != string(written.Data[tt.key]) {
+					t.Errorf("Reconcile() left %s = %q, want the rendered config restored", tt.key, live.Data[tt.key])
+				}
+				return
+			}
+			if integrity == nil || integrity.Status != metav1.ConditionFalse || integrity.Reason != "DriftDetected" {
+				t.Errorf("Reconcile() %s condition = %+v, want DriftDetected", configIntegrityCondition, integrity)
+			}
+			if meta.IsStatusConditionTrue(authConfig.Status.Conditions, "Ready") {
+				t.Error("Reconcile() kept Ready true with a drifted config under driftPolicy Alert")
+			}
+			if string(live.Data[tt.key]) != "tampered" {
+				t.Errorf("Reconcile() overwrote %s under driftPolicy Alert", tt.key)
+			}
+		})
+	}
+}
+
+func TestServerAuthConfigDeletedRecreated(t *testing.T) {
+	authConfig := testAuthConfig(natsv1alpha1.AuthModeJWT)
+	c, scheme := newTestClient(authConfig)
+	recorder := record.NewFakeRecorder(10)
+	r := &NatsAuthConfigReconciler{Client: c, Scheme: scheme, Recorder: recorder}
+
+	mustReconcile(t, r, authConfig)
+	written := serverAuthSecret(t, c)
+	drainEvents(recorder)
+	if err := c.Delete(context.Background(), written); err != nil {
+		t.Fatal(err)
+	}
+
+	mustReconcile(t, r, authConfig)
+	if events := drainEvents(recorder); len(events) == 0 || !strings.Contains(events[0], "ServerAuthConfigDeleted") {
+		t.Errorf("Reconcile() recorded %v, want a ServerAuthConfigDeleted event", events)
+	}
+	if live := serverAuthSecret(t, c); string(live.Data[authconf.OperatorKey]) != string(written.Data[authconf.OperatorKey]) {
+		t.Errorf("Reconcile() recreated operator = %q, want the operator JWT written before", live.Data[authconf.OperatorKey])
+	}
+}
@@ -0,0 +1,62 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"sort"
+
+	"github.com/go-logr/logr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// debugLevel is the verbosity of debug log lines, enabled with --zap-log-level=debug
+const debugLevel = 1
+
+// reconcileLogger returns a logger for the reconcile of the given custom resource.
+// Every line carries the "cr" key next to the reconcileID added by controller-runtime.
+func reconcileLogger(ctx context.Context, kind string, obj client.Object) (context.Context, logr.Logger) {
+	return withLogValues(ctx, "cr", kind+"/"+obj.GetNamespace()+"/"+obj.GetName())
+}
+
+// withLogValues adds key/value pairs to the logger carried in ctx
+func withLogValues(ctx context.Context, keysAndValues ...interface{}) (context.Context, logr.Logger) {
+	logger := log.FromContext(ctx).WithValues(keysAndValues...)
+	return log.IntoContext(ctx, logger), logger
+}
+
+// withStep tags the logger carried in ctx with the current reconcile step
+func withStep(ctx context.Context, step string) (context.Context, logr.Logger) {
+	return withLogValues(ctx, "step", step)
+}
+
+// traceID returns the correlation ID of the current reconcile
+func traceID(ctx context.Context) string {
+	return string(controller.ReconcileIDFromContext(ctx))
+}
+
+// sortedKeys returns the keys of a Secret data map in order, for logging without values
+func sortedKeys(data map[string][]byte) []string {
+	keys := make([]string, 0, len(data))
+	for k := range data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,130 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	natsv1alpha1 "github.com/jradikk/nats-auth-operator/api/v1alpha1"
+)
+
+// maxMigrationUsers caps the pending users listed in status.migration
+const maxMigrationUsers = 10
+
+// effectiveMode returns the mode the server auth config is rendered in: status.mode, which
+// trails spec.mode while a migration is pending, or spec.mode before the first reconcile
+func effectiveMode(authConfig *natsv1alpha1.NatsAuthConfig) natsv1alpha1.AuthMode {
+	if authConfig.Status.Mode != "" {
+		return authConfig.Status.Mode
+	}
+	return authConfig.Spec.Mode
+}
+
+// usesJWT reports whether the server auth config is rendered with an operator in mode
+func usesJWT(mode natsv1alpha1.AuthMode) bool {
+	return mode == natsv1alpha1.AuthModeJWT || mode == natsv1alpha1.AuthModeMixed
+}
+
+// inheritedAuthType returns the auth type of users inheriting mode: token users in token mode,
+// JWT users under an operator
+func inheritedAuthType(mode natsv1alpha1.AuthMode) natsv1alpha1.UserAuthType {
+	if usesJWT(mode) {
+		return natsv1alpha1.UserAuthTypeJWT
+	}
+	return natsv1alpha1.UserAuthTypeToken
+}
+
+// blocksModeChange reports whether user stops working when the server auth config changes from
+// one mode to the other. Switching between JWT and mixed mode keeps the operator, so nothing
+// blocks it. Otherwise users with an explicit token auth type need token mode, and users with
+// an explicit JWT auth type or a leafnode or jetstream-controller purpose need an operator;
+// users inheriting the mode follow it.
+func blocksModeChange(user *natsv1alpha1.NatsUser, from, to natsv1alpha1.AuthMode) bool {
+	if usesJWT(from) == usesJWT(to) {
+		return false
+	}
+	if usesJWT(from) {
+		return user.Spec.AuthType == natsv1alpha1.UserAuthTypeJWT ||
+			user.Spec.Purpose == natsv1alpha1.UserPurposeLeafNode ||
+			user.Spec.Purpose == natsv1alpha1.UserPurposeJetStreamController
+	}
+	return user.Spec.AuthType == natsv1alpha1.UserAuthTypeToken
+}
+
+// reconcileModeMigration moves status.mode to spec.mode. While NatsUsers referencing the
+// NatsAuthConfig still require the current mode, the server auth config keeps being rendered
+// in it and status.migration lists the users left to convert; the NatsUser watch retries the
+// migration as they change.
+func (r *NatsAuthConfigReconciler) reconcileModeMigration(ctx context.Context, authConfig *natsv1alpha1.NatsAuthConfig) error {
+	ctx, log := withStep(ctx, "mode-migration")
+	from, to := effectiveMode(authConfig), authConfig.Spec.Mode
+	if from == to {
+		authConfig.Status.Mode = to
+		authConfig.Status.Migration = nil
+		return nil
+	}
+
+	userList := &natsv1alpha1.NatsUserList{}
+	if err := r.List(ctx, userList, client.MatchingFields{authConfigIndex: client.ObjectKeyFromObject(authConfig).String()}); err != nil {
+		return fmt.Errorf("failed to list users: %w", err)
+	}
+	var pending []string
+	for i := range userList.Items {
+		user := &userList.Items[i]
+		if user.DeletionTimestamp.IsZero() && blocksModeChange(user, from, to) {
+			pending = append(pending, client.ObjectKeyFromObject(user).String())
+		}
+	}
+
+	if len(pending) == 0 {
+		log.Info("Auth mode migrated", "from", from, "to", to)
+		if r.Recorder != nil {
+			r.Recorder.Eventf(authConfig, corev1.EventTypeNormal, "ModeMigrated", "Auth mode changed from %s to %s", from, to)
+		}
+		authConfig.Status.Mode = to
+		authConfig.Status.Migration = nil
+		return nil
+	}
+
+	sort.Strings(pending)
+	migration := &natsv1alpha1.ModeMigration{From: from, To: to, PendingUsers: int32(len(pending))}
+	if len(pending) > maxMigrationUsers {
+		pending = pending[:maxMigrationUsers]
+	}
+	migration.Users = pending
+	if previous := authConfig.Status.Migration; previous != nil && previous.To == to {
+		migration.StartedAt = previous.StartedAt
+	} else {
+		now := metav1.Now()
+		migration.StartedAt = &now
+		if r.Recorder != nil {
+			r.Recorder.Eventf(authConfig, corev1.EventTypeWarning, "ModeMigrationPending",
+				"Keeping %s mode until %d NatsUsers no longer require it: %s", from, migration.PendingUsers, strings.Join(pending, ", "))
+		}
+	}
+	log.Info("Auth mode migration pending", "from", from, "to", to, "pendingUsers", migration.PendingUsers)
+	authConfig.Status.Mode = from
+	authConfig.Status.Migration = migration
+	return nil
+}
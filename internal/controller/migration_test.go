@@ -0,0 +1,200 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	natsv1alpha1 "github.com/jradikk/nats-auth-operator/api/v1alpha1"
+)
+
+func TestEffectiveMode(t *testing.T) {
+	tests := []struct {
+		spec, status, want natsv1alpha1.AuthMode
+	}{
+		{spec: natsv1alpha1.AuthModeJWT, want: natsv1alpha1.AuthModeJWT},
+		{spec: natsv1alpha1.AuthModeToken, status: natsv1alpha1.AuthModeToken, want: natsv1alpha1.AuthModeToken},
+		{spec: natsv1alpha1.AuthModeJWT, status: natsv1alpha1.AuthModeToken, want: natsv1alpha1.AuthModeToken},
+		{spec: natsv1alpha1.AuthModeToken, status: natsv1alpha1.AuthModeMixed, want: natsv1alpha1.AuthModeMixed},
+	}
+	for _, tt := range tests {
+		authConfig := &natsv1alpha1.NatsAuthConfig{
+			Spec:   natsv1alpha1.NatsAuthConfigSpec{Mode: tt.spec},
+			Status: natsv1alpha1.NatsAuthConfigStatus{Mode: tt.status},
+		}
+		if got := effectiveMode(authConfig); got != tt.want {
+			t.Errorf("effectiveMode(spec %q, status %q) = %q, want %q", tt.spec, tt.status, got, tt.want)
+		}
+	}
+}
+
+// migrationUsers returns a user of each kind that can block a mode change, keyed by name
+func migrationUsers() map[string]*natsv1alpha1.NatsUser {
+	leafNode := testUser("leafnode", natsv1alpha1.UserAuthTypeInherit, "")
+	leafNode.Spec.Purpose = natsv1alpha1.UserPurposeLeafNode
+	controller := testUser("jetstream-controller", natsv1alpha1.UserAuthTypeInherit, "")
+	controller.Spec.Purpose = natsv1alpha1.UserPurposeJetStreamController
+	return map[string]*natsv1alpha1.NatsUser{
+		"inherit":              testUser("inherit", natsv1alpha1.UserAuthTypeInherit, ""),
+		"token":                testUser("token", natsv1alpha1.UserAuthTypeToken, ""),
+		"jwt":                  testUser("jwt", natsv1alpha1.UserAuthTypeJWT, ""),
+		"leafnode":             leafNode,
+		"jetstream-controller": controller,
+	}
+}
+
+func TestBlocksModeChange(t *testing.T) {
+	tests := []struct {
+		from, to    natsv1alpha1.AuthMode
+		wantBlocked []string
+	}{
+		{from: natsv1alpha1.AuthModeToken, to: natsv1alpha1.AuthModeToken},
+		{from: natsv1alpha1.AuthModeJWT, to: natsv1alpha1.AuthModeJWT},
+		{from: natsv1alpha1.AuthModeJWT, to: natsv1alpha1.AuthModeMixed},
+		{from: natsv1alpha1.AuthModeMixed, to: natsv1alpha1.AuthModeJWT},
+		{from: natsv1alpha1.AuthModeMixed, to: natsv1alpha1.AuthModeMixed},
+		{from: natsv1alpha1.AuthModeToken, to: natsv1alpha1.AuthModeJWT, wantBlocked: []string{"token"}},
+		// Mixed mode renders the JWT config, so token users block moving to it as well
+		{from: natsv1alpha1.AuthModeToken, to: natsv1alpha1.AuthModeMixed, wantBlocked: []string{"token"}},
+		{from: natsv1alpha1.AuthModeJWT, to: natsv1alpha1.AuthModeToken, wantBlocked: []string{"jetstream-controller", "jwt", "leafnode"}},
+		{from: natsv1alpha1.AuthModeMixed, to: natsv1alpha1.AuthModeToken, wantBlocked: []string{"jetstream-controller", "jwt", "leafnode"}},
+	}
+	users := migrationUsers()
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s to %s", tt.from, tt.to), func(t *testing.T) {
+			blocked := map[string]bool{}
+			for _, name := range tt.wantBlocked {
+				blocked[name] = true
+			}
+			for name, user := range users {
+				if got := blocksModeChange(user, tt.from, tt.to); got != blocked[name] {
+					t.Errorf("blocksModeChange(%s) = %v, want %v", name, got, blocked[name])
+				}
+			}
+		})
+	}
+}
+
+func TestReconcileModeMigration(t *testing.T) {
+	tests := []struct {
+		name        string
+		from, to    natsv1alpha1.AuthMode
+		users       []string
+		wantMode    natsv1alpha1.AuthMode
+		wantPending []string
+	}{
+		{name: "Unchanged mode", from: natsv1alpha1.AuthModeToken, to: natsv1alpha1.AuthModeToken, users: []string{"token"}, wantMode: natsv1alpha1.AuthModeToken},
+		{name: "Token to JWT without token users", from: natsv1alpha1.AuthModeToken, to: natsv1alpha1.AuthModeJWT, users: []string{"inherit", "jwt"}, wantMode: natsv1alpha1.AuthModeJWT},
+		{name: "Token to JWT with a token user", from: natsv1alpha1.AuthModeToken, to: natsv1alpha1.AuthModeJWT, users: []string{"inherit", "token"}, wantMode: natsv1alpha1.AuthModeToken, wantPending: []string{"apps/token"}},
+		{name: "Token to mixed with a token user", from: natsv1alpha1.AuthModeToken, to: natsv1alpha1.AuthModeMixed, users: []string{"token"}, wantMode: natsv1alpha1.AuthModeToken, wantPending: []string{"apps/token"}},
+		{name: "Token to mixed without token users", from: natsv1alpha1.AuthModeToken, to: natsv1alpha1.AuthModeMixed, users: []string{"inherit"}, wantMode: natsv1alpha1.AuthModeMixed},
+		{name: "JWT to mixed", from: natsv1alpha1.AuthModeJWT, to: natsv1alpha1.AuthModeMixed, users: []string{"jwt", "leafnode"}, wantMode: natsv1alpha1.AuthModeMixed},
+		{name: "Mixed to JWT", from: natsv1alpha1.AuthModeMixed, to: natsv1alpha1.AuthModeJWT, users: []string{"jwt", "token"}, wantMode: natsv1alpha1.AuthModeJWT},
+		{name: "JWT to token with operator users", from: natsv1alpha1.AuthModeJWT, to: natsv1alpha1.AuthModeToken, users: []string{"inherit", "jwt", "leafnode", "jetstream-controller"}, wantMode: natsv1alpha1.AuthModeJWT, wantPending: []string{"apps/jetstream-controller", "apps/jwt", "apps/leafnode"}},
+		{name: "Mixed to token with a JWT user", from: natsv1alpha1.AuthModeMixed, to: natsv1alpha1.AuthModeToken, users: []string{"jwt"}, wantMode: natsv1alpha1.AuthModeMixed, wantPending: []string{"apps/jwt"}},
+		{name: "JWT to token with inheriting users", from: natsv1alpha1.AuthModeJWT, to: natsv1alpha1.AuthModeToken, users: []string{"inherit", "token"}, wantMode: natsv1alpha1.AuthModeToken},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			authConfig := testAuthConfig(tt.to)
+			authConfig.Status.Mode = tt.from
+			all := migrationUsers()
+			objs := []client.Object{authConfig}
+			for _, name := range tt.users {
+				objs = append(objs, all[name])
+			}
+			c, scheme := newTestClient(objs...)
+			r := &NatsAuthConfigReconciler{Client: c, Scheme: scheme}
+
+			if err := r.reconcileModeMigration(context.Background(), authConfig); err != nil {
+				t.Fatalf("reconcileModeMigration() error = %v", err)
+			}
+			if authConfig.Status.Mode != tt.wantMode {
+				t.Errorf("reconcileModeMigration() status.mode = %q, want %q", authConfig.Status.Mode, tt.wantMode)
+			}
+			migration := authConfig.Status.Migration
+			if len(tt.wantPending) == 0 {
+				if migration != nil {
+					t.Errorf("reconcileModeMigration() status.migration = %+v, want none", migration)
+				}
+				return
+			}
+			if migration == nil || migration.From != tt.from || migration.To != tt.to ||
+				int(migration.PendingUsers) != len(tt.wantPending) || !reflect.DeepEqual(migration.Users, tt.wantPending) || migration.StartedAt == nil {
+				t.Errorf("reconcileModeMigration() status.migration = %+v, want %s to %s pending %v", migration, tt.from, tt.to, tt.wantPending)
+			}
+		})
+	}
+}
+
+func TestReconcileModeMigrationProgress(t *testing.T) {
+	authConfig := testAuthConfig(natsv1alpha1.AuthModeJWT)
+	authConfig.Status.Mode = natsv1alpha1.AuthModeToken
+	objs := []client.Object{authConfig}
+	for i := 0; i < maxMigrationUsers+2; i++ {
+		objs = append(objs, testUser(fmt.Sprintf("token-%02d", i), natsv1alpha1.UserAuthTypeToken, ""))
+	}
+	c, scheme := newTestClient(objs...)
+	recorder := record.NewFakeRecorder(10)
+	r := &NatsAuthConfigReconciler{Client: c, Scheme: scheme, Recorder: recorder}
+	ctx := context.Background()
+
+	// The pending users listed are capped, and the migration keeps its start across reconciles
+	if err := r.reconcileModeMigration(ctx, authConfig); err != nil {
+		t.Fatal(err)
+	}
+	migration := authConfig.Status.Migration
+	if migration == nil || migration.PendingUsers != maxMigrationUsers+2 || len(migration.Users) != maxMigrationUsers {
+		t.Fatalf("reconcileModeMigration() status.migration = %+v, want %d pending users and %d listed", migration, maxMigrationUsers+2, maxMigrationUsers)
+	}
+	startedAt := metav1.NewTime(time.Now().Add(-time.Hour))
+	migration.StartedAt = &startedAt
+	if err := r.reconcileModeMigration(ctx, authConfig); err != nil {
+		t.Fatal(err)
+	}
+	if got := authConfig.Status.Migration.StartedAt; got == nil || !got.Equal(&startedAt) {
+		t.Errorf("reconcileModeMigration() status.migration.startedAt = %v, want %v", got, startedAt)
+	}
+	if events := drainEvents(recorder); len(events) != 1 || !strings.Contains(events[0], " ModeMigrationPending ") {
+		t.Errorf("reconcileModeMigration() recorded %v, want one ModeMigrationPending event", events)
+	}
+
+	// Converting the token users completes the migration
+	for _, obj := range objs[1:] {
+		user := obj.(*natsv1alpha1.NatsUser)
+		mustGet(t, c, user)
+		user.Spec.AuthType = natsv1alpha1.UserAuthTypeJWT
+		if err := c.Update(ctx, user); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := r.reconcileModeMigration(ctx, authConfig); err != nil {
+		t.Fatal(err)
+	}
+	if authConfig.Status.Mode != natsv1alpha1.AuthModeJWT || authConfig.Status.Migration != nil {
+		t.Errorf("reconcileModeMigration() status = %q, %+v, want jwt mode without a migration", authConfig.Status.Mode, authConfig.Status.Migration)
+	}
+	if events := drainEvents(recorder); len(events) != 1 || !strings.Contains(events[0], " ModeMigrated ") {
+		t.Errorf("reconcileModeMigration() recorded %v, want one ModeMigrated event", events)
+	}
+}
+
+// drainEvents returns the events recorded so far
+func drainEvents(recorder *record.FakeRecorder) []string {
+	var events []string
+	for {
+		select {
+		case event := <-recorder.Events:
+			events = append(events, event)
+		default:
+			return events
+		}
+	}
+}
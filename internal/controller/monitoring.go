@@ -0,0 +1,125 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	natsv1alpha1 "github.com/jradikk/nats-auth-operator/api/v1alpha1"
+	jwtpkg "github.com/jradikk/nats-auth-operator/internal/jwt"
+	"github.com/jradikk/nats-auth-operator/internal/resolver"
+)
+
+// monitoringUserName returns the name of the NatsUser provisioned for metrics collectors
+func monitoringUserName(authConfig *natsv1alpha1.NatsAuthConfig) string {
+	if authConfig.Spec.Monitoring != nil && authConfig.Spec.Monitoring.UserName != "" {
+		return authConfig.Spec.Monitoring.UserName
+	}
+	return fmt.Sprintf("%s-monitoring", authConfig.Name)
+}
+
+// monitoringUser returns the NatsUser of spec.monitoring: a JWT user of the system account
+// limited to the $SYS requests and events, with the creds file also under the nats.creds key
+func monitoringUser(authConfig *natsv1alpha1.NatsAuthConfig) *natsv1alpha1.NatsUser {
+	monitoring := authConfig.Spec.Monitoring
+	accountRef := monitoring.SystemAccountRef
+	if accountRef.Namespace == "" {
+		accountRef.Namespace = authConfig.Namespace
+	}
+	name := monitoringUserName(authConfig)
+	secretName := monitoring.SecretName
+	if secretName == "" {
+		secretName = name
+	}
+
+	return &natsv1alpha1.NatsUser{
+		TypeMeta: metav1.TypeMeta{APIVersion: natsv1alpha1.GroupVersion.String(), Kind: "NatsUser"},
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: authConfig.Namespace,
+			Name:      name,
+		},
+		Spec: natsv1alpha1.NatsUserSpec{
+			AuthConfigRef: natsv1alpha1.NatsAuthConfigRef{Name: authConfig.Name},
+			AuthType:      natsv1alpha1.UserAuthTypeJWT,
+			AccountRef:    &accountRef,
+			Permissions:   jwtpkg.MonitoringPermissions(),
+			SecretName:    secretName,
+			Output: &natsv1alpha1.CredentialsOutput{
+				Format:       natsv1alpha1.CredentialsFormatCreds,
+				WellKnownKey: true,
+			},
+		},
+	}
+}
+
+// reconcileMonitoringUser creates or updates the NatsUser of spec.monitoring, and deletes the one
+// created earlier once spec.monitoring is removed or names another user
+func (r *NatsAuthConfigReconciler) reconcileMonitoringUser(ctx context.Context, authConfig *natsv1alpha1.NatsAuthConfig) error {
+	log := log.FromContext(ctx)
+
+	var desired *natsv1alpha1.NatsUser
+	if authConfig.Spec.Monitoring != nil {
+		desired = monitoringUser(authConfig)
+		if err := controllerutil.SetControllerReference(authConfig, desired, r.Scheme); err != nil {
+			return err
+		}
+
+		existing := &natsv1alpha1.NatsUser{}
+		err := r.Get(ctx, client.ObjectKeyFromObject(desired), existing)
+		switch {
+		case err == nil && !metav1.IsControlledBy(existing, authConfig):
+			return permanent(natsv1alpha1.ReasonInvalidSpec,
+				fmt.Errorf("NatsUser %s/%s exists and does not belong to this NatsAuthConfig; set monitoring.userName", desired.Namespace, desired.Name))
+		case err != nil && !errors.IsNotFound(err):
+			return fmt.Errorf("failed to get monitoring NatsUser: %w", err)
+		case errors.IsNotFound(err):
+			log.Info("Creating monitoring user", "step", "apply-monitoring-user", "user", desired.Name, "account", desired.Spec.AccountRef.Namespace+"/"+desired.Spec.AccountRef.Name)
+			if r.Recorder != nil {
+				r.Recorder.Eventf(authConfig, corev1.EventTypeNormal, "MonitoringUserCreated", "Created NatsUser %s in system account %s/%s",
+					desired.Name, desired.Spec.AccountRef.Namespace, desired.Spec.AccountRef.Name)
+			}
+		}
+		if err := resolver.ApplyObject(ctx, r.Client, desired); err != nil {
+			return fmt.Errorf("failed to apply monitoring NatsUser: %w", err)
+		}
+	}
+
+	// A monitoring user left behind by a removed or renamed monitoring block is deleted
+	userList := &natsv1alpha1.NatsUserList{}
+	if err := r.List(ctx, userList, client.InNamespace(authConfig.Namespace)); err != nil {
+		return fmt.Errorf("failed to list NatsUsers: %w", err)
+	}
+	for i := range userList.Items {
+		user := &userList.Items[i]
+		if !metav1.IsControlledBy(user, authConfig) || (desired != nil && user.Name == desired.Name) {
+			continue
+		}
+		if err := r.Delete(ctx, user); err != nil && !errors.IsNotFound(err) {
+			return fmt.Errorf("failed to delete monitoring NatsUser %s: %w", user.Name, err)
+		}
+		log.Info("Deleted monitoring user", "step", "delete-monitoring-user", "user", user.Name)
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package controller
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/nats-io/jwt/v2"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	natsv1alpha1 "github.com/jradikk/nats-auth-operator/api/v1alpha1"
+	jwtpkg "github.com/jradikk/nats-auth-operator/internal/jwt"
+)
+
+func TestMonitoringUser(t *testing.T) {
+	authConfig := testAuthConfig(natsv1alpha1.AuthModeJWT)
+	authConfig.Spec.Monitoring = &natsv1alpha1.MonitoringConfig{SystemAccountRef: natsv1alpha1.NatsAccountRef{Name: "sys"}}
+	system := testAccount("sys")
+	system.Namespace = "nats"
+	c, scheme := newTestClient(authConfig, system)
+	authConfigs, accounts, users := testReconcilers(c, scheme)
+	ctx := context.Background()
+
+	// The NatsAuthConfig creates a user of the system account it controls
+	mustReconcile(t, authConfigs, authConfig)
+	user := &natsv1alpha1.NatsUser{ObjectMeta: metav1.ObjectMeta{Namespace: "nats", Name: "auth-monitoring"}}
+	mustGet(t, c, user)
+	if !metav1.IsControlledBy(user, authConfig) || user.Spec.AccountRef == nil || *user.Spec.AccountRef != (natsv1alpha1.NatsAccountRef{Namespace: "nats", Name: "sys"}) ||
+		!reflect.DeepEqual(user.Spec.Permissions, jwtpkg.MonitoringPermissions()) {
+		t.Errorf("Reconcile() created NatsUser %+v controlled by %v, want a monitoring user of nats/sys", user.Spec, metav1.GetControllerOf(user))
+	}
+
+	// whose creds file a metrics collector mounts under nats.creds
+	mustReconcile(t, accounts, system)
+	mustReconcile(t, users, user)
+	creds := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "nats", Name: "auth-monitoring"}}
+	mustGet(t, c, creds)
+	userJWT, err := jwt.ParseDecoratedJWT(creds.Data[jwtpkg.WellKnownCredsKey])
+	if err != nil {
+		t.Fatalf("creds Secret %s = %v, want a creds file", jwtpkg.WellKnownCredsKey, err)
+	}
+	if claims, err := jwt.DecodeUserClaims(userJWT); err != nil || !claims.Pub.Allow.Contains("$SYS.REQ.>") {
+		t.Errorf("monitoring user claims %+v, %v, want $SYS server requests allowed", claims, err)
+	}
+
+	// Removing spec.monitoring deletes the user again, once its finalizer ran
+	mustGet(t, c, authConfig)
+	authConfig.Spec.Monitoring = nil
+	if err := c.Update(ctx, authConfig); err != nil {
+		t.Fatal(err)
+	}
+	mustReconcile(t, authConfigs, authConfig)
+	if err := c.Get(ctx, client.ObjectKeyFromObject(user), user); !errors.IsNotFound(err) && user.DeletionTimestamp.IsZero() {
+		t.Errorf("monitoring NatsUser after spec.monitoring was removed: %v, want it deleted", err)
+	}
+}
+
+func TestMonitoringUserNameTaken(t *testing.T) {
+	authConfig := testAuthConfig(natsv1alpha1.AuthModeJWT)
+	authConfig.Spec.Monitoring = &natsv1alpha1.MonitoringConfig{SystemAccountRef: natsv1alpha1.NatsAccountRef{Name: "sys"}}
+	taken := testUser("auth-monitoring", natsv1alpha1.UserAuthTypeJWT, "orders")
+	taken.Namespace = "nats"
+	c, scheme := newTestClient(authConfig, taken)
+	authConfigs, _, _ := testReconcilers(c, scheme)
+
+	// A NatsUser of the same name not created for monitoring is left alone
+	mustReconcile(t, authConfigs, authConfig)
+	mustGet(t, c, authConfig)
+	ready := meta.FindStatusCondition(authConfig.Status.Conditions, "Ready")
+	if ready == nil || ready.Status != metav1.ConditionFalse || ready.Reason != string(natsv1alpha1.ReasonInvalidSpec) {
+		t.Errorf("Reconcile() Ready condition = %+v, want InvalidSpec", ready)
+	}
+	mustGet(t, c, taken)
+	if taken.Spec.AccountRef.Name != "orders" || len(taken.OwnerReferences) != 0 {
+		t.Errorf("Reconcile() changed the existing NatsUser to %+v", taken)
+	}
+}
@@ -17,8 +17,10 @@ limitations under the License.
 package controller
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"sort"
 	"time"
 
 	"github.com/nats-io/nkeys"
@@ -27,12 +29,24 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	natsv1alpha1 "github.com/jradikk/nats-auth-operator/api/v1alpha1"
+	"github.com/jradikk/nats-auth-operator/internal/audit"
+	"github.com/jradikk/nats-auth-operator/internal/hooks"
 	jwtpkg "github.com/jradikk/nats-auth-operator/internal/jwt"
+	"github.com/jradikk/nats-auth-operator/internal/random"
+	"github.com/jradikk/nats-auth-operator/internal/resolver"
+	"github.com/jradikk/nats-auth-operator/internal/secrets"
+	"github.com/jradikk/nats-auth-operator/internal/signer"
+	natslabels "github.com/jradikk/nats-auth-operator/pkg/labels"
 )
 
 const (
@@ -43,17 +57,35 @@ const (
 type NatsAccountReconciler struct {
 	client.Client
 	Scheme *runtime.Scheme
+
+	// Resync controls periodic requeueing after a successful reconcile
+	Resync ResyncConfig
+
+	// Settings loads the operator-wide defaults
+	Settings *SettingsLoader
+
+	// Audit records credential issuance; nil disables the audit log
+	Audit audit.Sink
+
+	// ClaimHooks mutates account claims before they are signed, ahead of the HTTP hooks
+	// configured on the NatsAuthConfig (optional)
+	ClaimHooks hooks.Mutator
+
+	// Random generates keys and passwords; crypto/rand when nil
+	Random random.Generator
+
+	// SecretAccess restricted stops listing and watching Secrets; cluster when empty
+	SecretAccess SecretAccess
 }
 
 // +kubebuilder:rbac:groups=nats.jradikk,resources=natsaccounts,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=nats.jradikk,resources=natsaccounts/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=nats.jradikk,resources=natsaccounts/finalizers,verbs=update
 // +kubebuilder:rbac:groups=nats.jradikk,resources=natsauthconfigs,verbs=get;list;watch
+// +kubebuilder:rbac:groups=nats.jradikk,resources=natsusers,verbs=get;list;watch
 // +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch;create;update;patch;delete
 
 func (r *NatsAccountReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
-	log := log.FromContext(ctx)
-
 	// Fetch the NatsAccount instance
 	account := &natsv1alpha1.NatsAccount{}
 	if err := r.Get(ctx, req.NamespacedName, account); err != nil {
@@ -63,6 +95,8 @@ func (r *NatsAccountReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 		return ctrl.Result{}, err
 	}
 
+	ctx, log := reconcileLogger(ctx, "NatsAccount", account)
+
 	// Handle deletion
 	if !account.ObjectMeta.DeletionTimestamp.IsZero() {
 		return r.handleDeletion(ctx, account)
@@ -75,55 +109,83 @@ func (r *NatsAccountReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 			return ctrl.Result{}, err
 		}
 	}
+	ctx = withStatusBase(ctx, account)
 
-	// Get the referenced NatsAuthConfig
-	authConfig, err := r.getAuthConfig(ctx, account)
-	if err != nil {
-		log.Error(err, "Failed to get NatsAuthConfig")
+	// A paused resource is left as it is until spec.paused is unset
+	if paused, err := reconcilePaused(ctx, r.Client, account, account.Spec.Paused, account.Status.Conditions, func(c metav1.Condition) {
+		r.updateCondition(account, c)
+	}); paused || err != nil {
+		return ctrl.Result{}, err
+	}
+
+	// Validate the spec
+	if err := account.Spec.Validate(); err != nil {
+		log.Error(err, "Invalid spec")
 		r.updateCondition(account, metav1.Condition{
 			Type:    "Ready",
 			Status:  metav1.ConditionFalse,
-			Reason:  "AuthConfigNotFound",
+			Reason:  string(natsv1alpha1.ReasonInvalidSpec),
 			Message: err.Error(),
 		})
-		if err := r.Status().Update(ctx, account); err != nil {
+		if err := patchStatus(ctx, r.Client, account); err != nil {
 			return ctrl.Result{}, err
 		}
+		return ctrl.Result{RequeueAfter: permanentRetryInterval}, nil
+	}
+
+	// Load the operator-wide defaults
+	settings, err := r.Settings.Load(ctx)
+	if err != nil {
+		log.Error(err, "Failed to load operator settings")
 		return ctrl.Result{RequeueAfter: time.Minute}, err
 	}
 
-	// Validate that AuthConfig is in JWT mode
-	if authConfig.Spec.Mode != natsv1alpha1.AuthModeJWT && authConfig.Spec.Mode != natsv1alpha1.AuthModeMixed {
-		err := fmt.Errorf("NatsAuthConfig must be in JWT or mixed mode for NatsAccount")
-		log.Error(err, "Invalid auth mode")
+	// Get the referenced NatsAuthConfig
+	authConfig, err := r.getAuthConfig(ctx, account)
+	if err != nil {
+		log.Error(err, "Failed to get NatsAuthConfig")
 		r.updateCondition(account, metav1.Condition{
 			Type:    "Ready",
 			Status:  metav1.ConditionFalse,
-			Reason:  "InvalidAuthMode",
+			Reason:  string(natsv1alpha1.ReasonAuthConfigNotFound),
 			Message: err.Error(),
 		})
-		if err := r.Status().Update(ctx, account); err != nil {
+		if err := patchStatus(ctx, r.Client, account); err != nil {
 			return ctrl.Result{}, err
 		}
 		return ctrl.Result{RequeueAfter: time.Minute}, err
 	}
 
-	// Reconcile the account
-	if err := r.reconcileAccount(ctx, account, authConfig); err != nil {
-		log.Error(err, "Failed to reconcile account")
-		r.updateCondition(account, metav1.Condition{
-			Type:    "Ready",
-			Status:  metav1.ConditionFalse,
-			Reason:  "ReconcileError",
-			Message: err.Error(),
-		})
-		if err := r.Status().Update(ctx, account); err != nil {
-			return ctrl.Result{}, err
+	ctx, log = withLogValues(ctx, "authConfig", client.ObjectKeyFromObject(authConfig).String())
+
+	// Token-mode accounts are rendered into the static accounts block by the NatsAuthConfig
+	// controller; only JWT and mixed mode issue account keys
+	if effectiveMode(authConfig) == natsv1alpha1.AuthModeToken {
+		log.V(debugLevel).Info("Token mode, rendered by NatsAuthConfig", "step", "token-account")
+	} else {
+		// Reconcile the account
+		if err := r.reconcileAccount(ctx, account, authConfig, settings); err != nil {
+			reason, isPermanent := classifyError(err)
+			log.Error(err, "Failed to reconcile account", "reason", reason, "permanent", isPermanent)
+			r.updateCondition(account, metav1.Condition{
+				Type:    "Ready",
+				Status:  metav1.ConditionFalse,
+				Reason:  string(reason),
+				Message: err.Error(),
+			})
+			if err := patchStatus(ctx, r.Client, account); err != nil {
+				return ctrl.Result{}, err
+			}
+			return failureResult(err, isPermanent)
 		}
-		return ctrl.Result{RequeueAfter: time.Minute}, err
 	}
 
 	// Update status
+	if err := r.updateUsers(ctx, account); err != nil {
+		log.Error(err, "Failed to list account users")
+		return ctrl.Result{RequeueAfter: time.Minute}, err
+	}
+
 	now := metav1.Now()
 	account.Status.LastReconciled = &now
 	account.Status.ObservedGeneration = account.Generation
@@ -131,60 +193,165 @@ func (r *NatsAccountReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 	r.updateCondition(account, metav1.Condition{
 		Type:    "Ready",
 		Status:  metav1.ConditionTrue,
-		Reason:  "ReconcileSuccess",
+		Reason:  string(natsv1alpha1.ReasonReconcileSuccess),
 		Message: "NatsAccount reconciled successfully",
 	})
 
-	if err := r.Status().Update(ctx, account); err != nil {
+	if err := patchStatus(ctx, r.Client, account); err != nil {
 		return ctrl.Result{}, err
 	}
 
 	log.Info("NatsAccount reconciled successfully", "accountID", account.Status.AccountID)
 
-	return ctrl.Result{RequeueAfter: 5 * time.Minute}, nil
+	return r.Resync.withSettings(settings).Result(account.Spec.ResyncInterval), nil
+}
+
+// accountOwners returns the well-known labels of the Secrets generated for a NatsAccount
+func accountOwners(account *natsv1alpha1.NatsAccount, authConfig *natsv1alpha1.NatsAuthConfig) natslabels.Owners {
+	return natslabels.Owners{AuthConfig: authConfig.Name, Account: account.Name}
 }
 
-func (r *NatsAccountReconciler) reconcileAccount(ctx context.Context, account *natsv1alpha1.NatsAccount, authConfig *natsv1alpha1.NatsAuthConfig) error {
+func (r *NatsAccountReconciler) reconcileAccount(ctx context.Context, account *natsv1alpha1.NatsAccount, authConfig *natsv1alpha1.NatsAuthConfig, settings *natsv1alpha1.NatsOperatorSettingsSpec) error {
 	log := log.FromContext(ctx)
 
 	// Check if JWT secret already exists
-	jwtSecretName := fmt.Sprintf("%s-account-jwt", account.Name)
-	existingSecret := &corev1.Secret{}
-	jwtSecretExists := false
-	err := r.Get(ctx, client.ObjectKey{Namespace: account.Namespace, Name: jwtSecretName}, existingSecret)
-	if err == nil {
-		jwtSecretExists = true
-		// JWT already exists - verify it matches the status before skipping
-		if account.Status.AccountID != "" && len(existingSecret.Data["account.jwt"]) > 0 && len(existingSecret.Data["account.seed"]) > 0 {
-			// Verify the seed in the secret generates the same account ID as in status
-			seedData := existingSecret.Data["account.seed"]
-			kp, err := nkeys.FromSeed(seedData)
-			if err == nil {
-				pubKey, err := kp.PublicKey()
-				if err == nil && pubKey == account.Status.AccountID {
-					// JWT exists, status matches seed - no need to regenerate
-					log.Info("Account JWT already exists and matches status, skipping regeneration", "accountID", account.Status.AccountID)
-					return nil
-				}
+	jwtSecretName, err := accountJWTSecretName(account, settings)
+	if err != nil {
+		return err
+	}
+	limits, err := r.withUserConnLimit(ctx, account, accountLimits(account, settings))
+	if err != nil {
+		return err
+	}
+	existingSecret, _, err := secrets.Get(ctx, r.Client, client.ObjectKey{Namespace: account.Namespace, Name: jwtSecretName})
+	if err != nil {
+		return err
+	}
+	storedSeed, _, _ := jwtpkg.FindSeed(existingSecret.Data, nkeys.PrefixByteAccount, "")
+
+	sharing, activators, err := r.accountSharing(ctx, account)
+	if err != nil {
+		return err
+	}
+	mappings, err := jwtpkg.AccountMappings(account.Spec.Mappings)
+	if err != nil {
+		return permanent(natsv1alpha1.ReasonInvalidSpec, err)
+	}
+
+	signingKeys, signingSeeds, signingKeysGenerated, err := accountSigningKeys(random.OrDefault(r.Random), account, existingSecret.Data)
+	if err != nil {
+		return err
+	}
+
+	// Under an external signer the account key lives on the signer and no seed is stored
+	ext, err := externalSigner(authConfig)
+	if err != nil {
+		return err
+	}
+	var accountKP nkeys.KeyPair
+	if ext != nil {
+		if account.Spec.ExistingSeedSecret != nil || len(account.Spec.SigningKeys) > 0 {
+			return permanent(natsv1alpha1.ReasonInvalidSpec, fmt.Errorf("existingSeedSecret and signingKeys cannot be used with the external signer of the NatsAuthConfig"))
+		}
+		if accountKP, err = signerKeyPair(ctx, ext, accountSignerKey(account, authConfig)); err != nil {
+			return err
+		}
+		storedSeed = nil
+	}
+
+	storedPubKey := publicKeyFromSeed(storedSeed)
+	if accountKP != nil {
+		storedPubKey, _ = accountKP.PublicKey()
+	}
+	claimsHash := jwtpkg.AccountClaimsHash(account.Name, account.Spec.Description, limits, accountInfo(account), sharing, mappings, signingKeys)
+	if storedPubKey == "" && len(storedSeed) > 0 {
+		log.Info("Account JWT secret holds an invalid seed, will issue a new key", "secret", jwtSecretName)
+		storedSeed = nil
+	}
+
+	// Detect artifacts left behind by an interrupted reconcile
+	switch {
+	case storedPubKey != "" && storedPubKey != account.Status.AccountID:
+		// Secret written but status lost (or stale): trust the persisted seed instead of issuing a new key
+		log.Info("Account JWT secret does not match status, resuming from the stored seed",
+			"statusID", account.Status.AccountID, "storedID", storedPubKey, "lastCompletedStep", account.Status.LastCompletedStep)
+	case storedPubKey == "" && account.Status.AccountID != "" && account.Spec.ExistingSeedSecret == nil:
+		// Status recorded but the seed is gone: the old key cannot be recovered
+		log.Info("Account JWT secret is missing, issuing a new account key", "statusID", account.Status.AccountID)
+	case storedPubKey != "" && len(existingSecret.Data["account.jwt"]) > 0 && checkpointComplete(account.Status.LastCompletedStep, natsv1alpha1.ReconcileStepResolverUpdated):
+		// JWT exists and status matches seed - regenerate only if claims or revocations changed
+		revocationsMatch := jwtpkg.RevocationsMatch(string(existingSecret.Data["account.jwt"]), account.Status.RevokedUsers)
+		keyCurrent := operatorKeyCurrent(account, authConfig)
+		storedJWT := string(existingSecret.Data["account.jwt"])
+		// Accounts issued before fingerprints were recorded adopt the stored JWT
+		jwtCurrent := account.Status.JWTFingerprint == "" || jwtpkg.Fingerprint(storedJWT) == account.Status.JWTFingerprint
+		if revocationsMatch && keyCurrent && jwtCurrent && account.Status.ClaimsHash == claimsHash {
+			log.Info("Account JWT already exists and matches status, skipping regeneration", "accountID", account.Status.AccountID)
+			if account.Status.JWTFingerprint == "" {
+				account.Status.JWTFingerprint, account.Status.IssuedAt, account.Status.ExpiresAt = issuedJWTStatus(storedJWT)
 			}
-			// If we get here, the status doesn't match the seed - need to regenerate
-			log.Info("Account ID in status doesn't match seed, will regenerate", "statusID", account.Status.AccountID)
+			account.Status.OperatorKeyHash = authConfig.Status.OperatorKeyHash
+			account.Status.OperatorClaimsHash = authConfig.Status.OperatorClaimsHash
+			account.Status.SigningKeys = signingKeyStatus(signingKeys)
+			return syncPropagatedMetadata(ctx, r.Client, existingSecret, account, accountPropagation(account, settings), accountOwners(account, authConfig))
 		}
-	} else if !errors.IsNotFound(err) {
-		return fmt.Errorf("failed to check JWT secret: %w", err)
+		switch {
+		case !keyCurrent:
+			log.Info("Operator keys or claims changed, will re-sign account JWT", "accountID", account.Status.AccountID)
+		case !revocationsMatch:
+			log.Info("Account revocations changed, will re-sign account JWT", "accountID", account.Status.AccountID)
+		case !jwtCurrent:
+			log.Info("Account JWT secret drifted from the issued JWT, will re-sign account JWT", "accountID", account.Status.AccountID,
+				"fingerprint", jwtpkg.Fingerprint(storedJWT), "issuedFingerprint", account.Status.JWTFingerprint)
+		default:
+			log.Info("Account claims changed, will re-sign account JWT", "accountID", account.Status.AccountID)
+		}
+	case storedPubKey != "":
+		log.Info("Resuming interrupted account reconcile", "lastCompletedStep", account.Status.LastCompletedStep)
 	}
 
-	// Get or create account seed
-	accountSeed, err := r.getOrCreateAccountSeed(ctx, account)
-	if err != nil {
-		return fmt.Errorf("failed to get account seed: %w", err)
+	// Prefer an explicitly referenced seed, then the stored one; a new seed is persisted before use
+	accountSeed := storedSeed
+	if accountKP == nil && (accountSeed == nil || account.Spec.ExistingSeedSecret != nil) {
+		accountSeed, err = r.getOrCreateAccountSeed(ctx, account)
+		if err != nil {
+			return fmt.Errorf("failed to get account seed: %w", err)
+		}
+	}
+
+	// New signing key seeds are persisted with the account seed; an unchanged account keeps its JWT
+	if accountKP == nil && (!bytes.Equal(accountSeed, storedSeed) || signingKeysGenerated) {
+		seedSecret := secrets.New(account.Namespace, jwtSecretName, map[string][]byte{
+			jwtpkg.AccountSeedKey: accountSeed,
+		})
+		seedSecret.Labels = seedSecretLabels(nkeys.PrefixByteAccount)
+		protectSeedSecret(seedSecret, r.SecretAccess)
+		natslabels.Stamp(seedSecret, accountOwners(account, authConfig))
+		for key, seed := range signingSeeds {
+			seedSecret.Data[key] = seed
+		}
+		if accountJWT := existingSecret.Data["account.jwt"]; bytes.Equal(accountSeed, storedSeed) && len(accountJWT) > 0 {
+			seedSecret.Data["account.jwt"] = accountJWT
+		}
+		propagateMetadata(seedSecret, account, accountPropagation(account, settings))
+		if err := controllerutil.SetControllerReference(account, seedSecret, r.Scheme); err != nil {
+			return err
+		}
+		if err := resolver.ApplySecret(ctx, r.Client, seedSecret); err != nil {
+			return fmt.Errorf("failed to persist account seed: %w", err)
+		}
+	}
+	if err := r.checkpoint(ctx, account, natsv1alpha1.ReconcileStepSeedCreated); err != nil {
+		return err
 	}
 
 	// Create account manager
-	accountMgr, err := jwtpkg.NewAccountManager(accountSeed)
-	if err != nil {
-		return fmt.Errorf("failed to create account manager: %w", err)
+	if accountKP == nil {
+		if accountKP, err = nkeys.FromSeed(accountSeed); err != nil {
+			return fmt.Errorf("failed to create account manager: %w", err)
+		}
 	}
+	accountMgr := jwtpkg.NewAccountManagerFromKeyPair(accountKP)
 
 	// Get account public key
 	accountPubKey, err := accountMgr.GetPublicKey()
@@ -196,19 +363,49 @@ func (r *NatsAccountReconciler) reconcileAccount(ctx context.Context, account *n
 	accountClaims, err := accountMgr.CreateAccountClaims(
 		account.Name,
 		account.Spec.Description,
-		account.Spec.Limits,
+		limits,
 	)
 	if err != nil {
 		return fmt.Errorf("failed to create account claims: %w", err)
 	}
 
+	jwtpkg.SetAccountInfo(accountClaims, accountInfo(account))
+
+	// Imports of restricted exports carry an activation token signed by the exporting account
+	tokens, err := r.signActivations(ctx, account, authConfig, accountPubKey, activators)
+	if err != nil {
+		return err
+	}
+	jwtpkg.SetAccountSharing(accountClaims, sharing, tokens)
+	if mappings != nil {
+		accountClaims.Mappings = mappings
+	}
+	jwtpkg.SetSigningKeys(accountClaims, signingKeys)
+
+	// Let the claim hooks mutate or reject the claims before signing
+	chain, err := claimHooks(r.ClaimHooks, authConfig)
+	if err != nil {
+		return err
+	}
+	if err := chain.MutateAccountClaims(ctx, hookRequest(hooks.KindAccount, account, authConfig), accountClaims); err != nil {
+		return fmt.Errorf("failed to apply claim hooks: %w", err)
+	}
+
+	// Carry user revocations into the account JWT; applied after the hooks so they cannot be dropped
+	jwtpkg.ApplyRevocations(accountClaims, account.Status.RevokedUsers)
+	log.V(debugLevel).Info("Built account claims", "step", "build-claims", "accountID", accountPubKey, "revokedUsers", len(account.Status.RevokedUsers),
+		"exports", len(sharing.Exports), "imports", len(sharing.Imports))
+	if err := r.checkpoint(ctx, account, natsv1alpha1.ReconcileStepClaimsBuilt); err != nil {
+		return err
+	}
+
 	// Get operator keypair to sign the account JWT
-	operatorSeed, err := r.getOperatorSeed(ctx, authConfig)
+	operatorKP, err := r.getOperatorKeyPair(ctx, authConfig, ext)
 	if err != nil {
-		return fmt.Errorf("failed to get operator seed: %w", err)
+		return fmt.Errorf("failed to get operator key: %w", err)
 	}
 
-	operatorMgr, err := jwtpkg.NewOperatorManager(operatorSeed, "")
+	operatorMgr, err := jwtpkg.NewOperatorManagerFromKeyPair(operatorKP, "", jwtpkg.OperatorOptions{})
 	if err != nil {
 		return fmt.Errorf("failed to create operator manager: %w", err)
 	}
@@ -218,169 +415,229 @@ func (r *NatsAccountReconciler) reconcileAccount(ctx context.Context, account *n
 	if err != nil {
 		return fmt.Errorf("failed to sign account JWT: %w", err)
 	}
+	if err := r.checkpoint(ctx, account, natsv1alpha1.ReconcileStepSigned); err != nil {
+		return err
+	}
 
-	// Store account JWT in a secret
-	jwtSecret := &corev1.Secret{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      jwtSecretName,
-			Namespace: account.Namespace,
-		},
-		Data: map[string][]byte{
-			"account.jwt":  []byte(accountJWT),
-			"account.seed": accountSeed,
-		},
+	// Store account JWT in a secret, with the seeds unless they are held by the external signer
+	jwtSecret := secrets.New(account.Namespace, jwtSecretName, map[string][]byte{
+		"account.jwt": []byte(accountJWT),
+	})
+	// The NatsAuthConfig controller lists its account JWT Secrets by these labels
+	jwtSecret.Labels = map[string]string{
+		authConfigNameLabel:      authConfig.Name,
+		authConfigNamespaceLabel: authConfig.Namespace,
 	}
+	natslabels.Stamp(jwtSecret, accountOwners(account, authConfig))
+	if accountSeed != nil {
+		jwtSecret.Labels[jwtpkg.SeedTypeLabel] = jwtpkg.SeedType(nkeys.PrefixByteAccount)
+		protectSeedSecret(jwtSecret, r.SecretAccess)
+		jwtSecret.Data[jwtpkg.AccountSeedKey] = accountSeed
+	}
+	for key, seed := range signingSeeds {
+		jwtSecret.Data[key] = seed
+	}
+
+	propagateMetadata(jwtSecret, account, accountPropagation(account, settings))
 
 	if err := controllerutil.SetControllerReference(account, jwtSecret, r.Scheme); err != nil {
 		return err
 	}
 
-	// Create or update the secret
-	if !jwtSecretExists {
-		if err := r.Create(ctx, jwtSecret); err != nil {
-			return fmt.Errorf("failed to create JWT secret: %w", err)
-		}
-		log.Info("Created new account JWT secret", "secret", jwtSecretName)
-	} else {
-		existingSecret.Data = jwtSecret.Data
-		if err := r.Update(ctx, existingSecret); err != nil {
-			return fmt.Errorf("failed to update JWT secret: %w", err)
-		}
-		log.Info("Updated account JWT secret", "secret", jwtSecretName)
+	if err := resolver.ApplySecret(ctx, r.Client, jwtSecret); err != nil {
+		return fmt.Errorf("failed to apply JWT secret: %w", err)
 	}
+	log.Info("Applied account JWT secret", "step", "apply-secret", "secret", jwtSecretName, "accountID", accountPubKey)
+
+	action := audit.ActionIssued
+	if accountPubKey == account.Status.AccountID {
+		action = audit.ActionReissued
+	}
+	recordAudit(ctx, r.Audit, "NatsAccount", account, audit.Event{
+		Action:     action,
+		Credential: audit.CredentialAccountJWT,
+		PublicKey:  accountPubKey,
+		Issuer:     accountClaims.Issuer,
+		ExpiresAt:  auditExpiry(accountClaims.Expires),
+		ClaimsHash: claimsHash,
+		Secret:     secretKey(jwtSecret),
+	})
 
 	// Update status first (so the NatsAuthConfig controller can find it)
 	account.Status.AccountID = accountPubKey
 	account.Status.PublicKey = accountPubKey
+	account.Status.ClaimsHash = claimsHash
+	account.Status.JWTFingerprint, account.Status.IssuedAt, account.Status.ExpiresAt = issuedJWTStatus(accountJWT)
+	account.Status.OperatorKeyHash = authConfig.Status.OperatorKeyHash
+	account.Status.OperatorClaimsHash = authConfig.Status.OperatorClaimsHash
+	account.Status.SigningKeys = signingKeyStatus(signingKeys)
 	account.Status.JWTSecretRef = natsv1alpha1.SecretRef{
 		Name:      jwtSecretName,
 		Namespace: account.Namespace,
 	}
-
-	// Trigger NatsAuthConfig reconciliation to update resolver_preload
-	// Only do this once when we first create/update the JWT
-	if err := r.triggerAuthConfigReconcile(ctx, authConfig); err != nil {
-		return fmt.Errorf("failed to trigger auth config reconciliation: %w", err)
+	if err := r.checkpoint(ctx, account, natsv1alpha1.ReconcileStepSecretWritten); err != nil {
+		return err
 	}
 
-	return nil
+	// The NatsAuthConfig controller watches accounts and picks up the new JWT from the status
+	// written above to refresh resolver_preload
+	return r.checkpoint(ctx, account, natsv1alpha1.ReconcileStepResolverUpdated)
 }
 
-func (r *NatsAccountReconciler) getOrCreateAccountSeed(ctx context.Context, account *natsv1alpha1.NatsAccount) ([]byte, error) {
-	// Check if existing seed is specified
-	if account.Spec.ExistingSeedSecret != nil {
-		secret := &corev1.Secret{}
-		key := client.ObjectKey{
-			Namespace: account.Spec.ExistingSeedSecret.Namespace,
-			Name:      account.Spec.ExistingSeedSecret.Name,
-		}
-		if err := r.Get(ctx, key, secret); err != nil {
-			return nil, fmt.Errorf("failed to get account seed secret: %w", err)
-		}
+// operatorKeyCurrent reports whether the account JWT was signed under the current operator keys
+// and claims. Accounts signed before a hash was recorded are taken as current and adopt it.
+func operatorKeyCurrent(account *natsv1alpha1.NatsAccount, authConfig *natsv1alpha1.NatsAuthConfig) bool {
+	return hashCurrent(account.Status.OperatorKeyHash, authConfig.Status.OperatorKeyHash) &&
+		hashCurrent(account.Status.OperatorClaimsHash, authConfig.Status.OperatorClaimsHash)
+}
 
-		seed, ok := secret.Data["account.seed"]
-		if !ok {
-			return nil, fmt.Errorf("account seed not found in secret")
-		}
+// hashCurrent reports whether a recorded hash matches the wanted one; an unset hash matches any
+func hashCurrent(recorded, want string) bool {
+	return recorded == "" || want == "" || recorded == want
+}
 
-		return seed, nil
+// findAccountsForAuthConfig re-signs the accounts of a NatsAuthConfig whose operator keys or claims changed
+func (r *NatsAccountReconciler) findAccountsForAuthConfig(ctx context.Context, obj client.Object) []reconcile.Request {
+	accountList := &natsv1alpha1.NatsAccountList{}
+	if err := r.List(ctx, accountList, client.MatchingFields{authConfigIndex: client.ObjectKeyFromObject(obj).String()}); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to list NatsAccounts for NatsAuthConfig")
+		return nil
 	}
 
-	// Create new account and store the seed
-	accountMgr, err := jwtpkg.NewAccountManager(nil)
-	if err != nil {
-		return nil, err
+	requests := make([]reconcile.Request, 0, len(accountList.Items))
+	for _, account := range accountList.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&account)})
 	}
+	return requests
+}
 
-	seed, err := accountMgr.GetSeed()
-	if err != nil {
-		return nil, err
+// operatorKeyChangedPredicate passes NatsAuthConfig updates that change status.operatorKeyHash,
+// status.operatorClaimsHash or status.mode
+var operatorKeyChangedPredicate = predicate.Funcs{
+	CreateFunc:  func(event.CreateEvent) bool { return false },
+	DeleteFunc:  func(event.DeleteEvent) bool { return false },
+	GenericFunc: func(event.GenericEvent) bool { return false },
+	UpdateFunc: func(e event.UpdateEvent) bool {
+		oldConfig, okOld := e.ObjectOld.(*natsv1alpha1.NatsAuthConfig)
+		newConfig, okNew := e.ObjectNew.(*natsv1alpha1.NatsAuthConfig)
+		return okOld && okNew && (oldConfig.Status.OperatorKeyHash != newConfig.Status.OperatorKeyHash ||
+			oldConfig.Status.OperatorClaimsHash != newConfig.Status.OperatorClaimsHash ||
+			oldConfig.Status.Mode != newConfig.Status.Mode)
+	},
+}
+
+// checkpoint records a completed step in status so an interrupted reconcile is detected and resumed
+func (r *NatsAccountReconciler) checkpoint(ctx context.Context, account *natsv1alpha1.NatsAccount, step natsv1alpha1.ReconcileStep) error {
+	account.Status.LastCompletedStep = step
+	if err := patchStatus(ctx, r.Client, account); err != nil {
+		return fmt.Errorf("failed to record %s checkpoint: %w", step, err)
+	}
+	log.FromContext(ctx).V(debugLevel).Info("Checkpoint reached", "step", step)
+	return nil
+}
+
+func (r *NatsAccountReconciler) getOrCreateAccountSeed(ctx context.Context, account *natsv1alpha1.NatsAccount) ([]byte, error) {
+	// Check if existing seed is specified
+	if ref := account.Spec.ExistingSeedSecret; ref != nil {
+		return readSeedSecret(ctx, r.Client, ref, account.Namespace, nkeys.PrefixByteAccount)
 	}
 
-	return seed, nil
+	// Create new account and store the seed
+	return random.Seed(random.OrDefault(r.Random), nkeys.PrefixByteAccount)
 }
 
 func (r *NatsAccountReconciler) getAuthConfig(ctx context.Context, account *natsv1alpha1.NatsAccount) (*natsv1alpha1.NatsAuthConfig, error) {
 	authConfig := &natsv1alpha1.NatsAuthConfig{}
-	namespace := account.Spec.AuthConfigRef.Namespace
-	if namespace == "" {
-		namespace = account.Namespace
+	if err := r.Get(ctx, accountAuthConfigKey(account), authConfig); err != nil {
+		return nil, err
 	}
 
-	key := client.ObjectKey{
-		Namespace: namespace,
-		Name:      account.Spec.AuthConfigRef.Name,
-	}
+	return authConfig, nil
+}
 
-	if err := r.Get(ctx, key, authConfig); err != nil {
+// getOperatorKeyPair returns the key account JWTs are signed with: the operator key on the
+// external signer when there is one, otherwise the operator or operator signing key seed
+func (r *NatsAccountReconciler) getOperatorKeyPair(ctx context.Context, authConfig *natsv1alpha1.NatsAuthConfig, ext *signer.HTTPSigner) (nkeys.KeyPair, error) {
+	if ext != nil {
+		return signerKeyPair(ctx, ext, operatorSignerKey(authConfig))
+	}
+	seed, err := r.getOperatorSeed(ctx, authConfig)
+	if err != nil {
 		return nil, err
 	}
-
-	return authConfig, nil
+	return nkeys.FromSeed(seed)
 }
 
 func (r *NatsAccountReconciler) getOperatorSeed(ctx context.Context, authConfig *natsv1alpha1.NatsAuthConfig) ([]byte, error) {
 	var secretName, secretNamespace, seedKey string
 
-	if authConfig.Spec.JWT.OperatorSeedSecret != nil {
+	if authConfig.Spec.JWT.StrictSigningKeyUsage {
+		// Accounts must be signed by the operator signing key, not the identity key
+		secretName = operatorSigningKeySecretName(authConfig)
+		secretNamespace = authConfig.Namespace
+		seedKey = operatorSigningSeedKey
+	} else if authConfig.Spec.JWT.OperatorSeedSecret != nil {
 		secretName = authConfig.Spec.JWT.OperatorSeedSecret.Name
 		secretNamespace = authConfig.Spec.JWT.OperatorSeedSecret.Namespace
 		seedKey = authConfig.Spec.JWT.OperatorSeedSecret.Key
-		if seedKey == "" {
-			seedKey = "operator.seed"
-		}
 	} else {
-		secretName = fmt.Sprintf("%s-operator-seed", authConfig.Name)
+		secretName = operatorSeedSecretName(authConfig)
 		secretNamespace = authConfig.Namespace
-		seedKey = "operator.seed"
+		seedKey = jwtpkg.OperatorSeedKey
 	}
 
-	secret := &corev1.Secret{}
-	key := client.ObjectKey{
-		Namespace: secretNamespace,
-		Name:      secretName,
-	}
+	return getSeed(ctx, r.Client, client.ObjectKey{Namespace: secretNamespace, Name: secretName}, nkeys.PrefixByteOperator, seedKey)
+}
 
-	if err := r.Get(ctx, key, secret); err != nil {
-		return nil, fmt.Errorf("failed to get operator seed secret: %w", err)
+// updateUsers records the NatsUsers referencing the account in its status
+func (r *NatsAccountReconciler) updateUsers(ctx context.Context, account *natsv1alpha1.NatsAccount) error {
+	userList := &natsv1alpha1.NatsUserList{}
+	if err := r.List(ctx, userList, client.MatchingFields{userAccountIndex: client.ObjectKeyFromObject(account).String()}); err != nil {
+		return fmt.Errorf("failed to list users: %w", err)
 	}
 
-	seed, ok := secret.Data[seedKey]
-	if !ok {
-		return nil, fmt.Errorf("operator seed key %q not found in secret", seedKey)
+	users := make([]natsv1alpha1.AccountUser, 0, len(userList.Items))
+	for _, user := range userList.Items {
+		users = append(users, natsv1alpha1.AccountUser{
+			Name:      user.Name,
+			Namespace: user.Namespace,
+			PublicKey: user.Status.PublicKey,
+		})
 	}
+	sort.Slice(users, func(i, j int) bool {
+		if users[i].Namespace != users[j].Namespace {
+			return users[i].Namespace < users[j].Namespace
+		}
+		return users[i].Name < users[j].Name
+	})
 
-	return seed, nil
+	account.Status.Users = users
+	account.Status.UserCount = int32(len(users))
+	return nil
 }
 
-// triggerAuthConfigReconcile forces a reconciliation of the NatsAuthConfig
-// This is needed when accounts are created/updated/deleted to refresh resolver_preload
-func (r *NatsAccountReconciler) triggerAuthConfigReconcile(ctx context.Context, authConfig *natsv1alpha1.NatsAuthConfig) error {
-	log := log.FromContext(ctx)
-
-	// Update a dummy annotation to trigger reconciliation
-	if authConfig.Annotations == nil {
-		authConfig.Annotations = make(map[string]string)
+// accountInfo returns the ownership information carried into the account JWT
+func accountInfo(account *natsv1alpha1.NatsAccount) jwtpkg.AccountInfo {
+	return jwtpkg.AccountInfo{
+		InfoURL:  account.Spec.InfoURL,
+		Contact:  account.Spec.Contact,
+		Metadata: account.Spec.Metadata,
 	}
-	authConfig.Annotations["nats.jradikk/last-account-update"] = time.Now().Format(time.RFC3339)
+}
 
-	if err := r.Update(ctx, authConfig); err != nil {
-		return fmt.Errorf("failed to trigger auth config reconciliation: %w", err)
+// findAccountForUser maps a NatsUser to the NatsAccount it references
+func (r *NatsAccountReconciler) findAccountForUser(ctx context.Context, obj client.Object) []reconcile.Request {
+	user, ok := obj.(*natsv1alpha1.NatsUser)
+	if !ok || !hasAccount(user) {
+		return nil
 	}
-
-	log.Info("Triggered NatsAuthConfig reconciliation", "authConfig", authConfig.Name)
-	return nil
+	return []reconcile.Request{{NamespacedName: accountKey(user)}}
 }
 
 func (r *NatsAccountReconciler) handleDeletion(ctx context.Context, account *natsv1alpha1.NatsAccount) (ctrl.Result, error) {
 	if controllerutil.ContainsFinalizer(account, natsAccountFinalizer) {
-		// Trigger NatsAuthConfig reconciliation to remove this account from resolver_preload
-		authConfig, err := r.getAuthConfig(ctx, account)
-		if err == nil {
-			// Trigger reconciliation to update resolver_preload without this account
-			_ = r.triggerAuthConfigReconcile(ctx, authConfig)
-		}
-
+		// The NatsAuthConfig controller sees the deletion through its account watch and
+		// drops the account from resolver_preload
 		controllerutil.RemoveFinalizer(account, natsAccountFinalizer)
 		if err := r.Update(ctx, account); err != nil {
 			return ctrl.Result{}, err
@@ -402,12 +659,32 @@ func (r *NatsAccountReconciler) updateCondition(account *natsv1alpha1.NatsAccoun
 	if !found {
 		account.Status.Conditions = append(account.Status.Conditions, condition)
 	}
+	if condition.Type == "Ready" {
+		account.Status.Phase = readyPhase(condition)
+		account.Status.Reason = natsv1alpha1.ReasonCode(condition.Reason)
+		account.Status.Message = condition.Message
+	}
 }
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *NatsAccountReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	return ctrl.NewControllerManagedBy(mgr).
-		For(&natsv1alpha1.NatsAccount{}).
-		Owns(&corev1.Secret{}).
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &natsv1alpha1.NatsUser{}, userAccountIndex, indexUserByAccount); err != nil {
+		return err
+	}
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &natsv1alpha1.NatsAccount{}, accountImportIndex, indexAccountByImport); err != nil {
+		return err
+	}
+
+	b := ctrl.NewControllerManagedBy(mgr).
+		For(&natsv1alpha1.NatsAccount{})
+	if r.SecretAccess.WatchesSecrets() {
+		b = b.Owns(&corev1.Secret{})
+	}
+	return b.
+		Watches(&natsv1alpha1.NatsUser{}, handler.EnqueueRequestsFromMapFunc(r.findAccountForUser)).
+		Watches(&natsv1alpha1.NatsAuthConfig{}, handler.EnqueueRequestsFromMapFunc(r.findAccountsForAuthConfig),
+			builder.WithPredicates(operatorKeyChangedPredicate)).
+		Watches(&natsv1alpha1.NatsAccount{}, handler.EnqueueRequestsFromMapFunc(r.findAccountsImporting),
+			builder.WithPredicates(exportsChangedPredicate)).
 		Complete(r)
 }
@@ -0,0 +1,111 @@
+package controller
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/nats-io/jwt/v2"
+	"github.com/nats-io/nkeys"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	natsv1alpha1 "github.com/jradikk/nats-auth-operator/api/v1alpha1"
+)
+
+func TestAccountReconcileResumesFromStoredSeed(t *testing.T) {
+	authConfig := testAuthConfig(natsv1alpha1.AuthModeJWT)
+	c, scheme := newTestClient(authConfig)
+	authConfigs, accounts, users := testReconcilers(c, scheme)
+	mustReconcile(t, authConfigs, authConfig)
+	ctx := context.Background()
+
+	// Interrupted right after the seed was persisted: the JWT is issued for the stored seed
+	kp, _ := nkeys.CreateAccount()
+	seed, _ := kp.Seed()
+	pub, _ := kp.PublicKey()
+	account := testAccount("orders")
+	account.Status.LastCompletedStep = natsv1alpha1.ReconcileStepSeedCreated
+	secretName, err := accountJWTSecretName(account, &natsv1alpha1.NatsOperatorSettingsSpec{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	user := testUser("orders-app", natsv1alpha1.UserAuthTypeJWT, "orders")
+	if err := c.Create(ctx, &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: account.Namespace, Name: secretName},
+		Data:       map[string][]byte{"account.seed": seed},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Create(ctx, account); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Create(ctx, user); err != nil {
+		t.Fatal(err)
+	}
+
+	mustReconcile(t, accounts, account)
+	mustGet(t, c, account)
+	if account.Status.AccountID != pub || account.Status.LastCompletedStep != natsv1alpha1.ReconcileStepResolverUpdated {
+		t.Fatalf("Reconcile() status = %s at %s, want %s at %s", account.Status.AccountID, account.Status.LastCompletedStep, pub, natsv1alpha1.ReconcileStepResolverUpdated)
+	}
+	secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: account.Namespace, Name: secretName}}
+	mustGet(t, c, secret)
+	if claims, err := jwt.DecodeAccountClaims(string(secret.Data["account.jwt"])); err != nil || claims.Subject != pub {
+		t.Errorf("Reconcile() wrote account claims %+v, %v, want ones of the stored seed %s", claims, err, pub)
+	}
+
+	// Status lost after the Secret was written: the stored key is kept rather than replaced,
+	// so users issued by the account stay valid
+	mustReconcile(t, users, user)
+	mustGet(t, c, user)
+	userKey := user.Status.PublicKey
+	account.Status = natsv1alpha1.NatsAccountStatus{LastCompletedStep: natsv1alpha1.ReconcileStepSigned}
+	if err := c.Status().Update(ctx, account); err != nil {
+		t.Fatal(err)
+	}
+	user.Status = natsv1alpha1.NatsUserStatus{}
+	if err := c.Status().Update(ctx, user); err != nil {
+		t.Fatal(err)
+	}
+
+	mustReconcile(t, accounts, account)
+	mustReconcile(t, users, user)
+	mustGet(t, c, account)
+	mustGet(t, c, user)
+	if account.Status.AccountID != pub || account.Status.LastCompletedStep != natsv1alpha1.ReconcileStepResolverUpdated {
+		t.Errorf("Reconcile() after a lost status = %s at %s, want %s at %s", account.Status.AccountID, account.Status.LastCompletedStep, pub, natsv1alpha1.ReconcileStepResolverUpdated)
+	}
+	if userKey == "" || user.Status.PublicKey != userKey {
+		t.Errorf("Reconcile() after a lost status issued user key %q, want the stored %q", user.Status.PublicKey, userKey)
+	}
+}
+
+func TestAccountStatusListsUsers(t *testing.T) {
+	account := testAccount("orders")
+	var objs []client.Object
+	for _, key := range []client.ObjectKey{{Namespace: "billing", Name: "invoices"}, {Namespace: "apps", Name: "orders-b"}, {Namespace: "apps", Name: "orders-a"}} {
+		user := testUser(key.Name, natsv1alpha1.UserAuthTypeJWT, "orders")
+		user.Namespace = key.Namespace
+		user.Spec.AccountRef.Namespace = "apps"
+		user.Status.PublicKey = "U" + strings.ToUpper(key.Name)
+		objs = append(objs, user)
+	}
+	objs = append(objs, testUser("other", natsv1alpha1.UserAuthTypeJWT, "billing"), account)
+	c, scheme := newTestClient(objs...)
+	_, accounts, _ := testReconcilers(c, scheme)
+
+	if err := accounts.updateUsers(context.Background(), account); err != nil {
+		t.Fatal(err)
+	}
+	want := []natsv1alpha1.AccountUser{
+		{Namespace: "apps", Name: "orders-a", PublicKey: "UORDERS-A"},
+		{Namespace: "apps", Name: "orders-b", PublicKey: "UORDERS-B"},
+		{Namespace: "billing", Name: "invoices", PublicKey: "UINVOICES"},
+	}
+	if !reflect.DeepEqual(account.Status.Users, want) || account.Status.UserCount != int32(len(want)) {
+		t.Errorf("updateUsers() status = %+v, %d users, want %+v", account.Status.Users, account.Status.UserCount, want)
+	}
+}
@@ -0,0 +1,218 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	natsv1alpha1 "github.com/jradikk/nats-auth-operator/api/v1alpha1"
+	"github.com/jradikk/nats-auth-operator/internal/backup"
+	"github.com/jradikk/nats-auth-operator/internal/resolver"
+	"github.com/jradikk/nats-auth-operator/internal/secrets"
+	natslabels "github.com/jradikk/nats-auth-operator/pkg/labels"
+)
+
+// archiveHashAnnotation records the content hash of the archive in a NatsAuthBackup Secret
+const archiveHashAnnotation = "nats.jradikk/archive-hash"
+
+// NatsAuthBackupReconciler reconciles a NatsAuthBackup object
+type NatsAuthBackupReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+
+	// Recorder emits events when a new archive is written; optional
+	Recorder record.EventRecorder
+
+	// SecretAccess restricted stops listing and watching Secrets; cluster when empty
+	SecretAccess SecretAccess
+}
+
+// +kubebuilder:rbac:groups=nats.jradikk,resources=natsauthbackups,verbs=get;list;watch
+// +kubebuilder:rbac:groups=nats.jradikk,resources=natsauthbackups/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=nats.jradikk,resources=natsauthconfigs;natsaccounts;natsusers,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch;create;update;patch
+// +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+
+func (r *NatsAuthBackupReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	nab := &natsv1alpha1.NatsAuthBackup{}
+	if err := r.Get(ctx, req.NamespacedName, nab); err != nil {
+		if errors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+
+	ctx, log := reconcileLogger(ctx, "NatsAuthBackup", nab)
+	ctx = withStatusBase(ctx, nab)
+
+	// A paused resource is left as it is until spec.paused is unset
+	if paused, err := reconcilePaused(ctx, r.Client, nab, nab.Spec.Paused, nab.Status.Conditions, func(c metav1.Condition) {
+		r.updateCondition(nab, c)
+	}); paused || err != nil {
+		return ctrl.Result{}, err
+	}
+
+	now := metav1.Now()
+	nab.Status.LastReconciled = &now
+
+	err := r.reconcileBackup(ctx, nab)
+	nab.Status.ObservedGeneration = nab.Generation
+	if err != nil {
+		reason, isPermanent := classifyError(err)
+		log.Error(err, "Failed to back up auth hierarchy", "reason", reason, "permanent", isPermanent)
+		r.updateCondition(nab, metav1.Condition{
+			Type:    "Ready",
+			Status:  metav1.ConditionFalse,
+			Reason:  string(reason),
+			Message: err.Error(),
+		})
+		if err := patchStatus(ctx, r.Client, nab); err != nil {
+			return ctrl.Result{}, err
+		}
+		return failureResult(err, isPermanent)
+	}
+
+	r.updateCondition(nab, metav1.Condition{
+		Type:    "Ready",
+		Status:  metav1.ConditionTrue,
+		Reason:  string(natsv1alpha1.ReasonReconcileSuccess),
+		Message: fmt.Sprintf("Archive holds %d accounts, %d users and %d secrets", nab.Status.Accounts, nab.Status.Users, nab.Status.Secrets),
+	})
+	if err := patchStatus(ctx, r.Client, nab); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	return ResyncConfig{}.Result(nab.Spec.Interval), nil
+}
+
+// reconcileBackup snapshots the hierarchy and writes a newly sealed archive when its content changed
+func (r *NatsAuthBackupReconciler) reconcileBackup(ctx context.Context, nab *natsv1alpha1.NatsAuthBackup) error {
+	log := log.FromContext(ctx)
+
+	for _, recipient := range nab.Spec.Recipients {
+		if err := backup.ValidateRecipient(recipient); err != nil {
+			return permanent(natsv1alpha1.ReasonInvalidSpec, err)
+		}
+	}
+
+	archive, err := backup.Collect(ctx, r.Client, backupAuthConfigKey(nab))
+	if err != nil {
+		return fmt.Errorf("failed to collect auth hierarchy: %w", err)
+	}
+	hash, err := backup.ContentHash(archive)
+	if err != nil {
+		return fmt.Errorf("failed to hash archive: %w", err)
+	}
+
+	secretName := nab.Spec.SecretName
+	if secretName == "" {
+		secretName = nab.Name + "-backup"
+	}
+	existing, exists, err := secrets.Get(ctx, r.Client, client.ObjectKey{Namespace: nab.Namespace, Name: secretName})
+	if err != nil {
+		return err
+	}
+	// A spec change, such as a new recipient, reseals the archive even when its content is unchanged
+	if exists && existing.Annotations[archiveHashAnnotation] == hash && nab.Status.ArchiveHash == hash &&
+		nab.Status.ObservedGeneration == nab.Generation {
+		log.V(debugLevel).Info("Auth hierarchy unchanged since the last archive", "hash", hash)
+		return nil
+	}
+
+	data, err := backup.Marshal(archive)
+	if err != nil {
+		return fmt.Errorf("failed to encode archive: %w", err)
+	}
+	sealed, err := backup.Seal(data, nab.Spec.Recipients)
+	if err != nil {
+		return fmt.Errorf("failed to seal archive: %w", err)
+	}
+
+	secret := secrets.New(nab.Namespace, secretName, map[string][]byte{backup.ArchiveKey: sealed})
+	secret.Annotations = map[string]string{archiveHashAnnotation: hash}
+	natslabels.Stamp(secret, natslabels.Owners{})
+	if err := controllerutil.SetControllerReference(nab, secret, r.Scheme); err != nil {
+		return err
+	}
+	if err := resolver.ApplySecret(ctx, r.Client, secret); err != nil {
+		return fmt.Errorf("failed to write backup secret: %w", err)
+	}
+
+	backupTime := metav1.NewTime(archive.CreatedAt.Time)
+	nab.Status.SecretRef = natsv1alpha1.SecretRef{Name: secretName, Namespace: nab.Namespace}
+	nab.Status.ArchiveHash = hash
+	nab.Status.LastBackupTime = &backupTime
+	nab.Status.Accounts = len(archive.Accounts)
+	nab.Status.Users = len(archive.Users)
+	nab.Status.Secrets = len(archive.Secrets)
+
+	log.Info("Wrote sealed archive", "secret", secretName, "hash", hash, "accounts", len(archive.Accounts), "users", len(archive.Users))
+	if r.Recorder != nil {
+		r.Recorder.Eventf(nab, corev1.EventTypeNormal, "BackupWritten", "Sealed archive of %d accounts and %d users written to Secret %s", len(archive.Accounts), len(archive.Users), secretName)
+	}
+	return nil
+}
+
+// backupAuthConfigKey returns the key of the NatsAuthConfig referenced by the backup
+func backupAuthConfigKey(nab *natsv1alpha1.NatsAuthBackup) client.ObjectKey {
+	namespace := nab.Spec.AuthConfigRef.Namespace
+	if namespace == "" {
+		namespace = nab.Namespace
+	}
+	return client.ObjectKey{Namespace: namespace, Name: nab.Spec.AuthConfigRef.Name}
+}
+
+func (r *NatsAuthBackupReconciler) updateCondition(nab *natsv1alpha1.NatsAuthBackup, condition metav1.Condition) {
+	condition.LastTransitionTime = metav1.Now()
+	found := false
+	for i, c := range nab.Status.Conditions {
+		if c.Type == condition.Type {
+			nab.Status.Conditions[i] = condition
+			found = true
+			break
+		}
+	}
+	if !found {
+		nab.Status.Conditions = append(nab.Status.Conditions, condition)
+	}
+	if condition.Type == "Ready" {
+		nab.Status.Phase = readyPhase(condition)
+		nab.Status.Reason = natsv1alpha1.ReasonCode(condition.Reason)
+		nab.Status.Message = condition.Message
+	}
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *NatsAuthBackupReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	b := ctrl.NewControllerManagedBy(mgr).
+		For(&natsv1alpha1.NatsAuthBackup{})
+	if r.SecretAccess.WatchesSecrets() {
+		b = b.Owns(&corev1.Secret{})
+	}
+	return b.Complete(r)
+}
@@ -18,43 +18,152 @@ package controller
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"slices"
+	"sort"
+	"strings"
 	"time"
 
+	"github.com/nats-io/nkeys"
+	"github.com/spf13/afero"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	natsv1alpha1 "github.com/jradikk/nats-auth-operator/api/v1alpha1"
 	"github.com/jradikk/nats-auth-operator/internal/authconf"
 	jwtpkg "github.com/jradikk/nats-auth-operator/internal/jwt"
+	"github.com/jradikk/nats-auth-operator/internal/random"
 	"github.com/jradikk/nats-auth-operator/internal/resolver"
+	"github.com/jradikk/nats-auth-operator/internal/secrets"
+	"github.com/jradikk/nats-auth-operator/internal/token"
+	natslabels "github.com/jradikk/nats-auth-operator/pkg/labels"
 )
 
 const (
 	natsAuthConfigFinalizer = "nats.jradikk/authconfig-finalizer"
+
+	// resigningAccountsCondition reports account JWTs still signed with replaced operator keys
+	resigningAccountsCondition = "ResigningAccounts"
+
+	// operatorSigningSeedKey holds the operator signing key seed used under strictSigningKeyUsage
+	operatorSigningSeedKey = "operator.signing.seed"
+
+	// accountIndexAnnotation maps "namespace/name" of each account to its public key
+	accountIndexAnnotation = "nats.jradikk/account-index"
+
+	// authConfigNameLabel and authConfigNamespaceLabel track the NatsAuthConfig owning
+	// an infra auth Secret or a ConfigMap, since owner references cannot cross namespaces,
+	// and select the account JWT Secrets of a NatsAuthConfig
+	authConfigNameLabel      = "nats.jradikk/authconfig-name"
+	authConfigNamespaceLabel = "nats.jradikk/authconfig-namespace"
+
+	// managedByLabel marks the server auth config as written by the operator
+	managedByLabel = "app.kubernetes.io/managed-by"
+	managedByValue = "nats-auth-operator"
 )
 
+// authConfigTracking labels the Secrets and ConfigMaps written for a NatsAuthConfig
+var authConfigTracking = secrets.Tracking{NameLabel: authConfigNameLabel, NamespaceLabel: authConfigNamespaceLabel}
+
+// authConfigOwners returns the well-known labels of the objects generated for a NatsAuthConfig
+func authConfigOwners(authConfig *natsv1alpha1.NatsAuthConfig) natslabels.Owners {
+	return natslabels.Owners{AuthConfig: authConfig.Name}
+}
+
+// authConfigSecretLabels selects the objects labelled for the NatsAuthConfig
+func authConfigSecretLabels(authConfig *natsv1alpha1.NatsAuthConfig) client.MatchingLabels {
+	return client.MatchingLabels{
+		authConfigNameLabel:      authConfig.Name,
+		authConfigNamespaceLabel: authConfig.Namespace,
+	}
+}
+
 // NatsAuthConfigReconciler reconciles a NatsAuthConfig object
 type NatsAuthConfigReconciler struct {
 	client.Client
 	Scheme *runtime.Scheme
+
+	// Resync controls periodic requeueing after a successful reconcile
+	Resync ResyncConfig
+
+	// Settings loads the operator-wide defaults
+	Settings *SettingsLoader
+
+	// PasswordPolicy holds the operator-wide defaults for generated passwords
+	PasswordPolicy token.PasswordPolicy
+
+	// Debounce delays reconciles triggered by NatsAccount and NatsUser changes, so a burst
+	// of child updates is written to the server auth config at once. Zero disables batching.
+	Debounce time.Duration
+
+	// Recorder emits the ServerAuthConfigUpdated event used as a reload signal
+	Recorder record.EventRecorder
+
+	// APIReader reads the NATS server pods for the rollout check without caching them.
+	// Without it the cached client is used.
+	APIReader client.Reader
+
+	// LintConfig checks the rendered server auth config for syntax errors before writing it
+	LintConfig bool
+
+	// Random generates keys and passwords; crypto/rand when nil
+	Random random.Generator
+
+	// SecretAccess restricted stops listing and watching Secrets; cluster when empty
+	SecretAccess SecretAccess
+
+	// ShutdownTimeout bounds how long in-flight server auth config writes and account JWT pushes
+	// may run once the operator is stopping; DefaultShutdownTimeout when 0
+	ShutdownTimeout time.Duration
+
+	// ResolverFs holds the full-resolver directories of Directory resolver targets, mounted into
+	// the operator pod; the OS filesystem when nil
+	ResolverFs afero.Fs
+
+	// MountedResolverDirs are the full-resolver directories mounted into the operator pod. A
+	// NatsAuthConfig without spec.jwt.resolver whose jwt.resolverDir is listed maintains the
+	// directory as a Directory target, next to the server auth config Secret.
+	MountedResolverDirs []string
+
+	// flush lets in-flight reconciles complete at shutdown and records the unwritten ones
+	flush *shutdownFlush
 }
 
 // +kubebuilder:rbac:groups=nats.jradikk,resources=natsauthconfigs,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=nats.jradikk,resources=natsauthconfigs/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=nats.jradikk,resources=natsauthconfigs/finalizers,verbs=update
 // +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=nats.jradikk,resources=natsaccounts,verbs=get;list;watch
+// +kubebuilder:rbac:groups=nats.jradikk,resources=natsusers,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
 
 func (r *NatsAuthConfigReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
-	log := log.FromContext(ctx)
+	// Writes that have started complete when the operator stops; reconciles that have not are
+	// left to the next operator instance
+	ctx, finish, err := r.flush.begin(ctx, req.NamespacedName)
+	if err != nil {
+		return ctrl.Result{}, nil
+	}
+	result, err := r.reconcile(ctx, req)
+	finish(err)
+	return result, err
+}
 
+func (r *NatsAuthConfigReconciler) reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	// Fetch the NatsAuthConfig instance
 	authConfig := &natsv1alpha1.NatsAuthConfig{}
 	if err := r.Get(ctx, req.NamespacedName, authConfig); err != nil {
@@ -64,6 +173,8 @@ func (r *NatsAuthConfigReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 		return ctrl.Result{}, err
 	}
 
+	ctx, log := reconcileLogger(ctx, "NatsAuthConfig", authConfig)
+
 	// Handle deletion
 	if !authConfig.ObjectMeta.DeletionTimestamp.IsZero() {
 		return r.handleDeletion(ctx, authConfig)
@@ -76,6 +187,14 @@ func (r *NatsAuthConfigReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 			return ctrl.Result{}, err
 		}
 	}
+	ctx = withStatusBase(ctx, authConfig)
+
+	// A paused resource is left as it is until spec.paused is unset
+	if paused, err := reconcilePaused(ctx, r.Client, authConfig, authConfig.Spec.Paused, authConfig.Status.Conditions, func(c metav1.Condition) {
+		r.updateCondition(authConfig, c)
+	}); paused || err != nil {
+		return ctrl.Result{}, err
+	}
 
 	// Validate the spec
 	if err := r.validateSpec(authConfig); err != nil {
@@ -83,77 +202,185 @@ func (r *NatsAuthConfigReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 		r.updateCondition(authConfig, metav1.Condition{
 			Type:    "Ready",
 			Status:  metav1.ConditionFalse,
-			Reason:  "InvalidSpec",
+			Reason:  string(natsv1alpha1.ReasonInvalidSpec),
 			Message: err.Error(),
 		})
-		if err := r.Status().Update(ctx, authConfig); err != nil {
+		if err := patchStatus(ctx, r.Client, authConfig); err != nil {
 			return ctrl.Result{}, err
 		}
-		return ctrl.Result{RequeueAfter: time.Minute}, nil
+		return ctrl.Result{RequeueAfter: permanentRetryInterval}, nil
 	}
 
-	// Reconcile based on mode
-	var reconcileErr error
-	switch authConfig.Spec.Mode {
-	case natsv1alpha1.AuthModeJWT:
-		reconcileErr = r.reconcileJWTMode(ctx, authConfig)
-	case natsv1alpha1.AuthModeToken:
-		reconcileErr = r.reconcileTokenMode(ctx, authConfig)
-	case natsv1alpha1.AuthModeMixed:
-		reconcileErr = r.reconcileMixedMode(ctx, authConfig)
-	default:
-		reconcileErr = fmt.Errorf("unsupported auth mode: %s", authConfig.Spec.Mode)
+	// Reconcile based on the effective mode, which trails spec.mode while users still
+	// require the previous one
+	reconcileErr := r.reconcileModeMigration(ctx, authConfig)
+	mode := effectiveMode(authConfig)
+	ctx, log = withLogValues(ctx, "mode", mode)
+	if reconcileErr == nil {
+		reconcileErr = r.checkServerAuthConfigOwner(ctx, authConfig)
+	}
+	if reconcileErr == nil {
+		switch mode {
+		case natsv1alpha1.AuthModeJWT:
+			reconcileErr = r.reconcileJWTMode(ctx, authConfig)
+		case natsv1alpha1.AuthModeToken:
+			reconcileErr = r.reconcileTokenMode(ctx, authConfig)
+		case natsv1alpha1.AuthModeMixed:
+			reconcileErr = r.reconcileMixedMode(ctx, authConfig)
+		default:
+			reconcileErr = permanent(natsv1alpha1.ReasonInvalidSpec, fmt.Errorf("unsupported auth mode: %s", mode))
+		}
+	}
+	if reconcileErr == nil && authConfig.Spec.InfraAuth != nil {
+		reconcileErr = r.reconcileInfraAuth(ctx, authConfig)
+	}
+	if reconcileErr == nil {
+		reconcileErr = r.reconcileMonitoringUser(ctx, authConfig)
 	}
 
 	// Update status
+	if err := r.updateChildrenSummary(ctx, authConfig); err != nil && reconcileErr == nil {
+		reconcileErr = err
+	}
+
 	now := metav1.Now()
 	authConfig.Status.LastReconciled = &now
 	authConfig.Status.ObservedGeneration = authConfig.Generation
 
 	if reconcileErr != nil {
-		log.Error(reconcileErr, "Failed to reconcile")
+		reason, isPermanent := classifyError(reconcileErr)
+		log.Error(reconcileErr, "Failed to reconcile", "reason", reason, "permanent", isPermanent)
 		r.updateCondition(authConfig, metav1.Condition{
 			Type:    "Ready",
 			Status:  metav1.ConditionFalse,
-			Reason:  "ReconcileError",
+			Reason:  string(reason),
 			Message: reconcileErr.Error(),
 		})
-		if err := r.Status().Update(ctx, authConfig); err != nil {
+		if err := patchStatus(ctx, r.Client, authConfig); err != nil {
 			return ctrl.Result{}, err
 		}
-		return ctrl.Result{RequeueAfter: time.Minute}, reconcileErr
+		return failureResult(reconcileErr, isPermanent)
+	}
+
+	// Hold back Ready until the NATS servers run the written config
+	loaded, pending, err := r.checkConfigRollout(ctx, authConfig)
+	if err != nil {
+		reason, isPermanent := classifyError(err)
+		log.Error(err, "Failed to check the config rollout", "reason", reason, "permanent", isPermanent)
+		r.updateCondition(authConfig, metav1.Condition{
+			Type:    "Ready",
+			Status:  metav1.ConditionFalse,
+			Reason:  string(reason),
+			Message: err.Error(),
+		})
+		if err := patchStatus(ctx, r.Client, authConfig); err != nil {
+			return ctrl.Result{}, err
+		}
+		return failureResult(err, isPermanent)
+	}
+	if !loaded {
+		log.Info("Waiting for the NATS servers to load the config", "configHash", authConfig.Status.ConfigHash, "pending", pending)
+		authConfig.Status.ResolverReady = false
+		r.updateCondition(authConfig, metav1.Condition{
+			Type:    "Ready",
+			Status:  metav1.ConditionFalse,
+			Reason:  string(natsv1alpha1.ReasonConfigRolloutPending),
+			Message: pending,
+		})
+		if err := patchStatus(ctx, r.Client, authConfig); err != nil {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{RequeueAfter: rolloutCheckInterval}, nil
 	}
 
 	r.updateCondition(authConfig, metav1.Condition{
 		Type:    "Ready",
 		Status:  metav1.ConditionTrue,
-		Reason:  "ReconcileSuccess",
+		Reason:  string(natsv1alpha1.ReasonReconcileSuccess),
 		Message: "NatsAuthConfig reconciled successfully",
 	})
 
-	if err := r.Status().Update(ctx, authConfig); err != nil {
+	if err := patchStatus(ctx, r.Client, authConfig); err != nil {
 		return ctrl.Result{}, err
 	}
 
-	return ctrl.Result{RequeueAfter: 5 * time.Minute}, nil
+	return r.Settings.resync(ctx, r.Resync).Result(authConfig.Spec.ResyncInterval), nil
 }
 
 func (r *NatsAuthConfigReconciler) validateSpec(authConfig *natsv1alpha1.NatsAuthConfig) error {
-	if authConfig.Spec.Mode == natsv1alpha1.AuthModeJWT || authConfig.Spec.Mode == natsv1alpha1.AuthModeMixed {
+	if usesJWT(authConfig.Spec.Mode) || usesJWT(effectiveMode(authConfig)) {
 		if authConfig.Spec.JWT == nil {
-			return fmt.Errorf("JWT configuration is required for JWT or mixed mode")
+			return fmt.Errorf("JWT configuration is required for JWT or mixed mode, and until a migration away from them completes")
 		}
 	}
-	return nil
+	if authConfig.Spec.NoAuthUser != nil && authConfig.Spec.Mode != natsv1alpha1.AuthModeToken {
+		return fmt.Errorf("noAuthUser requires token mode")
+	}
+	if authConfig.Spec.Websocket != nil && authConfig.Spec.Websocket.JWTCookie != "" && authConfig.Spec.Mode == natsv1alpha1.AuthModeToken {
+		return fmt.Errorf("websocket.jwtCookie requires JWT or mixed mode")
+	}
+	if authConfig.Spec.Bootstrap != nil && bootstrapConfigMapName(authConfig) == authConfig.Spec.ServerAuthConfig.Name {
+		return fmt.Errorf("bootstrap.configMapName must differ from serverAuthConfig.name")
+	}
+	if authConfig.Spec.InfraAuth != nil && infraAuthSecretName(authConfig) == authConfig.Spec.ServerAuthConfig.Name {
+		return fmt.Errorf("infraAuth.secretName must differ from serverAuthConfig.name")
+	}
+	if authConfig.Spec.PublicCatalog != nil {
+		name := publicCatalogConfigMapName(authConfig)
+		if (authConfig.Spec.Bootstrap != nil && name == bootstrapConfigMapName(authConfig)) ||
+			(name == authConfig.Spec.ServerAuthConfig.Name && authConfig.Namespace == authConfig.Spec.ServerAuthConfig.Namespace) {
+			return fmt.Errorf("publicCatalog.configMapName must differ from the server auth config and bootstrap ConfigMap")
+		}
+	}
+	if jwtConfig := authConfig.Spec.JWT; jwtConfig != nil && jwtConfig.Signer != nil {
+		if jwtConfig.OperatorSeedSecret != nil || jwtConfig.StrictSigningKeyUsage {
+			return fmt.Errorf("jwt.signer cannot be combined with operatorSeedSecret or strictSigningKeyUsage")
+		}
+	}
+	for _, include := range authConfig.Spec.ServerAuthConfig.Includes {
+		if (include == natsv1alpha1.ConfigIncludeAuthorization) == usesJWT(authConfig.Spec.Mode) {
+			return fmt.Errorf("serverAuthConfig.includes %q is not written in %s mode", include, authConfig.Spec.Mode)
+		}
+		if includeKeys[include] == authConfig.Spec.ServerAuthConfig.Key {
+			return fmt.Errorf("serverAuthConfig.key must differ from the %s include key %q", include, includeKeys[include])
+		}
+	}
+	if authConfig.Spec.ServerAuthConfig.SizePolicy == natsv1alpha1.SizePolicyChunk && !usesJWT(authConfig.Spec.Mode) {
+		return fmt.Errorf("serverAuthConfig.sizePolicy Chunk requires JWT or mixed mode")
+	}
+	if authConfig.Spec.Monitoring != nil && !usesJWT(authConfig.Spec.Mode) {
+		return fmt.Errorf("monitoring requires JWT or mixed mode")
+	}
+	if jwtConfig := authConfig.Spec.JWT; jwtConfig != nil && jwtConfig.Publish != nil {
+		if jwtConfig.Publish.AccountServer == nil && jwtConfig.Publish.Bucket == nil {
+			return fmt.Errorf("jwt.publish requires accountServer or bucket")
+		}
+	}
+	if err := validateResolverTargets(authConfig.Spec.JWT); err != nil {
+		return err
+	}
+	return validateUserClaimPolicy(userClaimPolicy(authConfig))
 }
 
 func (r *NatsAuthConfigReconciler) reconcileJWTMode(ctx context.Context, authConfig *natsv1alpha1.NatsAuthConfig) error {
 	log := log.FromContext(ctx)
 
-	// Get or create operator seed
-	operatorSeed, err := r.getOrCreateOperatorSeed(ctx, authConfig)
+	// The operator key lives on the external signer when there is one, otherwise in a seed Secret
+	ext, err := externalSigner(authConfig)
+	if err != nil {
+		return err
+	}
+	var operatorKP nkeys.KeyPair
+	if ext != nil {
+		operatorKP, err = signerKeyPair(ctx, ext, operatorSignerKey(authConfig))
+	} else {
+		var operatorSeed []byte
+		if operatorSeed, err = r.getOrCreateOperatorSeed(ctx, authConfig); err == nil {
+			operatorKP, err = nkeys.FromSeed(operatorSeed)
+		}
+	}
 	if err != nil {
-		return fmt.Errorf("failed to get operator seed: %w", err)
+		return fmt.Errorf("failed to get operator key: %w", err)
 	}
 
 	// Create operator manager
@@ -162,7 +389,21 @@ func (r *NatsAuthConfigReconciler) reconcileJWTMode(ctx context.Context, authCon
 		operatorName = authConfig.Spec.JWT.OperatorName
 	}
 
-	operatorMgr, err := jwtpkg.NewOperatorManager(operatorSeed, operatorName)
+	operatorOpts := jwtpkg.OperatorOptions{
+		AccountServerURL:      authConfig.Spec.JWT.AccountServerURL,
+		OperatorServiceURLs:   authConfig.Spec.JWT.OperatorServiceURLs,
+		StrictSigningKeyUsage: authConfig.Spec.JWT.StrictSigningKeyUsage,
+		Tags:                  authConfig.Spec.JWT.Tags,
+	}
+	if authConfig.Spec.JWT.StrictSigningKeyUsage {
+		signingKey, err := r.getOrCreateOperatorSigningKey(ctx, authConfig)
+		if err != nil {
+			return fmt.Errorf("failed to get operator signing key: %w", err)
+		}
+		operatorOpts.SigningKeys = []string{signingKey}
+	}
+
+	operatorMgr, err := jwtpkg.NewOperatorManagerFromKeyPair(operatorKP, operatorName, operatorOpts)
 	if err != nil {
 		return fmt.Errorf("failed to create operator manager: %w", err)
 	}
@@ -173,97 +414,404 @@ func (r *NatsAuthConfigReconciler) reconcileJWTMode(ctx context.Context, authCon
 		return fmt.Errorf("failed to get operator public key: %w", err)
 	}
 
+	// Keep the operator JWT already in the server auth config while its claims are unchanged, so
+	// the operator JWT is only re-issued, everywhere it is written, when its claims change
+	live, _, err := secrets.Get(ctx, r.Client, client.ObjectKey{Namespace: authConfig.Spec.ServerAuthConfig.Namespace, Name: authConfig.Spec.ServerAuthConfig.Name})
+	if err != nil {
+		return err
+	}
+	operatorMgr.ReuseJWT(string(live.Data[authconf.OperatorKey]))
+
+	// A changed operator key or claims hash makes the NatsAccount controller re-sign every account JWT
+	keyHash := jwtpkg.OperatorKeyHash(operatorPubKey, operatorOpts.SigningKeys)
+	claimsHash := operatorMgr.ClaimsHash()
+	switch previous := authConfig.Status; {
+	case previous.OperatorKeyHash != "" && previous.OperatorKeyHash != keyHash:
+		log.Info("Operator keys changed, re-signing account JWTs", "operatorPubKey", operatorPubKey, "previousOperatorPubKey", authConfig.Status.OperatorPubKey)
+		if r.Recorder != nil {
+			r.Recorder.Eventf(authConfig, corev1.EventTypeNormal, "OperatorKeyRotated", "Operator keys changed to %s, re-signing account JWTs", operatorPubKey)
+		}
+	case previous.OperatorClaimsHash != "" && previous.OperatorClaimsHash != claimsHash:
+		log.Info("Operator claims changed, re-issued the operator JWT and re-signing account JWTs", "operatorPubKey", operatorPubKey, "operatorName", operatorName)
+		if r.Recorder != nil {
+			r.Recorder.Event(authConfig, corev1.EventTypeNormal, "OperatorClaimsChanged", "Operator claims changed, re-issued the operator JWT and re-signing account JWTs")
+		}
+	}
+	authConfig.Status.OperatorKeyHash = keyHash
+	authConfig.Status.OperatorClaimsHash = claimsHash
+
 	// Collect all account JWTs
-	accounts, err := r.collectAccountJWTs(ctx, authConfig)
+	collectCtx, _ := withStep(ctx, "collect-accounts")
+	accounts, err := r.collectAccountJWTs(collectCtx, authConfig)
 	if err != nil {
 		return fmt.Errorf("failed to collect account JWTs: %w", err)
 	}
 
-	// Build Secret data with individual JWT keys
-	secretData := map[string][]byte{
-		"operator": []byte(operatorMgr.GetJWT()),
+	// Build Secret data with individual JWT keys, unless spec.jwt.resolver leaves the Secret out
+	targets := r.resolverTargets(authConfig)
+	secretAccounts := accounts
+	if !writesServerAuthSecret(targets) {
+		secretAccounts = nil
+	}
+	secretData, accountIndex, err := authconf.BuildServerAuthSecretData(
+		operatorMgr.GetJWT(),
+		secretAccounts,
+		authConfig.Spec.ServerAuthConfig.AccountKeyFormat,
+	)
+	if err != nil {
+		return err
+	}
+	for _, account := range accounts {
+		accountIndex[account.AccountNamespace+"/"+account.AccountName] = account.AccountID
+	}
+	for key, conf := range listenerConfData(authConfig) {
+		if _, ok := secretData[key]; ok {
+			return fmt.Errorf("account key %q collides with the listener config key of the same name", key)
+		}
+		secretData[key] = []byte(conf)
+	}
+	for key, conf := range jwtIncludeData(authConfig, operatorMgr.GetJWT(), serverResolverDir(authConfig.Spec.JWT, targets), secretAccounts) {
+		if _, ok := secretData[key]; ok {
+			return fmt.Errorf("account key %q collides with the include key of the same name", key)
+		}
+		secretData[key] = []byte(conf)
+	}
+	if pinned := authConfig.Spec.JWT.PinnedAccounts; pinned != nil {
+		accountIDs, err := authconf.PinnedAccountIDs(accounts, pinned.Tags, pinned.AccountIDs)
+		if err != nil {
+			return err
+		}
+		if _, ok := secretData[authconf.PinnedAccountsConfKey]; ok {
+			return fmt.Errorf("account key %q collides with the pinned accounts config key", authconf.PinnedAccountsConfKey)
+		}
+		secretData[authconf.PinnedAccountsConfKey] = []byte(authconf.RenderPinnedAccountsConf(accountIDs))
+		log.V(debugLevel).Info("Rendered pinned accounts", "step", "render-pinned-accounts", "accounts", len(accountIDs))
+	}
+	// Spread the account JWTs over overflow Secrets before the Secret outgrows its size limit
+	chunks := []map[string][]byte{secretData}
+	if authConfig.Spec.ServerAuthConfig.SizePolicy == natsv1alpha1.SizePolicyChunk && authconf.DataSize(secretData) > authconf.ConfigSizeWarning {
+		chunks = authconf.ChunkServerAuthSecretData(secretData, secretAccounts, authConfig.Spec.ServerAuthConfig.AccountKeyFormat, authconf.ChunkSize)
+		secretData = chunks[0]
+		log.V(debugLevel).Info("Chunked server auth Secret", "step", "render-secret", "chunks", len(chunks))
+	}
+	if err := signConfigData(authConfig, secretData, operatorKP); err != nil {
+		return err
+	}
+	if err := r.checkConfigSize(authConfig, secretData, len(chunks)); err != nil {
+		return err
+	}
+	if err := r.lintConfigData(authConfig, mergeConfigChunks(chunks)); err != nil {
+		return err
 	}
 
-	// Add each account JWT as a separate key (using account name for readability)
-	for _, acc := range accounts {
-		// Use account name as the key (e.g., "rumpusaccount", "system-account")
-		secretData[acc.AccountName] = []byte(acc.JWT)
+	log.V(debugLevel).Info("Rendered server auth Secret", "step", "render-secret", "keys", sortedKeys(secretData), "accountIndex", accountIndex)
+
+	if err := r.updateResigningCondition(ctx, authConfig); err != nil {
+		return err
 	}
 
-	// Create or update the Secret
-	secret := &corev1.Secret{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      authConfig.Spec.ServerAuthConfig.Name,
-			Namespace: authConfig.Spec.ServerAuthConfig.Namespace,
-		},
-		Data: secretData,
+	indexJSON, err := json.Marshal(accountIndex)
+	if err != nil {
+		return fmt.Errorf("failed to encode account index: %w", err)
 	}
 
-	// Try to get existing secret
-	existingSecret := &corev1.Secret{}
-	err = r.Get(ctx, client.ObjectKey{
-		Namespace: authConfig.Spec.ServerAuthConfig.Namespace,
-		Name:      authConfig.Spec.ServerAuthConfig.Name,
-	}, existingSecret)
+	// Check the live Secret for manual edits before overwriting it
+	signer := authConfig.Status.OperatorPubKey
+	if signer == "" {
+		signer = operatorPubKey
+	}
+	if err := r.verifyServerAuthConfig(ctx, authConfig, signer); err != nil {
+		return err
+	}
 
-	if err != nil {
-		if errors.IsNotFound(err) {
-			// Create new secret
-			if err := r.Create(ctx, secret); err != nil {
-				return fmt.Errorf("failed to create JWT secret: %w", err)
-			}
-			log.Info("Created JWT secret", "name", secret.Name, "accounts", len(accounts))
-		} else {
-			return fmt.Errorf("failed to get existing secret: %w", err)
-		}
-	} else {
-		// Update existing secret
-		existingSecret.Data = secretData
-		if err := r.Update(ctx, existingSecret); err != nil {
-			return fmt.Errorf("failed to update JWT secret: %w", err)
+	// Apply the Secret, only touching the keys this operator manages
+	secret := secrets.New(authConfig.Spec.ServerAuthConfig.Namespace, authConfig.Spec.ServerAuthConfig.Name, secretData)
+	secret.Annotations = map[string]string{
+		accountIndexAnnotation: string(indexJSON),
+		configHashAnnotation:   hashSecretData(secretData),
+	}
+	if err := r.setServerAuthConfigOwner(authConfig, secret); err != nil {
+		return err
+	}
+
+	if err := secrets.ApplyManaged(ctx, r.Client, secret); err != nil {
+		return fmt.Errorf("failed to apply JWT secret: %w", err)
+	}
+	if err := r.applyConfigChunks(ctx, authConfig, chunks); err != nil {
+		return err
+	}
+	log.Info("Applied JWT secret", "step", "apply-secret", "secret", secret.Namespace+"/"+secret.Name, "accounts", len(accounts), "chunks", len(chunks))
+	r.recordConfigWrite(authConfig, mergeConfigChunks(chunks))
+
+	// Mirror the account JWTs to the store of an external account resolver and the other resolver targets
+	if err := r.writeResolverDirs(authConfig, targets, operatorMgr.GetJWT()); err != nil {
+		return err
+	}
+	publishCtx, _ := withStep(ctx, "publish-accounts")
+	if err := r.publishAccounts(publishCtx, authConfig, targets, operatorMgr.GetJWT(), accounts); err != nil {
+		return err
+	}
+
+	if authConfig.Spec.Bootstrap != nil {
+		authConf := authconf.RenderMemoryResolverConf(operatorMgr.GetJWT(), accounts)
+		if err := r.reconcileBootstrap(ctx, authConfig, authConf, listenerIncludes(authConfig)); err != nil {
+			return err
 		}
-		log.Info("Updated JWT secret", "name", secret.Name, "accounts", len(accounts))
 	}
 
 	// Update status
 	authConfig.Status.OperatorPubKey = operatorPubKey
 	authConfig.Status.ResolverReady = true
 
+	if authConfig.Spec.PublicCatalog != nil {
+		if err := r.reconcilePublicCatalog(ctx, authConfig, operatorMgr.GetJWT()); err != nil {
+			return err
+		}
+	}
+
 	log.Info("JWT mode reconciled successfully", "operatorPubKey", operatorPubKey, "accounts", len(accounts))
 
 	return nil
 }
 
 func (r *NatsAuthConfigReconciler) reconcileTokenMode(ctx context.Context, authConfig *natsv1alpha1.NatsAuthConfig) error {
-	// In token mode, we just write an empty auth config initially
-	// Users will be added by the NatsUser controller
-	authConf := authconf.RenderTokenAuthConf([]authconf.TokenUser{})
+	// Render the users whose credentials the NatsUser controller has written
+	collectCtx, _ := withStep(ctx, "collect-users")
+	users, accountUsers, err := r.collectTokenUsers(collectCtx, authConfig)
+	if err != nil {
+		return fmt.Errorf("failed to collect token users: %w", err)
+	}
+	accounts, err := r.collectTokenAccounts(collectCtx, authConfig, accountUsers)
+	if err != nil {
+		return fmt.Errorf("failed to collect token accounts: %w", err)
+	}
+	noAuthUser, err := r.noAuthUsername(collectCtx, authConfig, users, accounts)
+	if err != nil {
+		return err
+	}
+	renderer, err := r.authConfRenderer(ctx, authConfig)
+	if err != nil {
+		return err
+	}
+	authConf, err := renderer.RenderTokenAuthConf(authconf.TokenAuthConf{NoAuthUser: noAuthUser, Users: users, Accounts: accounts})
+	if err != nil {
+		return permanent(natsv1alpha1.ReasonInvalidSpec, err)
+	}
+	log.FromContext(ctx).V(debugLevel).Info("Rendered token auth config", "step", "render-config", "config", authconf.Redact(authConf))
 
-	if err := resolver.WriteResolverConfig(
+	written := map[string][]byte{authConfig.Spec.ServerAuthConfig.Key: []byte(authConf)}
+	if hasInclude(authConfig, natsv1alpha1.ConfigIncludeAuthorization) {
+		authorizationConf, err := renderer.RenderAuthorizationConf(users)
+		if err != nil {
+			return permanent(natsv1alpha1.ReasonInvalidSpec, err)
+		}
+		written[authconf.AuthorizationConfKey] = []byte(authorizationConf)
+	}
+	for k, v := range listenerConfData(authConfig) {
+		written[k] = []byte(v)
+	}
+	if err := signConfigData(authConfig, written, nil); err != nil {
+		return err
+	}
+	if err := r.checkConfigSize(authConfig, written, 1); err != nil {
+		return err
+	}
+	if err := r.lintConfigData(authConfig, written); err != nil {
+		return err
+	}
+
+	// Check the live config for manual edits before overwriting it
+	if err := r.verifyServerAuthConfig(ctx, authConfig, ""); err != nil {
+		return err
+	}
+
+	data := make(map[string]string, len(written))
+	for k, v := range written {
+		data[k] = string(v)
+	}
+	meta := metav1.ObjectMeta{
+		Name:        authConfig.Spec.ServerAuthConfig.Name,
+		Namespace:   authConfig.Spec.ServerAuthConfig.Namespace,
+		Annotations: map[string]string{configHashAnnotation: hashSecretData(written)},
+	}
+	if err := r.setServerAuthConfigOwner(authConfig, &meta); err != nil {
+		return err
+	}
+	if err := resolver.WriteResolverConfigData(
 		ctx,
 		r.Client,
-		authConfig.Spec.ServerAuthConfig.Namespace,
-		authConfig.Spec.ServerAuthConfig.Name,
-		authConfig.Spec.ServerAuthConfig.Key,
+		meta,
 		authConfig.Spec.ServerAuthConfig.Type,
-		authConf,
+		data,
 	); err != nil {
 		return fmt.Errorf("failed to write token auth config: %w", err)
 	}
+	r.recordConfigWrite(authConfig, written)
+
+	if authConfig.Spec.Bootstrap != nil {
+		includes := append([]string{authConfig.Spec.ServerAuthConfig.Key}, listenerIncludes(authConfig)...)
+		if err := r.reconcileBootstrap(ctx, authConfig, "", includes); err != nil {
+			return err
+		}
+	}
+	if authConfig.Spec.PublicCatalog != nil {
+		if err := r.reconcilePublicCatalog(ctx, authConfig, ""); err != nil {
+			return err
+		}
+	}
 
 	authConfig.Status.ResolverReady = true
 
 	return nil
 }
 
+// reconcileMixedMode renders the JWT config. Servers configured with an operator refuse users
+// defined in the config, so token users cannot be added to it: the NatsUser webhook and
+// controller refuse them, and mixed mode is deprecated in favour of jwt mode.
 func (r *NatsAuthConfigReconciler) reconcileMixedMode(ctx context.Context, authConfig *natsv1alpha1.NatsAuthConfig) error {
-	// For mixed mode, we need both JWT and token configs
-	// First setup JWT infrastructure
-	if err := r.reconcileJWTMode(ctx, authConfig); err != nil {
+	if r.Recorder != nil {
+		r.Recorder.Event(authConfig, corev1.EventTypeWarning, "DeprecatedMode", "Mixed mode is deprecated and behaves like jwt mode; set spec.mode to jwt")
+	}
+	return r.reconcileJWTMode(ctx, authConfig)
+}
+
+// setServerAuthConfigOwner labels the server auth config with the operator and its NatsAuthConfig.
+// Under DeletionPolicy Delete it also gets a controller reference when it lives in the
+// NatsAuthConfig namespace; elsewhere the finalizer deletes it by its labels.
+func (r *NatsAuthConfigReconciler) setServerAuthConfigOwner(authConfig *natsv1alpha1.NatsAuthConfig, obj metav1.Object) error {
+	labels := obj.GetLabels()
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	labels[managedByLabel] = managedByValue
+	labels[authConfigNameLabel] = authConfig.Name
+	labels[authConfigNamespaceLabel] = authConfig.Namespace
+	obj.SetLabels(labels)
+	natslabels.Stamp(obj, authConfigOwners(authConfig))
+
+	if authConfig.Spec.ServerAuthConfig.DeletionPolicy != natsv1alpha1.DeletionPolicyDelete || obj.GetNamespace() != authConfig.Namespace {
+		return nil
+	}
+	return controllerutil.SetControllerReference(authConfig, obj, r.Scheme)
+}
+
+// recordConfigWrite stores the hash of the written server auth config and emits a single
+// ServerAuthConfigUpdated event when the content changed, for reloaders to act on
+func (r *NatsAuthConfigReconciler) recordConfigWrite(authConfig *natsv1alpha1.NatsAuthConfig, data map[string][]byte) {
+	hash := hashSecretData(data)
+	if hash == authConfig.Status.ConfigHash {
+		return
+	}
+	if authConfig.Status.ConfigHash != "" && r.Recorder != nil {
+		r.Recorder.Eventf(authConfig, corev1.EventTypeNormal, "ServerAuthConfigUpdated", "Updated server auth config %s/%s",
+			authConfig.Spec.ServerAuthConfig.Namespace, authConfig.Spec.ServerAuthConfig.Name)
+	}
+	authConfig.Status.ConfigHash = hash
+}
+
+// listenerConfData renders the optional websocket and mqtt blocks written next to the auth config
+func listenerConfData(authConfig *natsv1alpha1.NatsAuthConfig) map[string]string {
+	data := map[string]string{}
+	if ws := authConfig.Spec.Websocket; ws != nil {
+		data[authconf.WebsocketConfKey] = authconf.RenderWebsocketConf(authconf.WebsocketOptions{
+			Port:      ws.Port,
+			NoTLS:     ws.NoTLS,
+			JWTCookie: ws.JWTCookie,
+		})
+	}
+	if mqtt := authConfig.Spec.MQTT; mqtt != nil {
+		data[authconf.MQTTConfKey] = authconf.RenderMQTTConf(authconf.MQTTOptions{
+			Port: mqtt.Port,
+		})
+	}
+	return data
+}
+
+// includeKeys maps each split include to the server auth config key it is written to
+var includeKeys = map[natsv1alpha1.ConfigInclude]string{
+	natsv1alpha1.ConfigIncludeOperator:      authconf.OperatorConfKey,
+	natsv1alpha1.ConfigIncludeResolver:      authconf.ResolverConfKey,
+	natsv1alpha1.ConfigIncludePreload:       authconf.PreloadConfKey,
+	natsv1alpha1.ConfigIncludeAuthorization: authconf.AuthorizationConfKey,
+}
+
+// hasInclude reports whether serverAuthConfig.includes lists the include
+func hasInclude(authConfig *natsv1alpha1.NatsAuthConfig, include natsv1alpha1.ConfigInclude) bool {
+	return slices.Contains(authConfig.Spec.ServerAuthConfig.Includes, include)
+}
+
+// jwtIncludeData renders the operator, resolver and preload includes listed in
+// serverAuthConfig.includes; the resolver include points the servers at resolverDir
+func jwtIncludeData(authConfig *natsv1alpha1.NatsAuthConfig, operatorJWT, resolverDir string, accounts []authconf.AccountJWT) map[string]string {
+	data := map[string]string{}
+	if hasInclude(authConfig, natsv1alpha1.ConfigIncludeOperator) {
+		data[authconf.OperatorConfKey] = authconf.RenderOperatorConf(operatorJWT)
+	}
+	if hasInclude(authConfig, natsv1alpha1.ConfigIncludeResolver) {
+		data[authconf.ResolverConfKey] = authconf.RenderResolverConf(resolverDir)
+	}
+	if hasInclude(authConfig, natsv1alpha1.ConfigIncludePreload) {
+		data[authconf.PreloadConfKey] = authconf.RenderPreloadConf(accounts)
+	}
+	return data
+}
+
+// listenerIncludes returns the listener config keys to include from the bootstrap config
+func listenerIncludes(authConfig *natsv1alpha1.NatsAuthConfig) []string {
+	var includes []string
+	if authConfig.Spec.Websocket != nil {
+		includes = append(includes, authconf.WebsocketConfKey)
+	}
+	if authConfig.Spec.MQTT != nil {
+		includes = append(includes, authconf.MQTTConfKey)
+	}
+	if authConfig.Spec.JWT != nil && authConfig.Spec.JWT.PinnedAccounts != nil && usesJWT(effectiveMode(authConfig)) {
+		includes = append(includes, authconf.PinnedAccountsConfKey)
+	}
+	return includes
+}
+
+// bootstrapConfigMapName returns the ConfigMap receiving the generated nats-server.conf
+func bootstrapConfigMapName(authConfig *natsv1alpha1.NatsAuthConfig) string {
+	if authConfig.Spec.Bootstrap != nil && authConfig.Spec.Bootstrap.ConfigMapName != "" {
+		return authConfig.Spec.Bootstrap.ConfigMapName
+	}
+	return fmt.Sprintf("%s-bootstrap", authConfig.Name)
+}
+
+// reconcileBootstrap writes a minimal nats-server.conf that a fresh server can boot from.
+// Includes are resolved relative to the config file, so the server auth config is expected
+// to be mounted in the same directory.
+func (r *NatsAuthConfigReconciler) reconcileBootstrap(ctx context.Context, authConfig *natsv1alpha1.NatsAuthConfig, authConf string, includes []string) error {
+	bootstrap := authConfig.Spec.Bootstrap
+
+	conf := authconf.RenderBootstrapConf(authconf.BootstrapOptions{
+		Port:              bootstrap.Port,
+		JetStreamStoreDir: bootstrap.JetStreamStoreDir,
+		AuthConf:          authConf,
+		Includes:          includes,
+	})
+
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      bootstrapConfigMapName(authConfig),
+			Namespace: authConfig.Spec.ServerAuthConfig.Namespace,
+		},
+		Data: map[string]string{
+			authconf.BootstrapConfKey: conf,
+		},
+	}
+
+	natslabels.Stamp(cm, authConfigOwners(authConfig))
+	if err := authConfigTracking.SetOwner(authConfig, cm, r.Scheme); err != nil {
 		return err
 	}
 
-	// Token users will be added by the NatsUser controller
+	if err := resolver.ApplyConfigMap(ctx, r.Client, cm); err != nil {
+		return fmt.Errorf("failed to apply bootstrap ConfigMap: %w", err)
+	}
+	log.FromContext(ctx).Info("Applied bootstrap ConfigMap", "step", "apply-bootstrap", "configMap", cm.Namespace+"/"+cm.Name)
+
 	return nil
 }
 
@@ -271,107 +819,443 @@ func (r *NatsAuthConfigReconciler) reconcileMixedMode(ctx context.Context, authC
 func (r *NatsAuthConfigReconciler) collectAccountJWTs(ctx context.Context, authConfig *natsv1alpha1.NatsAuthConfig) ([]authconf.AccountJWT, error) {
 	log := log.FromContext(ctx)
 
-	// List all NatsAccounts that reference this NatsAuthConfig
+	// List all NatsAccounts that reference this NatsAuthConfig, in any namespace
 	accountList := &natsv1alpha1.NatsAccountList{}
-	if err := r.List(ctx, accountList, client.InNamespace(authConfig.Namespace)); err != nil {
+	if err := r.List(ctx, accountList, client.MatchingFields{authConfigIndex: client.ObjectKeyFromObject(authConfig).String()}); err != nil {
 		return nil, fmt.Errorf("failed to list accounts: %w", err)
 	}
 
+	// Read the account JWT Secrets in one List; Secrets written before they were labelled are
+	// read one at a time until their account is reconciled again, as are all of them under
+	// restricted Secret access, which cannot list
+	jwtSecrets := map[client.ObjectKey]*corev1.Secret{}
+	var err error
+	if r.SecretAccess.WatchesSecrets() {
+		if jwtSecrets, err = secrets.List(ctx, r.Client, authConfigSecretLabels(authConfig), client.UnsafeDisableDeepCopy); err != nil {
+			return nil, err
+		}
+	}
+
 	var accounts []authconf.AccountJWT
 
 	for _, account := range accountList.Items {
-		// Check if this account references our NatsAuthConfig
-		if account.Spec.AuthConfigRef.Name != authConfig.Name {
+		// Get the account JWT from the secret recorded by the account controller
+		if account.Status.JWTSecretRef.Name == "" {
+			log.Info("Account JWT secret not recorded yet, skipping", "account", account.Namespace+"/"+account.Name)
 			continue
 		}
-
-		// Get the account JWT from the secret
-		secretName := fmt.Sprintf("%s-account-jwt", account.Name)
-		secret := &corev1.Secret{}
 		key := client.ObjectKey{
 			Namespace: account.Namespace,
-			Name:      secretName,
+			Name:      account.Status.JWTSecretRef.Name,
 		}
-
-		if err := r.Get(ctx, key, secret); err != nil {
-			if errors.IsNotFound(err) {
-				log.Info("Account JWT secret not found yet, skipping", "account", account.Name)
-				continue
+		secret, exists := jwtSecrets[key]
+		if !exists {
+			if secret, exists, err = secrets.Get(ctx, r.Client, key); err != nil {
+				return nil, fmt.Errorf("failed to get account JWT secret: %w", err)
 			}
-			return nil, fmt.Errorf("failed to get account JWT secret: %w", err)
+		}
+		if !exists {
+			log.Info("Account JWT secret not found yet, skipping", "account", account.Namespace+"/"+account.Name)
+			continue
 		}
 
 		// Extract JWT and account ID
 		jwtData, ok := secret.Data["account.jwt"]
 		if !ok {
-			log.Info("Account JWT not found in secret, skipping", "account", account.Name)
+			log.Info("Account JWT not found in secret, skipping", "account", account.Namespace+"/"+account.Name)
 			continue
 		}
 
 		// Get account ID from status
 		if account.Status.AccountID == "" {
-			log.Info("Account ID not set in status yet, skipping", "account", account.Name)
+			log.Info("Account ID not set in status yet, skipping", "account", account.Namespace+"/"+account.Name)
 			continue
 		}
 
 		accounts = append(accounts, authconf.AccountJWT{
-			AccountName: account.Name,
-			AccountID:   account.Status.AccountID,
-			JWT:         string(jwtData),
+			AccountName:      account.Name,
+			AccountNamespace: account.Namespace,
+			AccountID:        account.Status.AccountID,
+			JWT:              string(jwtData),
 		})
 	}
+	authconf.SortAccounts(accounts)
 
-	log.Info("Collected account JWTs", "count", len(accounts))
+	log.V(debugLevel).Info("Collected account JWTs", "count", len(accounts))
 	return accounts, nil
 }
 
-func (r *NatsAuthConfigReconciler) getOrCreateOperatorSeed(ctx context.Context, authConfig *natsv1alpha1.NatsAuthConfig) ([]byte, error) {
-	// Check if existing seed is specified
-	if authConfig.Spec.JWT.OperatorSeedSecret != nil {
+// collectTokenUsers reads the credentials of the token-mode NatsUsers referencing the NatsAuthConfig.
+// Users with an accountRef are returned grouped by the key of their NatsAccount.
+func (r *NatsAuthConfigReconciler) collectTokenUsers(ctx context.Context, authConfig *natsv1alpha1.NatsAuthConfig) ([]authconf.TokenUser, map[client.ObjectKey][]authconf.TokenUser, error) {
+	log := log.FromContext(ctx)
+
+	userList := &natsv1alpha1.NatsUserList{}
+	if err := r.List(ctx, userList, client.MatchingFields{authConfigIndex: client.ObjectKeyFromObject(authConfig).String()}); err != nil {
+		return nil, nil, fmt.Errorf("failed to list users: %w", err)
+	}
+
+	// Visit users in claim order so that the oldest user claiming a username keeps it
+	sort.Slice(userList.Items, func(i, j int) bool { return claimsBefore(&userList.Items[i], &userList.Items[j]) })
+
+	var users []authconf.TokenUser
+	accountUsers := map[client.ObjectKey][]authconf.TokenUser{}
+	owners := map[string]client.ObjectKey{}
+	for i := range userList.Items {
+		user := &userList.Items[i]
+		// Only called in token mode, where users inheriting the mode are token users as well
+		if user.Spec.AuthType == natsv1alpha1.UserAuthTypeJWT || !user.DeletionTimestamp.IsZero() {
+			continue
+		}
+		ref := user.Status.SecretRef
+		if ref.Name == "" {
+			log.Info("Credentials secret not written yet, skipping", "user", user.Namespace+"/"+user.Name)
+			continue
+		}
+		if ref.Namespace == "" {
+			ref.Namespace = user.Namespace
+		}
+
 		secret := &corev1.Secret{}
-		key := client.ObjectKey{
-			Namespace: authConfig.Spec.JWT.OperatorSeedSecret.Namespace,
-			Name:      authConfig.Spec.JWT.OperatorSeedSecret.Name,
+		if err := r.Get(ctx, client.ObjectKey{Namespace: ref.Namespace, Name: ref.Name}, secret); err != nil {
+			if errors.IsNotFound(err) {
+				log.Info("Credentials secret not found, skipping", "user", user.Namespace+"/"+user.Name)
+				continue
+			}
+			return nil, nil, fmt.Errorf("failed to get credentials secret: %w", err)
 		}
-		if err := r.Get(ctx, key, secret); err != nil {
-			return nil, fmt.Errorf("failed to get operator seed secret: %w", err)
+
+		username := string(secret.Data["USERNAME"])
+		if username == "" {
+			continue
+		}
+		if owner, ok := owners[username]; ok {
+			log.Info("Username already used by another user, skipping", "user", user.Namespace+"/"+user.Name, "owner", owner.String())
+			if r.Recorder != nil {
+				r.Recorder.Eventf(authConfig, corev1.EventTypeWarning, "DuplicateUsername", "NatsUser %s/%s claims username %q already used by NatsUser %s, skipping it",
+					user.Namespace, user.Name, username, owner)
+			}
+			continue
+		}
+		owners[username] = client.ObjectKeyFromObject(user)
+		tokenUser := authconf.TokenUser{
+			Username:    username,
+			Password:    string(secret.Data["PASSWORD"]),
+			Permissions: user.Spec.Permissions,
+		}
+		for _, connType := range user.Spec.AllowedConnectionTypes {
+			tokenUser.AllowedConnectionTypes = append(tokenUser.AllowedConnectionTypes, string(connType))
+		}
+		if user.Spec.AccountRef != nil {
+			key := accountKey(user)
+			accountUsers[key] = append(accountUsers[key], tokenUser)
+			continue
+		}
+		users = append(users, tokenUser)
+	}
+	sortTokenUsers(users)
+	for _, list := range accountUsers {
+		sortTokenUsers(list)
+	}
+
+	log.V(debugLevel).Info("Collected token users", "count", len(users), "accounts", len(accountUsers))
+	return users, accountUsers, nil
+}
+
+// noAuthUsername returns the username of the NatsUser named by spec.noAuthUser once it is rendered
+// into the config; the server refuses a no_auth_user it has no user for, so until then it is
+// left out
+func (r *NatsAuthConfigReconciler) noAuthUsername(ctx context.Context, authConfig *natsv1alpha1.NatsAuthConfig, users []authconf.TokenUser, accounts []authconf.TokenAccount) (string, error) {
+	ref := authConfig.Spec.NoAuthUser
+	if ref == nil {
+		return "", nil
+	}
+	key := client.ObjectKey{Namespace: ref.Namespace, Name: ref.Name}
+	if key.Namespace == "" {
+		key.Namespace = authConfig.Namespace
+	}
+
+	user := &natsv1alpha1.NatsUser{}
+	if err := r.Get(ctx, key, user); err != nil && !errors.IsNotFound(err) {
+		return "", fmt.Errorf("failed to get noAuthUser %s: %w", key, err)
+	} else if err == nil && authConfigKey(user) != client.ObjectKeyFromObject(authConfig) {
+		return "", permanent(natsv1alpha1.ReasonInvalidSpec, fmt.Errorf("noAuthUser %s belongs to NatsAuthConfig %s", key, authConfigKey(user)))
+	}
+
+	rendered := func(list []authconf.TokenUser) bool {
+		return user.Status.Username != "" && slices.ContainsFunc(list, func(u authconf.TokenUser) bool { return u.Username == user.Status.Username })
+	}
+	found := rendered(users)
+	for _, account := range accounts {
+		found = found || rendered(account.Users)
+	}
+	if !found {
+		log.FromContext(ctx).Info("noAuthUser is not rendered yet, leaving out no_auth_user", "user", key.String())
+		if r.Recorder != nil {
+			r.Recorder.Eventf(authConfig, corev1.EventTypeWarning, "NoAuthUserNotReady", "NatsUser %s has no token credentials yet, no_auth_user is not set", key)
 		}
+		return "", nil
+	}
+	return user.Status.Username, nil
+}
+
+// collectTokenAccounts assembles the static accounts of a token-mode NatsAuthConfig from the
+// NatsAccounts referencing it, with their users, exports and imports. Accounts are rendered
+// under their resource name, which must be unique per NatsAuthConfig.
+func (r *NatsAuthConfigReconciler) collectTokenAccounts(ctx context.Context, authConfig *natsv1alpha1.NatsAuthConfig, accountUsers map[client.ObjectKey][]authconf.TokenUser) ([]authconf.TokenAccount, error) {
+	log := log.FromContext(ctx)
 
-		seedKey := authConfig.Spec.JWT.OperatorSeedSecret.Key
-		if seedKey == "" {
-			seedKey = "operator.seed"
+	accountList := &natsv1alpha1.NatsAccountList{}
+	if err := r.List(ctx, accountList, client.MatchingFields{authConfigIndex: client.ObjectKeyFromObject(authConfig).String()}); err != nil {
+		return nil, fmt.Errorf("failed to list accounts: %w", err)
+	}
+
+	// Map every live account to its rendered name
+	names := map[client.ObjectKey]string{}
+	owners := map[string]client.ObjectKey{}
+	var live []*natsv1alpha1.NatsAccount
+	for i := range accountList.Items {
+		account := &accountList.Items[i]
+		if !account.DeletionTimestamp.IsZero() {
+			continue
 		}
+		key := client.ObjectKeyFromObject(account)
+		if other, ok := owners[account.Name]; ok {
+			return nil, fmt.Errorf("accounts %s and %s share the name %q; token-mode account names must be unique", other, key, account.Name)
+		}
+		owners[account.Name] = key
+		names[key] = account.Name
+		live = append(live, account)
+	}
+	sort.Slice(live, func(i, j int) bool { return live[i].Name < live[j].Name })
 
-		seed, ok := secret.Data[seedKey]
-		if !ok {
-			return nil, fmt.Errorf("operator seed key %q not found in secret", seedKey)
+	resolve := func(account *natsv1alpha1.NatsAccount, ref natsv1alpha1.NatsAccountRef) (string, bool) {
+		namespace := ref.Namespace
+		if namespace == "" {
+			namespace = account.Namespace
 		}
+		name, ok := names[client.ObjectKey{Namespace: namespace, Name: ref.Name}]
+		return name, ok
+	}
 
-		return seed, nil
+	for key := range accountUsers {
+		if _, ok := names[key]; !ok {
+			log.Info("Users reference a NatsAccount that is not part of this auth config, skipping", "account", key.String())
+		}
 	}
 
-	// Create new operator and store the seed
-	operatorMgr, err := jwtpkg.NewOperatorManager(nil, "NATS Operator")
-	if err != nil {
-		return nil, err
+	accounts := make([]authconf.TokenAccount, 0, len(live))
+	for _, account := range live {
+		tokenAccount := authconf.TokenAccount{
+			Name:  account.Name,
+			Users: accountUsers[client.ObjectKeyFromObject(account)],
+		}
+
+		for _, export := range account.Spec.Exports {
+			tokenExport := authconf.TokenExport{Type: string(export.Type), Subject: export.Subject, ResponseType: string(export.ResponseType)}
+			if export.Latency != nil {
+				sampling, err := jwtpkg.ParseSamplingRate(export.Latency.Sampling)
+				if err != nil {
+					return nil, err
+				}
+				tokenExport.Latency = &authconf.TokenLatency{Sampling: int(sampling), Subject: export.Latency.Results}
+			}
+			for _, ref := range export.Accounts {
+				if name, ok := resolve(account, ref); ok {
+					tokenExport.Accounts = append(tokenExport.Accounts, name)
+				}
+			}
+			if len(export.Accounts) > 0 && len(tokenExport.Accounts) == 0 {
+				// Never widen a restricted export to a public one
+				log.Info("No importing account of a restricted export exists yet, skipping it", "account", account.Name, "subject", export.Subject)
+				continue
+			}
+			tokenAccount.Exports = append(tokenAccount.Exports, tokenExport)
+		}
+
+		for _, mapping := range account.Spec.Mappings {
+			tokenMapping := authconf.TokenMapping{Subject: mapping.Subject}
+			for _, dest := range mapping.Destinations {
+				tokenMapping.Destinations = append(tokenMapping.Destinations, authconf.TokenMappingDestination{
+					Subject: dest.Subject,
+					Weight:  int(dest.Weight),
+					Cluster: dest.Cluster,
+				})
+			}
+			tokenAccount.Mappings = append(tokenAccount.Mappings, tokenMapping)
+		}
+
+		for _, imp := range account.Spec.Imports {
+			name, ok := resolve(account, imp.AccountRef)
+			if !ok {
+				log.Info("Exporting account of an import does not exist yet, skipping it", "account", account.Name, "subject", imp.Subject)
+				continue
+			}
+			tokenAccount.Imports = append(tokenAccount.Imports, authconf.TokenImport{
+				Type:    string(imp.Type),
+				Account: name,
+				Subject: imp.Subject,
+				Prefix:  imp.Prefix,
+				To:      imp.To,
+			})
+		}
+
+		accounts = append(accounts, tokenAccount)
+	}
+
+	log.V(debugLevel).Info("Collected token accounts", "count", len(accounts))
+	return accounts, nil
+}
+
+// sortTokenUsers orders users by username so the rendered config is stable
+func sortTokenUsers(users []authconf.TokenUser) {
+	sort.Slice(users, func(i, j int) bool { return users[i].Username < users[j].Username })
+}
+
+// updateChildrenSummary aggregates the accounts and users referencing the NatsAuthConfig into its status
+func (r *NatsAuthConfigReconciler) updateChildrenSummary(ctx context.Context, authConfig *natsv1alpha1.NatsAuthConfig) error {
+	key := client.MatchingFields{authConfigIndex: client.ObjectKeyFromObject(authConfig).String()}
+
+	accountList := &natsv1alpha1.NatsAccountList{}
+	if err := r.List(ctx, accountList, key); err != nil {
+		return fmt.Errorf("failed to list accounts: %w", err)
+	}
+	userList := &natsv1alpha1.NatsUserList{}
+	if err := r.List(ctx, userList, key); err != nil {
+		return fmt.Errorf("failed to list users: %w", err)
+	}
+
+	summary := natsv1alpha1.ChildrenSummary{
+		Accounts:        int32(len(accountList.Items)),
+		Users:           int32(len(userList.Items)),
+		UsersByAuthType: map[string]int32{},
+	}
+
+	// Keep the most recent Ready=False message across all children
+	var lastErrorTime metav1.Time
+	noteError := func(kind string, obj client.Object, conditions []metav1.Condition) {
+		ready := meta.FindStatusCondition(conditions, "Ready")
+		if ready == nil || ready.Status != metav1.ConditionFalse || ready.LastTransitionTime.Before(&lastErrorTime) {
+			return
+		}
+		lastErrorTime = ready.LastTransitionTime
+		summary.LastError = fmt.Sprintf("%s %s: %s", kind, client.ObjectKeyFromObject(obj), ready.Message)
+	}
+
+	for i := range accountList.Items {
+		account := &accountList.Items[i]
+		if meta.IsStatusConditionTrue(account.Status.Conditions, "Ready") {
+			summary.ReadyAccounts++
+		}
+		noteError("NatsAccount", account, account.Status.Conditions)
+	}
+	for i := range userList.Items {
+		user := &userList.Items[i]
+		authType := user.Spec.AuthType
+		if authType == "" || authType == natsv1alpha1.UserAuthTypeInherit {
+			authType = inheritedAuthType(effectiveMode(authConfig))
+		}
+		summary.UsersByAuthType[string(authType)]++
+		noteError("NatsUser", user, user.Status.Conditions)
+	}
+
+	authConfig.Status.Children = summary
+	return nil
+}
+
+// findAuthConfigForChild maps a NatsAccount or NatsUser to the NatsAuthConfig it references
+func (r *NatsAuthConfigReconciler) findAuthConfigForChild(ctx context.Context, obj client.Object) []reconcile.Request {
+	var key client.ObjectKey
+	switch o := obj.(type) {
+	case *natsv1alpha1.NatsAccount:
+		key = accountAuthConfigKey(o)
+	case *natsv1alpha1.NatsUser:
+		key = authConfigKey(o)
+	default:
+		return nil
+	}
+	return []reconcile.Request{{NamespacedName: key}}
+}
+
+// updateResigningCondition reports the accounts not yet re-signed under the current operator keys
+// and claims in the ResigningAccounts condition. The condition is only added once a rotation or a
+// claims change leaves accounts behind.
+func (r *NatsAuthConfigReconciler) updateResigningCondition(ctx context.Context, authConfig *natsv1alpha1.NatsAuthConfig) error {
+	accountList := &natsv1alpha1.NatsAccountList{}
+	if err := r.List(ctx, accountList, client.MatchingFields{authConfigIndex: client.ObjectKeyFromObject(authConfig).String()}); err != nil {
+		return fmt.Errorf("failed to list accounts: %w", err)
+	}
+
+	var pending []string
+	reason := "OperatorClaimsChanged"
+	for i := range accountList.Items {
+		account := &accountList.Items[i]
+		if account.Status.AccountID == "" || operatorKeyCurrent(account, authConfig) {
+			continue
+		}
+		pending = append(pending, account.Namespace+"/"+account.Name)
+		if account.Status.OperatorKeyHash != "" && account.Status.OperatorKeyHash != authConfig.Status.OperatorKeyHash {
+			reason = "OperatorKeyRotated"
+		}
+	}
+
+	if len(pending) > 0 {
+		sort.Strings(pending)
+		r.updateCondition(authConfig, metav1.Condition{
+			Type:    resigningAccountsCondition,
+			Status:  metav1.ConditionTrue,
+			Reason:  reason,
+			Message: fmt.Sprintf("%d of %d accounts not yet re-signed with the current operator keys and claims: %s", len(pending), len(accountList.Items), strings.Join(pending, ", ")),
+		})
+		return nil
+	}
+	if meta.FindStatusCondition(authConfig.Status.Conditions, resigningAccountsCondition) != nil {
+		r.updateCondition(authConfig, metav1.Condition{
+			Type:    resigningAccountsCondition,
+			Status:  metav1.ConditionFalse,
+			Reason:  "AccountsResigned",
+			Message: "All account JWTs are signed with the current operator keys and claims",
+		})
+	}
+	return nil
+}
+
+// findAuthConfigForOperatorSeed maps a changed operator seed Secret to the NatsAuthConfigs referencing it,
+// so a replaced seed is picked up right away
+func (r *NatsAuthConfigReconciler) findAuthConfigForOperatorSeed(ctx context.Context, obj client.Object) []reconcile.Request {
+	list := &natsv1alpha1.NatsAuthConfigList{}
+	if err := r.List(ctx, list, client.MatchingFields{operatorSeedIndex: client.ObjectKeyFromObject(obj).String()}); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to list NatsAuthConfigs for operator seed")
+		return nil
 	}
 
-	seed, err := operatorMgr.GetSeed()
+	requests := make([]reconcile.Request, 0, len(list.Items))
+	for _, authConfig := range list.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&authConfig)})
+	}
+	return requests
+}
+
+func (r *NatsAuthConfigReconciler) getOrCreateOperatorSeed(ctx context.Context, authConfig *natsv1alpha1.NatsAuthConfig) ([]byte, error) {
+	// Check if existing seed is specified
+	if ref := authConfig.Spec.JWT.OperatorSeedSecret; ref != nil {
+		return getSeed(ctx, r.Client, client.ObjectKey{Namespace: ref.Namespace, Name: ref.Name}, nkeys.PrefixByteOperator, ref.Key)
+	}
+
+	// Create new operator and store the seed
+	seed, err := random.Seed(random.OrDefault(r.Random), nkeys.PrefixByteOperator)
 	if err != nil {
 		return nil, err
 	}
 
 	// Store the seed in a secret
-	secretName := fmt.Sprintf("%s-operator-seed", authConfig.Name)
-	secret := &corev1.Secret{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      secretName,
-			Namespace: authConfig.Namespace,
-		},
-		Data: map[string][]byte{
-			"operator.seed": seed,
-		},
-	}
+	secretName := operatorSeedSecretName(authConfig)
+	secret := secrets.New(authConfig.Namespace, secretName, map[string][]byte{
+		jwtpkg.OperatorSeedKey: seed,
+	})
+	secret.Labels = seedSecretLabels(nkeys.PrefixByteOperator)
+	protectSeedSecret(secret, r.SecretAccess)
+	natslabels.Stamp(secret, authConfigOwners(authConfig))
 
 	if err := controllerutil.SetControllerReference(authConfig, secret, r.Scheme); err != nil {
 		return nil, err
@@ -382,18 +1266,214 @@ func (r *NatsAuthConfigReconciler) getOrCreateOperatorSeed(ctx context.Context,
 			return nil, err
 		}
 		// If already exists, retrieve it
-		if err := r.Get(ctx, client.ObjectKey{Namespace: authConfig.Namespace, Name: secretName}, secret); err != nil {
-			return nil, err
-		}
-		return secret.Data["operator.seed"], nil
+		return getSeed(ctx, r.Client, client.ObjectKey{Namespace: authConfig.Namespace, Name: secretName}, nkeys.PrefixByteOperator, "")
 	}
 
 	return seed, nil
 }
 
+// infraAuthSecretName returns the Secret receiving the cluster and gateway authorization
+func infraAuthSecretName(authConfig *natsv1alpha1.NatsAuthConfig) string {
+	if authConfig.Spec.InfraAuth != nil && authConfig.Spec.InfraAuth.SecretName != "" {
+		return authConfig.Spec.InfraAuth.SecretName
+	}
+	return fmt.Sprintf("%s-infra-auth", authConfig.Name)
+}
+
+// reconcileInfraAuth renders the cluster and gateway authorization blocks into a Secret
+// next to the server auth config, so route and gateway credentials live with client auth
+func (r *NatsAuthConfigReconciler) reconcileInfraAuth(ctx context.Context, authConfig *natsv1alpha1.NatsAuthConfig) error {
+	log := log.FromContext(ctx)
+	infra := authConfig.Spec.InfraAuth
+
+	secret := secrets.New(authConfig.Spec.ServerAuthConfig.Namespace, infraAuthSecretName(authConfig), nil)
+	existing, _, err := secrets.Get(ctx, r.Client, client.ObjectKeyFromObject(secret))
+	if err != nil {
+		return err
+	}
+
+	blocks := []struct {
+		endpoint    *natsv1alpha1.InfraAuthEndpoint
+		defaultUser string
+		render      func(authconf.InfraAuth) string
+		confKey     string
+		usernameKey string
+		passwordKey string
+	}{
+		{infra.Cluster, "route", authconf.RenderClusterAuthConf, authconf.ClusterConfKey, authconf.ClusterUsernameKey, authconf.ClusterPasswordKey},
+		{infra.Gateway, "gateway", authconf.RenderGatewayAuthConf, authconf.GatewayConfKey, authconf.GatewayUsernameKey, authconf.GatewayPasswordKey},
+	}
+
+	for _, b := range blocks {
+		if b.endpoint == nil {
+			continue
+		}
+
+		username := b.endpoint.Username
+		if username == "" {
+			username = b.defaultUser
+		}
+
+		password, err := r.getInfraPassword(ctx, b.endpoint.Password, authConfig.Namespace, existing.Data[b.passwordKey])
+		if err != nil {
+			return err
+		}
+
+		conf := b.render(authconf.InfraAuth{
+			Username: username,
+			Password: password,
+			Timeout:  b.endpoint.Timeout,
+		})
+		log.V(debugLevel).Info("Rendered infra auth config", "step", "render-infra-auth", "config", authconf.Redact(conf))
+
+		secret.Data[b.confKey] = []byte(conf)
+		secret.Data[b.usernameKey] = []byte(username)
+		secret.Data[b.passwordKey] = []byte(password)
+	}
+
+	natslabels.Stamp(secret, authConfigOwners(authConfig))
+	if err := authConfigTracking.SetOwner(authConfig, secret, r.Scheme); err != nil {
+		return err
+	}
+
+	if err := secrets.ApplyManaged(ctx, r.Client, secret); err != nil {
+		return fmt.Errorf("failed to apply infra auth secret: %w", err)
+	}
+	log.Info("Applied infra auth secret", "step", "apply-infra-auth", "secret", secret.Namespace+"/"+secret.Name)
+
+	return nil
+}
+
+// getInfraPassword resolves a route or gateway password, keeping a generated one stable
+// across reconciles while it satisfies the password policy
+func (r *NatsAuthConfigReconciler) getInfraPassword(ctx context.Context, source *natsv1alpha1.PasswordSource, namespace string, current []byte) (string, error) {
+	policy := passwordPolicy(r.PasswordPolicy, source)
+	if source != nil && source.SecretRef != nil {
+		password, _, err := readPasswordSecret(ctx, r.Client, source.SecretRef, namespace)
+		if err != nil {
+			return "", err
+		}
+		if err := policy.CheckEntropy(password); err != nil {
+			return "", fmt.Errorf("password in secret %s rejected: %w", source.SecretRef.ObjectKey(namespace), err)
+		}
+		return password, nil
+	}
+
+	password, err := resolveGeneratedPassword(random.OrDefault(r.Random), policy, string(current))
+	if err != nil {
+		return "", fmt.Errorf("failed to generate password: %w", err)
+	}
+	return password, nil
+}
+
+// deleteCrossNamespaceObject deletes an object the NatsAuthConfig wrote outside its namespace,
+// provided its tracking labels show it belongs to this NatsAuthConfig
+func (r *NatsAuthConfigReconciler) deleteCrossNamespaceObject(ctx context.Context, authConfig *natsv1alpha1.NatsAuthConfig, obj client.Object) error {
+	if obj.GetNamespace() == authConfig.Namespace {
+		return nil
+	}
+
+	if err := r.Get(ctx, client.ObjectKeyFromObject(obj), obj); err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to get %s/%s: %w", obj.GetNamespace(), obj.GetName(), err)
+	}
+
+	if obj.GetLabels()[authConfigNameLabel] != authConfig.Name || obj.GetLabels()[authConfigNamespaceLabel] != authConfig.Namespace {
+		return nil
+	}
+
+	if err := r.Delete(ctx, obj); err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete %s/%s: %w", obj.GetNamespace(), obj.GetName(), err)
+	}
+	return nil
+}
+
+// operatorSigningKeySecretName returns the Secret holding the operator signing key seed
+func operatorSigningKeySecretName(authConfig *natsv1alpha1.NatsAuthConfig) string {
+	return fmt.Sprintf("%s-operator-signing-key", authConfig.Name)
+}
+
+// operatorSeedSecretName returns the name of the Secret holding the generated operator seed
+func operatorSeedSecretName(authConfig *natsv1alpha1.NatsAuthConfig) string {
+	return fmt.Sprintf("%s-operator-seed", authConfig.Name)
+}
+
+// getOrCreateOperatorSigningKey returns the public key of the operator signing key used
+// to sign accounts under strictSigningKeyUsage, creating and storing its seed if needed
+func (r *NatsAuthConfigReconciler) getOrCreateOperatorSigningKey(ctx context.Context, authConfig *natsv1alpha1.NatsAuthConfig) (string, error) {
+	key := client.ObjectKey{Namespace: authConfig.Namespace, Name: operatorSigningKeySecretName(authConfig)}
+	secret, _, err := secrets.Get(ctx, r.Client, key)
+	if err != nil {
+		return "", err
+	}
+	if pubKey := publicKeyFromSeed(secret.Data[operatorSigningSeedKey]); pubKey != "" {
+		return pubKey, nil
+	}
+
+	signingKP, err := random.OrDefault(r.Random).KeyPair(nkeys.PrefixByteOperator)
+	if err != nil {
+		return "", err
+	}
+	seed, err := signingKP.Seed()
+	if err != nil {
+		return "", err
+	}
+
+	secret = secrets.New(key.Namespace, key.Name, map[string][]byte{
+		operatorSigningSeedKey: seed,
+	})
+	secret.Labels = seedSecretLabels(nkeys.PrefixByteOperator)
+	protectSeedSecret(secret, r.SecretAccess)
+	natslabels.Stamp(secret, authConfigOwners(authConfig))
+	if err := controllerutil.SetControllerReference(authConfig, secret, r.Scheme); err != nil {
+		return "", err
+	}
+	if err := resolver.ApplySecret(ctx, r.Client, secret); err != nil {
+		return "", fmt.Errorf("failed to store operator signing key: %w", err)
+	}
+
+	return signingKP.PublicKey()
+}
+
 func (r *NatsAuthConfigReconciler) handleDeletion(ctx context.Context, authConfig *natsv1alpha1.NatsAuthConfig) (ctrl.Result, error) {
 	if controllerutil.ContainsFinalizer(authConfig, natsAuthConfigFinalizer) {
-		// Cleanup logic here if needed
+		// Owner references cannot cross namespaces, so remove such objects ourselves
+		namespace := authConfig.Spec.ServerAuthConfig.Namespace
+		if authConfig.Spec.InfraAuth != nil {
+			secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: infraAuthSecretName(authConfig)}}
+			if err := r.deleteCrossNamespaceObject(ctx, authConfig, secret); err != nil {
+				return ctrl.Result{}, err
+			}
+		}
+		if authConfig.Spec.Bootstrap != nil {
+			cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: bootstrapConfigMapName(authConfig)}}
+			if err := r.deleteCrossNamespaceObject(ctx, authConfig, cm); err != nil {
+				return ctrl.Result{}, err
+			}
+		}
+		if authConfig.Spec.ServerAuthConfig.DeletionPolicy == natsv1alpha1.DeletionPolicyDelete {
+			var obj client.Object = &corev1.ConfigMap{}
+			if serverAuthConfigType(authConfig) == "Secret" {
+				obj = &corev1.Secret{}
+			}
+			obj.SetNamespace(namespace)
+			obj.SetName(authConfig.Spec.ServerAuthConfig.Name)
+			if err := r.deleteCrossNamespaceObject(ctx, authConfig, obj); err != nil {
+				return ctrl.Result{}, err
+			}
+			chunks, err := r.listConfigChunks(ctx, authConfig)
+			if err != nil {
+				return ctrl.Result{}, err
+			}
+			for _, chunk := range chunks {
+				if err := r.deleteCrossNamespaceObject(ctx, authConfig, chunk); err != nil {
+					return ctrl.Result{}, err
+				}
+			}
+		}
+
 		controllerutil.RemoveFinalizer(authConfig, natsAuthConfigFinalizer)
 		if err := r.Update(ctx, authConfig); err != nil {
 			return ctrl.Result{}, err
@@ -415,13 +1495,71 @@ func (r *NatsAuthConfigReconciler) updateCondition(authConfig *natsv1alpha1.Nats
 	if !found {
 		authConfig.Status.Conditions = append(authConfig.Status.Conditions, condition)
 	}
+	if condition.Type == "Ready" {
+		authConfig.Status.Phase = readyPhase(condition)
+		authConfig.Status.Reason = natsv1alpha1.ReasonCode(condition.Reason)
+		authConfig.Status.Message = condition.Message
+	}
+}
+
+// recordUnsynced marks a NatsAuthConfig whose changes were not written before the operator
+// stopped, so the state is visible until the next operator instance reconciles it
+func (r *NatsAuthConfigReconciler) recordUnsynced(ctx context.Context, key client.ObjectKey, reason string) error {
+	authConfig := &natsv1alpha1.NatsAuthConfig{}
+	if err := r.Get(ctx, key, authConfig); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	patch := client.MergeFrom(authConfig.DeepCopy())
+	r.updateCondition(authConfig, metav1.Condition{
+		Type:    "Ready",
+		Status:  metav1.ConditionFalse,
+		Reason:  string(natsv1alpha1.ReasonShutdownPending),
+		Message: "the operator stopped before the server auth config was written: " + reason,
+	})
+	return r.Status().Patch(ctx, authConfig, patch)
 }
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *NatsAuthConfigReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	return ctrl.NewControllerManagedBy(mgr).
-		For(&natsv1alpha1.NatsAuthConfig{}).
-		Owns(&corev1.Secret{}).
+	r.flush = newShutdownFlush(r.ShutdownTimeout, r.recordUnsynced)
+	if err := mgr.Add(r.flush); err != nil {
+		return err
+	}
+	for _, obj := range []client.Object{&natsv1alpha1.NatsAccount{}, &natsv1alpha1.NatsUser{}} {
+		if err := mgr.GetFieldIndexer().IndexField(context.Background(), obj, authConfigIndex, indexByAuthConfig); err != nil {
+			return err
+		}
+	}
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &natsv1alpha1.NatsAuthConfig{}, serverAuthConfigIndex, indexAuthConfigByServerConfig); err != nil {
+		return err
+	}
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &natsv1alpha1.NatsAuthConfig{}, operatorSeedIndex, indexAuthConfigByOperatorSeed); err != nil {
+		return err
+	}
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &natsv1alpha1.NatsAuthConfig{}, templatesConfigMapIndex, indexAuthConfigByTemplates); err != nil {
+		return err
+	}
+
+	// Spec changes reconcile right away; annotation changes (e.g. a manual trigger) and
+	// child changes, such as a re-signed account JWT, are batched over the debounce window.
+	// NatsAuthConfigs sharing a server auth config are requeued when one of them changes.
+	// Under restricted Secret access Secrets cannot be watched; they are checked on resync.
+	b := ctrl.NewControllerManagedBy(mgr).
+		For(&natsv1alpha1.NatsAuthConfig{}, builder.WithPredicates(predicate.Or(predicate.GenerationChangedPredicate{}, deletingPredicate)))
+	if r.SecretAccess.WatchesSecrets() {
+		b = b.Owns(&corev1.Secret{}).
+			Watches(&corev1.Secret{}, handler.EnqueueRequestsFromMapFunc(r.findAuthConfigForServerConfig)).
+			Watches(&corev1.Secret{}, handler.EnqueueRequestsFromMapFunc(r.findAuthConfigForOperatorSeed))
+	}
+	return b.
 		Owns(&corev1.ConfigMap{}).
+		Watches(&natsv1alpha1.NatsAuthConfig{}, newDebouncedHandler(r.Debounce, r.flush, requestForObject),
+			builder.WithPredicates(predicate.AnnotationChangedPredicate{})).
+		Watches(&natsv1alpha1.NatsAuthConfig{}, handler.EnqueueRequestsFromMapFunc(r.findAuthConfigsSharingServerConfig),
+			builder.WithPredicates(predicate.Or(predicate.GenerationChangedPredicate{}, deletingPredicate))).
+		Watches(&natsv1alpha1.NatsAccount{}, newDebouncedHandler(r.Debounce, r.flush, r.findAuthConfigForChild)).
+		Watches(&natsv1alpha1.NatsUser{}, newDebouncedHandler(r.Debounce, r.flush, r.findAuthConfigForChild)).
+		Watches(&corev1.ConfigMap{}, handler.EnqueueRequestsFromMapFunc(r.findAuthConfigForServerConfig)).
+		Watches(&corev1.ConfigMap{}, handler.EnqueueRequestsFromMapFunc(r.findAuthConfigForTemplates)).
 		Complete(r)
 }
@@ -0,0 +1,213 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	natsv1alpha1 "github.com/jradikk/nats-auth-operator/api/v1alpha1"
+)
+
+const (
+	// benchmarkAccounts and benchmarkUsers are the number of children the collect benchmarks render
+	benchmarkAccounts = 1000
+	benchmarkUsers    = 2000
+)
+
+// benchmarkAccountObjects returns n NatsAccounts of authConfig with their labelled JWT Secrets,
+// as the NatsAccount controller leaves them
+func benchmarkAccountObjects(authConfig *natsv1alpha1.NatsAuthConfig, n int) []client.Object {
+	objs := make([]client.Object, 0, 2*n)
+	for i := 0; i < n; i++ {
+		account := testAccount(fmt.Sprintf("account-%d", i))
+		account.Status.AccountID = fmt.Sprintf("A%055d", i)
+		account.Status.JWTSecretRef = natsv1alpha1.SecretRef{Namespace: account.Namespace, Name: account.Name + "-jwt"}
+		secret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: account.Namespace, Name: account.Status.JWTSecretRef.Name, Labels: authConfigSecretLabels(authConfig)},
+			Data:       map[string][]byte{"account.jwt": []byte("jwt-of-" + account.Name)},
+		}
+		objs = append(objs, account, secret)
+	}
+	return objs
+}
+
+// benchmarkTokenUserObjects returns n token NatsUsers of authConfig with their credentials Secrets
+func benchmarkTokenUserObjects(n int) []client.Object {
+	objs := make([]client.Object, 0, 2*n)
+	for i := 0; i < n; i++ {
+		user := testUser(fmt.Sprintf("user-%d", i), natsv1alpha1.UserAuthTypeToken, "")
+		user.Status.SecretRef = natsv1alpha1.SecretRef{Namespace: user.Namespace, Name: user.Name + "-creds"}
+		secret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: user.Namespace, Name: user.Status.SecretRef.Name},
+			Data:       map[string][]byte{"USERNAME": []byte(user.Name), "PASSWORD": []byte("password")},
+		}
+		objs = append(objs, user, secret)
+	}
+	return objs
+}
+
+func TestDuplicateTokenUsername(t *testing.T) {
+	authConfig := testAuthConfig(natsv1alpha1.AuthModeToken)
+	var objs []client.Object
+	for i, name := range []string{"orders", "orders-copy"} {
+		user := testUser(name, natsv1alpha1.UserAuthTypeToken, "")
+		user.CreationTimestamp = metav1.NewTime(time.Now().Add(time.Duration(i-2) * time.Hour))
+		user.Spec.Username = "orders"
+		user.Status.SecretRef = natsv1alpha1.SecretRef{Namespace: user.Namespace, Name: name + "-creds"}
+		objs = append(objs, user, &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: user.Namespace, Name: name + "-creds", Labels: map[string]string{userNameLabel: name, userNamespaceLabel: user.Namespace}},
+			Data:       map[string][]byte{"USERNAME": []byte("orders"), "PASSWORD": []byte("password-of-" + name)},
+		})
+	}
+	c, scheme := newTestClient(append(objs, authConfig)...)
+	recorder := record.NewFakeRecorder(10)
+	_, _, users := testReconcilers(c, scheme)
+	r := &NatsAuthConfigReconciler{Client: c, Scheme: scheme, Recorder: recorder}
+
+	// The user claiming the username last is left out of the config
+	collected, _, err := r.collectTokenUsers(context.Background(), authConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(collected) != 1 || collected[0].Password != "password-of-orders" {
+		t.Errorf("collectTokenUsers() = %+v, want only the user orders", collected)
+	}
+	if events := drainEvents(recorder); len(events) != 1 || !strings.Contains(events[0], "DuplicateUsername") || !strings.Contains(events[0], "apps/orders-copy") {
+		t.Errorf("collectTokenUsers() recorded %v, want a DuplicateUsername event for apps/orders-copy", events)
+	}
+
+	// and reported the conflict with the user holding the username
+	duplicate := objs[2].(*natsv1alpha1.NatsUser)
+	mustReconcile(t, users, duplicate)
+	mustGet(t, c, duplicate)
+	ready := meta.FindStatusCondition(duplicate.Status.Conditions, "Ready")
+	if ready == nil || ready.Status != metav1.ConditionFalse || ready.Reason != string(natsv1alpha1.ReasonUsernameConflict) || !strings.Contains(ready.Message, "apps/orders") {
+		t.Errorf("Reconcile() Ready condition = %+v, want UsernameConflict with apps/orders", ready)
+	}
+	holder := objs[0].(*natsv1alpha1.NatsUser)
+	mustReconcile(t, users, holder)
+	mustGet(t, c, holder)
+	wantReady(t, holder, holder.Status.Conditions)
+}
+
+func TestChildrenSummary(t *testing.T) {
+	authConfig := testAuthConfig(natsv1alpha1.AuthModeJWT)
+	earlier := metav1.NewTime(time.Now().Add(-time.Hour))
+	later := metav1.NewTime(time.Now())
+	condition := func(status metav1.ConditionStatus, message string, at metav1.Time) []metav1.Condition {
+		return []metav1.Condition{{Type: "Ready", Status: status, Reason: "Test", Message: message, LastTransitionTime: at}}
+	}
+
+	ready := testAccount("orders")
+	ready.Status.Conditions = condition(metav1.ConditionTrue, "", earlier)
+	failing := testAccount("billing")
+	failing.Status.Conditions = condition(metav1.ConditionFalse, "account failed", earlier)
+	jwtUser := testUser("orders-app", natsv1alpha1.UserAuthTypeJWT, "orders")
+	inheriting := testUser("billing-app", natsv1alpha1.UserAuthTypeInherit, "billing")
+	inheriting.Status.Conditions = condition(metav1.ConditionFalse, "user failed", later)
+	tokenUser := testUser("legacy", natsv1alpha1.UserAuthTypeToken, "")
+	elsewhere := testAccount("elsewhere")
+	elsewhere.Spec.AuthConfigRef.Name = "other"
+	c, scheme := newTestClient(authConfig, ready, failing, jwtUser, inheriting, tokenUser, elsewhere)
+	r := &NatsAuthConfigReconciler{Client: c, Scheme: scheme}
+
+	if err := r.updateChildrenSummary(context.Background(), authConfig); err != nil {
+		t.Fatal(err)
+	}
+	want := natsv1alpha1.ChildrenSummary{
+		Accounts:        2,
+		ReadyAccounts:   1,
+		Users:           3,
+		UsersByAuthType: map[string]int32{"jwt": 2, "token": 1},
+		LastError:       "NatsUser apps/billing-app: user failed",
+	}
+	if !reflect.DeepEqual(authConfig.Status.Children, want) {
+		t.Errorf("updateChildrenSummary() = %+v, want %+v", authConfig.Status.Children, want)
+	}
+}
+
+func TestServerAuthConfigDeletionPolicy(t *testing.T) {
+	tests := []struct {
+		name        string
+		policy      natsv1alpha1.DeletionPolicy
+		namespace   string
+		wantOwner   bool
+		wantDeleted bool
+	}{
+		{name: "Retain", policy: natsv1alpha1.DeletionPolicyRetain, namespace: "nats-server"},
+		// The garbage collector deletes it through the owner reference
+		{name: "Delete in the same namespace", policy: natsv1alpha1.DeletionPolicyDelete, namespace: "nats", wantOwner: true},
+		{name: "Delete in another namespace", policy: natsv1alpha1.DeletionPolicyDelete, namespace: "nats-server", wantDeleted: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			authConfig := testAuthConfig(natsv1alpha1.AuthModeJWT)
+			authConfig.Spec.ServerAuthConfig.Namespace = tt.namespace
+			authConfig.Spec.ServerAuthConfig.DeletionPolicy = tt.policy
+			c, scheme := newTestClient(authConfig)
+			r, _, _ := testReconcilers(c, scheme)
+			ctx := context.Background()
+
+			mustReconcile(t, r, authConfig)
+			secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: tt.namespace, Name: "nats-auth"}}
+			mustGet(t, c, secret)
+			if secret.Labels[managedByLabel] != managedByValue || secret.Labels[authConfigNameLabel] != "auth" || secret.Labels[authConfigNamespaceLabel] != "nats" {
+				t.Errorf("Reconcile() labelled the server auth config %v, want the operator and nats/auth", secret.Labels)
+			}
+			if owner := metav1.GetControllerOf(secret); (owner != nil && owner.UID == authConfig.UID) != tt.wantOwner {
+				t.Errorf("Reconcile() set controller %v, want an owner reference %v", owner, tt.wantOwner)
+			}
+
+			mustGet(t, c, authConfig)
+			if err := c.Delete(ctx, authConfig); err != nil {
+				t.Fatal(err)
+			}
+			mustReconcile(t, r, authConfig)
+			if err := c.Get(ctx, client.ObjectKeyFromObject(secret), secret); errors.IsNotFound(err) != tt.wantDeleted {
+				t.Errorf("server auth config after the NatsAuthConfig was deleted: %v, want deleted %v", err, tt.wantDeleted)
+			}
+		})
+	}
+}
+
+func BenchmarkCollectAccountJWTs(b *testing.B) {
+	authConfig := testAuthConfig(natsv1alpha1.AuthModeJWT)
+	c, scheme := newTestClient(benchmarkAccountObjects(authConfig, benchmarkAccounts)...)
+
+	// Restricted Secret access cannot list, so it reads each account JWT Secret on its own
+	for _, access := range []SecretAccess{SecretAccessCluster, SecretAccessRestricted} {
+		r := &NatsAuthConfigReconciler{Client: c, Scheme: scheme, SecretAccess: access}
+		b.Run(string(access), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				accounts, err := r.collectAccountJWTs(context.Background(), authConfig)
+				if err != nil || len(accounts) != benchmarkAccounts {
+					b.Fatalf("collectAccountJWTs() = %d accounts, %v, want %d", len(accounts), err, benchmarkAccounts)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkCollectTokenUsers(b *testing.B) {
+	authConfig := testAuthConfig(natsv1alpha1.AuthModeToken)
+	c, scheme := newTestClient(benchmarkTokenUserObjects(benchmarkUsers)...)
+	r := &NatsAuthConfigReconciler{Client: c, Scheme: scheme}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		users, _, err := r.collectTokenUsers(context.Background(), authConfig)
+		if err != nil || len(users) != benchmarkUsers {
+			b.Fatalf("collectTokenUsers() = %d users, %v, want %d", len(users), err, benchmarkUsers)
+		}
+	}
+}
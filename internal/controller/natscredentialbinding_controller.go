@@ -0,0 +1,394 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	natsv1alpha1 "github.com/jradikk/nats-auth-operator/api/v1alpha1"
+	jwtpkg "github.com/jradikk/nats-auth-operator/internal/jwt"
+)
+
+const (
+	natsCredentialBindingFinalizer = "nats.jradikk/credentialbinding-finalizer"
+
+	// credsHashAnnotation is stamped on the workload pod template to roll it out on credential changes
+	credsHashAnnotation = "nats.jradikk/creds-hash"
+
+	// pausedByAnnotation marks a Deployment paused by a NatsCredentialBinding
+	pausedByAnnotation = "nats.jradikk/paused-by"
+)
+
+// NatsCredentialBindingReconciler reconciles a NatsCredentialBinding object
+type NatsCredentialBindingReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+
+	// Resync controls periodic requeueing after a successful reconcile
+	Resync ResyncConfig
+
+	// Settings loads the operator-wide defaults
+	Settings *SettingsLoader
+
+	// SecretAccess restricted stops listing and watching Secrets; cluster when empty
+	SecretAccess SecretAccess
+}
+
+// +kubebuilder:rbac:groups=nats.jradikk,resources=natscredentialbindings,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=nats.jradikk,resources=natscredentialbindings/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=nats.jradikk,resources=natscredentialbindings/finalizers,verbs=update
+// +kubebuilder:rbac:groups=nats.jradikk,resources=natsusers,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch
+// +kubebuilder:rbac:groups=apps,resources=deployments;statefulsets,verbs=get;list;watch;update;patch
+
+func (r *NatsCredentialBindingReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	// Fetch the NatsCredentialBinding instance
+	binding := &natsv1alpha1.NatsCredentialBinding{}
+	if err := r.Get(ctx, req.NamespacedName, binding); err != nil {
+		if errors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+
+	ctx, log := reconcileLogger(ctx, "NatsCredentialBinding", binding)
+	ctx, log = withLogValues(ctx, "user", r.userKey(binding).String(), "workload", string(binding.Spec.WorkloadRef.Kind)+"/"+binding.Spec.WorkloadRef.Name)
+
+	// Handle deletion
+	if !binding.ObjectMeta.DeletionTimestamp.IsZero() {
+		return r.handleDeletion(ctx, binding)
+	}
+
+	// Add finalizer if not present
+	if !controllerutil.ContainsFinalizer(binding, natsCredentialBindingFinalizer) {
+		controllerutil.AddFinalizer(binding, natsCredentialBindingFinalizer)
+		if err := r.Update(ctx, binding); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+	ctx = withStatusBase(ctx, binding)
+
+	// A paused resource is left as it is until spec.paused is unset
+	if paused, err := reconcilePaused(ctx, r.Client, binding, binding.Spec.Paused, binding.Status.Conditions, func(c metav1.Condition) {
+		r.updateCondition(binding, c)
+	}); paused || err != nil {
+		return ctrl.Result{}, err
+	}
+
+	now := metav1.Now()
+	binding.Status.LastReconciled = &now
+	binding.Status.ObservedGeneration = binding.Generation
+
+	// Verify the credentials before touching the workload
+	secret, verifyErr := r.verifyCredentials(ctx, binding)
+	if verifyErr != nil {
+		log.Info("Credentials not ready, holding workload rollout", "reason", verifyErr.Error())
+		if err := r.holdWorkload(ctx, binding); err != nil && !errors.IsNotFound(err) {
+			return ctrl.Result{}, err
+		}
+		r.updateCondition(binding, metav1.Condition{
+			Type:    "Ready",
+			Status:  metav1.ConditionFalse,
+			Reason:  "CredentialsNotReady",
+			Message: verifyErr.Error(),
+		})
+		if err := patchStatus(ctx, r.Client, binding); err != nil {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{RequeueAfter: 30 * time.Second}, nil
+	}
+
+	credsHash := hashSecretData(secret.Data)
+	if err := r.releaseWorkload(ctx, binding, credsHash); err != nil {
+		if !errors.IsNotFound(err) {
+			return ctrl.Result{}, err
+		}
+		r.updateCondition(binding, metav1.Condition{
+			Type:    "Ready",
+			Status:  metav1.ConditionFalse,
+			Reason:  "WorkloadNotFound",
+			Message: fmt.Sprintf("%s %q not found", binding.Spec.WorkloadRef.Kind, binding.Spec.WorkloadRef.Name),
+		})
+		if err := patchStatus(ctx, r.Client, binding); err != nil {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{RequeueAfter: time.Minute}, nil
+	}
+
+	binding.Status.SecretRef = natsv1alpha1.SecretRef{
+		Name:      secret.Name,
+		Namespace: secret.Namespace,
+	}
+	binding.Status.CredentialsHash = credsHash
+	r.updateCondition(binding, metav1.Condition{
+		Type:    "Ready",
+		Status:  metav1.ConditionTrue,
+		Reason:  "CredentialsVerified",
+		Message: "Credentials Secret exists and passed verification",
+	})
+
+	if err := patchStatus(ctx, r.Client, binding); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	log.Info("NatsCredentialBinding reconciled successfully", "secret", secret.Name)
+
+	return r.Settings.resync(ctx, r.Resync).Result(binding.Spec.ResyncInterval), nil
+}
+
+// verifyCredentials checks that the referenced NatsUser is ready and its credentials Secret is valid
+func (r *NatsCredentialBindingReconciler) verifyCredentials(ctx context.Context, binding *natsv1alpha1.NatsCredentialBinding) (*corev1.Secret, error) {
+	user := &natsv1alpha1.NatsUser{}
+	if err := r.Get(ctx, r.userKey(binding), user); err != nil {
+		return nil, fmt.Errorf("failed to get NatsUser: %w", err)
+	}
+
+	if user.Status.State != natsv1alpha1.UserStateReady {
+		return nil, fmt.Errorf("NatsUser %q is not ready yet", user.Name)
+	}
+
+	if user.Status.SecretRef.Name == "" {
+		return nil, fmt.Errorf("NatsUser %q has no credentials Secret yet", user.Name)
+	}
+
+	if user.Status.SecretRef.Namespace != "" && user.Status.SecretRef.Namespace != binding.Namespace {
+		return nil, fmt.Errorf("credentials Secret must live in namespace %q to be mounted by the workload", binding.Namespace)
+	}
+
+	secret := &corev1.Secret{}
+	if err := r.Get(ctx, client.ObjectKey{Namespace: binding.Namespace, Name: user.Status.SecretRef.Name}, secret); err != nil {
+		return nil, fmt.Errorf("failed to get credentials Secret: %w", err)
+	}
+
+	if len(secret.Data["USERNAME"]) > 0 || len(secret.Data["PASSWORD"]) > 0 {
+		if len(secret.Data["USERNAME"]) == 0 || len(secret.Data["PASSWORD"]) == 0 {
+			return nil, fmt.Errorf("credentials Secret %q is missing USERNAME or PASSWORD", secret.Name)
+		}
+		return secret, nil
+	}
+
+	if err := jwtpkg.VerifyCredentials(secret.Data, user.Status.PublicKey); err != nil {
+		return nil, fmt.Errorf("credentials Secret failed verification: %w", err)
+	}
+
+	return secret, nil
+}
+
+// holdWorkload pauses the Deployment rollout while the credentials are not ready
+func (r *NatsCredentialBindingReconciler) holdWorkload(ctx context.Context, binding *natsv1alpha1.NatsCredentialBinding) error {
+	if !binding.Spec.PauseRollout || binding.Spec.WorkloadRef.Kind != natsv1alpha1.WorkloadKindDeployment {
+		return nil
+	}
+
+	deployment := &appsv1.Deployment{}
+	if err := r.Get(ctx, client.ObjectKey{Namespace: binding.Namespace, Name: binding.Spec.WorkloadRef.Name}, deployment); err != nil {
+		return err
+	}
+
+	if deployment.Spec.Paused {
+		return nil
+	}
+
+	patch := client.MergeFrom(deployment.DeepCopy())
+	deployment.Spec.Paused = true
+	if deployment.Annotations == nil {
+		deployment.Annotations = make(map[string]string)
+	}
+	deployment.Annotations[pausedByAnnotation] = binding.Name
+
+	return r.Patch(ctx, deployment, patch)
+}
+
+// releaseWorkload resumes a Deployment paused by this binding and stamps the credentials hash
+func (r *NatsCredentialBindingReconciler) releaseWorkload(ctx context.Context, binding *natsv1alpha1.NatsCredentialBinding, credsHash string) error {
+	var obj client.Object
+	var template *corev1.PodTemplateSpec
+
+	switch binding.Spec.WorkloadRef.Kind {
+	case natsv1alpha1.WorkloadKindDeployment:
+		deployment := &appsv1.Deployment{}
+		obj, template = deployment, &deployment.Spec.Template
+	case natsv1alpha1.WorkloadKindStatefulSet:
+		statefulSet := &appsv1.StatefulSet{}
+		obj, template = statefulSet, &statefulSet.Spec.Template
+	default:
+		return fmt.Errorf("unsupported workload kind: %s", binding.Spec.WorkloadRef.Kind)
+	}
+
+	if err := r.Get(ctx, client.ObjectKey{Namespace: binding.Namespace, Name: binding.Spec.WorkloadRef.Name}, obj); err != nil {
+		return err
+	}
+
+	patch := client.MergeFrom(obj.DeepCopyObject().(client.Object))
+	changed := false
+
+	if deployment, ok := obj.(*appsv1.Deployment); ok && deployment.Annotations[pausedByAnnotation] == binding.Name {
+		deployment.Spec.Paused = false
+		delete(deployment.Annotations, pausedByAnnotation)
+		changed = true
+	}
+
+	if binding.Spec.RestartOnChange && template.Annotations[credsHashAnnotation] != credsHash {
+		if template.Annotations == nil {
+			template.Annotations = make(map[string]string)
+		}
+		template.Annotations[credsHashAnnotation] = credsHash
+		changed = true
+	}
+
+	if !changed {
+		return nil
+	}
+
+	return r.Patch(ctx, obj, patch)
+}
+
+func (r *NatsCredentialBindingReconciler) userKey(binding *natsv1alpha1.NatsCredentialBinding) client.ObjectKey {
+	namespace := binding.Spec.UserRef.Namespace
+	if namespace == "" {
+		namespace = binding.Namespace
+	}
+	return client.ObjectKey{Namespace: namespace, Name: binding.Spec.UserRef.Name}
+}
+
+func (r *NatsCredentialBindingReconciler) handleDeletion(ctx context.Context, binding *natsv1alpha1.NatsCredentialBinding) (ctrl.Result, error) {
+	if controllerutil.ContainsFinalizer(binding, natsCredentialBindingFinalizer) {
+		// Resume a Deployment we paused so it isn't left stuck
+		if binding.Spec.WorkloadRef.Kind == natsv1alpha1.WorkloadKindDeployment {
+			deployment := &appsv1.Deployment{}
+			err := r.Get(ctx, client.ObjectKey{Namespace: binding.Namespace, Name: binding.Spec.WorkloadRef.Name}, deployment)
+			if err == nil && deployment.Annotations[pausedByAnnotation] == binding.Name {
+				patch := client.MergeFrom(deployment.DeepCopy())
+				deployment.Spec.Paused = false
+				delete(deployment.Annotations, pausedByAnnotation)
+				if err := r.Patch(ctx, deployment, patch); err != nil {
+					return ctrl.Result{}, err
+				}
+			} else if err != nil && !errors.IsNotFound(err) {
+				return ctrl.Result{}, err
+			}
+		}
+
+		controllerutil.RemoveFinalizer(binding, natsCredentialBindingFinalizer)
+		if err := r.Update(ctx, binding); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+	return ctrl.Result{}, nil
+}
+
+func (r *NatsCredentialBindingReconciler) updateCondition(binding *natsv1alpha1.NatsCredentialBinding, condition metav1.Condition) {
+	condition.LastTransitionTime = metav1.Now()
+	found := false
+	for i, c := range binding.Status.Conditions {
+		if c.Type == condition.Type {
+			binding.Status.Conditions[i] = condition
+			found = true
+			break
+		}
+	}
+	if !found {
+		binding.Status.Conditions = append(binding.Status.Conditions, condition)
+	}
+	if condition.Type == "Ready" {
+		binding.Status.Phase = readyPhase(condition)
+		binding.Status.Message = condition.Message
+	}
+}
+
+// findBindingsForUser maps a NatsUser to the bindings referencing it
+func (r *NatsCredentialBindingReconciler) findBindingsForUser(ctx context.Context, obj client.Object) []reconcile.Request {
+	bindingList := &natsv1alpha1.NatsCredentialBindingList{}
+	if err := r.List(ctx, bindingList); err != nil {
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for _, binding := range bindingList.Items {
+		key := r.userKey(&binding)
+		if key.Name == obj.GetName() && key.Namespace == obj.GetNamespace() {
+			requests = append(requests, reconcile.Request{
+				NamespacedName: types.NamespacedName{Namespace: binding.Namespace, Name: binding.Name},
+			})
+		}
+	}
+	return requests
+}
+
+// findBindingsForSecret maps a credentials Secret to the bindings that verified it
+func (r *NatsCredentialBindingReconciler) findBindingsForSecret(ctx context.Context, obj client.Object) []reconcile.Request {
+	bindingList := &natsv1alpha1.NatsCredentialBindingList{}
+	if err := r.List(ctx, bindingList, client.InNamespace(obj.GetNamespace())); err != nil {
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for _, binding := range bindingList.Items {
+		if binding.Status.SecretRef.Name == obj.GetName() {
+			requests = append(requests, reconcile.Request{
+				NamespacedName: types.NamespacedName{Namespace: binding.Namespace, Name: binding.Name},
+			})
+		}
+	}
+	return requests
+}
+
+// hashSecretData returns a stable hash of the Secret data
+func hashSecretData(data map[string][]byte) string {
+	keys := make([]string, 0, len(data))
+	for k := range data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	h := sha256.New()
+	for _, k := range keys {
+		h.Write([]byte(k))
+		h.Write([]byte{0})
+		h.Write(data[k])
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *NatsCredentialBindingReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	b := ctrl.NewControllerManagedBy(mgr).
+		For(&natsv1alpha1.NatsCredentialBinding{}).
+		Watches(&natsv1alpha1.NatsUser{}, handler.EnqueueRequestsFromMapFunc(r.findBindingsForUser))
+	if r.SecretAccess.WatchesSecrets() {
+		b = b.Watches(&corev1.Secret{}, handler.EnqueueRequestsFromMapFunc(r.findBindingsForSecret))
+	}
+	return b.Complete(r)
+}
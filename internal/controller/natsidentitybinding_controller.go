@@ -0,0 +1,333 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	natsv1alpha1 "github.com/jradikk/nats-auth-operator/api/v1alpha1"
+	"github.com/jradikk/nats-auth-operator/internal/hooks"
+	"github.com/jradikk/nats-auth-operator/internal/identity"
+	"github.com/jradikk/nats-auth-operator/internal/resolver"
+	"github.com/jradikk/nats-auth-operator/internal/subject"
+)
+
+const (
+	// identityBindingLabel marks the NatsUsers provisioned by a NatsIdentityBinding with its name
+	identityBindingLabel = "nats.jradikk/identity-binding"
+
+	// identitySubjectAnnotation records the subject of the identity a NatsUser was provisioned for
+	identitySubjectAnnotation = "nats.jradikk/identity-subject"
+
+	// defaultIdentityKey is the ConfigMap key the identity directory is read from
+	defaultIdentityKey = "identities.json"
+)
+
+// NatsIdentityBindingReconciler reconciles a NatsIdentityBinding object
+type NatsIdentityBindingReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+
+	// Recorder emits events when users are provisioned or removed; optional
+	Recorder record.EventRecorder
+}
+
+// +kubebuilder:rbac:groups=nats.jradikk,resources=natsidentitybindings,verbs=get;list;watch
+// +kubebuilder:rbac:groups=nats.jradikk,resources=natsidentitybindings/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=nats.jradikk,resources=natsusers,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+
+func (r *NatsIdentityBindingReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	binding := &natsv1alpha1.NatsIdentityBinding{}
+	if err := r.Get(ctx, req.NamespacedName, binding); err != nil {
+		if errors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+
+	ctx, log := reconcileLogger(ctx, "NatsIdentityBinding", binding)
+	ctx = withStatusBase(ctx, binding)
+
+	// A paused resource is left as it is until spec.paused is unset
+	if paused, err := reconcilePaused(ctx, r.Client, binding, binding.Spec.Paused, binding.Status.Conditions, func(c metav1.Condition) {
+		r.updateCondition(binding, c)
+	}); paused || err != nil {
+		return ctrl.Result{}, err
+	}
+
+	now := metav1.Now()
+	binding.Status.LastReconciled = &now
+
+	err := r.reconcileBinding(ctx, binding)
+	binding.Status.ObservedGeneration = binding.Generation
+	if err != nil {
+		reason, isPermanent := classifyError(err)
+		log.Error(err, "Failed to provision identity users", "reason", reason, "permanent", isPermanent)
+		r.updateCondition(binding, metav1.Condition{
+			Type:    "Ready",
+			Status:  metav1.ConditionFalse,
+			Reason:  string(reason),
+			Message: err.Error(),
+		})
+		if err := patchStatus(ctx, r.Client, binding); err != nil {
+			return ctrl.Result{}, err
+		}
+		return failureResult(err, isPermanent)
+	}
+
+	r.updateCondition(binding, metav1.Condition{
+		Type:    "Ready",
+		Status:  metav1.ConditionTrue,
+		Reason:  string(natsv1alpha1.ReasonReconcileSuccess),
+		Message: fmt.Sprintf("%d identities bound", binding.Status.UserCount),
+	})
+	if err := patchStatus(ctx, r.Client, binding); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	return ResyncConfig{}.Result(binding.Spec.Interval), nil
+}
+
+// reconcileBinding reads the identity source and makes the provisioned NatsUsers match the bound
+// identities: users of new members are created or updated, users of identities that left are deleted
+func (r *NatsIdentityBindingReconciler) reconcileBinding(ctx context.Context, binding *natsv1alpha1.NatsIdentityBinding) error {
+	log := log.FromContext(ctx)
+
+	identities, err := r.readIdentities(ctx, binding)
+	if err != nil {
+		return err
+	}
+	syncTime := metav1.Now()
+	binding.Status.LastSyncTime = &syncTime
+
+	matched := identity.Match(identities, binding.Spec.Subjects, binding.Spec.Groups)
+	desired := make(map[string]bool, len(matched))
+	var bound []natsv1alpha1.BoundIdentity
+	for _, id := range matched {
+		user, err := identityUser(binding, id)
+		if err != nil {
+			return permanent(natsv1alpha1.ReasonInvalidSpec, err)
+		}
+		if err := controllerutil.SetControllerReference(binding, user, r.Scheme); err != nil {
+			return err
+		}
+
+		existing := &natsv1alpha1.NatsUser{}
+		err = r.Get(ctx, client.ObjectKeyFromObject(user), existing)
+		switch {
+		case err == nil && !metav1.IsControlledBy(existing, binding):
+			log.Info("NatsUser exists and does not belong to this binding, skipping identity", "user", user.Name, "subject", id.Subject)
+			if r.Recorder != nil {
+				r.Recorder.Eventf(binding, corev1.EventTypeWarning, "UserConflict", "NatsUser %s exists and does not belong to this binding; identity %s is not provisioned", user.Name, id.Subject)
+			}
+			continue
+		case err != nil && !errors.IsNotFound(err):
+			return fmt.Errorf("failed to get NatsUser %s: %w", user.Name, err)
+		case errors.IsNotFound(err):
+			log.Info("Provisioning NatsUser for identity", "user", user.Name, "subject", id.Subject)
+			if r.Recorder != nil {
+				r.Recorder.Eventf(binding, corev1.EventTypeNormal, "UserProvisioned", "Provisioned NatsUser %s for identity %s", user.Name, id.Subject)
+			}
+		}
+
+		if err := resolver.ApplyObject(ctx, r.Client, user); err != nil {
+			return fmt.Errorf("failed to apply NatsUser %s: %w", user.Name, err)
+		}
+		desired[user.Name] = true
+		bound = append(bound, natsv1alpha1.BoundIdentity{Subject: id.Subject, User: user.Name})
+	}
+
+	// Users of identities that left the source or the binding are removed
+	userList := &natsv1alpha1.NatsUserList{}
+	if err := r.List(ctx, userList, client.InNamespace(binding.Namespace), client.MatchingLabels{identityBindingLabel: binding.Name}); err != nil {
+		return fmt.Errorf("failed to list provisioned NatsUsers: %w", err)
+	}
+	for i := range userList.Items {
+		user := &userList.Items[i]
+		if desired[user.Name] || !metav1.IsControlledBy(user, binding) {
+			continue
+		}
+		if err := r.Delete(ctx, user); err != nil && !errors.IsNotFound(err) {
+			return fmt.Errorf("failed to delete NatsUser %s: %w", user.Name, err)
+		}
+		idSubject := user.Annotations[identitySubjectAnnotation]
+		log.Info("Removed NatsUser of unbound identity", "user", user.Name, "subject", idSubject)
+		if r.Recorder != nil {
+			r.Recorder.Eventf(binding, corev1.EventTypeNormal, "UserRemoved", "Removed NatsUser %s of identity %s", user.Name, idSubject)
+		}
+	}
+
+	binding.Status.Identities = bound
+	binding.Status.UserCount = int32(len(bound))
+	return nil
+}
+
+// readIdentities reads the directory from the source of the binding
+func (r *NatsIdentityBindingReconciler) readIdentities(ctx context.Context, binding *natsv1alpha1.NatsIdentityBinding) ([]identity.Identity, error) {
+	source := binding.Spec.Source
+	switch {
+	case source.ConfigMap != nil && source.HTTP != nil:
+		return nil, permanent(natsv1alpha1.ReasonInvalidSpec, fmt.Errorf("source.configMap and source.http are mutually exclusive"))
+
+	case source.ConfigMap != nil:
+		key := source.ConfigMap.Key
+		if key == "" {
+			key = defaultIdentityKey
+		}
+		cm := &corev1.ConfigMap{}
+		name := binding.Namespace + "/" + source.ConfigMap.Name
+		if err := r.Get(ctx, client.ObjectKey{Namespace: binding.Namespace, Name: source.ConfigMap.Name}, cm); err != nil {
+			if errors.IsNotFound(err) {
+				return nil, &dependencyError{Kind: "ConfigMap", Name: name, Reason: "IdentitySourceNotFound", Message: "identity source not found"}
+			}
+			return nil, fmt.Errorf("failed to get identity source %s: %w", name, err)
+		}
+		data, ok := cm.Data[key]
+		if !ok {
+			return nil, &dependencyError{Kind: "ConfigMap", Name: name, Reason: "IdentitySourceNotFound", Message: fmt.Sprintf("key %q not found", key)}
+		}
+		identities, err := identity.Parse([]byte(data))
+		if err != nil {
+			return nil, permanent(natsv1alpha1.ReasonInvalidSpec, fmt.Errorf("identity source %s: %w", name, err))
+		}
+		return identities, nil
+
+	case source.HTTP != nil:
+		httpClient, err := hooks.NewHTTPClient([]byte(source.HTTP.CABundle), time.Duration(source.HTTP.TimeoutSeconds)*time.Second)
+		if err != nil {
+			return nil, permanent(natsv1alpha1.ReasonInvalidSpec, fmt.Errorf("invalid identity source: %w", err))
+		}
+		return (&identity.HTTPSource{URL: source.HTTP.URL, Client: httpClient}).Identities(ctx)
+	}
+	return nil, permanent(natsv1alpha1.ReasonInvalidSpec, fmt.Errorf("one of source.configMap and source.http is required"))
+}
+
+// identityUser returns the NatsUser provisioned for an identity, with the permission templates of
+// the binding rendered for it
+func identityUser(binding *natsv1alpha1.NatsIdentityBinding, id identity.Identity) (*natsv1alpha1.NatsUser, error) {
+	tmpl := binding.Spec.Template
+	accountRef := tmpl.AccountRef
+	user := &natsv1alpha1.NatsUser{
+		TypeMeta: metav1.TypeMeta{APIVersion: natsv1alpha1.GroupVersion.String(), Kind: "NatsUser"},
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   binding.Namespace,
+			Name:        identity.ResourceName(binding.Name, id),
+			Labels:      map[string]string{identityBindingLabel: binding.Name},
+			Annotations: map[string]string{identitySubjectAnnotation: id.Subject},
+		},
+		Spec: natsv1alpha1.NatsUserSpec{
+			AuthConfigRef:          binding.Spec.AuthConfigRef,
+			AuthType:               natsv1alpha1.UserAuthTypeJWT,
+			AccountRef:             &accountRef,
+			AllowedConnectionTypes: tmpl.AllowedConnectionTypes,
+			Limits:                 tmpl.Limits,
+			SigningKeyRole:         tmpl.SigningKeyRole,
+			Expiry:                 tmpl.Expiry,
+			Output:                 tmpl.Output,
+			RevokeOnDelete:         tmpl.RevokeOnDelete,
+		},
+	}
+
+	if p := tmpl.Permissions; p != nil {
+		rendered := &natsv1alpha1.Permissions{}
+		for _, field := range []struct {
+			name      string
+			templates []string
+			into      *[]string
+		}{
+			{"template.permissions.publishAllow", p.PublishAllow, &rendered.PublishAllow},
+			{"template.permissions.publishDeny", p.PublishDeny, &rendered.PublishDeny},
+			{"template.permissions.subscribeAllow", p.SubscribeAllow, &rendered.SubscribeAllow},
+			{"template.permissions.subscribeDeny", p.SubscribeDeny, &rendered.SubscribeDeny},
+		} {
+			subjects, err := identity.RenderList(field.templates, id)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", field.name, err)
+			}
+			if err := subject.ValidateList(field.name, subjects); err != nil {
+				return nil, err
+			}
+			*field.into = subjects
+		}
+		user.Spec.Permissions = rendered
+	}
+	return user, nil
+}
+
+func (r *NatsIdentityBindingReconciler) updateCondition(binding *natsv1alpha1.NatsIdentityBinding, condition metav1.Condition) {
+	condition.LastTransitionTime = metav1.Now()
+	found := false
+	for i, c := range binding.Status.Conditions {
+		if c.Type == condition.Type {
+			binding.Status.Conditions[i] = condition
+			found = true
+			break
+		}
+	}
+	if !found {
+		binding.Status.Conditions = append(binding.Status.Conditions, condition)
+	}
+	if condition.Type == "Ready" {
+		binding.Status.Phase = readyPhase(condition)
+		binding.Status.Reason = natsv1alpha1.ReasonCode(condition.Reason)
+		binding.Status.Message = condition.Message
+	}
+}
+
+// findBindingsForConfigMap maps a changed ConfigMap to the bindings reading their identities from it
+func (r *NatsIdentityBindingReconciler) findBindingsForConfigMap(ctx context.Context, obj client.Object) []reconcile.Request {
+	bindingList := &natsv1alpha1.NatsIdentityBindingList{}
+	if err := r.List(ctx, bindingList, client.InNamespace(obj.GetNamespace())); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to list NatsIdentityBindings for ConfigMap")
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for _, binding := range bindingList.Items {
+		if cm := binding.Spec.Source.ConfigMap; cm != nil && cm.Name == obj.GetName() {
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&binding)})
+		}
+	}
+	sort.Slice(requests, func(i, j int) bool { return requests[i].Name < requests[j].Name })
+	return requests
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *NatsIdentityBindingReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&natsv1alpha1.NatsIdentityBinding{}).
+		Owns(&natsv1alpha1.NatsUser{}).
+		Watches(&corev1.ConfigMap{}, handler.EnqueueRequestsFromMapFunc(r.findBindingsForConfigMap)).
+		Complete(r)
+}
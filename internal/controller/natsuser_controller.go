@@ -17,32 +17,90 @@ limitations under the License.
 package controller
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"slices"
 	"time"
 
+	"github.com/nats-io/nkeys"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/workqueue"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	natsv1alpha1 "github.com/jradikk/nats-auth-operator/api/v1alpha1"
+	"github.com/jradikk/nats-auth-operator/internal/audit"
+	"github.com/jradikk/nats-auth-operator/internal/authconf"
+	"github.com/jradikk/nats-auth-operator/internal/hooks"
 	jwtpkg "github.com/jradikk/nats-auth-operator/internal/jwt"
+	"github.com/jradikk/nats-auth-operator/internal/random"
+	"github.com/jradikk/nats-auth-operator/internal/resolver"
+	"github.com/jradikk/nats-auth-operator/internal/secrets"
 	"github.com/jradikk/nats-auth-operator/internal/token"
+	natslabels "github.com/jradikk/nats-auth-operator/pkg/labels"
 )
 
 const (
 	natsUserFinalizer = "nats.jradikk/user-finalizer"
+
+	// credsPathAnnotation records the recommended path of the mounted nats.creds file
+	credsPathAnnotation = "nats.jradikk/creds-path"
+
+	// mountSnippetKey holds the pod spec snippet in the companion ConfigMap
+	mountSnippetKey = "snippet.yaml"
+
+	// userNameLabel and userNamespaceLabel track the NatsUser owning a credentials Secret,
+	// since owner references cannot cross namespaces
+	userNameLabel      = "nats.jradikk/user-name"
+	userNamespaceLabel = "nats.jradikk/user-namespace"
 )
 
 // NatsUserReconciler reconciles a NatsUser object
 type NatsUserReconciler struct {
 	client.Client
 	Scheme *runtime.Scheme
+
+	// Resync controls periodic requeueing after a successful reconcile
+	Resync ResyncConfig
+
+	// Recorder emits events such as PermissionsUpdated
+	Recorder record.EventRecorder
+
+	// PasswordPolicy holds the operator-wide defaults for generated passwords
+	PasswordPolicy token.PasswordPolicy
+
+	// Settings loads the operator-wide defaults
+	Settings *SettingsLoader
+
+	// Audit records credential issuance; nil disables the audit log
+	Audit audit.Sink
+
+	// ClaimHooks mutates user claims before they are signed, ahead of the HTTP hooks
+	// configured on the NatsAuthConfig (optional)
+	ClaimHooks hooks.Mutator
+
+	// Random generates keys and passwords; crypto/rand when nil
+	Random random.Generator
+
+	// SecretAccess restricted stops listing and watching Secrets; cluster when empty
+	SecretAccess SecretAccess
+
+	// dependencyBackoff tracks per-user exponential backoff while dependencies are not ready
+	dependencyBackoff workqueue.RateLimiter
 }
 
 // +kubebuilder:rbac:groups=nats.jradikk,resources=natsusers,verbs=get;list;watch;create;update;patch;delete
@@ -50,11 +108,12 @@ type NatsUserReconciler struct {
 // +kubebuilder:rbac:groups=nats.jradikk,resources=natsusers/finalizers,verbs=update
 // +kubebuilder:rbac:groups=nats.jradikk,resources=natsauthconfigs,verbs=get;list;watch
 // +kubebuilder:rbac:groups=nats.jradikk,resources=natsaccounts,verbs=get;list;watch
+// +kubebuilder:rbac:groups=nats.jradikk,resources=natsaccounts/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
 
 func (r *NatsUserReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
-	log := log.FromContext(ctx)
-
 	// Fetch the NatsUser instance
 	user := &natsv1alpha1.NatsUser{}
 	if err := r.Get(ctx, req.NamespacedName, user); err != nil {
@@ -64,6 +123,8 @@ func (r *NatsUserReconciler) Reconcile(ctx context.Context, req ctrl.Request) (c
 		return ctrl.Result{}, err
 	}
 
+	ctx, log := reconcileLogger(ctx, "NatsUser", user)
+
 	// Handle deletion
 	if !user.ObjectMeta.DeletionTimestamp.IsZero() {
 		return r.handleDeletion(ctx, user)
@@ -76,13 +137,58 @@ func (r *NatsUserReconciler) Reconcile(ctx context.Context, req ctrl.Request) (c
 			return ctrl.Result{}, err
 		}
 	}
+	ctx = withStatusBase(ctx, user)
+
+	// A paused resource is left as it is until spec.paused is unset
+	if paused, err := reconcilePaused(ctx, r.Client, user, user.Spec.Paused, user.Status.Conditions, func(c metav1.Condition) {
+		r.updateCondition(user, c)
+	}); paused || err != nil {
+		return ctrl.Result{}, err
+	}
+
+	// Validate the spec
+	if err := user.Spec.Validate(); err != nil {
+		log.Error(err, "Invalid spec")
+		r.updateStatus(user, natsv1alpha1.UserStateError, err.Error())
+		r.updateCondition(user, metav1.Condition{
+			Type:    "Ready",
+			Status:  metav1.ConditionFalse,
+			Reason:  string(natsv1alpha1.ReasonInvalidSpec),
+			Message: err.Error(),
+		})
+		if err := patchStatus(ctx, r.Client, user); err != nil {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{RequeueAfter: permanentRetryInterval}, nil
+	}
+
+	// Load the operator-wide defaults
+	settings, err := r.Settings.Load(ctx)
+	if err != nil {
+		log.Error(err, "Failed to load operator settings")
+		return ctrl.Result{RequeueAfter: time.Minute}, err
+	}
 
 	// Get the referenced NatsAuthConfig
 	authConfig, err := r.getAuthConfig(ctx, user)
+	if err != nil && errors.IsNotFound(err) {
+		return r.setPending(ctx, req, user, &dependencyError{
+			Kind:    "NatsAuthConfig",
+			Name:    authConfigKey(user).String(),
+			Reason:  "AuthConfigNotFound",
+			Message: "not found",
+		})
+	}
 	if err != nil {
 		log.Error(err, "Failed to get NatsAuthConfig")
 		r.updateStatus(user, natsv1alpha1.UserStateError, err.Error())
-		if err := r.Status().Update(ctx, user); err != nil {
+		r.updateCondition(user, metav1.Condition{
+			Type:    "Ready",
+			Status:  metav1.ConditionFalse,
+			Reason:  string(natsv1alpha1.ReasonReconcileError),
+			Message: err.Error(),
+		})
+		if err := patchStatus(ctx, r.Client, user); err != nil {
 			return ctrl.Result{}, err
 		}
 		return ctrl.Result{RequeueAfter: time.Minute}, err
@@ -91,18 +197,46 @@ func (r *NatsUserReconciler) Reconcile(ctx context.Context, req ctrl.Request) (c
 	// Determine auth type
 	authType := user.Spec.AuthType
 	if authType == natsv1alpha1.UserAuthTypeInherit {
-		authType = natsv1alpha1.UserAuthType(authConfig.Spec.Mode)
+		authType = inheritedAuthType(effectiveMode(authConfig))
+	}
+
+	resolveAccountRef(user, authConfig, authType)
+
+	ctx, log = withLogValues(ctx, "authConfig", authConfigKey(user).String(), "authType", authType)
+	if hasAccount(user) {
+		ctx, log = withLogValues(ctx, "account", accountKey(user).String())
 	}
 
 	// Reconcile based on auth type
 	var reconcileErr error
 	switch authType {
 	case natsv1alpha1.UserAuthTypeJWT:
-		reconcileErr = r.reconcileJWTUser(ctx, user, authConfig)
+		reconcileErr = r.reconcileJWTUser(ctx, user, authConfig, settings)
+		if reconcileErr == nil && user.Spec.Purpose == natsv1alpha1.UserPurposeLeafNode {
+			reconcileErr = r.reconcileLeafNodeConfig(ctx, user)
+		}
+		if reconcileErr == nil && user.Spec.Output != nil && user.Spec.Output.MountSnippet {
+			reconcileErr = r.reconcileMountSnippet(ctx, user)
+		}
 	case natsv1alpha1.UserAuthTypeToken:
-		reconcileErr = r.reconcileTokenUser(ctx, user, authConfig)
+		if user.Spec.Purpose == natsv1alpha1.UserPurposeLeafNode || user.Spec.Purpose == natsv1alpha1.UserPurposeJetStreamController {
+			reconcileErr = permanent(natsv1alpha1.ReasonIncompatibleAuthMode,
+				fmt.Errorf("%s users require JWT auth, but NatsAuthConfig %s uses token mode", user.Spec.Purpose, authConfigKey(user)))
+			break
+		}
+		// Servers configured with an operator refuse users defined in the config, so a token user
+		// would get credentials that cannot connect
+		if mode := effectiveMode(authConfig); usesJWT(mode) {
+			reconcileErr = permanent(natsv1alpha1.ReasonIncompatibleAuthMode,
+				fmt.Errorf("token users cannot connect to servers configured with an operator, but NatsAuthConfig %s uses %s mode; use authType jwt", authConfigKey(user), mode))
+			break
+		}
+		reconcileErr = r.reconcileTokenUser(ctx, user, authConfig, settings)
 	default:
-		reconcileErr = fmt.Errorf("unsupported auth type: %s", authType)
+		reconcileErr = permanent(natsv1alpha1.ReasonInvalidSpec, fmt.Errorf("unsupported auth type: %s", authType))
+	}
+	if reconcileErr == nil {
+		reconcileErr = r.updateConnLimitCondition(ctx, user, authType)
 	}
 
 	// Update status
@@ -110,76 +244,214 @@ func (r *NatsUserReconciler) Reconcile(ctx context.Context, req ctrl.Request) (c
 	user.Status.LastReconciled = &now
 	user.Status.ObservedGeneration = user.Generation
 
+	if depErr, ok := asDependencyError(reconcileErr); ok {
+		return r.setPending(ctx, req, user, depErr)
+	}
+
 	if reconcileErr != nil {
-		log.Error(reconcileErr, "Failed to reconcile user")
+		reason, isPermanent := classifyError(reconcileErr)
+		log.Error(reconcileErr, "Failed to reconcile user", "reason", reason, "permanent", isPermanent)
 		r.updateStatus(user, natsv1alpha1.UserStateError, reconcileErr.Error())
 		r.updateCondition(user, metav1.Condition{
 			Type:    "Ready",
 			Status:  metav1.ConditionFalse,
-			Reason:  "ReconcileError",
+			Reason:  string(reason),
 			Message: reconcileErr.Error(),
 		})
-		if err := r.Status().Update(ctx, user); err != nil {
+		if err := patchStatus(ctx, r.Client, user); err != nil {
 			return ctrl.Result{}, err
 		}
-		return ctrl.Result{RequeueAfter: time.Minute}, reconcileErr
+		return failureResult(reconcileErr, isPermanent)
 	}
 
+	r.dependencyBackoff.Forget(req.NamespacedName)
+	r.updateCondition(user, metav1.Condition{
+		Type:    "DependenciesReady",
+		Status:  metav1.ConditionTrue,
+		Reason:  "DependenciesReady",
+		Message: "Referenced NatsAuthConfig and NatsAccount are ready",
+	})
 	r.updateStatus(user, natsv1alpha1.UserStateReady, "User reconciled successfully")
 	r.updateCondition(user, metav1.Condition{
 		Type:    "Ready",
 		Status:  metav1.ConditionTrue,
-		Reason:  "ReconcileSuccess",
+		Reason:  string(natsv1alpha1.ReasonReconcileSuccess),
 		Message: "NatsUser reconciled successfully",
 	})
 
-	if err := r.Status().Update(ctx, user); err != nil {
+	if err := patchStatus(ctx, r.Client, user); err != nil {
 		return ctrl.Result{}, err
 	}
 
 	log.Info("NatsUser reconciled successfully", "authType", authType)
 
-	return ctrl.Result{RequeueAfter: 5 * time.Minute}, nil
+	return renewalResult(r.Resync.withSettings(settings).Result(user.Spec.ResyncInterval), user, authConfig, settings), nil
 }
 
-func (r *NatsUserReconciler) reconcileJWTUser(ctx context.Context, user *natsv1alpha1.NatsUser, authConfig *natsv1alpha1.NatsAuthConfig) error {
+func (r *NatsUserReconciler) reconcileJWTUser(ctx context.Context, user *natsv1alpha1.NatsUser, authConfig *natsv1alpha1.NatsAuthConfig, settings *natsv1alpha1.NatsOperatorSettingsSpec) error {
 	log := log.FromContext(ctx)
 
-	// Validate that account reference is provided
-	if user.Spec.AccountRef == nil {
-		return fmt.Errorf("accountRef is required for JWT mode")
+	// Validate that the user has an account, set directly or through the NatsAuthConfig default
+	if !hasAccount(user) {
+		return permanent(natsv1alpha1.ReasonInvalidSpec, fmt.Errorf("accountRef is required for JWT mode when NatsAuthConfig %s has no defaultAccountRef", authConfigKey(user)))
 	}
 
 	// Get the referenced NatsAccount
 	account, err := r.getAccount(ctx, user)
 	if err != nil {
+		if errors.IsNotFound(err) {
+			return &dependencyError{
+				Kind:    "NatsAccount",
+				Name:    accountKey(user).String(),
+				Reason:  "AccountNotFound",
+				Message: "not found",
+			}
+		}
 		return fmt.Errorf("failed to get NatsAccount: %w", err)
 	}
 
 	// Wait for account to be ready
-	if account.Status.AccountID == "" {
-		return fmt.Errorf("NatsAccount is not ready yet")
+	if account.Status.AccountID == "" || account.Status.JWTSecretRef.Name == "" {
+		return &dependencyError{
+			Kind:    "NatsAccount",
+			Name:    accountKey(user).String(),
+			Reason:  "AccountNotReady",
+			Message: "account JWT has not been issued yet",
+		}
+	}
+
+	// Package an externally issued JWT instead of issuing one
+	if user.Spec.ExistingJWTSecret != nil {
+		return r.reconcileExternalJWTUser(ctx, user, authConfig, account, settings)
 	}
 
 	// Check if user credentials secret already exists
-	secretName := fmt.Sprintf("%s-user-creds", user.Name)
-	existingSecret := &corev1.Secret{}
-	checkErr := r.Get(ctx, client.ObjectKey{Namespace: user.Namespace, Name: secretName}, existingSecret)
-	if checkErr == nil {
-		// Credentials already exist - check if we need to update them
-		if user.Status.PublicKey != "" && len(existingSecret.Data["user.creds"]) > 0 {
-			// Credentials exist and status is set - no need to regenerate
-			log.Info("User credentials already exist, skipping regeneration", "publicKey", user.Status.PublicKey)
-			return nil
+	secretName, err := userCredsSecretName(user, settings)
+	if err != nil {
+		return err
+	}
+	secretType, err := userCredsSecretType(user, settings)
+	if err != nil {
+		return err
+	}
+	limits := userLimits(user, settings)
+	validity, err := userValidity(user, authConfig, settings)
+	if err != nil {
+		return err
+	}
+	expiry := validity.Expiry
+	existingSecret, secretExists, err := r.getCredsSecret(ctx, user, secretName)
+	if err != nil {
+		return err
+	}
+
+	// A user of a scoped signing key gets its permissions from the scope in the account JWT
+	var signingSeed []byte
+	var signingScope *natsv1alpha1.SigningKeyScope
+	if user.Spec.SigningKeyRole != "" {
+		signingSeed, signingScope, err = r.userSigningKey(ctx, user, account)
+		if err != nil {
+			return err
 		}
-	} else if !errors.IsNotFound(checkErr) {
-		return fmt.Errorf("failed to check credentials secret: %w", checkErr)
+	}
+	permissions, connectionTypes := userPermissions(user), allowedConnectionTypes(user)
+	if signingScope != nil {
+		permissions, connectionTypes, limits = nil, nil, nil
+	}
+	connectionTypes, constraints, err := applyUserClaimPolicy(userClaimPolicy(authConfig), user, connectionTypes, signingScope != nil)
+	if err != nil {
+		return err
+	}
+
+	storedJWT, storedSeed := jwtpkg.ExtractCredentials(existingSecret.Data)
+	permissionsHash := jwtpkg.PermissionsHash(permissions, connectionTypes, user.Spec.BearerToken, limits, validity, publicKeyFromSeed(signingSeed), constraints)
+	storedPubKey := publicKeyFromSeed(storedSeed)
+	if storedPubKey == "" {
+		storedSeed = nil
+	}
+
+	// Credentials laid out as requested must still hold exactly what was issued
+	var driftErr error
+	if storedPubKey != "" && jwtpkg.HasFormat(existingSecret.Data, credentialsFormat(user)) && hasCredentialsLayout(user, existingSecret) {
+		driftErr = jwtpkg.CredentialsDrift(existingSecret.Data, credentialsFormat(user), authConfig.Spec.NatsURL, storedPubKey, wellKnownCreds(user))
+	}
+	// A JWT for the same key that the controller did not issue, such as one signed by hand, has drifted too
+	if driftErr == nil && storedPubKey == user.Status.PublicKey && user.Status.JWTFingerprint != "" &&
+		jwtpkg.Fingerprint(storedJWT) != user.Status.JWTFingerprint {
+		driftErr = fmt.Errorf("user JWT has fingerprint %s, want %s", jwtpkg.Fingerprint(storedJWT), user.Status.JWTFingerprint)
 	}
 
-	// Get or create user seed
-	userSeed, err := r.getOrCreateUserSeed(ctx, user)
+	// Detect artifacts left behind by an interrupted reconcile
+	switch {
+	case storedPubKey != "" && storedPubKey != user.Status.PublicKey:
+		// Secret written but status lost (or stale): trust the persisted seed instead of issuing a new key
+		log.Info("Credentials secret does not match status, resuming from the stored seed",
+			"statusKey", user.Status.PublicKey, "storedKey", storedPubKey, "lastCompletedStep", user.Status.LastCompletedStep)
+	case storedPubKey != "" && jwtpkg.HasFormat(existingSecret.Data, credentialsFormat(user)) &&
+		hasCredentialsLayout(user, existingSecret) &&
+		jwtpkg.HasAllowedConnectionTypes(storedJWT, connectionTypes...) &&
+		jwtpkg.IsBearerToken(storedJWT) == user.Spec.BearerToken &&
+		driftErr == nil &&
+		existingSecret.Type == secretType &&
+		user.Status.PermissionsHash == permissionsHash &&
+		jwtpkg.IssuingAccount(storedJWT) == account.Status.AccountID &&
+		!renewalDue(user, expiry, time.Now()) &&
+		checkpointComplete(user.Status.LastCompletedStep, natsv1alpha1.ReconcileStepSecretWritten):
+		// Credentials exist and match the spec - no need to regenerate
+		log.Info("User credentials already exist, skipping regeneration", "publicKey", user.Status.PublicKey)
+		// Users issued before fingerprints were recorded adopt the stored JWT
+		if user.Status.JWTFingerprint == "" {
+			user.Status.JWTFingerprint, user.Status.IssuedAt, user.Status.ExpiresAt = issuedJWTStatus(storedJWT)
+			user.Status.NotBefore = jwtNotBefore(storedJWT)
+		}
+		return syncPropagatedMetadata(ctx, r.Client, existingSecret, user, userPropagation(user, settings), userOwners(user))
+	case storedPubKey != "" && storedPubKey == user.Status.PublicKey && jwtpkg.IssuingAccount(storedJWT) != account.Status.AccountID:
+		log.Info("User moved to another account, will re-sign user JWT", "publicKey", user.Status.PublicKey, "account", accountKey(user).String())
+	case storedPubKey != "" && storedPubKey == user.Status.PublicKey && user.Status.PermissionsHash != permissionsHash:
+		log.Info("User permissions changed, will re-sign user JWT", "publicKey", user.Status.PublicKey)
+	case storedPubKey != "" && storedPubKey == user.Status.PublicKey && renewalDue(user, expiry, time.Now()):
+		log.Info("User JWT expires soon, will re-issue it", "publicKey", user.Status.PublicKey, "expiresAt", user.Status.ExpiresAt)
+	case storedPubKey != "" && storedPubKey == user.Status.PublicKey && driftErr != nil:
+		log.Info("Credentials secret drifted from the issued credentials, repairing it", "publicKey", user.Status.PublicKey, "reason", driftErr.Error())
+	case storedPubKey != "" && storedPubKey == user.Status.PublicKey && existingSecret.Type != secretType:
+		log.Info("Credentials secret type changed, will recreate it", "publicKey", user.Status.PublicKey, "type", secretType)
+	case storedPubKey != "":
+		log.Info("Resuming interrupted user reconcile", "lastCompletedStep", user.Status.LastCompletedStep)
+	case user.Status.PublicKey != "":
+		log.Info("Credentials secret is missing, recreating it", "publicKey", user.Status.PublicKey)
+	}
+
+	// Prefer an explicitly referenced seed, then the stored one; a new seed is persisted before use
+	userSeed := storedSeed
+	if userSeed == nil || user.Spec.ExistingSeedSecret != nil {
+		userSeed, err = r.getOrCreateUserSeed(ctx, user)
+		if err != nil {
+			return fmt.Errorf("failed to get user seed: %w", err)
+		}
+	}
+
+	// A Secret of another type is replaced, so the stored seed is persisted again right away
+	recreated, err := r.recreateForSecretType(ctx, user, existingSecret, secretExists, secretType)
 	if err != nil {
-		return fmt.Errorf("failed to get user seed: %w", err)
+		return err
+	}
+	if !bytes.Equal(userSeed, storedSeed) || recreated {
+		seedSecret := secrets.New(credsSecretNamespace(user), secretName, map[string][]byte{
+			jwtpkg.SeedKey: userSeed,
+		})
+		seedSecret.Type = secretType
+		seedSecret.Labels = seedSecretLabels(nkeys.PrefixByteUser)
+		protectSeedSecret(seedSecret, r.SecretAccess)
+		propagateMetadata(seedSecret, user, userPropagation(user, settings))
+		if err := r.setSecretOwner(user, seedSecret); err != nil {
+			return err
+		}
+		if err := resolver.ApplySecret(ctx, r.Client, seedSecret); err != nil {
+			return fmt.Errorf("failed to persist user seed: %w", err)
+		}
+	}
+	if err := r.checkpoint(ctx, user, natsv1alpha1.ReconcileStepSeedCreated); err != nil {
+		return err
 	}
 
 	// Create user manager
@@ -200,223 +472,491 @@ func (r *NatsUserReconciler) reconcileJWTUser(ctx context.Context, user *natsv1a
 		userName = user.Spec.Username
 	}
 
-	userClaims, err := userMgr.CreateUserClaims(userName, user.Spec.Permissions)
+	userClaims, err := userMgr.CreateUserClaims(userName, permissions)
 	if err != nil {
 		return fmt.Errorf("failed to create user claims: %w", err)
 	}
-
-	// Get account keypair to sign the user JWT
-	accountSeed, err := r.getAccountSeed(ctx, account)
+	jwtpkg.SetAllowedConnectionTypes(userClaims, connectionTypes...)
+	userClaims.BearerToken = user.Spec.BearerToken
+	jwtpkg.SetUserLimits(userClaims, limits)
+	jwtpkg.SetUserConstraints(userClaims, constraints)
+	jwtpkg.SetValidity(userClaims, validity, time.Now())
+
+	// Let the claim hooks mutate or reject the claims before signing
+	chain, err := claimHooks(r.ClaimHooks, authConfig)
 	if err != nil {
-		return fmt.Errorf("failed to get account seed: %w", err)
+		return err
+	}
+	if err := chain.MutateUserClaims(ctx, hookRequest(hooks.KindUser, user, authConfig), userClaims); err != nil {
+		return fmt.Errorf("failed to apply claim hooks: %w", err)
+	}
+	log.V(debugLevel).Info("Built user claims", "step", "build-claims", "publicKey", userPubKey, "permissions", permissions, "signingKeyRole", user.Spec.SigningKeyRole)
+	if err := r.checkpoint(ctx, user, natsv1alpha1.ReconcileStepClaimsBuilt); err != nil {
+		return err
 	}
 
-	accountMgr, err := jwtpkg.NewAccountManager(accountSeed)
+	// Get account keypair to sign the user JWT
+	accountKP, err := accountKeyPair(ctx, r.Client, account, authConfig)
 	if err != nil {
-		return fmt.Errorf("failed to create account manager: %w", err)
+		return fmt.Errorf("failed to get account key: %w", err)
 	}
+	accountMgr := jwtpkg.NewAccountManagerFromKeyPair(accountKP)
 
-	// Sign the user JWT
-	userJWT, err := accountMgr.SignUserJWT(userClaims)
+	// Sign the user JWT, with the selected account signing key if any
+	var userJWT string
+	if signingSeed != nil {
+		userJWT, err = accountMgr.SignUserJWTWithSigningKey(userClaims, signingSeed)
+	} else {
+		userJWT, err = accountMgr.SignUserJWT(userClaims)
+	}
 	if err != nil {
 		return fmt.Errorf("failed to sign user JWT: %w", err)
 	}
+	if err := r.checkpoint(ctx, user, natsv1alpha1.ReconcileStepSigned); err != nil {
+		return err
+	}
 
-	// Generate credentials file
-	credsContent := jwtpkg.GenerateCredsFile(userJWT, userSeed)
+	// Render the credentials in the requested output format
+	credsData, err := r.renderCredentials(ctx, user, authConfig.Spec.NatsURL, userJWT, userSeed)
+	if err != nil {
+		return err
+	}
 
 	// Store user credentials in a secret (secretName already declared above)
-	secret := &corev1.Secret{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      secretName,
-			Namespace: user.Namespace,
-		},
-		StringData: map[string]string{
-			"user.creds": credsContent,
-			"user.jwt":   userJWT,
-			"NATS_URL":   authConfig.Spec.NatsURL,
-		},
-		Data: map[string][]byte{
-			"seed.nk": userSeed,
-		},
+	secret := secrets.New(credsSecretNamespace(user), secretName, credsData)
+	secret.Type = secretType
+	secret.Labels = seedSecretLabels(nkeys.PrefixByteUser)
+	protectSeedSecret(secret, r.SecretAccess)
+	setCredentialsLayout(user, secret)
+	propagateMetadata(secret, user, userPropagation(user, settings))
+
+	if err := r.setSecretOwner(user, secret); err != nil {
+		return err
 	}
 
-	if err := controllerutil.SetControllerReference(user, secret, r.Scheme); err != nil {
-		return err
+	if err := resolver.ApplySecret(ctx, r.Client, secret); err != nil {
+		return fmt.Errorf("failed to apply credentials secret: %w", err)
 	}
 
-	// Create or update the secret (reuse existingSecret from above)
-	existingSecret = &corev1.Secret{}
-	checkErr = r.Get(ctx, client.ObjectKey{Namespace: user.Namespace, Name: secretName}, existingSecret)
-	if checkErr != nil {
-		if errors.IsNotFound(checkErr) {
-			if err := r.Create(ctx, secret); err != nil {
-				return fmt.Errorf("failed to create credentials secret: %w", err)
-			}
-		} else {
-			return checkErr
-		}
-	} else {
-		existingSecret.StringData = secret.StringData
-		existingSecret.Data = secret.Data
-		if err := r.Update(ctx, existingSecret); err != nil {
-			return fmt.Errorf("failed to update credentials secret: %w", err)
-		}
+	if user.Status.PermissionsHash != "" && user.Status.PermissionsHash != permissionsHash && r.Recorder != nil {
+		r.Recorder.Eventf(user, corev1.EventTypeNormal, "PermissionsUpdated", "Re-signed user JWT %s with updated permissions", userPubKey)
+	}
+	if driftErr != nil && userPubKey == storedPubKey && r.Recorder != nil {
+		r.Recorder.Eventf(user, corev1.EventTypeNormal, "DriftRepaired", "Repaired credentials Secret %s/%s: %v", secret.Namespace, secret.Name, driftErr)
+	}
+
+	action := audit.ActionIssued
+	if userPubKey == user.Status.PublicKey {
+		action = audit.ActionReissued
 	}
+	recordAudit(ctx, r.Audit, "NatsUser", user, audit.Event{
+		Action:          action,
+		Credential:      audit.CredentialUserJWT,
+		PublicKey:       userPubKey,
+		Issuer:          userClaims.Issuer,
+		ExpiresAt:       auditExpiry(userClaims.Expires),
+		PermissionsHash: permissionsHash,
+		Secret:          secretKey(secret),
+	})
 
 	// Update status
 	user.Status.PublicKey = userPubKey
+	user.Status.PermissionsHash = permissionsHash
+	user.Status.JWTFingerprint, user.Status.IssuedAt, user.Status.ExpiresAt = issuedJWTStatus(userJWT)
+	user.Status.NotBefore = jwtNotBefore(userJWT)
+	if err := r.cleanupMovedSecret(ctx, user, secret); err != nil {
+		return err
+	}
 	user.Status.SecretRef = natsv1alpha1.SecretRef{
 		Name:      secretName,
-		Namespace: user.Namespace,
+		Namespace: secret.Namespace,
 	}
 
-	return nil
+	return r.checkpoint(ctx, user, natsv1alpha1.ReconcileStepSecretWritten)
 }
 
-func (r *NatsUserReconciler) reconcileTokenUser(ctx context.Context, user *natsv1alpha1.NatsUser, authConfig *natsv1alpha1.NatsAuthConfig) error {
-	// Determine username
-	username := user.Spec.Username
-	if username == "" {
-		// Generate username
-		var err error
-		username, err = token.GenerateUsername(user.Name)
+// reconcileExternalJWTUser validates an externally issued user JWT against the account
+// and packages it into the standard credentials Secret layout
+func (r *NatsUserReconciler) reconcileExternalJWTUser(ctx context.Context, user *natsv1alpha1.NatsUser, authConfig *natsv1alpha1.NatsAuthConfig, account *natsv1alpha1.NatsAccount, settings *natsv1alpha1.NatsOperatorSettingsSpec) error {
+	log := log.FromContext(ctx)
+
+	key := user.Spec.ExistingJWTSecret.ObjectKey(user.Namespace)
+	jwtSecret := &corev1.Secret{}
+	if err := r.Get(ctx, key, jwtSecret); err != nil {
+		return fmt.Errorf("failed to get user JWT secret: %w", err)
+	}
+
+	userJWT := string(jwtSecret.Data["user.jwt"])
+	if userJWT == "" {
+		return fmt.Errorf("user JWT not found in secret %s", key)
+	}
+
+	// The seed lives next to the JWT unless an explicit seed secret is referenced
+	var userSeed []byte
+	if user.Spec.ExistingSeedSecret != nil {
+		seed, err := r.getOrCreateUserSeed(ctx, user)
 		if err != nil {
-			return fmt.Errorf("failed to generate username: %w", err)
+			return fmt.Errorf("failed to get user seed: %w", err)
 		}
+		userSeed = seed
+	} else {
+		userSeed, _, _ = jwtpkg.FindSeed(jwtSecret.Data, nkeys.PrefixByteUser, "")
 	}
 
-	// Determine password
-	var password string
-	if user.Spec.PasswordFrom != nil {
-		if user.Spec.PasswordFrom.Generate {
-			// Generate password
-			var err error
-			password, err = token.GeneratePassword()
-			if err != nil {
-				return fmt.Errorf("failed to generate password: %w", err)
-			}
-		} else if user.Spec.PasswordFrom.SecretRef != nil {
-			// Get password from secret
-			secret := &corev1.Secret{}
-			key := client.ObjectKey{
-				Namespace: user.Spec.PasswordFrom.SecretRef.Namespace,
-				Name:      user.Spec.PasswordFrom.SecretRef.Name,
-			}
-			if err := r.Get(ctx, key, secret); err != nil {
-				return fmt.Errorf("failed to get password secret: %w", err)
-			}
-			password = string(secret.Data["password"])
+	userPubKey := publicKeyFromSeed(userSeed)
+	if userPubKey == "" {
+		return fmt.Errorf("valid user seed not found for externally issued JWT")
+	}
+
+	accountJWTSecret := &corev1.Secret{}
+	if err := r.Get(ctx, account.Status.JWTSecretRef.ObjectKey(account.Namespace), accountJWTSecret); err != nil {
+		return fmt.Errorf("failed to get account JWT secret: %w", err)
+	}
+
+	claims, err := jwtpkg.VerifyUserJWT(userJWT, string(accountJWTSecret.Data["account.jwt"]), userPubKey)
+	if err != nil {
+		return err
+	}
+	log.V(debugLevel).Info("Verified externally issued user JWT", "step", "verify-jwt", "publicKey", userPubKey, "issuer", claims.Issuer)
+
+	credsData, err := r.renderCredentials(ctx, user, authConfig.Spec.NatsURL, userJWT, userSeed)
+	if err != nil {
+		return err
+	}
+
+	secretName, err := userCredsSecretName(user, settings)
+	if err != nil {
+		return err
+	}
+	secretType, err := userCredsSecretType(user, settings)
+	if err != nil {
+		return err
+	}
+	secret := secrets.New(credsSecretNamespace(user), secretName, credsData)
+	secret.Type = secretType
+	setCredentialsLayout(user, secret)
+	propagateMetadata(secret, user, userPropagation(user, settings))
+
+	if err := r.setSecretOwner(user, secret); err != nil {
+		return err
+	}
+
+	// The JWT is packaged on every reconcile; only a changed JWT is a new import
+	existingSecret, secretExists, err := r.getCredsSecret(ctx, user, secretName)
+	if err != nil {
+		return err
+	}
+	storedJWT, _ := jwtpkg.ExtractCredentials(existingSecret.Data)
+
+	if _, err := r.recreateForSecretType(ctx, user, existingSecret, secretExists, secretType); err != nil {
+		return err
+	}
+	if err := resolver.ApplySecret(ctx, r.Client, secret); err != nil {
+		return fmt.Errorf("failed to apply credentials secret: %w", err)
+	}
+
+	if storedJWT != userJWT {
+		recordAudit(ctx, r.Audit, "NatsUser", user, audit.Event{
+			Action:     audit.ActionImported,
+			Credential: audit.CredentialUserJWT,
+			PublicKey:  userPubKey,
+			Issuer:     claims.Issuer,
+			ExpiresAt:  auditExpiry(claims.Expires),
+			Secret:     secretKey(secret),
+		})
+	}
+
+	user.Status.PublicKey = userPubKey
+	user.Status.JWTFingerprint, user.Status.IssuedAt, user.Status.ExpiresAt = issuedJWTStatus(userJWT)
+	user.Status.NotBefore = jwtNotBefore(userJWT)
+	if err := r.cleanupMovedSecret(ctx, user, secret); err != nil {
+		return err
+	}
+	user.Status.SecretRef = natsv1alpha1.SecretRef{
+		Name:      secretName,
+		Namespace: secret.Namespace,
+	}
+
+	return r.checkpoint(ctx, user, natsv1alpha1.ReconcileStepSecretWritten)
+}
+
+// jwtTime converts a JWT unix timestamp to a status timestamp; 0 means unset
+func jwtTime(unix int64) *metav1.Time {
+	if unix == 0 {
+		return nil
+	}
+	t := metav1.NewTime(time.Unix(unix, 0))
+	return &t
+}
+
+// issuedJWTStatus returns the fingerprint, issue time and expiry that status records for an
+// issued JWT
+func issuedJWTStatus(token string) (string, *metav1.Time, *metav1.Time) {
+	issued, err := jwtpkg.DescribeJWT(token)
+	if err != nil {
+		return jwtpkg.Fingerprint(token), nil, nil
+	}
+	return issued.Fingerprint, jwtTime(issued.IssuedAt), jwtTime(issued.Expires)
+}
+
+// jwtNotBefore returns the time an issued JWT becomes valid, or nil when it is valid from issuance
+func jwtNotBefore(token string) *metav1.Time {
+	issued, err := jwtpkg.DescribeJWT(token)
+	if err != nil {
+		return nil
+	}
+	return jwtTime(issued.NotBefore)
+}
+
+// credentialsFormat returns the output format of the user's credentials Secret
+func credentialsFormat(user *natsv1alpha1.NatsUser) natsv1alpha1.CredentialsFormat {
+	if user.Spec.Output == nil || user.Spec.Output.Format == "" {
+		return natsv1alpha1.CredentialsFormatCreds
+	}
+	return user.Spec.Output.Format
+}
+
+// renderCredentials builds the credentials Secret data in the user's output format
+func (r *NatsUserReconciler) renderCredentials(ctx context.Context, user *natsv1alpha1.NatsUser, natsURL, userJWT string, seed []byte) (map[string][]byte, error) {
+	var tls *jwtpkg.TLSBundle
+	if user.Spec.Output != nil && user.Spec.Output.TLSSecretRef != nil {
+		tlsSecret := &corev1.Secret{}
+		if err := r.Get(ctx, user.Spec.Output.TLSSecretRef.ObjectKey(user.Namespace), tlsSecret); err != nil {
+			return nil, fmt.Errorf("failed to get TLS secret: %w", err)
 		}
-	} else {
-		// Default: generate password
-		var err error
-		password, err = token.GeneratePassword()
-		if err != nil {
-			return fmt.Errorf("failed to generate password: %w", err)
+		tls = &jwtpkg.TLSBundle{
+			CA:   string(tlsSecret.Data["ca.crt"]),
+			Cert: string(tlsSecret.Data["tls.crt"]),
+			Key:  string(tlsSecret.Data["tls.key"]),
 		}
 	}
 
-	// Store user credentials in a secret
-	secretName := fmt.Sprintf("%s-user-creds", user.Name)
-	secret := &corev1.Secret{
+	data, err := jwtpkg.RenderCredentials(credentialsFormat(user), natsURL, userJWT, seed, tls)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render credentials: %w", err)
+	}
+	if wellKnownCreds(user) {
+		data[jwtpkg.WellKnownCredsKey] = []byte(jwtpkg.GenerateCredsFile(userJWT, seed))
+	}
+	return data, nil
+}
+
+// setCredentialsLayout annotates the credentials Secret with the recommended creds file path
+func setCredentialsLayout(user *natsv1alpha1.NatsUser, secret *corev1.Secret) {
+	if !wellKnownCreds(user) {
+		return
+	}
+	if secret.Annotations == nil {
+		secret.Annotations = map[string]string{}
+	}
+	secret.Annotations[credsPathAnnotation] = jwtpkg.CredsFilePath(credsMountPath(user))
+}
+
+// hasCredentialsLayout reports whether an existing credentials Secret carries the requested well-known key
+func hasCredentialsLayout(user *natsv1alpha1.NatsUser, secret *corev1.Secret) bool {
+	if !wellKnownCreds(user) {
+		return len(secret.Data[jwtpkg.WellKnownCredsKey]) == 0
+	}
+	return len(secret.Data[jwtpkg.WellKnownCredsKey]) > 0 &&
+		secret.Annotations[credsPathAnnotation] == jwtpkg.CredsFilePath(credsMountPath(user))
+}
+
+// reconcileMountSnippet writes the companion ConfigMap with a pod spec snippet for the credentials Secret
+func (r *NatsUserReconciler) reconcileMountSnippet(ctx context.Context, user *natsv1alpha1.NatsUser) error {
+	secretName := user.Status.SecretRef.Name
+	cm := &corev1.ConfigMap{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      secretName,
-			Namespace: user.Namespace,
+			Name:      secretName + "-mount",
+			Namespace: credsSecretNamespace(user),
 		},
-		StringData: map[string]string{
-			"USERNAME":  username,
-			"PASSWORD":  password,
-			"NATS_URL":  authConfig.Spec.NatsURL,
+		Data: map[string]string{
+			mountSnippetKey: jwtpkg.MountSnippet(secretName, user.Spec.Output.MountPath),
 		},
 	}
+	if err := r.setSecretOwner(user, cm); err != nil {
+		return err
+	}
+
+	if err := resolver.ApplyConfigMap(ctx, r.Client, cm); err != nil {
+		return fmt.Errorf("failed to apply mount snippet ConfigMap: %w", err)
+	}
+	return nil
+}
 
-	if err := controllerutil.SetControllerReference(user, secret, r.Scheme); err != nil {
+// checkpoint records a completed step in status so an interrupted reconcile is detected and resumed
+func (r *NatsUserReconciler) checkpoint(ctx context.Context, user *natsv1alpha1.NatsUser, step natsv1alpha1.ReconcileStep) error {
+	user.Status.LastCompletedStep = step
+	if err := patchStatus(ctx, r.Client, user); err != nil {
+		return fmt.Errorf("failed to record %s checkpoint: %w", step, err)
+	}
+	log.FromContext(ctx).V(debugLevel).Info("Checkpoint reached", "step", step)
+	return nil
+}
+
+func (r *NatsUserReconciler) reconcileTokenUser(ctx context.Context, user *natsv1alpha1.NatsUser, authConfig *natsv1alpha1.NatsAuthConfig, settings *natsv1alpha1.NatsOperatorSettingsSpec) error {
+	// Determine username
+	username, err := tokenUsername(user, settings)
+	if err != nil {
+		return permanent(natsv1alpha1.ReasonInvalidSpec, err)
+	}
+	if err := r.checkUsernameOwner(ctx, user, username); err != nil {
 		return err
 	}
 
-	// Create or update the secret
-	existingSecret := &corev1.Secret{}
-	err := r.Get(ctx, client.ObjectKey{Namespace: user.Namespace, Name: secretName}, existingSecret)
+	secretName, err := userCredsSecretName(user, settings)
 	if err != nil {
-		if errors.IsNotFound(err) {
-			if err := r.Create(ctx, secret); err != nil {
-				return fmt.Errorf("failed to create credentials secret: %w", err)
-			}
-		} else {
+		return err
+	}
+	secretType, err := userCredsSecretType(user, settings)
+	if err != nil {
+		return err
+	}
+	existingSecret, secretExists, err := r.getCredsSecret(ctx, user, secretName)
+	if err != nil {
+		return err
+	}
+
+	// Determine password
+	var password, sourceVersion string
+	policy := passwordPolicy(r.PasswordPolicy, user.Spec.PasswordFrom)
+	if user.Spec.PasswordFrom != nil && !user.Spec.PasswordFrom.Generate && user.Spec.PasswordFrom.SecretRef != nil {
+		// Get password from secret
+		ref := user.Spec.PasswordFrom.SecretRef
+		password, sourceVersion, err = readPasswordSecret(ctx, r.Client, ref, user.Namespace)
+		if err != nil {
 			return err
 		}
+		if err := policy.CheckEntropy(password); err != nil {
+			return fmt.Errorf("password in secret %s rejected: %w", ref.ObjectKey(user.Namespace), err)
+		}
 	} else {
-		// Only update if password/username changed
-		if existingSecret.Data == nil ||
-			string(existingSecret.Data["USERNAME"]) != username ||
-			string(existingSecret.Data["PASSWORD"]) != password {
-			existingSecret.StringData = secret.StringData
-			if err := r.Update(ctx, existingSecret); err != nil {
-				return fmt.Errorf("failed to update credentials secret: %w", err)
-			}
+		// Generate a password, keeping the stored one while it satisfies the policy
+		password, err = resolveGeneratedPassword(random.OrDefault(r.Random), policy, string(existingSecret.Data["PASSWORD"]))
+		if err != nil {
+			return fmt.Errorf("failed to generate password: %w", err)
+		}
+	}
+
+	// Store user credentials in a secret
+	secret := secrets.New(credsSecretNamespace(user), secretName, secrets.FromStrings(map[string]string{
+		"USERNAME": username,
+		"PASSWORD": password,
+		"NATS_URL": authConfig.Spec.NatsURL,
+	}))
+	secret.Type = secretType
+	propagateMetadata(secret, user, userPropagation(user, settings))
+
+	if err := r.setSecretOwner(user, secret); err != nil {
+		return err
+	}
+
+	// Apply the secret only if username/password changed, or the NATS URL drifted
+	changed := secrets.ChangedKeys(existingSecret, secret.Data)
+	urlDrifted := secretExists && slices.Contains(changed, "NATS_URL")
+	recreated, err := r.recreateForSecretType(ctx, user, existingSecret, secretExists, secretType)
+	if err != nil {
+		return err
+	}
+	if len(changed) > 0 || recreated {
+		if err := resolver.ApplySecret(ctx, r.Client, secret); err != nil {
+			return fmt.Errorf("failed to apply credentials secret: %w", err)
+		}
+		if secretExists && sourceVersion != "" && r.Recorder != nil {
+			r.Recorder.Eventf(user, corev1.EventTypeNormal, "PasswordRotated", "Updated credentials Secret %s/%s with the rotated password", secret.Namespace, secret.Name)
 		}
+		if urlDrifted && r.Recorder != nil {
+			r.Recorder.Eventf(user, corev1.EventTypeNormal, "DriftRepaired", "Repaired credentials Secret %s/%s: key \"NATS_URL\" does not match the NatsAuthConfig", secret.Namespace, secret.Name)
+		}
+		action := audit.ActionIssued
+		if secretExists && string(existingSecret.Data["PASSWORD"]) == password {
+			action = audit.ActionReissued
+		}
+		recordAudit(ctx, r.Audit, "NatsUser", user, audit.Event{
+			Action:     action,
+			Credential: audit.CredentialPassword,
+			Username:   username,
+			Secret:     secretKey(secret),
+		})
+	} else if err := syncPropagatedMetadata(ctx, r.Client, existingSecret, user, userPropagation(user, settings), userOwners(user)); err != nil {
+		return err
 	}
 
 	// Update status
+	if err := r.cleanupMovedSecret(ctx, user, secret); err != nil {
+		return err
+	}
 	user.Status.SecretRef = natsv1alpha1.SecretRef{
 		Name:      secretName,
-		Namespace: user.Namespace,
+		Namespace: secret.Namespace,
 	}
+	user.Status.PasswordSourceVersion = sourceVersion
+	user.Status.JWTFingerprint = ""
+	user.Status.IssuedAt = nil
+	user.Status.ExpiresAt = nil
+	user.Status.NotBefore = nil
+	user.Status.Username = username
 
 	return nil
 }
 
-func (r *NatsUserReconciler) getOrCreateUserSeed(ctx context.Context, user *natsv1alpha1.NatsUser) ([]byte, error) {
-	// Check if existing seed is specified
-	if user.Spec.ExistingSeedSecret != nil {
-		secret := &corev1.Secret{}
-		key := client.ObjectKey{
-			Namespace: user.Spec.ExistingSeedSecret.Namespace,
-			Name:      user.Spec.ExistingSeedSecret.Name,
-		}
-		if err := r.Get(ctx, key, secret); err != nil {
-			return nil, fmt.Errorf("failed to get user seed secret: %w", err)
-		}
+// checkUsernameOwner fails with UsernameConflict when another NatsUser of the NatsAuthConfig holds the
+// username. The oldest user claiming a username holds it, so the owner does not change while
+// duplicates come and go.
+func (r *NatsUserReconciler) checkUsernameOwner(ctx context.Context, user *natsv1alpha1.NatsUser, username string) error {
+	userList := &natsv1alpha1.NatsUserList{}
+	if err := r.List(ctx, userList, client.MatchingFields{tokenUsernameIndex: tokenUsernameKey(user, username)}); err != nil {
+		return fmt.Errorf("failed to list users claiming username %q: %w", username, err)
+	}
 
-		seed, ok := secret.Data["user.seed"]
-		if !ok {
-			seed, ok = secret.Data["seed.nk"]
-			if !ok {
-				return nil, fmt.Errorf("user seed not found in secret")
-			}
+	owner := user
+	for i := range userList.Items {
+		other := &userList.Items[i]
+		if other.UID == user.UID || !other.DeletionTimestamp.IsZero() {
+			continue
 		}
+		if claimsBefore(other, owner) {
+			owner = other
+		}
+	}
+	if owner != user {
+		return permanent(natsv1alpha1.ReasonUsernameConflict, fmt.Errorf("username %q is already used by NatsUser %s", username, client.ObjectKeyFromObject(owner)))
+	}
+	return nil
+}
 
-		return seed, nil
+// claimsBefore orders users claiming the same username by creation, then by namespace and name
+func claimsBefore(a, b *natsv1alpha1.NatsUser) bool {
+	if !a.CreationTimestamp.Equal(&b.CreationTimestamp) {
+		return a.CreationTimestamp.Before(&b.CreationTimestamp)
 	}
+	return client.ObjectKeyFromObject(a).String() < client.ObjectKeyFromObject(b).String()
+}
 
-	// Create new user keypair
-	userMgr, err := jwtpkg.NewUserManager(nil)
-	if err != nil {
-		return nil, err
+func (r *NatsUserReconciler) getOrCreateUserSeed(ctx context.Context, user *natsv1alpha1.NatsUser) ([]byte, error) {
+	// Check if existing seed is specified
+	if ref := user.Spec.ExistingSeedSecret; ref != nil {
+		return readSeedSecret(ctx, r.Client, ref, user.Namespace, nkeys.PrefixByteUser)
 	}
 
-	seed, err := userMgr.GetSeed()
-	if err != nil {
-		return nil, err
+	// Reuse the seed of the current credentials Secret, e.g. when it moves to another namespace
+	if ref := user.Status.SecretRef; ref.Name != "" {
+		secret := &corev1.Secret{}
+		if err := r.Get(ctx, ref.ObjectKey(user.Namespace), secret); err == nil {
+			if _, seed := jwtpkg.ExtractCredentials(secret.Data); len(seed) > 0 {
+				return seed, nil
+			}
+		} else if !errors.IsNotFound(err) {
+			return nil, fmt.Errorf("failed to get credentials secret: %w", err)
+		}
 	}
 
-	return seed, nil
+	// Create new user keypair
+	return random.Seed(random.OrDefault(r.Random), nkeys.PrefixByteUser)
 }
 
 func (r *NatsUserReconciler) getAuthConfig(ctx context.Context, user *natsv1alpha1.NatsUser) (*natsv1alpha1.NatsAuthConfig, error) {
 	authConfig := &natsv1alpha1.NatsAuthConfig{}
-	namespace := user.Spec.AuthConfigRef.Namespace
-	if namespace == "" {
-		namespace = user.Namespace
-	}
-
-	key := client.ObjectKey{
-		Namespace: namespace,
-		Name:      user.Spec.AuthConfigRef.Name,
-	}
-
-	if err := r.Get(ctx, key, authConfig); err != nil {
+	if err := r.Get(ctx, authConfigKey(user), authConfig); err != nil {
 		return nil, err
 	}
 
@@ -424,54 +964,118 @@ func (r *NatsUserReconciler) getAuthConfig(ctx context.Context, user *natsv1alph
 }
 
 func (r *NatsUserReconciler) getAccount(ctx context.Context, user *natsv1alpha1.NatsUser) (*natsv1alpha1.NatsAccount, error) {
-	if user.Spec.AccountRef == nil {
-		return nil, fmt.Errorf("accountRef is nil")
+	if !hasAccount(user) {
+		return nil, fmt.Errorf("user has no account")
 	}
 
 	account := &natsv1alpha1.NatsAccount{}
-	namespace := user.Spec.AccountRef.Namespace
-	if namespace == "" {
-		namespace = user.Namespace
-	}
-
-	key := client.ObjectKey{
-		Namespace: namespace,
-		Name:      user.Spec.AccountRef.Name,
-	}
-
-	if err := r.Get(ctx, key, account); err != nil {
+	if err := r.Get(ctx, accountKey(user), account); err != nil {
 		return nil, err
 	}
 
 	return account, nil
 }
 
-func (r *NatsUserReconciler) getAccountSeed(ctx context.Context, account *natsv1alpha1.NatsAccount) ([]byte, error) {
-	if account.Status.JWTSecretRef.Name == "" {
-		return nil, fmt.Errorf("account JWT secret not ready")
+// authConfigKey returns the key of the NatsAuthConfig referenced by the user
+func authConfigKey(user *natsv1alpha1.NatsUser) client.ObjectKey {
+	namespace := user.Spec.AuthConfigRef.Namespace
+	if namespace == "" {
+		namespace = user.Namespace
 	}
+	return client.ObjectKey{Namespace: namespace, Name: user.Spec.AuthConfigRef.Name}
+}
 
-	secret := &corev1.Secret{}
-	key := client.ObjectKey{
-		Namespace: account.Status.JWTSecretRef.Namespace,
-		Name:      account.Status.JWTSecretRef.Name,
+// resolveAccountRef records the NatsAccount of the user in status: spec.accountRef, or the
+// defaultAccountRef of the NatsAuthConfig for JWT users without one
+func resolveAccountRef(user *natsv1alpha1.NatsUser, authConfig *natsv1alpha1.NatsAuthConfig, authType natsv1alpha1.UserAuthType) {
+	switch {
+	case user.Spec.AccountRef != nil:
+		key := accountKey(user)
+		user.Status.AccountRef = &natsv1alpha1.NatsAccountRef{Name: key.Name, Namespace: key.Namespace}
+	case authType == natsv1alpha1.UserAuthTypeJWT && authConfig.Spec.DefaultAccountRef != nil:
+		namespace := authConfig.Spec.DefaultAccountRef.Namespace
+		if namespace == "" {
+			namespace = authConfig.Namespace
+		}
+		user.Status.AccountRef = &natsv1alpha1.NatsAccountRef{Name: authConfig.Spec.DefaultAccountRef.Name, Namespace: namespace}
+	default:
+		user.Status.AccountRef = nil
 	}
+}
+
+// hasAccount reports whether the user belongs to a NatsAccount, through spec.accountRef or the
+// default account recorded in status.accountRef
+func hasAccount(user *natsv1alpha1.NatsUser) bool {
+	return user.Spec.AccountRef != nil || user.Status.AccountRef != nil
+}
 
-	if err := r.Get(ctx, key, secret); err != nil {
-		return nil, fmt.Errorf("failed to get account JWT secret: %w", err)
+// accountKey returns the key of the NatsAccount of the user: spec.accountRef, otherwise the
+// default account recorded in status.accountRef. The user must have an account.
+func accountKey(user *natsv1alpha1.NatsUser) client.ObjectKey {
+	if user.Spec.AccountRef == nil {
+		return client.ObjectKey{Namespace: user.Status.AccountRef.Namespace, Name: user.Status.AccountRef.Name}
+	}
+	namespace := user.Spec.AccountRef.Namespace
+	if namespace == "" {
+		namespace = user.Namespace
 	}
+	return client.ObjectKey{Namespace: namespace, Name: user.Spec.AccountRef.Name}
+}
+
+// setPending marks the user as waiting for a dependency and requeues with exponential backoff.
+// The NatsAccount and NatsAuthConfig watches re-trigger the user as soon as the dependency changes.
+func (r *NatsUserReconciler) setPending(ctx context.Context, req ctrl.Request, user *natsv1alpha1.NatsUser, depErr *dependencyError) (ctrl.Result, error) {
+	now := metav1.Now()
+	user.Status.LastReconciled = &now
+	user.Status.ObservedGeneration = user.Generation
 
-	seed, ok := secret.Data["account.seed"]
-	if !ok {
-		return nil, fmt.Errorf("account seed not found in secret")
+	r.updateStatus(user, natsv1alpha1.UserStatePending, depErr.Error())
+	r.updateCondition(user, metav1.Condition{
+		Type:    "DependenciesReady",
+		Status:  metav1.ConditionFalse,
+		Reason:  depErr.Reason,
+		Message: depErr.Error(),
+	})
+	r.updateCondition(user, metav1.Condition{
+		Type:    "Ready",
+		Status:  metav1.ConditionFalse,
+		Reason:  string(natsv1alpha1.ReasonDependencyNotReady),
+		Message: depErr.Error(),
+	})
+	if err := patchStatus(ctx, r.Client, user); err != nil {
+		return ctrl.Result{}, err
 	}
 
-	return seed, nil
+	delay := r.dependencyBackoff.When(req.NamespacedName)
+	log.FromContext(ctx).Info("Waiting for dependency", "dependency", depErr.Kind, "name", depErr.Name, "reason", depErr.Reason, "retryAfter", delay)
+
+	return ctrl.Result{RequeueAfter: delay}, nil
 }
 
 func (r *NatsUserReconciler) handleDeletion(ctx context.Context, user *natsv1alpha1.NatsUser) (ctrl.Result, error) {
 	if controllerutil.ContainsFinalizer(user, natsUserFinalizer) {
-		// Cleanup logic here if needed
+		// Revoke the user in its account so the JWT stops being accepted
+		if user.Spec.RevokeOnDelete && user.Status.PublicKey != "" && hasAccount(user) {
+			if err := r.revokeUser(ctx, user); err != nil {
+				return ctrl.Result{}, fmt.Errorf("failed to revoke user: %w", err)
+			}
+		}
+
+		// Owner references cannot cross namespaces, so remove such Secrets ourselves
+		if err := r.deleteCrossNamespaceSecret(ctx, user, user.Status.SecretRef); err != nil {
+			return ctrl.Result{}, err
+		}
+		if user.Spec.Purpose == natsv1alpha1.UserPurposeLeafNode {
+			if err := r.deleteCrossNamespaceConfigMap(ctx, user, leafNodeConfigMapName(user)); err != nil {
+				return ctrl.Result{}, err
+			}
+		}
+		if user.Spec.Output != nil && user.Spec.Output.MountSnippet {
+			if err := r.deleteCrossNamespaceConfigMap(ctx, user, user.Status.SecretRef.Name+"-mount"); err != nil {
+				return ctrl.Result{}, err
+			}
+		}
+
 		controllerutil.RemoveFinalizer(user, natsUserFinalizer)
 		if err := r.Update(ctx, user); err != nil {
 			return ctrl.Result{}, err
@@ -480,9 +1084,50 @@ func (r *NatsUserReconciler) handleDeletion(ctx context.Context, user *natsv1alp
 	return ctrl.Result{}, nil
 }
 
-func (r *NatsUserReconciler) updateStatus(user *natsv1alpha1.NatsUser, state natsv1alpha1.UserState, reason string) {
+// revokeUser records the user's public key in the parent account's revocation list.
+// The NatsAccount controller re-signs the account JWT and refreshes the server config.
+func (r *NatsUserReconciler) revokeUser(ctx context.Context, user *natsv1alpha1.NatsUser) error {
+	log := log.FromContext(ctx)
+
+	account, err := r.getAccount(ctx, user)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			// Account is gone, so are all of its users
+			return nil
+		}
+		return err
+	}
+
+	if _, ok := account.Status.RevokedUsers[user.Status.PublicKey]; ok {
+		return nil
+	}
+
+	// Patch only the new entry, so revocations of other users deleted at the same time are kept
+	ctx = withStatusBase(ctx, account)
+
+	if account.Status.RevokedUsers == nil {
+		account.Status.RevokedUsers = make(map[string]int64)
+	}
+	account.Status.RevokedUsers[user.Status.PublicKey] = time.Now().Unix()
+
+	if err := patchStatus(ctx, r.Client, account); err != nil {
+		return err
+	}
+
+	log.Info("Revoked user in account", "publicKey", user.Status.PublicKey, "account", account.Name)
+	recordAudit(ctx, r.Audit, "NatsUser", user, audit.Event{
+		Action:     audit.ActionRevoked,
+		Credential: audit.CredentialUserJWT,
+		PublicKey:  user.Status.PublicKey,
+		Issuer:     account.Status.AccountID,
+	})
+	return nil
+}
+
+func (r *NatsUserReconciler) updateStatus(user *natsv1alpha1.NatsUser, state natsv1alpha1.UserState, message string) {
 	user.Status.State = state
-	user.Status.Reason = reason
+	user.Status.Phase = natsv1alpha1.Phase(state)
+	user.Status.Message = message
 }
 
 func (r *NatsUserReconciler) updateCondition(user *natsv1alpha1.NatsUser, condition metav1.Condition) {
@@ -498,12 +1143,358 @@ func (r *NatsUserReconciler) updateCondition(user *natsv1alpha1.NatsUser, condit
 	if !found {
 		user.Status.Conditions = append(user.Status.Conditions, condition)
 	}
+	if condition.Type == "Ready" {
+		user.Status.Reason = natsv1alpha1.ReasonCode(condition.Reason)
+	}
+}
+
+// credsSecretNamespace returns the namespace the credentials Secret is written to
+func credsSecretNamespace(user *natsv1alpha1.NatsUser) string {
+	if user.Spec.SecretNamespace != "" {
+		return user.Spec.SecretNamespace
+	}
+	return user.Namespace
+}
+
+// userSecretTracking labels the Secrets and ConfigMaps written for a NatsUser
+var userSecretTracking = secrets.Tracking{NameLabel: userNameLabel, NamespaceLabel: userNamespaceLabel}
+
+// userOwners returns the well-known labels of the objects generated for a NatsUser; token users
+// and users whose account is not resolved yet have no account
+func userOwners(user *natsv1alpha1.NatsUser) natslabels.Owners {
+	owners := natslabels.Owners{AuthConfig: user.Spec.AuthConfigRef.Name, User: user.Name}
+	if user.Status.AccountRef != nil {
+		owners.Account = user.Status.AccountRef.Name
+	}
+	return owners
+}
+
+// setSecretOwner labels the credentials Secret with its NatsUser and sets a controller
+// reference when the Secret lives in the same namespace
+func (r *NatsUserReconciler) setSecretOwner(user *natsv1alpha1.NatsUser, obj client.Object) error {
+	natslabels.Stamp(obj, userOwners(user))
+	return userSecretTracking.SetOwner(user, obj, r.Scheme)
+}
+
+// getCredsSecret returns the credentials Secret with the given name and whether it exists; a
+// missing Secret is returned empty. A Secret of that name that does not belong to the NatsUser
+// is a permanent SecretNameCollision rather than being adopted and overwritten.
+func (r *NatsUserReconciler) getCredsSecret(ctx context.Context, user *natsv1alpha1.NatsUser, name string) (*corev1.Secret, bool, error) {
+	key := client.ObjectKey{Namespace: credsSecretNamespace(user), Name: name}
+	secret, exists, err := userSecretTracking.GetOwned(ctx, r.Client, key, user)
+	if !secrets.IsNotOwned(err) {
+		return secret, exists, err
+	}
+
+	if r.Recorder != nil {
+		r.Recorder.Eventf(user, corev1.EventTypeWarning, string(natsv1alpha1.ReasonSecretNameCollision),
+			"Secret %s exists and does not belong to this NatsUser, leaving it untouched", key)
+	}
+	return nil, false, permanent(natsv1alpha1.ReasonSecretNameCollision,
+		fmt.Errorf("secret %s exists and does not belong to this NatsUser; set spec.secretName to another name", key))
+}
+
+// recreateForSecretType deletes an existing credentials Secret whose type is not secretType, since
+// the type of a Secret cannot be changed, and reports whether it did. The caller writes the Secret
+// again with the data it read. The seed protection finalizer is released once the Secret is being
+// deleted, so the Secret is gone before it is written again.
+func (r *NatsUserReconciler) recreateForSecretType(ctx context.Context, user *natsv1alpha1.NatsUser, existing *corev1.Secret, exists bool, secretType corev1.SecretType) (bool, error) {
+	if !exists || existing.Type == secretType {
+		return false, nil
+	}
+	key := client.ObjectKeyFromObject(existing)
+	if err := r.Delete(ctx, existing, client.Preconditions{UID: &existing.UID}); err != nil && !errors.IsNotFound(err) {
+		return false, fmt.Errorf("failed to delete credentials secret %s to change its type: %w", key, err)
+	}
+	if controllerutil.ContainsFinalizer(existing, seedProtectionFinalizer) {
+		patch := client.MergeFrom(existing.DeepCopy())
+		controllerutil.RemoveFinalizer(existing, seedProtectionFinalizer)
+		if err := r.Patch(ctx, existing, patch); err != nil && !errors.IsNotFound(err) {
+			return false, fmt.Errorf("failed to release credentials secret %s: %w", key, err)
+		}
+	}
+
+	log.FromContext(ctx).Info("Recreating credentials secret with another type", "secret", key.String(), "from", existing.Type, "to", secretType)
+	if r.Recorder != nil {
+		r.Recorder.Eventf(user, corev1.EventTypeNormal, "SecretTypeChanged", "Recreated credentials Secret %s with type %s, was %s", key, secretType, existing.Type)
+	}
+	return true, nil
+}
+
+// cleanupMovedSecret removes the previous credentials Secret after spec.secretName or spec.secretNamespace changed
+func (r *NatsUserReconciler) cleanupMovedSecret(ctx context.Context, user *natsv1alpha1.NatsUser, current *corev1.Secret) error {
+	previous := user.Status.SecretRef
+	if previous.Name == "" || previous.Namespace == "" || (previous.Namespace == current.Namespace && previous.Name == current.Name) {
+		return nil
+	}
+
+	if previous.Namespace == user.Namespace {
+		// Same-namespace Secrets are owned by the NatsUser and would otherwise linger until it is deleted
+		return r.deleteSecret(ctx, previous)
+	}
+	return r.deleteCrossNamespaceSecret(ctx, user, previous)
+}
+
+// deleteCrossNamespaceSecret deletes a credentials Secret outside the NatsUser namespace,
+// provided its tracking labels show it belongs to this NatsUser
+func (r *NatsUserReconciler) deleteCrossNamespaceSecret(ctx context.Context, user *natsv1alpha1.NatsUser, ref natsv1alpha1.SecretRef) error {
+	if ref.Name == "" || ref.Namespace == "" || ref.Namespace == user.Namespace {
+		return nil
+	}
+
+	secret, exists, err := secrets.Get(ctx, r.Client, client.ObjectKey{Namespace: ref.Namespace, Name: ref.Name})
+	if err != nil || !exists {
+		return err
+	}
+
+	if !userSecretTracking.Owns(user, secret) {
+		log.FromContext(ctx).Info("Credentials secret is not tracked by this NatsUser, leaving it", "secret", ref.Namespace+"/"+ref.Name)
+		return nil
+	}
+
+	return r.deleteSecret(ctx, ref)
+}
+
+func (r *NatsUserReconciler) deleteSecret(ctx context.Context, ref natsv1alpha1.SecretRef) error {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      ref.Name,
+			Namespace: ref.Namespace,
+		},
+	}
+	if err := r.Delete(ctx, secret); err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete credentials secret: %w", err)
+	}
+	return nil
+}
+
+// allowedConnectionTypes returns the connection types the user JWT is restricted to
+func allowedConnectionTypes(user *natsv1alpha1.NatsUser) []string {
+	if user.Spec.Purpose == natsv1alpha1.UserPurposeLeafNode {
+		return []string{jwtpkg.ConnectionTypeLeafNode}
+	}
+
+	types := make([]string, 0, len(user.Spec.AllowedConnectionTypes))
+	for _, t := range user.Spec.AllowedConnectionTypes {
+		types = append(types, string(t))
+	}
+	return types
+}
+
+// userPermissions returns the permissions of the user JWT; JetStream controller users
+// without explicit permissions get the JetStream API permissions
+func userPermissions(user *natsv1alpha1.NatsUser) *natsv1alpha1.Permissions {
+	if user.Spec.Permissions == nil && user.Spec.Purpose == natsv1alpha1.UserPurposeJetStreamController {
+		return jwtpkg.JetStreamControllerPermissions()
+	}
+	return user.Spec.Permissions
+}
+
+// wellKnownCreds reports whether the creds file is also written under the well-known nats.creds key
+func wellKnownCreds(user *natsv1alpha1.NatsUser) bool {
+	if user.Spec.Purpose == natsv1alpha1.UserPurposeJetStreamController {
+		return true
+	}
+	return user.Spec.Output != nil && user.Spec.Output.WellKnownKey
+}
+
+// credsMountPath returns the directory the credentials Secret is recommended to be mounted at
+func credsMountPath(user *natsv1alpha1.NatsUser) string {
+	if user.Spec.Output == nil {
+		return ""
+	}
+	return user.Spec.Output.MountPath
+}
+
+// leafNodeConfigMapName returns the ConfigMap receiving the rendered leafnodes block
+func leafNodeConfigMapName(user *natsv1alpha1.NatsUser) string {
+	if user.Spec.LeafNode != nil && user.Spec.LeafNode.ConfigMapName != "" {
+		return user.Spec.LeafNode.ConfigMapName
+	}
+	return fmt.Sprintf("%s-leafnode", user.Name)
+}
+
+// reconcileLeafNodeConfig renders the leafnodes remote block for the edge server next to the credentials Secret
+func (r *NatsUserReconciler) reconcileLeafNodeConfig(ctx context.Context, user *natsv1alpha1.NatsUser) error {
+	spec := user.Spec.LeafNode
+	if spec == nil {
+		return fmt.Errorf("leafNode is required when purpose is leafnode")
+	}
+
+	credentialsPath := spec.CredentialsPath
+	if credentialsPath == "" {
+		credentialsPath = "/etc/nats/leafnode/" + jwtpkg.CredsKey
+	}
+
+	conf := authconf.RenderLeafNodeConf([]authconf.LeafNodeRemote{
+		{
+			URLs:        spec.URLs,
+			Account:     spec.LocalAccount,
+			Credentials: credentialsPath,
+		},
+	})
+
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      leafNodeConfigMapName(user),
+			Namespace: credsSecretNamespace(user),
+		},
+		Data: map[string]string{
+			authconf.LeafNodeConfKey: conf,
+		},
+	}
+	if err := r.setSecretOwner(user, cm); err != nil {
+		return err
+	}
+
+	if err := resolver.ApplyConfigMap(ctx, r.Client, cm); err != nil {
+		return fmt.Errorf("failed to apply leafnode ConfigMap: %w", err)
+	}
+
+	log.FromContext(ctx).V(debugLevel).Info("Rendered leafnode config", "step", "leafnode-config", "configMap", cm.Namespace+"/"+cm.Name)
+	return nil
+}
+
+// deleteCrossNamespaceConfigMap deletes a companion ConfigMap outside the NatsUser namespace,
+// provided its tracking labels show it belongs to this NatsUser
+func (r *NatsUserReconciler) deleteCrossNamespaceConfigMap(ctx context.Context, user *natsv1alpha1.NatsUser, name string) error {
+	namespace := credsSecretNamespace(user)
+	if namespace == user.Namespace {
+		return nil
+	}
+
+	cm := &corev1.ConfigMap{}
+	if err := r.Get(ctx, client.ObjectKey{Namespace: namespace, Name: name}, cm); err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to get ConfigMap %s/%s: %w", namespace, name, err)
+	}
+
+	if !userSecretTracking.Owns(user, cm) {
+		return nil
+	}
+
+	if err := r.Delete(ctx, cm); err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete ConfigMap %s/%s: %w", namespace, name, err)
+	}
+	return nil
+}
+
+// findUserForSecret maps a labeled credentials Secret or a passwordFrom Secret back to its NatsUsers
+func (r *NatsUserReconciler) findUserForSecret(ctx context.Context, obj client.Object) []reconcile.Request {
+	var requests []reconcile.Request
+
+	// Users reading their password from this Secret pick up a rotated password
+	userList := &natsv1alpha1.NatsUserList{}
+	key := client.ObjectKeyFromObject(obj).String()
+	if err := r.List(ctx, userList, client.MatchingFields{passwordSecretIndex: key}); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to list NatsUsers for password secret", "secret", key)
+	}
+	for i := range userList.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&userList.Items[i])})
+	}
+
+	name := obj.GetLabels()[userNameLabel]
+	namespace := obj.GetLabels()[userNamespaceLabel]
+	if name == "" || namespace == "" || namespace == obj.GetNamespace() {
+		// Same-namespace Secrets are handled through their owner reference
+		return requests
+	}
+
+	return append(requests, reconcile.Request{
+		NamespacedName: types.NamespacedName{Namespace: namespace, Name: name},
+	})
+}
+
+// findPendingUsersForAccount enqueues pending NatsUsers referencing the NatsAccount
+func (r *NatsUserReconciler) findPendingUsersForAccount(ctx context.Context, obj client.Object) []reconcile.Request {
+	return r.findPendingUsers(ctx, func(user *natsv1alpha1.NatsUser) bool {
+		return hasAccount(user) && accountKey(user) == client.ObjectKeyFromObject(obj)
+	})
+}
+
+// findPendingUsersForAuthConfig enqueues pending NatsUsers referencing the NatsAuthConfig
+func (r *NatsUserReconciler) findPendingUsersForAuthConfig(ctx context.Context, obj client.Object) []reconcile.Request {
+	return r.findPendingUsers(ctx, func(user *natsv1alpha1.NatsUser) bool {
+		return authConfigKey(user) == client.ObjectKeyFromObject(obj)
+	})
+}
+
+// findUsersForAuthConfig enqueues every user of a NatsAuthConfig, so a changed claim policy is
+// applied to JWTs already issued
+func (r *NatsUserReconciler) findUsersForAuthConfig(ctx context.Context, obj client.Object) []reconcile.Request {
+	userList := &natsv1alpha1.NatsUserList{}
+	if err := r.List(ctx, userList, client.MatchingFields{authConfigIndex: client.ObjectKeyFromObject(obj).String()}); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to list NatsUsers")
+		return nil
+	}
+
+	requests := make([]reconcile.Request, 0, len(userList.Items))
+	for i := range userList.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&userList.Items[i])})
+	}
+	return requests
+}
+
+// userClaimsChangedPredicate passes NatsAuthConfig updates that change spec.userClaims,
+// spec.defaultAccountRef, or status.mode, which users inheriting the mode follow
+var userClaimsChangedPredicate = predicate.Funcs{
+	CreateFunc:  func(event.CreateEvent) bool { return false },
+	DeleteFunc:  func(event.DeleteEvent) bool { return false },
+	GenericFunc: func(event.GenericEvent) bool { return false },
+	UpdateFunc: func(e event.UpdateEvent) bool {
+		oldConfig, okOld := e.ObjectOld.(*natsv1alpha1.NatsAuthConfig)
+		newConfig, okNew := e.ObjectNew.(*natsv1alpha1.NatsAuthConfig)
+		return okOld && okNew && (!equality.Semantic.DeepEqual(oldConfig.Spec.UserClaims, newConfig.Spec.UserClaims) ||
+			!equality.Semantic.DeepEqual(oldConfig.Spec.DefaultAccountRef, newConfig.Spec.DefaultAccountRef) ||
+			oldConfig.Status.Mode != newConfig.Status.Mode)
+	},
+}
+
+func (r *NatsUserReconciler) findPendingUsers(ctx context.Context, matches func(*natsv1alpha1.NatsUser) bool) []reconcile.Request {
+	userList := &natsv1alpha1.NatsUserList{}
+	if err := r.List(ctx, userList); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to list NatsUsers")
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for i := range userList.Items {
+		user := &userList.Items[i]
+		if user.Status.State != natsv1alpha1.UserStatePending || !matches(user) {
+			continue
+		}
+		requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(user)})
+	}
+	return requests
 }
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *NatsUserReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	return ctrl.NewControllerManagedBy(mgr).
-		For(&natsv1alpha1.NatsUser{}).
-		Owns(&corev1.Secret{}).
+	if r.dependencyBackoff == nil {
+		r.dependencyBackoff = workqueue.NewItemExponentialFailureRateLimiter(dependencyBackoffBase, dependencyBackoffCap)
+	}
+
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &natsv1alpha1.NatsUser{}, passwordSecretIndex, indexUserByPasswordSecret); err != nil {
+		return err
+	}
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &natsv1alpha1.NatsUser{}, tokenUsernameIndex, indexUserByTokenUsername); err != nil {
+		return err
+	}
+
+	b := ctrl.NewControllerManagedBy(mgr).
+		For(&natsv1alpha1.NatsUser{})
+	if r.SecretAccess.WatchesSecrets() {
+		b = b.Owns(&corev1.Secret{}).
+			Watches(&corev1.Secret{}, handler.EnqueueRequestsFromMapFunc(r.findUserForSecret))
+	}
+	return b.
+		Owns(&corev1.ConfigMap{}).
+		Watches(&natsv1alpha1.NatsUser{}, handler.EnqueueRequestsFromMapFunc(r.findUsersSharingAccount)).
+		Watches(&natsv1alpha1.NatsAccount{}, handler.EnqueueRequestsFromMapFunc(r.findPendingUsersForAccount)).
+		Watches(&natsv1alpha1.NatsAuthConfig{}, handler.EnqueueRequestsFromMapFunc(r.findPendingUsersForAuthConfig)).
+		Watches(&natsv1alpha1.NatsAuthConfig{}, handler.EnqueueRequestsFromMapFunc(r.findUsersForAuthConfig),
+			builder.WithPredicates(userClaimsChangedPredicate)).
 		Complete(r)
 }
@@ -0,0 +1,162 @@
+package controller
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	natsv1alpha1 "github.com/jradikk/nats-auth-operator/api/v1alpha1"
+)
+
+// wantPending fails the test unless the user is Pending on a dependency for reason
+func wantPending(t *testing.T, c client.Client, user *natsv1alpha1.NatsUser, reason string) {
+	t.Helper()
+	mustGet(t, c, user)
+	dependencies := meta.FindStatusCondition(user.Status.Conditions, "DependenciesReady")
+	ready := meta.FindStatusCondition(user.Status.Conditions, "Ready")
+	if user.Status.State != natsv1alpha1.UserStatePending || dependencies == nil || dependencies.Status != metav1.ConditionFalse || dependencies.Reason != reason ||
+		ready == nil || ready.Reason != string(natsv1alpha1.ReasonDependencyNotReady) {
+		t.Errorf("Reconcile() state %q conditions %+v, want Pending with DependenciesReady %s", user.Status.State, user.Status.Conditions, reason)
+	}
+}
+
+func TestUserPendingOnDependencies(t *testing.T) {
+	user := testUser("orders-app", natsv1alpha1.UserAuthTypeJWT, "orders")
+	c, scheme := newTestClient(user)
+	authConfigs, accounts, users := testReconcilers(c, scheme)
+	ctx := context.Background()
+
+	// The wait for a missing NatsAuthConfig backs off exponentially
+	first := mustReconcile(t, users, user)
+	wantPending(t, c, user, "AuthConfigNotFound")
+	second := mustReconcile(t, users, user)
+	if first.RequeueAfter != dependencyBackoffBase || second.RequeueAfter != 2*dependencyBackoffBase {
+		t.Errorf("Reconcile() requeued after %v then %v, want %v doubling", first.RequeueAfter, second.RequeueAfter, dependencyBackoffBase)
+	}
+
+	// The dependency watches re-trigger the pending user
+	authConfig := testAuthConfig(natsv1alpha1.AuthModeJWT)
+	if err := c.Create(ctx, authConfig); err != nil {
+		t.Fatal(err)
+	}
+	if requests := users.findPendingUsersForAuthConfig(ctx, authConfig); len(requests) != 1 || requests[0].NamespacedName != client.ObjectKeyFromObject(user) {
+		t.Errorf("findPendingUsersForAuthConfig() = %v, want the pending user", requests)
+	}
+	mustReconcile(t, authConfigs, authConfig)
+	mustReconcile(t, users, user)
+	wantPending(t, c, user, "AccountNotFound")
+
+	account := testAccount("orders")
+	if err := c.Create(ctx, account); err != nil {
+		t.Fatal(err)
+	}
+	if requests := users.findPendingUsersForAccount(ctx, account); len(requests) != 1 || requests[0].NamespacedName != client.ObjectKeyFromObject(user) {
+		t.Errorf("findPendingUsersForAccount() = %v, want the pending user", requests)
+	}
+	mustReconcile(t, users, user)
+	wantPending(t, c, user, "AccountNotReady")
+
+	// Once the account is issued the user is ready and its backoff starts over
+	mustReconcile(t, accounts, account)
+	mustReconcile(t, users, user)
+	mustGet(t, c, user)
+	wantReady(t, user, user.Status.Conditions)
+	if user.Status.State != natsv1alpha1.UserStateReady || !meta.IsStatusConditionTrue(user.Status.Conditions, "DependenciesReady") {
+		t.Errorf("Reconcile() state %q conditions %+v, want Ready with DependenciesReady", user.Status.State, user.Status.Conditions)
+	}
+	if requests := users.findPendingUsersForAccount(ctx, account); len(requests) != 0 {
+		t.Errorf("findPendingUsersForAccount() = %v, want no pending users", requests)
+	}
+	if n := users.dependencyBackoff.NumRequeues(client.ObjectKeyFromObject(user)); n != 0 {
+		t.Errorf("dependency backoff after Ready = %d requeues, want it reset", n)
+	}
+}
+
+func TestUserPasswordFromSecretRotation(t *testing.T) {
+	authConfig := testAuthConfig(natsv1alpha1.AuthModeToken)
+	source := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "vault", Name: "orders-password"},
+		Data:       map[string][]byte{"nats": []byte("Zq8vN3rT1xWk5pLm9cYb2hGd")},
+	}
+	user := testUser("orders-app", natsv1alpha1.UserAuthTypeToken, "")
+	user.Spec.Username = "orders"
+	user.Spec.PasswordFrom = &natsv1alpha1.PasswordSource{SecretRef: &natsv1alpha1.PasswordSecretRef{Namespace: "vault", Name: "orders-password", Key: "nats"}}
+	c, scheme := newTestClient(authConfig, source, user)
+	authConfigs, _, users := testReconcilers(c, scheme)
+	ctx := context.Background()
+
+	// credentials checks the password of the user's credentials Secret and rendered config
+	credentials := func(want string) {
+		t.Helper()
+		mustReconcile(t, users, user)
+		mustReconcile(t, authConfigs, authConfig)
+		mustGet(t, c, user)
+		mustGet(t, c, source)
+		creds := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: user.Namespace, Name: user.Status.SecretRef.Name}}
+		mustGet(t, c, creds)
+		if string(creds.Data["PASSWORD"]) != want || user.Status.PasswordSourceVersion != source.ResourceVersion {
+			t.Errorf("Reconcile() wrote password %q from source version %q, want %q from %q", creds.Data["PASSWORD"], user.Status.PasswordSourceVersion, want, source.ResourceVersion)
+		}
+		if conf := string(serverAuthSecret(t, c).Data["auth.conf"]); !strings.Contains(conf, want) {
+			t.Errorf("Reconcile() rendered auth.conf without the password %q", want)
+		}
+	}
+	credentials("Zq8vN3rT1xWk5pLm9cYb2hGd")
+
+	// A rotated upstream password re-triggers the user and is rendered again
+	source.Data["nats"] = []byte("Hf4kR7sWq2NzX9bLc3VmT6pY")
+	if err := c.Update(ctx, source); err != nil {
+		t.Fatal(err)
+	}
+	if requests := users.findUserForSecret(ctx, source); len(requests) != 1 || requests[0].NamespacedName != client.ObjectKeyFromObject(user) {
+		t.Errorf("findUserForSecret() = %v, want the user reading its password from it", requests)
+	}
+	credentials("Hf4kR7sWq2NzX9bLc3VmT6pY")
+}
+
+func TestUserSecretNameCollision(t *testing.T) {
+	authConfig := testAuthConfig(natsv1alpha1.AuthModeToken)
+	user := testUser("orders-app", natsv1alpha1.UserAuthTypeToken, "")
+	secretName, err := userCredsSecretName(user, &natsv1alpha1.NatsOperatorSettingsSpec{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	unrelated := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: user.Namespace, Name: secretName},
+		Data:       map[string][]byte{"tls.crt": []byte("certificate")},
+	}
+	c, scheme := newTestClient(authConfig, unrelated, user)
+	_, _, users := testReconcilers(c, scheme)
+	ctx := context.Background()
+
+	// An existing Secret not written for the user is refused rather than adopted
+	mustReconcile(t, users, user)
+	mustGet(t, c, user)
+	ready := meta.FindStatusCondition(user.Status.Conditions, "Ready")
+	if ready == nil || ready.Status != metav1.ConditionFalse || ready.Reason != string(natsv1alpha1.ReasonSecretNameCollision) {
+		t.Errorf("Reconcile() Ready condition = %+v, want SecretNameCollision", ready)
+	}
+	mustGet(t, c, unrelated)
+	if len(unrelated.Data) != 1 || string(unrelated.Data["tls.crt"]) != "certificate" || len(unrelated.OwnerReferences) != 0 {
+		t.Errorf("Reconcile() changed the colliding Secret to %+v", unrelated)
+	}
+
+	// spec.secretName moves the credentials out of the way
+	user.Spec.SecretName = "orders-app-nats"
+	if err := c.Update(ctx, user); err != nil {
+		t.Fatal(err)
+	}
+	mustReconcile(t, users, user)
+	mustGet(t, c, user)
+	wantReady(t, user, user.Status.Conditions)
+	creds := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: user.Namespace, Name: "orders-app-nats"}}
+	mustGet(t, c, creds)
+	if user.Status.SecretRef.Name != creds.Name || len(creds.Data["PASSWORD"]) == 0 {
+		t.Errorf("Reconcile() wrote %s with %v, want the credentials in %s", user.Status.SecretRef.Name, creds.Data, creds.Name)
+	}
+}
@@ -0,0 +1,323 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	natsv1alpha1 "github.com/jradikk/nats-auth-operator/api/v1alpha1"
+	jwtpkg "github.com/jradikk/nats-auth-operator/internal/jwt"
+	"github.com/jradikk/nats-auth-operator/internal/secrets"
+	natslabels "github.com/jradikk/nats-auth-operator/pkg/labels"
+)
+
+// OrphanPolicy selects what the startup scan does with generated Secrets whose owner is gone
+type OrphanPolicy string
+
+const (
+	// OrphanPolicyReport lists orphans in the status of their NatsAuthConfig
+	OrphanPolicyReport OrphanPolicy = "report"
+	// OrphanPolicyDelete deletes orphaned Secrets; seed Secrets and preload entries are still
+	// only reported
+	OrphanPolicyDelete OrphanPolicy = "delete"
+)
+
+// ParseOrphanPolicy validates the value of --orphan-policy
+func ParseOrphanPolicy(s string) (OrphanPolicy, error) {
+	switch policy := OrphanPolicy(s); policy {
+	case OrphanPolicyReport, OrphanPolicyDelete:
+		return policy, nil
+	default:
+		return "", fmt.Errorf("unknown orphan policy %q, expected %q or %q", s, OrphanPolicyReport, OrphanPolicyDelete)
+	}
+}
+
+// Kinds of orphaned resources
+const (
+	orphanKindSecret       = "Secret"
+	orphanKindPreloadEntry = "PreloadEntry"
+)
+
+// orphan is an orphaned resource and the NatsAuthConfig it is reported under
+type orphan struct {
+	natsv1alpha1.OrphanedResource
+
+	// authConfig is the namespace and name of the NatsAuthConfig; an empty namespace matches
+	// every NatsAuthConfig of the name
+	authConfig types.NamespacedName
+
+	// seed marks Secrets holding an nkey seed, which are never deleted
+	seed bool
+}
+
+// OrphanScanner looks once, when the operator becomes leader, for generated Secrets whose owning
+// resource no longer exists and for accounts still preloaded by a server auth config after their
+// NatsAccount was deleted. Such leftovers appear when the operator was not running as an owner was
+// deleted, or when a Secret outside the owner's namespace only tracks it by labels.
+type OrphanScanner struct {
+	client.Client
+
+	// Reader reads without the cache, which may not hold every generated Secret
+	Reader client.Reader
+
+	Policy       OrphanPolicy
+	SecretAccess SecretAccess
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable
+func (s *OrphanScanner) NeedLeaderElection() bool {
+	return true
+}
+
+// Start implements manager.Runnable. A failed scan is logged and does not stop the manager.
+func (s *OrphanScanner) Start(ctx context.Context) error {
+	logger := log.FromContext(ctx).WithName("orphans")
+	if err := s.Scan(log.IntoContext(ctx, logger)); err != nil {
+		logger.Error(err, "Orphan scan failed")
+	}
+	return nil
+}
+
+// Scan finds the orphans, deletes those the policy allows and reports the rest
+func (s *OrphanScanner) Scan(ctx context.Context) error {
+	log := log.FromContext(ctx)
+
+	var authConfigs natsv1alpha1.NatsAuthConfigList
+	if err := s.Reader.List(ctx, &authConfigs); err != nil {
+		return fmt.Errorf("failed to list NatsAuthConfigs: %w", err)
+	}
+	var accounts natsv1alpha1.NatsAccountList
+	if err := s.Reader.List(ctx, &accounts); err != nil {
+		return fmt.Errorf("failed to list NatsAccounts: %w", err)
+	}
+	existingAccounts := map[types.NamespacedName]bool{}
+	for i := range accounts.Items {
+		existingAccounts[client.ObjectKeyFromObject(&accounts.Items[i])] = true
+	}
+
+	var orphans []orphan
+	// Restricted access cannot list Secrets, so only the server auth configs are checked
+	if s.SecretAccess.WatchesSecrets() {
+		found, err := s.orphanedSecrets(ctx)
+		if err != nil {
+			return err
+		}
+		orphans = append(orphans, found...)
+	}
+	for i := range authConfigs.Items {
+		found, err := s.stalePreloadEntries(ctx, &authConfigs.Items[i], existingAccounts)
+		if err != nil {
+			return err
+		}
+		orphans = append(orphans, found...)
+	}
+
+	var reported []orphan
+	for _, o := range orphans {
+		if s.Policy != OrphanPolicyDelete || o.Kind != orphanKindSecret || o.seed {
+			log.Info("Found orphaned resource", "kind", o.Kind, "namespace", o.Namespace, "name", o.Name, "owner", o.Owner)
+			reported = append(reported, o)
+			continue
+		}
+		secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: o.Namespace, Name: o.Name}}
+		if err := s.Delete(ctx, secret); client.IgnoreNotFound(err) != nil {
+			return fmt.Errorf("failed to delete orphaned secret %s/%s: %w", o.Namespace, o.Name, err)
+		}
+		log.Info("Deleted orphaned secret", "namespace", o.Namespace, "name", o.Name, "owner", o.Owner)
+	}
+
+	for i := range authConfigs.Items {
+		if err := s.report(ctx, &authConfigs.Items[i], reported); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// orphanedSecrets returns the generated Secrets whose controlling resource or tracked owner is gone
+func (s *OrphanScanner) orphanedSecrets(ctx context.Context) ([]orphan, error) {
+	generated, err := natslabels.ListSecrets(ctx, s.Reader, natslabels.Owners{})
+	if err != nil {
+		return nil, err
+	}
+
+	var orphans []orphan
+	for i := range generated {
+		secret := &generated[i]
+		// Server auth configs outlive their NatsAuthConfig under the Retain deletion policy
+		if _, ok := secret.Annotations[configHashAnnotation]; ok {
+			continue
+		}
+		owner, uid, ok := secretOwner(secret)
+		if !ok {
+			continue
+		}
+		exists, err := s.ownerExists(ctx, owner, uid)
+		if err != nil {
+			return nil, err
+		}
+		if exists {
+			continue
+		}
+
+		o := orphan{
+			OrphanedResource: natsv1alpha1.OrphanedResource{
+				Kind:      orphanKindSecret,
+				Namespace: secret.Namespace,
+				Name:      secret.Name,
+				Owner:     fmt.Sprintf("%s %s/%s", owner.Kind, owner.Namespace, owner.Name),
+			},
+			authConfig: types.NamespacedName{Name: secret.Labels[natslabels.AuthConfig]},
+			seed:       secret.Labels[jwtpkg.SeedTypeLabel] != "",
+		}
+		if name, namespace := secret.Labels[authConfigNameLabel], secret.Labels[authConfigNamespaceLabel]; name != "" && namespace != "" {
+			o.authConfig = types.NamespacedName{Namespace: namespace, Name: name}
+		}
+		orphans = append(orphans, o)
+	}
+	return orphans, nil
+}
+
+// orphanOwner is the resource a generated Secret belongs to
+type orphanOwner struct {
+	schema.GroupVersionKind
+	types.NamespacedName
+}
+
+// secretOwner returns the resource owning a generated Secret: its controller when that is one of
+// the operator's resources, otherwise the NatsUser or NatsAuthConfig tracked by its labels. The
+// UID is only known for controllers.
+func secretOwner(secret *corev1.Secret) (orphanOwner, types.UID, bool) {
+	if ref := metav1.GetControllerOf(secret); ref != nil {
+		gv, err := schema.ParseGroupVersion(ref.APIVersion)
+		if err != nil || gv.Group != natsv1alpha1.GroupVersion.Group {
+			return orphanOwner{}, "", false
+		}
+		return orphanOwner{
+			GroupVersionKind: gv.WithKind(ref.Kind),
+			NamespacedName:   types.NamespacedName{Namespace: secret.Namespace, Name: ref.Name},
+		}, ref.UID, true
+	}
+	for _, tracked := range []struct {
+		kind     string
+		tracking secrets.Tracking
+	}{{"NatsUser", userSecretTracking}, {"NatsAuthConfig", authConfigTracking}} {
+		name, namespace := secret.Labels[tracked.tracking.NameLabel], secret.Labels[tracked.tracking.NamespaceLabel]
+		if name == "" || namespace == "" {
+			continue
+		}
+		return orphanOwner{
+			GroupVersionKind: natsv1alpha1.GroupVersion.WithKind(tracked.kind),
+			NamespacedName:   types.NamespacedName{Namespace: namespace, Name: name},
+		}, "", true
+	}
+	return orphanOwner{}, "", false
+}
+
+// ownerExists reports whether the owner exists, with the given UID when one is set
+func (s *OrphanScanner) ownerExists(ctx context.Context, owner orphanOwner, uid types.UID) (bool, error) {
+	obj := &metav1.PartialObjectMetadata{}
+	obj.SetGroupVersionKind(owner.GroupVersionKind)
+	if err := s.Reader.Get(ctx, owner.NamespacedName, obj); err != nil {
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to get %s %s: %w", owner.Kind, owner.NamespacedName, err)
+	}
+	return uid == "" || obj.UID == uid, nil
+}
+
+// stalePreloadEntries returns the accounts listed in the account index of the server auth config
+// whose NatsAccount is gone. The NatsAuthConfig controller drops them with its next write of the
+// Secret, so they are only reported.
+func (s *OrphanScanner) stalePreloadEntries(ctx context.Context, authConfig *natsv1alpha1.NatsAuthConfig, existingAccounts map[types.NamespacedName]bool) ([]orphan, error) {
+	key := client.ObjectKey{Namespace: authConfig.Spec.ServerAuthConfig.Namespace, Name: authConfig.Spec.ServerAuthConfig.Name}
+	secret, exists, err := secrets.Get(ctx, s.Reader, key)
+	if err != nil || !exists || !authConfigTracking.Owns(authConfig, secret) {
+		return nil, err
+	}
+	raw := secret.Annotations[accountIndexAnnotation]
+	if raw == "" {
+		return nil, nil
+	}
+	var index map[string]string
+	if err := json.Unmarshal([]byte(raw), &index); err != nil {
+		log.FromContext(ctx).Error(err, "Ignoring invalid account index", "secret", key)
+		return nil, nil
+	}
+
+	var orphans []orphan
+	for account, pubKey := range index {
+		namespace, name, ok := strings.Cut(account, "/")
+		if !ok || existingAccounts[types.NamespacedName{Namespace: namespace, Name: name}] {
+			continue
+		}
+		orphans = append(orphans, orphan{
+			OrphanedResource: natsv1alpha1.OrphanedResource{
+				Kind:      orphanKindPreloadEntry,
+				Namespace: namespace,
+				Name:      pubKey,
+				Owner:     "NatsAccount " + account,
+			},
+			authConfig: client.ObjectKeyFromObject(authConfig),
+		})
+	}
+	return orphans, nil
+}
+
+// report sets the orphans of the NatsAuthConfig in its status, clearing those of an earlier scan
+func (s *OrphanScanner) report(ctx context.Context, authConfig *natsv1alpha1.NatsAuthConfig, orphans []orphan) error {
+	var found []natsv1alpha1.OrphanedResource
+	for _, o := range orphans {
+		if o.authConfig.Name == authConfig.Name && (o.authConfig.Namespace == "" || o.authConfig.Namespace == authConfig.Namespace) {
+			found = append(found, o.OrphanedResource)
+		}
+	}
+	sort.Slice(found, func(i, j int) bool {
+		a, b := found[i], found[j]
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		return a.Name < b.Name
+	})
+	if equality.Semantic.DeepEqual(found, authConfig.Status.Orphans) {
+		return nil
+	}
+
+	base := authConfig.DeepCopy()
+	authConfig.Status.Orphans = found
+	if err := s.Status().Patch(ctx, authConfig, client.MergeFrom(base)); err != nil {
+		return fmt.Errorf("failed to report orphans of NatsAuthConfig %s/%s: %w", authConfig.Namespace, authConfig.Name, err)
+	}
+	return nil
+}
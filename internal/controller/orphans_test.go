@@ -0,0 +1,152 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	natsv1alpha1 "github.com/jradikk/nats-auth-operator/api/v1alpha1"
+	jwtpkg "github.com/jradikk/nats-auth-operator/internal/jwt"
+	natslabels "github.com/jradikk/nats-auth-operator/pkg/labels"
+)
+
+func TestParseOrphanPolicy(t *testing.T) {
+	for value, wantErr := range map[string]bool{"report": false, "delete": false, "": true, "ignore": true} {
+		if _, err := ParseOrphanPolicy(value); (err != nil) != wantErr {
+			t.Errorf("ParseOrphanPolicy(%q) error = %v, want error %v", value, err, wantErr)
+		}
+	}
+}
+
+func TestOrphanScanner(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = natsv1alpha1.AddToScheme(scheme)
+
+	generated := func(namespace, name string, owners natslabels.Owners, labels map[string]string) *corev1.Secret {
+		secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name, Labels: labels}}
+		natslabels.Stamp(secret, owners)
+		return secret
+	}
+	controlledBy := func(secret *corev1.Secret, kind, name, uid string) *corev1.Secret {
+		isController := true
+		secret.OwnerReferences = []metav1.OwnerReference{{
+			APIVersion: natsv1alpha1.GroupVersion.String(),
+			Kind:       kind,
+			Name:       name,
+			UID:        types.UID("uid-" + uid),
+			Controller: &isController,
+		}}
+		return secret
+	}
+
+	objects := func() []client.Object {
+		return []client.Object{
+			&natsv1alpha1.NatsAuthConfig{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "nats", Name: "main"},
+				Spec: natsv1alpha1.NatsAuthConfigSpec{
+					ServerAuthConfig: natsv1alpha1.ServerAuthConfigRef{Namespace: "nats", Name: "nats-auth"},
+				},
+			},
+			&natsv1alpha1.NatsAccount{ObjectMeta: metav1.ObjectMeta{Namespace: "nats", Name: "orders", UID: "uid-orders"}},
+			&natsv1alpha1.NatsUser{ObjectMeta: metav1.ObjectMeta{Namespace: "nats", Name: "app"}},
+			// Server auth config listing a deleted account
+			func() *corev1.Secret {
+				secret := generated("nats", "nats-auth", natslabels.Owners{AuthConfig: "main"}, map[string]string{
+					authConfigNameLabel: "main", authConfigNamespaceLabel: "nats",
+				})
+				secret.Annotations = map[string]string{
+					configHashAnnotation:   "hash",
+					accountIndexAnnotation: `{"nats/orders":"AORDERS","nats/legacy":"ALEGACY"}`,
+				}
+				return secret
+			}(),
+			// Account JWT Secret of a live account
+			controlledBy(generated("nats", "orders-jwt", natslabels.Owners{AuthConfig: "main", Account: "orders"}, nil), "NatsAccount", "orders", "orders"),
+			// Account JWT Secret of a deleted account, holding its seed
+			controlledBy(generated("nats", "legacy-jwt", natslabels.Owners{AuthConfig: "main", Account: "legacy"}, map[string]string{
+				jwtpkg.SeedTypeLabel: "account",
+			}), "NatsAccount", "legacy", "legacy"),
+			// Account JWT Secret of an account recreated under the same name
+			controlledBy(generated("nats", "orders-old-jwt", natslabels.Owners{AuthConfig: "main", Account: "orders"}, nil), "NatsAccount", "orders", "old"),
+			// Credentials of a live and a deleted user in another namespace
+			generated("apps", "app-creds", natslabels.Owners{AuthConfig: "main", User: "app"}, map[string]string{
+				userNameLabel: "app", userNamespaceLabel: "nats",
+			}),
+			generated("apps", "gone-creds", natslabels.Owners{AuthConfig: "main", User: "gone"}, map[string]string{
+				userNameLabel: "gone", userNamespaceLabel: "nats",
+			}),
+			// Server auth config retained after its NatsAuthConfig was deleted
+			func() *corev1.Secret {
+				secret := generated("nats", "retained-auth", natslabels.Owners{AuthConfig: "retained"}, map[string]string{
+					authConfigNameLabel: "retained", authConfigNamespaceLabel: "nats",
+				})
+				secret.Annotations = map[string]string{configHashAnnotation: "hash"}
+				return secret
+			}(),
+		}
+	}
+
+	for _, tt := range []struct {
+		policy      OrphanPolicy
+		wantDeleted []string
+	}{
+		{policy: OrphanPolicyReport},
+		{policy: OrphanPolicyDelete, wantDeleted: []string{"apps/gone-creds", "nats/orders-old-jwt"}},
+	} {
+		objs := objects()
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).WithStatusSubresource(&natsv1alpha1.NatsAuthConfig{}).Build()
+
+		s := &OrphanScanner{Client: c, Reader: c, Policy: tt.policy, SecretAccess: SecretAccessCluster}
+		if err := s.Scan(context.Background()); err != nil {
+			t.Fatalf("%s: Scan() error = %v", tt.policy, err)
+		}
+
+		want := []natsv1alpha1.OrphanedResource{
+			{Kind: orphanKindPreloadEntry, Namespace: "nats", Name: "ALEGACY", Owner: "NatsAccount nats/legacy"},
+			{Kind: orphanKindSecret, Namespace: "apps", Name: "gone-creds", Owner: "NatsUser nats/gone"},
+			{Kind: orphanKindSecret, Namespace: "nats", Name: "legacy-jwt", Owner: "NatsAccount nats/legacy"},
+			{Kind: orphanKindSecret, Namespace: "nats", Name: "orders-old-jwt", Owner: "NatsAccount nats/orders"},
+		}
+		if tt.policy == OrphanPolicyDelete {
+			// Only the seed Secret and the preload entry are left to report
+			want = []natsv1alpha1.OrphanedResource{want[0], want[2]}
+		}
+		var authConfig natsv1alpha1.NatsAuthConfig
+		if err := c.Get(context.Background(), client.ObjectKey{Namespace: "nats", Name: "main"}, &authConfig); err != nil {
+			t.Fatal(err)
+		}
+		if len(authConfig.Status.Orphans) != len(want) {
+			t.Fatalf("%s: status.orphans = %+v, want %+v", tt.policy, authConfig.Status.Orphans, want)
+		}
+		for i := range want {
+			if authConfig.Status.Orphans[i] != want[i] {
+				t.Errorf("%s: status.orphans[%d] = %+v, want %+v", tt.policy, i, authConfig.Status.Orphans[i], want[i])
+			}
+		}
+
+		deleted := map[string]bool{}
+		for _, name := range tt.wantDeleted {
+			deleted[name] = true
+		}
+		for _, obj := range objs {
+			secret, ok := obj.(*corev1.Secret)
+			if !ok {
+				continue
+			}
+			key := secret.Namespace + "/" + secret.Name
+			err := c.Get(context.Background(), client.ObjectKeyFromObject(secret), &corev1.Secret{})
+			if gone := apierrors.IsNotFound(err); gone != deleted[key] {
+				t.Errorf("%s: Secret %s deleted = %v, want %v", tt.policy, key, gone, deleted[key])
+			}
+		}
+	}
+}
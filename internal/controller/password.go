@@ -0,0 +1,76 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	natsv1alpha1 "github.com/jradikk/nats-auth-operator/api/v1alpha1"
+	"github.com/jradikk/nats-auth-operator/internal/random"
+	"github.com/jradikk/nats-auth-operator/internal/token"
+)
+
+// defaultPasswordKey is the Secret key read when a password secretRef sets no key
+const defaultPasswordKey = "password"
+
+// readPasswordSecret reads the password referenced by ref and returns it with the
+// resourceVersion of its Secret
+func readPasswordSecret(ctx context.Context, c client.Client, ref *natsv1alpha1.PasswordSecretRef, namespace string) (string, string, error) {
+	secret := &corev1.Secret{}
+	key := ref.ObjectKey(namespace)
+	if err := c.Get(ctx, key, secret); err != nil {
+		return "", "", fmt.Errorf("failed to get password secret: %w", err)
+	}
+
+	dataKey := ref.Key
+	if dataKey == "" {
+		dataKey = defaultPasswordKey
+	}
+	password := string(secret.Data[dataKey])
+	if password == "" {
+		return "", "", fmt.Errorf("password key %q not found in secret %s/%s", dataKey, key.Namespace, key.Name)
+	}
+	return password, secret.ResourceVersion, nil
+}
+
+// passwordPolicy layers the policy of a PasswordSource over the operator defaults
+func passwordPolicy(defaults token.PasswordPolicy, source *natsv1alpha1.PasswordSource) token.PasswordPolicy {
+	policy := token.DefaultPasswordPolicy().Merge(defaults)
+	if source == nil || source.Policy == nil {
+		return policy
+	}
+	return policy.Merge(token.PasswordPolicy{
+		Length:           source.Policy.Length,
+		Charset:          source.Policy.Charset,
+		RequireSymbols:   source.Policy.RequireSymbols,
+		ExcludeAmbiguous: source.Policy.ExcludeAmbiguous,
+		MinEntropyBits:   source.Policy.MinEntropyBits,
+	})
+}
+
+// resolveGeneratedPassword keeps the current password while it satisfies the policy
+// and generates a new one with g otherwise
+func resolveGeneratedPassword(g random.Generator, policy token.PasswordPolicy, current string) (string, error) {
+	if current != "" && policy.Satisfied(current) {
+		return current, nil
+	}
+	return g.Password(policy)
+}
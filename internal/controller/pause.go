@@ -0,0 +1,61 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// pausedCondition reports whether spec.paused stops the reconciliation of a resource
+const pausedCondition = "Paused"
+
+// reconcilePaused records the Paused condition of obj and reports whether its reconciliation
+// stops here. A paused resource keeps its status and generated objects as they are; deletion
+// still proceeds, as it is handled before. Resuming sets the condition false and goes on with
+// a full reconcile.
+func reconcilePaused(ctx context.Context, c client.Client, obj client.Object, paused bool, conditions []metav1.Condition, update func(metav1.Condition)) (bool, error) {
+	current := meta.FindStatusCondition(conditions, pausedCondition)
+	wasPaused := current != nil && current.Status == metav1.ConditionTrue
+	switch {
+	case paused && !wasPaused:
+		log.FromContext(ctx).Info("Reconciliation paused")
+		update(metav1.Condition{
+			Type:    pausedCondition,
+			Status:  metav1.ConditionTrue,
+			Reason:  "SpecPaused",
+			Message: "Reconciliation is paused by spec.paused; nothing is written until it is unset",
+		})
+		return true, patchStatus(ctx, c, obj)
+	case paused:
+		return true, nil
+	case wasPaused:
+		log.FromContext(ctx).Info("Reconciliation resumed")
+		update(metav1.Condition{
+			Type:    pausedCondition,
+			Status:  metav1.ConditionFalse,
+			Reason:  "Resumed",
+			Message: "Reconciliation resumed after spec.paused was unset",
+		})
+		return false, patchStatus(ctx, c, obj)
+	}
+	return false, nil
+}
@@ -0,0 +1,98 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	natsv1alpha1 "github.com/jradikk/nats-auth-operator/api/v1alpha1"
+)
+
+func TestReconcilePaused(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = natsv1alpha1.AddToScheme(scheme)
+
+	nab := &natsv1alpha1.NatsAuthBackup{ObjectMeta: metav1.ObjectMeta{Namespace: "nats", Name: "backup"}}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(nab).WithStatusSubresource(nab).Build()
+	r := &NatsAuthBackupReconciler{Client: c, Scheme: scheme}
+	ctx := context.Background()
+	key := client.ObjectKeyFromObject(nab)
+
+	for _, step := range []struct {
+		paused     bool
+		wantPaused bool
+		wantStatus metav1.ConditionStatus
+	}{
+		{paused: true, wantPaused: true, wantStatus: metav1.ConditionTrue},
+		{paused: true, wantPaused: true, wantStatus: metav1.ConditionTrue},
+		{paused: false, wantStatus: metav1.ConditionFalse},
+		{paused: false, wantStatus: metav1.ConditionFalse},
+	} {
+		if err := c.Get(ctx, key, nab); err != nil {
+			t.Fatal(err)
+		}
+		nab.Spec.Paused = step.paused
+		stepCtx := withStatusBase(ctx, nab)
+		paused, err := reconcilePaused(stepCtx, c, nab, nab.Spec.Paused, nab.Status.Conditions, func(condition metav1.Condition) {
+			r.updateCondition(nab, condition)
+		})
+		if err != nil {
+			t.Fatalf("reconcilePaused() error = %v", err)
+		}
+		if paused != step.wantPaused {
+			t.Errorf("reconcilePaused(paused=%v) = %v, want %v", step.paused, paused, step.wantPaused)
+		}
+
+		if err := c.Get(ctx, key, nab); err != nil {
+			t.Fatal(err)
+		}
+		condition := meta.FindStatusCondition(nab.Status.Conditions, pausedCondition)
+		if condition == nil || condition.Status != step.wantStatus {
+			t.Errorf("reconcilePaused(paused=%v) condition = %+v, want status %s", step.paused, condition, step.wantStatus)
+		}
+	}
+}
+
+func TestPausedBackupWritesNothing(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = natsv1alpha1.AddToScheme(scheme)
+
+	nab := &natsv1alpha1.NatsAuthBackup{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "nats", Name: "backup"},
+		Spec:       natsv1alpha1.NatsAuthBackupSpec{Paused: true},
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(nab).WithStatusSubresource(nab).Build()
+	r := &NatsAuthBackupReconciler{Client: c, Scheme: scheme}
+
+	result, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: client.ObjectKeyFromObject(nab)})
+	if err != nil || result != (ctrl.Result{}) {
+		t.Errorf("Reconcile() = %+v, %v, want no requeue", result, err)
+	}
+
+	var secrets corev1.SecretList
+	if err := c.List(context.Background(), &secrets); err != nil {
+		t.Fatal(err)
+	}
+	if len(secrets.Items) != 0 {
+		t.Errorf("Reconcile() of a paused backup wrote %d Secrets", len(secrets.Items))
+	}
+	if err := c.Get(context.Background(), client.ObjectKeyFromObject(nab), nab); err != nil {
+		t.Fatal(err)
+	}
+	if nab.Status.LastReconciled != nil || meta.FindStatusCondition(nab.Status.Conditions, "Ready") != nil {
+		t.Errorf("Reconcile() of a paused backup updated its status: %+v", nab.Status)
+	}
+	if !meta.IsStatusConditionTrue(nab.Status.Conditions, pausedCondition) {
+		t.Errorf("Reconcile() conditions = %+v, want Paused", nab.Status.Conditions)
+	}
+}
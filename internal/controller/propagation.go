@@ -0,0 +1,138 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	natsv1alpha1 "github.com/jradikk/nats-auth-operator/api/v1alpha1"
+	natslabels "github.com/jradikk/nats-auth-operator/pkg/labels"
+)
+
+// operatorKeyPrefix marks the labels and annotations the operator manages itself; they are never propagated
+const operatorKeyPrefix = "nats.jradikk/"
+
+// propagationRules returns the settings' propagation rules extended by the selectors of a resource
+func propagationRules(settings *natsv1alpha1.NatsOperatorSettingsSpec, labels, annotations []string) natsv1alpha1.PropagationRules {
+	var rules natsv1alpha1.PropagationRules
+	if settings.Propagation != nil {
+		rules.Labels = append(rules.Labels, settings.Propagation.Labels...)
+		rules.Annotations = append(rules.Annotations, settings.Propagation.Annotations...)
+	}
+	rules.Labels = append(rules.Labels, labels...)
+	rules.Annotations = append(rules.Annotations, annotations...)
+	return rules
+}
+
+// userPropagation returns the propagation rules for the Secrets of a user
+func userPropagation(user *natsv1alpha1.NatsUser, settings *natsv1alpha1.NatsOperatorSettingsSpec) natsv1alpha1.PropagationRules {
+	return propagationRules(settings, user.Spec.PropagateLabels, user.Spec.PropagateAnnotations)
+}
+
+// accountPropagation returns the propagation rules for the Secrets of an account
+func accountPropagation(account *natsv1alpha1.NatsAccount, settings *natsv1alpha1.NatsOperatorSettingsSpec) natsv1alpha1.PropagationRules {
+	return propagationRules(settings, account.Spec.PropagateLabels, account.Spec.PropagateAnnotations)
+}
+
+// propagateMetadata copies the labels and annotations selected by the rules from a resource
+// to a generated object, without overwriting keys the operator sets itself
+func propagateMetadata(dst, src metav1.Object, rules natsv1alpha1.PropagationRules) {
+	dst.SetLabels(mergeKeys(dst.GetLabels(), selectKeys(src.GetLabels(), rules.Labels)))
+	dst.SetAnnotations(mergeKeys(dst.GetAnnotations(), selectKeys(src.GetAnnotations(), rules.Annotations)))
+}
+
+// syncPropagatedMetadata patches the selected labels and annotations, and the well-known labels of
+// the owners, onto an existing Secret whose content is up to date, so a propagation change does
+// not require re-issuing credentials and Secrets written before the well-known labels get them
+func syncPropagatedMetadata(ctx context.Context, c client.Client, secret *corev1.Secret, src metav1.Object, rules natsv1alpha1.PropagationRules, owners natslabels.Owners) error {
+	labels := overwriteKeys(selectKeys(src.GetLabels(), rules.Labels), owners.Labels())
+	annotations := selectKeys(src.GetAnnotations(), rules.Annotations)
+	if containsKeys(secret.Labels, labels) && containsKeys(secret.Annotations, annotations) {
+		return nil
+	}
+
+	patch := client.MergeFrom(secret.DeepCopy())
+	secret.Labels = overwriteKeys(secret.Labels, labels)
+	secret.Annotations = overwriteKeys(secret.Annotations, annotations)
+	if err := c.Patch(ctx, secret, patch); err != nil {
+		return fmt.Errorf("failed to propagate metadata to secret %s/%s: %w", secret.Namespace, secret.Name, err)
+	}
+	return nil
+}
+
+// selectKeys returns the entries of m whose keys match a rule; a rule ending in "*" matches by prefix.
+// Keys in the operator's own domain are never selected.
+func selectKeys(m map[string]string, rules []string) map[string]string {
+	selected := map[string]string{}
+	for k, v := range m {
+		if strings.HasPrefix(k, operatorKeyPrefix) {
+			continue
+		}
+		for _, rule := range rules {
+			prefix, isPrefix := strings.CutSuffix(rule, "*")
+			if k == rule || (isPrefix && strings.HasPrefix(k, prefix)) {
+				selected[k] = v
+				break
+			}
+		}
+	}
+	return selected
+}
+
+// mergeKeys adds the entries of src missing from dst
+func mergeKeys(dst, src map[string]string) map[string]string {
+	for k, v := range src {
+		if _, ok := dst[k]; ok {
+			continue
+		}
+		if dst == nil {
+			dst = map[string]string{}
+		}
+		dst[k] = v
+	}
+	return dst
+}
+
+// overwriteKeys sets the entries of src in dst
+func overwriteKeys(dst, src map[string]string) map[string]string {
+	if len(src) == 0 {
+		return dst
+	}
+	if dst == nil {
+		dst = map[string]string{}
+	}
+	for k, v := range src {
+		dst[k] = v
+	}
+	return dst
+}
+
+// containsKeys reports whether m holds every entry of want
+func containsKeys(m, want map[string]string) bool {
+	for k, v := range want {
+		if current, ok := m[k]; !ok || current != v {
+			return false
+		}
+	}
+	return true
+}
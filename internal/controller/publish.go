@@ -0,0 +1,184 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	natsv1alpha1 "github.com/jradikk/nats-auth-operator/api/v1alpha1"
+	"github.com/jradikk/nats-auth-operator/internal/authconf"
+	"github.com/jradikk/nats-auth-operator/internal/hooks"
+	jwtpkg "github.com/jradikk/nats-auth-operator/internal/jwt"
+	"github.com/jradikk/nats-auth-operator/internal/publish"
+	"github.com/jradikk/nats-auth-operator/internal/secrets"
+)
+
+// Keys of the access key pair in the bucket credentials Secret
+const (
+	bucketAccessKeyIDKey     = "accessKeyID"
+	bucketSecretAccessKeyKey = "secretAccessKey"
+)
+
+// accountPublishers builds the publishers of spec.jwt.publish
+func (r *NatsAuthConfigReconciler) accountPublishers(ctx context.Context, authConfig *natsv1alpha1.NatsAuthConfig) ([]publish.Publisher, error) {
+	spec := authConfig.Spec.JWT.Publish
+	var publishers []publish.Publisher
+
+	if target := spec.AccountServer; target != nil {
+		httpClient, err := hooks.NewHTTPClient([]byte(target.CABundle), time.Duration(target.TimeoutSeconds)*time.Second)
+		if err != nil {
+			return nil, permanent(natsv1alpha1.ReasonInvalidSpec, fmt.Errorf("invalid account server: %w", err))
+		}
+		publishers = append(publishers, &publish.AccountServer{URL: target.URL, Client: httpClient})
+	}
+
+	if target := spec.Bucket; target != nil {
+		httpClient, err := hooks.NewHTTPClient([]byte(target.CABundle), time.Duration(target.TimeoutSeconds)*time.Second)
+		if err != nil {
+			return nil, permanent(natsv1alpha1.ReasonInvalidSpec, fmt.Errorf("invalid bucket: %w", err))
+		}
+		key := target.CredentialsSecret.ObjectKey(authConfig.Namespace)
+		secret, exists, err := secrets.Get(ctx, r.Client, key)
+		if err != nil {
+			return nil, err
+		}
+		creds := publish.Credentials{
+			AccessKeyID:     string(secret.Data[bucketAccessKeyIDKey]),
+			SecretAccessKey: string(secret.Data[bucketSecretAccessKeyKey]),
+		}
+		if !exists || creds.AccessKeyID == "" || creds.SecretAccessKey == "" {
+			return nil, &dependencyError{
+				Kind:    "Secret",
+				Name:    key.String(),
+				Reason:  "BucketCredentialsNotFound",
+				Message: fmt.Sprintf("%s and %s are required", bucketAccessKeyIDKey, bucketSecretAccessKeyKey),
+			}
+		}
+		publishers = append(publishers, &publish.Bucket{
+			Endpoint:    target.Endpoint,
+			Name:        target.Name,
+			Region:      target.Region,
+			Prefix:      target.Prefix,
+			Credentials: creds,
+			Client:      httpClient,
+		})
+	}
+	return publishers, nil
+}
+
+// publishTargetsHash identifies the publish targets and the resolver targets written through
+// publishers, so changing them publishes every JWT again. KV targets hold the operator JWT as
+// well, which is put again with the account JWTs when it changes.
+func publishTargetsHash(spec *natsv1alpha1.AccountPublishing, pushed []natsv1alpha1.ResolverTarget, operatorJWT string) string {
+	data, _ := json.Marshal(spec)
+	if len(pushed) > 0 {
+		var operator string
+		if hasResolverTarget(pushed, natsv1alpha1.ResolverTargetKV) {
+			operator = jwtpkg.Fingerprint(operatorJWT)
+		}
+		data, _ = json.Marshal(struct {
+			Publish  *natsv1alpha1.AccountPublishing `json:"publish"`
+			Resolver []natsv1alpha1.ResolverTarget   `json:"resolver"`
+			Operator string                          `json:"operator,omitempty"`
+		}{spec, pushed, operator})
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// publishAccounts mirrors the account JWTs to the targets of spec.jwt.publish and to the
+// Directory, NATS and KV resolver targets. Only JWTs that changed since they were last published are
+// sent; accounts that are gone are deleted from the targets. Progress is kept in status, so a
+// failing target resumes where it stopped.
+func (r *NatsAuthConfigReconciler) publishAccounts(ctx context.Context, authConfig *natsv1alpha1.NatsAuthConfig, targets []natsv1alpha1.ResolverTarget, operatorJWT string, accounts []authconf.AccountJWT) error {
+	log := log.FromContext(ctx)
+
+	spec := authConfig.Spec.JWT.Publish
+	pushed := pushedResolverTargets(targets)
+	if spec == nil && len(pushed) == 0 {
+		authConfig.Status.PublishedAccounts = nil
+		authConfig.Status.PublishTargetsHash = ""
+		return nil
+	}
+	var publishers []publish.Publisher
+	if spec != nil {
+		var err error
+		if publishers, err = r.accountPublishers(ctx, authConfig); err != nil {
+			return err
+		}
+	}
+	resolverPublishers, err := r.resolverPublishers(ctx, authConfig, targets, operatorJWT, accounts)
+	if err != nil {
+		return err
+	}
+	publishers = append(publishers, resolverPublishers...)
+
+	targetsHash := publishTargetsHash(spec, pushed, operatorJWT)
+	previous := authConfig.Status.PublishedAccounts
+	if authConfig.Status.PublishTargetsHash != targetsHash {
+		previous = nil
+	}
+	published := make(map[string]string, len(accounts))
+	// Keep the progress made so far in status, whether or not every target answered
+	defer func() {
+		for accountID, fingerprint := range previous {
+			if _, ok := published[accountID]; !ok {
+				published[accountID] = fingerprint
+			}
+		}
+		authConfig.Status.PublishedAccounts = published
+		authConfig.Status.PublishTargetsHash = targetsHash
+	}()
+
+	current := make(map[string]bool, len(accounts))
+	for _, account := range accounts {
+		current[account.AccountID] = true
+		fingerprint := jwtpkg.Fingerprint(account.JWT)
+		if previous[account.AccountID] == fingerprint {
+			published[account.AccountID] = fingerprint
+			continue
+		}
+		for _, p := range publishers {
+			if err := p.Publish(ctx, account.AccountID, account.JWT); err != nil {
+				return fmt.Errorf("failed to publish JWT of account %s/%s: %w", account.AccountNamespace, account.AccountName, err)
+			}
+		}
+		published[account.AccountID] = fingerprint
+		log.V(debugLevel).Info("Published account JWT", "accountID", account.AccountID, "fingerprint", fingerprint)
+	}
+
+	for accountID := range previous {
+		if current[accountID] {
+			continue
+		}
+		for _, p := range publishers {
+			if err := p.Delete(ctx, accountID); err != nil {
+				return fmt.Errorf("failed to delete JWT of account %s: %w", accountID, err)
+			}
+		}
+		delete(previous, accountID)
+		log.Info("Deleted published account JWT", "accountID", accountID)
+	}
+	return nil
+}
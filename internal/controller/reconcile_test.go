@@ -0,0 +1,285 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/nats-io/jwt/v2"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/util/workqueue"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	natsv1alpha1 "github.com/jradikk/nats-auth-operator/api/v1alpha1"
+	"github.com/jradikk/nats-auth-operator/internal/authconf"
+)
+
+// newTestClient returns a fake client holding objs, with the status subresources and field
+// indexes the reconcilers register in SetupWithManager
+func newTestClient(objs ...client.Object) (client.Client, *runtime.Scheme) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = natsv1alpha1.AddToScheme(scheme)
+
+	builder := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).
+		WithStatusSubresource(&natsv1alpha1.NatsAuthConfig{}, &natsv1alpha1.NatsAccount{}, &natsv1alpha1.NatsUser{}).
+		WithIndex(&natsv1alpha1.NatsUser{}, userAccountIndex, indexUserByAccount).
+		WithIndex(&natsv1alpha1.NatsAccount{}, accountImportIndex, indexAccountByImport).
+		WithIndex(&natsv1alpha1.NatsAuthConfig{}, serverAuthConfigIndex, indexAuthConfigByServerConfig).
+		WithIndex(&natsv1alpha1.NatsAuthConfig{}, operatorSeedIndex, indexAuthConfigByOperatorSeed).
+		WithIndex(&natsv1alpha1.NatsAuthConfig{}, templatesConfigMapIndex, indexAuthConfigByTemplates).
+		WithIndex(&natsv1alpha1.NatsUser{}, passwordSecretIndex, indexUserByPasswordSecret).
+		WithIndex(&natsv1alpha1.NatsUser{}, tokenUsernameIndex, indexUserByTokenUsername)
+	for _, obj := range []client.Object{&natsv1alpha1.NatsAccount{}, &natsv1alpha1.NatsUser{}} {
+		builder = builder.WithIndex(obj, authConfigIndex, indexByAuthConfig)
+	}
+	for _, obj := range []client.Object{&natsv1alpha1.NatsAuthConfig{}, &natsv1alpha1.NatsAccount{}, &natsv1alpha1.NatsUser{}} {
+		builder = builder.WithIndex(obj, seedSecretIndex, indexBySeedSecret)
+	}
+	for _, obj := range []client.Object{&natsv1alpha1.NatsAuthConfig{}, &natsv1alpha1.NatsAccount{}, &natsv1alpha1.NatsUser{}, &natsv1alpha1.NatsAuthBackup{}} {
+		builder = builder.WithIndex(obj, secretAccessIndex, secretAccessNamespaces)
+	}
+	return builder.WithInterceptorFuncs(interceptor.Funcs{Patch: applyAsMergePatch}).Build(), scheme
+}
+
+// applyAsMergePatch stands in for server-side apply, which the fake client does not implement:
+// an applied object is created, or merged into the live one so keys written by others are kept
+func applyAsMergePatch(ctx context.Context, c client.WithWatch, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	if patch.Type() != types.ApplyPatchType {
+		return c.Patch(ctx, obj, patch, opts...)
+	}
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return err
+	}
+	err = c.Patch(ctx, obj, client.RawPatch(types.MergePatchType, data))
+	if errors.IsNotFound(err) {
+		return c.Create(ctx, obj)
+	}
+	return err
+}
+
+// testReconcilers returns the NatsAuthConfig, NatsAccount and NatsUser reconcilers over c
+func testReconcilers(c client.Client, scheme *runtime.Scheme) (*NatsAuthConfigReconciler, *NatsAccountReconciler, *NatsUserReconciler) {
+	return &NatsAuthConfigReconciler{Client: c, Scheme: scheme},
+		&NatsAccountReconciler{Client: c, Scheme: scheme},
+		&NatsUserReconciler{Client: c, Scheme: scheme, dependencyBackoff: workqueue.NewItemExponentialFailureRateLimiter(dependencyBackoffBase, dependencyBackoffCap)}
+}
+
+// mustReconcile reconciles obj with r and fails the test on an error
+func mustReconcile(t *testing.T, r reconcile.Reconciler, obj client.Object) ctrl.Result {
+	t.Helper()
+	result, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: client.ObjectKeyFromObject(obj)})
+	if err != nil {
+		t.Fatalf("Reconcile(%s) error = %v", client.ObjectKeyFromObject(obj), err)
+	}
+	return result
+}
+
+// mustGet reads obj back from c and fails the test on an error
+func mustGet(t *testing.T, c client.Client, obj client.Object) {
+	t.Helper()
+	if err := c.Get(context.Background(), client.ObjectKeyFromObject(obj), obj); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// testAuthConfig returns a NatsAuthConfig in mode writing its config to the nats-auth Secret
+func testAuthConfig(mode natsv1alpha1.AuthMode) *natsv1alpha1.NatsAuthConfig {
+	authConfig := &natsv1alpha1.NatsAuthConfig{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "nats", Name: "auth", UID: "uid-auth"},
+		Spec: natsv1alpha1.NatsAuthConfigSpec{
+			Mode:             mode,
+			ServerAuthConfig: natsv1alpha1.ServerAuthConfigRef{Namespace: "nats", Name: "nats-auth", Key: "auth.conf", Type: "Secret"},
+		},
+	}
+	if usesJWT(mode) {
+		authConfig.Spec.JWT = &natsv1alpha1.JWTConfig{ResolverDir: "/var/lib/nats-resolver", OperatorName: "NATS Operator"}
+	}
+	return authConfig
+}
+
+// testAccount returns a NatsAccount of the auth NatsAuthConfig
+func testAccount(name string) *natsv1alpha1.NatsAccount {
+	return &natsv1alpha1.NatsAccount{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "apps", Name: name},
+		Spec:       natsv1alpha1.NatsAccountSpec{AuthConfigRef: natsv1alpha1.NatsAuthConfigRef{Namespace: "nats", Name: "auth"}},
+	}
+}
+
+// testUser returns a NatsUser of the auth NatsAuthConfig with authType, in account when it is not empty
+func testUser(name string, authType natsv1alpha1.UserAuthType, account string) *natsv1alpha1.NatsUser {
+	user := &natsv1alpha1.NatsUser{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "apps", Name: name, UID: types.UID("uid-" + name)},
+		Spec: natsv1alpha1.NatsUserSpec{
+			AuthConfigRef: natsv1alpha1.NatsAuthConfigRef{Namespace: "nats", Name: "auth"},
+			AuthType:      authType,
+		},
+	}
+	if account != "" {
+		user.Spec.AccountRef = &natsv1alpha1.NatsAccountRef{Name: account}
+	}
+	return user
+}
+
+// serverAuthSecret returns the server auth config Secret written for the auth NatsAuthConfig
+func serverAuthSecret(t *testing.T, c client.Client) *corev1.Secret {
+	t.Helper()
+	secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "nats", Name: "nats-auth"}}
+	mustGet(t, c, secret)
+	return secret
+}
+
+// wantReady fails the test unless the Ready condition of conditions is true
+func wantReady(t *testing.T, obj client.Object, conditions []metav1.Condition) {
+	t.Helper()
+	if !meta.IsStatusConditionTrue(conditions, "Ready") {
+		t.Errorf("%T %s conditions = %+v, want Ready", obj, client.ObjectKeyFromObject(obj), conditions)
+	}
+}
+
+func TestReconcileJWTMode(t *testing.T) {
+	authConfig := testAuthConfig(natsv1alpha1.AuthModeJWT)
+	account := testAccount("orders")
+	user := testUser("orders-app", natsv1alpha1.UserAuthTypeJWT, "orders")
+	c, scheme := newTestClient(authConfig, account, user)
+	authConfigs, accounts, users := testReconcilers(c, scheme)
+
+	// Create: the operator JWT is written before any account exists
+	mustReconcile(t, authConfigs, authConfig)
+	mustGet(t, c, authConfig)
+	wantReady(t, authConfig, authConfig.Status.Conditions)
+	if authConfig.Status.OperatorPubKey == "" {
+		t.Fatal("Reconcile() left status.operatorPubKey empty")
+	}
+	secret := serverAuthSecret(t, c)
+	operatorClaims, err := jwt.DecodeOperatorClaims(string(secret.Data[authconf.OperatorKey]))
+	if err != nil || operatorClaims.Subject != authConfig.Status.OperatorPubKey {
+		t.Fatalf("Reconcile() wrote operator JWT %v, %v, want one of %s", operatorClaims, err, authConfig.Status.OperatorPubKey)
+	}
+
+	mustReconcile(t, accounts, account)
+	mustGet(t, c, account)
+	wantReady(t, account, account.Status.Conditions)
+	if account.Status.AccountID == "" || account.Status.JWTSecretRef.Name == "" {
+		t.Fatalf("Reconcile() account status = %+v, want an account ID and JWT Secret", account.Status)
+	}
+
+	mustReconcile(t, users, user)
+	mustGet(t, c, user)
+	wantReady(t, user, user.Status.Conditions)
+	creds := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: credsSecretNamespace(user), Name: user.Status.SecretRef.Name}}
+	mustGet(t, c, creds)
+	userJWT, err := jwt.ParseDecoratedJWT(creds.Data["user.creds"])
+	if err != nil {
+		t.Fatalf("Reconcile() wrote creds %q: %v", creds.Data["user.creds"], err)
+	}
+	userClaims, err := jwt.DecodeUserClaims(userJWT)
+	if err != nil || userClaims.Issuer != account.Status.AccountID {
+		t.Errorf("Reconcile() issued user claims %+v, %v, want ones of account %s", userClaims, err, account.Status.AccountID)
+	}
+
+	// The account JWT is merged into the server auth Secret, next to keys written by others
+	secret = serverAuthSecret(t, c)
+	secret.Data["custom.conf"] = []byte("# kept")
+	if err := c.Update(context.Background(), secret); err != nil {
+		t.Fatal(err)
+	}
+	mustReconcile(t, authConfigs, authConfig)
+	secret = serverAuthSecret(t, c)
+	accountClaims, err := jwt.DecodeAccountClaims(string(secret.Data[account.Name]))
+	if err != nil || accountClaims.Subject != account.Status.AccountID {
+		t.Fatalf("Reconcile() server auth Secret keys %v, want the JWT of account %s", sortedKeys(secret.Data), account.Status.AccountID)
+	}
+	if string(secret.Data["custom.conf"]) != "# kept" {
+		t.Errorf("Reconcile() dropped the custom.conf key: %v", sortedKeys(secret.Data))
+	}
+
+	// Update: a changed account is re-signed and written to the server auth Secret again
+	mustGet(t, c, account)
+	account.Spec.Description = "Orders"
+	if err := c.Update(context.Background(), account); err != nil {
+		t.Fatal(err)
+	}
+	mustReconcile(t, accounts, account)
+	mustReconcile(t, authConfigs, authConfig)
+	secret = serverAuthSecret(t, c)
+	if accountClaims, err = jwt.DecodeAccountClaims(string(secret.Data[account.Name])); err != nil || accountClaims.Description != "Orders" {
+		t.Errorf("Reconcile() wrote account claims %+v, %v, want the updated description", accountClaims, err)
+	}
+}
+
+func TestReconcileTokenMode(t *testing.T) {
+	authConfig := testAuthConfig(natsv1alpha1.AuthModeToken)
+	user := testUser("orders-app", natsv1alpha1.UserAuthTypeToken, "")
+	user.Spec.Username = "orders"
+	c, scheme := newTestClient(authConfig, user, &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "nats", Name: "nats-auth"},
+		Data:       map[string][]byte{"custom.conf": []byte("# kept")},
+	})
+	authConfigs, _, users := testReconcilers(c, scheme)
+
+	// Create: the user gets credentials, which are rendered into the server auth config
+	mustReconcile(t, users, user)
+	mustGet(t, c, user)
+	wantReady(t, user, user.Status.Conditions)
+	mustReconcile(t, authConfigs, authConfig)
+	mustGet(t, c, authConfig)
+	wantReady(t, authConfig, authConfig.Status.Conditions)
+
+	secret := serverAuthSecret(t, c)
+	if conf := string(secret.Data["auth.conf"]); !strings.Contains(conf, `user: "orders"`) {
+		t.Errorf("Reconcile() wrote auth.conf %q, want the user orders", conf)
+	}
+	if string(secret.Data["custom.conf"]) != "# kept" {
+		t.Errorf("Reconcile() dropped the custom.conf key: %v", sortedKeys(secret.Data))
+	}
+
+	// Update: changed permissions are rendered on the next reconcile
+	mustGet(t, c, user)
+	user.Spec.Permissions = &natsv1alpha1.Permissions{PublishAllow: []string{"orders.>"}}
+	if err := c.Update(context.Background(), user); err != nil {
+		t.Fatal(err)
+	}
+	mustReconcile(t, users, user)
+	mustReconcile(t, authConfigs, authConfig)
+	if conf := string(serverAuthSecret(t, c).Data["auth.conf"]); !strings.Contains(conf, "orders.>") {
+		t.Errorf("Reconcile() wrote auth.conf %q, want the publish permission orders.>", conf)
+	}
+}
+
+func TestChildReconcilesLeaveAuthConfigUntouched(t *testing.T) {
+	authConfig := testAuthConfig(natsv1alpha1.AuthModeJWT)
+	authConfig.Labels = map[string]string{"app.kubernetes.io/instance": "nats"}
+	authConfig.Annotations = map[string]string{"argocd.argoproj.io/tracking-id": "nats:nats.jradikk/NatsAuthConfig:nats/auth"}
+	account := testAccount("orders")
+	user := testUser("orders-app", natsv1alpha1.UserAuthTypeJWT, "orders")
+	c, scheme := newTestClient(authConfig, account, user)
+	authConfigs, accounts, users := testReconcilers(c, scheme)
+	mustReconcile(t, authConfigs, authConfig)
+	mustGet(t, c, authConfig)
+	before := authConfig.DeepCopy()
+
+	// GitOps tools compare the metadata they applied; the children reach their NatsAuthConfig
+	// through watches rather than by writing to it
+	mustReconcile(t, accounts, account)
+	mustReconcile(t, users, user)
+	mustGet(t, c, user)
+	wantReady(t, user, user.Status.Conditions)
+	mustGet(t, c, authConfig)
+	if !reflect.DeepEqual(authConfig.ObjectMeta, before.ObjectMeta) || !reflect.DeepEqual(authConfig.Spec, before.Spec) {
+		t.Errorf("account and user reconciles changed the NatsAuthConfig metadata from %+v to %+v", before.ObjectMeta, authConfig.ObjectMeta)
+	}
+}
@@ -0,0 +1,130 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/nats-io/jwt/v2"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	"github.com/jradikk/nats-auth-operator/internal/authconf"
+	"github.com/jradikk/nats-auth-operator/internal/resolver"
+	"github.com/jradikk/nats-auth-operator/internal/secrets"
+)
+
+// ResolverAgentReconciler is the resolver agent. Running as a sidecar of the NATS servers, it
+// materializes the operator and account JWTs of a server auth config Secret, and of its overflow
+// Secrets, in the servers' full-resolver directory, so the servers need no volume shared with
+// the operator.
+type ResolverAgentReconciler struct {
+	client.Client
+
+	// Secret is the server auth config Secret of the NatsAuthConfig
+	Secret types.NamespacedName
+
+	// Builder maintains the full-resolver directory
+	Builder *resolver.Builder
+}
+
+func (r *ResolverAgentReconciler) Reconcile(ctx context.Context, _ ctrl.Request) (ctrl.Result, error) {
+	log := log.FromContext(ctx).WithValues("secret", r.Secret.String())
+
+	// Every watched Secret is a part of the same server auth config, which is read as a whole
+	secret, exists, err := secrets.Get(ctx, r.Client, r.Secret)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if !exists {
+		// The directory is kept as it is until the operator writes the Secret again
+		log.Info("Server auth config Secret not found, keeping the resolver directory")
+		return ctrl.Result{}, nil
+	}
+	data := []map[string][]byte{secret.Data}
+	for i := 1; i <= maxGrantedConfigChunks; i++ {
+		chunk, exists, err := secrets.Get(ctx, r.Client, types.NamespacedName{Namespace: r.Secret.Namespace, Name: fmt.Sprintf("%s-%d", r.Secret.Name, i)})
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		if !exists {
+			break
+		}
+		if _, ok := chunk.Labels[authconf.ConfigChunkLabel]; ok {
+			data = append(data, chunk.Data)
+		}
+	}
+
+	operatorJWT := string(secret.Data[authconf.OperatorKey])
+	if operatorJWT == "" {
+		return ctrl.Result{}, fmt.Errorf("server auth config Secret %s holds no %s key", r.Secret, authconf.OperatorKey)
+	}
+	accounts := accountJWTsFromSecretData(data...)
+	if err := r.Builder.Sync(operatorJWT, accounts); err != nil {
+		return ctrl.Result{}, err
+	}
+	log.V(debugLevel).Info("Synced resolver directory", "accounts", len(accounts))
+	return ctrl.Result{}, nil
+}
+
+// accountJWTsFromSecretData returns the account JWTs held in server auth config Secret data,
+// keyed by account public key. Accounts are keyed in the Secret by name, public key or both, so
+// every value decoding as account claims is taken.
+func accountJWTsFromSecretData(data ...map[string][]byte) map[string]string {
+	accounts := map[string]string{}
+	for _, d := range data {
+		for key, value := range d {
+			if key == authconf.OperatorKey || strings.HasSuffix(key, ".conf") {
+				continue
+			}
+			claims, err := jwt.DecodeAccountClaims(string(value))
+			if err != nil {
+				continue
+			}
+			accounts[claims.Subject] = string(value)
+		}
+	}
+	return accounts
+}
+
+// isAgentSecret reports whether the Secret is the server auth config Secret of the agent or
+// one of its overflow Secrets
+func (r *ResolverAgentReconciler) isAgentSecret(obj client.Object) bool {
+	if obj.GetNamespace() != r.Secret.Namespace {
+		return false
+	}
+	if obj.GetName() == r.Secret.Name {
+		return true
+	}
+	_, chunk := obj.GetLabels()[authconf.ConfigChunkLabel]
+	return chunk && strings.HasPrefix(obj.GetName(), r.Secret.Name+"-")
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *ResolverAgentReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("resolveragent").
+		For(&corev1.Secret{}, builder.WithPredicates(predicate.NewPredicateFuncs(r.isAgentSecret))).
+		Complete(r)
+}
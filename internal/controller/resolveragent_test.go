@@ -0,0 +1,97 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/nats-io/jwt/v2"
+	"github.com/nats-io/nkeys"
+	"github.com/spf13/afero"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/jradikk/nats-auth-operator/internal/authconf"
+	"github.com/jradikk/nats-auth-operator/internal/resolver"
+)
+
+// accountJWT returns the public key and a JWT of a new account signed by operatorKP
+func accountJWT(t *testing.T, operatorKP nkeys.KeyPair) (string, string) {
+	t.Helper()
+	kp, err := nkeys.CreateAccount()
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub, _ := kp.PublicKey()
+	token, err := jwt.NewAccountClaims(pub).Encode(operatorKP)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pub, token
+}
+
+func TestResolverAgentReconcile(t *testing.T) {
+	operatorKP, _ := nkeys.CreateOperator()
+	appID, appJWT := accountJWT(t, operatorKP)
+	chunkedID, chunkedJWT := accountJWT(t, operatorKP)
+
+	c := fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).WithObjects(
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "nats", Name: "nats-auth"},
+			Data: map[string][]byte{
+				authconf.OperatorKey:     []byte("operator-token"),
+				"app":                    []byte(appJWT),
+				authconf.ResolverConfKey: []byte("resolver: {}"),
+			},
+		},
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "nats", Name: "nats-auth-1", Labels: map[string]string{authconf.ConfigChunkLabel: "1"}},
+			Data:       map[string][]byte{chunkedID: []byte(chunkedJWT)},
+		},
+	).Build()
+
+	fs := afero.NewMemMapFs()
+	builder := resolver.NewBuilder(fs, "/var/lib/nats-resolver")
+	if err := builder.Sync("operator-token", map[string]string{"AREMOVED": "removed"}); err != nil {
+		t.Fatal(err)
+	}
+
+	r := &ResolverAgentReconciler{Client: c, Secret: types.NamespacedName{Namespace: "nats", Name: "nats-auth"}, Builder: builder}
+	if _, err := r.Reconcile(context.Background(), ctrl.Request{}); err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+
+	for accountID, want := range map[string]string{appID: appJWT, chunkedID: chunkedJWT} {
+		if data, _ := afero.ReadFile(fs, builder.GetAccountJWTPath(accountID)); string(data) != want {
+			t.Errorf("Reconcile() wrote %s = %q, want the account JWT", accountID, data)
+		}
+	}
+	if exists, _ := builder.AccountJWTExists("AREMOVED"); exists {
+		t.Error("Reconcile() kept the JWT of an account no longer in the Secret")
+	}
+	if data, _ := afero.ReadFile(fs, builder.GetOperatorJWTPath()); string(data) != "operator-token" {
+		t.Errorf("Reconcile() wrote operator.jwt = %q, want %q", data, "operator-token")
+	}
+}
+
+func TestResolverAgentIsAgentSecret(t *testing.T) {
+	r := &ResolverAgentReconciler{Secret: types.NamespacedName{Namespace: "nats", Name: "nats-auth"}}
+	chunk := map[string]string{authconf.ConfigChunkLabel: "1"}
+	for _, tt := range []struct {
+		name   string
+		secret *corev1.Secret
+		want   bool
+	}{
+		{name: "Server auth config", secret: &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "nats", Name: "nats-auth"}}, want: true},
+		{name: "Overflow Secret", secret: &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "nats", Name: "nats-auth-1", Labels: chunk}}, want: true},
+		{name: "Unlabelled", secret: &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "nats", Name: "nats-auth-1"}}},
+		{name: "Other namespace", secret: &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "apps", Name: "nats-auth"}}},
+	} {
+		if got := r.isAgentSecret(tt.secret); got != tt.want {
+			t.Errorf("%s: isAgentSecret() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,251 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/spf13/afero"
+
+	natsv1alpha1 "github.com/jradikk/nats-auth-operator/api/v1alpha1"
+	"github.com/jradikk/nats-auth-operator/internal/authconf"
+	"github.com/jradikk/nats-auth-operator/internal/backup"
+	jwtpkg "github.com/jradikk/nats-auth-operator/internal/jwt"
+	"github.com/jradikk/nats-auth-operator/internal/natsclient"
+	"github.com/jradikk/nats-auth-operator/internal/publish"
+	"github.com/jradikk/nats-auth-operator/internal/resolver"
+	"github.com/jradikk/nats-auth-operator/internal/secrets"
+	"github.com/jradikk/nats-auth-operator/pkg/credentials"
+)
+
+// resolverTargets returns the targets account JWTs are written to: spec.jwt.resolver.targets, or
+// by default the server auth config Secret and, when jwt.resolverDir is one of the full-resolver
+// directories mounted into the operator pod, that directory
+func resolverTargets(jwt *natsv1alpha1.JWTConfig, mountedDirs []string) []natsv1alpha1.ResolverTarget {
+	if jwt == nil {
+		return nil
+	}
+	if jwt.Resolver != nil {
+		return jwt.Resolver.Targets
+	}
+	targets := []natsv1alpha1.ResolverTarget{{Type: natsv1alpha1.ResolverTargetSecret}}
+	if jwt.ResolverDir != "" && slices.Contains(mountedDirs, filepath.Clean(jwt.ResolverDir)) {
+		targets = append(targets, natsv1alpha1.ResolverTarget{Type: natsv1alpha1.ResolverTargetDirectory})
+	}
+	return targets
+}
+
+// hasResolverTarget reports whether targets hold one of type targetType
+func hasResolverTarget(targets []natsv1alpha1.ResolverTarget, targetType natsv1alpha1.ResolverTargetType) bool {
+	return slices.ContainsFunc(targets, func(target natsv1alpha1.ResolverTarget) bool {
+		return target.Type == targetType
+	})
+}
+
+// writesServerAuthSecret reports whether the account JWTs are written as keys of the server auth
+// config Secret: for a Secret target, or for the resolver agent of an Agent target
+func writesServerAuthSecret(targets []natsv1alpha1.ResolverTarget) bool {
+	return hasResolverTarget(targets, natsv1alpha1.ResolverTargetSecret) || hasResolverTarget(targets, natsv1alpha1.ResolverTargetAgent)
+}
+
+// pushedResolverTargets returns the resolver targets written through publishers, the Directory,
+// NATS and KV targets; the others are written with the server auth config
+func pushedResolverTargets(targets []natsv1alpha1.ResolverTarget) []natsv1alpha1.ResolverTarget {
+	var pushed []natsv1alpha1.ResolverTarget
+	for _, target := range targets {
+		switch target.Type {
+		case natsv1alpha1.ResolverTargetDirectory, natsv1alpha1.ResolverTargetNATS, natsv1alpha1.ResolverTargetKV:
+			pushed = append(pushed, target)
+		}
+	}
+	return pushed
+}
+
+// serverResolverDir returns the directory the resolver include points the servers at: the
+// accounts directory of jwt.resolverDir when the operator or the resolver agent maintains the
+// full-resolver directory, so the servers read the JWTs written there, otherwise
+// jwt.resolverDir itself
+func serverResolverDir(jwt *natsv1alpha1.JWTConfig, targets []natsv1alpha1.ResolverTarget) string {
+	if hasResolverTarget(targets, natsv1alpha1.ResolverTargetDirectory) || hasResolverTarget(targets, natsv1alpha1.ResolverTargetAgent) {
+		return resolver.NewBuilder(nil, jwt.ResolverDir).AccountsDir()
+	}
+	return jwt.ResolverDir
+}
+
+// validateResolverTargets checks that each target carries the settings of its type
+func validateResolverTargets(jwt *natsv1alpha1.JWTConfig) error {
+	if jwt == nil || jwt.Resolver == nil {
+		return nil
+	}
+	for i, target := range jwt.Resolver.Targets {
+		connects := target.Type == natsv1alpha1.ResolverTargetNATS || target.Type == natsv1alpha1.ResolverTargetKV
+		if connects != (target.NATS != nil) {
+			return fmt.Errorf("jwt.resolver.targets[%d]: nats is required for, and only allowed with, the NATS and KV types", i)
+		}
+		if (target.Type == natsv1alpha1.ResolverTargetKV) != (target.KV != nil) {
+			return fmt.Errorf("jwt.resolver.targets[%d]: kv is required for, and only allowed with, the KV type", i)
+		}
+		if target.Dir != "" && target.Type != natsv1alpha1.ResolverTargetDirectory {
+			return fmt.Errorf("jwt.resolver.targets[%d]: dir is only allowed with the Directory type", i)
+		}
+		if target.KV != nil {
+			for _, recipient := range target.KV.MetadataRecipients {
+				if err := backup.ValidateRecipient(recipient); err != nil {
+					return fmt.Errorf("jwt.resolver.targets[%d].kv.metadataRecipients: %w", i, err)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// resolverFs returns the filesystem the Directory targets are written to
+func (r *NatsAuthConfigReconciler) resolverFs() afero.Fs {
+	if r.ResolverFs == nil {
+		return afero.NewOsFs()
+	}
+	return r.ResolverFs
+}
+
+// resolverTargets returns the resolver targets of the NatsAuthConfig
+func (r *NatsAuthConfigReconciler) resolverTargets(authConfig *natsv1alpha1.NatsAuthConfig) []natsv1alpha1.ResolverTarget {
+	return resolverTargets(authConfig.Spec.JWT, r.MountedResolverDirs)
+}
+
+// resolverDirBuilder returns the Builder of the full-resolver directory of a Directory target
+func (r *NatsAuthConfigReconciler) resolverDirBuilder(authConfig *natsv1alpha1.NatsAuthConfig, target natsv1alpha1.ResolverTarget) (*resolver.Builder, error) {
+	dir := target.Dir
+	if dir == "" {
+		dir = authConfig.Spec.JWT.ResolverDir
+	}
+	// Writing into a directory nothing is mounted on would only fill the operator's own filesystem
+	mounted, err := afero.DirExists(r.resolverFs(), dir)
+	if err != nil {
+		return nil, err
+	}
+	if !mounted {
+		return nil, &dependencyError{
+			Kind:    "Volume",
+			Name:    dir,
+			Reason:  "ResolverDirNotMounted",
+			Message: "mount the full-resolver volume into the operator pod",
+		}
+	}
+	return resolver.NewBuilder(r.resolverFs(), dir), nil
+}
+
+// writeResolverDirs creates the layout of the full-resolver directory of each Directory target
+// and keeps its operator.jwt current; publishAccounts writes the account JWTs
+func (r *NatsAuthConfigReconciler) writeResolverDirs(authConfig *natsv1alpha1.NatsAuthConfig, targets []natsv1alpha1.ResolverTarget, operatorJWT string) error {
+	for _, target := range targets {
+		if target.Type != natsv1alpha1.ResolverTargetDirectory {
+			continue
+		}
+		builder, err := r.resolverDirBuilder(authConfig, target)
+		if err != nil {
+			return err
+		}
+		if err := builder.Initialize(); err != nil {
+			return err
+		}
+		if err := builder.WriteOperatorJWT(operatorJWT); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// natsTargetOptions returns the connection options of a NATS or KV target
+func (r *NatsAuthConfigReconciler) natsTargetOptions(ctx context.Context, authConfig *natsv1alpha1.NatsAuthConfig, target *natsv1alpha1.NATSResolverTarget) (natsclient.Options, error) {
+	opts := natsclient.Options{URL: target.URL, Name: "nats-auth-operator-resolver"}
+	if target.CABundle != "" {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM([]byte(target.CABundle)) {
+			return opts, permanent(natsv1alpha1.ReasonInvalidSpec, fmt.Errorf("invalid NATS resolver target: caBundle holds no PEM certificates"))
+		}
+		opts.TLSConfig = &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}
+	}
+
+	key := target.CredentialsSecret.ObjectKey(authConfig.Namespace)
+	secret, exists, err := secrets.Get(ctx, r.Client, key)
+	if err != nil {
+		return opts, err
+	}
+	if opts.Creds = secret.Data[credentials.CredsKey]; !exists || len(opts.Creds) == 0 {
+		return opts, &dependencyError{
+			Kind:    "Secret",
+			Name:    key.String(),
+			Reason:  "ResolverCredentialsNotFound",
+			Message: fmt.Sprintf("%s is required", credentials.CredsKey),
+		}
+	}
+	return opts, nil
+}
+
+// resolverPublishers builds a publisher for each Directory, NATS and KV target
+func (r *NatsAuthConfigReconciler) resolverPublishers(ctx context.Context, authConfig *natsv1alpha1.NatsAuthConfig, targets []natsv1alpha1.ResolverTarget, operatorJWT string, accounts []authconf.AccountJWT) ([]publish.Publisher, error) {
+	var publishers []publish.Publisher
+	for _, target := range pushedResolverTargets(targets) {
+		switch target.Type {
+		case natsv1alpha1.ResolverTargetDirectory:
+			builder, err := r.resolverDirBuilder(authConfig, target)
+			if err != nil {
+				return nil, err
+			}
+			publishers = append(publishers, &publish.Directory{Builder: builder})
+
+		case natsv1alpha1.ResolverTargetNATS:
+			opts, err := r.natsTargetOptions(ctx, authConfig, target.NATS)
+			if err != nil {
+				return nil, err
+			}
+			publishers = append(publishers, &publish.NATSResolver{
+				Options: opts,
+				Timeout: time.Duration(target.NATS.TimeoutSeconds) * time.Second,
+			})
+
+		case natsv1alpha1.ResolverTargetKV:
+			opts, err := r.natsTargetOptions(ctx, authConfig, target.NATS)
+			if err != nil {
+				return nil, err
+			}
+			metadata := make(map[string]publish.AccountMetadata, len(accounts))
+			for _, account := range accounts {
+				metadata[account.AccountID] = publish.AccountMetadata{
+					Namespace:   account.AccountNamespace,
+					Name:        account.AccountName,
+					Fingerprint: jwtpkg.Fingerprint(account.JWT),
+				}
+			}
+			publishers = append(publishers, &publish.KV{
+				Options:     opts,
+				Bucket:      target.KV.Bucket,
+				Timeout:     time.Duration(target.NATS.TimeoutSeconds) * time.Second,
+				OperatorJWT: operatorJWT,
+				Metadata:    metadata,
+				Recipients:  target.KV.MetadataRecipients,
+			})
+		}
+	}
+	return publishers, nil
+}
@@ -0,0 +1,183 @@
+package controller
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/spf13/afero"
+
+	natsv1alpha1 "github.com/jradikk/nats-auth-operator/api/v1alpha1"
+)
+
+func TestResolverTargets(t *testing.T) {
+	secret := natsv1alpha1.ResolverTarget{Type: natsv1alpha1.ResolverTargetSecret}
+	directory := natsv1alpha1.ResolverTarget{Type: natsv1alpha1.ResolverTargetDirectory}
+	mounted := []string{"/var/lib/nats-resolver"}
+	for _, tt := range []struct {
+		name        string
+		jwt         *natsv1alpha1.JWTConfig
+		mountedDirs []string
+		want        []natsv1alpha1.ResolverTarget
+	}{
+		{name: "Default", jwt: &natsv1alpha1.JWTConfig{ResolverDir: "/var/lib/nats-resolver"}, want: []natsv1alpha1.ResolverTarget{secret}},
+		{
+			name:        "Mounted resolver dir",
+			jwt:         &natsv1alpha1.JWTConfig{ResolverDir: "/var/lib/nats-resolver/"},
+			mountedDirs: mounted,
+			want:        []natsv1alpha1.ResolverTarget{secret, directory},
+		},
+		{
+			name:        "Other resolver dir mounted",
+			jwt:         &natsv1alpha1.JWTConfig{ResolverDir: "/data/resolver"},
+			mountedDirs: mounted,
+			want:        []natsv1alpha1.ResolverTarget{secret},
+		},
+		{
+			name:        "Explicit targets",
+			jwt:         &natsv1alpha1.JWTConfig{ResolverDir: "/var/lib/nats-resolver", Resolver: &natsv1alpha1.ResolverConfig{Targets: []natsv1alpha1.ResolverTarget{directory}}},
+			mountedDirs: mounted,
+			want:        []natsv1alpha1.ResolverTarget{directory},
+		},
+	} {
+		if got := resolverTargets(tt.jwt, tt.mountedDirs); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: resolverTargets() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestServerResolverDir(t *testing.T) {
+	jwt := &natsv1alpha1.JWTConfig{ResolverDir: "/var/lib/nats-resolver"}
+	secret := []natsv1alpha1.ResolverTarget{{Type: natsv1alpha1.ResolverTargetSecret}}
+	if got := serverResolverDir(jwt, secret); got != "/var/lib/nats-resolver" {
+		t.Errorf("serverResolverDir() = %s, want /var/lib/nats-resolver", got)
+	}
+	for _, targetType := range []natsv1alpha1.ResolverTargetType{natsv1alpha1.ResolverTargetDirectory, natsv1alpha1.ResolverTargetAgent} {
+		targets := []natsv1alpha1.ResolverTarget{{Type: targetType}}
+		if got := serverResolverDir(jwt, targets); got != "/var/lib/nats-resolver/accounts" {
+			t.Errorf("serverResolverDir() with a %s target = %s, want /var/lib/nats-resolver/accounts", targetType, got)
+		}
+	}
+}
+
+func TestWritesServerAuthSecret(t *testing.T) {
+	for _, tt := range []struct {
+		targetType natsv1alpha1.ResolverTargetType
+		want       bool
+		wantPushed bool
+	}{
+		{targetType: natsv1alpha1.ResolverTargetSecret, want: true},
+		{targetType: natsv1alpha1.ResolverTargetAgent, want: true},
+		{targetType: natsv1alpha1.ResolverTargetDirectory, wantPushed: true},
+		{targetType: natsv1alpha1.ResolverTargetNATS, wantPushed: true},
+		{targetType: natsv1alpha1.ResolverTargetKV, wantPushed: true},
+	} {
+		targets := []natsv1alpha1.ResolverTarget{{Type: tt.targetType}}
+		if got := writesServerAuthSecret(targets); got != tt.want {
+			t.Errorf("writesServerAuthSecret(%s) = %v, want %v", tt.targetType, got, tt.want)
+		}
+		if got := len(pushedResolverTargets(targets)) > 0; got != tt.wantPushed {
+			t.Errorf("pushedResolverTargets(%s) = %v, want pushed %v", tt.targetType, got, tt.wantPushed)
+		}
+	}
+}
+
+func TestValidateResolverTargets(t *testing.T) {
+	nats := &natsv1alpha1.NATSResolverTarget{URL: "nats://nats:4222"}
+	for _, tt := range []struct {
+		name    string
+		target  natsv1alpha1.ResolverTarget
+		wantErr bool
+	}{
+		{name: "NATS", target: natsv1alpha1.ResolverTarget{Type: natsv1alpha1.ResolverTargetNATS, NATS: nats}},
+		{name: "NATS without server", target: natsv1alpha1.ResolverTarget{Type: natsv1alpha1.ResolverTargetNATS}, wantErr: true},
+		{name: "Directory with server", target: natsv1alpha1.ResolverTarget{Type: natsv1alpha1.ResolverTargetDirectory, NATS: nats}, wantErr: true},
+		{name: "Directory", target: natsv1alpha1.ResolverTarget{Type: natsv1alpha1.ResolverTargetDirectory, Dir: "/resolver"}},
+		{name: "Secret with dir", target: natsv1alpha1.ResolverTarget{Type: natsv1alpha1.ResolverTargetSecret, Dir: "/resolver"}, wantErr: true},
+		{name: "KV", target: natsv1alpha1.ResolverTarget{Type: natsv1alpha1.ResolverTargetKV, NATS: nats, KV: &natsv1alpha1.KVResolverTarget{Bucket: "accounts"}}},
+		{name: "KV without bucket", target: natsv1alpha1.ResolverTarget{Type: natsv1alpha1.ResolverTargetKV, NATS: nats}, wantErr: true},
+		{
+			name:    "KV with an invalid recipient",
+			target:  natsv1alpha1.ResolverTarget{Type: natsv1alpha1.ResolverTargetKV, NATS: nats, KV: &natsv1alpha1.KVResolverTarget{Bucket: "accounts", MetadataRecipients: []string{"UNOTAKEY"}}},
+			wantErr: true,
+		},
+	} {
+		jwt := &natsv1alpha1.JWTConfig{Resolver: &natsv1alpha1.ResolverConfig{Targets: []natsv1alpha1.ResolverTarget{tt.target}}}
+		if err := validateResolverTargets(jwt); (err != nil) != tt.wantErr {
+			t.Errorf("%s: validateResolverTargets() error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestPublishTargetsHash(t *testing.T) {
+	publish := &natsv1alpha1.AccountPublishing{AccountServer: &natsv1alpha1.AccountServerTarget{URL: "http://nats-account-server:9090"}}
+
+	// Without resolver targets written through publishers the hash is unchanged, so upgrading
+	// does not publish every JWT again
+	data, _ := json.Marshal(publish)
+	sum := sha256.Sum256(data)
+	if got, want := publishTargetsHash(publish, nil, "operator"), hex.EncodeToString(sum[:]); got != want {
+		t.Errorf("publishTargetsHash() = %s, want %s", got, want)
+	}
+
+	directory := []natsv1alpha1.ResolverTarget{{Type: natsv1alpha1.ResolverTargetDirectory}}
+	if publishTargetsHash(publish, directory, "operator") == publishTargetsHash(publish, nil, "operator") {
+		t.Error("publishTargetsHash() did not change with a Directory target")
+	}
+
+	// Only KV targets hold the operator JWT
+	if publishTargetsHash(publish, directory, "operator") != publishTargetsHash(publish, directory, "rotated") {
+		t.Error("publishTargetsHash() changed with the operator JWT without a KV target")
+	}
+	kv := []natsv1alpha1.ResolverTarget{{Type: natsv1alpha1.ResolverTargetKV}}
+	if publishTargetsHash(publish, kv, "operator") == publishTargetsHash(publish, kv, "rotated") {
+		t.Error("publishTargetsHash() did not change with the operator JWT of a KV target")
+	}
+}
+
+func TestResolverPublishersDirectory(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	r := &NatsAuthConfigReconciler{ResolverFs: fs, MountedResolverDirs: []string{"/var/lib/nats-resolver"}}
+	authConfig := &natsv1alpha1.NatsAuthConfig{Spec: natsv1alpha1.NatsAuthConfigSpec{JWT: &natsv1alpha1.JWTConfig{
+		ResolverDir: "/var/lib/nats-resolver",
+	}}}
+	targets := r.resolverTargets(authConfig)
+
+	_, err := r.resolverPublishers(context.Background(), authConfig, targets, "", nil)
+	if depErr, ok := asDependencyError(err); !ok || depErr.Reason != "ResolverDirNotMounted" {
+		t.Fatalf("resolverPublishers() without a volume error = %v, want ResolverDirNotMounted", err)
+	}
+
+	if err := fs.MkdirAll("/var/lib/nats-resolver", 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.writeResolverDirs(authConfig, targets, "operator-token"); err != nil {
+		t.Fatalf("writeResolverDirs() error = %v", err)
+	}
+	if data, _ := afero.ReadFile(fs, "/var/lib/nats-resolver/operator.jwt"); string(data) != "operator-token" {
+		t.Errorf("writeResolverDirs() wrote operator.jwt %q, want %q", data, "operator-token")
+	}
+
+	publishers, err := r.resolverPublishers(context.Background(), authConfig, targets, "", nil)
+	if err != nil {
+		t.Fatalf("resolverPublishers() error = %v", err)
+	}
+	if len(publishers) != 1 {
+		t.Fatalf("resolverPublishers() = %d publishers, want 1", len(publishers))
+	}
+	if err := publishers[0].Publish(context.Background(), "AACCOUNT", "token"); err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+	if exists, _ := afero.Exists(fs, "/var/lib/nats-resolver/accounts/AACCOUNT.jwt"); !exists {
+		t.Error("Publish() did not write the account JWT to the resolver directory")
+	}
+	if err := publishers[0].Delete(context.Background(), "AACCOUNT"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if exists, _ := afero.Exists(fs, "/var/lib/nats-resolver/accounts/AACCOUNT.jwt"); exists {
+		t.Error("Delete() did not remove the account JWT from the resolver directory")
+	}
+}
@@ -0,0 +1,57 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// DefaultResyncInterval is the periodic resync interval used when none is configured
+const DefaultResyncInterval = 5 * time.Minute
+
+// ResyncConfig controls periodic requeueing of successfully reconciled resources
+type ResyncConfig struct {
+	// Interval between periodic resyncs. Zero or negative disables periodic resync
+	// and relies on watches only.
+	Interval time.Duration
+
+	// Jitter is the maximum fraction of Interval added to each requeue to spread load
+	Jitter float64
+}
+
+// Result returns the reconcile result for a successful reconcile, honoring an
+// optional per-resource override of the interval
+func (c ResyncConfig) Result(override *metav1.Duration) ctrl.Result {
+	interval := c.Interval
+	if override != nil {
+		interval = override.Duration
+	}
+
+	if interval <= 0 {
+		return ctrl.Result{}
+	}
+
+	if c.Jitter > 0 {
+		interval = wait.Jitter(interval, c.Jitter)
+	}
+
+	return ctrl.Result{RequeueAfter: interval}
+}
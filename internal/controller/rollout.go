@@ -0,0 +1,95 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	natsv1alpha1 "github.com/jradikk/nats-auth-operator/api/v1alpha1"
+)
+
+const (
+	// configHashAnnotation records the hash of the written server auth config on the ConfigMap or
+	// Secret, and is the default pod annotation of the rollout check
+	configHashAnnotation = "nats.jradikk/config-hash"
+
+	// rolloutCheckInterval is how often a pending rollout is checked again; pods are not watched
+	rolloutCheckInterval = 15 * time.Second
+)
+
+// +kubebuilder:rbac:groups="",resources=pods,verbs=get;list
+
+// checkConfigRollout reports whether every NATS server pod selected by the rollout check is ready
+// and annotated with the hash of the config last written. Without a rollout check the config
+// counts as loaded. The message names the first pod still pending.
+func (r *NatsAuthConfigReconciler) checkConfigRollout(ctx context.Context, authConfig *natsv1alpha1.NatsAuthConfig) (bool, string, error) {
+	check := authConfig.Spec.ServerAuthConfig.RolloutCheck
+	if check == nil {
+		return true, "", nil
+	}
+	selector, err := metav1.LabelSelectorAsSelector(&check.PodSelector)
+	if err != nil {
+		return false, "", permanent(natsv1alpha1.ReasonInvalidSpec, fmt.Errorf("serverAuthConfig.rolloutCheck.podSelector: %w", err))
+	}
+	annotation := check.Annotation
+	if annotation == "" {
+		annotation = configHashAnnotation
+	}
+
+	// Read pods uncached, so the operator does not hold every pod of the cluster in memory
+	reader := r.APIReader
+	if reader == nil {
+		reader = r.Client
+	}
+	pods := &corev1.PodList{}
+	if err := reader.List(ctx, pods, client.InNamespace(authConfig.Spec.ServerAuthConfig.Namespace), client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		return false, "", fmt.Errorf("failed to list NATS server pods: %w", err)
+	}
+	running := 0
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if !pod.DeletionTimestamp.IsZero() {
+			continue
+		}
+		running++
+		if hash := pod.Annotations[annotation]; hash != authConfig.Status.ConfigHash {
+			return false, fmt.Sprintf("pod %s runs config %q, want %q", pod.Name, hash, authConfig.Status.ConfigHash), nil
+		}
+		if !podReady(pod) {
+			return false, fmt.Sprintf("pod %s is not ready", pod.Name), nil
+		}
+	}
+	if running == 0 {
+		return false, fmt.Sprintf("no running pods in namespace %s match serverAuthConfig.rolloutCheck.podSelector", authConfig.Spec.ServerAuthConfig.Namespace), nil
+	}
+	return true, "", nil
+}
+
+func podReady(pod *corev1.Pod) bool {
+	for _, c := range pod.Status.Conditions {
+		if c.Type == corev1.PodReady {
+			return c.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
@@ -0,0 +1,68 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	natsv1alpha1 "github.com/jradikk/nats-auth-operator/api/v1alpha1"
+)
+
+// wantRolloutPending fails the test unless authConfig waits for its config to be loaded
+func wantRolloutPending(t *testing.T, authConfig *natsv1alpha1.NatsAuthConfig, result ctrl.Result) {
+	t.Helper()
+	ready := meta.FindStatusCondition(authConfig.Status.Conditions, "Ready")
+	if authConfig.Status.ResolverReady || ready == nil || ready.Status != metav1.ConditionFalse || ready.Reason != string(natsv1alpha1.ReasonConfigRolloutPending) {
+		t.Errorf("Reconcile() resolverReady %v Ready condition %+v, want ConfigRolloutPending", authConfig.Status.ResolverReady, ready)
+	}
+	if result.RequeueAfter != rolloutCheckInterval {
+		t.Errorf("Reconcile() requeued after %v, want %v", result.RequeueAfter, rolloutCheckInterval)
+	}
+}
+
+func TestConfigRolloutCheck(t *testing.T) {
+	authConfig := testAuthConfig(natsv1alpha1.AuthModeJWT)
+	authConfig.Spec.ServerAuthConfig.RolloutCheck = &natsv1alpha1.ConfigRolloutCheck{
+		PodSelector: metav1.LabelSelector{MatchLabels: map[string]string{"app": "nats"}},
+	}
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "nats", Name: "nats-0", Labels: map[string]string{"app": "nats"},
+			Annotations: map[string]string{configHashAnnotation: "previous"}},
+		Status: corev1.PodStatus{Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionFalse}}},
+	}
+	c, scheme := newTestClient(authConfig, pod)
+	r, _, _ := testReconcilers(c, scheme)
+	ctx := context.Background()
+
+	// A pod still running the previous config holds back Ready
+	result := mustReconcile(t, r, authConfig)
+	mustGet(t, c, authConfig)
+	wantRolloutPending(t, authConfig, result)
+	if live := serverAuthSecret(t, c); authConfig.Status.ConfigHash == "" || live.Annotations[configHashAnnotation] != authConfig.Status.ConfigHash {
+		t.Errorf("Reconcile() annotated the server auth config %q, want the config hash %q", live.Annotations[configHashAnnotation], authConfig.Status.ConfigHash)
+	}
+
+	// and so does one that loaded the config but is not ready yet
+	pod.Annotations[configHashAnnotation] = authConfig.Status.ConfigHash
+	if err := c.Update(ctx, pod); err != nil {
+		t.Fatal(err)
+	}
+	result = mustReconcile(t, r, authConfig)
+	mustGet(t, c, authConfig)
+	wantRolloutPending(t, authConfig, result)
+
+	pod.Status.Conditions[0].Status = corev1.ConditionTrue
+	if err := c.Status().Update(ctx, pod); err != nil {
+		t.Fatal(err)
+	}
+	mustReconcile(t, r, authConfig)
+	mustGet(t, c, authConfig)
+	wantReady(t, authConfig, authConfig.Status.Conditions)
+	if !authConfig.Status.ResolverReady {
+		t.Error("Reconcile() left resolverReady false once every pod loaded the config")
+	}
+}
@@ -0,0 +1,336 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	natsv1alpha1 "github.com/jradikk/nats-auth-operator/api/v1alpha1"
+	"github.com/jradikk/nats-auth-operator/internal/rbac"
+	"github.com/jradikk/nats-auth-operator/internal/resolver"
+	natslabels "github.com/jradikk/nats-auth-operator/pkg/labels"
+)
+
+// SecretAccess is the access to Secrets the operator is granted
+type SecretAccess string
+
+const (
+	// SecretAccessCluster lets the operator list and watch Secrets in every namespace it
+	// watches. It is the default.
+	SecretAccessCluster SecretAccess = "cluster"
+
+	// SecretAccessRestricted limits the operator to the Secrets the RBAC manager grants it by
+	// name. Secrets are read with live Gets rather than from a cache, and changes made to them by
+	// hand are picked up on the next resync rather than through a watch.
+	SecretAccessRestricted SecretAccess = "restricted"
+)
+
+// ParseSecretAccess parses the --secret-access flag
+func ParseSecretAccess(s string) (SecretAccess, error) {
+	switch access := SecretAccess(s); access {
+	case SecretAccessCluster, SecretAccessRestricted:
+		return access, nil
+	default:
+		return "", fmt.Errorf("unknown secret access %q, expected %q or %q", s, SecretAccessCluster, SecretAccessRestricted)
+	}
+}
+
+// WatchesSecrets reports whether Secrets may be listed and watched
+func (a SecretAccess) WatchesSecrets() bool {
+	return a != SecretAccessRestricted
+}
+
+// maxGrantedConfigChunks is the number of overflow Secrets of a chunked server auth config the
+// RBAC manager grants access to, about 12MiB of account JWTs
+const maxGrantedConfigChunks = 16
+
+// secretAccessIndex indexes NatsAuthConfigs, NatsAccounts, NatsUsers and NatsAuthBackups by the
+// namespaces of the Secrets the operator reads or writes for them
+const secretAccessIndex = "secretAccess"
+
+// secretGrant is a Secret the operator reads or writes for a resource
+type secretGrant struct {
+	key client.ObjectKey
+
+	// write is set for Secrets the operator generates. Secrets referenced by the spec are only read.
+	write bool
+}
+
+// secretAccessGrants returns the Secrets the operator reads or writes for a NatsAuthConfig,
+// NatsAccount, NatsUser or NatsAuthBackup: the Secrets it generates and the Secrets referenced by
+// the spec. Names that cannot be rendered from the settings are left out; the resource itself
+// fails with InvalidSpec.
+func secretAccessGrants(obj client.Object, settings *natsv1alpha1.NatsOperatorSettingsSpec) []secretGrant {
+	var grants []secretGrant
+	owned := func(key client.ObjectKey) { grants = append(grants, secretGrant{key: key, write: true}) }
+	referenced := func(key client.ObjectKey) { grants = append(grants, secretGrant{key: key}) }
+
+	for _, key := range seedSecretKeys(obj) {
+		owned(key)
+	}
+	switch o := obj.(type) {
+	case *natsv1alpha1.NatsAuthConfig:
+		ref := o.Spec.ServerAuthConfig
+		if serverAuthConfigType(o) == "Secret" {
+			owned(client.ObjectKey{Namespace: ref.Namespace, Name: ref.Name})
+		}
+		if ref.SizePolicy == natsv1alpha1.SizePolicyChunk {
+			for i := 1; i <= maxGrantedConfigChunks; i++ {
+				owned(client.ObjectKey{Namespace: ref.Namespace, Name: configChunkName(o, i)})
+			}
+		}
+		if o.Spec.InfraAuth != nil {
+			owned(client.ObjectKey{Namespace: ref.Namespace, Name: infraAuthSecretName(o)})
+		}
+		if jwt := o.Spec.JWT; jwt != nil {
+			if jwt.OperatorSeedSecret != nil {
+				referenced(client.ObjectKey{Namespace: jwt.OperatorSeedSecret.Namespace, Name: jwt.OperatorSeedSecret.Name})
+			}
+			if jwt.Publish != nil && jwt.Publish.Bucket != nil {
+				referenced(jwt.Publish.Bucket.CredentialsSecret.ObjectKey(o.Namespace))
+			}
+			for _, target := range pushedResolverTargets(resolverTargets(jwt, nil)) {
+				if target.NATS != nil {
+					referenced(target.NATS.CredentialsSecret.ObjectKey(o.Namespace))
+				}
+			}
+		}
+	case *natsv1alpha1.NatsAccount:
+		if name, err := accountJWTSecretName(o, settings); err == nil {
+			owned(client.ObjectKey{Namespace: o.Namespace, Name: name})
+		}
+		if o.Spec.ExistingSeedSecret != nil {
+			referenced(o.Spec.ExistingSeedSecret.ObjectKey(o.Namespace))
+		}
+	case *natsv1alpha1.NatsUser:
+		if name, err := userCredsSecretName(o, settings); err == nil {
+			owned(client.ObjectKey{Namespace: credsSecretNamespace(o), Name: name})
+		}
+		if o.Spec.ExistingSeedSecret != nil {
+			referenced(o.Spec.ExistingSeedSecret.ObjectKey(o.Namespace))
+		}
+		if o.Spec.ExistingJWTSecret != nil {
+			referenced(o.Spec.ExistingJWTSecret.ObjectKey(o.Namespace))
+		}
+		if o.Spec.PasswordFrom != nil && o.Spec.PasswordFrom.SecretRef != nil {
+			referenced(o.Spec.PasswordFrom.SecretRef.ObjectKey(o.Namespace))
+		}
+		if o.Spec.Output != nil && o.Spec.Output.TLSSecretRef != nil {
+			referenced(o.Spec.Output.TLSSecretRef.ObjectKey(o.Namespace))
+		}
+	case *natsv1alpha1.NatsAuthBackup:
+		name := o.Spec.SecretName
+		if name == "" {
+			name = o.Name + "-backup"
+		}
+		owned(client.ObjectKey{Namespace: o.Namespace, Name: name})
+	}
+	return grants
+}
+
+// crossNamespaceGrantAllowed reports whether the RBAC manager may grant access to a Secret in
+// another namespace than the resource asking for it. The server auth config Secrets of a
+// NatsAuthConfig are written to the namespace of the NATS servers by design; any other Secret
+// outside the resource's namespace, whether generated or referenced, needs its namespace listed
+// in allowed. Otherwise anyone allowed to create a NatsUser could have the operator read or
+// overwrite the Secrets of any namespace.
+func crossNamespaceGrantAllowed(obj client.Object, grant secretGrant, allowed []string) bool {
+	if grant.key.Namespace == obj.GetNamespace() || slices.Contains(allowed, grant.key.Namespace) {
+		return true
+	}
+	authConfig, ok := obj.(*natsv1alpha1.NatsAuthConfig)
+	return ok && grant.write && grant.key.Namespace == authConfig.Spec.ServerAuthConfig.Namespace
+}
+
+// secretAccessNamespaces returns the namespaces of the secretAccessGrants of obj. The namespaces
+// do not depend on the settings, which only template names.
+func secretAccessNamespaces(obj client.Object) []string {
+	var namespaces []string
+	for _, grant := range secretAccessGrants(obj, &natsv1alpha1.NatsOperatorSettingsSpec{}) {
+		if grant.key.Name != "" && !slices.Contains(namespaces, grant.key.Namespace) {
+			namespaces = append(namespaces, grant.key.Namespace)
+		}
+	}
+	return namespaces
+}
+
+// SecretAccessReconciler is the RBAC manager: it maintains, in every namespace, a Role granting
+// the operator access to exactly the Secrets it reads and writes there, for an operator running
+// with SecretAccessRestricted. It runs in its own Deployment under its own ServiceAccount, so the
+// operator never holds the right to change RBAC. Requests are keyed by namespace name.
+//
+// Its RBAC, which includes escalate on Roles, is not generated into the manager role; see
+// config/rbac/rbac_manager_role.yaml.
+type SecretAccessReconciler struct {
+	client.Client
+
+	// Settings loads the NatsOperatorSettings the generated Secret names are rendered from
+	Settings *SettingsLoader
+
+	// ServiceAccount of the operator the Roles are bound to
+	ServiceAccount types.NamespacedName
+
+	// RoleName of the Roles and RoleBindings; rbac.DefaultRoleName when empty
+	RoleName string
+
+	// CrossNamespaceSecrets lists the namespaces whose Secrets may be granted to resources in
+	// other namespaces. Other cross-namespace Secrets are not granted, so the resource fails to
+	// read or write them.
+	CrossNamespaceSecrets []string
+}
+
+func (r *SecretAccessReconciler) roleName() string {
+	if r.RoleName == "" {
+		return rbac.DefaultRoleName
+	}
+	return r.RoleName
+}
+
+func (r *SecretAccessReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	namespace := req.Name
+	log := log.FromContext(ctx).WithValues("namespace", namespace)
+
+	written, read, err := r.secretNames(ctx, namespace)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	key := client.ObjectKey{Namespace: namespace, Name: r.roleName()}
+	if len(written) == 0 && len(read) == 0 {
+		objectMeta := metav1.ObjectMeta{Namespace: key.Namespace, Name: key.Name}
+		for _, obj := range []client.Object{&rbacv1.RoleBinding{ObjectMeta: objectMeta}, &rbacv1.Role{ObjectMeta: objectMeta}} {
+			if err := r.Delete(ctx, obj); err != nil && !errors.IsNotFound(err) {
+				return ctrl.Result{}, fmt.Errorf("failed to delete Secret access Role or RoleBinding %s: %w", key, err)
+			}
+		}
+		log.V(debugLevel).Info("No Secrets to grant, removed the Secret access Role")
+		return ctrl.Result{}, nil
+	}
+
+	labels := natslabels.Owners{}.Labels()
+	if err := resolver.ApplyObject(ctx, r.Client, rbac.SecretRole(key.Namespace, key.Name, written, read, labels)); err != nil {
+		return ctrl.Result{}, err
+	}
+	if err := resolver.ApplyObject(ctx, r.Client, rbac.RoleBinding(key.Namespace, key.Name, r.ServiceAccount, labels)); err != nil {
+		return ctrl.Result{}, err
+	}
+	log.V(debugLevel).Info("Applied the Secret access Role", "writtenSecrets", len(written), "readSecrets", len(read))
+	return ctrl.Result{}, nil
+}
+
+// secretNames returns the names of the Secrets in namespace the operator writes, and of those it
+// only reads. Secrets another namespace refers to without being allowed to are left out.
+func (r *SecretAccessReconciler) secretNames(ctx context.Context, namespace string) (written, read []string, err error) {
+	settings, err := r.Settings.Load(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, list := range []client.ObjectList{
+		&natsv1alpha1.NatsAuthConfigList{},
+		&natsv1alpha1.NatsAccountList{},
+		&natsv1alpha1.NatsUserList{},
+		&natsv1alpha1.NatsAuthBackupList{},
+	} {
+		if err := r.List(ctx, list, client.MatchingFields{secretAccessIndex: namespace}); err != nil {
+			return nil, nil, fmt.Errorf("failed to list resources needing Secrets: %w", err)
+		}
+		items, err := meta.ExtractList(list)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, item := range items {
+			obj := item.(client.Object)
+			for _, grant := range secretAccessGrants(obj, settings) {
+				if grant.key.Namespace != namespace || grant.key.Name == "" {
+					continue
+				}
+				if !crossNamespaceGrantAllowed(obj, grant, r.CrossNamespaceSecrets) {
+					log.FromContext(ctx).Info("Not granting a Secret in another namespace than the resource referring to it",
+						"secret", grant.key.String(), "resource", client.ObjectKeyFromObject(obj).String())
+					continue
+				}
+				if grant.write {
+					written = append(written, grant.key.Name)
+				} else {
+					read = append(read, grant.key.Name)
+				}
+			}
+		}
+	}
+	return written, read, nil
+}
+
+// requestsForNamespaces maps a resource to the namespaces of the Secrets it needs. On updates
+// both the old and the new object are mapped, so access to a Secret no longer referenced is
+// revoked.
+func (r *SecretAccessReconciler) requestsForNamespaces(_ context.Context, obj client.Object) []reconcile.Request {
+	var requests []reconcile.Request
+	for _, namespace := range secretAccessNamespaces(obj) {
+		requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Name: namespace}})
+	}
+	return requests
+}
+
+// requestForRole maps a Role or RoleBinding of the RBAC manager to its namespace, so edits by
+// hand are reverted
+func requestForRole(_ context.Context, obj client.Object) []reconcile.Request {
+	return []reconcile.Request{{NamespacedName: types.NamespacedName{Name: obj.GetNamespace()}}}
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *SecretAccessReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	kinds := []client.Object{
+		&natsv1alpha1.NatsAuthConfig{},
+		&natsv1alpha1.NatsAccount{},
+		&natsv1alpha1.NatsUser{},
+		&natsv1alpha1.NatsAuthBackup{},
+	}
+	for _, obj := range kinds {
+		if err := mgr.GetFieldIndexer().IndexField(context.Background(), obj, secretAccessIndex, secretAccessNamespaces); err != nil {
+			return err
+		}
+	}
+
+	roleName := r.roleName()
+	ownRole := predicate.NewPredicateFuncs(func(obj client.Object) bool {
+		return obj.GetName() == roleName
+	})
+	b := ctrl.NewControllerManagedBy(mgr).
+		Named("secretaccess").
+		Watches(&rbacv1.Role{}, handler.EnqueueRequestsFromMapFunc(requestForRole), builder.WithPredicates(ownRole)).
+		Watches(&rbacv1.RoleBinding{}, handler.EnqueueRequestsFromMapFunc(requestForRole), builder.WithPredicates(ownRole))
+	for _, obj := range kinds {
+		b = b.Watches(obj, handler.EnqueueRequestsFromMapFunc(r.requestsForNamespaces))
+	}
+	return b.Complete(r)
+}
@@ -0,0 +1,63 @@
+package controller
+
+import (
+	"reflect"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	natsv1alpha1 "github.com/jradikk/nats-auth-operator/api/v1alpha1"
+)
+
+func TestSecretAccessGrants(t *testing.T) {
+	user := &natsv1alpha1.NatsUser{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "apps", Name: "orders"},
+		Spec: natsv1alpha1.NatsUserSpec{
+			SecretName:        "orders-creds",
+			SecretNamespace:   "shared",
+			ExistingJWTSecret: &natsv1alpha1.SecretRef{Name: "jwt", Namespace: "kube-system"},
+			PasswordFrom:      &natsv1alpha1.PasswordSource{SecretRef: &natsv1alpha1.PasswordSecretRef{Name: "pw"}},
+		},
+	}
+
+	got := secretAccessGrants(user, &natsv1alpha1.NatsOperatorSettingsSpec{})
+	want := []secretGrant{
+		{key: client.ObjectKey{Namespace: "shared", Name: "orders-creds"}, write: true},
+		{key: client.ObjectKey{Namespace: "kube-system", Name: "jwt"}},
+		{key: client.ObjectKey{Namespace: "apps", Name: "pw"}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("secretAccessGrants() = %+v, want %+v", got, want)
+	}
+
+	for _, tt := range []struct {
+		grant   secretGrant
+		allowed []string
+		want    bool
+	}{
+		{grant: want[0], want: false},
+		{grant: want[0], allowed: []string{"shared"}, want: true},
+		{grant: want[1], allowed: []string{"shared"}, want: false},
+		{grant: want[2], want: true},
+	} {
+		if got := crossNamespaceGrantAllowed(user, tt.grant, tt.allowed); got != tt.want {
+			t.Errorf("crossNamespaceGrantAllowed(%s, %v) = %v, want %v", tt.grant.key, tt.allowed, got, tt.want)
+		}
+	}
+
+	// The server auth config of a NatsAuthConfig is written to the namespace of the NATS servers
+	authConfig := &natsv1alpha1.NatsAuthConfig{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "nats-auth", Name: "main"},
+		Spec: natsv1alpha1.NatsAuthConfigSpec{
+			ServerAuthConfig: natsv1alpha1.ServerAuthConfigRef{Name: "nats-auth", Namespace: "nats"},
+		},
+	}
+	written := secretGrant{key: client.ObjectKey{Namespace: "nats", Name: "nats-auth"}, write: true}
+	if !crossNamespaceGrantAllowed(authConfig, written, nil) {
+		t.Errorf("crossNamespaceGrantAllowed(%s) = false, want true", written.key)
+	}
+	if read := (secretGrant{key: written.key}); crossNamespaceGrantAllowed(authConfig, read, nil) {
+		t.Errorf("crossNamespaceGrantAllowed(%s, read) = true, want false", read.key)
+	}
+}
@@ -0,0 +1,71 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/nats-io/nkeys"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	natsv1alpha1 "github.com/jradikk/nats-auth-operator/api/v1alpha1"
+	jwtpkg "github.com/jradikk/nats-auth-operator/internal/jwt"
+)
+
+// readSeedSecret reads an nkey seed of the given type from a referenced Secret. The namespace
+// defaults to the namespace of the referencing resource; without an explicit key the canonical
+// and legacy seed keys are tried in order.
+func readSeedSecret(ctx context.Context, c client.Client, ref *natsv1alpha1.SeedSecretRef, namespace string, prefix nkeys.PrefixByte) ([]byte, error) {
+	return getSeed(ctx, c, ref.ObjectKey(namespace), prefix, ref.Key)
+}
+
+// getSeed reads an nkey seed of the given type from a Secret, accepting legacy keys
+func getSeed(ctx context.Context, c client.Client, key client.ObjectKey, prefix nkeys.PrefixByte, seedKey string) ([]byte, error) {
+	secret := &corev1.Secret{}
+	if err := c.Get(ctx, key, secret); err != nil {
+		return nil, fmt.Errorf("failed to get seed secret %s: %w", key, err)
+	}
+
+	seed, _, err := jwtpkg.FindSeed(secret.Data, prefix, seedKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid seed secret %s: %w", key, err)
+	}
+	return seed, nil
+}
+
+// seedProtectionFinalizer keeps a seed Secret generated by the operator while the resource
+// whose trust chain depends on the seed exists
+const seedProtectionFinalizer = "nats.jradikk/seed-protection"
+
+// protectSeedSecret sets the seed protection finalizer on a seed Secret about to be written. Only
+// the SeedSecret controller releases the finalizer, and it needs a Secret watch, so under
+// restricted access the Secret is written without it; applying it then also drops a finalizer
+// left by an earlier run with cluster access, so deleting the Secret is not blocked forever.
+func protectSeedSecret(secret *corev1.Secret, access SecretAccess) {
+	if !access.WatchesSecrets() {
+		secret.Finalizers = nil
+		return
+	}
+	secret.Finalizers = []string{seedProtectionFinalizer}
+}
+
+// seedSecretLabels labels a Secret written by the operator with the type of the seed it holds
+func seedSecretLabels(prefix nkeys.PrefixByte) map[string]string {
+	return map[string]string{jwtpkg.SeedTypeLabel: jwtpkg.SeedType(prefix)}
+}
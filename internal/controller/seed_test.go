@@ -0,0 +1,80 @@
+package controller
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	natsv1alpha1 "github.com/jradikk/nats-auth-operator/api/v1alpha1"
+	jwtpkg "github.com/jradikk/nats-auth-operator/internal/jwt"
+)
+
+func TestProtectSeedSecret(t *testing.T) {
+	for _, tt := range []struct {
+		access SecretAccess
+		want   []string
+	}{
+		{access: SecretAccessCluster, want: []string{seedProtectionFinalizer}},
+		// Nothing releases the finalizer without a Secret watch, so it is not added and a
+		// finalizer left by an earlier run is dropped
+		{access: SecretAccessRestricted, want: nil},
+	} {
+		secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Finalizers: []string{seedProtectionFinalizer}}}
+		protectSeedSecret(secret, tt.access)
+		if !reflect.DeepEqual(secret.Finalizers, tt.want) {
+			t.Errorf("protectSeedSecret(%s) finalizers = %v, want %v", tt.access, secret.Finalizers, tt.want)
+		}
+	}
+}
+
+func TestSeedSecretProtection(t *testing.T) {
+	account := testAccount("orders")
+	account.UID = "uid-orders"
+	account.Status.JWTSecretRef = natsv1alpha1.SecretRef{Namespace: account.Namespace, Name: "orders-jwt"}
+	// The seed Secret as left after its label, finalizer and owner were removed by hand
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: account.Namespace, Name: "orders-jwt"},
+		Data:       map[string][]byte{"account.seed": []byte("SA")},
+	}
+	c, scheme := newTestClient(account, secret)
+	recorder := record.NewFakeRecorder(10)
+	r := &SeedSecretReconciler{Client: c, Scheme: scheme, Recorder: recorder}
+	ctx := context.Background()
+
+	mustReconcile(t, r, secret)
+	mustGet(t, c, secret)
+	if secret.Labels[jwtpkg.SeedTypeLabel] != "account" || !controllerutil.ContainsFinalizer(secret, seedProtectionFinalizer) ||
+		metav1.GetControllerOf(secret) == nil || metav1.GetControllerOf(secret).UID != account.UID {
+		t.Errorf("Reconcile() left labels %v finalizers %v owner %v, want the seed metadata restored", secret.Labels, secret.Finalizers, metav1.GetControllerOf(secret))
+	}
+
+	// Deleting the Secret is blocked while the account exists
+	if err := c.Delete(ctx, secret); err != nil {
+		t.Fatal(err)
+	}
+	mustReconcile(t, r, secret)
+	mustGet(t, c, secret)
+	if !controllerutil.ContainsFinalizer(secret, seedProtectionFinalizer) {
+		t.Error("Reconcile() released a seed Secret its NatsAccount still depends on")
+	}
+	if events := drainEvents(recorder); len(events) != 1 || !strings.Contains(events[0], "SeedDeletionBlocked") || !strings.Contains(events[0], "NatsAccount apps/orders") {
+		t.Errorf("Reconcile() recorded %v, want a SeedDeletionBlocked event naming NatsAccount apps/orders", events)
+	}
+
+	// and goes through once the account is gone
+	if err := c.Delete(ctx, account); err != nil {
+		t.Fatal(err)
+	}
+	mustReconcile(t, r, secret)
+	if err := c.Get(ctx, client.ObjectKeyFromObject(secret), secret); !errors.IsNotFound(err) {
+		t.Errorf("seed Secret after its NatsAccount was deleted: finalizers %v, %v, want it deleted", secret.Finalizers, err)
+	}
+}
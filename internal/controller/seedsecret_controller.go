@@ -0,0 +1,232 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	natsv1alpha1 "github.com/jradikk/nats-auth-operator/api/v1alpha1"
+	jwtpkg "github.com/jradikk/nats-auth-operator/internal/jwt"
+)
+
+// SeedSecretReconciler protects the seed Secrets generated by the operator. A Secret is kept,
+// through seedProtectionFinalizer, while the NatsAuthConfig, NatsAccount or NatsUser whose key
+// it holds exists, and its seed type label, finalizer and owner are restored when removed by hand.
+type SeedSecretReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+
+	// Recorder emits events on Secrets whose deletion is blocked; optional
+	Recorder record.EventRecorder
+}
+
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups=nats.jradikk,resources=natsauthconfigs;natsaccounts;natsusers,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+
+func (r *SeedSecretReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	secret := &corev1.Secret{}
+	if err := r.Get(ctx, req.NamespacedName, secret); err != nil {
+		if errors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+
+	ctx, log := reconcileLogger(ctx, "Secret", secret)
+
+	dependents, err := r.seedDependents(ctx, secret)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if !secret.DeletionTimestamp.IsZero() {
+		if !controllerutil.ContainsFinalizer(secret, seedProtectionFinalizer) {
+			return ctrl.Result{}, nil
+		}
+		if len(dependents) > 0 {
+			names := make([]string, 0, len(dependents))
+			for _, dependent := range dependents {
+				names = append(names, dependentName(dependent))
+			}
+			log.Info("Blocking deletion of seed Secret", "dependents", names)
+			if r.Recorder != nil {
+				r.Recorder.Eventf(secret, corev1.EventTypeWarning, "SeedDeletionBlocked",
+					"Seed Secret is kept until %s is deleted", strings.Join(names, ", "))
+			}
+			return ctrl.Result{}, nil
+		}
+
+		patch := client.MergeFrom(secret.DeepCopy())
+		controllerutil.RemoveFinalizer(secret, seedProtectionFinalizer)
+		if err := r.Patch(ctx, secret, patch); err != nil {
+			return ctrl.Result{}, fmt.Errorf("failed to remove seed protection finalizer: %w", err)
+		}
+		log.Info("Released seed Secret, no resource depends on it")
+		return ctrl.Result{}, nil
+	}
+
+	if len(dependents) == 0 {
+		return ctrl.Result{}, nil
+	}
+
+	patch := client.MergeFrom(secret.DeepCopy())
+	restored, err := r.restoreOwnership(secret, dependents[0])
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if len(restored) == 0 {
+		return ctrl.Result{}, nil
+	}
+	if err := r.Patch(ctx, secret, patch); err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to restore seed Secret metadata: %w", err)
+	}
+	log.Info("Restored seed Secret metadata", "restored", restored, "owner", dependentName(dependents[0]))
+	return ctrl.Result{}, nil
+}
+
+// seedDependents returns the resources, not being deleted, whose generated seed the Secret holds
+func (r *SeedSecretReconciler) seedDependents(ctx context.Context, secret *corev1.Secret) ([]client.Object, error) {
+	key := client.ObjectKeyFromObject(secret).String()
+	lists := []client.ObjectList{
+		&natsv1alpha1.NatsAuthConfigList{},
+		&natsv1alpha1.NatsAccountList{},
+		&natsv1alpha1.NatsUserList{},
+	}
+
+	var dependents []client.Object
+	for _, list := range lists {
+		if err := r.List(ctx, list, client.MatchingFields{seedSecretIndex: key}); err != nil {
+			return nil, fmt.Errorf("failed to list seed Secret dependents: %w", err)
+		}
+		items, err := meta.ExtractList(list)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range items {
+			obj, ok := item.(client.Object)
+			if ok && obj.GetDeletionTimestamp().IsZero() {
+				dependents = append(dependents, obj)
+			}
+		}
+	}
+	return dependents, nil
+}
+
+// restoreOwnership puts back the seed type label, the protection finalizer and the owner of a seed
+// Secret, and returns what was restored
+func (r *SeedSecretReconciler) restoreOwnership(secret *corev1.Secret, owner client.Object) ([]string, error) {
+	var restored []string
+
+	if seedType := dependentSeedType(owner); secret.Labels[jwtpkg.SeedTypeLabel] != seedType {
+		if secret.Labels == nil {
+			secret.Labels = map[string]string{}
+		}
+		secret.Labels[jwtpkg.SeedTypeLabel] = seedType
+		restored = append(restored, "label")
+	}
+	if controllerutil.AddFinalizer(secret, seedProtectionFinalizer) {
+		restored = append(restored, "finalizer")
+	}
+
+	if user, ok := owner.(*natsv1alpha1.NatsUser); ok &&
+		(secret.Labels[userNameLabel] != user.Name || secret.Labels[userNamespaceLabel] != user.Namespace) {
+		secret.Labels[userNameLabel] = user.Name
+		secret.Labels[userNamespaceLabel] = user.Namespace
+		restored = append(restored, "owner labels")
+	}
+	if owner.GetNamespace() == secret.Namespace && metav1.GetControllerOf(secret) == nil {
+		if err := controllerutil.SetControllerReference(owner, secret, r.Scheme); err != nil {
+			return nil, err
+		}
+		restored = append(restored, "owner reference")
+	}
+	return restored, nil
+}
+
+// dependentSeedType returns the SeedTypeLabel value of the seed a resource depends on
+func dependentSeedType(obj client.Object) string {
+	switch obj.(type) {
+	case *natsv1alpha1.NatsAuthConfig:
+		return "operator"
+	case *natsv1alpha1.NatsAccount:
+		return "account"
+	case *natsv1alpha1.NatsUser:
+		return "user"
+	}
+	return ""
+}
+
+// dependentName returns "Kind namespace/name" of a dependent resource for logs and events
+func dependentName(obj client.Object) string {
+	kind := "NatsAuthConfig"
+	switch obj.(type) {
+	case *natsv1alpha1.NatsAccount:
+		kind = "NatsAccount"
+	case *natsv1alpha1.NatsUser:
+		kind = "NatsUser"
+	}
+	return kind + " " + client.ObjectKeyFromObject(obj).String()
+}
+
+// requestsForSeedSecret maps a NatsAuthConfig, NatsAccount or NatsUser to its generated seed Secret
+func (r *SeedSecretReconciler) requestsForSeedSecret(_ context.Context, obj client.Object) []reconcile.Request {
+	var requests []reconcile.Request
+	for _, key := range seedSecretKeys(obj) {
+		requests = append(requests, reconcile.Request{NamespacedName: key})
+	}
+	return requests
+}
+
+// seedSecretPredicate passes Secrets labeled with a seed type or carrying the protection finalizer
+var seedSecretPredicate = predicate.NewPredicateFuncs(func(obj client.Object) bool {
+	_, labeled := obj.GetLabels()[jwtpkg.SeedTypeLabel]
+	return labeled || controllerutil.ContainsFinalizer(obj, seedProtectionFinalizer)
+})
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *SeedSecretReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	for _, obj := range []client.Object{&natsv1alpha1.NatsAuthConfig{}, &natsv1alpha1.NatsAccount{}, &natsv1alpha1.NatsUser{}} {
+		if err := mgr.GetFieldIndexer().IndexField(context.Background(), obj, seedSecretIndex, indexBySeedSecret); err != nil {
+			return err
+		}
+	}
+
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("seedsecret").
+		For(&corev1.Secret{}, builder.WithPredicates(seedSecretPredicate)).
+		Watches(&natsv1alpha1.NatsAuthConfig{}, handler.EnqueueRequestsFromMapFunc(r.requestsForSeedSecret)).
+		Watches(&natsv1alpha1.NatsAccount{}, handler.EnqueueRequestsFromMapFunc(r.requestsForSeedSecret)).
+		Watches(&natsv1alpha1.NatsUser{}, handler.EnqueueRequestsFromMapFunc(r.requestsForSeedSecret)).
+		Complete(r)
+}
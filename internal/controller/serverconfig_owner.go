@@ -0,0 +1,100 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	natsv1alpha1 "github.com/jradikk/nats-auth-operator/api/v1alpha1"
+)
+
+// checkServerAuthConfigOwner returns a permanent error when another NatsAuthConfig writes the same
+// server auth config. Server-side apply under one field manager would let each replace the keys of
+// the other, so only the oldest NatsAuthConfig writing the object owns it.
+func (r *NatsAuthConfigReconciler) checkServerAuthConfigOwner(ctx context.Context, authConfig *natsv1alpha1.NatsAuthConfig) error {
+	owner, err := r.serverAuthConfigOwner(ctx, authConfig)
+	if err != nil {
+		return err
+	}
+	if owner.UID == authConfig.UID {
+		return nil
+	}
+
+	ref := authConfig.Spec.ServerAuthConfig
+	if r.Recorder != nil {
+		r.Recorder.Eventf(authConfig, corev1.EventTypeWarning, string(natsv1alpha1.ReasonServerAuthConfigConflict),
+			"Server auth config %s %s/%s is written by NatsAuthConfig %s/%s", serverAuthConfigType(authConfig), ref.Namespace, ref.Name, owner.Namespace, owner.Name)
+	}
+	return permanent(natsv1alpha1.ReasonServerAuthConfigConflict,
+		fmt.Errorf("server auth config %s %s/%s is already written by NatsAuthConfig %s/%s; point serverAuthConfig at another object",
+			serverAuthConfigType(authConfig), ref.Namespace, ref.Name, owner.Namespace, owner.Name))
+}
+
+// serverAuthConfigOwner returns the oldest NatsAuthConfig, not being deleted, that writes the same
+// server auth config as authConfig, or authConfig itself. Ties are broken by namespace and name.
+func (r *NatsAuthConfigReconciler) serverAuthConfigOwner(ctx context.Context, authConfig *natsv1alpha1.NatsAuthConfig) (*natsv1alpha1.NatsAuthConfig, error) {
+	list := &natsv1alpha1.NatsAuthConfigList{}
+	if err := r.List(ctx, list, client.MatchingFields{serverAuthConfigIndex: indexAuthConfigByServerConfig(authConfig)[0]}); err != nil {
+		return nil, fmt.Errorf("failed to list NatsAuthConfigs sharing the server auth config: %w", err)
+	}
+
+	owner := authConfig
+	for i := range list.Items {
+		other := &list.Items[i]
+		if other.UID == authConfig.UID || !other.DeletionTimestamp.IsZero() ||
+			serverAuthConfigType(other) != serverAuthConfigType(authConfig) {
+			continue
+		}
+		if claimsServerAuthConfigBefore(other, owner) {
+			owner = other
+		}
+	}
+	return owner, nil
+}
+
+// claimsServerAuthConfigBefore reports whether a takes precedence over b for a shared server auth config
+func claimsServerAuthConfigBefore(a, b *natsv1alpha1.NatsAuthConfig) bool {
+	if !a.CreationTimestamp.Equal(&b.CreationTimestamp) {
+		return a.CreationTimestamp.Before(&b.CreationTimestamp)
+	}
+	return client.ObjectKeyFromObject(a).String() < client.ObjectKeyFromObject(b).String()
+}
+
+// findAuthConfigsSharingServerConfig maps a changed NatsAuthConfig to the others writing the same
+// server auth config, so the next oldest takes it over once the owner is deleted or moved away
+func (r *NatsAuthConfigReconciler) findAuthConfigsSharingServerConfig(ctx context.Context, obj client.Object) []reconcile.Request {
+	list := &natsv1alpha1.NatsAuthConfigList{}
+	if err := r.List(ctx, list, client.MatchingFields{serverAuthConfigIndex: indexAuthConfigByServerConfig(obj)[0]}); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to list NatsAuthConfigs sharing the server auth config")
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for _, authConfig := range list.Items {
+		if authConfig.UID == obj.GetUID() {
+			continue
+		}
+		requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&authConfig)})
+	}
+	return requests
+}
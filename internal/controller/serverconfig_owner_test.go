@@ -0,0 +1,60 @@
+package controller
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	natsv1alpha1 "github.com/jradikk/nats-auth-operator/api/v1alpha1"
+	"github.com/jradikk/nats-auth-operator/internal/authconf"
+)
+
+func TestServerAuthConfigConflict(t *testing.T) {
+	owner := testAuthConfig(natsv1alpha1.AuthModeJWT)
+	owner.CreationTimestamp = metav1.NewTime(time.Now().Add(-time.Hour))
+	second := testAuthConfig(natsv1alpha1.AuthModeJWT)
+	second.Name, second.UID = "auth-b", "uid-auth-b"
+	second.CreationTimestamp = metav1.NewTime(time.Now())
+	c, scheme := newTestClient(owner, second)
+	recorder := record.NewFakeRecorder(10)
+	r := &NatsAuthConfigReconciler{Client: c, Scheme: scheme, Recorder: recorder}
+	ctx := context.Background()
+
+	mustReconcile(t, r, owner)
+	written := serverAuthSecret(t, c)
+	drainEvents(recorder)
+
+	// The newer NatsAuthConfig is refused rather than replacing the config of the older one
+	mustReconcile(t, r, second)
+	mustGet(t, c, second)
+	ready := meta.FindStatusCondition(second.Status.Conditions, "Ready")
+	if ready == nil || ready.Status != metav1.ConditionFalse || ready.Reason != string(natsv1alpha1.ReasonServerAuthConfigConflict) || !strings.Contains(ready.Message, "nats/auth") {
+		t.Errorf("Reconcile() Ready condition = %+v, want ServerAuthConfigConflict naming nats/auth", ready)
+	}
+	if events := drainEvents(recorder); len(events) == 0 || !strings.Contains(events[0], string(natsv1alpha1.ReasonServerAuthConfigConflict)) {
+		t.Errorf("Reconcile() recorded %v, want a ServerAuthConfigConflict event", events)
+	}
+	if live := serverAuthSecret(t, c); string(live.Data[authconf.OperatorKey]) != string(written.Data[authconf.OperatorKey]) {
+		t.Error("Reconcile() of the conflicting NatsAuthConfig replaced the operator of the owner")
+	}
+
+	// Once the owner is deleted the next oldest takes the server auth config over
+	if err := c.Delete(ctx, owner); err != nil {
+		t.Fatal(err)
+	}
+	if requests := r.findAuthConfigsSharingServerConfig(ctx, owner); len(requests) != 1 || requests[0].NamespacedName != client.ObjectKeyFromObject(second) {
+		t.Errorf("findAuthConfigsSharingServerConfig() = %v, want nats/auth-b", requests)
+	}
+	mustReconcile(t, r, second)
+	mustGet(t, c, second)
+	wantReady(t, second, second.Status.Conditions)
+	if live := serverAuthSecret(t, c); string(live.Data[authconf.OperatorKey]) == string(written.Data[authconf.OperatorKey]) {
+		t.Error("Reconcile() after the owner was deleted kept its operator, want the one of nats/auth-b")
+	}
+}
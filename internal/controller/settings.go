@@ -0,0 +1,263 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	natsv1alpha1 "github.com/jradikk/nats-auth-operator/api/v1alpha1"
+	jwtpkg "github.com/jradikk/nats-auth-operator/internal/jwt"
+	"github.com/jradikk/nats-auth-operator/internal/token"
+)
+
+// DefaultSettingsName is the NatsOperatorSettings object read unless another name is configured
+const DefaultSettingsName = "default"
+
+// Secret name templates used when the settings do not set one
+const (
+	defaultUserCredsSecretTemplate  = "{{ .Name }}-user-creds"
+	defaultAccountJWTSecretTemplate = "{{ .Name }}-account-jwt"
+)
+
+// +kubebuilder:rbac:groups=nats.jradikk,resources=natsoperatorsettings,verbs=get;list;watch
+
+// SettingsLoader reads the cluster-wide NatsOperatorSettings consumed by all reconcilers
+type SettingsLoader struct {
+	client.Reader
+
+	// Name of the NatsOperatorSettings object (defaults to DefaultSettingsName)
+	Name string
+}
+
+// Load returns the operator settings. Without a loader, a settings object or its CRD,
+// empty settings are returned so the built-in defaults and flags apply.
+func (l *SettingsLoader) Load(ctx context.Context) (*natsv1alpha1.NatsOperatorSettingsSpec, error) {
+	if l == nil || l.Reader == nil {
+		return &natsv1alpha1.NatsOperatorSettingsSpec{}, nil
+	}
+	name := l.Name
+	if name == "" {
+		name = DefaultSettingsName
+	}
+
+	settings := &natsv1alpha1.NatsOperatorSettings{}
+	if err := l.Get(ctx, client.ObjectKey{Name: name}, settings); err != nil {
+		if errors.IsNotFound(err) || meta.IsNoMatchError(err) {
+			return &natsv1alpha1.NatsOperatorSettingsSpec{}, nil
+		}
+		return nil, fmt.Errorf("failed to get NatsOperatorSettings %s: %w", name, err)
+	}
+	return &settings.Spec, nil
+}
+
+// withSettings returns the resync configuration with the settings' default interval applied
+func (c ResyncConfig) withSettings(settings *natsv1alpha1.NatsOperatorSettingsSpec) ResyncConfig {
+	if settings.ResyncInterval != nil {
+		c.Interval = settings.ResyncInterval.Duration
+	}
+	return c
+}
+
+// resync returns base with the settings' default interval applied. When the settings cannot be
+// loaded the configured interval is kept, as the resource itself reconciled successfully.
+func (l *SettingsLoader) resync(ctx context.Context, base ResyncConfig) ResyncConfig {
+	settings, err := l.Load(ctx)
+	if err != nil {
+		log.FromContext(ctx).Error(err, "Failed to load operator settings, keeping the configured resync interval")
+		return base
+	}
+	return base.withSettings(settings)
+}
+
+// userExpiry returns the lifetime of issued user JWTs, capped by userClaims.maxExpiry of the
+// NatsAuthConfig; zero means the JWT does not expire
+func userExpiry(user *natsv1alpha1.NatsUser, authConfig *natsv1alpha1.NatsAuthConfig, settings *natsv1alpha1.NatsOperatorSettingsSpec) time.Duration {
+	var expiry time.Duration
+	switch {
+	case user.Spec.Expiry != nil:
+		expiry = user.Spec.Expiry.Duration
+	case settings.UserDefaults != nil && settings.UserDefaults.Expiry != nil:
+		expiry = settings.UserDefaults.Expiry.Duration
+	}
+	if maxExpiry := userClaimPolicy(authConfig).MaxExpiry; maxExpiry != nil && maxExpiry.Duration > 0 && (expiry <= 0 || expiry > maxExpiry.Duration) {
+		expiry = maxExpiry.Duration
+	}
+	return expiry
+}
+
+// userValidity returns the validity window of issued user JWTs: the expiry, spec.notBefore and the
+// clock skew tolerance of the user or the settings default
+func userValidity(user *natsv1alpha1.NatsUser, authConfig *natsv1alpha1.NatsAuthConfig, settings *natsv1alpha1.NatsOperatorSettingsSpec) (jwtpkg.Validity, error) {
+	validity := jwtpkg.Validity{Expiry: userExpiry(user, authConfig, settings)}
+	if user.Spec.NotBefore != nil {
+		validity.NotBefore = user.Spec.NotBefore.Time
+	}
+	switch {
+	case user.Spec.ClockSkewTolerance != nil:
+		validity.ClockSkew = user.Spec.ClockSkewTolerance.Duration
+	case settings.UserDefaults != nil && settings.UserDefaults.ClockSkewTolerance != nil:
+		validity.ClockSkew = settings.UserDefaults.ClockSkewTolerance.Duration
+		if err := natsv1alpha1.ValidateClockSkewTolerance(validity.ClockSkew); err != nil {
+			return jwtpkg.Validity{}, permanent(natsv1alpha1.ReasonInvalidSpec, fmt.Errorf("invalid userDefaults.clockSkewTolerance in the operator settings: %w", err))
+		}
+	}
+	return validity, nil
+}
+
+// renewalDue reports whether an issued user JWT has less than a third of its lifetime left
+func renewalDue(user *natsv1alpha1.NatsUser, expiry time.Duration, now time.Time) bool {
+	if expiry <= 0 || user.Status.ExpiresAt == nil {
+		return false
+	}
+	return now.After(renewalTime(user.Status.ExpiresAt.Time, expiry))
+}
+
+// renewalTime returns when a user JWT expiring at expiresAt is re-issued
+func renewalTime(expiresAt time.Time, expiry time.Duration) time.Time {
+	return expiresAt.Add(-expiry / 3)
+}
+
+// renewalResult shortens the resync of a user so its JWT is re-issued before it expires
+func renewalResult(result ctrl.Result, user *natsv1alpha1.NatsUser, authConfig *natsv1alpha1.NatsAuthConfig, settings *natsv1alpha1.NatsOperatorSettingsSpec) ctrl.Result {
+	expiry := userExpiry(user, authConfig, settings)
+	if expiry <= 0 || user.Status.ExpiresAt == nil || user.Spec.ExistingJWTSecret != nil {
+		return result
+	}
+	until := time.Until(renewalTime(user.Status.ExpiresAt.Time, expiry))
+	if until < time.Second {
+		until = time.Second
+	}
+	if result.RequeueAfter == 0 || until < result.RequeueAfter {
+		result.RequeueAfter = until
+	}
+	return result
+}
+
+// userLimits returns the user limits from the spec or the settings defaults
+func userLimits(user *natsv1alpha1.NatsUser, settings *natsv1alpha1.NatsOperatorSettingsSpec) *natsv1alpha1.UserLimits {
+	if user.Spec.Limits != nil {
+		return user.Spec.Limits
+	}
+	if settings.UserDefaults != nil {
+		return settings.UserDefaults.Limits
+	}
+	return nil
+}
+
+// accountLimits returns the account limits from the spec or the settings defaults
+func accountLimits(account *natsv1alpha1.NatsAccount, settings *natsv1alpha1.NatsOperatorSettingsSpec) *natsv1alpha1.AccountLimits {
+	if account.Spec.Limits != nil {
+		return account.Spec.Limits
+	}
+	if settings.AccountDefaults != nil {
+		return settings.AccountDefaults.Limits
+	}
+	return nil
+}
+
+// userCredsSecretName returns the name of the credentials Secret of a user: spec.secretName when
+// set, otherwise the name of its existing Secret; new users get the name from the settings template.
+func userCredsSecretName(user *natsv1alpha1.NatsUser, settings *natsv1alpha1.NatsOperatorSettingsSpec) (string, error) {
+	if user.Spec.SecretName != "" {
+		return user.Spec.SecretName, nil
+	}
+	if user.Status.SecretRef.Name != "" {
+		return user.Status.SecretRef.Name, nil
+	}
+	tmpl := defaultUserCredsSecretTemplate
+	if settings.SecretNames != nil && settings.SecretNames.UserCredentials != "" {
+		tmpl = settings.SecretNames.UserCredentials
+	}
+	return renderSecretName(tmpl, user)
+}
+
+// userCredsSecretType returns the type of the credentials Secret of a user: spec.output.secretType,
+// the settings default, or Opaque
+func userCredsSecretType(user *natsv1alpha1.NatsUser, settings *natsv1alpha1.NatsOperatorSettingsSpec) (corev1.SecretType, error) {
+	if user.Spec.Output != nil && user.Spec.Output.SecretType != "" {
+		return corev1.SecretType(user.Spec.Output.SecretType), nil
+	}
+	if settings.UserDefaults != nil && settings.UserDefaults.CredentialsSecretType != "" {
+		if err := natsv1alpha1.ValidateSecretType(settings.UserDefaults.CredentialsSecretType); err != nil {
+			return "", permanent(natsv1alpha1.ReasonInvalidSpec, fmt.Errorf("invalid userDefaults.credentialsSecretType in the operator settings: %w", err))
+		}
+		return corev1.SecretType(settings.UserDefaults.CredentialsSecretType), nil
+	}
+	return corev1.SecretTypeOpaque, nil
+}
+
+// tokenUsername returns the username of a token user: spec.username, the username the user already
+// holds, or one generated from the settings template and the user UID
+func tokenUsername(user *natsv1alpha1.NatsUser, settings *natsv1alpha1.NatsOperatorSettingsSpec) (string, error) {
+	if user.Spec.Username != "" {
+		return user.Spec.Username, nil
+	}
+	if user.Status.Username != "" {
+		return user.Status.Username, nil
+	}
+	var tmpl string
+	if settings.UserDefaults != nil {
+		tmpl = settings.UserDefaults.UsernameTemplate
+	}
+	return token.GenerateUsername(tmpl, token.UsernameData{Name: user.Name, Namespace: user.Namespace, UID: string(user.UID)})
+}
+
+// accountJWTSecretName returns the name of the JWT Secret of an account. An account keeps the
+// name of its existing Secret, which holds its seed; new accounts get the name from the settings template.
+func accountJWTSecretName(account *natsv1alpha1.NatsAccount, settings *natsv1alpha1.NatsOperatorSettingsSpec) (string, error) {
+	if account.Status.JWTSecretRef.Name != "" {
+		return account.Status.JWTSecretRef.Name, nil
+	}
+	tmpl := defaultAccountJWTSecretTemplate
+	if settings.SecretNames != nil && settings.SecretNames.AccountJWT != "" {
+		tmpl = settings.SecretNames.AccountJWT
+	}
+	return renderSecretName(tmpl, account)
+}
+
+// renderSecretName renders a Secret name template for a resource and validates the result
+func renderSecretName(tmpl string, obj metav1.Object) (string, error) {
+	t, err := template.New("secretName").Option("missingkey=error").Parse(tmpl)
+	if err != nil {
+		return "", fmt.Errorf("invalid Secret name template %q: %w", tmpl, err)
+	}
+
+	var sb strings.Builder
+	data := struct{ Name, Namespace string }{Name: obj.GetName(), Namespace: obj.GetNamespace()}
+	if err := t.Execute(&sb, data); err != nil {
+		return "", fmt.Errorf("failed to render Secret name template %q: %w", tmpl, err)
+	}
+
+	name := sb.String()
+	if errs := validation.IsDNS1123Subdomain(name); len(errs) > 0 {
+		return "", fmt.Errorf("Secret name template %q rendered invalid name %q: %s", tmpl, name, strings.Join(errs, ", "))
+	}
+	return name, nil
+}
@@ -0,0 +1,165 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	natsv1alpha1 "github.com/jradikk/nats-auth-operator/api/v1alpha1"
+	jwtpkg "github.com/jradikk/nats-auth-operator/internal/jwt"
+)
+
+// accountSharing converts the exports and imports of a JWT-mode account to their claims form.
+// Imports are resolved to the public key of the exporting account, which must belong to the
+// same NatsAuthConfig and export the subject to this account. For each import of a restricted
+// export the exporting account is returned at the same index, to sign the activation token.
+func (r *NatsAccountReconciler) accountSharing(ctx context.Context, account *natsv1alpha1.NatsAccount) (jwtpkg.AccountSharing, []*natsv1alpha1.NatsAccount, error) {
+	var sharing jwtpkg.AccountSharing
+	for _, export := range account.Spec.Exports {
+		e, err := jwtpkg.AccountExport(export)
+		if err != nil {
+			return sharing, nil, permanent(natsv1alpha1.ReasonInvalidSpec, err)
+		}
+		sharing.Exports = append(sharing.Exports, e)
+	}
+
+	activators := make([]*natsv1alpha1.NatsAccount, 0, len(account.Spec.Imports))
+	for _, imp := range account.Spec.Imports {
+		exporter, export, err := r.resolveImport(ctx, account, imp)
+		if err != nil {
+			return sharing, nil, err
+		}
+		sharing.Imports = append(sharing.Imports, jwtpkg.AccountImport(imp, exporter.Status.AccountID))
+		if len(export.Accounts) > 0 {
+			activators = append(activators, exporter)
+		} else {
+			activators = append(activators, nil)
+		}
+	}
+	return sharing, activators, nil
+}
+
+// resolveImport returns the NatsAccount an import is taken from and its export covering the import
+func (r *NatsAccountReconciler) resolveImport(ctx context.Context, account *natsv1alpha1.NatsAccount, imp natsv1alpha1.AccountImport) (*natsv1alpha1.NatsAccount, *natsv1alpha1.AccountExport, error) {
+	key := accountRefKey(imp.AccountRef, account.Namespace)
+	exporter := &natsv1alpha1.NatsAccount{}
+	if err := r.Get(ctx, key, exporter); err != nil {
+		if errors.IsNotFound(err) {
+			return nil, nil, &dependencyError{Kind: "NatsAccount", Name: key.String(), Reason: "AccountNotFound", Message: "exporting account does not exist"}
+		}
+		return nil, nil, fmt.Errorf("failed to get exporting account %s: %w", key, err)
+	}
+	if accountAuthConfigKey(exporter) != accountAuthConfigKey(account) {
+		return nil, nil, permanent(natsv1alpha1.ReasonInvalidSpec,
+			fmt.Errorf("exporting account %s belongs to another NatsAuthConfig", key))
+	}
+
+	var export *natsv1alpha1.AccountExport
+	for i := range exporter.Spec.Exports {
+		if jwtpkg.ExportCovers(exporter.Spec.Exports[i], imp) {
+			export = &exporter.Spec.Exports[i]
+			break
+		}
+	}
+	if export == nil {
+		return nil, nil, permanent(natsv1alpha1.ReasonInvalidSpec,
+			fmt.Errorf("NatsAccount %s does not export %s %q", key, imp.Type, imp.Subject))
+	}
+	if len(export.Accounts) > 0 && !exportAllows(export, exporter, account) {
+		return nil, nil, permanent(natsv1alpha1.ReasonInvalidSpec,
+			fmt.Errorf("NatsAccount %s does not export %s %q to this account", key, imp.Type, imp.Subject))
+	}
+
+	if exporter.Status.AccountID == "" {
+		return nil, nil, &dependencyError{Kind: "NatsAccount", Name: key.String(), Reason: "AccountNotReady", Message: "exporting account has no key yet"}
+	}
+	return exporter, export, nil
+}
+
+// exportAllows reports whether a restricted export of exporter lists the importing account
+func exportAllows(export *natsv1alpha1.AccountExport, exporter, importer *natsv1alpha1.NatsAccount) bool {
+	for _, ref := range export.Accounts {
+		if accountRefKey(ref, exporter.Namespace) == client.ObjectKeyFromObject(importer) {
+			return true
+		}
+	}
+	return false
+}
+
+// signActivations signs the activation token of every import of a restricted export with the
+// key of the exporting account, keyed by import index
+func (r *NatsAccountReconciler) signActivations(ctx context.Context, account *natsv1alpha1.NatsAccount, authConfig *natsv1alpha1.NatsAuthConfig, importer string, activators []*natsv1alpha1.NatsAccount) (map[int]string, error) {
+	tokens := map[int]string{}
+	for i, exporter := range activators {
+		if exporter == nil {
+			continue
+		}
+		kp, err := accountKeyPair(ctx, r.Client, exporter, authConfig)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get key of exporting account %s: %w", client.ObjectKeyFromObject(exporter), err)
+		}
+		exporterMgr := jwtpkg.NewAccountManagerFromKeyPair(kp)
+		imp := account.Spec.Imports[i]
+		token, err := exporterMgr.SignActivation(importer, imp.Subject, imp.Type)
+		if err != nil {
+			return nil, err
+		}
+		tokens[i] = token
+	}
+	return tokens, nil
+}
+
+// accountRefKey returns the key of a referenced NatsAccount, defaulting to the given namespace
+func accountRefKey(ref natsv1alpha1.NatsAccountRef, namespace string) client.ObjectKey {
+	if ref.Namespace != "" {
+		namespace = ref.Namespace
+	}
+	return client.ObjectKey{Namespace: namespace, Name: ref.Name}
+}
+
+// findAccountsImporting maps a NatsAccount to the accounts importing from it, so they pick up its
+// key and exports
+func (r *NatsAccountReconciler) findAccountsImporting(ctx context.Context, obj client.Object) []reconcile.Request {
+	accountList := &natsv1alpha1.NatsAccountList{}
+	if err := r.List(ctx, accountList, client.MatchingFields{accountImportIndex: client.ObjectKeyFromObject(obj).String()}); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to list importing NatsAccounts")
+		return nil
+	}
+
+	requests := make([]reconcile.Request, 0, len(accountList.Items))
+	for _, account := range accountList.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&account)})
+	}
+	return requests
+}
+
+// exportsChangedPredicate passes NatsAccount updates that change the spec or the account key
+var exportsChangedPredicate = predicate.Funcs{
+	UpdateFunc: func(e event.UpdateEvent) bool {
+		oldAccount, okOld := e.ObjectOld.(*natsv1alpha1.NatsAccount)
+		newAccount, okNew := e.ObjectNew.(*natsv1alpha1.NatsAccount)
+		return okOld && okNew && (oldAccount.Generation != newAccount.Generation || oldAccount.Status.AccountID != newAccount.Status.AccountID)
+	},
+}
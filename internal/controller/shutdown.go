@@ -0,0 +1,196 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+// DefaultShutdownTimeout bounds how long in-flight server auth config writes and account JWT
+// pushes may run once the operator is stopping. Together with minStatusFlushTimeout it stays
+// within the default termination grace period of the operator pod.
+const DefaultShutdownTimeout = 5 * time.Second
+
+// minStatusFlushTimeout is the least time given to recording unsynced state at shutdown
+const minStatusFlushTimeout = 3 * time.Second
+
+// errShuttingDown stops reconciles that start after the operator began stopping
+var errShuttingDown = errors.New("the operator is shutting down")
+
+// shutdownFlush tracks the queued and in-flight reconciles of the NatsAuthConfig controller.
+// In-flight reconciles run on a context that outlives the manager, so a server auth config write
+// or account JWT push that has started completes instead of being cut off halfway. When the
+// manager stops, the flush waits up to timeout for them, then records every NatsAuthConfig whose
+// changes were not written in its status; the next operator instance reconciles it on startup.
+type shutdownFlush struct {
+	timeout time.Duration
+
+	// record writes the unsynced state of a NatsAuthConfig to its status
+	record func(ctx context.Context, key types.NamespacedName, reason string) error
+
+	mu       sync.Mutex
+	stopping bool
+	seq      uint64
+	pending  map[types.NamespacedName]pendingChange
+	inflight map[types.NamespacedName]context.CancelFunc
+	wg       sync.WaitGroup
+}
+
+// pendingChange is a change not yet written, numbered so a reconcile only clears the changes
+// queued before it started
+type pendingChange struct {
+	seq    uint64
+	reason string
+}
+
+var _ manager.Runnable = &shutdownFlush{}
+
+func newShutdownFlush(timeout time.Duration, record func(context.Context, types.NamespacedName, string) error) *shutdownFlush {
+	if timeout <= 0 {
+		timeout = DefaultShutdownTimeout
+	}
+	return &shutdownFlush{
+		timeout:  timeout,
+		record:   record,
+		pending:  map[types.NamespacedName]pendingChange{},
+		inflight: map[types.NamespacedName]context.CancelFunc{},
+	}
+}
+
+// queued records a change waiting in the workqueue, e.g. within the debounce window
+func (f *shutdownFlush) queued(key types.NamespacedName, reason string) {
+	if f == nil {
+		return
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.seq++
+	f.pending[key] = pendingChange{seq: f.seq, reason: reason}
+}
+
+// begin starts a reconcile of key. The returned context is not canceled when ctx is, only when
+// the shutdown timeout runs out; finish must be called with the result of the reconcile. Once
+// the operator is stopping, begin returns errShuttingDown and the change stays pending.
+func (f *shutdownFlush) begin(ctx context.Context, key types.NamespacedName) (context.Context, func(error), error) {
+	if f == nil {
+		return ctx, func(error) {}, nil
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.stopping {
+		if _, ok := f.pending[key]; !ok {
+			f.seq++
+			f.pending[key] = pendingChange{seq: f.seq, reason: "reconcile requested during shutdown"}
+		}
+		return ctx, nil, errShuttingDown
+	}
+
+	started := f.seq
+	ctx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	f.inflight[key] = cancel
+	f.wg.Add(1)
+	finish := func(err error) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		cancel()
+		delete(f.inflight, key)
+		f.wg.Done()
+		switch change, ok := f.pending[key]; {
+		case err != nil:
+			f.seq++
+			f.pending[key] = pendingChange{seq: f.seq, reason: err.Error()}
+		case ok && change.seq <= started:
+			delete(f.pending, key)
+		}
+	}
+	return ctx, finish, nil
+}
+
+// Start implements manager.Runnable. It blocks until the manager stops, then flushes.
+func (f *shutdownFlush) Start(ctx context.Context) error {
+	<-ctx.Done()
+	f.flush(log.FromContext(ctx).WithName("shutdown"))
+	return nil
+}
+
+func (f *shutdownFlush) flush(log logr.Logger) {
+	f.mu.Lock()
+	f.stopping = true
+	inflight := len(f.inflight)
+	f.mu.Unlock()
+
+	// Let in-flight writes and pushes complete, up to the timeout
+	deadline := time.Now().Add(f.timeout)
+	if inflight > 0 {
+		log.Info("Waiting for in-flight server auth config writes", "count", inflight, "timeout", f.timeout)
+		done := make(chan struct{})
+		go func() {
+			f.wg.Wait()
+			close(done)
+		}()
+		select {
+		case <-done:
+		case <-time.After(f.timeout):
+			f.mu.Lock()
+			for key, cancel := range f.inflight {
+				cancel()
+				f.seq++
+				f.pending[key] = pendingChange{seq: f.seq, reason: "write interrupted by shutdown"}
+			}
+			f.mu.Unlock()
+			log.Info("Interrupted server auth config writes still running at the shutdown timeout", "timeout", f.timeout)
+		}
+	}
+
+	// Copy the pending changes, since a reconcile interrupted above may still finish and update them
+	f.mu.Lock()
+	keys := make([]types.NamespacedName, 0, len(f.pending))
+	pending := make(map[types.NamespacedName]pendingChange, len(f.pending))
+	for key, change := range f.pending {
+		keys = append(keys, key)
+		pending[key] = change
+	}
+	f.mu.Unlock()
+	if len(keys) == 0 {
+		return
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].String() < keys[j].String() })
+
+	// Record what was left unwritten, with what remains of the timeout but at least a few seconds
+	remaining := time.Until(deadline)
+	if remaining < minStatusFlushTimeout {
+		remaining = minStatusFlushTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), remaining)
+	defer cancel()
+	for _, key := range keys {
+		if err := f.record(ctx, key, pending[key].reason); err != nil {
+			log.Error(err, "Failed to record unsynced NatsAuthConfig", "natsauthconfig", key)
+			continue
+		}
+		log.Info("Recorded unsynced NatsAuthConfig", "natsauthconfig", key, "reason", pending[key].reason)
+	}
+}
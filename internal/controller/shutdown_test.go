@@ -0,0 +1,152 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// recorder collects the unsynced NatsAuthConfigs recorded by a flush
+type recorder struct {
+	mu      sync.Mutex
+	records map[types.NamespacedName]string
+}
+
+func (r *recorder) record(_ context.Context, key types.NamespacedName, reason string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.records == nil {
+		r.records = map[types.NamespacedName]string{}
+	}
+	r.records[key] = reason
+	return nil
+}
+
+func (r *recorder) get() map[types.NamespacedName]string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	out := make(map[types.NamespacedName]string, len(r.records))
+	for k, v := range r.records {
+		out[k] = v
+	}
+	return out
+}
+
+var (
+	keyA = types.NamespacedName{Namespace: "nats", Name: "a"}
+	keyB = types.NamespacedName{Namespace: "nats", Name: "b"}
+)
+
+func TestShutdownFlushFinishClearsEarlierChanges(t *testing.T) {
+	rec := &recorder{}
+	f := newShutdownFlush(time.Second, rec.record)
+
+	f.queued(keyA, "debounced")
+	_, finish, err := f.begin(context.Background(), keyA)
+	if err != nil {
+		t.Fatalf("begin() error = %v", err)
+	}
+	// A change queued while the reconcile runs is not covered by it
+	f.queued(keyB, "debounced")
+	_, finishB, err := f.begin(context.Background(), keyB)
+	if err != nil {
+		t.Fatalf("begin() error = %v", err)
+	}
+	f.queued(keyB, "changed again")
+	finish(nil)
+	finishB(nil)
+
+	f.flush(logr.Discard())
+	got := rec.get()
+	if _, ok := got[keyA]; ok {
+		t.Errorf("flush recorded %s, whose change was written", keyA)
+	}
+	if got[keyB] != "changed again" {
+		t.Errorf("flush recorded %s with %q, want %q", keyB, got[keyB], "changed again")
+	}
+}
+
+func TestShutdownFlushRecordsFailedReconcile(t *testing.T) {
+	rec := &recorder{}
+	f := newShutdownFlush(time.Second, rec.record)
+
+	_, finish, err := f.begin(context.Background(), keyA)
+	if err != nil {
+		t.Fatalf("begin() error = %v", err)
+	}
+	finish(errors.New("apply failed"))
+
+	f.flush(logr.Discard())
+	if got := rec.get()[keyA]; got != "apply failed" {
+		t.Errorf("flush recorded %q, want %q", got, "apply failed")
+	}
+}
+
+func TestShutdownFlushRejectsReconcilesWhileStopping(t *testing.T) {
+	rec := &recorder{}
+	f := newShutdownFlush(time.Second, rec.record)
+	f.flush(logr.Discard())
+
+	if _, _, err := f.begin(context.Background(), keyA); !errors.Is(err, errShuttingDown) {
+		t.Fatalf("begin() error = %v, want %v", err, errShuttingDown)
+	}
+	f.mu.Lock()
+	_, pending := f.pending[keyA]
+	f.mu.Unlock()
+	if !pending {
+		t.Errorf("begin() during shutdown did not keep %s pending", keyA)
+	}
+}
+
+func TestShutdownFlushWaitsForInflight(t *testing.T) {
+	rec := &recorder{}
+	f := newShutdownFlush(time.Second, rec.record)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	reconcileCtx, finish, err := f.begin(ctx, keyA)
+	if err != nil {
+		t.Fatalf("begin() error = %v", err)
+	}
+	// The manager context being canceled does not cut the write off
+	cancel()
+	if reconcileCtx.Err() != nil {
+		t.Fatal("reconcile context canceled with the manager context")
+	}
+
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		finish(nil)
+	}()
+	f.flush(logr.Discard())
+	if got := rec.get(); len(got) != 0 {
+		t.Errorf("flush recorded %v, want nothing", got)
+	}
+}
+
+func TestShutdownFlushInterruptsAtTimeout(t *testing.T) {
+	rec := &recorder{}
+	f := newShutdownFlush(20*time.Millisecond, rec.record)
+
+	reconcileCtx, finish, err := f.begin(context.Background(), keyA)
+	if err != nil {
+		t.Fatalf("begin() error = %v", err)
+	}
+	// The interrupted reconcile finishes concurrently with the flush recording it
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		<-reconcileCtx.Done()
+		finish(reconcileCtx.Err())
+	}()
+
+	f.flush(logr.Discard())
+	<-done
+	if got := rec.get()[keyA]; got == "" {
+		t.Errorf("flush did not record the interrupted write of %s", keyA)
+	}
+}
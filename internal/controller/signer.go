@@ -0,0 +1,104 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/nats-io/nkeys"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	natsv1alpha1 "github.com/jradikk/nats-auth-operator/api/v1alpha1"
+	"github.com/jradikk/nats-auth-operator/internal/hooks"
+	"github.com/jradikk/nats-auth-operator/internal/signer"
+)
+
+// externalSigner returns the signer holding the operator and account keys of the NatsAuthConfig,
+// or nil when the keys are kept in Secrets
+func externalSigner(authConfig *natsv1alpha1.NatsAuthConfig) (*signer.HTTPSigner, error) {
+	if authConfig.Spec.JWT == nil || authConfig.Spec.JWT.Signer == nil {
+		return nil, nil
+	}
+	spec := authConfig.Spec.JWT.Signer
+	httpClient, err := hooks.NewHTTPClient([]byte(spec.CABundle), time.Duration(spec.TimeoutSeconds)*time.Second)
+	if err != nil {
+		return nil, permanent(natsv1alpha1.ReasonInvalidSpec, fmt.Errorf("invalid signer: %w", err))
+	}
+	return &signer.HTTPSigner{URL: spec.URL, Client: httpClient}, nil
+}
+
+// operatorSignerKey identifies the operator key of the NatsAuthConfig on the signer
+func operatorSignerKey(authConfig *natsv1alpha1.NatsAuthConfig) signer.Key {
+	return signer.Key{
+		Kind:       signer.KindOperator,
+		Namespace:  authConfig.Namespace,
+		Name:       authConfig.Name,
+		AuthConfig: client.ObjectKeyFromObject(authConfig).String(),
+	}
+}
+
+// accountSignerKey identifies the key of the NatsAccount on the signer
+func accountSignerKey(account *natsv1alpha1.NatsAccount, authConfig *natsv1alpha1.NatsAuthConfig) signer.Key {
+	return signer.Key{
+		Kind:       signer.KindAccount,
+		Namespace:  account.Namespace,
+		Name:       account.Name,
+		AuthConfig: client.ObjectKeyFromObject(authConfig).String(),
+	}
+}
+
+// signerKeyPair looks up the public key of key on the signer and returns a key pair signing with it
+func signerKeyPair(ctx context.Context, s *signer.HTTPSigner, key signer.Key) (nkeys.KeyPair, error) {
+	pubKey, err := s.PublicKey(ctx, key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %s key from the external signer: %w", key.Kind, err)
+	}
+	return s.KeyPair(ctx, key, pubKey)
+}
+
+// accountKeyPair returns the key pair of an issued NatsAccount: from the signer when the
+// NatsAuthConfig has one, otherwise from the seed in the account JWT Secret. A signer key that
+// no longer matches the account is a dependency until the account is re-issued.
+func accountKeyPair(ctx context.Context, c client.Client, account *natsv1alpha1.NatsAccount, authConfig *natsv1alpha1.NatsAuthConfig) (nkeys.KeyPair, error) {
+	s, err := externalSigner(authConfig)
+	if err != nil {
+		return nil, err
+	}
+	if s == nil {
+		seed, err := getSeed(ctx, c, account.Status.JWTSecretRef.ObjectKey(account.Namespace), nkeys.PrefixByteAccount, "")
+		if err != nil {
+			return nil, err
+		}
+		return nkeys.FromSeed(seed)
+	}
+
+	kp, err := signerKeyPair(ctx, s, accountSignerKey(account, authConfig))
+	if err != nil {
+		return nil, err
+	}
+	if pubKey, _ := kp.PublicKey(); pubKey != account.Status.AccountID {
+		return nil, &dependencyError{
+			Kind:    "NatsAccount",
+			Name:    client.ObjectKeyFromObject(account).String(),
+			Reason:  "AccountNotReady",
+			Message: fmt.Sprintf("external signer key %s has not been issued yet", pubKey),
+		}
+	}
+	return kp, nil
+}
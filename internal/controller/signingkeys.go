@@ -0,0 +1,130 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/nats-io/nkeys"
+	corev1 "k8s.io/api/core/v1"
+
+	natsv1alpha1 "github.com/jradikk/nats-auth-operator/api/v1alpha1"
+	jwtpkg "github.com/jradikk/nats-auth-operator/internal/jwt"
+	"github.com/jradikk/nats-auth-operator/internal/random"
+)
+
+// accountSigningKeys returns the signing keys of the account and their seeds by Secret key.
+// Seeds stored in the account JWT Secret are kept; roles without a valid stored seed get a new
+// one from g, reported by generated so it can be persisted before use.
+func accountSigningKeys(g random.Generator, account *natsv1alpha1.NatsAccount, stored map[string][]byte) ([]jwtpkg.SigningKey, map[string][]byte, bool, error) {
+	keys := make([]jwtpkg.SigningKey, 0, len(account.Spec.SigningKeys))
+	seeds := make(map[string][]byte, len(account.Spec.SigningKeys))
+	generated := false
+
+	for _, spec := range account.Spec.SigningKeys {
+		seedKey := jwtpkg.SigningKeySeedKey(spec.Role)
+		seed := stored[seedKey]
+		if prefix, _, err := nkeys.DecodeSeed(seed); err != nil || prefix != nkeys.PrefixByteAccount {
+			if seed, err = random.Seed(g, nkeys.PrefixByteAccount); err != nil {
+				return nil, nil, false, fmt.Errorf("failed to create signing key %q: %w", spec.Role, err)
+			}
+			generated = true
+		}
+
+		seeds[seedKey] = seed
+		keys = append(keys, jwtpkg.SigningKey{
+			Role:      spec.Role,
+			PublicKey: publicKeyFromSeed(seed),
+			Scope:     spec.Scope,
+		})
+	}
+
+	jwtpkg.SortSigningKeys(keys)
+	return keys, seeds, generated, nil
+}
+
+// signingKeyStatus maps the role of each signing key to its public key
+func signingKeyStatus(keys []jwtpkg.SigningKey) map[string]string {
+	if len(keys) == 0 {
+		return nil
+	}
+	status := make(map[string]string, len(keys))
+	for _, key := range keys {
+		status[key.Role] = key.PublicKey
+	}
+	return status
+}
+
+// userSigningKey resolves the account signing key selected by spec.signingKeyRole and returns its
+// seed and scope. A role the account does not define, or permissions set next to a scoped key,
+// are permanent errors; a key the account has not issued yet is a dependency.
+func (r *NatsUserReconciler) userSigningKey(ctx context.Context, user *natsv1alpha1.NatsUser, account *natsv1alpha1.NatsAccount) ([]byte, *natsv1alpha1.SigningKeyScope, error) {
+	role := user.Spec.SigningKeyRole
+
+	var spec *natsv1alpha1.AccountSigningKey
+	for i := range account.Spec.SigningKeys {
+		if account.Spec.SigningKeys[i].Role == role {
+			spec = &account.Spec.SigningKeys[i]
+		}
+	}
+	if spec == nil {
+		return nil, nil, permanent(natsv1alpha1.ReasonInvalidSpec,
+			fmt.Errorf("NatsAccount %s has no signing key with role %q", accountKey(user), role))
+	}
+	if spec.Scope != nil {
+		if err := scopedUserConflict(user); err != nil {
+			return nil, nil, permanent(natsv1alpha1.ReasonInvalidSpec, err)
+		}
+	}
+
+	notReady := &dependencyError{
+		Kind:    "NatsAccount",
+		Name:    accountKey(user).String(),
+		Reason:  "SigningKeyNotReady",
+		Message: fmt.Sprintf("signing key %q has not been issued yet", role),
+	}
+	pubKey := account.Status.SigningKeys[role]
+	if pubKey == "" {
+		return nil, nil, notReady
+	}
+
+	secret := &corev1.Secret{}
+	if err := r.Get(ctx, account.Status.JWTSecretRef.ObjectKey(account.Namespace), secret); err != nil {
+		return nil, nil, fmt.Errorf("failed to get account JWT secret: %w", err)
+	}
+	seed := secret.Data[jwtpkg.SigningKeySeedKey(role)]
+	if publicKeyFromSeed(seed) != pubKey {
+		return nil, nil, notReady
+	}
+	return seed, spec.Scope, nil
+}
+
+// scopedUserConflict reports user settings that a scoped signing key would override
+func scopedUserConflict(user *natsv1alpha1.NatsUser) error {
+	switch {
+	case user.Spec.Permissions != nil:
+		return fmt.Errorf("permissions cannot be set for users of scoped signing key %q; they come from the key's scope", user.Spec.SigningKeyRole)
+	case user.Spec.Limits != nil:
+		return fmt.Errorf("limits cannot be set for users of scoped signing key %q; they come from the key's scope", user.Spec.SigningKeyRole)
+	case len(user.Spec.AllowedConnectionTypes) > 0 || user.Spec.BearerToken:
+		return fmt.Errorf("allowedConnectionTypes and bearerToken cannot be set for users of scoped signing key %q", user.Spec.SigningKeyRole)
+	case user.Spec.Purpose != "" && user.Spec.Purpose != natsv1alpha1.UserPurposeClient:
+		return fmt.Errorf("%s users cannot use scoped signing key %q", user.Spec.Purpose, user.Spec.SigningKeyRole)
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// statusBaseKey keys the status base of an object, by UID, in a reconcile context
+type statusBaseKey types.UID
+
+// withStatusBase records the current state of obj in ctx as the base its status changes are
+// computed against. Call it once the object is read and its finalizer is in place.
+func withStatusBase(ctx context.Context, obj client.Object) context.Context {
+	return context.WithValue(ctx, statusBaseKey(obj.GetUID()), obj.DeepCopyObject())
+}
+
+// patchStatus writes the status changes of obj since its base as a merge patch. The patch carries
+// no resourceVersion, so it does not conflict with finalizer updates or with other writers of
+// status fields the reconcile did not touch. Without a base recorded in
+// ctx the whole status is written with an update.
+func patchStatus(ctx context.Context, c client.Client, obj client.Object) error {
+	base, ok := ctx.Value(statusBaseKey(obj.GetUID())).(client.Object)
+	if !ok {
+		return c.Status().Update(ctx, obj)
+	}
+	return c.Status().Patch(ctx, obj, client.MergeFrom(base))
+}
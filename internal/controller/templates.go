@@ -0,0 +1,75 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	natsv1alpha1 "github.com/jradikk/nats-auth-operator/api/v1alpha1"
+	"github.com/jradikk/nats-auth-operator/internal/authconf"
+)
+
+// authConfRenderer returns the renderer of the token-mode auth config: the built-in templates,
+// with the keys of the templates ConfigMap replacing them when one is set
+func (r *NatsAuthConfigReconciler) authConfRenderer(ctx context.Context, authConfig *natsv1alpha1.NatsAuthConfig) (*authconf.Renderer, error) {
+	name := authConfig.Spec.ServerAuthConfig.TemplatesConfigMap
+	if name == "" {
+		return authconf.DefaultRenderer(), nil
+	}
+
+	key := client.ObjectKey{Namespace: authConfig.Namespace, Name: name}
+	cm := &corev1.ConfigMap{}
+	if err := r.Get(ctx, key, cm); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, &dependencyError{
+				Kind:    "ConfigMap",
+				Name:    key.String(),
+				Reason:  "TemplatesNotFound",
+				Message: "templates ConfigMap not found",
+			}
+		}
+		return nil, fmt.Errorf("failed to get templates ConfigMap: %w", err)
+	}
+	renderer, err := authconf.NewRenderer(cm.Data)
+	if err != nil {
+		return nil, permanent(natsv1alpha1.ReasonInvalidSpec, fmt.Errorf("templates ConfigMap %s: %w", key, err))
+	}
+	log.FromContext(ctx).V(debugLevel).Info("Rendering with templates", "configMap", key.String(), "overrides", len(cm.Data))
+	return renderer, nil
+}
+
+// findAuthConfigForTemplates enqueues the NatsAuthConfigs rendering with the templates ConfigMap
+func (r *NatsAuthConfigReconciler) findAuthConfigForTemplates(ctx context.Context, obj client.Object) []reconcile.Request {
+	list := &natsv1alpha1.NatsAuthConfigList{}
+	if err := r.List(ctx, list, client.MatchingFields{templatesConfigMapIndex: client.ObjectKeyFromObject(obj).String()}); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to list NatsAuthConfigs for templates ConfigMap")
+		return nil
+	}
+
+	requests := make([]reconcile.Request, 0, len(list.Items))
+	for _, authConfig := range list.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&authConfig)})
+	}
+	return requests
+}
@@ -0,0 +1,147 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// DefaultAPITimeout bounds one call to the Kubernetes API server
+const DefaultAPITimeout = 30 * time.Second
+
+// WithTimeout returns a client that bounds every call with timeout, on top of the deadline of the
+// caller's context. An API server that stops answering then fails the reconcile, which is retried,
+// instead of stalling its worker. Reads served from the cache return long before the timeout.
+// A zero timeout returns c unchanged.
+func WithTimeout(c client.Client, timeout time.Duration) client.Client {
+	if timeout <= 0 {
+		return c
+	}
+	return &timeoutClient{Client: c, timeout: timeout}
+}
+
+// ReaderWithTimeout is WithTimeout for a reader such as the manager's API reader
+func ReaderWithTimeout(r client.Reader, timeout time.Duration) client.Reader {
+	if timeout <= 0 {
+		return r
+	}
+	return &timeoutReader{Reader: r, timeout: timeout}
+}
+
+type timeoutReader struct {
+	client.Reader
+	timeout time.Duration
+}
+
+func (r *timeoutReader) Get(ctx context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+	return r.Reader.Get(ctx, key, obj, opts...)
+}
+
+func (r *timeoutReader) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+	return r.Reader.List(ctx, list, opts...)
+}
+
+type timeoutClient struct {
+	client.Client
+	timeout time.Duration
+}
+
+func (c *timeoutClient) Get(ctx context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+	return c.Client.Get(ctx, key, obj, opts...)
+}
+
+func (c *timeoutClient) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+	return c.Client.List(ctx, list, opts...)
+}
+
+func (c *timeoutClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+	return c.Client.Create(ctx, obj, opts...)
+}
+
+func (c *timeoutClient) Delete(ctx context.Context, obj client.Object, opts ...client.DeleteOption) error {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+	return c.Client.Delete(ctx, obj, opts...)
+}
+
+func (c *timeoutClient) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+	return c.Client.Update(ctx, obj, opts...)
+}
+
+func (c *timeoutClient) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+	return c.Client.Patch(ctx, obj, patch, opts...)
+}
+
+func (c *timeoutClient) DeleteAllOf(ctx context.Context, obj client.Object, opts ...client.DeleteAllOfOption) error {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+	return c.Client.DeleteAllOf(ctx, obj, opts...)
+}
+
+func (c *timeoutClient) Status() client.SubResourceWriter {
+	return c.SubResource("status")
+}
+
+func (c *timeoutClient) SubResource(subResource string) client.SubResourceClient {
+	return &timeoutSubResourceClient{SubResourceClient: c.Client.SubResource(subResource), timeout: c.timeout}
+}
+
+type timeoutSubResourceClient struct {
+	client.SubResourceClient
+	timeout time.Duration
+}
+
+func (c *timeoutSubResourceClient) Get(ctx context.Context, obj, subResource client.Object, opts ...client.SubResourceGetOption) error {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+	return c.SubResourceClient.Get(ctx, obj, subResource, opts...)
+}
+
+func (c *timeoutSubResourceClient) Create(ctx context.Context, obj, subResource client.Object, opts ...client.SubResourceCreateOption) error {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+	return c.SubResourceClient.Create(ctx, obj, subResource, opts...)
+}
+
+func (c *timeoutSubResourceClient) Update(ctx context.Context, obj client.Object, opts ...client.SubResourceUpdateOption) error {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+	return c.SubResourceClient.Update(ctx, obj, opts...)
+}
+
+func (c *timeoutSubResourceClient) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.SubResourcePatchOption) error {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+	return c.SubResourceClient.Patch(ctx, obj, patch, opts...)
+}
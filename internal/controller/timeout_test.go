@@ -0,0 +1,66 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	natsv1alpha1 "github.com/jradikk/nats-auth-operator/api/v1alpha1"
+)
+
+// hang stands in for an API server that stops answering: it returns only once ctx is done
+func hang(ctx context.Context) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func TestWithTimeout(t *testing.T) {
+	const timeout = 50 * time.Millisecond
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = natsv1alpha1.AddToScheme(scheme)
+	authConfig := testAuthConfig(natsv1alpha1.AuthModeJWT)
+	hanging := fake.NewClientBuilder().WithScheme(scheme).WithObjects(authConfig).
+		WithStatusSubresource(authConfig).
+		WithInterceptorFuncs(interceptor.Funcs{
+			Get: func(ctx context.Context, _ client.WithWatch, _ client.ObjectKey, _ client.Object, _ ...client.GetOption) error {
+				return hang(ctx)
+			},
+			SubResourceUpdate: func(ctx context.Context, _ client.Client, _ string, _ client.Object, _ ...client.SubResourceUpdateOption) error {
+				return hang(ctx)
+			},
+		}).Build()
+	c := WithTimeout(hanging, timeout)
+	ctx := context.Background()
+
+	// A hanging call fails once the timeout passes, through the status writer too
+	for name, call := range map[string]func() error{
+		"Get": func() error {
+			return c.Get(ctx, client.ObjectKeyFromObject(authConfig), &natsv1alpha1.NatsAuthConfig{})
+		},
+		"Status().Update": func() error { return c.Status().Update(ctx, authConfig) },
+	} {
+		start := time.Now()
+		if err := call(); !errors.Is(err, context.DeadlineExceeded) || time.Since(start) > 10*timeout {
+			t.Errorf("%s() = %v after %v, want %v after %v", name, err, time.Since(start), context.DeadlineExceeded, timeout)
+		}
+	}
+
+	// so the reconcile fails and is retried instead of stalling its worker
+	r := &NatsAuthConfigReconciler{Client: c, Scheme: scheme}
+	if _, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(authConfig)}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Reconcile() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	if _, wrapped := WithTimeout(hanging, 0).(*timeoutClient); wrapped {
+		t.Error("WithTimeout(0) wrapped the client, want it unchanged")
+	}
+}
@@ -0,0 +1,187 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/nats-io/jwt/v2"
+	"github.com/nats-io/nkeys"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	natsv1alpha1 "github.com/jradikk/nats-auth-operator/api/v1alpha1"
+	"github.com/jradikk/nats-auth-operator/internal/audit"
+	"github.com/jradikk/nats-auth-operator/internal/exchange"
+	"github.com/jradikk/nats-auth-operator/internal/hooks"
+	jwtpkg "github.com/jradikk/nats-auth-operator/internal/jwt"
+	"github.com/jradikk/nats-auth-operator/internal/random"
+	"github.com/jradikk/nats-auth-operator/internal/secrets"
+)
+
+// DefaultTokenExchangeTTL is the lifetime of exchanged credentials when spec.tokenExchange.ttl is unset
+const DefaultTokenExchangeTTL = 15 * time.Minute
+
+//+kubebuilder:rbac:groups=authentication.k8s.io,resources=tokenreviews,verbs=create
+
+// TokenExchangeIssuer issues short-lived credentials to the ServiceAccounts a NatsUser lists in
+// spec.tokenExchange. Each exchange gets a new user key and a JWT with the claims of the user JWT
+// the operator issued, passed through the same claim hooks, signed by the same account key or
+// signing key. Nothing is written to the
+// cluster: the credentials expire on their own.
+type TokenExchangeIssuer struct {
+	Client client.Client
+	Audit  audit.Sink
+
+	// ClaimHooks mutates the exchanged user claims before they are signed, ahead of the HTTP hooks;
+	// it should be the mutator registered on the NatsUser reconciler
+	ClaimHooks hooks.Mutator
+
+	// Random generates the user keys; crypto/rand when nil
+	Random random.Generator
+}
+
+var _ exchange.Issuer = &TokenExchangeIssuer{}
+
+// Issue implements exchange.Issuer
+func (i *TokenExchangeIssuer) Issue(ctx context.Context, req exchange.Request) (*exchange.Credentials, error) {
+	user := &natsv1alpha1.NatsUser{}
+	key := client.ObjectKey{Namespace: req.ServiceAccount.Namespace, Name: req.User}
+	if err := i.Client.Get(ctx, key, user); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, fmt.Errorf("%w: NatsUser %s", exchange.ErrNotFound, key)
+		}
+		return nil, fmt.Errorf("failed to get NatsUser %s: %w", key, err)
+	}
+	spec := user.Spec.TokenExchange
+	if spec == nil || !slices.Contains(spec.ServiceAccounts, req.ServiceAccount.Name) {
+		return nil, fmt.Errorf("%w: NatsUser %s does not list ServiceAccount %s", exchange.ErrForbidden, key, req.ServiceAccount)
+	}
+	if !hasAccount(user) {
+		return nil, fmt.Errorf("%w: NatsUser %s is not a JWT user", exchange.ErrForbidden, key)
+	}
+	if user.Status.PublicKey == "" || user.Status.SecretRef.Name == "" {
+		return nil, fmt.Errorf("%w: NatsUser %s", exchange.ErrUnavailable, key)
+	}
+
+	// The issued user JWT is the template of the exchanged credentials
+	credsSecret, exists, err := secrets.Get(ctx, i.Client, user.Status.SecretRef.ObjectKey(user.Namespace))
+	if err != nil {
+		return nil, err
+	}
+	userJWT, _ := jwtpkg.ExtractCredentials(credsSecret.Data)
+	if !exists || userJWT == "" {
+		return nil, fmt.Errorf("%w: NatsUser %s", exchange.ErrUnavailable, key)
+	}
+	template, err := jwt.DecodeUserClaims(userJWT)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user JWT of NatsUser %s: %w", key, err)
+	}
+
+	account := &natsv1alpha1.NatsAccount{}
+	if err := i.Client.Get(ctx, accountKey(user), account); err != nil {
+		return nil, fmt.Errorf("failed to get NatsAccount %s: %w", accountKey(user), err)
+	}
+	authConfig := &natsv1alpha1.NatsAuthConfig{}
+	if err := i.Client.Get(ctx, authConfigKey(user), authConfig); err != nil {
+		return nil, fmt.Errorf("failed to get NatsAuthConfig %s: %w", authConfigKey(user), err)
+	}
+	signingKP, err := i.issuerKeyPair(ctx, user, account, authConfig, template.Issuer)
+	if err != nil {
+		return nil, err
+	}
+
+	ttl := DefaultTokenExchangeTTL
+	if spec.TTL != nil {
+		ttl = spec.TTL.Duration
+	}
+	userKP, err := random.OrDefault(i.Random).KeyPair(nkeys.PrefixByteUser)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create user key: %w", err)
+	}
+	userPubKey, _ := userKP.PublicKey()
+	userSeed, _ := userKP.Seed()
+	claims, err := exchange.DeriveUserClaims(template, userPubKey, time.Now(), req.Lifetime(ttl))
+	if err != nil {
+		return nil, fmt.Errorf("%w: NatsUser %s: %v", exchange.ErrUnavailable, key, err)
+	}
+
+	// Run the claim hooks the reconciler runs, but never let them extend the lifetime
+	expires := claims.Expires
+	chain, err := claimHooks(i.ClaimHooks, authConfig)
+	if err != nil {
+		return nil, err
+	}
+	if err := chain.MutateUserClaims(ctx, hookRequest(hooks.KindUser, user, authConfig), claims); err != nil {
+		if errors.Is(err, hooks.ErrRejected) {
+			return nil, fmt.Errorf("%w: %v", exchange.ErrForbidden, err)
+		}
+		return nil, fmt.Errorf("failed to apply claim hooks: %w", err)
+	}
+	if claims.Expires == 0 || claims.Expires > expires {
+		claims.Expires = expires
+	}
+
+	token, err := claims.Encode(signingKP)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign user JWT: %w", err)
+	}
+
+	recordAudit(ctx, i.Audit, "NatsUser", user, audit.Event{
+		Action:     audit.ActionExchanged,
+		Credential: audit.CredentialUserJWT,
+		PublicKey:  userPubKey,
+		Issuer:     claims.Issuer,
+		ExpiresAt:  auditExpiry(claims.Expires),
+	})
+
+	return &exchange.Credentials{
+		Creds:     jwtpkg.GenerateCredsFile(token, userSeed),
+		PublicKey: userPubKey,
+		URL:       authConfig.Spec.NatsURL,
+		ExpiresAt: time.Unix(claims.Expires, 0).UTC(),
+	}, nil
+}
+
+// issuerKeyPair returns the key pair behind the issuer of the user JWT: the account key, or one of
+// the signing keys of the account
+func (i *TokenExchangeIssuer) issuerKeyPair(ctx context.Context, user *natsv1alpha1.NatsUser, account *natsv1alpha1.NatsAccount, authConfig *natsv1alpha1.NatsAuthConfig, issuer string) (nkeys.KeyPair, error) {
+	if issuer == account.Status.AccountID {
+		return accountKeyPair(ctx, i.Client, account, authConfig)
+	}
+
+	for role, pubKey := range account.Status.SigningKeys {
+		if pubKey != issuer {
+			continue
+		}
+		secret, _, err := secrets.Get(ctx, i.Client, account.Status.JWTSecretRef.ObjectKey(account.Namespace))
+		if err != nil {
+			return nil, err
+		}
+		seed := secret.Data[jwtpkg.SigningKeySeedKey(role)]
+		if publicKeyFromSeed(seed) != issuer {
+			break
+		}
+		return nkeys.FromSeed(seed)
+	}
+	return nil, fmt.Errorf("%w: the user JWT of NatsUser %s is signed by %s, which the operator does not hold",
+		exchange.ErrForbidden, client.ObjectKeyFromObject(user), issuer)
+}
@@ -0,0 +1,213 @@
+// Package csiprovider is a provider of the Secrets Store CSI driver serving NATS credentials to
+// pods at mount time. A SecretProviderClass names the NatsUser; the provider exchanges the
+// projected ServiceAccount token of the pod for short-lived credentials of the user at the token
+// exchange endpoint of the operator, and the driver writes them to the volume. The credentials
+// never pass through a Kubernetes Secret, and the provider needs no access to the API.
+//
+//	apiVersion: secrets-store.csi.x-k8s.io/v1
+//	kind: SecretProviderClass
+//	metadata:
+//	  name: orders-nats
+//	spec:
+//	  provider: nats-auth-operator
+//	  parameters:
+//	    user: orders
+package csiprovider
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/secrets-store-csi-driver/provider/v1alpha1"
+
+	"github.com/jradikk/nats-auth-operator/internal/exchange"
+)
+
+// Name is the provider name of SecretProviderClasses served by this provider
+const Name = "nats-auth-operator"
+
+// DefaultSocket is where the driver looks for the socket of the provider
+const DefaultSocket = "/var/run/secrets-store-csi-providers/" + Name + ".sock"
+
+// Attributes the driver adds to the SecretProviderClass parameters
+const (
+	podNamespaceAttribute   = "csi.storage.k8s.io/pod.namespace"
+	podNameAttribute        = "csi.storage.k8s.io/pod.name"
+	serviceAccountAttribute = "csi.storage.k8s.io/serviceAccount.name"
+	// tokensAttribute holds the ServiceAccount tokens requested by the tokenRequests of the
+	// CSIDriver, by audience
+	tokensAttribute = "csi.storage.k8s.io/serviceAccount.tokens"
+)
+
+// Parameters of a SecretProviderClass
+const (
+	// userParameter names the NatsUser in the namespace of the pod (required)
+	userParameter = "user"
+	// audienceParameter selects the token the credentials are exchanged with (defaults to
+	// exchange.DefaultAudience)
+	audienceParameter = "audience"
+	// ttlParameter requests a lifetime shorter than the user allows, e.g. "10m"
+	ttlParameter = "ttl"
+	// fileNameParameter names the creds file in the volume (defaults to DefaultFileName)
+	fileNameParameter = "fileName"
+)
+
+// DefaultFileName is the name of the creds file in the volume
+const DefaultFileName = "user.creds"
+
+// defaultMode is the mode of the creds file when the volume sets none. The file holds the user
+// seed, so it is readable by the owner only.
+const defaultMode = 0o600
+
+// Provider answers the Mount calls of the driver
+type Provider struct {
+	v1alpha1.UnimplementedCSIDriverProviderServer
+
+	// Exchange requests the credentials from the operator
+	Exchange *exchange.Client
+}
+
+var _ v1alpha1.CSIDriverProviderServer = &Provider{}
+
+// serviceAccountToken is a token of the tokensAttribute
+type serviceAccountToken struct {
+	Token string `json:"token"`
+}
+
+// Version implements v1alpha1.CSIDriverProviderServer
+func (p *Provider) Version(context.Context, *v1alpha1.VersionRequest) (*v1alpha1.VersionResponse, error) {
+	resp := &v1alpha1.VersionResponse{Version: "v1alpha1", RuntimeName: Name, RuntimeVersion: "devel"}
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" {
+		resp.RuntimeVersion = info.Main.Version
+	}
+	return resp, nil
+}
+
+// Mount implements v1alpha1.CSIDriverProviderServer. It exchanges the ServiceAccount token of the
+// pod for credentials of the user of the SecretProviderClass. Every call issues new credentials,
+// so rotation by the driver renews them.
+func (p *Provider) Mount(ctx context.Context, req *v1alpha1.MountRequest) (*v1alpha1.MountResponse, error) {
+	var attributes map[string]string
+	if err := json.Unmarshal([]byte(req.GetAttributes()), &attributes); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid attributes: %v", err)
+	}
+	user := attributes[userParameter]
+	if user == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "parameter %q is required", userParameter)
+	}
+	fileName := attributes[fileNameParameter]
+	if fileName == "" {
+		fileName = DefaultFileName
+	}
+	if filepath.Base(fileName) != fileName || fileName == "." || fileName == ".." {
+		return nil, status.Errorf(codes.InvalidArgument, "parameter %q must be a file name, got %q", fileNameParameter, fileName)
+	}
+	var ttl time.Duration
+	if value := attributes[ttlParameter]; value != "" {
+		parsed, err := time.ParseDuration(value)
+		if err != nil || parsed <= 0 {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid parameter %q: %q", ttlParameter, value)
+		}
+		ttl = parsed
+	}
+	mode := int32(defaultMode)
+	if req.GetPermission() != "" {
+		if err := json.Unmarshal([]byte(req.GetPermission()), &mode); err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid permission %q", req.GetPermission())
+		}
+		// The seed is never readable by others, whatever the filePermission of the driver
+		mode &^= 0o007
+	}
+
+	audience := attributes[audienceParameter]
+	if audience == "" {
+		audience = exchange.DefaultAudience
+	}
+	var tokens map[string]serviceAccountToken
+	if raw := attributes[tokensAttribute]; raw != "" {
+		if err := json.Unmarshal([]byte(raw), &tokens); err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid ServiceAccount tokens: %v", err)
+		}
+	}
+	token := tokens[audience].Token
+	if token == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "no ServiceAccount token for audience %q; add it to the tokenRequests of the CSIDriver secrets-store.csi.k8s.io", audience)
+	}
+
+	log := log.FromContext(ctx).WithValues(
+		"pod", attributes[podNamespaceAttribute]+"/"+attributes[podNameAttribute],
+		"serviceAccount", attributes[serviceAccountAttribute],
+		"user", user)
+	creds, err := p.Exchange.Exchange(ctx, token, user, ttl)
+	if err != nil {
+		log.Error(err, "Failed to exchange the ServiceAccount token")
+		return nil, status.Error(codes.Unavailable, err.Error())
+	}
+	log.Info("Mounted credentials", "publicKey", creds.PublicKey, "expiresAt", creds.ExpiresAt)
+
+	return &v1alpha1.MountResponse{
+		ObjectVersion: []*v1alpha1.ObjectVersion{{Id: "nats/" + user, Version: creds.PublicKey}},
+		Files:         []*v1alpha1.File{{Path: fileName, Mode: mode, Contents: []byte(creds.Creds)}},
+	}, nil
+}
+
+// shutdownTimeout bounds the wait for calls in flight when the server stops
+const shutdownTimeout = 5 * time.Second
+
+// Server serves the provider on the Unix socket the driver connects to
+type Server struct {
+	// Socket is the path of the socket, e.g. DefaultSocket
+	Socket string
+
+	Provider *Provider
+}
+
+// Start serves the provider until ctx is done
+func (s *Server) Start(ctx context.Context) error {
+	// A socket left behind by a previous run would fail the listen
+	if err := os.Remove(s.Socket); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to remove stale socket: %w", err)
+	}
+	listener, err := net.Listen("unix", s.Socket)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", s.Socket, err)
+	}
+
+	srv := grpc.NewServer(grpc.UnaryInterceptor(func(callCtx context.Context, req interface{}, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		// Calls log through the logger of ctx
+		return handler(log.IntoContext(callCtx, log.FromContext(ctx)), req)
+	}))
+	v1alpha1.RegisterCSIDriverProviderServer(srv, s.Provider)
+	errs := make(chan error, 1)
+	go func() {
+		errs <- srv.Serve(listener)
+	}()
+
+	select {
+	case err := <-errs:
+		return err
+	case <-ctx.Done():
+	}
+	stopped := make(chan struct{})
+	go func() {
+		srv.GracefulStop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(shutdownTimeout):
+		srv.Stop()
+	}
+	return nil
+}
@@ -0,0 +1,153 @@
+package csiprovider
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"sigs.k8s.io/secrets-store-csi-driver/provider/v1alpha1"
+
+	"github.com/jradikk/nats-auth-operator/internal/exchange"
+)
+
+// fakeExchange answers the token exchange for the token "valid" with the ttl it was asked for
+func fakeExchange(t *testing.T) *exchange.Client {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct{ User, TTL string }
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if r.Header.Get("Authorization") != "Bearer valid" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"message":"token is not a valid ServiceAccount token"}`))
+			return
+		}
+		_ = json.NewEncoder(w).Encode(exchange.Credentials{Creds: "creds of " + body.User + " for " + body.TTL, PublicKey: "UORDERS"})
+	}))
+	t.Cleanup(srv.Close)
+	return &exchange.Client{URL: srv.URL}
+}
+
+// attributes returns the JSON attributes of a mount with the given parameters and a token for
+// the default audience
+func attributes(token string, params map[string]string) string {
+	tokens, _ := json.Marshal(map[string]serviceAccountToken{exchange.DefaultAudience: {Token: token}})
+	attrs := map[string]string{
+		podNamespaceAttribute:   "apps",
+		podNameAttribute:        "orders-0",
+		serviceAccountAttribute: "orders",
+		tokensAttribute:         string(tokens),
+	}
+	for k, v := range params {
+		attrs[k] = v
+	}
+	data, _ := json.Marshal(attrs)
+	return string(data)
+}
+
+func TestProviderMount(t *testing.T) {
+	p := &Provider{Exchange: fakeExchange(t)}
+
+	tests := []struct {
+		name      string
+		req       *v1alpha1.MountRequest
+		wantFile  *v1alpha1.File
+		wantCode  codes.Code
+		wantError string
+	}{
+		{
+			name:     "Default file",
+			req:      &v1alpha1.MountRequest{Attributes: attributes("valid", map[string]string{"user": "orders"})},
+			wantFile: &v1alpha1.File{Path: DefaultFileName, Mode: 0o600, Contents: []byte("creds of orders for ")},
+		},
+		{
+			name:     "Named file and lifetime",
+			req:      &v1alpha1.MountRequest{Attributes: attributes("valid", map[string]string{"user": "orders", "fileName": "nats.creds", "ttl": "10m"}), Permission: "256"},
+			wantFile: &v1alpha1.File{Path: "nats.creds", Mode: 0o400, Contents: []byte("creds of orders for 10m0s")},
+		},
+		{
+			name:     "World-readable permission",
+			req:      &v1alpha1.MountRequest{Attributes: attributes("valid", map[string]string{"user": "orders"}), Permission: "420"},
+			wantFile: &v1alpha1.File{Path: DefaultFileName, Mode: 0o640, Contents: []byte("creds of orders for ")},
+		},
+		{name: "Missing user", req: &v1alpha1.MountRequest{Attributes: attributes("valid", nil)}, wantCode: codes.InvalidArgument, wantError: `parameter "user" is required`},
+		{name: "File outside the volume", req: &v1alpha1.MountRequest{Attributes: attributes("valid", map[string]string{"user": "orders", "fileName": "../x"})}, wantCode: codes.InvalidArgument, wantError: "must be a file name"},
+		{name: "Invalid lifetime", req: &v1alpha1.MountRequest{Attributes: attributes("valid", map[string]string{"user": "orders", "ttl": "soon"})}, wantCode: codes.InvalidArgument, wantError: `invalid parameter "ttl"`},
+		{name: "Token of another audience", req: &v1alpha1.MountRequest{Attributes: attributes("valid", map[string]string{"user": "orders", "audience": "vault"})}, wantCode: codes.InvalidArgument, wantError: `no ServiceAccount token for audience "vault"`},
+		{name: "Rejected token", req: &v1alpha1.MountRequest{Attributes: attributes("forged", map[string]string{"user": "orders"})}, wantCode: codes.Unavailable, wantError: "401"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := p.Mount(context.Background(), tt.req)
+			if tt.wantError != "" {
+				if status.Code(err) != tt.wantCode || !strings.Contains(err.Error(), tt.wantError) {
+					t.Fatalf("Mount() error = %v, want %s %q", err, tt.wantCode, tt.wantError)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Mount() error = %v", err)
+			}
+			checkMount(t, resp, tt.wantFile)
+		})
+	}
+}
+
+// checkMount fails unless resp holds the one file want and the version of the user orders
+func checkMount(t *testing.T, resp *v1alpha1.MountResponse, want *v1alpha1.File) {
+	t.Helper()
+	files := resp.GetFiles()
+	if len(files) != 1 || files[0].GetPath() != want.Path || files[0].GetMode() != want.Mode || string(files[0].GetContents()) != string(want.Contents) {
+		t.Errorf("Mount() files = %v, want %v", files, want)
+	}
+	versions := resp.GetObjectVersion()
+	if len(versions) != 1 || versions[0].GetId() != "nats/orders" || versions[0].GetVersion() != "UORDERS" {
+		t.Errorf("Mount() object versions = %v", versions)
+	}
+}
+
+func TestServer(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	socket := filepath.Join(t.TempDir(), "provider.sock")
+	srv := &Server{Socket: socket, Provider: &Provider{Exchange: fakeExchange(t)}}
+	done := make(chan error, 1)
+	go func() {
+		done <- srv.Start(ctx)
+	}()
+
+	// The driver dials the socket like this
+	conn, err := grpc.Dial("unix://"+socket, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	c := v1alpha1.NewCSIDriverProviderClient(conn)
+
+	version, err := c.Version(ctx, &v1alpha1.VersionRequest{Version: "v1alpha1"}, grpc.WaitForReady(true))
+	if err != nil || version.GetRuntimeName() != Name {
+		t.Errorf("Version() = %v, %v", version, err)
+	}
+
+	resp, err := c.Mount(ctx, &v1alpha1.MountRequest{Attributes: attributes("valid", map[string]string{"user": "orders"}), Permission: "420"})
+	if err != nil {
+		t.Fatalf("Mount() error = %v", err)
+	}
+	checkMount(t, resp, &v1alpha1.File{Path: DefaultFileName, Mode: 0o640, Contents: []byte("creds of orders for ")})
+
+	if _, err := c.Mount(ctx, &v1alpha1.MountRequest{}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Mount() of an empty request error = %v, want InvalidArgument", err)
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("Start() error = %v", err)
+	}
+}
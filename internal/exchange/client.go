@@ -0,0 +1,67 @@
+package exchange
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// maxResponseSize bounds the response body; a creds file is a few KiB
+const maxResponseSize = 1 << 16
+
+// Client requests credentials from the token exchange endpoint on behalf of a ServiceAccount
+type Client struct {
+	// URL of the endpoint without Path, e.g. https://nats-auth-operator-token-exchange.nats-system:8444
+	URL string
+
+	// HTTPClient sends the requests; http.DefaultClient when nil
+	HTTPClient *http.Client
+}
+
+// Exchange trades the ServiceAccount token for credentials of the user in the namespace of the
+// ServiceAccount. A ttl of 0 leaves the lifetime to the user.
+func (c *Client) Exchange(ctx context.Context, token, user string, ttl time.Duration) (*Credentials, error) {
+	body := requestBody{User: user}
+	if ttl > 0 {
+		body.TTL = ttl.String()
+	}
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimSuffix(c.URL, "/")+Path, bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Content-Type", "application/json")
+
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("token exchange failed: %w", err)
+	}
+	defer resp.Body.Close()
+	decoder := json.NewDecoder(io.LimitReader(resp.Body, maxResponseSize))
+
+	if resp.StatusCode != http.StatusOK {
+		var failure errorBody
+		if err := decoder.Decode(&failure); err != nil || failure.Message == "" {
+			failure.Message = http.StatusText(resp.StatusCode)
+		}
+		return nil, fmt.Errorf("token exchange answered %d: %s", resp.StatusCode, failure.Message)
+	}
+	var creds Credentials
+	if err := decoder.Decode(&creds); err != nil {
+		return nil, fmt.Errorf("invalid token exchange answer: %w", err)
+	}
+	return &creds, nil
+}
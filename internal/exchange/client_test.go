@@ -0,0 +1,39 @@
+package exchange
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestClient(t *testing.T) {
+	issuer := &fakeIssuer{}
+	srv := httptest.NewServer(&Handler{Reviewer: fakeReviewer{token: "valid", sa: ServiceAccount{Namespace: "apps", Name: "orders"}}, Issuer: issuer})
+	defer srv.Close()
+	c := &Client{URL: srv.URL + "/", HTTPClient: srv.Client()}
+
+	creds, err := c.Exchange(context.Background(), "valid", "orders", 5*time.Minute)
+	if err != nil {
+		t.Fatalf("Exchange() error = %v", err)
+	}
+	if creds.Creds != "creds" || creds.PublicKey == "" {
+		t.Errorf("Exchange() = %+v, want the issued credentials", creds)
+	}
+	if issuer.got.User != "orders" || issuer.got.TTL != 5*time.Minute {
+		t.Errorf("Exchange() requested %+v, want orders for 5m", issuer.got)
+	}
+
+	for _, tt := range []struct {
+		token, user, want string
+	}{
+		{token: "forged", user: "orders", want: "401"},
+		{token: "valid", user: "billing", want: "403: " + ErrForbidden.Error()},
+		{token: "valid", user: "missing", want: "404"},
+	} {
+		if _, err := c.Exchange(context.Background(), tt.token, tt.user, 0); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("Exchange(%s, %s) error = %v, want %q", tt.token, tt.user, err, tt.want)
+		}
+	}
+}
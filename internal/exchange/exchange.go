@@ -0,0 +1,282 @@
+package exchange
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/nats-io/jwt/v2"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// Path the credentials are requested at with POST
+const Path = "/v1/credentials"
+
+// DefaultAudience is the audience projected ServiceAccount tokens must be issued for
+const DefaultAudience = "nats-auth-operator"
+
+// serviceAccountPrefix starts the username of a ServiceAccount token
+const serviceAccountPrefix = "system:serviceaccount:"
+
+// maxRequestSize bounds the request body
+const maxRequestSize = 1 << 12
+
+// Errors of an exchange, each answered with its own HTTP status
+var (
+	// ErrUnauthenticated is a token that is not a valid ServiceAccount token
+	ErrUnauthenticated = errors.New("token is not a valid ServiceAccount token")
+	// ErrForbidden is a ServiceAccount the user does not allow to exchange tokens
+	ErrForbidden = errors.New("service account may not exchange tokens for this user")
+	// ErrNotFound is a user that does not exist in the namespace of the ServiceAccount
+	ErrNotFound = errors.New("user not found")
+	// ErrUnavailable is a user whose credentials have not been issued yet
+	ErrUnavailable = errors.New("user credentials have not been issued yet")
+)
+
+// ServiceAccount identifies the ServiceAccount a token was issued to
+type ServiceAccount struct {
+	Namespace string
+	Name      string
+}
+
+// String returns namespace/name
+func (sa ServiceAccount) String() string {
+	return sa.Namespace + "/" + sa.Name
+}
+
+// ParseServiceAccount parses the username of a ServiceAccount token,
+// system:serviceaccount:<namespace>:<name>
+func ParseServiceAccount(username string) (ServiceAccount, error) {
+	rest, ok := strings.CutPrefix(username, serviceAccountPrefix)
+	if !ok {
+		return ServiceAccount{}, fmt.Errorf("%q is not a ServiceAccount", username)
+	}
+	namespace, name, ok := strings.Cut(rest, ":")
+	if !ok || namespace == "" || name == "" || strings.Contains(name, ":") {
+		return ServiceAccount{}, fmt.Errorf("%q is not a ServiceAccount", username)
+	}
+	return ServiceAccount{Namespace: namespace, Name: name}, nil
+}
+
+// Request asks for credentials of a user on behalf of a ServiceAccount
+type Request struct {
+	ServiceAccount ServiceAccount
+
+	// User is the name of the NatsUser in the namespace of the ServiceAccount
+	User string
+
+	// TTL is the requested lifetime; 0 leaves it to the user
+	TTL time.Duration
+
+	// MaxTTL caps the lifetime whatever the user allows
+	MaxTTL time.Duration
+}
+
+// Lifetime returns the lifetime of the credentials: the lifetime the user allows, shortened to
+// the requested TTL and to MaxTTL
+func (r Request) Lifetime(allowed time.Duration) time.Duration {
+	lifetime := allowed
+	if r.TTL > 0 && r.TTL < lifetime {
+		lifetime = r.TTL
+	}
+	if r.MaxTTL > 0 && r.MaxTTL < lifetime {
+		lifetime = r.MaxTTL
+	}
+	return lifetime
+}
+
+// Credentials are the short-lived credentials returned to the caller
+type Credentials struct {
+	// Creds is a NATS creds file holding the user JWT and seed
+	Creds string `json:"creds"`
+
+	// PublicKey of the issued user
+	PublicKey string `json:"publicKey"`
+
+	// URL of the NATS server, when the NatsAuthConfig names one
+	URL string `json:"url,omitempty"`
+
+	// ExpiresAt is the expiry of the user JWT
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// Reviewer verifies a token and returns the ServiceAccount it was issued to
+type Reviewer interface {
+	Review(ctx context.Context, token string) (ServiceAccount, error)
+}
+
+// Issuer issues credentials for a request of an authenticated ServiceAccount
+type Issuer interface {
+	Issue(ctx context.Context, req Request) (*Credentials, error)
+}
+
+// TokenReviewer verifies tokens with the TokenReview API of the Kubernetes API server
+type TokenReviewer struct {
+	Client client.Client
+
+	// Audiences the token must be issued for; the API server audience when empty
+	Audiences []string
+}
+
+// Review creates a TokenReview for the token
+func (r *TokenReviewer) Review(ctx context.Context, token string) (ServiceAccount, error) {
+	review := &authenticationv1.TokenReview{
+		Spec: authenticationv1.TokenReviewSpec{Token: token, Audiences: r.Audiences},
+	}
+	if err := r.Client.Create(ctx, review); err != nil {
+		return ServiceAccount{}, fmt.Errorf("failed to review token: %w", err)
+	}
+	if !review.Status.Authenticated {
+		if review.Status.Error != "" {
+			return ServiceAccount{}, fmt.Errorf("%w: %s", ErrUnauthenticated, review.Status.Error)
+		}
+		return ServiceAccount{}, ErrUnauthenticated
+	}
+	sa, err := ParseServiceAccount(review.Status.User.Username)
+	if err != nil {
+		return ServiceAccount{}, fmt.Errorf("%w: %v", ErrUnauthenticated, err)
+	}
+	return sa, nil
+}
+
+// DeriveUserClaims returns claims for the user key publicKey carrying the permissions, limits,
+// audience and issuer account of template. They expire after ttl, and never after template does, and are not
+// valid before template is.
+func DeriveUserClaims(template *jwt.UserClaims, publicKey string, now time.Time, ttl time.Duration) (*jwt.UserClaims, error) {
+	if ttl <= 0 {
+		return nil, fmt.Errorf("lifetime must be positive, got %s", ttl)
+	}
+	expires := now.Add(ttl).Unix()
+	if template.Expires != 0 && template.Expires < expires {
+		expires = template.Expires
+	}
+	if expires <= now.Unix() {
+		return nil, fmt.Errorf("user JWT expired at %s", time.Unix(template.Expires, 0).UTC().Format(time.RFC3339))
+	}
+
+	claims := jwt.NewUserClaims(publicKey)
+	claims.Name = template.Name
+	claims.User = template.User
+	claims.Tags = append(jwt.TagList(nil), template.Tags...)
+	claims.Audience = template.Audience
+	claims.IssuedAt = now.Unix()
+	claims.Expires = expires
+	claims.NotBefore = template.NotBefore
+	return claims, nil
+}
+
+// Handler answers credential requests: a POST to Path with the ServiceAccount token as bearer
+// token and a JSON body naming the user, {"user": "orders", "ttl": "10m"}
+type Handler struct {
+	Reviewer Reviewer
+	Issuer   Issuer
+
+	// MaxTTL caps the lifetime of the issued credentials; uncapped when 0
+	MaxTTL time.Duration
+}
+
+// requestBody is the JSON body of a credential request
+type requestBody struct {
+	User string `json:"user"`
+	TTL  string `json:"ttl,omitempty"`
+}
+
+// errorBody is the JSON body of a failed request
+type errorBody struct {
+	Message string `json:"message"`
+}
+
+// ServeHTTP implements http.Handler
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != Path {
+		writeError(w, http.StatusNotFound, "not found")
+		return
+	}
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || token == "" {
+		writeError(w, http.StatusUnauthorized, "a ServiceAccount token is required as bearer token")
+		return
+	}
+
+	var body requestBody
+	if err := json.NewDecoder(io.LimitReader(r.Body, maxRequestSize)).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid request: %v", err))
+		return
+	}
+	if body.User == "" {
+		writeError(w, http.StatusBadRequest, "user is required")
+		return
+	}
+	req := Request{User: body.User, MaxTTL: h.MaxTTL}
+	if body.TTL != "" {
+		ttl, err := time.ParseDuration(body.TTL)
+		if err != nil || ttl <= 0 {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid ttl %q", body.TTL))
+			return
+		}
+		req.TTL = ttl
+	}
+
+	sa, err := h.Reviewer.Review(r.Context(), token)
+	if err != nil {
+		writeFailure(w, r, err)
+		return
+	}
+	req.ServiceAccount = sa
+
+	creds, err := h.Issuer.Issue(r.Context(), req)
+	if err != nil {
+		writeFailure(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	_ = json.NewEncoder(w).Encode(creds)
+}
+
+// statusOf maps an exchange error to its HTTP status
+func statusOf(err error) int {
+	switch {
+	case errors.Is(err, ErrUnauthenticated):
+		return http.StatusUnauthorized
+	case errors.Is(err, ErrForbidden):
+		return http.StatusForbidden
+	case errors.Is(err, ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, ErrUnavailable):
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// writeFailure answers a failed review or exchange. Unexpected errors are logged and answered
+// with a generic message, so internal details do not reach the caller.
+func writeFailure(w http.ResponseWriter, r *http.Request, err error) {
+	status := statusOf(err)
+	if status != http.StatusInternalServerError {
+		writeError(w, status, err.Error())
+		return
+	}
+	log.FromContext(r.Context()).WithName("token-exchange").Error(err, "Failed to exchange token", "remoteAddr", r.RemoteAddr)
+	writeError(w, status, "internal error")
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(errorBody{Message: message})
+}
@@ -0,0 +1,214 @@
+package exchange
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nats-io/jwt/v2"
+	"github.com/nats-io/nkeys"
+)
+
+func TestParseServiceAccount(t *testing.T) {
+	tests := []struct {
+		name     string
+		username string
+		want     ServiceAccount
+		wantErr  bool
+	}{
+		{name: "ServiceAccount", username: "system:serviceaccount:apps:orders", want: ServiceAccount{Namespace: "apps", Name: "orders"}},
+		{name: "Human user", username: "jane@example.com", wantErr: true},
+		{name: "Missing name", username: "system:serviceaccount:apps", wantErr: true},
+		{name: "Empty namespace", username: "system:serviceaccount::orders", wantErr: true},
+		{name: "Extra segment", username: "system:serviceaccount:apps:orders:x", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseServiceAccount(tt.username)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseServiceAccount() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseServiceAccount() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRequestLifetime(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     Request
+		allowed time.Duration
+		want    time.Duration
+	}{
+		{name: "Allowed by the user", req: Request{}, allowed: 15 * time.Minute, want: 15 * time.Minute},
+		{name: "Shorter request", req: Request{TTL: 5 * time.Minute}, allowed: 15 * time.Minute, want: 5 * time.Minute},
+		{name: "Longer request", req: Request{TTL: time.Hour}, allowed: 15 * time.Minute, want: 15 * time.Minute},
+		{name: "Capped by the operator", req: Request{TTL: time.Hour, MaxTTL: 10 * time.Minute}, allowed: 2 * time.Hour, want: 10 * time.Minute},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.req.Lifetime(tt.allowed); got != tt.want {
+				t.Errorf("Lifetime() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDeriveUserClaims(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	template := jwt.NewUserClaims("UTEMPLATE")
+	template.Name = "orders"
+	template.Pub.Allow.Add("orders.>")
+	template.Limits.Subs = 10
+	template.IssuerAccount = "AACCOUNT"
+	template.Tags.Add("team:orders")
+	template.Audience = "orders-service"
+
+	tests := []struct {
+		name        string
+		expires     int64
+		ttl         time.Duration
+		wantExpires int64
+		wantErr     bool
+	}{
+		{name: "Template without expiry", ttl: 15 * time.Minute, wantExpires: now.Add(15 * time.Minute).Unix()},
+		{name: "Template outlives the lifetime", expires: now.Add(time.Hour).Unix(), ttl: 15 * time.Minute, wantExpires: now.Add(15 * time.Minute).Unix()},
+		{name: "Capped by the template", expires: now.Add(5 * time.Minute).Unix(), ttl: 15 * time.Minute, wantExpires: now.Add(5 * time.Minute).Unix()},
+		{name: "Expired template", expires: now.Add(-time.Minute).Unix(), ttl: 15 * time.Minute, wantErr: true},
+		{name: "No lifetime", ttl: 0, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			template.Expires = tt.expires
+			got, err := DeriveUserClaims(template, "UEXCHANGED", now, tt.ttl)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("DeriveUserClaims() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if got.Subject != "UEXCHANGED" || got.Name != "orders" {
+				t.Errorf("subject, name = %s, %s", got.Subject, got.Name)
+			}
+			if got.Expires != tt.wantExpires || got.IssuedAt != now.Unix() {
+				t.Errorf("expires, issued = %d, %d, want %d, %d", got.Expires, got.IssuedAt, tt.wantExpires, now.Unix())
+			}
+			if !got.Pub.Allow.Contains("orders.>") || got.Limits.Subs != 10 || got.IssuerAccount != "AACCOUNT" {
+				t.Errorf("claims not copied from the template: %+v", got.User)
+			}
+			if !got.Tags.Contains("team:orders") {
+				t.Errorf("tags = %v", got.Tags)
+			}
+			if got.Audience != "orders-service" {
+				t.Errorf("audience = %q, want the audience of the template", got.Audience)
+			}
+		})
+	}
+}
+
+// fakeReviewer accepts one token for one ServiceAccount
+type fakeReviewer struct {
+	token string
+	sa    ServiceAccount
+}
+
+func (r fakeReviewer) Review(_ context.Context, token string) (ServiceAccount, error) {
+	if token != r.token {
+		return ServiceAccount{}, ErrUnauthenticated
+	}
+	return r.sa, nil
+}
+
+// fakeIssuer issues credentials for users allowing the orders ServiceAccount
+type fakeIssuer struct {
+	got Request
+}
+
+func (i *fakeIssuer) Issue(_ context.Context, req Request) (*Credentials, error) {
+	i.got = req
+	switch req.User {
+	case "missing":
+		return nil, ErrNotFound
+	case "pending":
+		return nil, ErrUnavailable
+	case "billing":
+		return nil, ErrForbidden
+	case "broken":
+		return nil, errors.New("failed to get Secret apps/broken: connection refused")
+	}
+	user, _ := nkeys.CreateUser()
+	pub, _ := user.PublicKey()
+	return &Credentials{Creds: "creds", PublicKey: pub}, nil
+}
+
+func TestHandler(t *testing.T) {
+	orders := ServiceAccount{Namespace: "apps", Name: "orders"}
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		token      string
+		body       string
+		wantStatus int
+		wantTTL    time.Duration
+	}{
+		{name: "Exchange", method: http.MethodPost, path: Path, token: "valid", body: `{"user":"orders"}`, wantStatus: http.StatusOK},
+		{name: "Requested lifetime", method: http.MethodPost, path: Path, token: "valid", body: `{"user":"orders","ttl":"5m"}`, wantStatus: http.StatusOK, wantTTL: 5 * time.Minute},
+		{name: "Missing token", method: http.MethodPost, path: Path, body: `{"user":"orders"}`, wantStatus: http.StatusUnauthorized},
+		{name: "Invalid token", method: http.MethodPost, path: Path, token: "forged", body: `{"user":"orders"}`, wantStatus: http.StatusUnauthorized},
+		{name: "Missing user", method: http.MethodPost, path: Path, token: "valid", body: `{}`, wantStatus: http.StatusBadRequest},
+		{name: "Invalid lifetime", method: http.MethodPost, path: Path, token: "valid", body: `{"user":"orders","ttl":"-1m"}`, wantStatus: http.StatusBadRequest},
+		{name: "Not allowed", method: http.MethodPost, path: Path, token: "valid", body: `{"user":"billing"}`, wantStatus: http.StatusForbidden},
+		{name: "Unknown user", method: http.MethodPost, path: Path, token: "valid", body: `{"user":"missing"}`, wantStatus: http.StatusNotFound},
+		{name: "Not issued yet", method: http.MethodPost, path: Path, token: "valid", body: `{"user":"pending"}`, wantStatus: http.StatusServiceUnavailable},
+		{name: "Internal error", method: http.MethodPost, path: Path, token: "valid", body: `{"user":"broken"}`, wantStatus: http.StatusInternalServerError},
+		{name: "Wrong method", method: http.MethodGet, path: Path, token: "valid", wantStatus: http.StatusMethodNotAllowed},
+		{name: "Wrong path", method: http.MethodPost, path: "/", token: "valid", wantStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			issuer := &fakeIssuer{}
+			h := &Handler{Reviewer: fakeReviewer{token: "valid", sa: orders}, Issuer: issuer, MaxTTL: time.Hour}
+
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+			if rec.Code != http.StatusOK {
+				var body errorBody
+				if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil || body.Message == "" {
+					t.Errorf("error body = %q", rec.Body.String())
+				}
+				if rec.Code == http.StatusInternalServerError && strings.Contains(body.Message, "Secret") {
+					t.Errorf("error body = %q, want no internal details", body.Message)
+				}
+				return
+			}
+			var creds Credentials
+			if err := json.Unmarshal(rec.Body.Bytes(), &creds); err != nil || creds.Creds == "" {
+				t.Errorf("response = %q", rec.Body.String())
+			}
+			if issuer.got.ServiceAccount != orders || issuer.got.TTL != tt.wantTTL || issuer.got.MaxTTL != time.Hour {
+				t.Errorf("issued for %+v", issuer.got)
+			}
+		})
+	}
+}
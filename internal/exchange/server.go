@@ -0,0 +1,86 @@
+package exchange
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/certwatcher"
+)
+
+// shutdownTimeout bounds the wait for requests in flight when the server stops
+const shutdownTimeout = 10 * time.Second
+
+// Server serves a Handler until its context is done. It runs on every replica, not only the
+// leader, so it can be added to a controller-runtime manager.
+type Server struct {
+	// Addr the server listens on, e.g. ":8444"
+	Addr string
+
+	// CertDir holds tls.crt and tls.key, reloaded when they change. Required unless Insecure is
+	// set, as requests carry ServiceAccount tokens and responses NATS credentials.
+	CertDir string
+
+	// Insecure serves plain HTTP without CertDir, e.g. behind a service mesh that terminates TLS
+	Insecure bool
+
+	Handler http.Handler
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable
+func (s *Server) NeedLeaderElection() bool {
+	return false
+}
+
+// Start implements manager.Runnable
+func (s *Server) Start(ctx context.Context) error {
+	if s.CertDir == "" && !s.Insecure {
+		return errors.New("token exchange server needs a certificate directory, or Insecure to serve plain HTTP")
+	}
+	srv := &http.Server{
+		Addr:              s.Addr,
+		Handler:           s.Handler,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	if s.CertDir != "" {
+		watcher, err := certwatcher.New(filepath.Join(s.CertDir, "tls.crt"), filepath.Join(s.CertDir, "tls.key"))
+		if err != nil {
+			return fmt.Errorf("failed to load token exchange certificate: %w", err)
+		}
+		go func() {
+			_ = watcher.Start(ctx)
+		}()
+		srv.TLSConfig = &tls.Config{
+			MinVersion:     tls.VersionTLS12,
+			GetCertificate: watcher.GetCertificate,
+		}
+	}
+
+	errs := make(chan error, 1)
+	go func() {
+		var err error
+		if srv.TLSConfig != nil {
+			err = srv.ListenAndServeTLS("", "")
+		} else {
+			err = srv.ListenAndServe()
+		}
+		if !errors.Is(err, http.ErrServerClosed) {
+			errs <- err
+		}
+		close(errs)
+	}()
+
+	select {
+	case err := <-errs:
+		return fmt.Errorf("token exchange server failed: %w", err)
+	case <-ctx.Done():
+	}
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	return srv.Shutdown(shutdownCtx)
+}
@@ -0,0 +1,21 @@
+package exchange
+
+import (
+	"context"
+	"net/http"
+	"testing"
+)
+
+func TestServerRequiresTLS(t *testing.T) {
+	s := &Server{Addr: "127.0.0.1:0", Handler: http.NotFoundHandler()}
+	if err := s.Start(context.Background()); err == nil {
+		t.Fatal("Start() without a certificate directory succeeded, want an error")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	s.Insecure = true
+	if err := s.Start(ctx); err != nil {
+		t.Errorf("Start() with Insecure error = %v", err)
+	}
+}
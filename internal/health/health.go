@@ -0,0 +1,85 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/healthz"
+)
+
+// DefaultTimeout bounds one check
+const DefaultTimeout = 5 * time.Second
+
+// CRDsInstalled fails while a kind of group registered in scheme has no resource on the API
+// server, e.g. because its CRD is missing or was installed at an older version
+func CRDsInstalled(mapper meta.RESTMapper, scheme *runtime.Scheme, group string) healthz.Checker {
+	var kinds []schema.GroupVersionKind
+	for gvk := range scheme.AllKnownTypes() {
+		if gvk.Group != group || gvk.Version == runtime.APIVersionInternal {
+			continue
+		}
+		// Skip lists and the option and event types registered with every group version
+		if obj, err := scheme.New(gvk); err == nil && !meta.IsListType(obj) {
+			if _, ok := obj.(client.Object); ok {
+				kinds = append(kinds, gvk)
+			}
+		}
+	}
+	sort.Slice(kinds, func(i, j int) bool { return kinds[i].String() < kinds[j].String() })
+
+	return func(*http.Request) error {
+		var missing []string
+		for _, gvk := range kinds {
+			if _, err := mapper.RESTMapping(gvk.GroupKind(), gvk.Version); err != nil {
+				if !meta.IsNoMatchError(err) {
+					return fmt.Errorf("failed to look up %s: %w", gvk.Kind, err)
+				}
+				missing = append(missing, gvk.Kind+"."+gvk.Version)
+			}
+		}
+		if len(missing) > 0 {
+			return fmt.Errorf("CRDs not installed: %s", strings.Join(missing, ", "))
+		}
+		return nil
+	}
+}
+
+// SecretsReadable fails while Secrets, which hold the seeds, cannot be listed in namespace (all
+// namespaces when empty). Only the metadata of a single Secret is read.
+func SecretsReadable(reader client.Reader, namespace string) healthz.Checker {
+	return func(req *http.Request) error {
+		ctx, cancel := context.WithTimeout(req.Context(), DefaultTimeout)
+		defer cancel()
+
+		list := &metav1.PartialObjectMetadataList{}
+		list.SetGroupVersionKind(schema.GroupVersionKind{Version: "v1", Kind: "SecretList"})
+		if err := reader.List(ctx, list, client.InNamespace(namespace), client.Limit(1)); err != nil {
+			return fmt.Errorf("failed to list Secrets: %w", err)
+		}
+		return nil
+	}
+}
+
+// Pinger is a connection that can be probed, such as the NATS audit sink
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
+// Reachable fails while p cannot be reached
+func Reachable(p Pinger) healthz.Checker {
+	return func(req *http.Request) error {
+		ctx, cancel := context.WithTimeout(req.Context(), DefaultTimeout)
+		defer cancel()
+
+		return p.Ping(ctx)
+	}
+}
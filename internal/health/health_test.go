@@ -0,0 +1,68 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	natsv1alpha1 "github.com/jradikk/nats-auth-operator/api/v1alpha1"
+)
+
+func TestCRDsInstalled(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := natsv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("AddToScheme() error = %v", err)
+	}
+
+	mapper := meta.NewDefaultRESTMapper(nil)
+	for gvk := range scheme.AllKnownTypes() {
+		if gvk.Kind != "NatsUser" && !strings.HasSuffix(gvk.Kind, "List") {
+			mapper.Add(gvk, meta.RESTScopeNamespace)
+		}
+	}
+	check := CRDsInstalled(mapper, scheme, natsv1alpha1.GroupVersion.Group)
+
+	err := check(httptest.NewRequest("GET", "/readyz", nil))
+	if err == nil || err.Error() != "CRDs not installed: NatsUser.v1alpha1" {
+		t.Fatalf("check() error = %v, want the missing NatsUser", err)
+	}
+
+	mapper.Add(natsv1alpha1.GroupVersion.WithKind("NatsUser"), meta.RESTScopeNamespace)
+	if err := check(httptest.NewRequest("GET", "/readyz", nil)); err != nil {
+		t.Errorf("check() error = %v", err)
+	}
+}
+
+func TestSecretsReadable(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := corev1.AddToScheme(scheme); err != nil {
+		t.Fatalf("AddToScheme() error = %v", err)
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).Build()
+
+	if err := SecretsReadable(c, "nats-system")(httptest.NewRequest("GET", "/readyz", nil)); err != nil {
+		t.Errorf("check() error = %v", err)
+	}
+}
+
+// pinger answers every ping with err
+type pinger struct{ err error }
+
+func (p pinger) Ping(context.Context) error { return p.err }
+
+func TestReachable(t *testing.T) {
+	req := httptest.NewRequest("GET", "/readyz", nil)
+	if err := Reachable(pinger{})(req); err != nil {
+		t.Errorf("check() error = %v", err)
+	}
+	if err := Reachable(pinger{err: errors.New("connection refused")})(req); err == nil {
+		t.Error("check() succeeded for an unreachable server")
+	}
+}
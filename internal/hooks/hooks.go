@@ -0,0 +1,75 @@
+package hooks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/nats-io/jwt/v2"
+)
+
+// Kinds of claims passed to a mutator
+const (
+	KindAccount = "Account"
+	KindUser    = "User"
+)
+
+// ErrRejected is returned when a mutator refuses to have the claims signed
+var ErrRejected = errors.New("claims rejected by hook")
+
+// Request identifies the resource whose claims are about to be signed
+type Request struct {
+	// Kind is Account or User
+	Kind string `json:"kind"`
+
+	// Namespace and Name of the NatsAccount or NatsUser
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+
+	// AuthConfig is the namespace/name of the NatsAuthConfig the claims are issued under
+	AuthConfig string `json:"authConfig"`
+}
+
+// Mutator changes or rejects claims before they are signed. Implementations are registered on the
+// reconcilers at manager setup; return an error wrapping ErrRejected to refuse the claims.
+type Mutator interface {
+	MutateAccountClaims(ctx context.Context, req Request, claims *jwt.AccountClaims) error
+	MutateUserClaims(ctx context.Context, req Request, claims *jwt.UserClaims) error
+}
+
+// Chain runs mutators in order, stopping at the first error
+type Chain []Mutator
+
+// MutateAccountClaims runs every mutator on the account claims
+func (c Chain) MutateAccountClaims(ctx context.Context, req Request, claims *jwt.AccountClaims) error {
+	subject := claims.Subject
+	for _, m := range c {
+		if m == nil {
+			continue
+		}
+		if err := m.MutateAccountClaims(ctx, req, claims); err != nil {
+			return err
+		}
+		if claims.Subject != subject {
+			return fmt.Errorf("claim hook changed the account subject from %s to %s", subject, claims.Subject)
+		}
+	}
+	return nil
+}
+
+// MutateUserClaims runs every mutator on the user claims
+func (c Chain) MutateUserClaims(ctx context.Context, req Request, claims *jwt.UserClaims) error {
+	subject := claims.Subject
+	for _, m := range c {
+		if m == nil {
+			continue
+		}
+		if err := m.MutateUserClaims(ctx, req, claims); err != nil {
+			return err
+		}
+		if claims.Subject != subject {
+			return fmt.Errorf("claim hook changed the user subject from %s to %s", subject, claims.Subject)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,141 @@
+package hooks
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/nats-io/jwt/v2"
+	"github.com/nats-io/nkeys"
+)
+
+// hookServer answers every call with the response built by respond from the received request
+func hookServer(t *testing.T, status int, respond func(HookRequest) HookResponse) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req HookRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("Hook received invalid request: %v", err)
+		}
+		w.WriteHeader(status)
+		_ = json.NewEncoder(w).Encode(respond(req))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func newUserClaims(t *testing.T) *jwt.UserClaims {
+	t.Helper()
+	kp, _ := nkeys.CreateUser()
+	pub, _ := kp.PublicKey()
+	claims := jwt.NewUserClaims(pub)
+	claims.Expires = 2000000000
+	return claims
+}
+
+func TestHTTPHookMutateUserClaims(t *testing.T) {
+	capExpiry := func(req HookRequest) HookResponse {
+		var claims jwt.UserClaims
+		_ = json.Unmarshal(req.Claims, &claims)
+		claims.Expires = 1900000000
+		claims.Tags.Add("org:payments")
+		encoded, _ := json.Marshal(claims)
+		return HookResponse{Allowed: true, Claims: encoded}
+	}
+
+	tests := []struct {
+		name        string
+		status      int
+		respond     func(HookRequest) HookResponse
+		kinds       []string
+		ignore      bool
+		wantExpires int64
+		wantTag     bool
+		wantErr     error
+		wantAnyErr  bool
+	}{
+		{
+			name:        "Claims replaced",
+			status:      http.StatusOK,
+			respond:     capExpiry,
+			wantExpires: 1900000000,
+			wantTag:     true,
+		},
+		{
+			name:        "Allowed without changes",
+			status:      http.StatusOK,
+			respond:     func(HookRequest) HookResponse { return HookResponse{Allowed: true} },
+			wantExpires: 2000000000,
+		},
+		{
+			name:        "Kind not handled",
+			status:      http.StatusOK,
+			respond:     capExpiry,
+			kinds:       []string{KindAccount},
+			wantExpires: 2000000000,
+		},
+		{
+			name:        "Rejected",
+			status:      http.StatusOK,
+			respond:     func(HookRequest) HookResponse { return HookResponse{Message: "expiry too long"} },
+			wantExpires: 2000000000,
+			wantErr:     ErrRejected,
+		},
+		{
+			name:        "Failing hook",
+			status:      http.StatusInternalServerError,
+			respond:     capExpiry,
+			wantExpires: 2000000000,
+			wantAnyErr:  true,
+		},
+		{
+			name:        "Failing hook ignored",
+			status:      http.StatusInternalServerError,
+			respond:     capExpiry,
+			ignore:      true,
+			wantExpires: 2000000000,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := hookServer(t, tt.status, tt.respond)
+			hook := &HTTPHook{Name: "test", URL: srv.URL, Kinds: tt.kinds, IgnoreFailure: tt.ignore}
+			claims := newUserClaims(t)
+
+			err := hook.MutateUserClaims(context.Background(), Request{Kind: KindUser, Namespace: "apps", Name: "orders"}, claims)
+			switch {
+			case tt.wantErr != nil && !errors.Is(err, tt.wantErr):
+				t.Fatalf("MutateUserClaims() error = %v, want %v", err, tt.wantErr)
+			case tt.wantErr == nil && (err != nil) != tt.wantAnyErr:
+				t.Fatalf("MutateUserClaims() error = %v, wantErr %v", err, tt.wantAnyErr)
+			}
+			if claims.Expires != tt.wantExpires {
+				t.Errorf("Expires = %d, want %d", claims.Expires, tt.wantExpires)
+			}
+			if got := claims.Tags.Contains("org:payments"); got != tt.wantTag {
+				t.Errorf("Tags contain org:payments = %v, want %v", got, tt.wantTag)
+			}
+		})
+	}
+}
+
+func TestChainRejectsSubjectChange(t *testing.T) {
+	other, _ := nkeys.CreateUser()
+	otherPub, _ := other.PublicKey()
+	srv := hookServer(t, http.StatusOK, func(req HookRequest) HookResponse {
+		var claims jwt.UserClaims
+		_ = json.Unmarshal(req.Claims, &claims)
+		claims.Subject = otherPub
+		encoded, _ := json.Marshal(claims)
+		return HookResponse{Allowed: true, Claims: encoded}
+	})
+
+	chain := Chain{nil, &HTTPHook{Name: "test", URL: srv.URL}}
+	if err := chain.MutateUserClaims(context.Background(), Request{Kind: KindUser}, newUserClaims(t)); err == nil {
+		t.Error("MutateUserClaims() accepted a changed subject")
+	}
+}
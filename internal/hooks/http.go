@@ -0,0 +1,181 @@
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/nats-io/jwt/v2"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// DefaultTimeout bounds one call to an HTTP hook
+const DefaultTimeout = 5 * time.Second
+
+// maxResponseSize caps the response body read from a hook
+const maxResponseSize = 1 << 20
+
+// HookRequest is the body POSTed to an HTTP hook
+type HookRequest struct {
+	Request
+
+	// Claims are the decoded claims about to be signed
+	Claims json.RawMessage `json:"claims"`
+}
+
+// HookResponse is the body an HTTP hook answers with
+type HookResponse struct {
+	// Allowed must be true for the claims to be signed
+	Allowed bool `json:"allowed"`
+
+	// Message explains a rejection
+	Message string `json:"message,omitempty"`
+
+	// Claims replace the sent claims when set; the subject must not change
+	Claims json.RawMessage `json:"claims,omitempty"`
+}
+
+// HTTPHook sends claims to an HTTP endpoint, which can replace or reject them
+type HTTPHook struct {
+	// Name identifies the hook in errors
+	Name string
+
+	// URL the claims are POSTed to
+	URL string
+
+	// Kinds limits the claims sent to the hook; empty sends both accounts and users
+	Kinds []string
+
+	// IgnoreFailure signs the claims unchanged when the hook cannot be called
+	IgnoreFailure bool
+
+	// Client performs the call; http.DefaultClient with DefaultTimeout when nil
+	Client *http.Client
+}
+
+// NewHTTPClient returns a client trusting caBundle, or the system roots when it is empty
+func NewHTTPClient(caBundle []byte, timeout time.Duration) (*http.Client, error) {
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	client := &http.Client{Timeout: timeout}
+	if len(caBundle) > 0 {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caBundle) {
+			return nil, fmt.Errorf("caBundle holds no PEM certificates")
+		}
+		client.Transport = &http.Transport{
+			TLSClientConfig: &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12},
+		}
+	}
+	return client, nil
+}
+
+// MutateAccountClaims sends the account claims to the hook
+func (h *HTTPHook) MutateAccountClaims(ctx context.Context, req Request, claims *jwt.AccountClaims) error {
+	var mutated jwt.AccountClaims
+	replaced, err := h.mutate(ctx, req, claims, &mutated)
+	if replaced {
+		*claims = mutated
+	}
+	return err
+}
+
+// MutateUserClaims sends the user claims to the hook
+func (h *HTTPHook) MutateUserClaims(ctx context.Context, req Request, claims *jwt.UserClaims) error {
+	var mutated jwt.UserClaims
+	replaced, err := h.mutate(ctx, req, claims, &mutated)
+	if replaced {
+		*claims = mutated
+	}
+	return err
+}
+
+// mutate calls the hook with claims and decodes the claims it returns into mutated.
+// It reports whether mutated holds replacement claims.
+func (h *HTTPHook) mutate(ctx context.Context, req Request, claims, mutated interface{}) (bool, error) {
+	if !h.handles(req.Kind) {
+		return false, nil
+	}
+
+	resp, err := h.call(ctx, req, claims)
+	if err != nil {
+		if h.IgnoreFailure {
+			log.FromContext(ctx).Error(err, "Claim hook failed, signing claims unchanged", "hook", h.Name)
+			return false, nil
+		}
+		return false, fmt.Errorf("claim hook %s failed: %w", h.Name, err)
+	}
+
+	if !resp.Allowed {
+		return false, fmt.Errorf("%w %s: %s", ErrRejected, h.Name, resp.Message)
+	}
+	if len(resp.Claims) == 0 {
+		return false, nil
+	}
+	if err := json.Unmarshal(resp.Claims, mutated); err != nil {
+		return false, fmt.Errorf("claim hook %s returned invalid claims: %w", h.Name, err)
+	}
+	return true, nil
+}
+
+// call POSTs the claims and decodes the response
+func (h *HTTPHook) call(ctx context.Context, req Request, claims interface{}) (*HookResponse, error) {
+	encoded, err := json.Marshal(claims)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode claims: %w", err)
+	}
+	body, err := json.Marshal(HookRequest{Request: req, Claims: encoded})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode hook request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, h.URL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	client := h.Client
+	if client == nil {
+		client = &http.Client{Timeout: DefaultTimeout}
+	}
+	httpResp, err := client.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer httpResp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(httpResp.Body, maxResponseSize))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read hook response: %w", err)
+	}
+	if httpResp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("hook answered %s", httpResp.Status)
+	}
+
+	resp := &HookResponse{}
+	if err := json.Unmarshal(data, resp); err != nil {
+		return nil, fmt.Errorf("failed to decode hook response: %w", err)
+	}
+	return resp, nil
+}
+
+// handles reports whether the hook is called for the kind of claims
+func (h *HTTPHook) handles(kind string) bool {
+	if len(h.Kinds) == 0 {
+		return true
+	}
+	for _, k := range h.Kinds {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,56 @@
+package identity
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// DefaultTimeout bounds a directory request when the source has no client of its own
+const DefaultTimeout = 10 * time.Second
+
+// maxDirectorySize bounds the directory read from a source
+const maxDirectorySize = 8 << 20
+
+// HTTPSource reads the identity directory from an HTTP endpoint, e.g. a bridge that exports the
+// members of OIDC or LDAP groups
+type HTTPSource struct {
+	// URL the directory is fetched from with GET
+	URL string
+
+	// Client performs the call; a client with DefaultTimeout when nil
+	Client *http.Client
+}
+
+// Identities fetches and parses the directory
+func (s *HTTPSource) Identities(ctx context.Context) ([]Identity, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.URL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	client := s.Client
+	if client == nil {
+		client = &http.Client{Timeout: DefaultTimeout}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("identity source failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("identity source answered %s", resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxDirectorySize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read identity directory: %w", err)
+	}
+	if len(data) > maxDirectorySize {
+		return nil, fmt.Errorf("identity directory exceeds %d bytes", maxDirectorySize)
+	}
+	return Parse(data)
+}
@@ -0,0 +1,155 @@
+package identity
+
+import (
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// Identity is a person or workload known to an identity source
+type Identity struct {
+	// Subject is the stable ID of the identity, e.g. the OIDC sub claim or the LDAP DN
+	Subject string `json:"subject"`
+
+	// Name is a readable name such as the username or email (defaults to the subject)
+	Name string `json:"name,omitempty"`
+
+	// Groups are the groups the identity is a member of, e.g. the OIDC groups claim or LDAP groups
+	Groups []string `json:"groups,omitempty"`
+}
+
+// Directory is the document an identity source serves
+type Directory struct {
+	Identities []Identity `json:"identities"`
+}
+
+// Parse decodes a Directory and returns its identities. Subjects must be set and unique.
+func Parse(data []byte) ([]Identity, error) {
+	var dir Directory
+	if err := json.Unmarshal(data, &dir); err != nil {
+		return nil, fmt.Errorf("invalid identity directory: %w", err)
+	}
+	seen := make(map[string]bool, len(dir.Identities))
+	for i, id := range dir.Identities {
+		if id.Subject == "" {
+			return nil, fmt.Errorf("identities[%d] has no subject", i)
+		}
+		if seen[id.Subject] {
+			return nil, fmt.Errorf("identities[%d]: subject %q is listed twice", i, id.Subject)
+		}
+		seen[id.Subject] = true
+		if id.Name == "" {
+			dir.Identities[i].Name = id.Subject
+		}
+	}
+	return dir.Identities, nil
+}
+
+// Match returns the identities whose subject is listed in subjects or that are a member of one of
+// groups, ordered by subject
+func Match(identities []Identity, subjects, groups []string) []Identity {
+	wantSubject := toSet(subjects)
+	wantGroup := toSet(groups)
+
+	var matched []Identity
+	for _, id := range identities {
+		if wantSubject[id.Subject] || memberOf(id, wantGroup) {
+			matched = append(matched, id)
+		}
+	}
+	sort.Slice(matched, func(i, j int) bool { return matched[i].Subject < matched[j].Subject })
+	return matched
+}
+
+func memberOf(id Identity, groups map[string]bool) bool {
+	for _, g := range id.Groups {
+		if groups[g] {
+			return true
+		}
+	}
+	return false
+}
+
+func toSet(values []string) map[string]bool {
+	set := make(map[string]bool, len(values))
+	for _, v := range values {
+		set[v] = true
+	}
+	return set
+}
+
+// hashLength is the number of base32 characters of the subject hash in a resource name
+const hashLength = 10
+
+// hashEncoding is lowercase base32 without padding, so the hash is DNS-safe
+var hashEncoding = base32.NewEncoding("abcdefghijklmnopqrstuvwxyz234567").WithPadding(base32.NoPadding)
+
+// maxNameLength keeps resource names valid as labels, e.g. in the names of derived Secrets
+const maxNameLength = 63
+
+var nonDNS = regexp.MustCompile(`[^a-z0-9-]+`)
+
+// ResourceName returns the name of the resource provisioned for an identity: the prefix, the name
+// of the identity reduced to DNS-safe characters and a hash of the subject. The hash keeps names of
+// different subjects apart, and the same subject always gets the same name.
+func ResourceName(prefix string, id Identity) string {
+	sum := sha256.Sum256([]byte(id.Subject))
+	hash := hashEncoding.EncodeToString(sum[:])[:hashLength]
+
+	name := strings.Trim(nonDNS.ReplaceAllString(strings.ToLower(id.Name), "-"), "-")
+	if room := maxNameLength - len(prefix) - len(hash) - 2; len(name) > room {
+		name = strings.Trim(name[:max(room, 0)], "-")
+	}
+	if name == "" {
+		return fmt.Sprintf("%s-%s", prefix, hash)
+	}
+	return fmt.Sprintf("%s-%s-%s", prefix, name, hash)
+}
+
+var nonToken = regexp.MustCompile(`[^A-Za-z0-9_-]+`)
+
+// SubjectToken reduces a value to a single NATS subject token: every run of characters other than
+// letters, digits, '_' and '-' becomes '_', so "alice@example.com" becomes "alice_example_com"
+func SubjectToken(value string) string {
+	return nonToken.ReplaceAllString(value, "_")
+}
+
+// Render executes a permission template for an identity. The template sees the name and subject
+// of the identity as {{ .Name }} and {{ .Subject }}, both reduced to a single subject token by
+// SubjectToken, so an identity cannot widen the subjects it is granted.
+func Render(tmpl string, id Identity) (string, error) {
+	t, err := template.New("permission").Option("missingkey=error").Parse(tmpl)
+	if err != nil {
+		return "", fmt.Errorf("invalid template %q: %w", tmpl, err)
+	}
+	values := struct{ Name, Subject string }{
+		Name:    SubjectToken(id.Name),
+		Subject: SubjectToken(id.Subject),
+	}
+	var sb strings.Builder
+	if err := t.Execute(&sb, values); err != nil {
+		return "", fmt.Errorf("failed to render template %q: %w", tmpl, err)
+	}
+	return sb.String(), nil
+}
+
+// RenderList renders every template of a list for an identity
+func RenderList(templates []string, id Identity) ([]string, error) {
+	if templates == nil {
+		return nil, nil
+	}
+	rendered := make([]string, 0, len(templates))
+	for _, tmpl := range templates {
+		s, err := Render(tmpl, id)
+		if err != nil {
+			return nil, err
+		}
+		rendered = append(rendered, s)
+	}
+	return rendered, nil
+}
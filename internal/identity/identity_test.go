@@ -0,0 +1,169 @@
+package identity
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    []Identity
+		wantErr string
+	}{
+		{
+			name: "Name defaults to the subject",
+			data: `{"identities":[{"subject":"alice","groups":["dev"]},{"subject":"uid=bob,ou=people","name":"bob"}]}`,
+			want: []Identity{
+				{Subject: "alice", Name: "alice", Groups: []string{"dev"}},
+				{Subject: "uid=bob,ou=people", Name: "bob"},
+			},
+		},
+		{name: "Missing subject", data: `{"identities":[{"name":"alice"}]}`, wantErr: "identities[0] has no subject"},
+		{name: "Duplicate subject", data: `{"identities":[{"subject":"alice"},{"subject":"alice"}]}`, wantErr: "listed twice"},
+		{name: "Not JSON", data: `identities: []`, wantErr: "invalid identity directory"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse([]byte(tt.data))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Parse() error = %v, want it to contain %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatch(t *testing.T) {
+	identities := []Identity{
+		{Subject: "carol", Groups: []string{"ops"}},
+		{Subject: "alice", Groups: []string{"dev", "ops"}},
+		{Subject: "bob", Groups: []string{"dev"}},
+	}
+
+	tests := []struct {
+		name     string
+		subjects []string
+		groups   []string
+		want     []string
+	}{
+		{name: "By group", groups: []string{"ops"}, want: []string{"alice", "carol"}},
+		{name: "By subject", subjects: []string{"bob"}, want: []string{"bob"}},
+		{name: "Subject and group overlap", subjects: []string{"alice"}, groups: []string{"dev"}, want: []string{"alice", "bob"}},
+		{name: "Unknown group", groups: []string{"finance"}, want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, id := range Match(identities, tt.subjects, tt.groups) {
+				got = append(got, id.Subject)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Match() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResourceName(t *testing.T) {
+	tests := []struct {
+		name       string
+		identity   Identity
+		wantPrefix string
+	}{
+		{name: "Email", identity: Identity{Subject: "1234", Name: "Alice.Smith@example.com"}, wantPrefix: "sso-alice-smith-example-com-"},
+		{name: "Nothing DNS-safe", identity: Identity{Subject: "5678", Name: "@@@"}, wantPrefix: "sso-"},
+		{name: "Long name", identity: Identity{Subject: "9", Name: strings.Repeat("a", 100)}, wantPrefix: "sso-aaaa"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ResourceName("sso", tt.identity)
+			if !strings.HasPrefix(got, tt.wantPrefix) {
+				t.Errorf("ResourceName() = %q, want prefix %q", got, tt.wantPrefix)
+			}
+			if errs := validation.IsDNS1123Label(got); len(errs) > 0 {
+				t.Errorf("ResourceName() = %q is not a DNS-1123 label: %v", got, errs)
+			}
+			if got != ResourceName("sso", tt.identity) {
+				t.Error("ResourceName() is not stable")
+			}
+		})
+	}
+
+	same := Identity{Subject: "other", Name: "Alice.Smith@example.com"}
+	if ResourceName("sso", same) == ResourceName("sso", tests[0].identity) {
+		t.Error("ResourceName() gives identities of the same name but another subject the same name")
+	}
+}
+
+func TestRender(t *testing.T) {
+	alice := Identity{Subject: "uid=alice,ou=people", Name: "alice.smith@example.com"}
+
+	tests := []struct {
+		name     string
+		identity Identity
+		tmpl     string
+		want     string
+		wantErr  bool
+	}{
+		{name: "Name", identity: alice, tmpl: "users.{{ .Name }}.>", want: "users.alice_smith_example_com.>"},
+		{name: "Subject", identity: alice, tmpl: "inbox.{{ .Subject }}", want: "inbox.uid_alice_ou_people"},
+		{name: "Wildcards cannot be injected", identity: Identity{Subject: "x", Name: "a.*>b"}, tmpl: "users.{{ .Name }}", want: "users.a_b"},
+		{name: "No placeholders", identity: alice, tmpl: "public.>", want: "public.>"},
+		{name: "Unknown field", identity: alice, tmpl: "{{ .Email }}", wantErr: true},
+		{name: "Invalid template", identity: alice, tmpl: "{{ .Name", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Render(tt.tmpl, tt.identity)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Render() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Render() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHTTPSource(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/directory" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(`{"identities":[{"subject":"alice","groups":["dev"]}]}`))
+	}))
+	defer srv.Close()
+
+	got, err := (&HTTPSource{URL: srv.URL + "/directory"}).Identities(context.Background())
+	if err != nil {
+		t.Fatalf("Identities() error = %v", err)
+	}
+	if len(got) != 1 || got[0].Subject != "alice" {
+		t.Errorf("Identities() = %+v", got)
+	}
+
+	if _, err := (&HTTPSource{URL: srv.URL + "/missing"}).Identities(context.Background()); err == nil || !strings.Contains(err.Error(), "404") {
+		t.Errorf("Identities() error = %v, want the status", err)
+	}
+}
@@ -2,6 +2,7 @@ package jwt
 
 import (
 	"fmt"
+	"sort"
 	"time"
 
 	"github.com/nats-io/jwt/v2"
@@ -22,7 +23,7 @@ func NewAccountManager(seed []byte) (*AccountManager, error) {
 
 	if len(seed) > 0 {
 		// Use existing seed
-		kp, err = nkeys.FromSeed(seed)
+		kp, err = ParseSeed(seed, nkeys.PrefixByteAccount)
 		if err != nil {
 			return nil, fmt.Errorf("failed to create keypair from seed: %w", err)
 		}
@@ -34,9 +35,15 @@ func NewAccountManager(seed []byte) (*AccountManager, error) {
 		}
 	}
 
+	return NewAccountManagerFromKeyPair(kp), nil
+}
+
+// NewAccountManagerFromKeyPair creates an account manager signing with kp, e.g. a key held by
+// an external signer
+func NewAccountManagerFromKeyPair(kp nkeys.KeyPair) *AccountManager {
 	return &AccountManager{
 		accountKP: kp,
-	}, nil
+	}
 }
 
 // GetPublicKey returns the account's public key
@@ -67,7 +74,7 @@ func (am *AccountManager) CreateAccountClaims(name, description string, limits *
 	claims.IssuedAt = time.Now().Unix()
 
 	// Apply limits if specified
-	if limits != nil {
+	if limits := effectiveAccountLimits(limits); limits != nil {
 		claims.Limits.Conn = limits.Conn
 		claims.Limits.Subs = limits.Subs
 		claims.Limits.Payload = limits.Payload
@@ -78,20 +85,94 @@ func (am *AccountManager) CreateAccountClaims(name, description string, limits *
 
 		// Apply JetStream limits if specified
 		if limits.JetStream != nil {
-			claims.Limits.MemoryStorage = limits.JetStream.MemoryStorage
-			claims.Limits.DiskStorage = limits.JetStream.DiskStorage
-			claims.Limits.Streams = limits.JetStream.Streams
-			claims.Limits.Consumer = limits.JetStream.Consumer
-			claims.Limits.MaxAckPending = limits.JetStream.MaxAckPending
-			claims.Limits.MemoryMaxStreamBytes = limits.JetStream.MemoryMaxStreamBytes
-			claims.Limits.DiskMaxStreamBytes = limits.JetStream.DiskMaxStreamBytes
-			claims.Limits.MaxBytesRequired = limits.JetStream.MaxBytesRequired
+			claims.Limits.JetStreamLimits = jetStreamLimits(limits.JetStream)
+		}
+
+		// Tiered limits replace the global JetStream limits, which must stay empty
+		if len(limits.JetStreamTiers) > 0 {
+			claims.Limits.JetStreamLimits = jwt.JetStreamLimits{}
+			claims.Limits.JetStreamTieredLimits = make(jwt.JetStreamTieredLimits, len(limits.JetStreamTiers))
+			for tier, tierLimits := range limits.JetStreamTiers {
+				tierLimits := tierLimits
+				claims.Limits.JetStreamTieredLimits[tier] = jetStreamLimits(&tierLimits)
+			}
 		}
 	}
 
 	return claims, nil
 }
 
+// AccountInfo is the ownership information carried in account claims
+type AccountInfo struct {
+	InfoURL  string            `json:"infoURL,omitempty"`
+	Contact  string            `json:"contact,omitempty"`
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+// IsEmpty reports whether no ownership information is set
+func (i AccountInfo) IsEmpty() bool {
+	return i.InfoURL == "" && i.Contact == "" && len(i.Metadata) == 0
+}
+
+// SetAccountInfo sets the info URL and records the contact and metadata as "key:value" tags.
+// NATS lowercases tags.
+func SetAccountInfo(claims *jwt.AccountClaims, info AccountInfo) {
+	claims.InfoURL = info.InfoURL
+	if info.Contact != "" {
+		claims.Tags.Add("contact:" + info.Contact)
+	}
+
+	keys := make([]string, 0, len(info.Metadata))
+	for k := range info.Metadata {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		claims.Tags.Add(k + ":" + info.Metadata[k])
+	}
+}
+
+// AccountMappings converts subject mappings from the spec to their claims form
+func AccountMappings(mappings []natsv1alpha1.SubjectMapping) (jwt.Mapping, error) {
+	if len(mappings) == 0 {
+		return nil, nil
+	}
+
+	m := jwt.Mapping{}
+	for _, mapping := range mappings {
+		destinations := make([]jwt.WeightedMapping, 0, len(mapping.Destinations))
+		for _, dest := range mapping.Destinations {
+			destinations = append(destinations, jwt.WeightedMapping{
+				Subject: jwt.Subject(dest.Subject),
+				Weight:  uint8(dest.Weight),
+				Cluster: dest.Cluster,
+			})
+		}
+		m[jwt.Subject(mapping.Subject)] = destinations
+	}
+
+	vr := jwt.CreateValidationResults()
+	m.Validate(vr)
+	if errs := vr.Errors(); len(errs) > 0 {
+		return nil, fmt.Errorf("invalid subject mappings: %w", errs[0])
+	}
+	return m, nil
+}
+
+// jetStreamLimits converts JetStream limits from the spec to their claims form
+func jetStreamLimits(limits *natsv1alpha1.JetStreamLimits) jwt.JetStreamLimits {
+	return jwt.JetStreamLimits{
+		MemoryStorage:        limits.MemoryStorage,
+		DiskStorage:          limits.DiskStorage,
+		Streams:              limits.Streams,
+		Consumer:             limits.Consumer,
+		MaxAckPending:        limits.MaxAckPending,
+		MemoryMaxStreamBytes: limits.MemoryMaxStreamBytes,
+		DiskMaxStreamBytes:   limits.DiskMaxStreamBytes,
+		MaxBytesRequired:     limits.MaxBytesRequired,
+	}
+}
+
 // SignUserJWT signs a user JWT with the account key
 func (am *AccountManager) SignUserJWT(userClaims *jwt.UserClaims) (string, error) {
 	// Set the issuer to the account's public key
@@ -109,3 +190,30 @@ func (am *AccountManager) SignUserJWT(userClaims *jwt.UserClaims) (string, error
 
 	return token, nil
 }
+
+// ApplyRevocations adds user revocations (public key to unix time) to the account claims
+func ApplyRevocations(claims *jwt.AccountClaims, revocations map[string]int64) {
+	for pubKey, at := range revocations {
+		claims.RevokeAt(pubKey, time.Unix(at, 0))
+	}
+}
+
+// RevocationsMatch reports whether the account JWT carries exactly the given revocations
+func RevocationsMatch(accountJWT string, revocations map[string]int64) bool {
+	claims, err := jwt.DecodeAccountClaims(accountJWT)
+	if err != nil {
+		return false
+	}
+
+	if len(claims.Revocations) != len(revocations) {
+		return false
+	}
+
+	for pubKey, at := range revocations {
+		if claims.Revocations[pubKey] != at {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,218 @@
+package jwt
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/nats-io/jwt/v2"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	natsv1alpha1 "github.com/jradikk/nats-auth-operator/api/v1alpha1"
+)
+
+func TestRevocations(t *testing.T) {
+	om, err := NewOperatorManager(nil, "Test Operator")
+	if err != nil {
+		t.Fatalf("Failed to create operator manager: %v", err)
+	}
+
+	am, err := NewAccountManager(nil)
+	if err != nil {
+		t.Fatalf("Failed to create account manager: %v", err)
+	}
+
+	um, err := NewUserManager(nil)
+	if err != nil {
+		t.Fatalf("Failed to create user manager: %v", err)
+	}
+	userPubKey, _ := um.GetPublicKey()
+
+	claims, err := am.CreateAccountClaims("Test Account", "", nil)
+	if err != nil {
+		t.Fatalf("Failed to create account claims: %v", err)
+	}
+
+	revocations := map[string]int64{userPubKey: 1700000000}
+	ApplyRevocations(claims, revocations)
+
+	accountJWT, err := om.SignAccountJWT(claims)
+	if err != nil {
+		t.Fatalf("Failed to sign account JWT: %v", err)
+	}
+
+	if !RevocationsMatch(accountJWT, revocations) {
+		t.Error("RevocationsMatch() = false, want true for applied revocations")
+	}
+
+	if RevocationsMatch(accountJWT, nil) {
+		t.Error("RevocationsMatch() = true, want false when revocations were removed")
+	}
+
+	if RevocationsMatch(accountJWT, map[string]int64{userPubKey: 1800000000}) {
+		t.Error("RevocationsMatch() = true, want false for different revocation time")
+	}
+}
+
+func TestCreateAccountClaimsJetStreamLimits(t *testing.T) {
+	am, err := NewAccountManager(nil)
+	if err != nil {
+		t.Fatalf("Failed to create account manager: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		limits     *natsv1alpha1.AccountLimits
+		wantGlobal int64
+		wantTiers  map[string]int64
+	}{
+		{
+			name:       "Global limits",
+			limits:     &natsv1alpha1.AccountLimits{JetStream: &natsv1alpha1.JetStreamLimits{DiskStorage: 1024}},
+			wantGlobal: 1024,
+		},
+		{
+			name: "Tiered limits",
+			limits: &natsv1alpha1.AccountLimits{JetStreamTiers: map[string]natsv1alpha1.JetStreamLimits{
+				"R1": {DiskStorage: 1024, Streams: -1},
+				"R3": {DiskStorage: 4096, Streams: 10},
+			}},
+			wantTiers: map[string]int64{"R1": 1024, "R3": 4096},
+		},
+		{
+			name:       "Size overrides the byte count",
+			limits:     &natsv1alpha1.AccountLimits{JetStream: &natsv1alpha1.JetStreamLimits{DiskStorage: 1024, DiskStorageSize: resource.NewQuantity(10<<30, resource.BinarySI)}},
+			wantGlobal: 10 << 30,
+		},
+		{
+			name: "Tiered sizes",
+			limits: &natsv1alpha1.AccountLimits{JetStreamTiers: map[string]natsv1alpha1.JetStreamLimits{
+				"R1": {DiskStorageSize: ptrQuantity("1Gi")},
+				"R3": {DiskStorage: 4096},
+			}},
+			wantTiers: map[string]int64{"R1": 1 << 30, "R3": 4096},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims, err := am.CreateAccountClaims("test", "", tt.limits)
+			if err != nil {
+				t.Fatalf("CreateAccountClaims() error = %v", err)
+			}
+
+			vr := jwt.CreateValidationResults()
+			claims.Validate(vr)
+			if len(vr.Errors()) > 0 {
+				t.Fatalf("claims are invalid: %v", vr.Errors())
+			}
+
+			if got := claims.Limits.DiskStorage; got != tt.wantGlobal {
+				t.Errorf("global DiskStorage = %d, want %d", got, tt.wantGlobal)
+			}
+			if len(claims.Limits.JetStreamTieredLimits) != len(tt.wantTiers) {
+				t.Fatalf("tiers = %v, want %v", claims.Limits.JetStreamTieredLimits, tt.wantTiers)
+			}
+			for tier, want := range tt.wantTiers {
+				if got := claims.Limits.JetStreamTieredLimits[tier].DiskStorage; got != want {
+					t.Errorf("tier %s DiskStorage = %d, want %d", tier, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestSetAccountInfo(t *testing.T) {
+	am, err := NewAccountManager(nil)
+	if err != nil {
+		t.Fatalf("Failed to create account manager: %v", err)
+	}
+	claims, err := am.CreateAccountClaims("test", "", nil)
+	if err != nil {
+		t.Fatalf("CreateAccountClaims() error = %v", err)
+	}
+
+	SetAccountInfo(claims, AccountInfo{
+		InfoURL:  "https://wiki.example.com/payments",
+		Contact:  "Payments@example.com",
+		Metadata: map[string]string{"team": "payments", "cost-center": "cc-42"},
+	})
+
+	if claims.InfoURL != "https://wiki.example.com/payments" {
+		t.Errorf("InfoURL = %q", claims.InfoURL)
+	}
+	want := jwt.TagList{"contact:payments@example.com", "cost-center:cc-42", "team:payments"}
+	if len(claims.Tags) != len(want) {
+		t.Fatalf("Tags = %v, want %v", claims.Tags, want)
+	}
+	for i := range want {
+		if claims.Tags[i] != want[i] {
+			t.Errorf("Tags[%d] = %q, want %q", i, claims.Tags[i], want[i])
+		}
+	}
+
+	vr := jwt.CreateValidationResults()
+	claims.Validate(vr)
+	if len(vr.Errors()) > 0 {
+		t.Errorf("claims are invalid: %v", vr.Errors())
+	}
+}
+
+func TestAccountMappings(t *testing.T) {
+	tests := []struct {
+		name     string
+		mappings []natsv1alpha1.SubjectMapping
+		want     jwt.Mapping
+		wantErr  bool
+	}{
+		{
+			name: "No mappings",
+		},
+		{
+			name: "Canary split",
+			mappings: []natsv1alpha1.SubjectMapping{{
+				Subject: "orders.>",
+				Destinations: []natsv1alpha1.MappingDestination{
+					{Subject: "orders.v2.>", Weight: 10},
+					{Subject: "orders.v1.>", Weight: 90},
+				},
+			}},
+			want: jwt.Mapping{"orders.>": {{Subject: "orders.v2.>", Weight: 10}, {Subject: "orders.v1.>", Weight: 90}}},
+		},
+		{
+			name: "Cluster-specific destination",
+			mappings: []natsv1alpha1.SubjectMapping{{
+				Subject:      "events.*",
+				Destinations: []natsv1alpha1.MappingDestination{{Subject: "events.east.{{wildcard(1)}}", Cluster: "east"}},
+			}},
+			want: jwt.Mapping{"events.*": {{Subject: "events.east.{{wildcard(1)}}", Cluster: "east"}}},
+		},
+		{
+			name: "Weights above 100%",
+			mappings: []natsv1alpha1.SubjectMapping{{
+				Subject: "orders.>",
+				Destinations: []natsv1alpha1.MappingDestination{
+					{Subject: "orders.v2.>", Weight: 60},
+					{Subject: "orders.v1.>", Weight: 60},
+				},
+			}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := AccountMappings(tt.mappings)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("AccountMappings() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("AccountMappings() = %v, want %v", got, tt.want)
+			}
+			for subject, want := range tt.want {
+				if fmt.Sprint(got[subject]) != fmt.Sprint(want) {
+					t.Errorf("AccountMappings()[%q] = %v, want %v", subject, got[subject], want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,129 @@
+package jwt
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/nats-io/jwt/v2"
+
+	natsv1alpha1 "github.com/jradikk/nats-auth-operator/api/v1alpha1"
+)
+
+// AccountSharing is the exports and imports carried in account claims. Import activation tokens
+// are not part of it, since they are signed anew on every issue.
+type AccountSharing struct {
+	Exports jwt.Exports `json:"exports,omitempty"`
+	Imports jwt.Imports `json:"imports,omitempty"`
+}
+
+// IsEmpty reports whether the account neither exports nor imports anything
+func (s AccountSharing) IsEmpty() bool {
+	return len(s.Exports) == 0 && len(s.Imports) == 0
+}
+
+// SetAccountSharing adds the exports and imports to the account claims. tokens holds the
+// activation token of each import requiring one, by index.
+func SetAccountSharing(claims *jwt.AccountClaims, sharing AccountSharing, tokens map[int]string) {
+	for _, export := range sharing.Exports {
+		export := *export
+		claims.Exports.Add(&export)
+	}
+	for i, imp := range sharing.Imports {
+		imp := *imp
+		imp.Token = tokens[i]
+		claims.Imports.Add(&imp)
+	}
+}
+
+// AccountExport converts an export from the spec to its claims form. An export restricted to
+// some accounts requires an activation token.
+func AccountExport(export natsv1alpha1.AccountExport) (*jwt.Export, error) {
+	e := &jwt.Export{
+		Subject:      jwt.Subject(export.Subject),
+		Type:         exportType(export.Type),
+		TokenReq:     len(export.Accounts) > 0,
+		ResponseType: jwt.ResponseType(export.ResponseType),
+		Advertise:    export.Advertise,
+	}
+	if export.Latency != nil {
+		sampling, err := ParseSamplingRate(export.Latency.Sampling)
+		if err != nil {
+			return nil, err
+		}
+		e.Latency = &jwt.ServiceLatency{Sampling: sampling, Results: jwt.Subject(export.Latency.Results)}
+	}
+
+	vr := jwt.CreateValidationResults()
+	e.Validate(vr)
+	if errs := vr.Errors(); len(errs) > 0 {
+		return nil, fmt.Errorf("invalid %s export %q: %w", export.Type, export.Subject, errs[0])
+	}
+	return e, nil
+}
+
+// AccountImport converts an import from the spec to its claims form, importing from the account
+// with the given public key. Stream imports with a prefix and service imports with a local
+// subject are mapped to the local subject of the import.
+func AccountImport(imp natsv1alpha1.AccountImport, exporter string) *jwt.Import {
+	i := &jwt.Import{
+		Subject: jwt.Subject(imp.Subject),
+		Account: exporter,
+		Type:    exportType(imp.Type),
+	}
+	switch {
+	case imp.Type == natsv1alpha1.ExportTypeStream && imp.Prefix != "":
+		i.LocalSubject = jwt.RenamingSubject(imp.Prefix + "." + imp.Subject)
+	case imp.Type == natsv1alpha1.ExportTypeService && imp.To != "":
+		i.LocalSubject = jwt.RenamingSubject(imp.To)
+	}
+	return i
+}
+
+// ExportCovers reports whether an export of the spec makes the subject of an import available
+func ExportCovers(export natsv1alpha1.AccountExport, imp natsv1alpha1.AccountImport) bool {
+	return export.Type == imp.Type && jwt.Subject(imp.Subject).IsContainedIn(jwt.Subject(export.Subject))
+}
+
+// SignActivation signs a token activating an import of subject by the importing account. The
+// token is signed with the key of the exporting account.
+func (am *AccountManager) SignActivation(importer, subject string, kind natsv1alpha1.ExportType) (string, error) {
+	pubKey, err := am.accountKP.PublicKey()
+	if err != nil {
+		return "", fmt.Errorf("failed to get account public key: %w", err)
+	}
+
+	claims := jwt.NewActivationClaims(importer)
+	claims.Issuer = pubKey
+	claims.ImportSubject = jwt.Subject(subject)
+	claims.ImportType = exportType(kind)
+	token, err := claims.Encode(am.accountKP)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode activation token: %w", err)
+	}
+	return token, nil
+}
+
+// ParseSamplingRate parses the sampling of a service export: "headers", or a percentage with or
+// without the "%" sign. An empty sampling tracks every request.
+func ParseSamplingRate(sampling string) (jwt.SamplingRate, error) {
+	switch sampling {
+	case "":
+		return 100, nil
+	case "headers":
+		return jwt.Headers, nil
+	}
+	rate, err := strconv.Atoi(strings.TrimSuffix(sampling, "%"))
+	if err != nil || rate < 1 || rate > 100 {
+		return 0, fmt.Errorf("invalid latency sampling %q: must be \"headers\" or a percentage between 1 and 100", sampling)
+	}
+	return jwt.SamplingRate(rate), nil
+}
+
+// exportType converts an export type from the spec to its claims form
+func exportType(t natsv1alpha1.ExportType) jwt.ExportType {
+	if t == natsv1alpha1.ExportTypeService {
+		return jwt.Service
+	}
+	return jwt.Stream
+}
@@ -0,0 +1,186 @@
+package jwt
+
+import (
+	"testing"
+
+	"github.com/nats-io/jwt/v2"
+
+	natsv1alpha1 "github.com/jradikk/nats-auth-operator/api/v1alpha1"
+)
+
+func TestAccountExport(t *testing.T) {
+	tests := []struct {
+		name    string
+		export  natsv1alpha1.AccountExport
+		want    jwt.Export
+		wantErr bool
+	}{
+		{
+			name:   "Public stream",
+			export: natsv1alpha1.AccountExport{Type: natsv1alpha1.ExportTypeStream, Subject: "events.>"},
+			want:   jwt.Export{Subject: "events.>", Type: jwt.Stream},
+		},
+		{
+			name: "Restricted service with response type and latency",
+			export: natsv1alpha1.AccountExport{
+				Type:         natsv1alpha1.ExportTypeService,
+				Subject:      "api.time",
+				Accounts:     []natsv1alpha1.NatsAccountRef{{Name: "consumer"}},
+				ResponseType: natsv1alpha1.ResponseTypeStream,
+				Latency:      &natsv1alpha1.ServiceLatency{Sampling: "25%", Results: "latency.time"},
+				Advertise:    true,
+			},
+			want: jwt.Export{
+				Subject:      "api.time",
+				Type:         jwt.Service,
+				TokenReq:     true,
+				ResponseType: jwt.ResponseTypeStream,
+				Latency:      &jwt.ServiceLatency{Sampling: 25, Results: "latency.time"},
+				Advertise:    true,
+			},
+		},
+		{
+			name: "Latency sampled by headers",
+			export: natsv1alpha1.AccountExport{
+				Type:    natsv1alpha1.ExportTypeService,
+				Subject: "api.time",
+				Latency: &natsv1alpha1.ServiceLatency{Sampling: "headers", Results: "latency.time"},
+			},
+			want: jwt.Export{
+				Subject: "api.time",
+				Type:    jwt.Service,
+				Latency: &jwt.ServiceLatency{Sampling: jwt.Headers, Results: "latency.time"},
+			},
+		},
+		{
+			name: "Response type on a stream",
+			export: natsv1alpha1.AccountExport{
+				Type:         natsv1alpha1.ExportTypeStream,
+				Subject:      "events.>",
+				ResponseType: natsv1alpha1.ResponseTypeChunked,
+			},
+			wantErr: true,
+		},
+		{
+			name: "Latency results with wildcard",
+			export: natsv1alpha1.AccountExport{
+				Type:    natsv1alpha1.ExportTypeService,
+				Subject: "api.time",
+				Latency: &natsv1alpha1.ServiceLatency{Results: "latency.*"},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := AccountExport(tt.export)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("AccountExport() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got.Subject != tt.want.Subject || got.Type != tt.want.Type || got.TokenReq != tt.want.TokenReq ||
+				got.ResponseType != tt.want.ResponseType || got.Advertise != tt.want.Advertise {
+				t.Errorf("AccountExport() = %+v, want %+v", got, tt.want)
+			}
+			if (got.Latency == nil) != (tt.want.Latency == nil) || (got.Latency != nil && *got.Latency != *tt.want.Latency) {
+				t.Errorf("AccountExport() latency = %+v, want %+v", got.Latency, tt.want.Latency)
+			}
+		})
+	}
+}
+
+func TestAccountImport(t *testing.T) {
+	tests := []struct {
+		name      string
+		imp       natsv1alpha1.AccountImport
+		wantLocal jwt.RenamingSubject
+	}{
+		{
+			name:      "Stream with prefix",
+			imp:       natsv1alpha1.AccountImport{Type: natsv1alpha1.ExportTypeStream, Subject: "events.>", Prefix: "provider"},
+			wantLocal: "provider.events.>",
+		},
+		{
+			name:      "Service with local subject",
+			imp:       natsv1alpha1.AccountImport{Type: natsv1alpha1.ExportTypeService, Subject: "api.time", To: "time"},
+			wantLocal: "time",
+		},
+		{
+			name: "Service under its own subject",
+			imp:  natsv1alpha1.AccountImport{Type: natsv1alpha1.ExportTypeService, Subject: "api.time"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := AccountImport(tt.imp, "AEXPORTER")
+			if got.Account != "AEXPORTER" || string(got.Subject) != tt.imp.Subject || got.LocalSubject != tt.wantLocal {
+				t.Errorf("AccountImport() = %+v, want local subject %q", got, tt.wantLocal)
+			}
+		})
+	}
+}
+
+func TestSignActivation(t *testing.T) {
+	exporter, err := NewAccountManager(nil)
+	if err != nil {
+		t.Fatalf("Failed to create exporter: %v", err)
+	}
+	importer, err := NewAccountManager(nil)
+	if err != nil {
+		t.Fatalf("Failed to create importer: %v", err)
+	}
+	exporterKey, _ := exporter.GetPublicKey()
+	importerKey, _ := importer.GetPublicKey()
+
+	token, err := exporter.SignActivation(importerKey, "api.time", natsv1alpha1.ExportTypeService)
+	if err != nil {
+		t.Fatalf("SignActivation() error = %v", err)
+	}
+
+	claims, err := importer.CreateAccountClaims("consumer", "", nil)
+	if err != nil {
+		t.Fatalf("Failed to create claims: %v", err)
+	}
+	imp := AccountImport(natsv1alpha1.AccountImport{Type: natsv1alpha1.ExportTypeService, Subject: "api.time"}, exporterKey)
+	SetAccountSharing(claims, AccountSharing{Imports: jwt.Imports{imp}}, map[int]string{0: token})
+
+	vr := jwt.CreateValidationResults()
+	claims.Validate(vr)
+	if errs := vr.Errors(); len(errs) > 0 {
+		t.Fatalf("account claims with activated import are invalid: %v", errs)
+	}
+	if claims.Imports[0].Token != token {
+		t.Errorf("import token = %q, want the activation token", claims.Imports[0].Token)
+	}
+}
+
+func TestParseSamplingRate(t *testing.T) {
+	tests := []struct {
+		sampling string
+		want     jwt.SamplingRate
+		wantErr  bool
+	}{
+		{sampling: "", want: 100},
+		{sampling: "headers", want: jwt.Headers},
+		{sampling: "50", want: 50},
+		{sampling: "50%", want: 50},
+		{sampling: "0", wantErr: true},
+		{sampling: "101%", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.sampling, func(t *testing.T) {
+			got, err := ParseSamplingRate(tt.sampling)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseSamplingRate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseSamplingRate() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,193 @@
+package jwt
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/nats-io/jwt/v2"
+
+	natsv1alpha1 "github.com/jradikk/nats-auth-operator/api/v1alpha1"
+)
+
+// userClaimsInput is the canonical form of the spec fields that end up in user claims
+type userClaimsInput struct {
+	PublishAllow    []string `json:"pubAllow,omitempty"`
+	PublishDeny     []string `json:"pubDeny,omitempty"`
+	SubscribeAllow  []string `json:"subAllow,omitempty"`
+	SubscribeDeny   []string `json:"subDeny,omitempty"`
+	ConnectionTypes []string `json:"connectionTypes,omitempty"`
+	BearerToken     bool     `json:"bearer,omitempty"`
+
+	// Limits is only part of the hash when set, so users without limits keep their hash
+	Limits *natsv1alpha1.UserLimits `json:"limits,omitempty"`
+
+	// Expiry is the JWT lifetime; zero keeps the hash of users without expiry
+	Expiry time.Duration `json:"expiry,omitempty"`
+
+	// NotBefore and ClockSkew are only part of the hash when set, like Expiry
+	NotBefore int64         `json:"nbf,omitempty"`
+	ClockSkew time.Duration `json:"skew,omitempty"`
+
+	// SigningKey is the public key of the account signing key the JWT is signed with, if any
+	SigningKey string `json:"signingKey,omitempty"`
+
+	Audience       string   `json:"aud,omitempty"`
+	SourceNetworks []string `json:"src,omitempty"`
+}
+
+// PermissionsHash returns a stable hash of the effective user permissions, so a change
+// can be detected without decoding the issued JWT. Subject order does not matter.
+func PermissionsHash(permissions *natsv1alpha1.Permissions, connectionTypes []string, bearerToken bool, limits *natsv1alpha1.UserLimits, validity Validity, signingKey string, constraints UserConstraints) string {
+	limits = effectiveUserLimits(limits)
+	if limits != nil && limits.Conn != 0 {
+		// The connection limit is enforced through the account, not carried in the user JWT
+		jwtLimits := *limits
+		jwtLimits.Conn = 0
+		limits = &jwtLimits
+	}
+	input := userClaimsInput{
+		ConnectionTypes: sortedCopy(connectionTypes),
+		BearerToken:     bearerToken,
+		Limits:          limits,
+		Expiry:          validity.Expiry,
+		ClockSkew:       validity.ClockSkew,
+		SigningKey:      signingKey,
+		Audience:        constraints.Audience,
+		SourceNetworks:  sortedCopy(constraints.SourceNetworks),
+	}
+	if !validity.NotBefore.IsZero() {
+		input.NotBefore = validity.NotBefore.Unix()
+	}
+	if permissions != nil {
+		input.PublishAllow = sortedCopy(permissions.PublishAllow)
+		input.PublishDeny = sortedCopy(permissions.PublishDeny)
+		input.SubscribeAllow = sortedCopy(permissions.SubscribeAllow)
+		input.SubscribeDeny = sortedCopy(permissions.SubscribeDeny)
+	}
+
+	// Marshalling a struct of string slices and plain values cannot fail
+	data, _ := json.Marshal(input)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// accountClaimsInput is the canonical form of the spec fields that end up in account claims
+type accountClaimsInput struct {
+	Name        string                      `json:"name"`
+	Description string                      `json:"description,omitempty"`
+	Limits      *natsv1alpha1.AccountLimits `json:"limits,omitempty"`
+
+	// Info is only part of the hash when set, so accounts without it keep their hash
+	Info *AccountInfo `json:"info,omitempty"`
+
+	// Sharing is only part of the hash when set, for the same reason
+	Sharing *AccountSharing `json:"sharing,omitempty"`
+
+	Mappings jwt.Mapping `json:"mappings,omitempty"`
+
+	SigningKeys []SigningKey `json:"signingKeys,omitempty"`
+}
+
+// AccountClaimsHash returns a stable hash of the spec fields carried into account claims, with
+// imports resolved to the public keys of their exporting accounts. Revocations are compared
+// separately with RevocationsMatch.
+func AccountClaimsHash(name, description string, limits *natsv1alpha1.AccountLimits, info AccountInfo, sharing AccountSharing, mappings jwt.Mapping, signingKeys []SigningKey) string {
+	input := accountClaimsInput{
+		Name:        name,
+		Description: description,
+		Limits:      effectiveAccountLimits(limits),
+		Mappings:    mappings,
+		SigningKeys: signingKeys,
+	}
+	if !info.IsEmpty() {
+		input.Info = &info
+	}
+	if !sharing.IsEmpty() {
+		input.Sharing = &sharing
+	}
+
+	// Exports are validated when converted from the spec, so marshalling cannot fail
+	data, _ := json.Marshal(input)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// OperatorKeyHash returns a stable hash of the operator public key and the signing keys
+// accounts are signed with, so an operator seed rotation can be detected
+func OperatorKeyHash(operatorPubKey string, signingKeys []string) string {
+	data, _ := json.Marshal(struct {
+		Operator    string   `json:"operator"`
+		SigningKeys []string `json:"signingKeys,omitempty"`
+	}{operatorPubKey, sortedCopy(signingKeys)})
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// OperatorClaimsHash returns a stable hash of the operator claims, leaving out the issue time and
+// JWT ID, so a change to the operator name, URLs, tags or keys can be detected. Order does not matter.
+func OperatorClaimsHash(claims *jwt.OperatorClaims) string {
+	data, _ := json.Marshal(struct {
+		Subject               string   `json:"sub"`
+		Name                  string   `json:"name,omitempty"`
+		AccountServerURL      string   `json:"accountServerURL,omitempty"`
+		OperatorServiceURLs   []string `json:"serviceURLs,omitempty"`
+		SystemAccount         string   `json:"systemAccount,omitempty"`
+		StrictSigningKeyUsage bool     `json:"strict,omitempty"`
+		SigningKeys           []string `json:"signingKeys,omitempty"`
+		Tags                  []string `json:"tags,omitempty"`
+		Expires               int64    `json:"exp,omitempty"`
+	}{
+		Subject:               claims.Subject,
+		Name:                  claims.Name,
+		AccountServerURL:      claims.AccountServerURL,
+		OperatorServiceURLs:   sortedCopy(claims.OperatorServiceURLs),
+		SystemAccount:         claims.SystemAccount,
+		StrictSigningKeyUsage: claims.StrictSigningKeyUsage,
+		SigningKeys:           sortedCopy(claims.SigningKeys),
+		Tags:                  sortedCopy(claims.Tags),
+		Expires:               claims.Expires,
+	})
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// IssuedJWT is what status records about an issued JWT, so the JWT in a Secret can be compared
+// with the one the controller issued without decoding it
+type IssuedJWT struct {
+	// Fingerprint is the hex SHA-256 of the encoded JWT
+	Fingerprint string
+
+	// IssuedAt, Expires and NotBefore are unix times; zero Expires means the JWT does not expire
+	// and zero NotBefore that it is valid from issuance
+	IssuedAt  int64
+	Expires   int64
+	NotBefore int64
+}
+
+// DescribeJWT returns the fingerprint and validity of an encoded JWT of any type
+func DescribeJWT(token string) (IssuedJWT, error) {
+	claims, err := jwt.DecodeGeneric(token)
+	if err != nil {
+		return IssuedJWT{}, fmt.Errorf("failed to decode JWT: %w", err)
+	}
+	return IssuedJWT{Fingerprint: Fingerprint(token), IssuedAt: claims.IssuedAt, Expires: claims.Expires, NotBefore: claims.NotBefore}, nil
+}
+
+// Fingerprint returns the hex SHA-256 of an encoded JWT
+func Fingerprint(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func sortedCopy(values []string) []string {
+	if len(values) == 0 {
+		return nil
+	}
+	sorted := append([]string(nil), values...)
+	sort.Strings(sorted)
+	return sorted
+}
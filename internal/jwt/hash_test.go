@@ -0,0 +1,369 @@
+package jwt
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nats-io/jwt/v2"
+	"github.com/nats-io/nkeys"
+
+	natsv1alpha1 "github.com/jradikk/nats-auth-operator/api/v1alpha1"
+)
+
+func TestPermissionsHash(t *testing.T) {
+	base := PermissionsHash(&natsv1alpha1.Permissions{
+		PublishAllow:   []string{"orders.>", "events.>"},
+		SubscribeAllow: []string{"_INBOX.>"},
+	}, nil, false, nil, Validity{}, "", UserConstraints{})
+
+	tests := []struct {
+		name            string
+		permissions     *natsv1alpha1.Permissions
+		connectionTypes []string
+		bearerToken     bool
+		limits          *natsv1alpha1.UserLimits
+		validity        Validity
+		signingKey      string
+		constraints     UserConstraints
+		wantSame        bool
+	}{
+		{
+			name: "Same subjects in another order",
+			permissions: &natsv1alpha1.Permissions{
+				PublishAllow:   []string{"events.>", "orders.>"},
+				SubscribeAllow: []string{"_INBOX.>"},
+			},
+			wantSame: true,
+		},
+		{
+			name: "Subject added",
+			permissions: &natsv1alpha1.Permissions{
+				PublishAllow:   []string{"events.>", "orders.>", "audit.>"},
+				SubscribeAllow: []string{"_INBOX.>"},
+			},
+			wantSame: false,
+		},
+		{
+			name: "Subject moved from publish to subscribe",
+			permissions: &natsv1alpha1.Permissions{
+				PublishAllow:   []string{"orders.>"},
+				SubscribeAllow: []string{"_INBOX.>", "events.>"},
+			},
+			wantSame: false,
+		},
+		{
+			name: "Connection types restricted",
+			permissions: &natsv1alpha1.Permissions{
+				PublishAllow:   []string{"orders.>", "events.>"},
+				SubscribeAllow: []string{"_INBOX.>"},
+			},
+			connectionTypes: []string{"WEBSOCKET"},
+			wantSame:        false,
+		},
+		{
+			name: "Bearer token enabled",
+			permissions: &natsv1alpha1.Permissions{
+				PublishAllow:   []string{"orders.>", "events.>"},
+				SubscribeAllow: []string{"_INBOX.>"},
+			},
+			bearerToken: true,
+			wantSame:    false,
+		},
+		{
+			name: "Limits set",
+			permissions: &natsv1alpha1.Permissions{
+				PublishAllow:   []string{"orders.>", "events.>"},
+				SubscribeAllow: []string{"_INBOX.>"},
+			},
+			limits:   &natsv1alpha1.UserLimits{Subs: 100, Data: -1, Payload: 1024},
+			wantSame: false,
+		},
+		{
+			name: "Expiry set",
+			permissions: &natsv1alpha1.Permissions{
+				PublishAllow:   []string{"orders.>", "events.>"},
+				SubscribeAllow: []string{"_INBOX.>"},
+			},
+			validity: Validity{Expiry: 24 * time.Hour},
+			wantSame: false,
+		},
+		{
+			name: "Not before set",
+			permissions: &natsv1alpha1.Permissions{
+				PublishAllow:   []string{"orders.>", "events.>"},
+				SubscribeAllow: []string{"_INBOX.>"},
+			},
+			validity: Validity{NotBefore: time.Unix(1_700_000_000, 0)},
+			wantSame: false,
+		},
+		{
+			name: "Clock skew set",
+			permissions: &natsv1alpha1.Permissions{
+				PublishAllow:   []string{"orders.>", "events.>"},
+				SubscribeAllow: []string{"_INBOX.>"},
+			},
+			validity: Validity{ClockSkew: time.Minute},
+			wantSame: false,
+		},
+		{
+			name: "Signed with a signing key",
+			permissions: &natsv1alpha1.Permissions{
+				PublishAllow:   []string{"orders.>", "events.>"},
+				SubscribeAllow: []string{"_INBOX.>"},
+			},
+			signingKey: "ADSIGNINGKEY",
+			wantSame:   false,
+		},
+		{
+			name: "Audience set",
+			permissions: &natsv1alpha1.Permissions{
+				PublishAllow:   []string{"orders.>", "events.>"},
+				SubscribeAllow: []string{"_INBOX.>"},
+			},
+			constraints: UserConstraints{Audience: "prod"},
+			wantSame:    false,
+		},
+		{
+			name: "Source networks set",
+			permissions: &natsv1alpha1.Permissions{
+				PublishAllow:   []string{"orders.>", "events.>"},
+				SubscribeAllow: []string{"_INBOX.>"},
+			},
+			constraints: UserConstraints{SourceNetworks: []string{"10.0.0.0/8"}},
+			wantSame:    false,
+		},
+		{
+			name:        "No permissions",
+			permissions: nil,
+			wantSame:    false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := PermissionsHash(tt.permissions, tt.connectionTypes, tt.bearerToken, tt.limits, tt.validity, tt.signingKey, tt.constraints)
+			if (got == base) != tt.wantSame {
+				t.Errorf("PermissionsHash() same = %v, want %v", got == base, tt.wantSame)
+			}
+		})
+	}
+
+	if PermissionsHash(nil, nil, false, nil, Validity{}, "", UserConstraints{}) != PermissionsHash(&natsv1alpha1.Permissions{}, nil, false, nil, Validity{}, "", UserConstraints{}) {
+		t.Error("PermissionsHash() differs for nil and empty permissions")
+	}
+	if PermissionsHash(nil, nil, false, &natsv1alpha1.UserLimits{Subs: 100, Conn: 5}, Validity{}, "", UserConstraints{}) != PermissionsHash(nil, nil, false, &natsv1alpha1.UserLimits{Subs: 100, Conn: -1}, Validity{}, "", UserConstraints{}) {
+		t.Error("PermissionsHash() changes with the connection limit, which is not part of the user JWT")
+	}
+}
+
+func TestAccountClaimsHash(t *testing.T) {
+	limits := func(conn int64, disk int64) *natsv1alpha1.AccountLimits {
+		return &natsv1alpha1.AccountLimits{
+			Conn:      conn,
+			JetStream: &natsv1alpha1.JetStreamLimits{DiskStorage: disk},
+		}
+	}
+	base := AccountClaimsHash("app", "App account", limits(100, 1024), AccountInfo{}, AccountSharing{}, nil, nil)
+
+	tests := []struct {
+		name        string
+		account     string
+		description string
+		limits      *natsv1alpha1.AccountLimits
+		info        AccountInfo
+		sharing     AccountSharing
+		mappings    jwt.Mapping
+		signingKeys []SigningKey
+		wantSame    bool
+	}{
+		{
+			name:        "Unchanged",
+			account:     "app",
+			description: "App account",
+			limits:      limits(100, 1024),
+			wantSame:    true,
+		},
+		{
+			name:        "Connection limit changed",
+			account:     "app",
+			description: "App account",
+			limits:      limits(200, 1024),
+			wantSame:    false,
+		},
+		{
+			name:        "JetStream limit changed",
+			account:     "app",
+			description: "App account",
+			limits:      limits(100, 2048),
+			wantSame:    false,
+		},
+		{
+			name:        "Description changed",
+			account:     "app",
+			description: "Application account",
+			limits:      limits(100, 1024),
+			wantSame:    false,
+		},
+		{
+			name:        "Limits removed",
+			account:     "app",
+			description: "App account",
+			limits:      nil,
+			wantSame:    false,
+		},
+		{
+			name:        "Empty metadata",
+			account:     "app",
+			description: "App account",
+			limits:      limits(100, 1024),
+			info:        AccountInfo{Metadata: map[string]string{}},
+			wantSame:    true,
+		},
+		{
+			name:        "Contact added",
+			account:     "app",
+			description: "App account",
+			limits:      limits(100, 1024),
+			info:        AccountInfo{Contact: "team-payments"},
+			wantSame:    false,
+		},
+		{
+			name:        "Empty sharing",
+			account:     "app",
+			description: "App account",
+			limits:      limits(100, 1024),
+			sharing:     AccountSharing{Exports: jwt.Exports{}},
+			wantSame:    true,
+		},
+		{
+			name:        "Export added",
+			account:     "app",
+			description: "App account",
+			limits:      limits(100, 1024),
+			sharing:     AccountSharing{Exports: jwt.Exports{{Subject: "api.time", Type: jwt.Service}}},
+			wantSame:    false,
+		},
+		{
+			name:        "Mapping added",
+			account:     "app",
+			description: "App account",
+			limits:      limits(100, 1024),
+			mappings:    jwt.Mapping{"orders.>": {{Subject: "orders.v2.>", Weight: 10}}},
+			wantSame:    false,
+		},
+		{
+			name:        "Signing key added",
+			account:     "app",
+			description: "App account",
+			limits:      limits(100, 1024),
+			signingKeys: []SigningKey{{Role: "ops", PublicKey: "ADSIGNINGKEY"}},
+			wantSame:    false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := AccountClaimsHash(tt.account, tt.description, tt.limits, tt.info, tt.sharing, tt.mappings, tt.signingKeys)
+			if (got == base) != tt.wantSame {
+				t.Errorf("AccountClaimsHash() same = %v, want %v", got == base, tt.wantSame)
+			}
+		})
+	}
+}
+
+func TestOperatorKeyHash(t *testing.T) {
+	base := OperatorKeyHash("OA", []string{"OB", "OC"})
+
+	tests := []struct {
+		name        string
+		operator    string
+		signingKeys []string
+		wantSame    bool
+	}{
+		{
+			name:        "Unchanged",
+			operator:    "OA",
+			signingKeys: []string{"OB", "OC"},
+			wantSame:    true,
+		},
+		{
+			name:        "Signing key order ignored",
+			operator:    "OA",
+			signingKeys: []string{"OC", "OB"},
+			wantSame:    true,
+		},
+		{
+			name:        "Operator key rotated",
+			operator:    "OD",
+			signingKeys: []string{"OB", "OC"},
+			wantSame:    false,
+		},
+		{
+			name:        "Signing key rotated",
+			operator:    "OA",
+			signingKeys: []string{"OB", "OE"},
+			wantSame:    false,
+		},
+		{
+			name:     "Signing keys dropped",
+			operator: "OA",
+			wantSame: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := OperatorKeyHash(tt.operator, tt.signingKeys)
+			if (got == base) != tt.wantSame {
+				t.Errorf("OperatorKeyHash() same = %v, want %v", got == base, tt.wantSame)
+			}
+		})
+	}
+}
+
+func TestDescribeJWT(t *testing.T) {
+	account, _ := nkeys.CreateAccount()
+	user, _ := nkeys.CreateUser()
+	userPub, _ := user.PublicKey()
+
+	claims := jwt.NewUserClaims(userPub)
+	claims.Expires = time.Now().Add(time.Hour).Unix()
+	token, err := claims.Encode(account)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		token   string
+		want    IssuedJWT
+		wantErr bool
+	}{
+		{
+			name:  "User JWT",
+			token: token,
+			want:  IssuedJWT{Fingerprint: Fingerprint(token), IssuedAt: claims.IssuedAt, Expires: claims.Expires},
+		},
+		{
+			name:    "Not a JWT",
+			token:   "not-a-jwt",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DescribeJWT(tt.token)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("DescribeJWT() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("DescribeJWT() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	if Fingerprint(token) == Fingerprint(token+"x") || len(Fingerprint(token)) != 64 {
+		t.Error("Fingerprint() does not tell JWTs apart")
+	}
+}
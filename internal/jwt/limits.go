@@ -0,0 +1,53 @@
+package jwt
+
+import (
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	natsv1alpha1 "github.com/jradikk/nats-auth-operator/api/v1alpha1"
+)
+
+// sizeBytes returns size in bytes when it is set, otherwise the raw byte count
+func sizeBytes(size *resource.Quantity, bytes int64) int64 {
+	if size == nil {
+		return bytes
+	}
+	return size.Value()
+}
+
+// effectiveUserLimits returns a copy of the limits with the size fields folded into the byte
+// counts they override, so "1Ki" and 1024 give the same claims and hash
+func effectiveUserLimits(limits *natsv1alpha1.UserLimits) *natsv1alpha1.UserLimits {
+	if limits == nil || (limits.DataSize == nil && limits.PayloadSize == nil) {
+		return limits
+	}
+	effective := limits.DeepCopy()
+	effective.Data, effective.DataSize = sizeBytes(limits.DataSize, limits.Data), nil
+	effective.Payload, effective.PayloadSize = sizeBytes(limits.PayloadSize, limits.Payload), nil
+	return effective
+}
+
+// effectiveAccountLimits is effectiveUserLimits for account limits, including the JetStream limits
+func effectiveAccountLimits(limits *natsv1alpha1.AccountLimits) *natsv1alpha1.AccountLimits {
+	if limits == nil {
+		return nil
+	}
+	effective := limits.DeepCopy()
+	effective.Data, effective.DataSize = sizeBytes(limits.DataSize, limits.Data), nil
+	effective.Payload, effective.PayloadSize = sizeBytes(limits.PayloadSize, limits.Payload), nil
+	if effective.JetStream != nil {
+		foldJetStreamSizes(effective.JetStream)
+	}
+	for tier, tierLimits := range effective.JetStreamTiers {
+		foldJetStreamSizes(&tierLimits)
+		effective.JetStreamTiers[tier] = tierLimits
+	}
+	return effective
+}
+
+// foldJetStreamSizes replaces the byte counts of the JetStream limits with their size fields
+func foldJetStreamSizes(limits *natsv1alpha1.JetStreamLimits) {
+	limits.MemoryStorage, limits.MemoryStorageSize = sizeBytes(limits.MemoryStorageSize, limits.MemoryStorage), nil
+	limits.DiskStorage, limits.DiskStorageSize = sizeBytes(limits.DiskStorageSize, limits.DiskStorage), nil
+	limits.MemoryMaxStreamBytes, limits.MemoryMaxStreamSize = sizeBytes(limits.MemoryMaxStreamSize, limits.MemoryMaxStreamBytes), nil
+	limits.DiskMaxStreamBytes, limits.DiskMaxStreamSize = sizeBytes(limits.DiskMaxStreamSize, limits.DiskMaxStreamBytes), nil
+}
@@ -0,0 +1,56 @@
+package jwt
+
+import (
+	"testing"
+
+	"github.com/nats-io/jwt/v2"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	natsv1alpha1 "github.com/jradikk/nats-auth-operator/api/v1alpha1"
+)
+
+func ptrQuantity(s string) *resource.Quantity {
+	q := resource.MustParse(s)
+	return &q
+}
+
+func TestSetUserLimitsSizes(t *testing.T) {
+	tests := []struct {
+		name        string
+		limits      *natsv1alpha1.UserLimits
+		wantData    int64
+		wantPayload int64
+	}{
+		{name: "Byte counts", limits: &natsv1alpha1.UserLimits{Data: 2048, Payload: 1024}, wantData: 2048, wantPayload: 1024},
+		{name: "Sizes", limits: &natsv1alpha1.UserLimits{Data: -1, DataSize: ptrQuantity("10Gi"), Payload: 1024, PayloadSize: ptrQuantity("1Mi")}, wantData: 10 << 30, wantPayload: 1 << 20},
+		{name: "Decimal size", limits: &natsv1alpha1.UserLimits{Data: -1, Payload: -1, PayloadSize: ptrQuantity("512k")}, wantData: -1, wantPayload: 512000},
+		{name: "Unlimited size", limits: &natsv1alpha1.UserLimits{Data: 1024, DataSize: ptrQuantity("-1"), Payload: -1}, wantData: -1, wantPayload: -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims := jwt.NewUserClaims("UTEST")
+			SetUserLimits(claims, tt.limits)
+			if claims.Limits.Data != tt.wantData || claims.Limits.Payload != tt.wantPayload {
+				t.Errorf("data, payload = %d, %d, want %d, %d", claims.Limits.Data, claims.Limits.Payload, tt.wantData, tt.wantPayload)
+			}
+		})
+	}
+}
+
+func TestSizeLimitsHash(t *testing.T) {
+	bytes := &natsv1alpha1.UserLimits{Subs: -1, Data: 1 << 30, Payload: 1 << 20}
+	sizes := &natsv1alpha1.UserLimits{Subs: -1, Data: -1, DataSize: ptrQuantity("1Gi"), Payload: -1, PayloadSize: ptrQuantity("1Mi")}
+	if PermissionsHash(nil, nil, false, bytes, Validity{}, "", UserConstraints{}) != PermissionsHash(nil, nil, false, sizes, Validity{}, "", UserConstraints{}) {
+		t.Error("PermissionsHash() differs for equal limits written as sizes")
+	}
+
+	accountBytes := &natsv1alpha1.AccountLimits{Payload: 1 << 20, JetStream: &natsv1alpha1.JetStreamLimits{DiskStorage: 10 << 30}}
+	accountSizes := &natsv1alpha1.AccountLimits{Payload: -1, PayloadSize: ptrQuantity("1Mi"), JetStream: &natsv1alpha1.JetStreamLimits{DiskStorageSize: ptrQuantity("10Gi")}}
+	if AccountClaimsHash("a", "", accountBytes, AccountInfo{}, AccountSharing{}, nil, nil) != AccountClaimsHash("a", "", accountSizes, AccountInfo{}, AccountSharing{}, nil, nil) {
+		t.Error("AccountClaimsHash() differs for equal limits written as sizes")
+	}
+	if accountSizes.JetStream.DiskStorage != 0 || accountSizes.PayloadSize == nil {
+		t.Error("AccountClaimsHash() modified the limits")
+	}
+}
@@ -10,18 +10,33 @@ import (
 
 // OperatorManager manages NATS operator JWT operations
 type OperatorManager struct {
-	operatorKP nkeys.KeyPair
+	operatorKP  nkeys.KeyPair
 	operatorJWT string
+	claimsHash  string
+}
+
+// OperatorOptions holds the optional fields set on the operator claims
+type OperatorOptions struct {
+	AccountServerURL      string
+	OperatorServiceURLs   []string
+	StrictSigningKeyUsage bool
+	SigningKeys           []string
+	Tags                  []string
 }
 
 // NewOperatorManager creates a new operator manager from an existing seed or generates a new one
 func NewOperatorManager(seed []byte, operatorName string) (*OperatorManager, error) {
+	return NewOperatorManagerWithOptions(seed, operatorName, OperatorOptions{})
+}
+
+// NewOperatorManagerWithOptions creates an operator manager whose JWT carries the given options
+func NewOperatorManagerWithOptions(seed []byte, operatorName string, opts OperatorOptions) (*OperatorManager, error) {
 	var kp nkeys.KeyPair
 	var err error
 
 	if len(seed) > 0 {
 		// Use existing seed
-		kp, err = nkeys.FromSeed(seed)
+		kp, err = ParseSeed(seed, nkeys.PrefixByteOperator)
 		if err != nil {
 			return nil, fmt.Errorf("failed to create keypair from seed: %w", err)
 		}
@@ -32,7 +47,12 @@ func NewOperatorManager(seed []byte, operatorName string) (*OperatorManager, err
 			return nil, fmt.Errorf("failed to create operator keypair: %w", err)
 		}
 	}
+	return NewOperatorManagerFromKeyPair(kp, operatorName, opts)
+}
 
+// NewOperatorManagerFromKeyPair creates an operator manager signing with kp, e.g. a key held by
+// an external signer
+func NewOperatorManagerFromKeyPair(kp nkeys.KeyPair, operatorName string, opts OperatorOptions) (*OperatorManager, error) {
 	// Create operator claims
 	pubKey, err := kp.PublicKey()
 	if err != nil {
@@ -43,6 +63,17 @@ func NewOperatorManager(seed []byte, operatorName string) (*OperatorManager, err
 	claims.Name = operatorName
 	claims.Issuer = pubKey
 	claims.IssuedAt = time.Now().Unix()
+	claims.AccountServerURL = opts.AccountServerURL
+	claims.OperatorServiceURLs.Add(opts.OperatorServiceURLs...)
+	claims.StrictSigningKeyUsage = opts.StrictSigningKeyUsage
+	claims.SigningKeys.Add(opts.SigningKeys...)
+	claims.Tags.Add(opts.Tags...)
+
+	vr := jwt.CreateValidationResults()
+	claims.Validate(vr)
+	if errs := vr.Errors(); len(errs) > 0 {
+		return nil, fmt.Errorf("invalid operator claims: %w", errs[0])
+	}
 
 	// Sign the operator JWT
 	operatorJWT, err := claims.Encode(kp)
@@ -53,9 +84,30 @@ func NewOperatorManager(seed []byte, operatorName string) (*OperatorManager, err
 	return &OperatorManager{
 		operatorKP:  kp,
 		operatorJWT: operatorJWT,
+		claimsHash:  OperatorClaimsHash(claims),
 	}, nil
 }
 
+// ClaimsHash returns the OperatorClaimsHash of the operator JWT
+func (om *OperatorManager) ClaimsHash() string {
+	return om.claimsHash
+}
+
+// ReuseJWT keeps a previously issued operator JWT in place of the one just signed when it is signed
+// by the same key and carries the same claims, so the operator JWT only changes with its claims.
+// It reports whether the JWT was reused.
+func (om *OperatorManager) ReuseJWT(token string) bool {
+	if token == om.operatorJWT {
+		return true
+	}
+	claims, err := jwt.DecodeOperatorClaims(token)
+	if err != nil || claims.Issuer != claims.Subject || OperatorClaimsHash(claims) != om.claimsHash {
+		return false
+	}
+	om.operatorJWT = token
+	return true
+}
+
 // GetPublicKey returns the operator's public key
 func (om *OperatorManager) GetPublicKey() (string, error) {
 	return om.operatorKP.PublicKey()
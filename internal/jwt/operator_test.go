@@ -3,6 +3,7 @@ package jwt
 import (
 	"testing"
 
+	"github.com/nats-io/jwt/v2"
 	"github.com/nats-io/nkeys"
 )
 
@@ -108,3 +109,127 @@ func generateTestOperatorSeed(t *testing.T) []byte {
 	}
 	return seed
 }
+
+func TestNewOperatorManagerWithOptions(t *testing.T) {
+	signingKP, err := nkeys.CreateOperator()
+	if err != nil {
+		t.Fatalf("Failed to create signing key: %v", err)
+	}
+	signingKey, err := signingKP.PublicKey()
+	if err != nil {
+		t.Fatalf("Failed to get signing key: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		opts    OperatorOptions
+		wantErr bool
+	}{
+		{
+			name: "All options",
+			opts: OperatorOptions{
+				AccountServerURL:      "http://nats-resolver:9090/jwt/v1",
+				OperatorServiceURLs:   []string{"nats://nats:4222", "tls://nats:4223"},
+				StrictSigningKeyUsage: true,
+				SigningKeys:           []string{signingKey},
+				Tags:                  []string{"env:test"},
+			},
+		},
+		{
+			name:    "Account server URL without protocol",
+			opts:    OperatorOptions{AccountServerURL: "/jwt/v1"},
+			wantErr: true,
+		},
+		{
+			name:    "Operator service URL with unsupported scheme",
+			opts:    OperatorOptions{OperatorServiceURLs: []string{"http://nats:4222"}},
+			wantErr: true,
+		},
+		{
+			name:    "Signing key is not an operator key",
+			opts:    OperatorOptions{SigningKeys: []string{"not-a-key"}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			om, err := NewOperatorManagerWithOptions(nil, "Test Operator", tt.opts)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewOperatorManagerWithOptions() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			claims, err := jwt.DecodeOperatorClaims(om.GetJWT())
+			if err != nil {
+				t.Fatalf("Failed to decode operator JWT: %v", err)
+			}
+			if claims.AccountServerURL != tt.opts.AccountServerURL {
+				t.Errorf("AccountServerURL = %q, want %q", claims.AccountServerURL, tt.opts.AccountServerURL)
+			}
+			if len(claims.OperatorServiceURLs) != len(tt.opts.OperatorServiceURLs) {
+				t.Errorf("OperatorServiceURLs = %v, want %v", claims.OperatorServiceURLs, tt.opts.OperatorServiceURLs)
+			}
+			if claims.StrictSigningKeyUsage != tt.opts.StrictSigningKeyUsage {
+				t.Errorf("StrictSigningKeyUsage = %v, want %v", claims.StrictSigningKeyUsage, tt.opts.StrictSigningKeyUsage)
+			}
+			if !claims.SigningKeys.Contains(signingKey) {
+				t.Errorf("SigningKeys = %v, missing %s", claims.SigningKeys, signingKey)
+			}
+			if !claims.Tags.Contains("env:test") {
+				t.Errorf("Tags = %v, missing env:test", claims.Tags)
+			}
+		})
+	}
+}
+
+func TestOperatorManager_ReuseJWT(t *testing.T) {
+	seed := generateTestOperatorSeed(t)
+	opts := OperatorOptions{Tags: []string{"prod", "eu"}}
+	issued, err := NewOperatorManagerWithOptions(seed, "Test Operator", opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherKey, _ := NewOperatorManagerWithOptions(nil, "Test Operator", opts)
+
+	tests := []struct {
+		name         string
+		operatorName string
+		opts         OperatorOptions
+		token        string
+		want         bool
+	}{
+		{name: "Same claims", operatorName: "Test Operator", opts: opts, token: issued.GetJWT(), want: true},
+		{name: "Tag order ignored", operatorName: "Test Operator", opts: OperatorOptions{Tags: []string{"eu", "prod"}}, token: issued.GetJWT(), want: true},
+		{name: "Renamed operator", operatorName: "Renamed", opts: opts, token: issued.GetJWT(), want: false},
+		{name: "Changed tags", operatorName: "Test Operator", opts: OperatorOptions{Tags: []string{"staging"}}, token: issued.GetJWT(), want: false},
+		{name: "Other operator key", operatorName: "Test Operator", opts: opts, token: otherKey.GetJWT(), want: false},
+		{name: "No JWT", operatorName: "Test Operator", opts: opts, token: "", want: false},
+		{name: "Not a JWT", operatorName: "Test Operator", opts: opts, token: "not-a-jwt", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			om, err := NewOperatorManagerWithOptions(seed, tt.operatorName, tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			signed := om.GetJWT()
+			if got := om.ReuseJWT(tt.token); got != tt.want {
+				t.Errorf("ReuseJWT() = %v, want %v", got, tt.want)
+			}
+			want := signed
+			if tt.want {
+				want = tt.token
+			}
+			if om.GetJWT() != want {
+				t.Error("GetJWT() does not return the expected operator JWT")
+			}
+			if (om.ClaimsHash() == issued.ClaimsHash()) != tt.want && tt.token == issued.GetJWT() {
+				t.Errorf("ClaimsHash() same = %v, want %v", om.ClaimsHash() == issued.ClaimsHash(), tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,234 @@
+package jwt
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/nats-io/jwt/v2"
+
+	natsv1alpha1 "github.com/jradikk/nats-auth-operator/api/v1alpha1"
+	"github.com/jradikk/nats-auth-operator/pkg/credentials"
+)
+
+// Credentials Secret keys for the supported output formats, defined by the consumer package so
+// the layout the operator writes and the layout applications read cannot diverge
+const (
+	CredsKey     = credentials.CredsKey
+	JWTKey       = credentials.JWTKey
+	SeedKey      = credentials.SeedKey
+	SplitSeedKey = credentials.SplitSeedKey
+	URLKey       = credentials.URLKey
+	EnvJWTKey    = credentials.EnvJWTKey
+	EnvSeedKey   = credentials.EnvSeedKey
+	BundleKey    = credentials.BundleKey
+
+	// WellKnownCredsKey is the stable key workloads mount regardless of the output format
+	WellKnownCredsKey = credentials.WellKnownCredsKey
+)
+
+// DefaultCredsMountPath is the recommended directory to mount the credentials Secret at
+const DefaultCredsMountPath = "/etc/nats/creds"
+
+// CredsPathEnv is the environment variable pointing clients at the mounted creds file
+const CredsPathEnv = credentials.CredsPathEnv
+
+// defaultFormat is used when no output format is set
+const defaultFormat = natsv1alpha1.CredentialsFormatCreds
+
+// TLSBundle holds PEM material embedded in the bundle format
+type TLSBundle = credentials.TLS
+
+// Bundle is the JSON document written in the bundle format
+type Bundle = credentials.Bundle
+
+// RenderCredentials builds the credentials Secret data in the requested format
+func RenderCredentials(format natsv1alpha1.CredentialsFormat, natsURL, userJWT string, seed []byte, tls *TLSBundle) (map[string][]byte, error) {
+	if format == "" {
+		format = defaultFormat
+	}
+
+	switch format {
+	case natsv1alpha1.CredentialsFormatCreds:
+		return map[string][]byte{
+			CredsKey: []byte(GenerateCredsFile(userJWT, seed)),
+			JWTKey:   []byte(userJWT),
+			SeedKey:  seed,
+			URLKey:   []byte(natsURL),
+		}, nil
+	case natsv1alpha1.CredentialsFormatSplit:
+		return map[string][]byte{
+			JWTKey:       []byte(userJWT),
+			SplitSeedKey: seed,
+			URLKey:       []byte(natsURL),
+		}, nil
+	case natsv1alpha1.CredentialsFormatEnv:
+		return map[string][]byte{
+			EnvJWTKey:  []byte(userJWT),
+			EnvSeedKey: seed,
+			URLKey:     []byte(natsURL),
+		}, nil
+	case natsv1alpha1.CredentialsFormatBundle:
+		bundle, err := json.Marshal(Bundle{URL: natsURL, JWT: userJWT, Seed: string(seed), TLS: tls})
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode credentials bundle: %w", err)
+		}
+		return map[string][]byte{
+			BundleKey: bundle,
+		}, nil
+	default:
+		return nil, fmt.Errorf("unsupported credentials format: %s", format)
+	}
+}
+
+// ExtractCredentials reads the user JWT and seed from credentials Secret data in any
+// supported format. Either value is empty if it is not present.
+func ExtractCredentials(data map[string][]byte) (string, []byte) {
+	if raw, ok := data[BundleKey]; ok {
+		var bundle Bundle
+		if err := json.Unmarshal(raw, &bundle); err == nil {
+			return bundle.JWT, []byte(bundle.Seed)
+		}
+	}
+
+	userJWT := string(data[JWTKey])
+	if userJWT == "" {
+		userJWT = string(data[EnvJWTKey])
+	}
+
+	for _, key := range []string{SeedKey, SplitSeedKey, EnvSeedKey} {
+		if seed := data[key]; len(seed) > 0 {
+			return userJWT, seed
+		}
+	}
+
+	if creds, ok := data[CredsKey]; ok {
+		if kp, err := parseCredsSeed(creds); err == nil {
+			return userJWT, kp
+		}
+	}
+
+	return userJWT, nil
+}
+
+// HasFormat reports whether the credentials Secret data is laid out in the given format
+func HasFormat(data map[string][]byte, format natsv1alpha1.CredentialsFormat) bool {
+	if format == "" {
+		format = defaultFormat
+	}
+
+	switch format {
+	case natsv1alpha1.CredentialsFormatCreds:
+		return len(data[CredsKey]) > 0
+	case natsv1alpha1.CredentialsFormatSplit:
+		return len(data[JWTKey]) > 0 && len(data[SplitSeedKey]) > 0
+	case natsv1alpha1.CredentialsFormatEnv:
+		return len(data[EnvJWTKey]) > 0 && len(data[EnvSeedKey]) > 0
+	case natsv1alpha1.CredentialsFormatBundle:
+		return len(data[BundleKey]) > 0
+	default:
+		return false
+	}
+}
+
+// VerifyCredentials checks credentials Secret data in any supported format, like VerifyCredsFile
+func VerifyCredentials(data map[string][]byte, expectedPubKey string) error {
+	userJWT, seed := ExtractCredentials(data)
+	if userJWT == "" || len(seed) == 0 {
+		return fmt.Errorf("credentials contain no user JWT and seed")
+	}
+	return VerifyCredsFile([]byte(GenerateCredsFile(userJWT, seed)), expectedPubKey)
+}
+
+// CredentialsDrift checks credentials Secret data against what the operator writes for the
+// format: the JWT and seed must belong to expectedPubKey, and every key the format derives from
+// them, the NATS URL included, must match. It returns nil when the data is current.
+func CredentialsDrift(data map[string][]byte, format natsv1alpha1.CredentialsFormat, natsURL, expectedPubKey string, wellKnown bool) error {
+	if err := VerifyCredentials(data, expectedPubKey); err != nil {
+		return err
+	}
+	userJWT, seed := ExtractCredentials(data)
+	if format == "" {
+		format = defaultFormat
+	}
+
+	if format == natsv1alpha1.CredentialsFormatBundle {
+		var bundle Bundle
+		if err := json.Unmarshal(data[BundleKey], &bundle); err != nil {
+			return fmt.Errorf("key %q is not a valid bundle: %w", BundleKey, err)
+		}
+		if bundle.URL != natsURL {
+			return fmt.Errorf("key %q has NATS URL %q, want %q", BundleKey, bundle.URL, natsURL)
+		}
+	} else {
+		want, err := RenderCredentials(format, natsURL, userJWT, seed, nil)
+		if err != nil {
+			return err
+		}
+		for _, key := range sortedKeys(want) {
+			if string(data[key]) != string(want[key]) {
+				return fmt.Errorf("key %q does not match the issued credentials", key)
+			}
+		}
+	}
+
+	if wellKnown && string(data[WellKnownCredsKey]) != GenerateCredsFile(userJWT, seed) {
+		return fmt.Errorf("key %q does not match the issued credentials", WellKnownCredsKey)
+	}
+	return nil
+}
+
+// sortedKeys returns the keys of credentials data in order, so drift is reported deterministically
+func sortedKeys(data map[string][]byte) []string {
+	keys := make([]string, 0, len(data))
+	for k := range data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// CredsFilePath returns the path of the well-known creds file under the mount directory
+func CredsFilePath(mountPath string) string {
+	if mountPath == "" {
+		mountPath = DefaultCredsMountPath
+	}
+	return strings.TrimSuffix(mountPath, "/") + "/" + WellKnownCredsKey
+}
+
+// MountSnippet renders a pod spec fragment that mounts the well-known creds key of the
+// credentials Secret and points NATS_CREDS_PATH at it
+func MountSnippet(secretName, mountPath string) string {
+	if mountPath == "" {
+		mountPath = DefaultCredsMountPath
+	}
+
+	return fmt.Sprintf(`# Merge into the pod spec of workloads using these credentials
+volumes:
+  - name: nats-creds
+    secret:
+      secretName: %[1]s
+      items:
+        - key: %[2]s
+          path: %[2]s
+containers:
+  - name: app # your container
+    env:
+      - name: %[3]s
+        value: %[4]s
+    volumeMounts:
+      - name: nats-creds
+        mountPath: %[5]s
+        readOnly: true
+`, secretName, WellKnownCredsKey, CredsPathEnv, CredsFilePath(mountPath), mountPath)
+}
+
+// parseCredsSeed extracts the seed from a creds file
+func parseCredsSeed(creds []byte) ([]byte, error) {
+	kp, err := jwt.ParseDecoratedUserNKey(creds)
+	if err != nil {
+		return nil, err
+	}
+	return kp.Seed()
+}
@@ -0,0 +1,261 @@
+package jwt
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	natsv1alpha1 "github.com/jradikk/nats-auth-operator/api/v1alpha1"
+)
+
+func TestRenderCredentials(t *testing.T) {
+	am, err := NewAccountManager(nil)
+	if err != nil {
+		t.Fatalf("Failed to create account manager: %v", err)
+	}
+
+	um, err := NewUserManager(nil)
+	if err != nil {
+		t.Fatalf("Failed to create user manager: %v", err)
+	}
+
+	claims, err := um.CreateUserClaims("test-user", nil)
+	if err != nil {
+		t.Fatalf("Failed to create user claims: %v", err)
+	}
+
+	userJWT, err := am.SignUserJWT(claims)
+	if err != nil {
+		t.Fatalf("Failed to sign user JWT: %v", err)
+	}
+
+	seed, _ := um.GetSeed()
+	pubKey, _ := um.GetPublicKey()
+	tls := &TLSBundle{CA: "ca-pem"}
+
+	tests := []struct {
+		name     string
+		format   natsv1alpha1.CredentialsFormat
+		wantKeys []string
+		wantErr  bool
+	}{
+		{
+			name:     "Default is creds",
+			format:   "",
+			wantKeys: []string{CredsKey, JWTKey, SeedKey, URLKey},
+		},
+		{
+			name:     "Creds",
+			format:   natsv1alpha1.CredentialsFormatCreds,
+			wantKeys: []string{CredsKey, JWTKey, SeedKey, URLKey},
+		},
+		{
+			name:     "Split",
+			format:   natsv1alpha1.CredentialsFormatSplit,
+			wantKeys: []string{JWTKey, SplitSeedKey, URLKey},
+		},
+		{
+			name:     "Env",
+			format:   natsv1alpha1.CredentialsFormatEnv,
+			wantKeys: []string{EnvJWTKey, EnvSeedKey, URLKey},
+		},
+		{
+			name:     "Bundle",
+			format:   natsv1alpha1.CredentialsFormatBundle,
+			wantKeys: []string{BundleKey},
+		},
+		{
+			name:    "Unknown format",
+			format:  "yaml",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := RenderCredentials(tt.format, "nats://nats:4222", userJWT, seed, tls)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("RenderCredentials() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			if len(data) != len(tt.wantKeys) {
+				t.Errorf("RenderCredentials() has %d keys, want %d", len(data), len(tt.wantKeys))
+			}
+			for _, key := range tt.wantKeys {
+				if len(data[key]) == 0 {
+					t.Errorf("RenderCredentials() missing key %q", key)
+				}
+			}
+
+			if !HasFormat(data, tt.format) {
+				t.Errorf("HasFormat() = false for rendered %q data", tt.format)
+			}
+
+			gotJWT, gotSeed := ExtractCredentials(data)
+			if gotJWT != userJWT || string(gotSeed) != string(seed) {
+				t.Errorf("ExtractCredentials() did not return the rendered JWT and seed")
+			}
+
+			if err := VerifyCredentials(data, pubKey); err != nil {
+				t.Errorf("VerifyCredentials() error = %v", err)
+			}
+		})
+	}
+}
+
+func TestRenderCredentialsBundleTLS(t *testing.T) {
+	data, err := RenderCredentials(natsv1alpha1.CredentialsFormatBundle, "nats://nats:4222", "jwt", []byte("seed"), &TLSBundle{CA: "ca-pem"})
+	if err != nil {
+		t.Fatalf("RenderCredentials() error = %v", err)
+	}
+
+	var bundle Bundle
+	if err := json.Unmarshal(data[BundleKey], &bundle); err != nil {
+		t.Fatalf("Failed to decode bundle: %v", err)
+	}
+
+	if bundle.URL != "nats://nats:4222" || bundle.TLS == nil || bundle.TLS.CA != "ca-pem" {
+		t.Errorf("bundle = %+v, want url and tls.ca set", bundle)
+	}
+}
+
+func TestMountSnippet(t *testing.T) {
+	tests := []struct {
+		name      string
+		mountPath string
+		want      []string
+	}{
+		{
+			name:      "Default mount path",
+			mountPath: "",
+			want: []string{
+				"secretName: app-user-creds",
+				"key: nats.creds",
+				"value: /etc/nats/creds/nats.creds",
+				"mountPath: /etc/nats/creds\n",
+			},
+		},
+		{
+			name:      "Custom mount path with trailing slash",
+			mountPath: "/var/run/nats/",
+			want: []string{
+				"name: NATS_CREDS_PATH",
+				"value: /var/run/nats/nats.creds",
+				"mountPath: /var/run/nats/\n",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := MountSnippet("app-user-creds", tt.mountPath)
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("MountSnippet() missing %q in:\n%s", want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestCredentialsDrift(t *testing.T) {
+	am, _ := NewAccountManager(nil)
+	um, _ := NewUserManager(nil)
+	claims, _ := um.CreateUserClaims("test-user", nil)
+	userJWT, err := am.SignUserJWT(claims)
+	if err != nil {
+		t.Fatalf("Failed to sign user JWT: %v", err)
+	}
+	seed, _ := um.GetSeed()
+	pubKey, _ := um.GetPublicKey()
+
+	other, _ := NewUserManager(nil)
+	otherSeed, _ := other.GetSeed()
+	natsURL := "nats://nats:4222"
+
+	render := func(format natsv1alpha1.CredentialsFormat, edit func(map[string][]byte)) map[string][]byte {
+		data, err := RenderCredentials(format, natsURL, userJWT, seed, nil)
+		if err != nil {
+			t.Fatalf("RenderCredentials() error = %v", err)
+		}
+		if edit != nil {
+			edit(data)
+		}
+		return data
+	}
+
+	tests := []struct {
+		name      string
+		format    natsv1alpha1.CredentialsFormat
+		data      map[string][]byte
+		natsURL   string
+		wellKnown bool
+		wantDrift bool
+	}{
+		{
+			name:   "Current creds",
+			format: natsv1alpha1.CredentialsFormatCreds,
+			data:   render(natsv1alpha1.CredentialsFormatCreds, nil),
+		},
+		{
+			name:   "Current bundle",
+			format: natsv1alpha1.CredentialsFormatBundle,
+			data:   render(natsv1alpha1.CredentialsFormatBundle, nil),
+		},
+		{
+			name:      "Removed key",
+			format:    natsv1alpha1.CredentialsFormatCreds,
+			data:      render(natsv1alpha1.CredentialsFormatCreds, func(d map[string][]byte) { delete(d, JWTKey) }),
+			wantDrift: true,
+		},
+		{
+			name:      "Edited creds file",
+			format:    natsv1alpha1.CredentialsFormatCreds,
+			data:      render(natsv1alpha1.CredentialsFormatCreds, func(d map[string][]byte) { d[CredsKey] = []byte("edited") }),
+			wantDrift: true,
+		},
+		{
+			name:      "Seed of another user",
+			format:    natsv1alpha1.CredentialsFormatSplit,
+			data:      render(natsv1alpha1.CredentialsFormatSplit, func(d map[string][]byte) { d[SplitSeedKey] = otherSeed }),
+			wantDrift: true,
+		},
+		{
+			name:      "Stale NATS URL",
+			format:    natsv1alpha1.CredentialsFormatEnv,
+			data:      render(natsv1alpha1.CredentialsFormatEnv, nil),
+			natsURL:   "nats://nats.new:4222",
+			wantDrift: true,
+		},
+		{
+			name:      "Stale bundle NATS URL",
+			format:    natsv1alpha1.CredentialsFormatBundle,
+			data:      render(natsv1alpha1.CredentialsFormatBundle, nil),
+			natsURL:   "nats://nats.new:4222",
+			wantDrift: true,
+		},
+		{
+			name:      "Missing well-known key",
+			format:    natsv1alpha1.CredentialsFormatCreds,
+			data:      render(natsv1alpha1.CredentialsFormatCreds, nil),
+			wellKnown: true,
+			wantDrift: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			url := tt.natsURL
+			if url == "" {
+				url = natsURL
+			}
+			err := CredentialsDrift(tt.data, tt.format, url, pubKey, tt.wellKnown)
+			if (err != nil) != tt.wantDrift {
+				t.Errorf("CredentialsDrift() error = %v, wantDrift %v", err, tt.wantDrift)
+			}
+		})
+	}
+}
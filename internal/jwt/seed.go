@@ -0,0 +1,154 @@
+package jwt
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/nats-io/nkeys"
+)
+
+// Canonical keys nkey seeds are written under
+const (
+	OperatorSeedKey = "operator.seed"
+	AccountSeedKey  = "account.seed"
+	UserSeedKey     = SplitSeedKey
+)
+
+// ErrInvalidSeed is returned when Secret data does not hold a usable seed of the requested type
+var ErrInvalidSeed = errors.New("invalid seed")
+
+// SeedTypeLabel marks Secrets holding an nkey seed with the type of the key (operator, account or user)
+const SeedTypeLabel = "nats.jradikk/seed-type"
+
+// legacySeedKeys are accepted for seeds of any type, as written by earlier versions or by hand
+var legacySeedKeys = []string{SeedKey, "seed", "nkey"}
+
+// SeedType returns the SeedTypeLabel value for a seed prefix
+func SeedType(prefix nkeys.PrefixByte) string {
+	switch prefix {
+	case nkeys.PrefixByteOperator:
+		return "operator"
+	case nkeys.PrefixByteAccount:
+		return "account"
+	case nkeys.PrefixByteUser:
+		return "user"
+	}
+	return ""
+}
+
+// CanonicalSeedKey returns the key a seed of the given type is written under
+func CanonicalSeedKey(prefix nkeys.PrefixByte) string {
+	switch prefix {
+	case nkeys.PrefixByteOperator:
+		return OperatorSeedKey
+	case nkeys.PrefixByteAccount:
+		return AccountSeedKey
+	case nkeys.PrefixByteUser:
+		return UserSeedKey
+	}
+	return ""
+}
+
+// SeedKeys returns the keys a seed of the given type is looked up under, canonical key first
+func SeedKeys(prefix nkeys.PrefixByte) []string {
+	keys := []string{CanonicalSeedKey(prefix)}
+	if t := SeedType(prefix); t != "" {
+		keys = append(keys, t+".nk")
+	}
+	return append(keys, legacySeedKeys...)
+}
+
+// seedKind describes a seed type with the characters its encoding starts with, e.g. "account seed (SA)"
+func seedKind(prefix nkeys.PrefixByte) string {
+	switch prefix {
+	case nkeys.PrefixByteOperator:
+		return "operator seed (SO)"
+	case nkeys.PrefixByteAccount:
+		return "account seed (SA)"
+	case nkeys.PrefixByteUser:
+		return "user seed (SU)"
+	}
+	return prefix.String() + " seed"
+}
+
+// checkSeed decodes a seed and checks it is of the given type. A seed of another type decodes
+// fine and only fails once it signs, so the error names both types.
+func checkSeed(seed []byte, prefix nkeys.PrefixByte) error {
+	seedPrefix, _, err := nkeys.DecodeSeed(seed)
+	if err != nil {
+		return fmt.Errorf("%w: not an nkey seed, want %s", ErrInvalidSeed, seedKind(prefix))
+	}
+	if seedPrefix != prefix {
+		return fmt.Errorf("%w: %s instead of %s", ErrInvalidSeed, seedKind(seedPrefix), seedKind(prefix))
+	}
+	return nil
+}
+
+// ParseSeed returns the key pair of a seed, which must be of the given type
+func ParseSeed(seed []byte, prefix nkeys.PrefixByte) (nkeys.KeyPair, error) {
+	if err := checkSeed(seed, prefix); err != nil {
+		return nil, err
+	}
+	return nkeys.FromSeed(seed)
+}
+
+// FindSeed returns the seed of the given type from Secret data and the key it was found under.
+// An explicit key other than the canonical one is the only key tried; otherwise the canonical and
+// legacy keys are tried in order. Values that are not seeds of the type are skipped, and
+// surrounding whitespace left by hand-made Secrets is trimmed. When no key holds a seed of the
+// type, the error names the first key holding a seed of another type.
+func FindSeed(data map[string][]byte, prefix nkeys.PrefixByte, key string) ([]byte, string, error) {
+	keys := SeedKeys(prefix)
+	if key != "" && key != keys[0] {
+		keys = []string{key}
+	}
+
+	var mismatch error
+	for _, k := range keys {
+		value, ok := data[k]
+		if !ok {
+			continue
+		}
+		seed := bytes.TrimSpace(value)
+		seedPrefix, _, err := nkeys.DecodeSeed(seed)
+		if err == nil && seedPrefix == prefix {
+			return seed, k, nil
+		}
+		if len(keys) == 1 {
+			return nil, "", fmt.Errorf("key %q: %w", k, checkSeed(seed, prefix))
+		}
+		// A seed of another type under a seed key is the likely mistake, so report it
+		if err == nil && mismatch == nil {
+			mismatch = fmt.Errorf("key %q: %w", k, checkSeed(seed, prefix))
+		}
+	}
+
+	if mismatch != nil {
+		return nil, "", mismatch
+	}
+	if len(keys) == 1 {
+		return nil, "", fmt.Errorf("%w: %s seed key %q not found", ErrInvalidSeed, SeedType(prefix), keys[0])
+	}
+	return nil, "", fmt.Errorf("%w: %s seed not found under any of the keys %v", ErrInvalidSeed, SeedType(prefix), keys)
+}
+
+// NormalizeSeedData moves a seed stored under a legacy key, or with surrounding whitespace, to the
+// canonical key and returns the key it was rewritten from. The data is left unchanged, and ""
+// returned, when the seed is already stored in canonical form.
+func NormalizeSeedData(data map[string][]byte, prefix nkeys.PrefixByte) (string, error) {
+	seed, key, err := FindSeed(data, prefix, "")
+	if err != nil {
+		return "", err
+	}
+	canonical := CanonicalSeedKey(prefix)
+	if key == canonical && bytes.Equal(data[canonical], seed) {
+		return "", nil
+	}
+
+	data[canonical] = seed
+	if key != canonical {
+		delete(data, key)
+	}
+	return key, nil
+}
@@ -0,0 +1,199 @@
+package jwt
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/nats-io/nkeys"
+)
+
+func newSeed(t *testing.T, create func() (nkeys.KeyPair, error)) []byte {
+	t.Helper()
+	kp, err := create()
+	if err != nil {
+		t.Fatalf("Failed to create key pair: %v", err)
+	}
+	seed, err := kp.Seed()
+	if err != nil {
+		t.Fatalf("Failed to get seed: %v", err)
+	}
+	return seed
+}
+
+func TestFindSeed(t *testing.T) {
+	accountSeed := newSeed(t, nkeys.CreateAccount)
+	userSeed := newSeed(t, nkeys.CreateUser)
+
+	tests := []struct {
+		name    string
+		data    map[string][]byte
+		key     string
+		wantKey string
+		wantErr string
+	}{
+		{
+			name:    "Canonical key",
+			data:    map[string][]byte{AccountSeedKey: accountSeed},
+			wantKey: AccountSeedKey,
+		},
+		{
+			name:    "Legacy key",
+			data:    map[string][]byte{SeedKey: accountSeed},
+			wantKey: SeedKey,
+		},
+		{
+			name:    "Canonical key preferred over legacy key",
+			data:    map[string][]byte{"seed": accountSeed, AccountSeedKey: accountSeed},
+			wantKey: AccountSeedKey,
+		},
+		{
+			name:    "Explicit canonical key falls back to legacy keys",
+			data:    map[string][]byte{"account.nk": accountSeed},
+			key:     AccountSeedKey,
+			wantKey: "account.nk",
+		},
+		{
+			name:    "Explicit custom key",
+			data:    map[string][]byte{"custom": accountSeed, AccountSeedKey: accountSeed},
+			key:     "custom",
+			wantKey: "custom",
+		},
+		{
+			name:    "Explicit custom key missing",
+			data:    map[string][]byte{AccountSeedKey: accountSeed},
+			key:     "custom",
+			wantErr: `account seed key "custom" not found`,
+		},
+		{
+			name:    "Explicit custom key of another type",
+			data:    map[string][]byte{"custom": userSeed},
+			key:     "custom",
+			wantErr: `key "custom": invalid seed: user seed (SU) instead of account seed (SA)`,
+		},
+		{
+			name:    "Explicit custom key without a seed",
+			data:    map[string][]byte{"custom": []byte("not-a-seed")},
+			key:     "custom",
+			wantErr: `key "custom": invalid seed: not an nkey seed, want account seed (SA)`,
+		},
+		{
+			name:    "Only a seed of another type",
+			data:    map[string][]byte{"seed": []byte("not-a-seed"), "nkey": userSeed},
+			wantErr: `key "nkey": invalid seed: user seed (SU) instead of account seed (SA)`,
+		},
+		{
+			name:    "Seed of another type is skipped",
+			data:    map[string][]byte{AccountSeedKey: userSeed, "seed": accountSeed},
+			wantKey: "seed",
+		},
+		{
+			name:    "Trailing newline is trimmed",
+			data:    map[string][]byte{AccountSeedKey: append(append([]byte{}, accountSeed...), '\n')},
+			wantKey: AccountSeedKey,
+		},
+		{
+			name:    "No seed",
+			data:    map[string][]byte{"account.jwt": []byte("ey...")},
+			wantErr: "account seed not found under any of the keys",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			seed, key, err := FindSeed(tt.data, nkeys.PrefixByteAccount, tt.key)
+			if tt.wantErr != "" {
+				if !errors.Is(err, ErrInvalidSeed) || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("FindSeed() error = %v, want ErrInvalidSeed containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("FindSeed() error = %v", err)
+			}
+			if key != tt.wantKey {
+				t.Errorf("FindSeed() key = %q, want %q", key, tt.wantKey)
+			}
+			if string(seed) != string(accountSeed) {
+				t.Errorf("FindSeed() returned a different seed")
+			}
+		})
+	}
+}
+
+func TestParseSeed(t *testing.T) {
+	operatorSeed := newSeed(t, nkeys.CreateOperator)
+	userSeed := newSeed(t, nkeys.CreateUser)
+
+	tests := []struct {
+		name    string
+		seed    []byte
+		prefix  nkeys.PrefixByte
+		wantErr string
+	}{
+		{name: "Operator seed", seed: operatorSeed, prefix: nkeys.PrefixByteOperator},
+		{name: "User seed", seed: userSeed, prefix: nkeys.PrefixByteUser},
+		{name: "User seed for an operator", seed: userSeed, prefix: nkeys.PrefixByteOperator, wantErr: "user seed (SU) instead of operator seed (SO)"},
+		{name: "Operator seed for an account", seed: operatorSeed, prefix: nkeys.PrefixByteAccount, wantErr: "operator seed (SO) instead of account seed (SA)"},
+		{name: "Public key", seed: []byte("UAHJLSMYZDYCQQI7JMWJGQDI3WHDLUVSIAQUKRXOLGN6FJ6GCH5J7FVS"), prefix: nkeys.PrefixByteUser, wantErr: "not an nkey seed, want user seed (SU)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kp, err := ParseSeed(tt.seed, tt.prefix)
+			if tt.wantErr != "" {
+				if !errors.Is(err, ErrInvalidSeed) || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("ParseSeed() error = %v, want ErrInvalidSeed containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseSeed() error = %v", err)
+			}
+			if seed, _ := kp.Seed(); string(seed) != string(tt.seed) {
+				t.Errorf("ParseSeed() returned the key pair of another seed")
+			}
+		})
+	}
+}
+
+func TestNormalizeSeedData(t *testing.T) {
+	operatorSeed := newSeed(t, nkeys.CreateOperator)
+
+	tests := []struct {
+		name     string
+		data     map[string][]byte
+		wantFrom string
+	}{
+		{
+			name:     "Already canonical",
+			data:     map[string][]byte{OperatorSeedKey: operatorSeed},
+			wantFrom: "",
+		},
+		{
+			name:     "Legacy key renamed",
+			data:     map[string][]byte{SeedKey: operatorSeed},
+			wantFrom: SeedKey,
+		},
+		{
+			name:     "Whitespace trimmed",
+			data:     map[string][]byte{OperatorSeedKey: append(append([]byte{}, operatorSeed...), '\n')},
+			wantFrom: OperatorSeedKey,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			from, err := NormalizeSeedData(tt.data, nkeys.PrefixByteOperator)
+			if err != nil {
+				t.Fatalf("NormalizeSeedData() error = %v", err)
+			}
+			if from != tt.wantFrom {
+				t.Errorf("NormalizeSeedData() = %q, want %q", from, tt.wantFrom)
+			}
+			if len(tt.data) != 1 || string(tt.data[OperatorSeedKey]) != string(operatorSeed) {
+				t.Errorf("NormalizeSeedData() left data %v", tt.data)
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package jwt
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/nats-io/jwt/v2"
+	"github.com/nats-io/nkeys"
+
+	natsv1alpha1 "github.com/jradikk/nats-auth-operator/api/v1alpha1"
+)
+
+// SigningKey is an account signing key as carried in the account claims
+type SigningKey struct {
+	Role      string                        `json:"role"`
+	PublicKey string                        `json:"publicKey"`
+	Scope     *natsv1alpha1.SigningKeyScope `json:"scope,omitempty"`
+}
+
+// SigningKeySeedKey returns the account JWT Secret key the seed of a signing key is kept under
+func SigningKeySeedKey(role string) string {
+	return "signing-key." + role + ".seed"
+}
+
+// SetSigningKeys adds the signing keys to the account claims. Scoped keys carry their
+// user permission template; the others are plain signing keys.
+func SetSigningKeys(claims *jwt.AccountClaims, keys []SigningKey) {
+	for _, key := range keys {
+		if key.Scope == nil {
+			claims.SigningKeys.Add(key.PublicKey)
+			continue
+		}
+
+		scope := jwt.NewUserScope()
+		scope.Key = key.PublicKey
+		scope.Role = key.Role
+		template := jwt.NewUserClaims(key.PublicKey)
+		applyPermissions(template, key.Scope.Permissions)
+		SetUserLimits(template, key.Scope.Limits)
+		for _, t := range key.Scope.AllowedConnectionTypes {
+			template.AllowedConnectionTypes.Add(string(t))
+		}
+		scope.Template = template.UserPermissionLimits
+		claims.SigningKeys.AddScopedSigner(scope)
+	}
+}
+
+// SortSigningKeys orders signing keys by role for stable claims and hashes
+func SortSigningKeys(keys []SigningKey) {
+	sort.Slice(keys, func(i, j int) bool { return keys[i].Role < keys[j].Role })
+}
+
+// SignUserJWTWithSigningKey signs a user JWT with an account signing key. The account the
+// key belongs to is recorded as the issuer account.
+func (am *AccountManager) SignUserJWTWithSigningKey(userClaims *jwt.UserClaims, signingSeed []byte) (string, error) {
+	kp, err := ParseSeed(signingSeed, nkeys.PrefixByteAccount)
+	if err != nil {
+		return "", fmt.Errorf("failed to create signing keypair from seed: %w", err)
+	}
+	defer kp.Wipe()
+
+	accountPubKey, err := am.accountKP.PublicKey()
+	if err != nil {
+		return "", fmt.Errorf("failed to get account public key: %w", err)
+	}
+	signingPubKey, err := kp.PublicKey()
+	if err != nil {
+		return "", fmt.Errorf("failed to get signing key: %w", err)
+	}
+	userClaims.Issuer = signingPubKey
+	userClaims.IssuerAccount = accountPubKey
+
+	token, err := userClaims.Encode(kp)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode user JWT: %w", err)
+	}
+	return token, nil
+}
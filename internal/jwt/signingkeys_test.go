@@ -0,0 +1,111 @@
+package jwt
+
+import (
+	"testing"
+
+	"github.com/nats-io/jwt/v2"
+	"github.com/nats-io/nkeys"
+
+	natsv1alpha1 "github.com/jradikk/nats-auth-operator/api/v1alpha1"
+)
+
+func TestSigningKeys(t *testing.T) {
+	om, err := NewOperatorManager(nil, "Test Operator")
+	if err != nil {
+		t.Fatalf("Failed to create operator manager: %v", err)
+	}
+	am, err := NewAccountManager(nil)
+	if err != nil {
+		t.Fatalf("Failed to create account manager: %v", err)
+	}
+	accountPubKey, _ := am.GetPublicKey()
+
+	newKey := func() ([]byte, string) {
+		kp, err := nkeys.CreateAccount()
+		if err != nil {
+			t.Fatalf("Failed to create signing key: %v", err)
+		}
+		seed, _ := kp.Seed()
+		pub, _ := kp.PublicKey()
+		return seed, pub
+	}
+	scopedSeed, scopedPub := newKey()
+	plainSeed, plainPub := newKey()
+
+	keys := []SigningKey{
+		{
+			Role:      "readers",
+			PublicKey: scopedPub,
+			Scope: &natsv1alpha1.SigningKeyScope{
+				Permissions:            &natsv1alpha1.Permissions{SubscribeAllow: []string{"orders.>"}},
+				AllowedConnectionTypes: []natsv1alpha1.ConnectionType{"WEBSOCKET"},
+			},
+		},
+		{Role: "apps", PublicKey: plainPub},
+	}
+	SortSigningKeys(keys)
+	if keys[0].Role != "apps" {
+		t.Errorf("SortSigningKeys() first role = %q, want apps", keys[0].Role)
+	}
+
+	claims, err := am.CreateAccountClaims("Test Account", "", nil)
+	if err != nil {
+		t.Fatalf("Failed to create account claims: %v", err)
+	}
+	SetSigningKeys(claims, keys)
+	accountJWT, err := om.SignAccountJWT(claims)
+	if err != nil {
+		t.Fatalf("Failed to sign account JWT: %v", err)
+	}
+	decoded, err := jwt.DecodeAccountClaims(accountJWT)
+	if err != nil {
+		t.Fatalf("Failed to decode account JWT: %v", err)
+	}
+
+	if scope, ok := decoded.SigningKeys.GetScope(plainPub); !ok || scope != nil {
+		t.Errorf("plain signing key scope = %v, %v; want nil, true", scope, ok)
+	}
+	scope, ok := decoded.SigningKeys.GetScope(scopedPub)
+	if !ok || scope == nil {
+		t.Fatalf("scoped signing key missing from account claims")
+	}
+	template := scope.(*jwt.UserScope).Template
+	if !template.Sub.Allow.Contains("orders.>") || !template.AllowedConnectionTypes.Contains("WEBSOCKET") {
+		t.Errorf("scope template = %+v, want the scope permissions", template)
+	}
+
+	for _, tt := range []struct {
+		name string
+		seed []byte
+		pub  string
+	}{
+		{name: "Scoped key", seed: scopedSeed, pub: scopedPub},
+		{name: "Plain key", seed: plainSeed, pub: plainPub},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			um, err := NewUserManager(nil)
+			if err != nil {
+				t.Fatalf("Failed to create user manager: %v", err)
+			}
+			userClaims, err := um.CreateUserClaims("alice", nil)
+			if err != nil {
+				t.Fatalf("Failed to create user claims: %v", err)
+			}
+			userJWT, err := am.SignUserJWTWithSigningKey(userClaims, tt.seed)
+			if err != nil {
+				t.Fatalf("SignUserJWTWithSigningKey() error = %v", err)
+			}
+
+			user, err := jwt.DecodeUserClaims(userJWT)
+			if err != nil {
+				t.Fatalf("Failed to decode user JWT: %v", err)
+			}
+			if user.Issuer != tt.pub || user.IssuerAccount != accountPubKey {
+				t.Errorf("issuer = %s/%s, want %s/%s", user.Issuer, user.IssuerAccount, tt.pub, accountPubKey)
+			}
+			if !decoded.DidSign(user) {
+				t.Error("account claims do not accept the user JWT signer")
+			}
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package jwt
 
 import (
+	"errors"
 	"fmt"
 	"time"
 
@@ -22,7 +23,7 @@ func NewUserManager(seed []byte) (*UserManager, error) {
 
 	if len(seed) > 0 {
 		// Use existing seed
-		kp, err = nkeys.FromSeed(seed)
+		kp, err = ParseSeed(seed, nkeys.PrefixByteUser)
 		if err != nil {
 			return nil, fmt.Errorf("failed to create keypair from seed: %w", err)
 		}
@@ -65,23 +66,154 @@ func (um *UserManager) CreateUserClaims(name string, permissions *natsv1alpha1.P
 	claims.Name = name
 	claims.IssuedAt = time.Now().Unix()
 
-	// Apply permissions if specified
-	if permissions != nil {
-		if len(permissions.PublishAllow) > 0 {
-			claims.Pub.Allow.Add(permissions.PublishAllow...)
-		}
-		if len(permissions.PublishDeny) > 0 {
-			claims.Pub.Deny.Add(permissions.PublishDeny...)
-		}
-		if len(permissions.SubscribeAllow) > 0 {
-			claims.Sub.Allow.Add(permissions.SubscribeAllow...)
+	applyPermissions(claims, permissions)
+
+	return claims, nil
+}
+
+// applyPermissions adds the publish and subscribe permissions to the user claims
+func applyPermissions(claims *jwt.UserClaims, permissions *natsv1alpha1.Permissions) {
+	if permissions == nil {
+		return
+	}
+	if len(permissions.PublishAllow) > 0 {
+		claims.Pub.Allow.Add(permissions.PublishAllow...)
+	}
+	if len(permissions.PublishDeny) > 0 {
+		claims.Pub.Deny.Add(permissions.PublishDeny...)
+	}
+	if len(permissions.SubscribeAllow) > 0 {
+		claims.Sub.Allow.Add(permissions.SubscribeAllow...)
+	}
+	if len(permissions.SubscribeDeny) > 0 {
+		claims.Sub.Deny.Add(permissions.SubscribeDeny...)
+	}
+}
+
+// JetStreamControllerPermissions returns the permissions a JetStream controller such as NACK
+// needs to manage streams, consumers and buckets through the JetStream API
+func JetStreamControllerPermissions() *natsv1alpha1.Permissions {
+	return &natsv1alpha1.Permissions{
+		PublishAllow:   []string{"$JS.API.>"},
+		SubscribeAllow: []string{"_INBOX.>"},
+	}
+}
+
+// MonitoringPermissions returns the permissions a metrics collector such as
+// prometheus-nats-exporter or nats-surveyor needs in the system account: sending $SYS requests
+// and receiving their replies and the server and account events
+func MonitoringPermissions() *natsv1alpha1.Permissions {
+	return &natsv1alpha1.Permissions{
+		PublishAllow:   []string{"$SYS.REQ.>"},
+		SubscribeAllow: []string{"$SYS.>", "_INBOX.>"},
+	}
+}
+
+// ConnectionTypeLeafNode is the connection type leafnode users are restricted to
+const ConnectionTypeLeafNode = jwt.ConnectionTypeLeafnode
+
+// SetAllowedConnectionTypes restricts the connection types the user may use (e.g. LEAFNODE).
+// An empty list leaves the user unrestricted.
+func SetAllowedConnectionTypes(claims *jwt.UserClaims, types ...string) {
+	claims.AllowedConnectionTypes = jwt.StringList{}
+	claims.AllowedConnectionTypes.Add(types...)
+}
+
+// SetUserLimits applies subscription, data and payload limits to the user claims.
+// Nil limits leave the user unlimited.
+func SetUserLimits(claims *jwt.UserClaims, limits *natsv1alpha1.UserLimits) {
+	limits = effectiveUserLimits(limits)
+	if limits == nil {
+		return
+	}
+	claims.Limits.Subs = limits.Subs
+	claims.Limits.Data = limits.Data
+	claims.Limits.Payload = limits.Payload
+}
+
+// Validity is the validity window of a user JWT
+type Validity struct {
+	// Expiry is the lifetime of the JWT from when it becomes valid; zero means it does not expire
+	Expiry time.Duration
+
+	// NotBefore is when the JWT becomes valid; zero means when it is issued
+	NotBefore time.Time
+
+	// ClockSkew moves nbf earlier and exp later, for servers whose clocks drift from the issuer's
+	ClockSkew time.Duration
+}
+
+// SetValidity sets nbf and exp on user claims issued at now. nbf is only set with NotBefore: the
+// server does not check the issue time, so a JWT without nbf is valid whatever its clock says.
+func SetValidity(claims *jwt.UserClaims, validity Validity, now time.Time) {
+	start := now
+	if !validity.NotBefore.IsZero() {
+		claims.NotBefore = validity.NotBefore.Add(-validity.ClockSkew).Unix()
+		if validity.NotBefore.After(now) {
+			start = validity.NotBefore
 		}
-		if len(permissions.SubscribeDeny) > 0 {
-			claims.Sub.Deny.Add(permissions.SubscribeDeny...)
+	}
+	if validity.Expiry > 0 {
+		claims.Expires = start.Add(validity.Expiry + validity.ClockSkew).Unix()
+	}
+}
+
+// UserConstraints are the claims a NatsAuthConfig sets on every user JWT issued under it
+type UserConstraints struct {
+	// Audience is set as the aud claim
+	Audience string
+
+	// SourceNetworks are the CIDR blocks the user may connect from
+	SourceNetworks []string
+}
+
+// SetUserConstraints sets the audience and source networks on the user claims
+func SetUserConstraints(claims *jwt.UserClaims, constraints UserConstraints) {
+	claims.Audience = constraints.Audience
+	claims.Src = jwt.CIDRList{}
+	claims.Src.Add(constraints.SourceNetworks...)
+}
+
+// HasAllowedConnectionTypes reports whether the user JWT restricts connections to exactly the given types
+func HasAllowedConnectionTypes(userJWT string, types ...string) bool {
+	claims, err := jwt.DecodeUserClaims(userJWT)
+	if err != nil {
+		return false
+	}
+
+	want := jwt.StringList{}
+	want.Add(types...)
+	if len(claims.AllowedConnectionTypes) != len(want) {
+		return false
+	}
+	for _, t := range want {
+		if !claims.AllowedConnectionTypes.Contains(t) {
+			return false
 		}
 	}
+	return true
+}
 
-	return claims, nil
+// IsBearerToken reports whether the user JWT is a bearer token
+func IsBearerToken(userJWT string) bool {
+	claims, err := jwt.DecodeUserClaims(userJWT)
+	if err != nil {
+		return false
+	}
+	return claims.BearerToken
+}
+
+// IssuingAccount returns the public key of the account the user JWT belongs to: the issuer, or
+// the issuer account when it is signed with an account signing key. Empty for an invalid JWT.
+func IssuingAccount(userJWT string) string {
+	claims, err := jwt.DecodeUserClaims(userJWT)
+	if err != nil {
+		return ""
+	}
+	if claims.IssuerAccount != "" {
+		return claims.IssuerAccount
+	}
+	return claims.Issuer
 }
 
 // GenerateCredsFile generates a NATS credentials file content
@@ -101,3 +233,72 @@ NKEYs are sensitive and should be treated as secrets.
 *************************************************************
 `, userJWT, string(userSeed))
 }
+
+// VerifyCredsFile checks that a credentials file contains a decodable user JWT
+// and a seed matching the JWT subject. If expectedPubKey is set, the JWT subject
+// must also match it.
+func VerifyCredsFile(creds []byte, expectedPubKey string) error {
+	userJWT, err := jwt.ParseDecoratedJWT(creds)
+	if err != nil {
+		return fmt.Errorf("failed to parse user JWT from creds: %w", err)
+	}
+
+	claims, err := jwt.DecodeUserClaims(userJWT)
+	if err != nil {
+		return fmt.Errorf("failed to decode user JWT: %w", err)
+	}
+
+	kp, err := jwt.ParseDecoratedUserNKey(creds)
+	if err != nil {
+		return fmt.Errorf("failed to parse user seed from creds: %w", err)
+	}
+
+	pubKey, err := kp.PublicKey()
+	if err != nil {
+		return fmt.Errorf("failed to get user public key: %w", err)
+	}
+
+	if pubKey != claims.Subject {
+		return fmt.Errorf("user seed does not match JWT subject %s", claims.Subject)
+	}
+
+	if expectedPubKey != "" && claims.Subject != expectedPubKey {
+		return fmt.Errorf("JWT subject %s does not match expected public key %s", claims.Subject, expectedPubKey)
+	}
+
+	return nil
+}
+
+// ErrIssuerMismatch is returned when a user JWT was not issued by the expected account
+var ErrIssuerMismatch = errors.New("user JWT issuer does not match account")
+
+// VerifyUserJWT checks that an externally issued user JWT is valid, belongs to
+// expectedPubKey and was signed by the account in accountJWT, either with the
+// account key itself or with one of its signing keys.
+func VerifyUserJWT(userJWT, accountJWT, expectedPubKey string) (*jwt.UserClaims, error) {
+	claims, err := jwt.DecodeUserClaims(userJWT)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode user JWT: %w", err)
+	}
+
+	vr := jwt.CreateValidationResults()
+	claims.Validate(vr)
+	if vr.IsBlocking(true) {
+		return nil, fmt.Errorf("user JWT is invalid: %v", vr.Errors())
+	}
+
+	if claims.Subject != expectedPubKey {
+		return nil, fmt.Errorf("user JWT subject %s does not match user public key %s", claims.Subject, expectedPubKey)
+	}
+
+	accountClaims, err := jwt.DecodeAccountClaims(accountJWT)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode account JWT: %w", err)
+	}
+
+	if !accountClaims.DidSign(claims) {
+		return nil, fmt.Errorf("%w: issued by %s, account is %s", ErrIssuerMismatch, claims.Issuer, accountClaims.Subject)
+	}
+
+	return claims, nil
+}
@@ -0,0 +1,508 @@
+package jwt
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/nats-io/jwt/v2"
+	"github.com/nats-io/nkeys"
+)
+
+func TestVerifyCredsFile(t *testing.T) {
+	am, err := NewAccountManager(nil)
+	if err != nil {
+		t.Fatalf("Failed to create account manager: %v", err)
+	}
+
+	um, err := NewUserManager(nil)
+	if err != nil {
+		t.Fatalf("Failed to create user manager: %v", err)
+	}
+
+	claims, err := um.CreateUserClaims("test-user", nil)
+	if err != nil {
+		t.Fatalf("Failed to create user claims: %v", err)
+	}
+
+	userJWT, err := am.SignUserJWT(claims)
+	if err != nil {
+		t.Fatalf("Failed to sign user JWT: %v", err)
+	}
+
+	seed, err := um.GetSeed()
+	if err != nil {
+		t.Fatalf("Failed to get user seed: %v", err)
+	}
+
+	pubKey, err := um.GetPublicKey()
+	if err != nil {
+		t.Fatalf("Failed to get user public key: %v", err)
+	}
+
+	otherUser, err := NewUserManager(nil)
+	if err != nil {
+		t.Fatalf("Failed to create user manager: %v", err)
+	}
+	otherSeed, _ := otherUser.GetSeed()
+	otherPubKey, _ := otherUser.GetPublicKey()
+
+	tests := []struct {
+		name           string
+		creds          []byte
+		expectedPubKey string
+		wantErr        bool
+	}{
+		{
+			name:           "Valid creds",
+			creds:          []byte(GenerateCredsFile(userJWT, seed)),
+			expectedPubKey: pubKey,
+			wantErr:        false,
+		},
+		{
+			name:    "Valid creds without expected key",
+			creds:   []byte(GenerateCredsFile(userJWT, seed)),
+			wantErr: false,
+		},
+		{
+			name:    "Seed does not match JWT",
+			creds:   []byte(GenerateCredsFile(userJWT, otherSeed)),
+			wantErr: true,
+		},
+		{
+			name:           "Unexpected public key",
+			creds:          []byte(GenerateCredsFile(userJWT, seed)),
+			expectedPubKey: otherPubKey,
+			wantErr:        true,
+		},
+		{
+			name:    "Garbage",
+			creds:   []byte("not a creds file"),
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := VerifyCredsFile(tt.creds, tt.expectedPubKey)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("VerifyCredsFile() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestVerifyUserJWT(t *testing.T) {
+	om, err := NewOperatorManager(nil, "test-operator")
+	if err != nil {
+		t.Fatalf("Failed to create operator manager: %v", err)
+	}
+
+	am, err := NewAccountManager(nil)
+	if err != nil {
+		t.Fatalf("Failed to create account manager: %v", err)
+	}
+	accountPubKey, _ := am.GetPublicKey()
+
+	signingKey, err := nkeys.CreateAccount()
+	if err != nil {
+		t.Fatalf("Failed to create signing key: %v", err)
+	}
+	signingPubKey, _ := signingKey.PublicKey()
+
+	accountClaims, err := am.CreateAccountClaims("test-account", "", nil)
+	if err != nil {
+		t.Fatalf("Failed to create account claims: %v", err)
+	}
+	accountClaims.SigningKeys.Add(signingPubKey)
+
+	accountJWT, err := om.SignAccountJWT(accountClaims)
+	if err != nil {
+		t.Fatalf("Failed to sign account JWT: %v", err)
+	}
+
+	otherAccount, err := NewAccountManager(nil)
+	if err != nil {
+		t.Fatalf("Failed to create account manager: %v", err)
+	}
+
+	um, err := NewUserManager(nil)
+	if err != nil {
+		t.Fatalf("Failed to create user manager: %v", err)
+	}
+	userPubKey, _ := um.GetPublicKey()
+
+	sign := func(kp nkeys.KeyPair, issuerAccount string) string {
+		claims, err := um.CreateUserClaims("test-user", nil)
+		if err != nil {
+			t.Fatalf("Failed to create user claims: %v", err)
+		}
+		claims.IssuerAccount = issuerAccount
+		token, err := claims.Encode(kp)
+		if err != nil {
+			t.Fatalf("Failed to encode user JWT: %v", err)
+		}
+		return token
+	}
+
+	tests := []struct {
+		name           string
+		userJWT        string
+		expectedPubKey string
+		wantErr        bool
+		wantMismatch   bool
+	}{
+		{
+			name:           "Signed by account key",
+			userJWT:        sign(am.GetKeyPair(), ""),
+			expectedPubKey: userPubKey,
+		},
+		{
+			name:           "Signed by account signing key",
+			userJWT:        sign(signingKey, accountPubKey),
+			expectedPubKey: userPubKey,
+		},
+		{
+			name:           "Signed by another account",
+			userJWT:        sign(otherAccount.GetKeyPair(), ""),
+			expectedPubKey: userPubKey,
+			wantErr:        true,
+			wantMismatch:   true,
+		},
+		{
+			name:           "Signing key without issuer account",
+			userJWT:        sign(signingKey, ""),
+			expectedPubKey: userPubKey,
+			wantErr:        true,
+			wantMismatch:   true,
+		},
+		{
+			name:           "Subject does not match user key",
+			userJWT:        sign(am.GetKeyPair(), ""),
+			expectedPubKey: signingPubKey,
+			wantErr:        true,
+		},
+		{
+			name:           "Not a JWT",
+			userJWT:        "not-a-jwt",
+			expectedPubKey: userPubKey,
+			wantErr:        true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := VerifyUserJWT(tt.userJWT, accountJWT, tt.expectedPubKey)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("VerifyUserJWT() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if errors.Is(err, ErrIssuerMismatch) != tt.wantMismatch {
+				t.Errorf("VerifyUserJWT() error = %v, want issuer mismatch %v", err, tt.wantMismatch)
+			}
+		})
+	}
+}
+
+func TestSetAllowedConnectionTypes(t *testing.T) {
+	um, err := NewUserManager(nil)
+	if err != nil {
+		t.Fatalf("Failed to create user manager: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		types []string
+		want  []string
+	}{
+		{
+			name:  "Leafnode only",
+			types: []string{"LEAFNODE"},
+			want:  []string{"LEAFNODE"},
+		},
+		{
+			name:  "Duplicates collapsed",
+			types: []string{"STANDARD", "WEBSOCKET", "STANDARD"},
+			want:  []string{"STANDARD", "WEBSOCKET"},
+		},
+		{
+			name:  "Unrestricted",
+			types: nil,
+			want:  nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims, err := um.CreateUserClaims("test", nil)
+			if err != nil {
+				t.Fatalf("Failed to create user claims: %v", err)
+			}
+			claims.AllowedConnectionTypes.Add("MQTT")
+
+			SetAllowedConnectionTypes(claims, tt.types...)
+
+			if len(claims.AllowedConnectionTypes) != len(tt.want) {
+				t.Fatalf("AllowedConnectionTypes = %v, want %v", claims.AllowedConnectionTypes, tt.want)
+			}
+			for _, want := range tt.want {
+				if !claims.AllowedConnectionTypes.Contains(want) {
+					t.Errorf("AllowedConnectionTypes = %v, missing %s", claims.AllowedConnectionTypes, want)
+				}
+			}
+		})
+	}
+}
+
+func TestSetUserConstraints(t *testing.T) {
+	um, err := NewUserManager(nil)
+	if err != nil {
+		t.Fatalf("Failed to create user manager: %v", err)
+	}
+	account, _ := nkeys.CreateAccount()
+
+	tests := []struct {
+		name        string
+		constraints UserConstraints
+	}{
+		{
+			name:        "Audience and source networks",
+			constraints: UserConstraints{Audience: "prod", SourceNetworks: []string{"10.0.0.0/8", "192.168.1.0/24"}},
+		},
+		{
+			name:        "Unconstrained",
+			constraints: UserConstraints{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims, err := um.CreateUserClaims("test", nil)
+			if err != nil {
+				t.Fatalf("Failed to create user claims: %v", err)
+			}
+			claims.Src.Add("172.16.0.0/12")
+
+			SetUserConstraints(claims, tt.constraints)
+
+			token, err := claims.Encode(account)
+			if err != nil {
+				t.Fatalf("Encode() error = %v", err)
+			}
+			decoded, err := jwt.DecodeUserClaims(token)
+			if err != nil {
+				t.Fatalf("DecodeUserClaims() error = %v", err)
+			}
+			if decoded.Audience != tt.constraints.Audience {
+				t.Errorf("Audience = %q, want %q", decoded.Audience, tt.constraints.Audience)
+			}
+			if len(decoded.Src) != len(tt.constraints.SourceNetworks) {
+				t.Fatalf("Src = %v, want %v", decoded.Src, tt.constraints.SourceNetworks)
+			}
+			for _, cidr := range tt.constraints.SourceNetworks {
+				if !decoded.Src.Contains(cidr) {
+					t.Errorf("Src = %v, missing %s", decoded.Src, cidr)
+				}
+			}
+		})
+	}
+}
+
+func TestSetValidity(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+
+	tests := []struct {
+		name          string
+		validity      Validity
+		wantNotBefore int64
+		wantExpires   int64
+	}{
+		{
+			name: "Unbounded",
+		},
+		{
+			name:        "Expiry from issuance",
+			validity:    Validity{Expiry: time.Hour},
+			wantExpires: now.Add(time.Hour).Unix(),
+		},
+		{
+			name:        "Clock skew extends the expiry only",
+			validity:    Validity{Expiry: time.Hour, ClockSkew: time.Minute},
+			wantExpires: now.Add(time.Hour + time.Minute).Unix(),
+		},
+		{
+			name:          "Expiry from a future not before",
+			validity:      Validity{Expiry: time.Hour, NotBefore: now.Add(24 * time.Hour), ClockSkew: time.Minute},
+			wantNotBefore: now.Add(24*time.Hour - time.Minute).Unix(),
+			wantExpires:   now.Add(25*time.Hour + time.Minute).Unix(),
+		},
+		{
+			name:          "Past not before",
+			validity:      Validity{Expiry: time.Hour, NotBefore: now.Add(-time.Hour)},
+			wantNotBefore: now.Add(-time.Hour).Unix(),
+			wantExpires:   now.Add(time.Hour).Unix(),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims := jwt.NewUserClaims("UA")
+			SetValidity(claims, tt.validity, now)
+			if claims.NotBefore != tt.wantNotBefore || claims.Expires != tt.wantExpires {
+				t.Errorf("nbf, exp = %d, %d, want %d, %d", claims.NotBefore, claims.Expires, tt.wantNotBefore, tt.wantExpires)
+			}
+		})
+	}
+}
+
+func TestHasAllowedConnectionTypes(t *testing.T) {
+	am, err := NewAccountManager(nil)
+	if err != nil {
+		t.Fatalf("Failed to create account manager: %v", err)
+	}
+
+	um, err := NewUserManager(nil)
+	if err != nil {
+		t.Fatalf("Failed to create user manager: %v", err)
+	}
+
+	sign := func(types ...string) string {
+		claims, err := um.CreateUserClaims("test", nil)
+		if err != nil {
+			t.Fatalf("Failed to create user claims: %v", err)
+		}
+		SetAllowedConnectionTypes(claims, types...)
+		userJWT, err := am.SignUserJWT(claims)
+		if err != nil {
+			t.Fatalf("Failed to sign user JWT: %v", err)
+		}
+		return userJWT
+	}
+
+	tests := []struct {
+		name    string
+		userJWT string
+		types   []string
+		want    bool
+	}{
+		{
+			name:    "Unrestricted matches no types",
+			userJWT: sign(),
+			types:   nil,
+			want:    true,
+		},
+		{
+			name:    "Leafnode matches leafnode",
+			userJWT: sign(ConnectionTypeLeafNode),
+			types:   []string{ConnectionTypeLeafNode},
+			want:    true,
+		},
+		{
+			name:    "Unrestricted does not match leafnode",
+			userJWT: sign(),
+			types:   []string{ConnectionTypeLeafNode},
+			want:    false,
+		},
+		{
+			name:    "Leafnode does not match unrestricted",
+			userJWT: sign(ConnectionTypeLeafNode),
+			types:   nil,
+			want:    false,
+		},
+		{
+			name:    "Invalid JWT",
+			userJWT: "not-a-jwt",
+			types:   nil,
+			want:    false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := HasAllowedConnectionTypes(tt.userJWT, tt.types...); got != tt.want {
+				t.Errorf("HasAllowedConnectionTypes() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsBearerToken(t *testing.T) {
+	am, err := NewAccountManager(nil)
+	if err != nil {
+		t.Fatalf("Failed to create account manager: %v", err)
+	}
+
+	um, err := NewUserManager(nil)
+	if err != nil {
+		t.Fatalf("Failed to create user manager: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		bearer bool
+	}{
+		{name: "Bearer token", bearer: true},
+		{name: "Nonce signing user", bearer: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims, err := um.CreateUserClaims("test", nil)
+			if err != nil {
+				t.Fatalf("Failed to create user claims: %v", err)
+			}
+			claims.BearerToken = tt.bearer
+
+			userJWT, err := am.SignUserJWT(claims)
+			if err != nil {
+				t.Fatalf("Failed to sign user JWT: %v", err)
+			}
+
+			if got := IsBearerToken(userJWT); got != tt.bearer {
+				t.Errorf("IsBearerToken() = %v, want %v", got, tt.bearer)
+			}
+		})
+	}
+
+	if IsBearerToken("not-a-jwt") {
+		t.Error("IsBearerToken() = true for an invalid JWT")
+	}
+}
+
+func TestIssuingAccount(t *testing.T) {
+	am, err := NewAccountManager(nil)
+	if err != nil {
+		t.Fatalf("Failed to create account manager: %v", err)
+	}
+	accountPubKey, _ := am.GetPublicKey()
+	signingKey, _ := nkeys.CreateAccount()
+	signingSeed, _ := signingKey.Seed()
+
+	um, err := NewUserManager(nil)
+	if err != nil {
+		t.Fatalf("Failed to create user manager: %v", err)
+	}
+	claims, err := um.CreateUserClaims("test", nil)
+	if err != nil {
+		t.Fatalf("Failed to create user claims: %v", err)
+	}
+
+	userJWT, err := am.SignUserJWT(claims)
+	if err != nil {
+		t.Fatalf("Failed to sign user JWT: %v", err)
+	}
+	if got := IssuingAccount(userJWT); got != accountPubKey {
+		t.Errorf("IssuingAccount() = %s, want %s", got, accountPubKey)
+	}
+
+	userJWT, err = am.SignUserJWTWithSigningKey(claims, signingSeed)
+	if err != nil {
+		t.Fatalf("Failed to sign user JWT with signing key: %v", err)
+	}
+	if got := IssuingAccount(userJWT); got != accountPubKey {
+		t.Errorf("IssuingAccount() = %s for a signing key, want %s", got, accountPubKey)
+	}
+
+	if got := IssuingAccount("not-a-jwt"); got != "" {
+		t.Errorf("IssuingAccount() = %s for an invalid JWT", got)
+	}
+}
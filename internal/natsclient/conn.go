@@ -0,0 +1,553 @@
+// Package natsclient speaks enough of the NATS client protocol for the operator to publish, send
+// requests and subscribe: it has no reconnect logic, so callers open a connection per task.
+package natsclient
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/textproto"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nats-io/jwt/v2"
+)
+
+// DefaultTimeout bounds connecting and authenticating to the server
+const DefaultTimeout = 5 * time.Second
+
+// StatusHeader holds the status code of a message sent by the server itself, e.g. "503" when a
+// request has no responders
+const StatusHeader = "Status"
+
+// ErrNoResponders is returned by Request when nothing listens on the subject
+var ErrNoResponders = errors.New("no responders")
+
+// ErrClosed is returned once the connection is closed
+var ErrClosed = errors.New("connection closed")
+
+// Options configure a connection
+type Options struct {
+	// URL of the server, nats://host:port or tls://host:port
+	URL string
+
+	// Creds is the content of a creds file to authenticate with (optional)
+	Creds []byte
+
+	// TLSConfig is used for tls:// URLs and servers requiring TLS (optional)
+	TLSConfig *tls.Config
+
+	// Name of the client shown in the server monitoring endpoints
+	Name string
+
+	// Timeout bounds connecting and authenticating (defaults to DefaultTimeout)
+	Timeout time.Duration
+}
+
+// Msg is a message received on a subscription, or published with headers
+type Msg struct {
+	Subject string
+	Reply   string
+	Header  textproto.MIMEHeader
+	Data    []byte
+}
+
+// Subscription delivers the messages of a subject
+type Subscription struct {
+	conn *Conn
+	sid  int
+	msgs chan *Msg
+
+	// done is closed by Unsubscribe, so a message for it no longer blocks the read loop
+	done     chan struct{}
+	doneOnce sync.Once
+}
+
+// Conn is a connection to a NATS server
+type Conn struct {
+	conn net.Conn
+
+	// wmu serializes writes
+	wmu sync.Mutex
+
+	mu      sync.Mutex
+	subs    map[int]*Subscription
+	nextSID int
+	pongs   []chan error
+	lastErr error
+	err     error
+
+	// closed is closed by Close, done once the read loop returned
+	closed    chan struct{}
+	closeOnce sync.Once
+	done      chan struct{}
+}
+
+// serverInfo holds the INFO fields the client needs
+type serverInfo struct {
+	Nonce       string `json:"nonce"`
+	TLSRequired bool   `json:"tls_required"`
+	Headers     bool   `json:"headers"`
+}
+
+// connectOptions is the CONNECT payload
+type connectOptions struct {
+	Verbose      bool   `json:"verbose"`
+	Pedantic     bool   `json:"pedantic"`
+	Name         string `json:"name"`
+	Lang         string `json:"lang"`
+	Protocol     int    `json:"protocol"`
+	Headers      bool   `json:"headers"`
+	NoResponders bool   `json:"no_responders"`
+	JWT          string `json:"jwt,omitempty"`
+	Sig          string `json:"sig,omitempty"`
+}
+
+// Dial connects and authenticates to the server. The connection lives until Close, independently
+// of ctx, which only bounds the handshake.
+func Dial(ctx context.Context, opts Options) (*Conn, error) {
+	u, err := url.Parse(opts.URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid NATS URL: %w", err)
+	}
+	host := u.Host
+	if u.Port() == "" {
+		host = net.JoinHostPort(u.Hostname(), "4222")
+	}
+
+	timeout := opts.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", host)
+	if err != nil {
+		return nil, err
+	}
+	c, err := handshake(ctx, conn, u, opts)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("NATS server %s: %w", u.Host, err)
+	}
+	return c, nil
+}
+
+// handshake reads INFO, upgrades to TLS when needed, sends CONNECT and waits for the server to
+// accept it before the read loop starts
+func handshake(ctx context.Context, conn net.Conn, u *url.URL, opts Options) (*Conn, error) {
+	deadline, _ := ctx.Deadline()
+	if err := conn.SetDeadline(deadline); err != nil {
+		return nil, err
+	}
+
+	r := bufio.NewReader(conn)
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, fmt.Errorf("failed to read server INFO: %w", err)
+	}
+	infoJSON, ok := strings.CutPrefix(line, "INFO ")
+	if !ok {
+		return nil, fmt.Errorf("unexpected server greeting %q", strings.TrimSpace(line))
+	}
+	var info serverInfo
+	if err := json.Unmarshal([]byte(infoJSON), &info); err != nil {
+		return nil, fmt.Errorf("invalid server INFO: %w", err)
+	}
+
+	if u.Scheme == "tls" || info.TLSRequired {
+		config := &tls.Config{MinVersion: tls.VersionTLS12}
+		if opts.TLSConfig != nil {
+			config = opts.TLSConfig.Clone()
+		}
+		if config.ServerName == "" {
+			config.ServerName = u.Hostname()
+		}
+		tlsConn := tls.Client(conn, config)
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			return nil, fmt.Errorf("TLS handshake failed: %w", err)
+		}
+		conn = tlsConn
+		r = bufio.NewReader(conn)
+	}
+
+	name := opts.Name
+	if name == "" {
+		name = "nats-auth-operator"
+	}
+	connect := connectOptions{Name: name, Lang: "go", Protocol: 1, Headers: info.Headers, NoResponders: info.Headers}
+	if len(opts.Creds) > 0 {
+		if connect.JWT, connect.Sig, err = signNonce(opts.Creds, info.Nonce); err != nil {
+			return nil, err
+		}
+	}
+	connectJSON, err := json.Marshal(connect)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode CONNECT: %w", err)
+	}
+	if _, err := fmt.Fprintf(conn, "CONNECT %s\r\nPING\r\n", connectJSON); err != nil {
+		return nil, err
+	}
+
+	// The server answers PONG once CONNECT is accepted, or -ERR when authentication failed
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return nil, fmt.Errorf("failed to read server response: %w", err)
+		}
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "PONG":
+			if err := conn.SetDeadline(time.Time{}); err != nil {
+				return nil, err
+			}
+			c := &Conn{conn: conn, subs: map[int]*Subscription{}, closed: make(chan struct{}), done: make(chan struct{})}
+			go c.readLoop(r)
+			return c, nil
+		case strings.HasPrefix(line, "-ERR"):
+			return nil, serverError(line)
+		case line == "PING":
+			if _, err := conn.Write([]byte("PONG\r\n")); err != nil {
+				return nil, err
+			}
+		}
+	}
+}
+
+// signNonce returns the user JWT of a creds file and the signature of the server nonce
+func signNonce(creds []byte, nonce string) (string, string, error) {
+	userJWT, err := jwt.ParseDecoratedJWT(creds)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid creds: %w", err)
+	}
+	kp, err := jwt.ParseDecoratedNKey(creds)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid creds: %w", err)
+	}
+	sig, err := kp.Sign([]byte(nonce))
+	if err != nil {
+		return "", "", fmt.Errorf("failed to sign server nonce: %w", err)
+	}
+	return userJWT, base64.RawURLEncoding.EncodeToString(sig), nil
+}
+
+func serverError(line string) error {
+	return fmt.Errorf("server error: %s", strings.Trim(strings.TrimSpace(strings.TrimPrefix(line, "-ERR")), "'"))
+}
+
+// readLoop dispatches what the server sends until the connection fails or is closed
+func (c *Conn) readLoop(r *bufio.Reader) {
+	err := c.read(r)
+	c.mu.Lock()
+	if c.err == nil {
+		c.err = err
+	}
+	for _, pong := range c.pongs {
+		pong <- c.err
+	}
+	c.pongs = nil
+	for _, sub := range c.subs {
+		close(sub.msgs)
+	}
+	c.subs = map[int]*Subscription{}
+	c.mu.Unlock()
+	close(c.done)
+}
+
+func (c *Conn) read(r *bufio.Reader) error {
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return err
+		}
+		line = strings.TrimSpace(line)
+		op, args, _ := strings.Cut(line, " ")
+		switch strings.ToUpper(op) {
+		case "MSG", "HMSG":
+			msg, sid, err := readMsg(r, strings.ToUpper(op) == "HMSG", strings.Fields(args))
+			if err != nil {
+				return err
+			}
+			c.mu.Lock()
+			sub := c.subs[sid]
+			c.mu.Unlock()
+			if sub != nil {
+				select {
+				case sub.msgs <- msg:
+				case <-sub.done:
+				case <-c.closed:
+					return ErrClosed
+				}
+			}
+		case "PING":
+			if err := c.write([]byte("PONG\r\n")); err != nil {
+				return err
+			}
+		case "PONG":
+			c.mu.Lock()
+			if len(c.pongs) > 0 {
+				c.pongs[0] <- c.lastErr
+				c.pongs = c.pongs[1:]
+			}
+			c.lastErr = nil
+			c.mu.Unlock()
+		case "-ERR":
+			// The server closes the connection after most errors; a permission violation only
+			// fails the publish or subscription, which the next Flush reports
+			c.mu.Lock()
+			c.lastErr = serverError(line)
+			c.mu.Unlock()
+		}
+	}
+}
+
+// readMsg reads the payload of a MSG or HMSG with the arguments
+// <subject> <sid> [reply] [#header bytes] <#total bytes>
+func readMsg(r *bufio.Reader, headers bool, args []string) (*Msg, int, error) {
+	want := 3
+	if headers {
+		want = 4
+	}
+	if len(args) != want && len(args) != want+1 {
+		return nil, 0, fmt.Errorf("malformed message arguments %q", strings.Join(args, " "))
+	}
+	msg := &Msg{Subject: args[0]}
+	sid, err := strconv.Atoi(args[1])
+	if err != nil {
+		return nil, 0, fmt.Errorf("malformed message sid %q", args[1])
+	}
+	if len(args) == want+1 {
+		msg.Reply = args[2]
+	}
+	size, err := strconv.Atoi(args[len(args)-1])
+	if err != nil || size < 0 {
+		return nil, 0, fmt.Errorf("malformed message size %q", args[len(args)-1])
+	}
+	headerSize := 0
+	if headers {
+		if headerSize, err = strconv.Atoi(args[len(args)-2]); err != nil || headerSize < 0 || headerSize > size {
+			return nil, 0, fmt.Errorf("malformed message header size %q", args[len(args)-2])
+		}
+	}
+
+	payload := make([]byte, size+2)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return nil, 0, err
+	}
+	if headers {
+		if msg.Header, err = decodeHeader(payload[:headerSize]); err != nil {
+			return nil, 0, err
+		}
+	}
+	msg.Data = payload[headerSize:size]
+	return msg, sid, nil
+}
+
+// decodeHeader parses a NATS/1.0 header block; the status code of the first line is kept under
+// StatusHeader
+func decodeHeader(data []byte) (textproto.MIMEHeader, error) {
+	r := textproto.NewReader(bufio.NewReader(bytes.NewReader(data)))
+	line, err := r.ReadLine()
+	if err != nil || !strings.HasPrefix(line, "NATS/1.0") {
+		return nil, fmt.Errorf("malformed message header %q", line)
+	}
+	header, err := r.ReadMIMEHeader()
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("malformed message header: %w", err)
+	}
+	if header == nil {
+		header = textproto.MIMEHeader{}
+	}
+	if status := strings.Fields(strings.TrimPrefix(line, "NATS/1.0")); len(status) > 0 {
+		header.Set(StatusHeader, status[0])
+	}
+	return header, nil
+}
+
+// encodeHeader renders a NATS/1.0 header block
+func encodeHeader(header textproto.MIMEHeader) []byte {
+	var b bytes.Buffer
+	b.WriteString("NATS/1.0\r\n")
+	for key, values := range header {
+		for _, value := range values {
+			fmt.Fprintf(&b, "%s: %s\r\n", key, value)
+		}
+	}
+	b.WriteString("\r\n")
+	return b.Bytes()
+}
+
+func (c *Conn) write(data []byte) error {
+	c.wmu.Lock()
+	defer c.wmu.Unlock()
+	_, err := c.conn.Write(data)
+	return err
+}
+
+// Publish sends data to subject
+func (c *Conn) Publish(subject string, data []byte) error {
+	return c.PublishMsg(&Msg{Subject: subject, Data: data})
+}
+
+// PublishMsg sends a message, with its headers and reply subject when set
+func (c *Conn) PublishMsg(msg *Msg) error {
+	if err := c.Err(); err != nil {
+		return err
+	}
+	reply := ""
+	if msg.Reply != "" {
+		reply = " " + msg.Reply
+	}
+	var b bytes.Buffer
+	if len(msg.Header) > 0 {
+		header := encodeHeader(msg.Header)
+		fmt.Fprintf(&b, "HPUB %s%s %d %d\r\n", msg.Subject, reply, len(header), len(header)+len(msg.Data))
+		b.Write(header)
+	} else {
+		fmt.Fprintf(&b, "PUB %s%s %d\r\n", msg.Subject, reply, len(msg.Data))
+	}
+	b.Write(msg.Data)
+	b.WriteString("\r\n")
+	return c.write(b.Bytes())
+}
+
+// Subscribe delivers the messages of subject, buffering up to size of them; the server is not
+// read from while the buffer is full
+func (c *Conn) Subscribe(subject string, size int) (*Subscription, error) {
+	c.mu.Lock()
+	if c.err != nil {
+		c.mu.Unlock()
+		return nil, c.err
+	}
+	c.nextSID++
+	sub := &Subscription{conn: c, sid: c.nextSID, msgs: make(chan *Msg, size), done: make(chan struct{})}
+	c.subs[sub.sid] = sub
+	c.mu.Unlock()
+
+	if err := c.write([]byte(fmt.Sprintf("SUB %s %d\r\n", subject, sub.sid))); err != nil {
+		return nil, err
+	}
+	return sub, nil
+}
+
+// Next returns the next message, or an error once ctx is done or the connection closed
+func (s *Subscription) Next(ctx context.Context) (*Msg, error) {
+	select {
+	case msg, ok := <-s.msgs:
+		if !ok {
+			if err := s.conn.Err(); err != nil {
+				return nil, err
+			}
+			return nil, ErrClosed
+		}
+		return msg, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Unsubscribe stops the delivery of messages
+func (s *Subscription) Unsubscribe() error {
+	s.doneOnce.Do(func() { close(s.done) })
+	s.conn.mu.Lock()
+	_, ok := s.conn.subs[s.sid]
+	delete(s.conn.subs, s.sid)
+	s.conn.mu.Unlock()
+	if !ok || s.conn.Err() != nil {
+		return nil
+	}
+	return s.conn.write([]byte(fmt.Sprintf("UNSUB %d\r\n", s.sid)))
+}
+
+// NewInbox returns a unique subject to receive replies on
+func NewInbox() string {
+	var b [11]byte
+	_, _ = rand.Read(b[:])
+	return "_INBOX." + hex.EncodeToString(b[:])
+}
+
+// Request sends data to subject and returns the first reply
+func (c *Conn) Request(ctx context.Context, subject string, data []byte) (*Msg, error) {
+	return c.RequestMsg(ctx, &Msg{Subject: subject, Data: data})
+}
+
+// RequestMsg sends a message and returns the first reply
+func (c *Conn) RequestMsg(ctx context.Context, msg *Msg) (*Msg, error) {
+	inbox := NewInbox()
+	sub, err := c.Subscribe(inbox, 1)
+	if err != nil {
+		return nil, err
+	}
+	defer sub.Unsubscribe()
+
+	req := *msg
+	req.Reply = inbox
+	if err := c.PublishMsg(&req); err != nil {
+		return nil, err
+	}
+	reply, err := sub.Next(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("no reply on %s: %w", msg.Subject, err)
+	}
+	if len(reply.Data) == 0 && reply.Header.Get(StatusHeader) == "503" {
+		return nil, fmt.Errorf("%s: %w", msg.Subject, ErrNoResponders)
+	}
+	return reply, nil
+}
+
+// Flush waits until the server processed everything sent so far, and returns the error it
+// reported for it, e.g. a permission violation
+func (c *Conn) Flush(ctx context.Context) error {
+	pong := make(chan error, 1)
+	c.mu.Lock()
+	if c.err != nil {
+		c.mu.Unlock()
+		return c.err
+	}
+	c.pongs = append(c.pongs, pong)
+	c.mu.Unlock()
+
+	if err := c.write([]byte("PING\r\n")); err != nil {
+		return err
+	}
+	select {
+	case err := <-pong:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Err returns the error that ended the connection, or nil while it is open
+func (c *Conn) Err() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.err
+}
+
+// Close closes the connection
+func (c *Conn) Close() error {
+	c.mu.Lock()
+	if c.err == nil {
+		c.err = ErrClosed
+	}
+	c.mu.Unlock()
+	c.closeOnce.Do(func() { close(c.closed) })
+	err := c.conn.Close()
+	<-c.done
+	return err
+}
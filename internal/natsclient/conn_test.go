@@ -0,0 +1,194 @@
+package natsclient
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// responder answers a request on subject with a reply, or nil for no responders
+type responder func(subject string, header textproto.MIMEHeader, data []byte) []byte
+
+// fakeConn is the connection of a client to a fake server
+type fakeConn struct {
+	conn net.Conn
+	sids map[string]string
+}
+
+// send delivers a message to the subscription of the client on subject; a StatusHeader becomes
+// the status code of the header block
+func (c *fakeConn) send(subject, reply string, header textproto.MIMEHeader, data []byte) {
+	c.deliver(subject, subject, reply, header, data)
+}
+
+// deliver sends a message on subject to the subscription of the client on inbox, like
+// JetStream delivers to a push consumer
+func (c *fakeConn) deliver(inbox, subject, reply string, header textproto.MIMEHeader, data []byte) {
+	if reply != "" {
+		reply = " " + reply
+	}
+	if header == nil {
+		fmt.Fprintf(c.conn, "MSG %s %s%s %d\r\n%s\r\n", subject, c.sids[inbox], reply, len(data), data)
+		return
+	}
+	var b strings.Builder
+	b.WriteString("NATS/1.0")
+	if status := header.Get(StatusHeader); status != "" {
+		b.WriteString(" " + status)
+	}
+	b.WriteString("\r\n")
+	for key, values := range header {
+		if key != StatusHeader {
+			for _, value := range values {
+				fmt.Fprintf(&b, "%s: %s\r\n", key, value)
+			}
+		}
+	}
+	b.WriteString("\r\n")
+	fmt.Fprintf(c.conn, "HMSG %s %s%s %d %d\r\n%s%s\r\n", subject, c.sids[inbox], reply, b.Len(), b.Len()+len(data), b.String(), data)
+}
+
+// serve accepts one connection and speaks enough of the protocol to hand every published
+// message to handle
+func serve(t *testing.T, handle func(c *fakeConn, subject, reply string, header textproto.MIMEHeader, data []byte)) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		fmt.Fprint(conn, "INFO {\"server_id\":\"test\",\"headers\":true,\"nonce\":\"abc\"}\r\n")
+
+		c := &fakeConn{conn: conn, sids: map[string]string{}}
+		r := bufio.NewReader(conn)
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			fields := strings.Fields(line)
+			switch fields[0] {
+			case "PING":
+				fmt.Fprint(conn, "PONG\r\n")
+			case "SUB":
+				c.sids[fields[1]] = fields[2]
+			case "PUB", "HPUB":
+				size, _ := strconv.Atoi(fields[len(fields)-1])
+				payload := make([]byte, size+2)
+				if _, err := io.ReadFull(r, payload); err != nil {
+					return
+				}
+				var header textproto.MIMEHeader
+				data := payload[:size]
+				if fields[0] == "HPUB" {
+					headerSize, _ := strconv.Atoi(fields[len(fields)-2])
+					header, _ = decodeHeader(payload[:headerSize])
+					data = payload[headerSize:size]
+				}
+				reply := ""
+				if len(fields) == 4 && fields[0] == "PUB" || len(fields) == 5 {
+					reply = fields[2]
+				}
+				handle(c, fields[1], reply, header, data)
+			}
+		}
+	}()
+	return "nats://" + ln.Addr().String()
+}
+
+// fakeServer answers every request on its reply subject with respond
+func fakeServer(t *testing.T, respond responder) string {
+	return serve(t, func(c *fakeConn, subject, reply string, header textproto.MIMEHeader, data []byte) {
+		if reply == "" {
+			return
+		}
+		if answer := respond(subject, header, data); answer != nil {
+			c.send(reply, "", nil, answer)
+		} else {
+			c.send(reply, "", textproto.MIMEHeader{StatusHeader: {"503"}}, nil)
+		}
+	})
+}
+
+func TestRequest(t *testing.T) {
+	url := fakeServer(t, func(subject string, header textproto.MIMEHeader, data []byte) []byte {
+		switch subject {
+		case "echo":
+			return []byte(header.Get("Operation") + ":" + string(data))
+		default:
+			return nil
+		}
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	conn, err := Dial(ctx, Options{URL: url})
+	if err != nil {
+		t.Fatalf("Dial() error = %v", err)
+	}
+	defer conn.Close()
+
+	reply, err := conn.RequestMsg(ctx, &Msg{Subject: "echo", Header: textproto.MIMEHeader{"Operation": {"PUT"}}, Data: []byte("hello")})
+	if err != nil {
+		t.Fatalf("RequestMsg() error = %v", err)
+	}
+	if got := string(reply.Data); got != "PUT:hello" {
+		t.Errorf("RequestMsg() = %q, want %q", got, "PUT:hello")
+	}
+
+	if _, err := conn.Request(ctx, "nobody", []byte("hello")); !errors.Is(err, ErrNoResponders) {
+		t.Errorf("Request() error = %v, want %v", err, ErrNoResponders)
+	}
+	if err := conn.Flush(ctx); err != nil {
+		t.Errorf("Flush() error = %v", err)
+	}
+}
+
+func TestDialAuthorizationViolation(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer ln.Close()
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		fmt.Fprint(conn, "INFO {\"server_id\":\"test\"}\r\n")
+		r := bufio.NewReader(conn)
+		_, _ = r.ReadString('\n')
+		fmt.Fprint(conn, "-ERR 'Authorization Violation'\r\n")
+	}()
+
+	_, err = Dial(context.Background(), Options{URL: "nats://" + ln.Addr().String(), Timeout: 2 * time.Second})
+	if err == nil || !strings.Contains(err.Error(), "Authorization Violation") {
+		t.Errorf("Dial() error = %v, want the authorization violation", err)
+	}
+}
+
+func TestDecodeHeader(t *testing.T) {
+	header, err := decodeHeader([]byte("NATS/1.0 404 No Messages\r\nKV-Operation: DEL\r\n\r\n"))
+	if err != nil {
+		t.Fatalf("decodeHeader() error = %v", err)
+	}
+	if header.Get(StatusHeader) != "404" || header.Get("KV-Operation") != "DEL" {
+		t.Errorf("decodeHeader() = %v, want status 404 and KV-Operation DEL", header)
+	}
+}
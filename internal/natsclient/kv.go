@@ -0,0 +1,211 @@
+package natsclient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// KVOperationHeader marks deletes and purges of a key-value entry
+const KVOperationHeader = "KV-Operation"
+
+// Operations of a key-value entry
+const (
+	KVPut    = "PUT"
+	KVDelete = "DEL"
+	KVPurge  = "PURGE"
+)
+
+// kvHeartbeat is how often the server signals an idle watch; a watch that hears nothing for
+// three intervals is considered lost
+const kvHeartbeat = 5 * time.Second
+
+// KV is a JetStream key-value bucket. Entries are stored on the stream KV_<bucket> under the
+// subjects $KV.<bucket>.<key>, so keys are dot-separated tokens.
+type KV struct {
+	Conn   *Conn
+	Bucket string
+}
+
+// apiError is the error of a JetStream API response or publish acknowledgement
+type apiError struct {
+	Code        int    `json:"code"`
+	ErrCode     int    `json:"err_code"`
+	Description string `json:"description"`
+}
+
+func (e *apiError) Error() string {
+	return fmt.Sprintf("%s (code %d)", e.Description, e.Code)
+}
+
+// pubAck is the answer of the stream to a publish
+type pubAck struct {
+	Stream string    `json:"stream"`
+	Seq    uint64    `json:"seq"`
+	Error  *apiError `json:"error,omitempty"`
+}
+
+func (kv *KV) subject(key string) string {
+	return "$KV." + kv.Bucket + "." + key
+}
+
+// Put stores value under key and waits for the stream to acknowledge it
+func (kv *KV) Put(ctx context.Context, key string, value []byte) error {
+	return kv.publish(ctx, &Msg{Subject: kv.subject(key), Data: value})
+}
+
+// Delete marks key deleted; watchers see an entry with the KVDelete operation
+func (kv *KV) Delete(ctx context.Context, key string) error {
+	return kv.publish(ctx, &Msg{Subject: kv.subject(key), Header: textproto.MIMEHeader{KVOperationHeader: {KVDelete}}})
+}
+
+func (kv *KV) publish(ctx context.Context, msg *Msg) error {
+	reply, err := kv.Conn.RequestMsg(ctx, msg)
+	if err != nil {
+		return fmt.Errorf("key-value bucket %s: %w", kv.Bucket, err)
+	}
+	var ack pubAck
+	if err := json.Unmarshal(reply.Data, &ack); err != nil {
+		return fmt.Errorf("invalid acknowledgement of key-value bucket %s: %w", kv.Bucket, err)
+	}
+	if ack.Error != nil {
+		return fmt.Errorf("key-value bucket %s: %w", kv.Bucket, ack.Error)
+	}
+	return nil
+}
+
+// KVEntry is an entry delivered by a watch
+type KVEntry struct {
+	Key       string
+	Value     []byte
+	Operation string
+
+	// Pending is the number of entries the watch has yet to deliver; 0 once it caught up
+	Pending uint64
+}
+
+// KVWatcher delivers the latest entry of every key, then every change
+type KVWatcher struct {
+	sub *Subscription
+
+	// Caught is true when the bucket was empty as the watch started
+	Caught bool
+}
+
+// consumerCreateRequest creates the ephemeral push consumer of a watch
+type consumerCreateRequest struct {
+	Stream string         `json:"stream_name"`
+	Config consumerConfig `json:"config"`
+}
+
+type consumerConfig struct {
+	DeliverSubject string        `json:"deliver_subject"`
+	DeliverPolicy  string        `json:"deliver_policy"`
+	AckPolicy      string        `json:"ack_policy"`
+	ReplayPolicy   string        `json:"replay_policy"`
+	FilterSubject  string        `json:"filter_subject"`
+	IdleHeartbeat  time.Duration `json:"idle_heartbeat"`
+	MemoryStorage  bool          `json:"mem_storage"`
+	Replicas       int           `json:"num_replicas"`
+}
+
+type consumerCreateResponse struct {
+	NumPending uint64    `json:"num_pending"`
+	Error      *apiError `json:"error,omitempty"`
+}
+
+// Watch starts delivering the latest entry of every key of the bucket, then every change
+func (kv *KV) Watch(ctx context.Context) (*KVWatcher, error) {
+	inbox := NewInbox()
+	sub, err := kv.Conn.Subscribe(inbox, 256)
+	if err != nil {
+		return nil, err
+	}
+	stream := "KV_" + kv.Bucket
+	req, err := json.Marshal(consumerCreateRequest{
+		Stream: stream,
+		Config: consumerConfig{
+			DeliverSubject: inbox,
+			DeliverPolicy:  "last_per_subject",
+			AckPolicy:      "none",
+			ReplayPolicy:   "instant",
+			FilterSubject:  kv.subject(">"),
+			IdleHeartbeat:  kvHeartbeat,
+			MemoryStorage:  true,
+			Replicas:       1,
+		},
+	})
+	if err != nil {
+		_ = sub.Unsubscribe()
+		return nil, err
+	}
+	reply, err := kv.Conn.Request(ctx, "$JS.API.CONSUMER.CREATE."+stream, req)
+	if err != nil {
+		_ = sub.Unsubscribe()
+		return nil, fmt.Errorf("failed to watch key-value bucket %s: %w", kv.Bucket, err)
+	}
+	var resp consumerCreateResponse
+	if err := json.Unmarshal(reply.Data, &resp); err != nil {
+		_ = sub.Unsubscribe()
+		return nil, fmt.Errorf("invalid consumer of key-value bucket %s: %w", kv.Bucket, err)
+	}
+	if resp.Error != nil {
+		_ = sub.Unsubscribe()
+		return nil, fmt.Errorf("failed to watch key-value bucket %s: %w", kv.Bucket, resp.Error)
+	}
+	return &KVWatcher{sub: sub, Caught: resp.NumPending == 0}, nil
+}
+
+// Next returns the next entry. Heartbeats are skipped; a watch that stays silent for three
+// heartbeat intervals fails, as the server lost its consumer.
+func (w *KVWatcher) Next(ctx context.Context) (*KVEntry, error) {
+	for {
+		nextCtx, cancel := context.WithTimeout(ctx, 3*kvHeartbeat)
+		msg, err := w.sub.Next(nextCtx)
+		cancel()
+		if err != nil {
+			if ctx.Err() == nil && nextCtx.Err() != nil {
+				return nil, fmt.Errorf("key-value watch missed its heartbeats")
+			}
+			return nil, err
+		}
+		if msg.Header.Get(StatusHeader) != "" {
+			// Idle heartbeat or flow control
+			continue
+		}
+		_, key, ok := strings.Cut(strings.TrimPrefix(msg.Subject, "$KV."), ".")
+		if !ok {
+			continue
+		}
+		entry := &KVEntry{Key: key, Value: msg.Data, Operation: KVPut, Pending: ackPending(msg.Reply)}
+		if op := msg.Header.Get(KVOperationHeader); op != "" {
+			entry.Operation = op
+		}
+		return entry, nil
+	}
+}
+
+// Stop ends the watch; the server removes the consumer once its interest is gone
+func (w *KVWatcher) Stop() error {
+	return w.sub.Unsubscribe()
+}
+
+// ackPending returns the pending count of the reply subject of a JetStream message,
+// $JS.ACK.<stream>.<consumer>.<delivered>.<stream seq>.<consumer seq>.<time>.<pending>, or its
+// form with the domain and account hash after $JS.ACK
+func ackPending(reply string) uint64 {
+	tokens := strings.Split(reply, ".")
+	index := 8
+	if len(tokens) >= 11 {
+		index = 10
+	}
+	if len(tokens) <= index {
+		return 0
+	}
+	pending, _ := strconv.ParseUint(tokens[index], 10, 64)
+	return pending
+}
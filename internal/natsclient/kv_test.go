@@ -0,0 +1,112 @@
+package natsclient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/textproto"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeBucket serves the key-value bucket "accounts" on a fake server: it keeps the latest entry
+// of every key and delivers them, then every put, to the consumer of a watch
+func fakeBucket(t *testing.T) string {
+	var keys []string
+	entries := map[string]*Msg{}
+	deliver := ""
+	seq := 0
+
+	return serve(t, func(c *fakeConn, subject, reply string, header textproto.MIMEHeader, data []byte) {
+		switch {
+		case strings.HasPrefix(subject, "$KV.accounts."):
+			seq++
+			if _, ok := entries[subject]; !ok {
+				keys = append(keys, subject)
+			}
+			entries[subject] = &Msg{Subject: subject, Header: header, Data: data}
+			c.send(reply, "", nil, []byte(fmt.Sprintf(`{"stream":"KV_accounts","seq":%d}`, seq)))
+			if deliver != "" {
+				c.deliver(deliver, subject, fmt.Sprintf("$JS.ACK.KV_accounts.watch.1.%d.%d.0.0", seq, seq), header, data)
+			}
+		case subject == "$JS.API.CONSUMER.CREATE.KV_accounts":
+			var req consumerCreateRequest
+			_ = json.Unmarshal(data, &req)
+			deliver = req.Config.DeliverSubject
+			c.send(reply, "", nil, []byte(fmt.Sprintf(`{"num_pending":%d}`, len(keys))))
+			for i, key := range keys {
+				entry := entries[key]
+				c.deliver(deliver, key, fmt.Sprintf("$JS.ACK.KV_accounts.watch.1.%d.%d.0.%d", i+1, i+1, len(keys)-i-1), entry.Header, entry.Data)
+			}
+		case reply != "":
+			c.send(reply, "", nil, []byte(`{"error":{"code":404,"err_code":10059,"description":"stream not found"}}`))
+		}
+	})
+}
+
+func TestKV(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	conn, err := Dial(ctx, Options{URL: fakeBucket(t)})
+	if err != nil {
+		t.Fatalf("Dial() error = %v", err)
+	}
+	defer conn.Close()
+
+	kv := &KV{Conn: conn, Bucket: "accounts"}
+	if err := kv.Put(ctx, "first", []byte("1")); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if err := kv.Put(ctx, "second", []byte("2")); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if err := kv.Delete(ctx, "first"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+
+	watcher, err := kv.Watch(ctx)
+	if err != nil {
+		t.Fatalf("Watch() error = %v", err)
+	}
+	defer watcher.Stop()
+	if watcher.Caught {
+		t.Error("Watch() of a bucket with entries is caught up")
+	}
+	for _, want := range []KVEntry{
+		{Key: "first", Operation: KVDelete, Pending: 1},
+		{Key: "second", Value: []byte("2"), Operation: KVPut},
+	} {
+		entry, err := watcher.Next(ctx)
+		if err != nil {
+			t.Fatalf("Next() error = %v", err)
+		}
+		if entry.Key != want.Key || string(entry.Value) != string(want.Value) || entry.Operation != want.Operation || entry.Pending != want.Pending {
+			t.Errorf("Next() = %+v, want %+v", entry, want)
+		}
+	}
+
+	if err := kv.Put(ctx, "third", []byte("3")); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if entry, err := watcher.Next(ctx); err != nil || entry.Key != "third" {
+		t.Errorf("Next() = %+v, %v, want the put of third", entry, err)
+	}
+
+	other := &KV{Conn: conn, Bucket: "missing"}
+	if _, err := other.Watch(ctx); err == nil || !strings.Contains(err.Error(), "stream not found") {
+		t.Errorf("Watch() of a missing bucket error = %v, want stream not found", err)
+	}
+}
+
+func TestAckPending(t *testing.T) {
+	for reply, want := range map[string]uint64{
+		"$JS.ACK.KV_accounts.watch.1.5.5.1700000000.3":                    3,
+		"$JS.ACK.hub.ACCHASH.KV_accounts.watch.1.5.5.1700000000.7.random": 7,
+		"": 0,
+	} {
+		if got := ackPending(reply); got != want {
+			t.Errorf("ackPending(%q) = %d, want %d", reply, got, want)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package publish
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jradikk/nats-auth-operator/internal/backup"
+	"github.com/jradikk/nats-auth-operator/internal/natsclient"
+	"github.com/jradikk/nats-auth-operator/internal/resolver"
+)
+
+// AccountMetadata describes an account next to its JWT in a key-value bucket
+type AccountMetadata struct {
+	Namespace   string `json:"namespace"`
+	Name        string `json:"name"`
+	Fingerprint string `json:"fingerprint"`
+}
+
+// KV puts account JWTs into a JetStream key-value bucket, which leaf clusters replicate and the
+// resolver agent materializes in their full-resolver directories. JWTs are put under
+// accounts.<public key> and the operator JWT under operator.
+type KV struct {
+	// Options of the connection; the user needs to publish to the bucket
+	Options natsclient.Options
+
+	// Bucket is the name of the key-value bucket, which must exist
+	Bucket string
+
+	// Timeout bounds one put (defaults to DefaultTimeout)
+	Timeout time.Duration
+
+	// OperatorJWT is put with the first account JWT
+	OperatorJWT string
+
+	// Metadata of the accounts by public key, put sealed to Recipients under
+	// metadata.<public key>; not put without recipients
+	Metadata   map[string]AccountMetadata
+	Recipients []string
+
+	operatorPut bool
+}
+
+// do runs f on a new connection to the bucket
+func (k *KV) do(ctx context.Context, f func(ctx context.Context, kv *natsclient.KV) error) error {
+	timeout := k.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	conn, err := natsclient.Dial(ctx, k.Options)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	return f(ctx, &natsclient.KV{Conn: conn, Bucket: k.Bucket})
+}
+
+// Publish puts the JWT of the account and its sealed metadata
+func (k *KV) Publish(ctx context.Context, accountID, token string) error {
+	return k.do(ctx, func(ctx context.Context, kv *natsclient.KV) error {
+		if !k.operatorPut && k.OperatorJWT != "" {
+			if err := kv.Put(ctx, resolver.KVOperatorKey, []byte(k.OperatorJWT)); err != nil {
+				return err
+			}
+			k.operatorPut = true
+		}
+		if err := kv.Put(ctx, resolver.KVAccountPrefix+accountID, []byte(token)); err != nil {
+			return err
+		}
+
+		metadata, ok := k.Metadata[accountID]
+		if !ok || len(k.Recipients) == 0 {
+			return nil
+		}
+		data, err := json.Marshal(metadata)
+		if err != nil {
+			return err
+		}
+		sealed, err := backup.Seal(data, k.Recipients)
+		if err != nil {
+			return fmt.Errorf("failed to seal metadata of account %s: %w", accountID, err)
+		}
+		return kv.Put(ctx, resolver.KVMetadataPrefix+accountID, sealed)
+	})
+}
+
+// Delete marks the JWT of the account and its metadata deleted
+func (k *KV) Delete(ctx context.Context, accountID string) error {
+	return k.do(ctx, func(ctx context.Context, kv *natsclient.KV) error {
+		if err := kv.Delete(ctx, resolver.KVAccountPrefix+accountID); err != nil {
+			return err
+		}
+		if len(k.Recipients) == 0 {
+			return nil
+		}
+		return kv.Delete(ctx, resolver.KVMetadataPrefix+accountID)
+	})
+}
@@ -0,0 +1,163 @@
+package publish
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// DefaultTimeout bounds one call to a target when the publisher has no client of its own
+const DefaultTimeout = 10 * time.Second
+
+// maxErrorSize caps the error body read from a target
+const maxErrorSize = 1 << 10
+
+// Publisher mirrors signed account JWTs to a store read by an external account resolver
+type Publisher interface {
+	// Publish stores the JWT of the account
+	Publish(ctx context.Context, accountID, token string) error
+	// Delete removes the JWT of an account that is no longer published
+	Delete(ctx context.Context, accountID string) error
+}
+
+// AccountServer publishes to a nats-account-server, which accepts account JWTs on
+// POST /jwt/v1/accounts/<public key>
+type AccountServer struct {
+	// URL of the account server, e.g. http://nats-account-server:9090
+	URL string
+
+	// Client performs the calls; a client with DefaultTimeout when nil
+	Client *http.Client
+}
+
+// Publish posts the JWT to the account server
+func (s *AccountServer) Publish(ctx context.Context, accountID, token string) error {
+	url := strings.TrimSuffix(s.URL, "/") + "/jwt/v1/accounts/" + accountID
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, strings.NewReader(token))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "text/plain")
+	return do(httpClient(s.Client), req, "account server")
+}
+
+// Delete is a no-op: the account server has no delete operation, so an account removed from the
+// operator stays served until its JWT expires or is replaced
+func (s *AccountServer) Delete(context.Context, string) error {
+	return nil
+}
+
+// Bucket publishes to an S3-compatible bucket, e.g. Amazon S3, MinIO or Google Cloud Storage
+// through its XML API with HMAC keys. Each JWT is stored as <Prefix><account public key>.jwt.
+type Bucket struct {
+	// Endpoint is the base URL of the storage service, e.g. https://s3.eu-west-1.amazonaws.com
+	Endpoint string
+	// Name of the bucket, addressed path-style under Endpoint
+	Name string
+	// Region the requests are signed for
+	Region string
+	// Prefix of the object keys, e.g. "accounts/"
+	Prefix string
+
+	Credentials Credentials
+
+	// Client performs the calls; a client with DefaultTimeout when nil
+	Client *http.Client
+
+	// now returns the signing time; time.Now when nil
+	now func() time.Time
+}
+
+// ObjectKey returns the key the JWT of the account is stored under
+func (b *Bucket) ObjectKey(accountID string) string {
+	return b.Prefix + accountID + ".jwt"
+}
+
+// Publish uploads the JWT with PUT
+func (b *Bucket) Publish(ctx context.Context, accountID, token string) error {
+	req, err := b.request(ctx, http.MethodPut, accountID, []byte(token))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/jwt")
+	return do(httpClient(b.Client), req, "bucket")
+}
+
+// Delete removes the JWT with DELETE; a missing object is not an error
+func (b *Bucket) Delete(ctx context.Context, accountID string) error {
+	req, err := b.request(ctx, http.MethodDelete, accountID, nil)
+	if err != nil {
+		return err
+	}
+	err = do(httpClient(b.Client), req, "bucket")
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusNotFound {
+		return nil
+	}
+	return err
+}
+
+// request builds a signed request for the object of the account
+func (b *Bucket) request(ctx context.Context, method, accountID string, body []byte) (*http.Request, error) {
+	url := strings.TrimSuffix(b.Endpoint, "/") + "/" + b.Name + "/" + b.ObjectKey(accountID)
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now
+	if b.now != nil {
+		now = b.now
+	}
+	payloadHash := hashHex(body)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	signV4(req, payloadHash, b.Credentials, b.Region, "s3", now())
+	return req, nil
+}
+
+// StatusError is a target answering with an unexpected status
+type StatusError struct {
+	Target     string
+	StatusCode int
+	Status     string
+	Message    string
+}
+
+func (e *StatusError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("%s answered %s", e.Target, e.Status)
+	}
+	return fmt.Sprintf("%s answered %s: %s", e.Target, e.Status, e.Message)
+}
+
+func httpClient(client *http.Client) *http.Client {
+	if client == nil {
+		return &http.Client{Timeout: DefaultTimeout}
+	}
+	return client
+}
+
+// do sends the request and turns a status outside 2xx into a *StatusError
+func do(client *http.Client, req *http.Request, target string) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s request failed: %w", target, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+	message, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorSize))
+	return &StatusError{
+		Target:     target,
+		StatusCode: resp.StatusCode,
+		Status:     resp.Status,
+		Message:    strings.TrimSpace(string(message)),
+	}
+}
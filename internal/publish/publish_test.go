@@ -0,0 +1,175 @@
+package publish
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/afero"
+
+	"github.com/jradikk/nats-auth-operator/internal/resolver"
+)
+
+func TestSignV4(t *testing.T) {
+	// get-vanilla of the AWS Signature Version 4 test suite
+	req, _ := http.NewRequest(http.MethodGet, "https://example.amazonaws.com/", nil)
+	now := time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC)
+	creds := Credentials{AccessKeyID: "AKIDEXAMPLE", SecretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY"}
+
+	signV4(req, hashHex(nil), creds, "us-east-1", "service", now)
+
+	want := "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, " +
+		"SignedHeaders=host;x-amz-date, Signature=5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31"
+	if got := req.Header.Get("Authorization"); got != want {
+		t.Errorf("Authorization = %s, want %s", got, want)
+	}
+	if got := req.Header.Get("X-Amz-Date"); got != "20150830T123600Z" {
+		t.Errorf("X-Amz-Date = %s", got)
+	}
+}
+
+// recorded is a request received by the test server
+type recorded struct {
+	method string
+	path   string
+	body   string
+	header http.Header
+}
+
+// target answers every request with status and records it
+func target(t *testing.T, status int) (*httptest.Server, *[]recorded) {
+	t.Helper()
+	var requests []recorded
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests = append(requests, recorded{method: r.Method, path: r.URL.Path, body: string(body), header: r.Header.Clone()})
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte("denied"))
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &requests
+}
+
+func TestAccountServer(t *testing.T) {
+	ctx := context.Background()
+	srv, requests := target(t, http.StatusOK)
+	s := &AccountServer{URL: srv.URL + "/"}
+
+	if err := s.Publish(ctx, "AACCOUNT", "eyJ.token"); err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+	if err := s.Delete(ctx, "AACCOUNT"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if len(*requests) != 1 {
+		t.Fatalf("requests = %d, want 1 (delete is a no-op)", len(*requests))
+	}
+	got := (*requests)[0]
+	if got.method != http.MethodPost || got.path != "/jwt/v1/accounts/AACCOUNT" || got.body != "eyJ.token" {
+		t.Errorf("request = %s %s %q", got.method, got.path, got.body)
+	}
+}
+
+func TestBucket(t *testing.T) {
+	ctx := context.Background()
+	now := func() time.Time { return time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC) }
+
+	tests := []struct {
+		name       string
+		status     int
+		call       func(b *Bucket) error
+		wantMethod string
+		wantBody   string
+		wantErr    string
+	}{
+		{
+			name:       "Publish",
+			status:     http.StatusOK,
+			call:       func(b *Bucket) error { return b.Publish(ctx, "AACCOUNT", "eyJ.token") },
+			wantMethod: http.MethodPut,
+			wantBody:   "eyJ.token",
+		},
+		{
+			name:       "Delete",
+			status:     http.StatusNoContent,
+			call:       func(b *Bucket) error { return b.Delete(ctx, "AACCOUNT") },
+			wantMethod: http.MethodDelete,
+		},
+		{
+			name:       "Delete a missing object",
+			status:     http.StatusNotFound,
+			call:       func(b *Bucket) error { return b.Delete(ctx, "AACCOUNT") },
+			wantMethod: http.MethodDelete,
+		},
+		{
+			name:       "Refused",
+			status:     http.StatusForbidden,
+			call:       func(b *Bucket) error { return b.Publish(ctx, "AACCOUNT", "eyJ.token") },
+			wantMethod: http.MethodPut,
+			wantBody:   "eyJ.token",
+			wantErr:    "bucket answered 403 Forbidden: denied",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, requests := target(t, tt.status)
+			b := &Bucket{
+				Endpoint:    srv.URL,
+				Name:        "nats",
+				Region:      "eu-west-1",
+				Prefix:      "accounts/",
+				Credentials: Credentials{AccessKeyID: "AKID", SecretAccessKey: "secret"},
+				now:         now,
+			}
+
+			err := tt.call(b)
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("error = %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || err.Error() != tt.wantErr) {
+				t.Fatalf("error = %v, want %q", err, tt.wantErr)
+			}
+
+			got := (*requests)[0]
+			if got.method != tt.wantMethod || got.path != "/nats/accounts/AACCOUNT.jwt" || got.body != tt.wantBody {
+				t.Errorf("request = %s %s %q", got.method, got.path, got.body)
+			}
+			if got.header.Get("X-Amz-Content-Sha256") != hashHex([]byte(tt.wantBody)) {
+				t.Errorf("X-Amz-Content-Sha256 = %s", got.header.Get("X-Amz-Content-Sha256"))
+			}
+			auth := got.header.Get("Authorization")
+			if !strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=AKID/20240501/eu-west-1/s3/aws4_request, SignedHeaders=host;x-amz-content-sha256;x-amz-date, Signature=") {
+				t.Errorf("Authorization = %s", auth)
+			}
+		})
+	}
+}
+
+func TestDirectory(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	d := &Directory{Builder: resolver.NewBuilder(fs, "/var/lib/nats-resolver")}
+	ctx := context.Background()
+	path := "/var/lib/nats-resolver/accounts/AACCOUNT.jwt"
+
+	if err := d.Publish(ctx, "AACCOUNT", "token"); err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+	if data, err := afero.ReadFile(fs, path); err != nil || string(data) != "token" {
+		t.Errorf("Publish() wrote %q (error %v), want %q", data, err, "token")
+	}
+
+	if err := d.Delete(ctx, "AACCOUNT"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if exists, _ := afero.Exists(fs, path); exists {
+		t.Errorf("Delete() left %s", path)
+	}
+	if err := d.Delete(ctx, "AACCOUNT"); err != nil {
+		t.Errorf("Delete() of a missing JWT error = %v", err)
+	}
+}
@@ -0,0 +1,91 @@
+package publish
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jradikk/nats-auth-operator/internal/natsclient"
+	"github.com/jradikk/nats-auth-operator/internal/resolver"
+)
+
+// ClaimsUpdateSubject is the subject servers running a full resolver accept account JWTs on
+const ClaimsUpdateSubject = "$SYS.REQ.CLAIMS.UPDATE"
+
+// Directory writes account JWTs to a full-resolver directory, e.g. a volume shared with the NATS
+// servers, as accounts/<account public key>.jwt
+type Directory struct {
+	Builder *resolver.Builder
+}
+
+// Publish writes the JWT file, creating the directory structure first
+func (d *Directory) Publish(_ context.Context, accountID, token string) error {
+	if err := d.Builder.Initialize(); err != nil {
+		return err
+	}
+	return d.Builder.WriteAccountJWT(accountID, token)
+}
+
+// Delete removes the JWT file; a missing file is not an error
+func (d *Directory) Delete(_ context.Context, accountID string) error {
+	return d.Builder.DeleteAccountJWT(accountID)
+}
+
+// NATSResolver pushes account JWTs to running servers over the system account, like nsc push.
+// It opens a connection per JWT; the servers store the JWT in their resolver and propagate it
+// to the rest of the cluster.
+type NATSResolver struct {
+	// Options of the connection; the creds must belong to a user of the system account
+	Options natsclient.Options
+
+	// Timeout bounds one push (defaults to DefaultTimeout)
+	Timeout time.Duration
+}
+
+// claimsUpdateResponse is the answer of a server to a claims update
+type claimsUpdateResponse struct {
+	Data *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"data,omitempty"`
+	Error *struct {
+		Code        int    `json:"code"`
+		Description string `json:"description"`
+	} `json:"error,omitempty"`
+}
+
+// Publish sends the JWT as a claims update and waits for the first server to store it
+func (n *NATSResolver) Publish(ctx context.Context, accountID, token string) error {
+	timeout := n.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	conn, err := natsclient.Dial(ctx, n.Options)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	reply, err := conn.Request(ctx, ClaimsUpdateSubject, []byte(token))
+	if err != nil {
+		return fmt.Errorf("failed to push account %s: %w", accountID, err)
+	}
+	var resp claimsUpdateResponse
+	if err := json.Unmarshal(reply.Data, &resp); err != nil {
+		return fmt.Errorf("invalid claims update response for account %s: %w", accountID, err)
+	}
+	if resp.Error != nil {
+		return fmt.Errorf("NATS server rejected account %s: %s (code %d)", accountID, resp.Error.Description, resp.Error.Code)
+	}
+	return nil
+}
+
+// Delete is a no-op: servers only delete an account on a request signed by the operator key,
+// so an account removed from the operator stays pushed until its JWT expires or is replaced
+func (n *NATSResolver) Delete(context.Context, string) error {
+	return nil
+}
@@ -0,0 +1,122 @@
+// Package random generates the nkeys, passwords and tokens the operator issues from an injectable
+// entropy source, so tests can run deterministically and rendered Secrets can be compared with
+// golden files.
+package random
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/nats-io/nkeys"
+
+	"github.com/jradikk/nats-auth-operator/internal/token"
+)
+
+// Generator creates the secrets the operator issues
+type Generator interface {
+	// KeyPair creates an nkey pair of the prefix type, e.g. nkeys.PrefixByteUser
+	KeyPair(prefix nkeys.PrefixByte) (nkeys.KeyPair, error)
+
+	// Password generates a password following the policy
+	Password(policy token.PasswordPolicy) (string, error)
+
+	// Token generates a base64 encoded token of length random bytes
+	Token(length int) (string, error)
+}
+
+// Source is a Generator drawing every random byte from Reader. Reads are serialized, so a
+// deterministic Reader yields the same secrets for the same sequence of calls.
+type Source struct {
+	mu     sync.Mutex
+	reader io.Reader
+}
+
+var _ Generator = &Source{}
+
+// New returns a Source reading from r
+func New(r io.Reader) *Source {
+	return &Source{reader: r}
+}
+
+// Default returns a Source reading from crypto/rand
+func Default() *Source {
+	return New(rand.Reader)
+}
+
+// OrDefault returns g, or Default when g is nil
+func OrDefault(g Generator) Generator {
+	if g == nil {
+		return Default()
+	}
+	return g
+}
+
+// NewDeterministic returns a Source whose bytes are derived from seed alone, so the same seed
+// always yields the same keys, passwords and tokens. It is meant for tests: anyone who knows the
+// seed can recreate every secret it generated.
+func NewDeterministic(seed string) *Source {
+	return New(&hashStream{seed: sha256.Sum256([]byte(seed))})
+}
+
+// KeyPair implements Generator
+func (s *Source) KeyPair(prefix nkeys.PrefixByte) (nkeys.KeyPair, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	kp, err := nkeys.CreatePairWithRand(prefix, s.reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s keypair: %w", prefix, err)
+	}
+	return kp, nil
+}
+
+// Password implements Generator
+func (s *Source) Password(policy token.PasswordPolicy) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return policy.GenerateFrom(s.reader)
+}
+
+// Token implements Generator
+func (s *Source) Token(length int) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return token.GenerateTokenFrom(s.reader, length)
+}
+
+// Seed creates an nkey pair with g and returns its seed
+func Seed(g Generator, prefix nkeys.PrefixByte) ([]byte, error) {
+	kp, err := g.KeyPair(prefix)
+	if err != nil {
+		return nil, err
+	}
+	return kp.Seed()
+}
+
+// hashStream is an endless stream of SHA-256(seed || counter) blocks
+type hashStream struct {
+	seed    [sha256.Size]byte
+	counter uint64
+	buf     []byte
+}
+
+func (h *hashStream) Read(p []byte) (int, error) {
+	n := 0
+	for n < len(p) {
+		if len(h.buf) == 0 {
+			block := make([]byte, 0, sha256.Size+8)
+			block = append(block, h.seed[:]...)
+			block = binary.BigEndian.AppendUint64(block, h.counter)
+			sum := sha256.Sum256(block)
+			h.buf = sum[:]
+			h.counter++
+		}
+		c := copy(p[n:], h.buf)
+		h.buf = h.buf[c:]
+		n += c
+	}
+	return n, nil
+}
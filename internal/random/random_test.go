@@ -0,0 +1,86 @@
+package random
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/nats-io/nkeys"
+
+	"github.com/jradikk/nats-auth-operator/internal/token"
+)
+
+// generate returns a user seed, a password and a token from g
+func generate(t *testing.T, g Generator) []string {
+	t.Helper()
+	seed, err := Seed(g, nkeys.PrefixByteUser)
+	if err != nil {
+		t.Fatalf("Seed() error = %v", err)
+	}
+	password, err := g.Password(token.PasswordPolicy{Length: 20, Charset: token.CharsetASCII, RequireSymbols: true})
+	if err != nil {
+		t.Fatalf("Password() error = %v", err)
+	}
+	tok, err := g.Token(16)
+	if err != nil {
+		t.Fatalf("Token() error = %v", err)
+	}
+	return []string{string(seed), password, tok}
+}
+
+func TestNewDeterministic(t *testing.T) {
+	a := generate(t, NewDeterministic("test"))
+	b := generate(t, NewDeterministic("test"))
+	for i := range a {
+		if a[i] != b[i] {
+			t.Errorf("value %d differs for the same seed: %q and %q", i, a[i], b[i])
+		}
+	}
+
+	other := generate(t, NewDeterministic("other"))
+	for i := range a {
+		if a[i] == other[i] {
+			t.Errorf("value %d is the same for different seeds: %q", i, a[i])
+		}
+	}
+
+	// Later calls on one source continue the stream
+	g := NewDeterministic("test")
+	first, second := generate(t, g), generate(t, g)
+	if first[0] == second[0] {
+		t.Error("consecutive seeds of one source are identical")
+	}
+
+	kp, err := nkeys.FromSeed([]byte(a[0]))
+	if err != nil {
+		t.Fatalf("generated seed is invalid: %v", err)
+	}
+	if pub, _ := kp.PublicKey(); !nkeys.IsValidPublicUserKey(pub) {
+		t.Errorf("public key %q is not a user key", pub)
+	}
+}
+
+func TestHashStream(t *testing.T) {
+	// Reads of any size see the same stream
+	whole := make([]byte, 100)
+	if _, err := NewDeterministic("test").reader.Read(whole); err != nil {
+		t.Fatal(err)
+	}
+	r := NewDeterministic("test").reader
+	var pieces []byte
+	for _, n := range []int{1, 31, 33, 35} {
+		p := make([]byte, n)
+		if _, err := r.Read(p); err != nil {
+			t.Fatal(err)
+		}
+		pieces = append(pieces, p...)
+	}
+	if !bytes.Equal(whole, pieces) {
+		t.Errorf("pieced reads = %x, want %x", pieces, whole)
+	}
+}
+
+func TestDefault(t *testing.T) {
+	if a, b := generate(t, OrDefault(nil)), generate(t, Default()); a[0] == b[0] || a[1] == b[1] || a[2] == b[2] {
+		t.Errorf("Default() generated the same values twice: %q and %q", a, b)
+	}
+}
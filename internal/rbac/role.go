@@ -0,0 +1,81 @@
+// Package rbac builds the Roles and RoleBindings the RBAC manager writes to grant the operator
+// access to individual Secrets by name, for operators that may not read every Secret of the
+// cluster.
+package rbac
+
+import (
+	"slices"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// DefaultRoleName is the name of the Role and RoleBinding written in each namespace
+const DefaultRoleName = "nats-auth-operator-secrets"
+
+// SecretVerbs are the verbs granted on the Secrets the operator generates. RBAC cannot restrict
+// create, list or watch to resource names, so Secrets are created under a separate grant and
+// never listed or watched.
+var SecretVerbs = []string{"get", "update", "patch", "delete"}
+
+// ReadSecretVerbs are the verbs granted on the Secrets a spec references, which the operator only reads
+var ReadSecretVerbs = []string{"get"}
+
+// SecretRole returns the Role name granting SecretVerbs on the Secrets of written and
+// ReadSecretVerbs on the Secrets of read. The names are sorted and deduplicated, and a Secret in
+// both lists is granted once as written, so the same sets always render the same Role.
+func SecretRole(namespace, name string, written, read []string, labels map[string]string) *rbacv1.Role {
+	written = slices.Clone(written)
+	slices.Sort(written)
+	written = slices.Compact(written)
+	var readOnly []string
+	for _, n := range read {
+		if _, found := slices.BinarySearch(written, n); !found {
+			readOnly = append(readOnly, n)
+		}
+	}
+	slices.Sort(readOnly)
+	readOnly = slices.Compact(readOnly)
+
+	var rules []rbacv1.PolicyRule
+	if len(written) > 0 {
+		rules = append(rules, rbacv1.PolicyRule{
+			APIGroups:     []string{""},
+			Resources:     []string{"secrets"},
+			ResourceNames: written,
+			Verbs:         SecretVerbs,
+		})
+	}
+	if len(readOnly) > 0 {
+		rules = append(rules, rbacv1.PolicyRule{
+			APIGroups:     []string{""},
+			Resources:     []string{"secrets"},
+			ResourceNames: readOnly,
+			Verbs:         ReadSecretVerbs,
+		})
+	}
+	return &rbacv1.Role{
+		TypeMeta:   metav1.TypeMeta{APIVersion: rbacv1.SchemeGroupVersion.String(), Kind: "Role"},
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name, Labels: labels},
+		Rules:      rules,
+	}
+}
+
+// RoleBinding returns the RoleBinding name binding the Role of the same name to serviceAccount
+func RoleBinding(namespace, name string, serviceAccount types.NamespacedName, labels map[string]string) *rbacv1.RoleBinding {
+	return &rbacv1.RoleBinding{
+		TypeMeta:   metav1.TypeMeta{APIVersion: rbacv1.SchemeGroupVersion.String(), Kind: "RoleBinding"},
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name, Labels: labels},
+		RoleRef: rbacv1.RoleRef{
+			APIGroup: rbacv1.GroupName,
+			Kind:     "Role",
+			Name:     name,
+		},
+		Subjects: []rbacv1.Subject{{
+			Kind:      rbacv1.ServiceAccountKind,
+			Namespace: serviceAccount.Namespace,
+			Name:      serviceAccount.Name,
+		}},
+	}
+}
@@ -0,0 +1,56 @@
+package rbac
+
+import (
+	"reflect"
+	"testing"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestSecretRole(t *testing.T) {
+	written := []string{"orders-creds", "main-operator-seed", "orders-creds", "billing-jwt"}
+	read := []string{"orders-password", "orders-creds", "orders-password", "billing-seed"}
+	role := SecretRole("apps", DefaultRoleName, written, read, map[string]string{"app": "test"})
+
+	want := []rbacv1.PolicyRule{
+		{
+			APIGroups:     []string{""},
+			Resources:     []string{"secrets"},
+			ResourceNames: []string{"billing-jwt", "main-operator-seed", "orders-creds"},
+			Verbs:         []string{"get", "update", "patch", "delete"},
+		},
+		{
+			APIGroups:     []string{""},
+			Resources:     []string{"secrets"},
+			ResourceNames: []string{"billing-seed", "orders-password"},
+			Verbs:         []string{"get"},
+		},
+	}
+	if !reflect.DeepEqual(role.Rules, want) {
+		t.Errorf("SecretRole() rules = %+v, want %+v", role.Rules, want)
+	}
+	if role.Namespace != "apps" || role.Name != DefaultRoleName || role.Labels["app"] != "test" {
+		t.Errorf("SecretRole() metadata = %+v", role.ObjectMeta)
+	}
+	if written[0] != "orders-creds" || len(written) != 4 {
+		t.Errorf("SecretRole() modified its written argument: %q", written)
+	}
+
+	readOnly := SecretRole("apps", DefaultRoleName, nil, []string{"tls"}, nil)
+	if len(readOnly.Rules) != 1 || !reflect.DeepEqual(readOnly.Rules[0].Verbs, []string{"get"}) {
+		t.Errorf("SecretRole() of read Secrets only = %+v, want a single get rule", readOnly.Rules)
+	}
+}
+
+func TestRoleBinding(t *testing.T) {
+	binding := RoleBinding("apps", DefaultRoleName, types.NamespacedName{Namespace: "nats-system", Name: "operator"}, nil)
+
+	if binding.RoleRef != (rbacv1.RoleRef{APIGroup: "rbac.authorization.k8s.io", Kind: "Role", Name: DefaultRoleName}) {
+		t.Errorf("RoleBinding() roleRef = %+v", binding.RoleRef)
+	}
+	want := []rbacv1.Subject{{Kind: "ServiceAccount", Namespace: "nats-system", Name: "operator"}}
+	if !reflect.DeepEqual(binding.Subjects, want) {
+		t.Errorf("RoleBinding() subjects = %+v, want %+v", binding.Subjects, want)
+	}
+}
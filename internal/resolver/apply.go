@@ -0,0 +1,159 @@
+package resolver
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// FieldManager is the field manager the operator uses for server-side apply
+const FieldManager = "nats-auth-operator"
+
+// legacyFieldManagers are the field managers recorded by operator versions that
+// wrote with Update. Fields they own are taken over instead of reported as conflicts.
+var legacyFieldManagers = map[string]bool{
+	"manager": true,
+}
+
+// ConflictError reports that an applied object has fields owned by another field manager
+type ConflictError struct {
+	Kind     string
+	Name     string
+	Managers []string
+	Fields   []string
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("%s %q has fields managed by %s: %s",
+		e.Kind, e.Name, strings.Join(e.Managers, ", "), strings.Join(e.Fields, ", "))
+}
+
+// ApplySecret server-side applies the given Secret. Only the keys present in the
+// Secret are claimed, so keys added by other tools are preserved. StringData is
+// folded into Data since it is write-only and cannot be tracked by field managers.
+func ApplySecret(ctx context.Context, c client.Client, secret *corev1.Secret) error {
+	obj := secret.DeepCopy()
+	obj.TypeMeta = metav1.TypeMeta{APIVersion: "v1", Kind: "Secret"}
+	obj.ResourceVersion = ""
+	obj.ManagedFields = nil
+
+	if len(obj.StringData) > 0 {
+		if obj.Data == nil {
+			obj.Data = make(map[string][]byte, len(obj.StringData))
+		}
+		for k, v := range obj.StringData {
+			obj.Data[k] = []byte(v)
+		}
+		obj.StringData = nil
+	}
+
+	return apply(ctx, c, obj)
+}
+
+// ApplyConfigMap server-side applies the given ConfigMap, preserving keys owned by other managers
+func ApplyConfigMap(ctx context.Context, c client.Client, cm *corev1.ConfigMap) error {
+	obj := cm.DeepCopy()
+	obj.TypeMeta = metav1.TypeMeta{APIVersion: "v1", Kind: "ConfigMap"}
+	obj.ResourceVersion = ""
+	obj.ManagedFields = nil
+
+	return apply(ctx, c, obj)
+}
+
+// ApplyObject server-side applies an object whose TypeMeta is set, such as a custom resource the
+// operator provisions. Only the fields set on the object are claimed.
+func ApplyObject(ctx context.Context, c client.Client, obj client.Object) error {
+	obj = obj.DeepCopyObject().(client.Object)
+	obj.SetResourceVersion("")
+	obj.SetManagedFields(nil)
+
+	return apply(ctx, c, obj)
+}
+
+func apply(ctx context.Context, c client.Client, obj client.Object) error {
+	kind := obj.GetObjectKind().GroupVersionKind().Kind
+
+	err := c.Patch(ctx, obj.DeepCopyObject().(client.Object), client.Apply, client.FieldOwner(FieldManager))
+	if err == nil {
+		return nil
+	}
+
+	conflict := conflictFromError(kind, obj.GetName(), err)
+	if conflict == nil {
+		return fmt.Errorf("failed to apply %s: %w", kind, err)
+	}
+
+	if !conflict.onlyLegacyManagers() {
+		return conflict
+	}
+
+	// Take over the fields written by earlier operator versions
+	if err := c.Patch(ctx, obj, client.Apply, client.FieldOwner(FieldManager), client.ForceOwnership); err != nil {
+		return fmt.Errorf("failed to apply %s: %w", kind, err)
+	}
+
+	return nil
+}
+
+// conflictFromError extracts the conflicting managers and fields from an apply conflict
+func conflictFromError(kind, name string, err error) *ConflictError {
+	if !errors.IsConflict(err) {
+		return nil
+	}
+
+	status, ok := err.(errors.APIStatus)
+	if !ok || status.Status().Details == nil {
+		return nil
+	}
+
+	managers := map[string]bool{}
+	var fields []string
+	for _, cause := range status.Status().Details.Causes {
+		if cause.Type != metav1.CauseTypeFieldManagerConflict {
+			continue
+		}
+		managers[parseConflictManager(cause.Message)] = true
+		fields = append(fields, cause.Field)
+	}
+
+	if len(fields) == 0 {
+		return nil
+	}
+
+	conflict := &ConflictError{Kind: kind, Name: name, Fields: fields}
+	for m := range managers {
+		conflict.Managers = append(conflict.Managers, m)
+	}
+	sort.Strings(conflict.Managers)
+
+	return conflict
+}
+
+// parseConflictManager extracts the manager name from a cause message
+// such as `conflict with "kubectl-edit" using v1`
+func parseConflictManager(message string) string {
+	start := strings.Index(message, "\"")
+	if start < 0 {
+		return message
+	}
+	end := strings.Index(message[start+1:], "\"")
+	if end < 0 {
+		return message
+	}
+	return message[start+1 : start+1+end]
+}
+
+func (e *ConflictError) onlyLegacyManagers() bool {
+	for _, m := range e.Managers {
+		if !legacyFieldManagers[m] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,75 @@
+package resolver
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestConflictFromError(t *testing.T) {
+	tests := []struct {
+		name         string
+		err          error
+		wantNil      bool
+		wantManagers []string
+		wantFields   []string
+		wantLegacy   bool
+	}{
+		{
+			name: "apply conflict with external manager",
+			err: apierrors.NewApplyConflict([]metav1.StatusCause{
+				{Type: metav1.CauseTypeFieldManagerConflict, Message: `conflict with "kubectl-edit" using v1`, Field: ".data.operator"},
+				{Type: metav1.CauseTypeFieldManagerConflict, Message: `conflict with "manager" using v1`, Field: ".data.app"},
+			}, "Apply failed with 2 conflicts"),
+			wantManagers: []string{"kubectl-edit", "manager"},
+			wantFields:   []string{".data.operator", ".data.app"},
+			wantLegacy:   false,
+		},
+		{
+			name: "apply conflict with legacy manager only",
+			err: apierrors.NewApplyConflict([]metav1.StatusCause{
+				{Type: metav1.CauseTypeFieldManagerConflict, Message: `conflict with "manager" using v1`, Field: ".data.operator"},
+			}, "Apply failed with 1 conflict"),
+			wantManagers: []string{"manager"},
+			wantFields:   []string{".data.operator"},
+			wantLegacy:   true,
+		},
+		{
+			name:    "not a conflict",
+			err:     errors.New("boom"),
+			wantNil: true,
+		},
+		{
+			name:    "conflict without field manager causes",
+			err:     apierrors.NewConflict(metav1.SchemeGroupVersion.WithResource("secrets").GroupResource(), "x", errors.New("stale")),
+			wantNil: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := conflictFromError("Secret", "nats-auth", tt.err)
+			if tt.wantNil {
+				if got != nil {
+					t.Fatalf("conflictFromError() = %v, want nil", got)
+				}
+				return
+			}
+			if got == nil {
+				t.Fatal("conflictFromError() = nil, want conflict")
+			}
+			if !reflect.DeepEqual(got.Managers, tt.wantManagers) {
+				t.Errorf("Managers = %v, want %v", got.Managers, tt.wantManagers)
+			}
+			if !reflect.DeepEqual(got.Fields, tt.wantFields) {
+				t.Errorf("Fields = %v, want %v", got.Fields, tt.wantFields)
+			}
+			if got.onlyLegacyManagers() != tt.wantLegacy {
+				t.Errorf("onlyLegacyManagers() = %v, want %v", got.onlyLegacyManagers(), tt.wantLegacy)
+			}
+		})
+	}
+}
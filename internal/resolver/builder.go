@@ -1,8 +1,11 @@
 package resolver
 
 import (
+	"bytes"
 	"fmt"
+	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/spf13/afero"
 )
@@ -29,8 +32,7 @@ func (b *Builder) Initialize() error {
 	}
 
 	// Create accounts subdirectory
-	accountsDir := filepath.Join(b.baseDir, "accounts")
-	if err := b.fs.MkdirAll(accountsDir, 0755); err != nil {
+	if err := b.fs.MkdirAll(b.AccountsDir(), 0755); err != nil {
 		return fmt.Errorf("failed to create accounts directory: %w", err)
 	}
 
@@ -39,8 +41,7 @@ func (b *Builder) Initialize() error {
 
 // WriteOperatorJWT writes the operator JWT to the resolver directory
 func (b *Builder) WriteOperatorJWT(operatorJWT string) error {
-	operatorPath := filepath.Join(b.baseDir, "operator.jwt")
-	if err := afero.WriteFile(b.fs, operatorPath, []byte(operatorJWT), 0644); err != nil {
+	if err := b.writeFile(b.GetOperatorJWTPath(), []byte(operatorJWT)); err != nil {
 		return fmt.Errorf("failed to write operator JWT: %w", err)
 	}
 	return nil
@@ -48,16 +49,32 @@ func (b *Builder) WriteOperatorJWT(operatorJWT string) error {
 
 // WriteAccountJWT writes an account JWT to the resolver directory
 func (b *Builder) WriteAccountJWT(accountID, accountJWT string) error {
-	accountPath := filepath.Join(b.baseDir, "accounts", fmt.Sprintf("%s.jwt", accountID))
-	if err := afero.WriteFile(b.fs, accountPath, []byte(accountJWT), 0644); err != nil {
+	if err := b.writeFile(b.GetAccountJWTPath(accountID), []byte(accountJWT)); err != nil {
 		return fmt.Errorf("failed to write account JWT: %w", err)
 	}
 	return nil
 }
 
+// writeFile replaces the file through a temporary file and a rename, so a server reading the
+// directory never sees a partial JWT. A file already holding data is left untouched.
+func (b *Builder) writeFile(path string, data []byte) error {
+	if current, err := afero.ReadFile(b.fs, path); err == nil && bytes.Equal(current, data) {
+		return nil
+	}
+	tmp := path + ".tmp"
+	if err := afero.WriteFile(b.fs, tmp, data, 0644); err != nil {
+		return err
+	}
+	if err := b.fs.Rename(tmp, path); err != nil {
+		_ = b.fs.Remove(tmp)
+		return err
+	}
+	return nil
+}
+
 // DeleteAccountJWT removes an account JWT from the resolver directory
 func (b *Builder) DeleteAccountJWT(accountID string) error {
-	accountPath := filepath.Join(b.baseDir, "accounts", fmt.Sprintf("%s.jwt", accountID))
+	accountPath := b.GetAccountJWTPath(accountID)
 	exists, err := afero.Exists(b.fs, accountPath)
 	if err != nil {
 		return fmt.Errorf("failed to check account JWT existence: %w", err)
@@ -71,7 +88,57 @@ func (b *Builder) DeleteAccountJWT(accountID string) error {
 	return nil
 }
 
-// GetResolverConfig generates the resolver configuration for NATS server
+// ListAccountJWTs returns the account public keys with a JWT in the resolver directory
+func (b *Builder) ListAccountJWTs() ([]string, error) {
+	entries, err := afero.ReadDir(b.fs, b.AccountsDir())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to list account JWTs: %w", err)
+	}
+	var accountIDs []string
+	for _, entry := range entries {
+		if accountID, ok := strings.CutSuffix(entry.Name(), ".jwt"); ok && !entry.IsDir() {
+			accountIDs = append(accountIDs, accountID)
+		}
+	}
+	return accountIDs, nil
+}
+
+// Sync makes the resolver directory hold exactly the operator JWT and the account JWTs, keyed
+// by account public key: changed JWTs are written and account JWTs not listed are deleted. An
+// empty operator JWT leaves operator.jwt as it is.
+func (b *Builder) Sync(operatorJWT string, accounts map[string]string) error {
+	if err := b.Initialize(); err != nil {
+		return err
+	}
+	if operatorJWT != "" {
+		if err := b.WriteOperatorJWT(operatorJWT); err != nil {
+			return err
+		}
+	}
+	for accountID, accountJWT := range accounts {
+		if err := b.WriteAccountJWT(accountID, accountJWT); err != nil {
+			return err
+		}
+	}
+	existing, err := b.ListAccountJWTs()
+	if err != nil {
+		return err
+	}
+	for _, accountID := range existing {
+		if _, ok := accounts[accountID]; !ok {
+			if err := b.DeleteAccountJWT(accountID); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// GetResolverConfig generates the resolver configuration for NATS server, reading the account
+// JWTs of the accounts directory
 func (b *Builder) GetResolverConfig() string {
 	return fmt.Sprintf(`resolver: {
     type: full
@@ -79,7 +146,13 @@ func (b *Builder) GetResolverConfig() string {
     allow_delete: false
     interval: "2m"
 }
-`, b.baseDir)
+`, b.AccountsDir())
+}
+
+// AccountsDir returns the directory holding the account JWTs, which the servers' full resolver
+// reads
+func (b *Builder) AccountsDir() string {
+	return filepath.Join(b.baseDir, "accounts")
 }
 
 // GetOperatorJWTPath returns the path to the operator JWT
@@ -89,7 +162,7 @@ func (b *Builder) GetOperatorJWTPath() string {
 
 // GetAccountJWTPath returns the path to an account JWT
 func (b *Builder) GetAccountJWTPath(accountID string) string {
-	return filepath.Join(b.baseDir, "accounts", fmt.Sprintf("%s.jwt", accountID))
+	return filepath.Join(b.AccountsDir(), fmt.Sprintf("%s.jwt", accountID))
 }
 
 // AccountJWTExists checks if an account JWT exists
@@ -0,0 +1,82 @@
+package resolver
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/spf13/afero"
+)
+
+func TestBuilder(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	b := NewBuilder(fs, "/var/lib/nats-resolver")
+
+	if err := b.Initialize(); err != nil {
+		t.Fatalf("Initialize() error = %v", err)
+	}
+	if err := b.WriteOperatorJWT("operator"); err != nil {
+		t.Fatalf("WriteOperatorJWT() error = %v", err)
+	}
+	for _, token := range []string{"first", "second", "second"} {
+		if err := b.WriteAccountJWT("AACCOUNT", token); err != nil {
+			t.Fatalf("WriteAccountJWT() error = %v", err)
+		}
+	}
+
+	files := map[string]string{}
+	_ = afero.Walk(fs, "/var/lib/nats-resolver", func(path string, info os.FileInfo, err error) error {
+		if err == nil && !info.IsDir() {
+			data, _ := afero.ReadFile(fs, path)
+			files[path] = string(data)
+		}
+		return nil
+	})
+	want := map[string]string{
+		"/var/lib/nats-resolver/operator.jwt":          "operator",
+		"/var/lib/nats-resolver/accounts/AACCOUNT.jwt": "second",
+	}
+	if len(files) != len(want) {
+		t.Errorf("resolver directory = %v, want %v", files, want)
+	}
+	for path, data := range want {
+		if files[path] != data {
+			t.Errorf("%s = %q, want %q", path, files[path], data)
+		}
+	}
+
+	if err := b.DeleteAccountJWT("AACCOUNT"); err != nil {
+		t.Fatalf("DeleteAccountJWT() error = %v", err)
+	}
+	if exists, _ := b.AccountJWTExists("AACCOUNT"); exists {
+		t.Error("DeleteAccountJWT() left the account JWT")
+	}
+
+	// The servers read the account JWTs from the accounts directory
+	if conf := b.GetResolverConfig(); !strings.Contains(conf, "dir: /var/lib/nats-resolver/accounts\n") {
+		t.Errorf("GetResolverConfig() = %s, want the accounts directory", conf)
+	}
+}
+
+func TestBuilderSync(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	b := NewBuilder(fs, "/var/lib/nats-resolver")
+
+	if err := b.Sync("operator", map[string]string{"AFIRST": "first", "ASECOND": "second"}); err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+	if err := b.Sync("operator", map[string]string{"ASECOND": "updated"}); err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+
+	accountIDs, err := b.ListAccountJWTs()
+	if err != nil {
+		t.Fatalf("ListAccountJWTs() error = %v", err)
+	}
+	if len(accountIDs) != 1 || accountIDs[0] != "ASECOND" {
+		t.Errorf("ListAccountJWTs() = %v, want [ASECOND]", accountIDs)
+	}
+	if data, _ := afero.ReadFile(fs, b.GetAccountJWTPath("ASECOND")); string(data) != "updated" {
+		t.Errorf("Sync() wrote %q, want %q", data, "updated")
+	}
+}
@@ -0,0 +1,120 @@
+package resolver
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/jradikk/nats-auth-operator/internal/natsclient"
+)
+
+// Keys of the key-value bucket the operator publishes JWTs to
+const (
+	// KVOperatorKey holds the operator JWT
+	KVOperatorKey = "operator"
+	// KVAccountPrefix prefixes the public key of each account JWT
+	KVAccountPrefix = "accounts."
+	// KVMetadataPrefix prefixes the public key of the sealed metadata of each account
+	KVMetadataPrefix = "metadata."
+)
+
+// DefaultRetryInterval is how long KVSource waits before watching the bucket again
+const DefaultRetryInterval = 10 * time.Second
+
+// KVSource materializes the full-resolver directory from a JetStream key-value bucket, e.g. one
+// replicated to a leaf cluster that cannot reach the Kubernetes API
+type KVSource struct {
+	// Options of the connection; the user needs to read the bucket and create consumers on it
+	Options natsclient.Options
+
+	// Bucket is the name of the key-value bucket
+	Bucket string
+
+	// Builder maintains the full-resolver directory
+	Builder *Builder
+
+	// RetryInterval separates watches after a failure (defaults to DefaultRetryInterval)
+	RetryInterval time.Duration
+}
+
+// Run keeps the directory in sync with the bucket until ctx is done, watching the bucket again
+// whenever the connection fails
+func (s *KVSource) Run(ctx context.Context) error {
+	log := log.FromContext(ctx).WithValues("bucket", s.Bucket)
+	interval := s.RetryInterval
+	if interval <= 0 {
+		interval = DefaultRetryInterval
+	}
+	for {
+		err := s.watch(ctx)
+		if ctx.Err() != nil {
+			return nil
+		}
+		log.Error(err, "Key-value watch failed, retrying", "interval", interval)
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(interval):
+		}
+	}
+}
+
+// watch syncs the directory once the watch delivered the whole bucket and again on every change
+func (s *KVSource) watch(ctx context.Context) error {
+	conn, err := natsclient.Dial(ctx, s.Options)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	kv := &natsclient.KV{Conn: conn, Bucket: s.Bucket}
+	watcher, err := kv.Watch(ctx)
+	if err != nil {
+		return err
+	}
+	defer watcher.Stop()
+
+	var operatorJWT string
+	accounts := map[string]string{}
+	caught := watcher.Caught
+	if caught {
+		if err := s.Builder.Sync(operatorJWT, accounts); err != nil {
+			return err
+		}
+	}
+	for {
+		entry, err := watcher.Next(ctx)
+		if err != nil {
+			return err
+		}
+		deleted := entry.Operation != natsclient.KVPut
+		changed := true
+		switch {
+		case entry.Key == KVOperatorKey:
+			if !deleted {
+				operatorJWT = string(entry.Value)
+			}
+		case strings.HasPrefix(entry.Key, KVAccountPrefix):
+			accountID := strings.TrimPrefix(entry.Key, KVAccountPrefix)
+			if deleted {
+				delete(accounts, accountID)
+			} else {
+				accounts[accountID] = string(entry.Value)
+			}
+		default:
+			changed = false
+		}
+		// Until the watch delivered the whole bucket, the accounts seen so far are incomplete
+		wasCaught := caught
+		caught = caught || entry.Pending == 0
+		if !caught || (wasCaught && !changed) {
+			continue
+		}
+		if err := s.Builder.Sync(operatorJWT, accounts); err != nil {
+			return fmt.Errorf("failed to sync the resolver directory: %w", err)
+		}
+	}
+}
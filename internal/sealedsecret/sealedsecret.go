@@ -0,0 +1,123 @@
+package sealedsecret
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// sessionKeyBytes is the size of the AES-256 key each value is encrypted with
+const sessionKeyBytes = 32
+
+// SealedSecret is a bitnami.com/v1alpha1 SealedSecret. The sealed-secrets controller decrypts it
+// into the Secret described by the template, so it can be committed to Git.
+type SealedSecret struct {
+	metav1.TypeMeta `json:",inline"`
+	Metadata        Metadata `json:"metadata"`
+	Spec            Spec     `json:"spec"`
+}
+
+// Metadata is the part of the object metadata a SealedSecret and its template carry
+type Metadata struct {
+	Name      string            `json:"name"`
+	Namespace string            `json:"namespace"`
+	Labels    map[string]string `json:"labels,omitempty"`
+}
+
+// Spec holds the encrypted values and the template of the Secret they are decrypted into
+type Spec struct {
+	EncryptedData map[string]string `json:"encryptedData"`
+	Template      Template          `json:"template"`
+}
+
+// Template describes the Secret the controller writes
+type Template struct {
+	Metadata Metadata          `json:"metadata"`
+	Type     corev1.SecretType `json:"type,omitempty"`
+}
+
+// ParseCertificate returns the public key of the sealed-secrets controller from its PEM
+// certificate, as printed by kubeseal --fetch-cert
+func ParseCertificate(data []byte) (*rsa.PublicKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != "CERTIFICATE" {
+		return nil, fmt.Errorf("no PEM certificate found")
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse certificate: %w", err)
+	}
+	pub, ok := cert.PublicKey.(*rsa.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("certificate holds a %T key, want an RSA key", cert.PublicKey)
+	}
+	return pub, nil
+}
+
+// Encrypt seals a value with the strict scope of sealed-secrets: it only decrypts into the Secret
+// namespace/name. The value is encrypted with a random AES-GCM session key, which is encrypted
+// with RSA-OAEP labelled with the scope and prepended with its length.
+func Encrypt(rnd io.Reader, pub *rsa.PublicKey, namespace, name string, plaintext []byte) ([]byte, error) {
+	sessionKey := make([]byte, sessionKeyBytes)
+	if _, err := io.ReadFull(rnd, sessionKey); err != nil {
+		return nil, fmt.Errorf("failed to create session key: %w", err)
+	}
+	block, err := aes.NewCipher(sessionKey)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	encryptedKey, err := rsa.EncryptOAEP(sha256.New(), rnd, pub, sessionKey, []byte(namespace+"/"+name))
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt session key: %w", err)
+	}
+	out := binary.BigEndian.AppendUint16(nil, uint16(len(encryptedKey)))
+	out = append(out, encryptedKey...)
+	// The session key is used once, so a zero nonce is safe
+	return aead.Seal(out, make([]byte, aead.NonceSize()), plaintext, nil), nil
+}
+
+// Seal returns the SealedSecret that decrypts into secret
+func Seal(rnd io.Reader, pub *rsa.PublicKey, secret *corev1.Secret) (*SealedSecret, error) {
+	meta := Metadata{Name: secret.Name, Namespace: secret.Namespace}
+	sealed := &SealedSecret{
+		TypeMeta: metav1.TypeMeta{APIVersion: "bitnami.com/v1alpha1", Kind: "SealedSecret"},
+		Metadata: meta,
+		Spec: Spec{
+			EncryptedData: make(map[string]string, len(secret.Data)),
+			Template: Template{
+				Metadata: Metadata{Name: secret.Name, Namespace: secret.Namespace, Labels: secret.Labels},
+				Type:     secret.Type,
+			},
+		},
+	}
+
+	keys := make([]string, 0, len(secret.Data))
+	for k := range secret.Data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		ciphertext, err := Encrypt(rnd, pub, secret.Namespace, secret.Name, secret.Data[k])
+		if err != nil {
+			return nil, fmt.Errorf("failed to seal key %q: %w", k, err)
+		}
+		sealed.Spec.EncryptedData[k] = base64.StdEncoding.EncodeToString(ciphertext)
+	}
+	return sealed, nil
+}
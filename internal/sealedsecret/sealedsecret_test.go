@@ -0,0 +1,136 @@
+package sealedsecret
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/pem"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// decrypt is what the sealed-secrets controller does with a value sealed for namespace/name
+func decrypt(t *testing.T, key *rsa.PrivateKey, namespace, name string, ciphertext []byte) ([]byte, error) {
+	t.Helper()
+	n := int(binary.BigEndian.Uint16(ciphertext))
+	sessionKey, err := rsa.DecryptOAEP(sha256.New(), rand.Reader, key, ciphertext[2:2+n], []byte(namespace+"/"+name))
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(sessionKey)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return aead.Open(nil, make([]byte, aead.NonceSize()), ciphertext[2+n:], nil)
+}
+
+func certificatePEM(t *testing.T, pub, priv any) []byte {
+	t.Helper()
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "sealed-secret"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, pub, priv)
+	if err != nil {
+		t.Fatalf("Failed to create certificate: %v", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
+
+func TestSeal(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "nats", Name: "app-seed", Labels: map[string]string{"nats.jradikk/seed-type": "account"}},
+		Data:       map[string][]byte{"account.seed": []byte("SAEXAMPLE")},
+	}
+
+	sealed, err := Seal(rand.Reader, &key.PublicKey, secret)
+	if err != nil {
+		t.Fatalf("Seal() error = %v", err)
+	}
+	if sealed.Kind != "SealedSecret" || sealed.Metadata.Name != "app-seed" || sealed.Spec.Template.Metadata.Labels["nats.jradikk/seed-type"] != "account" {
+		t.Errorf("Seal() = %+v", sealed)
+	}
+	ciphertext, err := base64.StdEncoding.DecodeString(sealed.Spec.EncryptedData["account.seed"])
+	if err != nil {
+		t.Fatalf("encryptedData is not base64: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		namespace string
+		secret    string
+		wantErr   bool
+	}{
+		{name: "Same Secret", namespace: "nats", secret: "app-seed"},
+		{name: "Other name", namespace: "nats", secret: "other-seed", wantErr: true},
+		{name: "Other namespace", namespace: "default", secret: "app-seed", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plaintext, err := decrypt(t, key, tt.namespace, tt.secret, ciphertext)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("decrypt() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && string(plaintext) != "SAEXAMPLE" {
+				t.Errorf("decrypt() = %q, want SAEXAMPLE", plaintext)
+			}
+		})
+	}
+}
+
+func TestParseCertificate(t *testing.T) {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+
+	tests := []struct {
+		name    string
+		data    []byte
+		wantErr string
+	}{
+		{name: "RSA certificate", data: certificatePEM(t, &rsaKey.PublicKey, rsaKey)},
+		{name: "ECDSA certificate", data: certificatePEM(t, &ecKey.PublicKey, ecKey), wantErr: "want an RSA key"},
+		{name: "Not PEM", data: []byte("not a certificate"), wantErr: "no PEM certificate found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pub, err := ParseCertificate(tt.data)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("ParseCertificate() error = %v, want it to contain %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseCertificate() error = %v", err)
+			}
+			if !pub.Equal(&rsaKey.PublicKey) {
+				t.Error("ParseCertificate() returned another key")
+			}
+		})
+	}
+}
@@ -0,0 +1,32 @@
+package secrets
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// List returns the Secrets matching opts keyed by namespace/name, in one pass instead of a Get
+// per Secret. With a client.Limit in opts it follows the continue token page by page, which
+// bounds the response size when c reads from the API server; the cache truncates at the limit
+// without a continue token, so a cached reader must be called without one.
+func List(ctx context.Context, c client.Reader, opts ...client.ListOption) (map[client.ObjectKey]*corev1.Secret, error) {
+	found := map[client.ObjectKey]*corev1.Secret{}
+	page := &corev1.SecretList{}
+	for {
+		if err := c.List(ctx, page, opts...); err != nil {
+			return nil, fmt.Errorf("failed to list secrets: %w", err)
+		}
+		for i := range page.Items {
+			secret := &page.Items[i]
+			found[client.ObjectKeyFromObject(secret)] = secret
+		}
+		if page.Continue == "" {
+			return found, nil
+		}
+		opts = append(opts, client.Continue(page.Continue))
+		page = &corev1.SecretList{}
+	}
+}
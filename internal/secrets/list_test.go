@@ -0,0 +1,151 @@
+package secrets
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// pagedReader answers List like the API server: at most Limit items per page, continuing at the
+// offset in the continue token
+type pagedReader struct {
+	client.Reader
+	items []corev1.Secret
+	calls int
+}
+
+func (r *pagedReader) List(_ context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	r.calls++
+	listOpts := client.ListOptions{}
+	listOpts.ApplyOptions(opts)
+	start := 0
+	if listOpts.Continue != "" {
+		start, _ = strconv.Atoi(listOpts.Continue)
+	}
+	end := len(r.items)
+	if listOpts.Limit > 0 && start+int(listOpts.Limit) < end {
+		end = start + int(listOpts.Limit)
+	}
+	out := list.(*corev1.SecretList)
+	out.Items = append([]corev1.Secret(nil), r.items[start:end]...)
+	if end < len(r.items) {
+		out.Continue = strconv.Itoa(end)
+	}
+	return nil
+}
+
+func numberedSecrets(n int, labels map[string]string) []corev1.Secret {
+	items := make([]corev1.Secret, n)
+	for i := range items {
+		items[i] = *New(fmt.Sprintf("team-%d", i%10), fmt.Sprintf("account-%d-jwt", i), FromStrings(map[string]string{"account.jwt": "jwt"}))
+		items[i].Labels = labels
+	}
+	return items
+}
+
+func TestList(t *testing.T) {
+	items := numberedSecrets(25, nil)
+
+	tests := []struct {
+		name      string
+		opts      []client.ListOption
+		wantCalls int
+	}{
+		{name: "Single page", wantCalls: 1},
+		{name: "Pages", opts: []client.ListOption{client.Limit(10)}, wantCalls: 3},
+		{name: "Page size of the item count", opts: []client.ListOption{client.Limit(25)}, wantCalls: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &pagedReader{items: items}
+			found, err := List(context.Background(), r, tt.opts...)
+			if err != nil {
+				t.Fatalf("List() error = %v", err)
+			}
+			if r.calls != tt.wantCalls {
+				t.Errorf("List() made %d calls, want %d", r.calls, tt.wantCalls)
+			}
+			if len(found) != len(items) {
+				t.Fatalf("List() found %d secrets, want %d", len(found), len(items))
+			}
+			for i := range items {
+				if _, ok := found[client.ObjectKeyFromObject(&items[i])]; !ok {
+					t.Errorf("List() is missing %s", client.ObjectKeyFromObject(&items[i]))
+				}
+			}
+		})
+	}
+}
+
+func TestListMatchingLabels(t *testing.T) {
+	selected := map[string]string{"example.com/owner-name": "nats"}
+	items := numberedSecrets(5, nil)
+	objs := make([]client.Object, len(items))
+	for i := range items {
+		if i < 3 {
+			items[i].Labels = selected
+		}
+		objs[i] = &items[i]
+	}
+	c := fake.NewClientBuilder().WithObjects(objs...).Build()
+
+	found, err := List(context.Background(), c, client.MatchingLabels(selected))
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	var names []string
+	for key := range found {
+		names = append(names, key.Name)
+	}
+	sort.Strings(names)
+	if fmt.Sprint(names) != "[account-0-jwt account-1-jwt account-2-jwt]" {
+		t.Errorf("List() = %v", names)
+	}
+}
+
+// The account JWT Secrets of a NatsAuthConfig are collected on every reconcile of it. Run with
+// go test -bench . ./internal/secrets to compare a Get per Secret with one List.
+const benchmarkSecrets = 2000
+
+func benchmarkClient(b *testing.B) (client.Client, []client.ObjectKey) {
+	b.Helper()
+	items := numberedSecrets(benchmarkSecrets, map[string]string{"example.com/owner-name": "nats"})
+	objs := make([]client.Object, len(items))
+	keys := make([]client.ObjectKey, len(items))
+	for i := range items {
+		objs[i] = &items[i]
+		keys[i] = client.ObjectKeyFromObject(&items[i])
+	}
+	return fake.NewClientBuilder().WithObjects(objs...).Build(), keys
+}
+
+func BenchmarkGetEach(b *testing.B) {
+	c, keys := benchmarkClient(b)
+	ctx := context.Background()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, key := range keys {
+			if _, _, err := Get(ctx, c, key); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+func BenchmarkList(b *testing.B) {
+	c, _ := benchmarkClient(b)
+	ctx := context.Background()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := List(ctx, c, client.MatchingLabels{"example.com/owner-name": "nats"}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
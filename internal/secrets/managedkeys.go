@@ -0,0 +1,114 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/jradikk/nats-auth-operator/internal/resolver"
+)
+
+// ManagedKeysAnnotation lists the data keys the operator owns in a shared Secret
+const ManagedKeysAnnotation = "nats.jradikk/managed-keys"
+
+// ManagedKeys returns the keys recorded in the managed-keys annotation
+func ManagedKeys(obj metav1.Object) []string {
+	value := obj.GetAnnotations()[ManagedKeysAnnotation]
+	if value == "" {
+		return nil
+	}
+	return strings.Split(value, ",")
+}
+
+// SetManagedKeys records the given keys in the managed-keys annotation
+func SetManagedKeys(obj metav1.Object, keys []string) {
+	sorted := append([]string(nil), keys...)
+	sort.Strings(sorted)
+
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[ManagedKeysAnnotation] = strings.Join(sorted, ",")
+	obj.SetAnnotations(annotations)
+}
+
+// StaleKeys returns the previously managed keys that are no longer desired
+func StaleKeys(previous, desired []string) []string {
+	want := make(map[string]bool, len(desired))
+	for _, k := range desired {
+		want[k] = true
+	}
+
+	var stale []string
+	for _, k := range previous {
+		if !want[k] {
+			stale = append(stale, k)
+		}
+	}
+	sort.Strings(stale)
+	return stale
+}
+
+// ApplyManaged applies the Secret's data keys and records them in the
+// managed-keys annotation. Keys the operator managed before but no longer
+// desires are removed; keys it never managed are left intact.
+func ApplyManaged(ctx context.Context, c client.Client, secret *corev1.Secret) error {
+	desired := make([]string, 0, len(secret.Data))
+	for k := range secret.Data {
+		desired = append(desired, k)
+	}
+
+	existing := &corev1.Secret{}
+	err := c.Get(ctx, client.ObjectKey{Namespace: secret.Namespace, Name: secret.Name}, existing)
+	if err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("failed to get Secret: %w", err)
+	}
+
+	if err == nil {
+		var remove []string
+		for _, k := range StaleKeys(ManagedKeys(existing), desired) {
+			if _, ok := existing.Data[k]; ok {
+				remove = append(remove, k)
+			}
+		}
+		if err := removeSecretKeys(ctx, c, existing, remove); err != nil {
+			return err
+		}
+	}
+
+	obj := secret.DeepCopy()
+	SetManagedKeys(obj, desired)
+
+	return resolver.ApplySecret(ctx, c, obj)
+}
+
+// removeSecretKeys deletes the given keys from the Secret with a merge patch
+func removeSecretKeys(ctx context.Context, c client.Client, secret *corev1.Secret, keys []string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	data := make(map[string]interface{}, len(keys))
+	for _, k := range keys {
+		data[k] = nil
+	}
+	patch, err := json.Marshal(map[string]interface{}{"data": data})
+	if err != nil {
+		return fmt.Errorf("failed to build patch: %w", err)
+	}
+
+	if err := c.Patch(ctx, secret, client.RawPatch(types.MergePatchType, patch), client.FieldOwner(resolver.FieldManager)); err != nil {
+		return fmt.Errorf("failed to remove stale keys from Secret: %w", err)
+	}
+
+	return nil
+}
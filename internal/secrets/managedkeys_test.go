@@ -0,0 +1,82 @@
+package secrets
+
+import (
+	"reflect"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestManagedKeysRoundTrip(t *testing.T) {
+	tests := []struct {
+		name string
+		keys []string
+		want []string
+	}{
+		{
+			name: "sorted on write",
+			keys: []string{"operator", "app", "SYS"},
+			want: []string{"SYS", "app", "operator"},
+		},
+		{
+			name: "single key",
+			keys: []string{"operator"},
+			want: []string{"operator"},
+		},
+		{
+			name: "no keys",
+			keys: nil,
+			want: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			obj := &metav1.ObjectMeta{Annotations: map[string]string{"other": "kept"}}
+			SetManagedKeys(obj, tt.keys)
+
+			if got := ManagedKeys(obj); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ManagedKeys() = %v, want %v", got, tt.want)
+			}
+			if obj.Annotations["other"] != "kept" {
+				t.Error("SetManagedKeys() dropped an unrelated annotation")
+			}
+		})
+	}
+}
+
+func TestStaleKeys(t *testing.T) {
+	tests := []struct {
+		name     string
+		previous []string
+		desired  []string
+		want     []string
+	}{
+		{
+			name:     "removed account",
+			previous: []string{"operator", "app", "billing"},
+			desired:  []string{"operator", "app"},
+			want:     []string{"billing"},
+		},
+		{
+			name:     "nothing stale",
+			previous: []string{"operator"},
+			desired:  []string{"operator", "app"},
+			want:     nil,
+		},
+		{
+			name:     "no previous manifest",
+			previous: nil,
+			desired:  []string{"operator"},
+			want:     nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := StaleKeys(tt.previous, tt.desired); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("StaleKeys() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
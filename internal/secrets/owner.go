@@ -0,0 +1,92 @@
+package secrets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// NotOwnedError reports a Secret that exists under the wanted name but belongs to something else
+type NotOwnedError struct {
+	Key client.ObjectKey
+}
+
+func (e *NotOwnedError) Error() string {
+	return fmt.Sprintf("secret %s exists and does not belong to this resource", e.Key)
+}
+
+// IsNotOwned reports whether err is or wraps a *NotOwnedError
+func IsNotOwned(err error) bool {
+	var notOwned *NotOwnedError
+	return errors.As(err, &notOwned)
+}
+
+// Tracking names the labels that record the owner of a Secret. Owner references cannot cross
+// namespaces, so the labels are what ties a Secret in another namespace to its owner.
+type Tracking struct {
+	NameLabel      string
+	NamespaceLabel string
+}
+
+// SetOwner labels the Secret with its owner and sets a controller reference when the Secret lives
+// in the owner's namespace
+func (t Tracking) SetOwner(owner, secret client.Object, scheme *runtime.Scheme) error {
+	labels := secret.GetLabels()
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	labels[t.NameLabel] = owner.GetName()
+	labels[t.NamespaceLabel] = owner.GetNamespace()
+	secret.SetLabels(labels)
+
+	if secret.GetNamespace() != owner.GetNamespace() {
+		return nil
+	}
+	return controllerutil.SetControllerReference(owner, secret, scheme)
+}
+
+// Owns reports whether a Secret belongs to the owner: it is controlled by the owner, or carries the
+// owner's tracking labels and has no other controller
+func (t Tracking) Owns(owner, secret client.Object) bool {
+	if metav1.IsControlledBy(secret, owner) {
+		return true
+	}
+	if metav1.GetControllerOf(secret) != nil {
+		return false
+	}
+	labels := secret.GetLabels()
+	return labels[t.NameLabel] == owner.GetName() && labels[t.NamespaceLabel] == owner.GetNamespace()
+}
+
+// Get returns the Secret and whether it exists; a missing Secret is returned empty so its Data can
+// be read without a nil check
+func Get(ctx context.Context, c client.Reader, key client.ObjectKey) (*corev1.Secret, bool, error) {
+	secret := &corev1.Secret{}
+	if err := c.Get(ctx, key, secret); err != nil {
+		if apierrors.IsNotFound(err) {
+			return &corev1.Secret{}, false, nil
+		}
+		return nil, false, fmt.Errorf("failed to get secret %s: %w", key, err)
+	}
+	return secret, true, nil
+}
+
+// GetOwned is Get for a Secret the owner writes. A Secret of that name that does not belong to the
+// owner is a *NotOwnedError rather than being adopted and overwritten.
+func (t Tracking) GetOwned(ctx context.Context, c client.Reader, key client.ObjectKey, owner client.Object) (*corev1.Secret, bool, error) {
+	secret, exists, err := Get(ctx, c, key)
+	if err != nil || !exists {
+		return secret, exists, err
+	}
+	if !t.Owns(owner, secret) {
+		return nil, false, &NotOwnedError{Key: key}
+	}
+	return secret, true, nil
+}
@@ -0,0 +1,141 @@
+package secrets
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var tracking = Tracking{NameLabel: "example.com/owner-name", NamespaceLabel: "example.com/owner-namespace"}
+
+// owner stands in for a custom resource; any namespaced type known to the scheme will do
+func owner(namespace, name string) *corev1.ConfigMap {
+	return &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+		Namespace: namespace,
+		Name:      name,
+		UID:       types.UID(namespace + "/" + name),
+	}}
+}
+
+func TestSetOwner(t *testing.T) {
+	app := owner("apps", "app")
+
+	tests := []struct {
+		name          string
+		namespace     string
+		wantReference bool
+	}{
+		{name: "Same namespace", namespace: "apps", wantReference: true},
+		{name: "Other namespace", namespace: "nats", wantReference: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			secret := New(tt.namespace, "app-creds", nil)
+			if err := tracking.SetOwner(app, secret, clientgoscheme.Scheme); err != nil {
+				t.Fatalf("SetOwner() error = %v", err)
+			}
+			if secret.Labels[tracking.NameLabel] != "app" || secret.Labels[tracking.NamespaceLabel] != "apps" {
+				t.Errorf("SetOwner() labels = %v", secret.Labels)
+			}
+			if got := metav1.IsControlledBy(secret, app); got != tt.wantReference {
+				t.Errorf("controller reference set = %v, want %v", got, tt.wantReference)
+			}
+			if !tracking.Owns(app, secret) {
+				t.Error("Owns() = false after SetOwner()")
+			}
+		})
+	}
+}
+
+func TestOwns(t *testing.T) {
+	app := owner("apps", "app")
+	other := owner("apps", "other")
+
+	controlledBy := func(o client.Object) *corev1.Secret {
+		secret := New("apps", "app-creds", nil)
+		isController := true
+		secret.OwnerReferences = []metav1.OwnerReference{{
+			APIVersion: "v1", Kind: "ConfigMap", Name: o.GetName(), UID: o.GetUID(), Controller: &isController,
+		}}
+		return secret
+	}
+	labelled := func(name, namespace string) *corev1.Secret {
+		secret := New("nats", "app-creds", nil)
+		secret.Labels = map[string]string{tracking.NameLabel: name, tracking.NamespaceLabel: namespace}
+		return secret
+	}
+
+	tests := []struct {
+		name   string
+		secret *corev1.Secret
+		want   bool
+	}{
+		{name: "Controlled by the owner", secret: controlledBy(app), want: true},
+		{name: "Controlled by another resource", secret: controlledBy(other), want: false},
+		{name: "Tracking labels of the owner", secret: labelled("app", "apps"), want: true},
+		{name: "Tracking labels of a namesake in another namespace", secret: labelled("app", "billing"), want: false},
+		{name: "Unlabelled", secret: New("nats", "app-creds", nil), want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tracking.Owns(app, tt.secret); got != tt.want {
+				t.Errorf("Owns() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetOwned(t *testing.T) {
+	ctx := context.Background()
+	app := owner("apps", "app")
+
+	owned := New("apps", "app-creds", FromStrings(map[string]string{"USERNAME": "app"}))
+	if err := tracking.SetOwner(app, owned, clientgoscheme.Scheme); err != nil {
+		t.Fatal(err)
+	}
+	foreign := New("apps", "shared", FromStrings(map[string]string{"token": "x"}))
+	c := fake.NewClientBuilder().WithObjects(owned, foreign).Build()
+
+	tests := []struct {
+		name         string
+		key          client.ObjectKey
+		wantExists   bool
+		wantData     string
+		wantNotOwned bool
+	}{
+		{name: "Owned", key: client.ObjectKeyFromObject(owned), wantExists: true, wantData: "app"},
+		{name: "Missing", key: client.ObjectKey{Namespace: "apps", Name: "missing"}},
+		{name: "Belongs to something else", key: client.ObjectKeyFromObject(foreign), wantNotOwned: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			secret, exists, err := tracking.GetOwned(ctx, c, tt.key, app)
+			var notOwned *NotOwnedError
+			if tt.wantNotOwned {
+				if !errors.As(err, &notOwned) || notOwned.Key != tt.key {
+					t.Fatalf("GetOwned() error = %v, want NotOwnedError for %s", err, tt.key)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GetOwned() error = %v", err)
+			}
+			if exists != tt.wantExists {
+				t.Errorf("GetOwned() exists = %v, want %v", exists, tt.wantExists)
+			}
+			if got := string(secret.Data["USERNAME"]); got != tt.wantData {
+				t.Errorf("GetOwned() USERNAME = %q, want %q", got, tt.wantData)
+			}
+		})
+	}
+}
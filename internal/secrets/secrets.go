@@ -0,0 +1,53 @@
+package secrets
+
+import (
+	"bytes"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// New returns a Secret holding a copy of data. Secrets the operator writes only ever set Data:
+// StringData is write-only, so it cannot be compared with the live Secret, and mixing it with
+// Data lets a stale key of one shadow the other.
+func New(namespace, name string, data map[string][]byte) *corev1.Secret {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+		Data: make(map[string][]byte, len(data)),
+	}
+	for k, v := range data {
+		secret.Data[k] = append([]byte(nil), v...)
+	}
+	return secret
+}
+
+// FromStrings converts string values to Secret data
+func FromStrings(values map[string]string) map[string][]byte {
+	data := make(map[string][]byte, len(values))
+	for k, v := range values {
+		data[k] = []byte(v)
+	}
+	return data
+}
+
+// ChangedKeys returns the sorted keys of desired that the existing Secret lacks or holds with
+// another value. A nil existing Secret is being created, so every key changed. Keys only the
+// existing Secret has are not changes: applying desired leaves them in place.
+func ChangedKeys(existing *corev1.Secret, desired map[string][]byte) []string {
+	var changed []string
+	for k, v := range desired {
+		if existing == nil {
+			changed = append(changed, k)
+			continue
+		}
+		if current, ok := existing.Data[k]; !ok || !bytes.Equal(current, v) {
+			changed = append(changed, k)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
@@ -0,0 +1,85 @@
+package secrets
+
+import (
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestNew(t *testing.T) {
+	data := map[string][]byte{"USERNAME": []byte("app")}
+	secret := New("default", "app-user-creds", data)
+
+	if secret.Namespace != "default" || secret.Name != "app-user-creds" {
+		t.Errorf("New() = %s/%s, want default/app-user-creds", secret.Namespace, secret.Name)
+	}
+	if secret.StringData != nil {
+		t.Errorf("New() set StringData = %v", secret.StringData)
+	}
+	if !reflect.DeepEqual(secret.Data, data) {
+		t.Errorf("New() Data = %v, want %v", secret.Data, data)
+	}
+
+	data["USERNAME"][0] = 'x'
+	if string(secret.Data["USERNAME"]) != "app" {
+		t.Error("New() shares data with the caller")
+	}
+}
+
+func TestFromStrings(t *testing.T) {
+	got := FromStrings(map[string]string{"USERNAME": "app", "PASSWORD": ""})
+	want := map[string][]byte{"USERNAME": []byte("app"), "PASSWORD": []byte("")}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("FromStrings() = %v, want %v", got, want)
+	}
+}
+
+func TestChangedKeys(t *testing.T) {
+	desired := FromStrings(map[string]string{"USERNAME": "app", "PASSWORD": "secret", "NATS_URL": "nats://nats:4222"})
+
+	tests := []struct {
+		name     string
+		existing *corev1.Secret
+		want     []string
+	}{
+		{
+			name:     "Create",
+			existing: nil,
+			want:     []string{"NATS_URL", "PASSWORD", "USERNAME"},
+		},
+		{
+			name:     "Up to date",
+			existing: New("default", "app", desired),
+			want:     nil,
+		},
+		{
+			name:     "Update a value",
+			existing: New("default", "app", FromStrings(map[string]string{"USERNAME": "app", "PASSWORD": "old", "NATS_URL": "nats://nats:4222"})),
+			want:     []string{"PASSWORD"},
+		},
+		{
+			name:     "Update a missing key",
+			existing: New("default", "app", FromStrings(map[string]string{"USERNAME": "app", "PASSWORD": "secret"})),
+			want:     []string{"NATS_URL"},
+		},
+		{
+			name:     "Merge with keys of other writers",
+			existing: New("default", "app", FromStrings(map[string]string{"USERNAME": "app", "PASSWORD": "secret", "NATS_URL": "nats://nats:4222", "extra": "kept"})),
+			want:     nil,
+		},
+		{
+			name:     "Empty value differs from a missing key",
+			existing: New("default", "app", FromStrings(map[string]string{"USERNAME": "app", "NATS_URL": "nats://nats:4222"})),
+			want:     []string{"PASSWORD"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ChangedKeys(tt.existing, desired); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ChangedKeys() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
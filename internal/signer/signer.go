@@ -0,0 +1,215 @@
+package signer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/nats-io/nkeys"
+)
+
+// DefaultTimeout bounds one call to the signer
+const DefaultTimeout = 10 * time.Second
+
+// maxResponseSize caps the response body read from the signer
+const maxResponseSize = 1 << 16
+
+// Kinds of keys held by the signer
+const (
+	KindOperator = "Operator"
+	KindAccount  = "Account"
+)
+
+// Operations a signer answers
+const (
+	OperationPublicKey = "publicKey"
+	OperationSign      = "sign"
+)
+
+// ErrNoPrivateKey is returned when the seed or private key of a remote key is requested
+var ErrNoPrivateKey = errors.New("private key is held by the external signer")
+
+// Key identifies a key held by the signer
+type Key struct {
+	// Kind is Operator or Account
+	Kind string `json:"kind"`
+
+	// Namespace and Name of the NatsAuthConfig or NatsAccount the key belongs to
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+
+	// AuthConfig is the namespace/name of the NatsAuthConfig the key is used under
+	AuthConfig string `json:"authConfig"`
+}
+
+// Request is the body POSTed to the signer
+type Request struct {
+	Operation string `json:"operation"`
+	Key
+
+	// PublicKey is the key expected to sign (sign only)
+	PublicKey string `json:"publicKey,omitempty"`
+
+	// Data is the input to sign, e.g. the header and claims of a JWT (sign only)
+	Data []byte `json:"data,omitempty"`
+}
+
+// Response is the body the signer answers with
+type Response struct {
+	// PublicKey of the requested key (publicKey only)
+	PublicKey string `json:"publicKey,omitempty"`
+
+	// Signature is the ed25519 signature of the data (sign only)
+	Signature []byte `json:"signature,omitempty"`
+
+	// Message explains a refusal
+	Message string `json:"message,omitempty"`
+}
+
+// HTTPSigner holds no private keys; it asks an HTTP service, e.g. in front of an HSM, for the
+// public keys of operators and accounts and for signatures made with them
+type HTTPSigner struct {
+	// URL requests are POSTed to
+	URL string
+
+	// Client performs the call; a client with DefaultTimeout when nil
+	Client *http.Client
+}
+
+// PublicKey returns the public key the signer holds for key, creating it on the signer if needed
+func (s *HTTPSigner) PublicKey(ctx context.Context, key Key) (string, error) {
+	resp, err := s.call(ctx, Request{Operation: OperationPublicKey, Key: key})
+	if err != nil {
+		return "", err
+	}
+	if err := validatePublicKey(key.Kind, resp.PublicKey); err != nil {
+		return "", err
+	}
+	return resp.PublicKey, nil
+}
+
+// KeyPair returns the key as an nkeys.KeyPair whose signatures are made by the signer. Signatures
+// are verified against publicKey before they are returned.
+func (s *HTTPSigner) KeyPair(ctx context.Context, key Key, publicKey string) (nkeys.KeyPair, error) {
+	if err := validatePublicKey(key.Kind, publicKey); err != nil {
+		return nil, err
+	}
+	verifier, err := nkeys.FromPublicKey(publicKey)
+	if err != nil {
+		return nil, err
+	}
+	return &remoteKeyPair{ctx: ctx, signer: s, key: key, publicKey: publicKey, verifier: verifier}, nil
+}
+
+// call POSTs the request and decodes the response
+func (s *HTTPSigner) call(ctx context.Context, req Request) (*Response, error) {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode signer request: %w", err)
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, s.URL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	client := s.Client
+	if client == nil {
+		client = &http.Client{Timeout: DefaultTimeout}
+	}
+	httpResp, err := client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("external signer failed: %w", err)
+	}
+	defer httpResp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(httpResp.Body, maxResponseSize))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read signer response: %w", err)
+	}
+	resp := &Response{}
+	decodeErr := json.Unmarshal(data, resp)
+	if httpResp.StatusCode != http.StatusOK {
+		if decodeErr == nil && resp.Message != "" {
+			return nil, fmt.Errorf("external signer answered %s: %s", httpResp.Status, resp.Message)
+		}
+		return nil, fmt.Errorf("external signer answered %s", httpResp.Status)
+	}
+	if decodeErr != nil {
+		return nil, fmt.Errorf("failed to decode signer response: %w", decodeErr)
+	}
+	return resp, nil
+}
+
+// validatePublicKey checks that a public key returned for a key of kind has the matching type
+func validatePublicKey(kind, publicKey string) error {
+	var valid bool
+	switch kind {
+	case KindOperator:
+		valid = nkeys.IsValidPublicOperatorKey(publicKey)
+	case KindAccount:
+		valid = nkeys.IsValidPublicAccountKey(publicKey)
+	default:
+		return fmt.Errorf("unsupported key kind %q", kind)
+	}
+	if !valid {
+		return fmt.Errorf("external signer returned %q, which is not a public %s key", publicKey, kind)
+	}
+	return nil
+}
+
+// remoteKeyPair is a signing-only nkeys.KeyPair backed by the signer. The context of the
+// reconcile it was created in bounds its calls, since nkeys.KeyPair.Sign takes none.
+type remoteKeyPair struct {
+	ctx       context.Context
+	signer    *HTTPSigner
+	key       Key
+	publicKey string
+	verifier  nkeys.KeyPair
+}
+
+func (kp *remoteKeyPair) PublicKey() (string, error) {
+	return kp.publicKey, nil
+}
+
+func (kp *remoteKeyPair) Sign(input []byte) ([]byte, error) {
+	resp, err := kp.signer.call(kp.ctx, Request{Operation: OperationSign, Key: kp.key, PublicKey: kp.publicKey, Data: input})
+	if err != nil {
+		return nil, err
+	}
+	if err := kp.verifier.Verify(input, resp.Signature); err != nil {
+		return nil, fmt.Errorf("external signer returned a signature not made by %s: %w", kp.publicKey, err)
+	}
+	return resp.Signature, nil
+}
+
+func (kp *remoteKeyPair) Verify(input []byte, sig []byte) error {
+	return kp.verifier.Verify(input, sig)
+}
+
+func (kp *remoteKeyPair) Seed() ([]byte, error) {
+	return nil, ErrNoPrivateKey
+}
+
+func (kp *remoteKeyPair) PrivateKey() ([]byte, error) {
+	return nil, ErrNoPrivateKey
+}
+
+func (kp *remoteKeyPair) Wipe() {}
+
+func (kp *remoteKeyPair) Seal([]byte, string) ([]byte, error) {
+	return nil, nkeys.ErrInvalidNKeyOperation
+}
+
+func (kp *remoteKeyPair) SealWithRand([]byte, string, io.Reader) ([]byte, error) {
+	return nil, nkeys.ErrInvalidNKeyOperation
+}
+
+func (kp *remoteKeyPair) Open([]byte, string) ([]byte, error) {
+	return nil, nkeys.ErrInvalidNKeyOperation
+}
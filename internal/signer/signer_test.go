@@ -0,0 +1,137 @@
+package signer
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/nats-io/jwt/v2"
+	"github.com/nats-io/nkeys"
+)
+
+// signerServer holds one key pair per kind and answers like an external signer; a non-nil
+// forge key signs instead of the held key
+func signerServer(t *testing.T, forge nkeys.KeyPair) (*HTTPSigner, map[string]nkeys.KeyPair) {
+	t.Helper()
+	operator, _ := nkeys.CreateOperator()
+	account, _ := nkeys.CreateAccount()
+	keys := map[string]nkeys.KeyPair{KindOperator: operator, KindAccount: account}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req Request
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("Signer received invalid request: %v", err)
+		}
+		kp, ok := keys[req.Kind]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			_ = json.NewEncoder(w).Encode(Response{Message: "unknown key"})
+			return
+		}
+		resp := Response{}
+		switch req.Operation {
+		case OperationPublicKey:
+			resp.PublicKey, _ = kp.PublicKey()
+		case OperationSign:
+			if forge != nil {
+				kp = forge
+			}
+			resp.Signature, _ = kp.Sign(req.Data)
+		}
+		_ = json.NewEncoder(w).Encode(resp)
+	}))
+	t.Cleanup(srv.Close)
+	return &HTTPSigner{URL: srv.URL}, keys
+}
+
+func TestHTTPSignerSignsJWT(t *testing.T) {
+	s, keys := signerServer(t, nil)
+	ctx := context.Background()
+	key := Key{Kind: KindAccount, Namespace: "default", Name: "app", AuthConfig: "default/nats"}
+
+	pub, err := s.PublicKey(ctx, key)
+	if err != nil {
+		t.Fatalf("PublicKey() error = %v", err)
+	}
+	if want, _ := keys[KindAccount].PublicKey(); pub != want {
+		t.Fatalf("PublicKey() = %s, want %s", pub, want)
+	}
+
+	kp, err := s.KeyPair(ctx, key, pub)
+	if err != nil {
+		t.Fatalf("KeyPair() error = %v", err)
+	}
+	if _, err := kp.Seed(); err != ErrNoPrivateKey {
+		t.Errorf("Seed() error = %v, want ErrNoPrivateKey", err)
+	}
+
+	user, _ := nkeys.CreateUser()
+	userPub, _ := user.PublicKey()
+	token, err := jwt.NewUserClaims(userPub).Encode(kp)
+	if err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+	claims, err := jwt.DecodeUserClaims(token)
+	if err != nil {
+		t.Fatalf("DecodeUserClaims() error = %v", err)
+	}
+	if claims.Issuer != pub {
+		t.Errorf("issuer = %s, want %s", claims.Issuer, pub)
+	}
+}
+
+func TestHTTPSignerErrors(t *testing.T) {
+	ctx := context.Background()
+	forged, _ := nkeys.CreateAccount()
+
+	tests := []struct {
+		name    string
+		forge   nkeys.KeyPair
+		call    func(s *HTTPSigner, keys map[string]nkeys.KeyPair) error
+		wantErr string
+	}{
+		{
+			name: "Signer refuses the key",
+			call: func(s *HTTPSigner, _ map[string]nkeys.KeyPair) error {
+				_, err := s.PublicKey(ctx, Key{Kind: "Unknown"})
+				return err
+			},
+			wantErr: "404 Not Found: unknown key",
+		},
+		{
+			name: "Public key of another type",
+			call: func(s *HTTPSigner, keys map[string]nkeys.KeyPair) error {
+				pub, _ := keys[KindOperator].PublicKey()
+				_, err := s.KeyPair(ctx, Key{Kind: KindAccount}, pub)
+				return err
+			},
+			wantErr: "not a public Account key",
+		},
+		{
+			name:  "Signature made by another key",
+			forge: forged,
+			call: func(s *HTTPSigner, keys map[string]nkeys.KeyPair) error {
+				pub, _ := keys[KindAccount].PublicKey()
+				kp, err := s.KeyPair(ctx, Key{Kind: KindAccount}, pub)
+				if err != nil {
+					return err
+				}
+				_, err = kp.Sign([]byte("payload"))
+				return err
+			},
+			wantErr: "signature not made by",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, keys := signerServer(t, tt.forge)
+			if err := tt.call(s, keys); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package subject
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Validate checks that a NATS subject is well formed: no whitespace, no empty
+// tokens, and wildcards only as whole tokens with '>' as the last token
+func Validate(subject string) error {
+	if subject == "" {
+		return fmt.Errorf("subject is empty")
+	}
+
+	if i := strings.IndexFunc(subject, unicode.IsSpace); i >= 0 {
+		return fmt.Errorf("subject %q contains whitespace at position %d", subject, i)
+	}
+
+	tokens := strings.Split(subject, ".")
+	for i, token := range tokens {
+		if token == "" {
+			return fmt.Errorf("subject %q contains an empty token at position %d", subject, i+1)
+		}
+
+		if strings.ContainsAny(token, "*>") && len(token) > 1 {
+			return fmt.Errorf("subject %q has wildcard inside token %q; wildcards must be whole tokens", subject, token)
+		}
+
+		if token == ">" && i != len(tokens)-1 {
+			return fmt.Errorf("subject %q uses '>' before the last token", subject)
+		}
+	}
+
+	return nil
+}
+
+// ValidateList validates each subject in a list, naming the offending entry
+// by field and index in the returned error
+func ValidateList(field string, subjects []string) error {
+	for i, s := range subjects {
+		if err := Validate(s); err != nil {
+			return fmt.Errorf("%s[%d]: %w", field, i, err)
+		}
+	}
+	return nil
+}
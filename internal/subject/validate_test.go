@@ -0,0 +1,50 @@
+package subject
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		subject string
+		wantErr bool
+	}{
+		{name: "Literal subject", subject: "foo.bar", wantErr: false},
+		{name: "Single token wildcard", subject: "foo.*.baz", wantErr: false},
+		{name: "Full wildcard", subject: "foo.>", wantErr: false},
+		{name: "JetStream API", subject: "$JS.API.>", wantErr: false},
+		{name: "Empty subject", subject: "", wantErr: true},
+		{name: "Empty token", subject: "foo..bar", wantErr: true},
+		{name: "Leading dot", subject: ".foo", wantErr: true},
+		{name: "Trailing dot", subject: "foo.", wantErr: true},
+		{name: "Whitespace", subject: "foo bar", wantErr: true},
+		{name: "Partial wildcard", subject: "foo.ba*", wantErr: true},
+		{name: "Partial full wildcard", subject: "foo.>bar", wantErr: true},
+		{name: "Full wildcard not last", subject: "foo.>.bar", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(tt.subject)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate(%q) error = %v, wantErr %v", tt.subject, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateList(t *testing.T) {
+	err := ValidateList("publishAllow", []string{"foo.>", "bar..baz"})
+	if err == nil {
+		t.Fatal("ValidateList() expected error for invalid entry")
+	}
+	if !strings.Contains(err.Error(), "publishAllow[1]") {
+		t.Errorf("ValidateList() error should name the offending entry, got %v", err)
+	}
+
+	if err := ValidateList("publishAllow", []string{"foo.>", "bar.*"}); err != nil {
+		t.Errorf("ValidateList() unexpected error = %v", err)
+	}
+}
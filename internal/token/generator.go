@@ -2,18 +2,30 @@ package token
 
 import (
 	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
 	"encoding/base64"
 	"fmt"
+	"io"
+	"strings"
+	"text/template"
+
+	"k8s.io/apimachinery/pkg/util/validation"
 )
 
 // GenerateToken generates a random secure token
 func GenerateToken(length int) (string, error) {
+	return GenerateTokenFrom(rand.Reader, length)
+}
+
+// GenerateTokenFrom generates a token of length random bytes read from r, base64 encoded
+func GenerateTokenFrom(r io.Reader, length int) (string, error) {
 	if length <= 0 {
 		length = 32
 	}
 
 	b := make([]byte, length)
-	_, err := rand.Read(b)
+	_, err := io.ReadFull(r, b)
 	if err != nil {
 		return "", fmt.Errorf("failed to generate random token: %w", err)
 	}
@@ -21,22 +33,71 @@ func GenerateToken(length int) (string, error) {
 	return base64.URLEncoding.EncodeToString(b), nil
 }
 
-// GeneratePassword generates a random password
+// GeneratePassword generates a random password using the default policy
 func GeneratePassword() (string, error) {
-	// Generate a 24-byte random password (32 characters when base64 encoded)
-	return GenerateToken(24)
+	return GeneratePasswordWithPolicy(DefaultPasswordPolicy())
+}
+
+// DefaultUsernameTemplate renders generated usernames as the resource name and a hash of its UID
+const DefaultUsernameTemplate = "{{ .Name }}-{{ .Hash }}"
+
+// usernameHashLength is the number of base32 characters of the UID hash in a username
+const usernameHashLength = 10
+
+// usernameHashEncoding is lowercase base32 without padding, so the hash is DNS-safe
+var usernameHashEncoding = base32.NewEncoding("abcdefghijklmnopqrstuvwxyz234567").WithPadding(base32.NoPadding)
+
+// UsernameData identifies the resource a username is generated for
+type UsernameData struct {
+	Name      string
+	Namespace string
+	UID       string
 }
 
-// GenerateUsername generates a random username
-func GenerateUsername(prefix string) (string, error) {
-	token, err := GenerateToken(8)
+// GenerateUsername renders a username template for a resource. The template sees the resource as
+// {{ .Name }} and {{ .Namespace }} and a hash of its UID as {{ .Hash }}, so the same resource always
+// gets the same username and a recreated resource gets a new one. The result must be a DNS-1123
+// subdomain; a longer username is truncated and ends in a hash of the full one instead. An empty
+// template uses DefaultUsernameTemplate.
+func GenerateUsername(tmpl string, data UsernameData) (string, error) {
+	if data.UID == "" {
+		return "", fmt.Errorf("cannot generate a username for %s/%s without a UID", data.Namespace, data.Name)
+	}
+	if tmpl == "" {
+		tmpl = DefaultUsernameTemplate
+	}
+	t, err := template.New("username").Option("missingkey=error").Parse(tmpl)
 	if err != nil {
-		return "", err
+		return "", fmt.Errorf("invalid username template %q: %w", tmpl, err)
 	}
 
-	if prefix == "" {
-		prefix = "user"
+	sum := sha256.Sum256([]byte(data.UID))
+	values := struct{ Name, Namespace, Hash string }{
+		Name:      data.Name,
+		Namespace: data.Namespace,
+		Hash:      usernameHashEncoding.EncodeToString(sum[:])[:usernameHashLength],
+	}
+	var sb strings.Builder
+	if err := t.Execute(&sb, values); err != nil {
+		return "", fmt.Errorf("failed to render username template %q: %w", tmpl, err)
 	}
 
-	return fmt.Sprintf("%s-%s", prefix, token[:12]), nil
+	username := truncateUsername(sb.String())
+	if errs := validation.IsDNS1123Subdomain(username); len(errs) > 0 {
+		return "", fmt.Errorf("username template %q rendered invalid username %q: %s", tmpl, username, strings.Join(errs, ", "))
+	}
+	return username, nil
+}
+
+// truncateUsername shortens a username longer than a DNS-1123 subdomain allows, such as one
+// rendered from a long resource name, keeping the usernames of different resources apart by
+// replacing the cut-off part with a hash of the full username
+func truncateUsername(username string) string {
+	if len(username) <= validation.DNS1123SubdomainMaxLength {
+		return username
+	}
+	sum := sha256.Sum256([]byte(username))
+	hash := usernameHashEncoding.EncodeToString(sum[:])[:usernameHashLength]
+	prefix := strings.TrimRight(username[:validation.DNS1123SubdomainMaxLength-len(hash)-1], "-.")
+	return prefix + "-" + hash
 }
@@ -1,6 +1,8 @@
 package token
 
 import (
+	"bytes"
+	"regexp"
 	"strings"
 	"testing"
 )
@@ -67,43 +69,128 @@ func TestGeneratePassword(t *testing.T) {
 }
 
 func TestGenerateUsername(t *testing.T) {
+	app := UsernameData{Name: "app", Namespace: "default", UID: "6f1c2e9a-0b7d-4a8e-9f31-2c5d8e7a1b40"}
+
 	tests := []struct {
-		name       string
-		prefix     string
-		wantPrefix string
-		wantErr    bool
+		name    string
+		tmpl    string
+		data    UsernameData
+		want    *regexp.Regexp
+		wantErr string
 	}{
 		{
-			name:       "Generate with custom prefix",
-			prefix:     "app",
-			wantPrefix: "app-",
-			wantErr:    false,
+			name: "Default template",
+			data: app,
+			want: regexp.MustCompile(`^app-[a-z2-7]{10}$`),
+		},
+		{
+			name: "Prefix and suffix",
+			tmpl: "nats-{{ .Namespace }}-{{ .Name }}-{{ .Hash }}-svc",
+			data: app,
+			want: regexp.MustCompile(`^nats-default-app-[a-z2-7]{10}-svc$`),
+		},
+		{
+			name:    "Missing UID",
+			data:    UsernameData{Name: "app", Namespace: "default"},
+			wantErr: "without a UID",
+		},
+		{
+			name:    "Unknown field",
+			tmpl:    "{{ .Kind }}",
+			data:    app,
+			wantErr: "failed to render",
 		},
 		{
-			name:       "Generate with empty prefix (should use default)",
-			prefix:     "",
-			wantPrefix: "user-",
-			wantErr:    false,
+			name:    "Not DNS-safe",
+			tmpl:    "{{ .Name }}_{{ .Hash }}",
+			data:    app,
+			wantErr: "rendered invalid username",
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			username, err := GenerateUsername(tt.prefix)
-			if (err != nil) != tt.wantErr {
-				t.Errorf("GenerateUsername() error = %v, wantErr %v", err, tt.wantErr)
+			username, err := GenerateUsername(tt.tmpl, tt.data)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("GenerateUsername() error = %v, want it to contain %q", err, tt.wantErr)
+				}
 				return
 			}
-
-			if !tt.wantErr {
-				if username == "" {
-					t.Error("GenerateUsername() returned empty username")
-				}
-
-				if !strings.HasPrefix(username, tt.wantPrefix) {
-					t.Errorf("GenerateUsername() = %v, want prefix %v", username, tt.wantPrefix)
-				}
+			if err != nil {
+				t.Fatalf("GenerateUsername() error = %v", err)
+			}
+			if !tt.want.MatchString(username) {
+				t.Errorf("GenerateUsername() = %q, want match for %s", username, tt.want)
 			}
 		})
 	}
 }
+
+func TestGenerateUsernameStable(t *testing.T) {
+	data := UsernameData{Name: "app", Namespace: "default", UID: "uid-1"}
+	first, _ := GenerateUsername("", data)
+	second, _ := GenerateUsername("", data)
+	if first != second {
+		t.Errorf("GenerateUsername() = %q then %q, want the same username for the same UID", first, second)
+	}
+
+	data.UID = "uid-2"
+	recreated, _ := GenerateUsername("", data)
+	if recreated == first {
+		t.Errorf("GenerateUsername() = %q for another UID, want a new username", recreated)
+	}
+}
+
+func TestGenerateUsernameLongName(t *testing.T) {
+	// The default template adds 11 characters, so a 242 character name fits exactly
+	fits := UsernameData{Name: strings.Repeat("a", 242), Namespace: "default", UID: "uid-1"}
+	username, err := GenerateUsername("", fits)
+	if err != nil {
+		t.Fatalf("GenerateUsername() error = %v", err)
+	}
+	if len(username) != 253 || !strings.HasPrefix(username, fits.Name+"-") {
+		t.Errorf("GenerateUsername() = %q (%d characters), want the name and the UID hash", username, len(username))
+	}
+
+	for _, name := range []string{strings.Repeat("a", 243), strings.Repeat("a", 253), strings.Repeat("a", 200) + "." + strings.Repeat("b", 52)} {
+		data := UsernameData{Name: name, Namespace: "default", UID: "uid-1"}
+		username, err := GenerateUsername("", data)
+		if err != nil {
+			t.Fatalf("GenerateUsername() of a %d character name error = %v", len(name), err)
+		}
+		if len(username) > 253 {
+			t.Errorf("GenerateUsername() = %d characters, want at most 253", len(username))
+		}
+		again, _ := GenerateUsername("", data)
+		if again != username {
+			t.Errorf("GenerateUsername() = %q then %q, want a stable username", username, again)
+		}
+		data.UID = "uid-2"
+		if recreated, _ := GenerateUsername("", data); recreated == username {
+			t.Errorf("GenerateUsername() = %q for another UID, want a new username", recreated)
+		}
+	}
+}
+
+func TestGenerateFrom(t *testing.T) {
+	zeros := bytes.NewReader(make([]byte, 64))
+	token, err := GenerateTokenFrom(zeros, 6)
+	if err != nil || token != "AAAAAAAA" {
+		t.Errorf("GenerateTokenFrom() = %q, %v, want AAAAAAAA", token, err)
+	}
+
+	if _, err := GenerateTokenFrom(bytes.NewReader(make([]byte, 4)), 6); err == nil {
+		t.Error("GenerateTokenFrom() of a short reader succeeded")
+	}
+
+	policy := PasswordPolicy{Length: 16, Charset: CharsetAlphanumeric}
+	a, err := policy.GenerateFrom(bytes.NewReader(bytes.Repeat([]byte{7}, 256)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := policy.GenerateFrom(bytes.NewReader(bytes.Repeat([]byte{7}, 256)))
+	if a != b || len(a) != 16 {
+		t.Errorf("GenerateFrom() = %q and %q, want the same 16 characters from the same bytes", a, b)
+	}
+}
@@ -0,0 +1,247 @@
+package token
+
+import (
+	"crypto/rand"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"strings"
+)
+
+// Password character sets
+const (
+	CharsetAlphanumeric = "alphanumeric"
+	CharsetBase64URL    = "base64url"
+	CharsetASCII        = "ascii"
+)
+
+const (
+	lowerChars  = "abcdefghijklmnopqrstuvwxyz"
+	upperChars  = "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	digitChars  = "0123456789"
+	symbolChars = "!#%&*+-.:=?@^_~"
+
+	// ambiguousChars are easily confused when a password is read or typed by hand
+	ambiguousChars = "0O1lI"
+
+	// DefaultPasswordLength matches the 32 characters of the former base64 encoded 24-byte password
+	DefaultPasswordLength = 32
+
+	// MinPasswordLength is the shortest password the generator produces
+	MinPasswordLength = 12
+)
+
+// PasswordPolicy controls how passwords are generated and which supplied passwords are accepted
+type PasswordPolicy struct {
+	// Length is the number of characters in a generated password
+	Length int
+
+	// Charset is one of alphanumeric, base64url or ascii
+	Charset string
+
+	// RequireSymbols guarantees at least one symbol in a generated password
+	RequireSymbols bool
+
+	// ExcludeAmbiguous drops characters such as 0, O, 1, l and I
+	ExcludeAmbiguous bool
+
+	// MinEntropyBits is the minimum estimated entropy of a supplied password; 0 disables the check
+	MinEntropyBits int
+}
+
+// DefaultPasswordPolicy returns the policy used when nothing is configured
+func DefaultPasswordPolicy() PasswordPolicy {
+	return PasswordPolicy{
+		Length:  DefaultPasswordLength,
+		Charset: CharsetBase64URL,
+	}
+}
+
+// Merge returns p with the set fields of override applied on top
+func (p PasswordPolicy) Merge(override PasswordPolicy) PasswordPolicy {
+	if override.Length > 0 {
+		p.Length = override.Length
+	}
+	if override.Charset != "" {
+		p.Charset = override.Charset
+	}
+	if override.MinEntropyBits > 0 {
+		p.MinEntropyBits = override.MinEntropyBits
+	}
+	p.RequireSymbols = p.RequireSymbols || override.RequireSymbols
+	p.ExcludeAmbiguous = p.ExcludeAmbiguous || override.ExcludeAmbiguous
+	return p
+}
+
+// Validate checks that the policy can generate passwords
+func (p PasswordPolicy) Validate() error {
+	if p.Length != 0 && p.Length < MinPasswordLength {
+		return fmt.Errorf("password length must be at least %d", MinPasswordLength)
+	}
+	switch p.Charset {
+	case "", CharsetAlphanumeric, CharsetBase64URL, CharsetASCII:
+	default:
+		return fmt.Errorf("unknown password charset %q", p.Charset)
+	}
+	if p.MinEntropyBits < 0 {
+		return fmt.Errorf("minimum entropy must not be negative")
+	}
+	return nil
+}
+
+// alphabet returns the characters a generated password is drawn from
+func (p PasswordPolicy) alphabet() string {
+	chars := lowerChars + upperChars + digitChars
+	switch p.Charset {
+	case CharsetAlphanumeric:
+	case CharsetASCII:
+		chars += symbolChars
+	default:
+		chars += "-_"
+	}
+	if p.RequireSymbols && p.Charset != CharsetASCII {
+		chars += symbolChars
+	}
+	if p.ExcludeAmbiguous {
+		chars = removeChars(chars, ambiguousChars)
+	}
+	return dedupe(chars)
+}
+
+// Satisfied reports whether password could have been generated under the policy,
+// so a stored password is kept until the policy changes
+func (p PasswordPolicy) Satisfied(password string) bool {
+	length := p.Length
+	if length <= 0 {
+		length = DefaultPasswordLength
+	}
+	if len(password) != length {
+		return false
+	}
+	alphabet := p.alphabet()
+	for _, c := range password {
+		if !strings.ContainsRune(alphabet, c) {
+			return false
+		}
+	}
+	if p.RequireSymbols && !strings.ContainsAny(password, symbolChars) {
+		return false
+	}
+	return true
+}
+
+// CheckEntropy returns an error when password falls below the policy's minimum entropy
+func (p PasswordPolicy) CheckEntropy(password string) error {
+	if p.MinEntropyBits <= 0 {
+		return nil
+	}
+	if bits := EstimateEntropy(password); bits < float64(p.MinEntropyBits) {
+		return fmt.Errorf("password entropy of %.0f bits is below the required %d bits", bits, p.MinEntropyBits)
+	}
+	return nil
+}
+
+// GeneratePasswordWithPolicy generates a random password following the policy
+func GeneratePasswordWithPolicy(p PasswordPolicy) (string, error) {
+	return p.GenerateFrom(rand.Reader)
+}
+
+// GenerateFrom generates a password following the policy from the random bytes of r
+func (p PasswordPolicy) GenerateFrom(r io.Reader) (string, error) {
+	if err := p.Validate(); err != nil {
+		return "", err
+	}
+	length := p.Length
+	if length <= 0 {
+		length = DefaultPasswordLength
+	}
+
+	alphabet := p.alphabet()
+	b := make([]byte, length)
+	for i := range b {
+		c, err := randomChar(r, alphabet)
+		if err != nil {
+			return "", err
+		}
+		b[i] = c
+	}
+
+	if p.RequireSymbols && !strings.ContainsAny(string(b), symbolChars) {
+		// Replace one random position with a symbol
+		c, err := randomChar(r, symbolChars)
+		if err != nil {
+			return "", err
+		}
+		n, err := rand.Int(r, big.NewInt(int64(length)))
+		if err != nil {
+			return "", fmt.Errorf("failed to generate random password: %w", err)
+		}
+		b[n.Int64()] = c
+	}
+
+	return string(b), nil
+}
+
+// EstimateEntropy estimates the entropy of a password in bits from its length and
+// the character classes it uses
+func EstimateEntropy(password string) float64 {
+	if password == "" {
+		return 0
+	}
+	pool := 0
+	var hasLower, hasUpper, hasDigit, hasOther bool
+	for _, c := range password {
+		switch {
+		case strings.ContainsRune(lowerChars, c):
+			hasLower = true
+		case strings.ContainsRune(upperChars, c):
+			hasUpper = true
+		case strings.ContainsRune(digitChars, c):
+			hasDigit = true
+		default:
+			hasOther = true
+		}
+	}
+	if hasLower {
+		pool += len(lowerChars)
+	}
+	if hasUpper {
+		pool += len(upperChars)
+	}
+	if hasDigit {
+		pool += len(digitChars)
+	}
+	if hasOther {
+		pool += 33 // printable ASCII punctuation and space
+	}
+	return float64(len([]rune(password))) * math.Log2(float64(pool))
+}
+
+func randomChar(r io.Reader, alphabet string) (byte, error) {
+	n, err := rand.Int(r, big.NewInt(int64(len(alphabet))))
+	if err != nil {
+		return 0, fmt.Errorf("failed to generate random password: %w", err)
+	}
+	return alphabet[n.Int64()], nil
+}
+
+func removeChars(s, drop string) string {
+	var sb strings.Builder
+	for _, c := range s {
+		if !strings.ContainsRune(drop, c) {
+			sb.WriteRune(c)
+		}
+	}
+	return sb.String()
+}
+
+func dedupe(s string) string {
+	var sb strings.Builder
+	for _, c := range s {
+		if !strings.ContainsRune(sb.String(), c) {
+			sb.WriteRune(c)
+		}
+	}
+	return sb.String()
+}
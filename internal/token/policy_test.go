@@ -0,0 +1,176 @@
+package token
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGeneratePasswordWithPolicy(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  PasswordPolicy
+		wantLen int
+		wantErr bool
+	}{
+		{
+			name:    "Default policy",
+			policy:  DefaultPasswordPolicy(),
+			wantLen: DefaultPasswordLength,
+		},
+		{
+			name:    "Zero value uses default length",
+			policy:  PasswordPolicy{},
+			wantLen: DefaultPasswordLength,
+		},
+		{
+			name:    "Alphanumeric without ambiguous characters",
+			policy:  PasswordPolicy{Length: 48, Charset: CharsetAlphanumeric, ExcludeAmbiguous: true},
+			wantLen: 48,
+		},
+		{
+			name:    "Required symbols",
+			policy:  PasswordPolicy{Length: 16, Charset: CharsetAlphanumeric, RequireSymbols: true},
+			wantLen: 16,
+		},
+		{
+			name:    "ASCII charset",
+			policy:  PasswordPolicy{Length: 20, Charset: CharsetASCII},
+			wantLen: 20,
+		},
+		{
+			name:    "Too short",
+			policy:  PasswordPolicy{Length: 8},
+			wantErr: true,
+		},
+		{
+			name:    "Unknown charset",
+			policy:  PasswordPolicy{Charset: "emoji"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			password, err := GeneratePasswordWithPolicy(tt.policy)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GeneratePasswordWithPolicy() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if len(password) != tt.wantLen {
+				t.Errorf("GeneratePasswordWithPolicy() length = %d, want %d", len(password), tt.wantLen)
+			}
+			if !tt.policy.Satisfied(password) {
+				t.Errorf("GeneratePasswordWithPolicy() = %q does not satisfy its own policy", password)
+			}
+			if tt.policy.ExcludeAmbiguous && strings.ContainsAny(password, ambiguousChars) {
+				t.Errorf("GeneratePasswordWithPolicy() = %q contains ambiguous characters", password)
+			}
+			if tt.policy.RequireSymbols && !strings.ContainsAny(password, symbolChars) {
+				t.Errorf("GeneratePasswordWithPolicy() = %q contains no symbol", password)
+			}
+			if strings.ContainsAny(password, "\"\\$ ") {
+				t.Errorf("GeneratePasswordWithPolicy() = %q contains characters unsafe in a NATS config", password)
+			}
+		})
+	}
+}
+
+func TestPasswordPolicySatisfied(t *testing.T) {
+	tests := []struct {
+		name     string
+		policy   PasswordPolicy
+		password string
+		want     bool
+	}{
+		{
+			name:     "Matching default password",
+			policy:   DefaultPasswordPolicy(),
+			password: "abcdefghijklmnopqrstuvwxyz012-_A",
+			want:     true,
+		},
+		{
+			name:     "Wrong length",
+			policy:   DefaultPasswordPolicy(),
+			password: "short",
+			want:     false,
+		},
+		{
+			name:     "Character outside alphanumeric charset",
+			policy:   PasswordPolicy{Length: 12, Charset: CharsetAlphanumeric},
+			password: "abcdefghijk-",
+			want:     false,
+		},
+		{
+			name:     "Missing required symbol",
+			policy:   PasswordPolicy{Length: 12, Charset: CharsetAlphanumeric, RequireSymbols: true},
+			password: "abcdefghijkl",
+			want:     false,
+		},
+		{
+			name:     "Ambiguous character excluded",
+			policy:   PasswordPolicy{Length: 12, Charset: CharsetAlphanumeric, ExcludeAmbiguous: true},
+			password: "abcdefghijk0",
+			want:     false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.policy.Satisfied(tt.password); got != tt.want {
+				t.Errorf("Satisfied(%q) = %v, want %v", tt.password, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPasswordPolicyMerge(t *testing.T) {
+	base := PasswordPolicy{Length: 32, Charset: CharsetBase64URL, ExcludeAmbiguous: true, MinEntropyBits: 64}
+	got := base.Merge(PasswordPolicy{Length: 40, RequireSymbols: true})
+	want := PasswordPolicy{Length: 40, Charset: CharsetBase64URL, RequireSymbols: true, ExcludeAmbiguous: true, MinEntropyBits: 64}
+	if got != want {
+		t.Errorf("Merge() = %+v, want %+v", got, want)
+	}
+}
+
+func TestCheckEntropy(t *testing.T) {
+	tests := []struct {
+		name     string
+		minBits  int
+		password string
+		wantErr  bool
+	}{
+		{
+			name:     "Check disabled",
+			minBits:  0,
+			password: "a",
+		},
+		{
+			name:     "Weak password",
+			minBits:  64,
+			password: "password",
+			wantErr:  true,
+		},
+		{
+			name:     "Strong password",
+			minBits:  64,
+			password: "Xk9#mQ2!vL7@pR4&",
+		},
+		{
+			name:     "Empty password",
+			minBits:  1,
+			password: "",
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := PasswordPolicy{MinEntropyBits: tt.minBits}.CheckEntropy(tt.password)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("CheckEntropy(%q) error = %v, wantErr %v", tt.password, err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,324 @@
+package webhooks
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	natsv1alpha1 "github.com/jradikk/nats-auth-operator/api/v1alpha1"
+)
+
+// DefaultAccessTTL is how long an access review answer is reused
+const DefaultAccessTTL = time.Minute
+
+// SecretAccess reports whether the operator may read the Secrets of a namespace
+type SecretAccess interface {
+	CanReadSecrets(ctx context.Context, namespace string) (bool, error)
+}
+
+// AccessReviewer asks the API server with a SelfSubjectAccessReview whether the operator may get
+// Secrets in a namespace, and reuses the answer for TTL
+type AccessReviewer struct {
+	Client client.Client
+
+	// TTL of a cached answer; DefaultAccessTTL when 0
+	TTL time.Duration
+
+	mu      sync.Mutex
+	answers map[string]cachedAnswer
+
+	// now returns the current time; time.Now when nil
+	now func() time.Time
+}
+
+type cachedAnswer struct {
+	allowed bool
+	expires time.Time
+}
+
+// CanReadSecrets reviews get access to Secrets in the namespace
+func (r *AccessReviewer) CanReadSecrets(ctx context.Context, namespace string) (bool, error) {
+	now := time.Now
+	if r.now != nil {
+		now = r.now
+	}
+	ttl := r.TTL
+	if ttl == 0 {
+		ttl = DefaultAccessTTL
+	}
+
+	r.mu.Lock()
+	answer, ok := r.answers[namespace]
+	r.mu.Unlock()
+	if ok && now().Before(answer.expires) {
+		return answer.allowed, nil
+	}
+
+	review := &authorizationv1.SelfSubjectAccessReview{
+		Spec: authorizationv1.SelfSubjectAccessReviewSpec{
+			ResourceAttributes: &authorizationv1.ResourceAttributes{Namespace: namespace, Verb: "get", Resource: "secrets"},
+		},
+	}
+	if err := r.Client.Create(ctx, review); err != nil {
+		return false, fmt.Errorf("failed to review access to secrets in namespace %s: %w", namespace, err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.answers == nil {
+		r.answers = map[string]cachedAnswer{}
+	}
+	r.answers[namespace] = cachedAnswer{allowed: review.Status.Allowed, expires: now().Add(ttl)}
+	return review.Status.Allowed, nil
+}
+
+// RequesterAccess reports whether the user making an admission request may use a Secret
+type RequesterAccess interface {
+	// CanAccessSecret reviews verb on the Secret key; an empty name reviews the whole namespace
+	CanAccessSecret(ctx context.Context, user authenticationv1.UserInfo, verb string, key types.NamespacedName) (bool, error)
+}
+
+//+kubebuilder:rbac:groups=authorization.k8s.io,resources=subjectaccessreviews,verbs=create
+
+// SubjectAccessReviewer asks the API server with a SubjectAccessReview whether a user may use a Secret
+type SubjectAccessReviewer struct {
+	Client client.Client
+}
+
+// CanAccessSecret implements RequesterAccess
+func (r *SubjectAccessReviewer) CanAccessSecret(ctx context.Context, user authenticationv1.UserInfo, verb string, key types.NamespacedName) (bool, error) {
+	extra := make(map[string]authorizationv1.ExtraValue, len(user.Extra))
+	for k, v := range user.Extra {
+		extra[k] = authorizationv1.ExtraValue(v)
+	}
+	review := &authorizationv1.SubjectAccessReview{
+		Spec: authorizationv1.SubjectAccessReviewSpec{
+			ResourceAttributes: &authorizationv1.ResourceAttributes{Namespace: key.Namespace, Name: key.Name, Verb: verb, Resource: "secrets"},
+			User:               user.Username,
+			Groups:             user.Groups,
+			UID:                user.UID,
+			Extra:              extra,
+		},
+	}
+	if err := r.Client.Create(ctx, review); err != nil {
+		return false, fmt.Errorf("failed to review access of %s to secrets in namespace %s: %w", user.Username, key.Namespace, err)
+	}
+	return review.Status.Allowed, nil
+}
+
+// NatsUserValidator validates NatsUsers like the NatsUser webhook does, and warns about Secrets
+// referenced in other namespaces that the operator cannot read
+type NatsUserValidator struct {
+	// Access reviews whether the operator may read a namespace's Secrets. Nil skips the review,
+	// e.g. when access is granted per Secret by the RBAC manager only after the NatsUser exists.
+	Access SecretAccess
+
+	// Requester reviews whether the user creating or updating the NatsUser may read every Secret
+	// the spec references and write the credentials to another namespace, and denies the request
+	// otherwise. Without it the operator would act on Secrets the user cannot touch. Nil skips
+	// the review, e.g. when the operator may read every Secret anyway.
+	Requester RequesterAccess
+
+	// Namespaces the operator watches; all namespaces when empty. Secrets elsewhere are not in
+	// its cache, so they cannot be read whatever RBAC allows.
+	Namespaces []string
+
+	// AuthConfigs reads the NatsAuthConfig of token users, which are denied under jwt or mixed
+	// mode. Nil skips the check; the NatsUser controller refuses such users either way.
+	AuthConfigs client.Reader
+
+	// Users lists the NatsUsers, to deny a spec.username another NatsUser of the NatsAuthConfig
+	// already claims. Nil skips the check; the NatsUser controller reports the conflict either way.
+	Users client.Reader
+}
+
+var _ admission.CustomValidator = &NatsUserValidator{}
+
+// ValidateCreate implements admission.CustomValidator
+func (v *NatsUserValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	return v.validate(ctx, nil, obj)
+}
+
+// ValidateUpdate implements admission.CustomValidator
+func (v *NatsUserValidator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	return v.validate(ctx, oldObj, newObj)
+}
+
+// ValidateDelete implements admission.CustomValidator
+func (v *NatsUserValidator) ValidateDelete(context.Context, runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+func (v *NatsUserValidator) validate(ctx context.Context, oldObj, obj runtime.Object) (admission.Warnings, error) {
+	user, ok := obj.(*natsv1alpha1.NatsUser)
+	if !ok {
+		return nil, fmt.Errorf("expected a NatsUser, got %T", obj)
+	}
+	if err := user.Spec.Validate(); err != nil {
+		return nil, err
+	}
+	old, _ := oldObj.(*natsv1alpha1.NatsUser)
+	if err := v.checkAuthMode(ctx, old, user); err != nil {
+		return nil, err
+	}
+	if err := v.checkUsername(ctx, old, user); err != nil {
+		return nil, err
+	}
+	if err := v.checkRequesterAccess(ctx, user); err != nil {
+		return nil, err
+	}
+	return v.secretRefWarnings(ctx, user.Namespace, natsUserSecretRefs(user)), nil
+}
+
+// checkAuthMode denies a token user of a NatsAuthConfig in jwt or mixed mode: servers configured
+// with an operator refuse users defined in the config, so its credentials could not connect.
+// Updates that keep the auth type and NatsAuthConfig are allowed, so users left over from a mode
+// migration can still be converted or deleted.
+func (v *NatsUserValidator) checkAuthMode(ctx context.Context, old, user *natsv1alpha1.NatsUser) error {
+	if v.AuthConfigs == nil || user.Spec.AuthType != natsv1alpha1.UserAuthTypeToken {
+		return nil
+	}
+	if old != nil && old.Spec.AuthType == user.Spec.AuthType && old.Spec.AuthConfigRef == user.Spec.AuthConfigRef {
+		return nil
+	}
+	key := user.Spec.AuthConfigRef.ObjectKey(user.Namespace)
+	authConfig := &natsv1alpha1.NatsAuthConfig{}
+	if err := v.AuthConfigs.Get(ctx, key, authConfig); err != nil {
+		if apierrors.IsNotFound(err) {
+			// The NatsUser controller reports the missing reference
+			return nil
+		}
+		return fmt.Errorf("spec.authConfigRef: %w", err)
+	}
+	if mode := authConfig.Spec.Mode; mode == natsv1alpha1.AuthModeJWT || mode == natsv1alpha1.AuthModeMixed {
+		return fmt.Errorf("spec.authType: token users cannot connect to servers configured with an operator, but NatsAuthConfig %s uses %s mode; use authType jwt or inherit", key, mode)
+	}
+	return nil
+}
+
+// checkUsername denies a spec.username that another NatsUser of the same NatsAuthConfig specifies
+// or holds. Updates that keep the username and NatsAuthConfig are allowed, so duplicates created
+// before the check can still be renamed or deleted.
+func (v *NatsUserValidator) checkUsername(ctx context.Context, old, user *natsv1alpha1.NatsUser) error {
+	username := user.Spec.Username
+	if v.Users == nil || username == "" || user.Spec.AuthType == natsv1alpha1.UserAuthTypeJWT {
+		return nil
+	}
+	if old != nil && old.Spec.Username == username && old.Spec.AuthConfigRef == user.Spec.AuthConfigRef {
+		return nil
+	}
+	userList := &natsv1alpha1.NatsUserList{}
+	if err := v.Users.List(ctx, userList, client.UnsafeDisableDeepCopy); err != nil {
+		return fmt.Errorf("spec.username: failed to list NatsUsers: %w", err)
+	}
+	key := user.Spec.AuthConfigRef.ObjectKey(user.Namespace)
+	for i := range userList.Items {
+		other := &userList.Items[i]
+		if other.Namespace == user.Namespace && other.Name == user.Name || !other.DeletionTimestamp.IsZero() ||
+			other.Spec.AuthType == natsv1alpha1.UserAuthTypeJWT || other.Spec.AuthConfigRef.ObjectKey(other.Namespace) != key {
+			continue
+		}
+		if other.Spec.Username == username || other.Status.Username == username {
+			return fmt.Errorf("spec.username: username %q is already used by NatsUser %s/%s", username, other.Namespace, other.Name)
+		}
+	}
+	return nil
+}
+
+// checkRequesterAccess denies a NatsUser whose requester may not get a Secret the spec references,
+// or create Secrets in the namespace the credentials are written to
+func (v *NatsUserValidator) checkRequesterAccess(ctx context.Context, user *natsv1alpha1.NatsUser) error {
+	if v.Requester == nil {
+		return nil
+	}
+	req, err := admission.RequestFromContext(ctx)
+	if err != nil {
+		return fmt.Errorf("cannot review Secret access without an admission request: %w", err)
+	}
+
+	checks := natsUserSecretRefs(user)
+	for i := range checks {
+		checks[i].verb = "get"
+	}
+	if ns := user.Spec.SecretNamespace; ns != "" && ns != user.Namespace {
+		checks = append(checks, secretRef{field: "spec.secretNamespace", key: types.NamespacedName{Namespace: ns}, verb: "create"})
+	}
+	for _, check := range checks {
+		allowed, err := v.Requester.CanAccessSecret(ctx, req.UserInfo, check.verb, check.key)
+		if err != nil {
+			return fmt.Errorf("%s: %w", check.field, err)
+		}
+		if !allowed {
+			target := "Secret " + check.key.String()
+			if check.key.Name == "" {
+				target = "Secrets in namespace " + check.key.Namespace
+			}
+			return fmt.Errorf("%s: %s may not %s %s", check.field, req.UserInfo.Username, check.verb, target)
+		}
+	}
+	return nil
+}
+
+// secretRef is a Secret referenced by a spec field
+type secretRef struct {
+	field string
+	key   types.NamespacedName
+
+	// verb the requester needs on the Secret
+	verb string
+}
+
+// natsUserSecretRefs returns the Secrets the spec of the user references, namespaces defaulted
+func natsUserSecretRefs(user *natsv1alpha1.NatsUser) []secretRef {
+	var refs []secretRef
+	if ref := user.Spec.ExistingSeedSecret; ref != nil {
+		refs = append(refs, secretRef{field: "spec.existingSeedSecret", key: ref.ObjectKey(user.Namespace)})
+	}
+	if ref := user.Spec.ExistingJWTSecret; ref != nil {
+		refs = append(refs, secretRef{field: "spec.existingJWTSecret", key: ref.ObjectKey(user.Namespace)})
+	}
+	if user.Spec.PasswordFrom != nil && user.Spec.PasswordFrom.SecretRef != nil {
+		refs = append(refs, secretRef{field: "spec.passwordFrom.secretRef", key: user.Spec.PasswordFrom.SecretRef.ObjectKey(user.Namespace)})
+	}
+	if user.Spec.Output != nil && user.Spec.Output.TLSSecretRef != nil {
+		refs = append(refs, secretRef{field: "spec.output.tlsSecretRef", key: user.Spec.Output.TLSSecretRef.ObjectKey(user.Namespace)})
+	}
+	return refs
+}
+
+// secretRefWarnings warns about every reference to a Secret outside namespace that the operator
+// cannot read. References within namespace are not reviewed: the operator reads the Secrets of
+// every namespace it reconciles.
+func (v *NatsUserValidator) secretRefWarnings(ctx context.Context, namespace string, refs []secretRef) admission.Warnings {
+	var warnings admission.Warnings
+	for _, ref := range refs {
+		if ref.key.Namespace == namespace {
+			continue
+		}
+		if len(v.Namespaces) > 0 && !slices.Contains(v.Namespaces, ref.key.Namespace) {
+			warnings = append(warnings, fmt.Sprintf("%s: namespace %s is not watched by the operator, so Secret %s cannot be read", ref.field, ref.key.Namespace, ref.key))
+			continue
+		}
+		if v.Access == nil {
+			continue
+		}
+		allowed, err := v.Access.CanReadSecrets(ctx, ref.key.Namespace)
+		switch {
+		case err != nil:
+			warnings = append(warnings, fmt.Sprintf("%s: could not check access to Secret %s: %v", ref.field, ref.key, err))
+		case !allowed:
+			warnings = append(warnings, fmt.Sprintf("%s: the operator is not allowed by RBAC to read Secret %s", ref.field, ref.key))
+		}
+	}
+	return warnings
+}
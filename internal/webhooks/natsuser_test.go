@@ -0,0 +1,305 @@
+package webhooks
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	natsv1alpha1 "github.com/jradikk/nats-auth-operator/api/v1alpha1"
+)
+
+// reviewClient answers SelfSubjectAccessReviews from a map of namespaces the operator may read
+type reviewClient struct {
+	client.Client
+	allowed map[string]bool
+	reviews int
+}
+
+func (c *reviewClient) Create(_ context.Context, obj client.Object, _ ...client.CreateOption) error {
+	c.reviews++
+	review := obj.(*authorizationv1.SelfSubjectAccessReview)
+	review.Status.Allowed = c.allowed[review.Spec.ResourceAttributes.Namespace]
+	return nil
+}
+
+func TestAccessReviewer(t *testing.T) {
+	ctx := context.Background()
+	now := time.Unix(1_700_000_000, 0)
+	c := &reviewClient{allowed: map[string]bool{"shared": true}}
+	r := &AccessReviewer{Client: c, now: func() time.Time { return now }}
+
+	for _, tt := range []struct {
+		namespace   string
+		advance     time.Duration
+		wantAllowed bool
+		wantReviews int
+	}{
+		{namespace: "shared", wantAllowed: true, wantReviews: 1},
+		{namespace: "shared", wantAllowed: true, wantReviews: 1},
+		{namespace: "private", wantAllowed: false, wantReviews: 2},
+		{namespace: "shared", advance: DefaultAccessTTL, wantAllowed: true, wantReviews: 3},
+	} {
+		now = now.Add(tt.advance)
+		allowed, err := r.CanReadSecrets(ctx, tt.namespace)
+		if err != nil {
+			t.Fatalf("CanReadSecrets(%s) error = %v", tt.namespace, err)
+		}
+		if allowed != tt.wantAllowed || c.reviews != tt.wantReviews {
+			t.Errorf("CanReadSecrets(%s) = %v after %d reviews, want %v after %d", tt.namespace, allowed, c.reviews, tt.wantAllowed, tt.wantReviews)
+		}
+	}
+}
+
+// fakeAccess allows reading the Secrets of the listed namespaces
+type fakeAccess map[string]bool
+
+func (a fakeAccess) CanReadSecrets(_ context.Context, namespace string) (bool, error) {
+	return a[namespace], nil
+}
+
+func TestNatsUserValidator(t *testing.T) {
+	tests := []struct {
+		name         string
+		spec         natsv1alpha1.NatsUserSpec
+		namespaces   []string
+		noReview     bool
+		wantWarnings admission.Warnings
+		wantErr      bool
+	}{
+		{
+			name: "Same namespace",
+			spec: natsv1alpha1.NatsUserSpec{ExistingSeedSecret: &natsv1alpha1.SeedSecretRef{Name: "seed"}},
+		},
+		{
+			name: "Readable namespace",
+			spec: natsv1alpha1.NatsUserSpec{ExistingSeedSecret: &natsv1alpha1.SeedSecretRef{Name: "seed", Namespace: "shared"}},
+		},
+		{
+			name: "Namespace denied by RBAC",
+			spec: natsv1alpha1.NatsUserSpec{
+				ExistingJWTSecret: &natsv1alpha1.SecretRef{Name: "jwt", Namespace: "private"},
+				Output:            &natsv1alpha1.CredentialsOutput{TLSSecretRef: &natsv1alpha1.SecretRef{Name: "tls", Namespace: "shared"}},
+			},
+			wantWarnings: admission.Warnings{"spec.existingJWTSecret: the operator is not allowed by RBAC to read Secret private/jwt"},
+		},
+		{
+			name:     "Access not reviewed",
+			spec:     natsv1alpha1.NatsUserSpec{ExistingJWTSecret: &natsv1alpha1.SecretRef{Name: "jwt", Namespace: "private"}},
+			noReview: true,
+		},
+		{
+			name:       "Namespace not watched",
+			spec:       natsv1alpha1.NatsUserSpec{PasswordFrom: &natsv1alpha1.PasswordSource{SecretRef: &natsv1alpha1.PasswordSecretRef{Name: "pw", Namespace: "shared"}}},
+			namespaces: []string{"apps"},
+			wantWarnings: admission.Warnings{
+				"spec.passwordFrom.secretRef: namespace shared is not watched by the operator, so Secret shared/pw cannot be read",
+			},
+		},
+		{
+			name:    "Built-in Secret type",
+			spec:    natsv1alpha1.NatsUserSpec{Output: &natsv1alpha1.CredentialsOutput{SecretType: "kubernetes.io/tls"}},
+			wantErr: true,
+		},
+		{
+			name:    "Clock skew tolerance too large",
+			spec:    natsv1alpha1.NatsUserSpec{ClockSkewTolerance: &metav1.Duration{Duration: 2 * time.Hour}},
+			wantErr: true,
+		},
+		{
+			name:    "Invalid spec",
+			spec:    natsv1alpha1.NatsUserSpec{Expiry: &metav1.Duration{Duration: time.Second}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := &NatsUserValidator{Access: fakeAccess{"shared": true}, Namespaces: tt.namespaces}
+			if tt.noReview {
+				v.Access = nil
+			}
+			user := &natsv1alpha1.NatsUser{ObjectMeta: metav1.ObjectMeta{Namespace: "apps", Name: "orders"}, Spec: tt.spec}
+
+			warnings, err := v.ValidateCreate(context.Background(), user)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ValidateCreate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(warnings, tt.wantWarnings) {
+				t.Errorf("ValidateCreate() warnings = %q, want %q", warnings, tt.wantWarnings)
+			}
+		})
+	}
+}
+
+// fakeRequester allows the listed verb/namespace/name Secret accesses
+type fakeRequester map[string]bool
+
+func (a fakeRequester) CanAccessSecret(_ context.Context, _ authenticationv1.UserInfo, verb string, key types.NamespacedName) (bool, error) {
+	return a[verb+" "+key.String()], nil
+}
+
+func TestNatsUserValidatorRequesterAccess(t *testing.T) {
+	requester := fakeRequester{"get apps/seed": true, "get shared/jwt": true, "create shared/": true}
+	tests := []struct {
+		name    string
+		spec    natsv1alpha1.NatsUserSpec
+		wantErr bool
+	}{
+		{
+			name: "Readable Secrets",
+			spec: natsv1alpha1.NatsUserSpec{
+				ExistingSeedSecret: &natsv1alpha1.SeedSecretRef{Name: "seed"},
+				ExistingJWTSecret:  &natsv1alpha1.SecretRef{Name: "jwt", Namespace: "shared"},
+			},
+		},
+		{
+			name:    "Secret the requester cannot read",
+			spec:    natsv1alpha1.NatsUserSpec{PasswordFrom: &natsv1alpha1.PasswordSource{SecretRef: &natsv1alpha1.PasswordSecretRef{Name: "pw", Namespace: "kube-system"}}},
+			wantErr: true,
+		},
+		{
+			name: "Credentials written to a namespace the requester can write",
+			spec: natsv1alpha1.NatsUserSpec{SecretNamespace: "shared"},
+		},
+		{
+			name:    "Credentials written to a namespace the requester cannot write",
+			spec:    natsv1alpha1.NatsUserSpec{SecretNamespace: "kube-system"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := &NatsUserValidator{Requester: requester}
+			user := &natsv1alpha1.NatsUser{ObjectMeta: metav1.ObjectMeta{Namespace: "apps", Name: "orders"}, Spec: tt.spec}
+			ctx := admission.NewContextWithRequest(context.Background(), admission.Request{})
+
+			if _, err := v.ValidateCreate(ctx, user); (err != nil) != tt.wantErr {
+				t.Fatalf("ValidateCreate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	// Without the admission request the requester cannot be reviewed, so the request is denied
+	v := &NatsUserValidator{Requester: requester}
+	user := &natsv1alpha1.NatsUser{ObjectMeta: metav1.ObjectMeta{Namespace: "apps", Name: "orders"}}
+	if _, err := v.ValidateCreate(context.Background(), user); err == nil {
+		t.Error("ValidateCreate() without an admission request succeeded, want an error")
+	}
+}
+
+func TestNatsUserValidatorAuthMode(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := natsv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	authConfigs := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		&natsv1alpha1.NatsAuthConfig{ObjectMeta: metav1.ObjectMeta{Namespace: "apps", Name: "token"}, Spec: natsv1alpha1.NatsAuthConfigSpec{Mode: natsv1alpha1.AuthModeToken}},
+		&natsv1alpha1.NatsAuthConfig{ObjectMeta: metav1.ObjectMeta{Namespace: "apps", Name: "jwt"}, Spec: natsv1alpha1.NatsAuthConfigSpec{Mode: natsv1alpha1.AuthModeJWT}},
+		&natsv1alpha1.NatsAuthConfig{ObjectMeta: metav1.ObjectMeta{Namespace: "nats", Name: "mixed"}, Spec: natsv1alpha1.NatsAuthConfigSpec{Mode: natsv1alpha1.AuthModeMixed}},
+	).Build()
+	v := &NatsUserValidator{AuthConfigs: authConfigs}
+
+	user := func(authType natsv1alpha1.UserAuthType, ref natsv1alpha1.NatsAuthConfigRef) *natsv1alpha1.NatsUser {
+		return &natsv1alpha1.NatsUser{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "apps", Name: "orders"},
+			Spec:       natsv1alpha1.NatsUserSpec{AuthType: authType, AuthConfigRef: ref},
+		}
+	}
+	tokenRef := natsv1alpha1.NatsAuthConfigRef{Name: "token"}
+	jwtRef := natsv1alpha1.NatsAuthConfigRef{Name: "jwt"}
+	mixedRef := natsv1alpha1.NatsAuthConfigRef{Name: "mixed", Namespace: "nats"}
+
+	tests := []struct {
+		name    string
+		old     *natsv1alpha1.NatsUser
+		user    *natsv1alpha1.NatsUser
+		wantErr bool
+	}{
+		{name: "Token user in token mode", user: user(natsv1alpha1.UserAuthTypeToken, tokenRef)},
+		{name: "Token user in jwt mode", user: user(natsv1alpha1.UserAuthTypeToken, jwtRef), wantErr: true},
+		{name: "Token user in mixed mode", user: user(natsv1alpha1.UserAuthTypeToken, mixedRef), wantErr: true},
+		{name: "Inheriting user in mixed mode", user: user(natsv1alpha1.UserAuthTypeInherit, mixedRef)},
+		{name: "JWT user in mixed mode", user: user(natsv1alpha1.UserAuthTypeJWT, mixedRef)},
+		{name: "Missing NatsAuthConfig", user: user(natsv1alpha1.UserAuthTypeToken, natsv1alpha1.NatsAuthConfigRef{Name: "missing"})},
+		{name: "Token user switched to jwt mode", old: user(natsv1alpha1.UserAuthTypeToken, tokenRef), user: user(natsv1alpha1.UserAuthTypeToken, jwtRef), wantErr: true},
+		{name: "JWT user switched to token auth", old: user(natsv1alpha1.UserAuthTypeJWT, jwtRef), user: user(natsv1alpha1.UserAuthTypeToken, jwtRef), wantErr: true},
+		{name: "Token user left over from a migration", old: user(natsv1alpha1.UserAuthTypeToken, jwtRef), user: user(natsv1alpha1.UserAuthTypeToken, jwtRef)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var err error
+			if tt.old == nil {
+				_, err = v.ValidateCreate(context.Background(), tt.user)
+			} else {
+				_, err = v.ValidateUpdate(context.Background(), tt.old, tt.user)
+			}
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validate error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNatsUserValidatorUsername(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := natsv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	user := func(namespace, name, username string, ref natsv1alpha1.NatsAuthConfigRef) *natsv1alpha1.NatsUser {
+		return &natsv1alpha1.NatsUser{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+			Spec:       natsv1alpha1.NatsUserSpec{AuthType: natsv1alpha1.UserAuthTypeToken, AuthConfigRef: ref, Username: username},
+		}
+	}
+	ref := natsv1alpha1.NatsAuthConfigRef{Name: "auth", Namespace: "nats"}
+	generated := user("billing", "invoices", "", ref)
+	generated.Status.Username = "invoices"
+	users := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		user("apps", "orders", "orders", ref),
+		user("nats", "other", "other", natsv1alpha1.NatsAuthConfigRef{Name: "auth"}),
+		generated,
+	).Build()
+	v := &NatsUserValidator{Users: users}
+
+	tests := []struct {
+		name    string
+		old     *natsv1alpha1.NatsUser
+		user    *natsv1alpha1.NatsUser
+		wantErr bool
+	}{
+		{name: "Unclaimed username", user: user("apps", "payments", "payments", ref)},
+		{name: "Username specified by another user", user: user("billing", "orders", "orders", ref), wantErr: true},
+		{name: "Username held by another user", user: user("apps", "payments", "invoices", ref), wantErr: true},
+		{name: "Username of another NatsAuthConfig", user: user("apps", "payments", "orders", natsv1alpha1.NatsAuthConfigRef{Name: "other"})},
+		{name: "Username in the same NatsAuthConfig by namespace default", user: user("apps", "payments", "other", natsv1alpha1.NatsAuthConfigRef{Name: "auth", Namespace: "nats"}), wantErr: true},
+		{name: "Own username", old: user("apps", "orders", "orders", ref), user: user("apps", "orders", "orders", ref)},
+		{name: "Renamed to a claimed username", old: user("apps", "payments", "payments", ref), user: user("apps", "payments", "orders", ref), wantErr: true},
+		{name: "Duplicate left unchanged", old: user("billing", "orders", "orders", ref), user: user("billing", "orders", "orders", ref)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var err error
+			if tt.old == nil {
+				_, err = v.ValidateCreate(context.Background(), tt.user)
+			} else {
+				_, err = v.ValidateUpdate(context.Background(), tt.old, tt.user)
+			}
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validate error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -17,23 +17,52 @@ limitations under the License.
 package main
 
 import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"flag"
+	"fmt"
+	"net/http"
 	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 
+	"github.com/spf13/afero"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
 
 	natsv1alpha1 "github.com/jradikk/nats-auth-operator/api/v1alpha1"
+	natsv1beta1 "github.com/jradikk/nats-auth-operator/api/v1beta1"
+	"github.com/jradikk/nats-auth-operator/internal/audit"
 	"github.com/jradikk/nats-auth-operator/internal/controller"
+	"github.com/jradikk/nats-auth-operator/internal/csiprovider"
+	"github.com/jradikk/nats-auth-operator/internal/exchange"
+	"github.com/jradikk/nats-auth-operator/internal/health"
+	"github.com/jradikk/nats-auth-operator/internal/hooks"
+	"github.com/jradikk/nats-auth-operator/internal/natsclient"
+	"github.com/jradikk/nats-auth-operator/internal/random"
+	"github.com/jradikk/nats-auth-operator/internal/resolver"
+	"github.com/jradikk/nats-auth-operator/internal/token"
+	"github.com/jradikk/nats-auth-operator/internal/webhooks"
+	natslabels "github.com/jradikk/nats-auth-operator/pkg/labels"
 )
 
 var (
@@ -44,17 +73,126 @@ var (
 func init() {
 	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
 	utilruntime.Must(natsv1alpha1.AddToScheme(scheme))
+	utilruntime.Must(natsv1beta1.AddToScheme(scheme))
 }
 
 func main() {
 	var metricsAddr string
 	var enableLeaderElection bool
 	var probeAddr string
+	var enableWebhooks bool
+	var resyncInterval time.Duration
+	var resyncJitter float64
+	var authConfigDebounce time.Duration
+	var apiTimeout time.Duration
+	var shutdownTimeout time.Duration
+	var lintConfig bool
+	var randomSeed string
+	var passwordPolicy token.PasswordPolicy
+	var settingsName string
+	var auditOpts auditOptions
+	var watchOpts watchOptions
+	var exchangeOpts tokenExchangeOptions
+	var secretAccessFlag string
+	var rbacManagerOpts rbacManagerOptions
+	var resolverDirsFlag string
+	var orphanPolicyFlag string
+	var mode string
+	var resolverAgentOpts resolverAgentOptions
+	var csiProviderOpts csiProviderOptions
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
 	flag.BoolVar(&enableLeaderElection, "leader-elect", false,
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
+	flag.BoolVar(&enableWebhooks, "enable-webhooks", false,
+		"Enable admission webhooks. Requires serving certificates in the webhook server cert directory.")
+	flag.DurationVar(&resyncInterval, "resync-interval", controller.DefaultResyncInterval,
+		"Interval between periodic resyncs of reconciled resources. Set to 0 to rely on watches only.")
+	flag.Float64Var(&resyncJitter, "resync-jitter", 0.1,
+		"Maximum fraction of the resync interval added as random jitter to spread requeues.")
+	flag.DurationVar(&authConfigDebounce, "auth-config-debounce", controller.DefaultAuthConfigDebounce,
+		"Window over which NatsAccount and NatsUser changes are batched into one server auth config write. Set to 0 to write on every change.")
+	flag.DurationVar(&apiTimeout, "api-timeout", controller.DefaultAPITimeout,
+		"Timeout of each call to the Kubernetes API server made while reconciling. Set to 0 to rely on the reconcile context only.")
+	flag.DurationVar(&shutdownTimeout, "shutdown-timeout", controller.DefaultShutdownTimeout,
+		"How long in-flight server auth config writes and account JWT pushes may run once the operator is stopping. "+
+			"NatsAuthConfigs left unwritten are marked ShutdownPending.")
+	flag.BoolVar(&lintConfig, "lint-config", false,
+		"Check rendered server auth configs for syntax errors before writing them, and leave the written config unchanged on errors.")
+	flag.StringVar(&randomSeed, "insecure-deterministic-random-seed", "",
+		"Derive every generated key, password and token from this seed, so test runs are reproducible. "+
+			"Anyone who knows the seed can recreate the credentials: never set it outside of tests.")
+	flag.IntVar(&passwordPolicy.Length, "password-length", token.DefaultPasswordLength,
+		"Default length of generated passwords.")
+	flag.StringVar(&passwordPolicy.Charset, "password-charset", token.CharsetBase64URL,
+		"Default character set of generated passwords: alphanumeric, base64url or ascii.")
+	flag.BoolVar(&passwordPolicy.RequireSymbols, "password-require-symbols", false,
+		"Require at least one symbol in generated passwords.")
+	flag.BoolVar(&passwordPolicy.ExcludeAmbiguous, "password-exclude-ambiguous", false,
+		"Exclude easily confused characters (0, O, 1, l, I) from generated passwords.")
+	flag.IntVar(&passwordPolicy.MinEntropyBits, "password-min-entropy-bits", 0,
+		"Minimum estimated entropy of passwords read from Secrets. Set to 0 to disable the check.")
+	flag.StringVar(&settingsName, "settings-name", controller.DefaultSettingsName,
+		"Name of the cluster-scoped NatsOperatorSettings object holding operator-wide defaults.")
+	flag.StringVar(&auditOpts.sink, "audit-sink", "none",
+		"Sink of the credential issuance audit log: none, stdout, configmap or nats.")
+	flag.StringVar(&auditOpts.configMap, "audit-configmap", "",
+		"ConfigMap keeping the most recent audit events, as namespace/name. Used with --audit-sink=configmap.")
+	flag.IntVar(&auditOpts.configMapSize, "audit-configmap-size", audit.DefaultConfigMapSize,
+		"Number of audit events kept in the audit ConfigMap.")
+	flag.StringVar(&auditOpts.natsURL, "audit-nats-url", "",
+		"NATS server audit events are published to. Used with --audit-sink=nats.")
+	flag.StringVar(&auditOpts.natsSubject, "audit-nats-subject", "nats-auth-operator.audit",
+		"NATS subject audit events are published to.")
+	flag.StringVar(&auditOpts.natsCreds, "audit-nats-creds", "",
+		"Path to a creds file used to publish audit events to NATS.")
+	flag.StringVar(&watchOpts.namespaces, "watch-namespaces", os.Getenv("WATCH_NAMESPACES"),
+		"Comma-separated namespaces to watch (defaults to $WATCH_NAMESPACES). All namespaces are watched when empty.")
+	flag.StringVar(&watchOpts.selector, "watch-namespace-selector", "",
+		"Label selector of namespaces to watch in addition to --watch-namespaces, resolved at startup.")
+	flag.StringVar(&exchangeOpts.bindAddress, "token-exchange-bind-address", "",
+		"Address the ServiceAccount token exchange endpoint binds to, e.g. :8444. Disabled when empty.")
+	flag.StringVar(&exchangeOpts.certDir, "token-exchange-cert-dir", "",
+		"Directory holding tls.crt and tls.key of the token exchange endpoint. Required unless --token-exchange-insecure is set.")
+	flag.BoolVar(&exchangeOpts.insecure, "token-exchange-insecure", false,
+		"Serve the token exchange endpoint over plain HTTP without --token-exchange-cert-dir, e.g. behind a service mesh that terminates TLS. ServiceAccount tokens and NATS credentials then cross the network unencrypted.")
+	flag.StringVar(&exchangeOpts.audiences, "token-exchange-audiences", exchange.DefaultAudience,
+		"Comma-separated audiences exchanged ServiceAccount tokens must be issued for.")
+	flag.DurationVar(&exchangeOpts.maxTTL, "token-exchange-max-ttl", time.Hour,
+		"Maximum lifetime of credentials issued by the token exchange endpoint.")
+	flag.StringVar(&secretAccessFlag, "secret-access", string(controller.SecretAccessCluster),
+		"Access to Secrets the operator has: cluster lists and watches Secrets, restricted only reads and writes the Secrets the RBAC manager grants by name.")
+	flag.StringVar(&rbacManagerOpts.serviceAccount, "rbac-manager-service-account", "",
+		"The ServiceAccount of the operator, as namespace/name, the RBAC manager grants Secrets to.")
+	flag.StringVar(&rbacManagerOpts.crossNamespaceSecrets, "rbac-manager-cross-namespace-secrets", "",
+		"Comma-separated namespaces in which the RBAC manager grants Secrets to resources of other namespaces.")
+	flag.StringVar(&orphanPolicyFlag, "orphan-policy", string(controller.OrphanPolicyReport),
+		"What the scan at startup does with generated Secrets whose owner is gone: report lists them in status.orphans of their NatsAuthConfig, delete deletes them. Seed Secrets and stale preload entries are only reported.")
+	flag.StringVar(&resolverDirsFlag, "resolver-dirs", "",
+		"Comma-separated full-resolver directories mounted into the operator pod; the operator maintains the operator.jwt and account JWT files of NatsAuthConfigs whose jwt.resolverDir is listed.")
+	flag.StringVar(&mode, "mode", "operator",
+		"What to run: operator; rbac-manager, which grants an operator with --secret-access=restricted its Secrets; resolver-agent, the sidecar of the NATS servers materializing the account JWTs of a server auth config Secret or key-value bucket in their full-resolver directory; or csi-provider, the Secrets Store CSI provider mounting credentials exchanged for the ServiceAccount tokens of pods.")
+	flag.StringVar(&resolverAgentOpts.secret, "resolver-agent-secret", "",
+		"The server auth config Secret, as namespace/name, the resolver agent reads the JWTs from.")
+	flag.StringVar(&resolverAgentOpts.dir, "resolver-agent-dir", "",
+		"The full-resolver directory the resolver agent maintains, jwt.resolverDir of the NatsAuthConfig.")
+	flag.StringVar(&resolverAgentOpts.source, "resolver-agent-source", "secret",
+		"Where the resolver agent reads the JWTs from: secret, the server auth config Secret, or kv, a JetStream key-value bucket the operator puts them into.")
+	flag.StringVar(&resolverAgentOpts.natsURL, "resolver-agent-nats-url", "",
+		"The NATS server the resolver agent reads the key-value bucket through.")
+	flag.StringVar(&resolverAgentOpts.natsCreds, "resolver-agent-nats-creds", "",
+		"Path to the creds file the resolver agent connects with.")
+	flag.StringVar(&resolverAgentOpts.natsCA, "resolver-agent-nats-ca", "",
+		"Path to the PEM CA bundle verifying the NATS server; the system roots when empty.")
+	flag.StringVar(&resolverAgentOpts.bucket, "resolver-agent-kv-bucket", "",
+		"The key-value bucket the resolver agent reads the JWTs from.")
+	flag.StringVar(&csiProviderOpts.socket, "csi-provider-socket", csiprovider.DefaultSocket,
+		"The Unix socket the CSI provider serves the Secrets Store CSI driver on.")
+	flag.StringVar(&csiProviderOpts.exchangeURL, "csi-provider-exchange-url", "",
+		"The token exchange endpoint of the operator the CSI provider exchanges ServiceAccount tokens at.")
+	flag.StringVar(&csiProviderOpts.exchangeCA, "csi-provider-exchange-ca", "",
+		"Path to the PEM CA bundle verifying the token exchange endpoint; the system roots when empty.")
 	opts := zap.Options{
 		Development: true,
 	}
@@ -63,8 +201,102 @@ func main() {
 
 	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
 
-	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
+	resync := controller.ResyncConfig{
+		Interval: resyncInterval,
+		Jitter:   resyncJitter,
+	}
+
+	if err := passwordPolicy.Validate(); err != nil {
+		setupLog.Error(err, "invalid password policy")
+		os.Exit(1)
+	}
+
+	var generator random.Generator = random.Default()
+	if randomSeed != "" {
+		setupLog.Info("WARNING: generating deterministic credentials from --insecure-deterministic-random-seed, do not use outside of tests")
+		generator = random.NewDeterministic(randomSeed)
+	}
+
+	secretAccess, err := controller.ParseSecretAccess(secretAccessFlag)
+	if err != nil {
+		setupLog.Error(err, "invalid --secret-access")
+		os.Exit(1)
+	}
+
+	orphanPolicy, err := controller.ParseOrphanPolicy(orphanPolicyFlag)
+	if err != nil {
+		setupLog.Error(err, "invalid --orphan-policy")
+		os.Exit(1)
+	}
+
+	if err := exchangeOpts.validate(); err != nil {
+		setupLog.Error(err, "invalid token exchange configuration")
+		os.Exit(1)
+	}
+
+	switch mode {
+	case "operator", "rbac-manager":
+	case "resolver-agent":
+		if err := resolverAgentOpts.run(ctrl.Options{
+			Scheme: scheme,
+			Metrics: metricsserver.Options{
+				BindAddress: metricsAddr,
+			},
+			HealthProbeBindAddress: probeAddr,
+		}); err != nil {
+			setupLog.Error(err, "problem running resolver agent")
+			os.Exit(1)
+		}
+		return
+	case "csi-provider":
+		if err := csiProviderOpts.run(); err != nil {
+			setupLog.Error(err, "problem running CSI provider")
+			os.Exit(1)
+		}
+		return
+	default:
+		setupLog.Error(fmt.Errorf("unknown mode %q", mode), "invalid --mode")
+		os.Exit(1)
+	}
+
+	cfg := ctrl.GetConfigOrDie()
+	cacheOpts, err := watchOpts.cacheOptions(cfg)
+	if err != nil {
+		setupLog.Error(err, "invalid watch namespace configuration")
+		os.Exit(1)
+	}
+	if len(cacheOpts.DefaultNamespaces) > 0 {
+		setupLog.Info("watching namespaces", "namespaces", sortedNamespaces(cacheOpts.DefaultNamespaces))
+	}
+
+	if mode == "rbac-manager" {
+		if err := rbacManagerOpts.run(cfg, ctrl.Options{
+			Scheme: scheme,
+			Cache:  cacheOpts,
+			Metrics: metricsserver.Options{
+				BindAddress: metricsAddr,
+			},
+			HealthProbeBindAddress: probeAddr,
+			LeaderElection:         enableLeaderElection,
+			LeaderElectionID:       "nats-auth-operator-rbac-manager.jradikk",
+		}, settingsName); err != nil {
+			setupLog.Error(err, "problem running RBAC manager")
+			os.Exit(1)
+		}
+		return
+	}
+
+	// Under restricted access Secrets can only be read by name, so they are not cached
+	var clientOpts client.Options
+	if secretAccess == controller.SecretAccessRestricted {
+		setupLog.Info("restricted Secret access, Secrets are neither listed nor watched")
+		clientOpts.Cache = &client.CacheOptions{DisableFor: []client.Object{&corev1.Secret{}}}
+	}
+
+	mgr, err := ctrl.NewManager(cfg, ctrl.Options{
 		Scheme: scheme,
+		Cache:  cacheOpts,
+		Client: clientOpts,
 		Metrics: metricsserver.Options{
 			BindAddress: metricsAddr,
 		},
@@ -77,30 +309,154 @@ func main() {
 		os.Exit(1)
 	}
 
+	// Bound every API call, so an API server that stops answering cannot stall a worker
+	apiClient := controller.WithTimeout(mgr.GetClient(), apiTimeout)
+
+	settings := &controller.SettingsLoader{
+		Reader: apiClient,
+		Name:   settingsName,
+	}
+
+	auditSink, err := auditOpts.newSink(apiClient)
+	if err != nil {
+		setupLog.Error(err, "invalid audit sink configuration")
+		os.Exit(1)
+	}
+
 	if err = (&controller.NatsAuthConfigReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
+		Client:          apiClient,
+		Scheme:          mgr.GetScheme(),
+		Resync:          resync,
+		PasswordPolicy:  passwordPolicy,
+		Debounce:        authConfigDebounce,
+		Recorder:        mgr.GetEventRecorderFor("natsauthconfig-controller"),
+		Settings:        settings,
+		APIReader:       controller.ReaderWithTimeout(mgr.GetAPIReader(), apiTimeout),
+		LintConfig:      lintConfig,
+		Random:          generator,
+		SecretAccess:    secretAccess,
+		ShutdownTimeout: shutdownTimeout,
+
+		MountedResolverDirs: resolverDirs(resolverDirsFlag),
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "NatsAuthConfig")
 		os.Exit(1)
 	}
 
 	if err = (&controller.NatsAccountReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
+		Client:       apiClient,
+		Scheme:       mgr.GetScheme(),
+		Resync:       resync,
+		Settings:     settings,
+		Audit:        auditSink,
+		Random:       generator,
+		SecretAccess: secretAccess,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "NatsAccount")
 		os.Exit(1)
 	}
 
-	if err = (&controller.NatsUserReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
-	}).SetupWithManager(mgr); err != nil {
+	userReconciler := &controller.NatsUserReconciler{
+		Client:         apiClient,
+		Scheme:         mgr.GetScheme(),
+		Resync:         resync,
+		Recorder:       mgr.GetEventRecorderFor("natsuser-controller"),
+		PasswordPolicy: passwordPolicy,
+		Settings:       settings,
+		Audit:          auditSink,
+		Random:         generator,
+		SecretAccess:   secretAccess,
+	}
+	if err = userReconciler.SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "NatsUser")
 		os.Exit(1)
 	}
 
+	if err = (&controller.NatsCredentialBindingReconciler{
+		Client:       apiClient,
+		Scheme:       mgr.GetScheme(),
+		Resync:       resync,
+		Settings:     settings,
+		SecretAccess: secretAccess,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "NatsCredentialBinding")
+		os.Exit(1)
+	}
+
+	// Seed Secrets are protected through a Secret watch, which restricted access does not allow
+	if secretAccess.WatchesSecrets() {
+		if err = (&controller.SeedSecretReconciler{
+			Client:   apiClient,
+			Scheme:   mgr.GetScheme(),
+			Recorder: mgr.GetEventRecorderFor("seedsecret-controller"),
+		}).SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "SeedSecret")
+			os.Exit(1)
+		}
+	}
+
+	if err = (&controller.NatsAuthBackupReconciler{
+		Client:       apiClient,
+		Scheme:       mgr.GetScheme(),
+		Recorder:     mgr.GetEventRecorderFor("natsauthbackup-controller"),
+		SecretAccess: secretAccess,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "NatsAuthBackup")
+		os.Exit(1)
+	}
+
+	if err = (&controller.NatsIdentityBindingReconciler{
+		Client:   apiClient,
+		Scheme:   mgr.GetScheme(),
+		Recorder: mgr.GetEventRecorderFor("natsidentitybinding-controller"),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "NatsIdentityBinding")
+		os.Exit(1)
+	}
+
+	if err := mgr.Add(&controller.OrphanScanner{
+		Client:       apiClient,
+		Reader:       controller.ReaderWithTimeout(mgr.GetAPIReader(), apiTimeout),
+		Policy:       orphanPolicy,
+		SecretAccess: secretAccess,
+	}); err != nil {
+		setupLog.Error(err, "unable to set up orphan scan")
+		os.Exit(1)
+	}
+
+	if exchangeOpts.bindAddress != "" {
+		if err := mgr.Add(exchangeOpts.server(apiClient, auditSink, generator, userReconciler.ClaimHooks)); err != nil {
+			setupLog.Error(err, "unable to set up token exchange endpoint")
+			os.Exit(1)
+		}
+	}
+
+	if enableWebhooks {
+		validator := &webhooks.NatsUserValidator{
+			Namespaces:  sortedNamespaces(cacheOpts.DefaultNamespaces),
+			AuthConfigs: mgr.GetClient(),
+			Users:       mgr.GetClient(),
+		}
+		if secretAccess.WatchesSecrets() {
+			validator.Access = &webhooks.AccessReviewer{Client: mgr.GetClient()}
+		}
+		if secretAccess == controller.SecretAccessRestricted {
+			validator.Requester = &webhooks.SubjectAccessReviewer{Client: mgr.GetClient()}
+		}
+		if err = (&natsv1alpha1.NatsUser{}).SetupWebhookWithManager(mgr, validator); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "NatsUser")
+			os.Exit(1)
+		}
+		if err = (&natsv1alpha1.NatsAccount{}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "NatsAccount")
+			os.Exit(1)
+		}
+		if err = (&natsv1alpha1.NatsAuthConfig{}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "NatsAuthConfig")
+			os.Exit(1)
+		}
+	}
+
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
 		setupLog.Error(err, "unable to set up health check")
 		os.Exit(1)
@@ -109,6 +465,12 @@ func main() {
 		setupLog.Error(err, "unable to set up ready check")
 		os.Exit(1)
 	}
+	for name, check := range readyzChecks(mgr, enableWebhooks, auditSink, secretAccess, sortedNamespaces(cacheOpts.DefaultNamespaces)) {
+		if err := mgr.AddReadyzCheck(name, check); err != nil {
+			setupLog.Error(err, "unable to set up ready check", "check", name)
+			os.Exit(1)
+		}
+	}
 
 	setupLog.Info("starting manager")
 	if err := mgr.Start(ctrl.SetupSignalHandler()); err != nil {
@@ -116,3 +478,351 @@ func main() {
 		os.Exit(1)
 	}
 }
+
+// readyzChecks returns the readiness checks of the operator's dependencies, so a rollout stops on
+// an operator that runs but cannot work: missing CRDs, webhook serving certificates that are not
+// loaded, unreadable Secrets or an unreachable NATS audit sink. Liveness stays a plain ping, as a
+// restart does not fix a dependency. Under restricted Secret access, which cannot list Secrets,
+// Secrets are not checked.
+func readyzChecks(mgr ctrl.Manager, enableWebhooks bool, auditSink audit.Sink, secretAccess controller.SecretAccess, namespaces []string) map[string]healthz.Checker {
+	// The first watched namespace stands in for the others; all namespaces are listed otherwise
+	var secretNamespace string
+	if len(namespaces) > 0 {
+		secretNamespace = namespaces[0]
+	}
+	checks := map[string]healthz.Checker{
+		"crds": health.CRDsInstalled(mgr.GetRESTMapper(), mgr.GetScheme(), natsv1alpha1.GroupVersion.Group),
+	}
+	if secretAccess.WatchesSecrets() {
+		checks["secrets"] = health.SecretsReadable(mgr.GetAPIReader(), secretNamespace)
+	}
+	if enableWebhooks {
+		checks["webhook"] = mgr.GetWebhookServer().StartedChecker()
+	}
+	if pinger, ok := auditSink.(health.Pinger); ok {
+		checks["audit-nats"] = health.Reachable(pinger)
+	}
+	return checks
+}
+
+// resolverDirs parses --resolver-dirs
+func resolverDirs(value string) []string {
+	var dirs []string
+	for _, dir := range strings.Split(value, ",") {
+		if dir = strings.TrimSpace(dir); dir != "" {
+			dirs = append(dirs, filepath.Clean(dir))
+		}
+	}
+	return dirs
+}
+
+// auditOptions holds the --audit-* flags
+type auditOptions struct {
+	sink          string
+	configMap     string
+	configMapSize int
+	natsURL       string
+	natsSubject   string
+	natsCreds     string
+}
+
+// newSink builds the audit sink selected by the flags; nil disables the audit log
+func (o auditOptions) newSink(c client.Client) (audit.Sink, error) {
+	switch o.sink {
+	case "", "none":
+		return nil, nil
+	case "stdout":
+		return audit.NewJSONSink(os.Stdout), nil
+	case "configmap":
+		namespace, name, ok := strings.Cut(o.configMap, "/")
+		if !ok || namespace == "" || name == "" {
+			return nil, fmt.Errorf("--audit-configmap must be set as namespace/name")
+		}
+		return &audit.ConfigMapSink{
+			Client: c,
+			Key:    client.ObjectKey{Namespace: namespace, Name: name},
+			Size:   o.configMapSize,
+		}, nil
+	case "nats":
+		if o.natsURL == "" || o.natsSubject == "" {
+			return nil, fmt.Errorf("--audit-nats-url and --audit-nats-subject must be set")
+		}
+		sink := &audit.NATSSink{URL: o.natsURL, Subject: o.natsSubject}
+		if o.natsCreds != "" {
+			creds, err := os.ReadFile(o.natsCreds)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read audit NATS creds: %w", err)
+			}
+			sink.Creds = creds
+		}
+		return sink, nil
+	}
+	return nil, fmt.Errorf("unknown audit sink %q", o.sink)
+}
+
+// rbacManagerOptions holds the --rbac-manager-* flags
+type rbacManagerOptions struct {
+	serviceAccount        string
+	crossNamespaceSecrets string
+}
+
+// run runs the RBAC manager in place of the operator: it writes, in every namespace, the Role
+// granting the operator's ServiceAccount the Secrets it needs there
+func (o rbacManagerOptions) run(cfg *rest.Config, opts ctrl.Options, settingsName string) error {
+	namespace, name, ok := strings.Cut(o.serviceAccount, "/")
+	if !ok || namespace == "" || name == "" {
+		return fmt.Errorf("--rbac-manager-service-account must be set as namespace/name")
+	}
+
+	var crossNamespace []string
+	for _, ns := range strings.Split(o.crossNamespaceSecrets, ",") {
+		if ns = strings.TrimSpace(ns); ns != "" {
+			crossNamespace = append(crossNamespace, ns)
+		}
+	}
+
+	// Only the Roles and RoleBindings the RBAC manager writes are cached
+	managed := labels.SelectorFromSet(labels.Set{natslabels.ManagedBy: natslabels.ManagedByValue})
+	opts.Cache.ByObject = map[client.Object]cache.ByObject{
+		&rbacv1.Role{}:        {Label: managed},
+		&rbacv1.RoleBinding{}: {Label: managed},
+	}
+	mgr, err := ctrl.NewManager(cfg, opts)
+	if err != nil {
+		return fmt.Errorf("unable to start manager: %w", err)
+	}
+
+	if err := (&controller.SecretAccessReconciler{
+		Client:         mgr.GetClient(),
+		Settings:       &controller.SettingsLoader{Reader: mgr.GetClient(), Name: settingsName},
+		ServiceAccount: types.NamespacedName{Namespace: namespace, Name: name},
+
+		CrossNamespaceSecrets: crossNamespace,
+	}).SetupWithManager(mgr); err != nil {
+		return fmt.Errorf("unable to create controller SecretAccess: %w", err)
+	}
+	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
+		return fmt.Errorf("unable to set up health check: %w", err)
+	}
+	if err := mgr.AddReadyzCheck("readyz", healthz.Ping); err != nil {
+		return fmt.Errorf("unable to set up ready check: %w", err)
+	}
+
+	setupLog.Info("starting RBAC manager", "serviceAccount", o.serviceAccount)
+	return mgr.Start(ctrl.SetupSignalHandler())
+}
+
+// resolverAgentOptions holds the --resolver-agent-* flags
+type resolverAgentOptions struct {
+	secret    string
+	dir       string
+	source    string
+	natsURL   string
+	natsCreds string
+	natsCA    string
+	bucket    string
+}
+
+// run runs the resolver agent in place of the operator: it keeps the full-resolver directory in
+// sync with the server auth config Secret or the key-value bucket
+func (o resolverAgentOptions) run(opts ctrl.Options) error {
+	if o.dir == "" {
+		return fmt.Errorf("--resolver-agent-dir must be set")
+	}
+	builder := resolver.NewBuilder(afero.NewOsFs(), filepath.Clean(o.dir))
+	switch o.source {
+	case "secret":
+	case "kv":
+		return o.runKV(builder)
+	default:
+		return fmt.Errorf("unknown resolver agent source %q", o.source)
+	}
+
+	namespace, name, ok := strings.Cut(o.secret, "/")
+	if !ok || namespace == "" || name == "" {
+		return fmt.Errorf("--resolver-agent-secret must be set as namespace/name")
+	}
+
+	// Only the Secrets of the namespace of the server auth config are cached
+	opts.Cache.DefaultNamespaces = map[string]cache.Config{namespace: {}}
+	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), opts)
+	if err != nil {
+		return fmt.Errorf("unable to start manager: %w", err)
+	}
+
+	if err := (&controller.ResolverAgentReconciler{
+		Client:  mgr.GetClient(),
+		Secret:  types.NamespacedName{Namespace: namespace, Name: name},
+		Builder: builder,
+	}).SetupWithManager(mgr); err != nil {
+		return fmt.Errorf("unable to create controller ResolverAgent: %w", err)
+	}
+	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
+		return fmt.Errorf("unable to set up health check: %w", err)
+	}
+	if err := mgr.AddReadyzCheck("readyz", healthz.Ping); err != nil {
+		return fmt.Errorf("unable to set up ready check: %w", err)
+	}
+
+	setupLog.Info("starting resolver agent", "secret", o.secret, "dir", o.dir)
+	return mgr.Start(ctrl.SetupSignalHandler())
+}
+
+// runKV runs the resolver agent on the key-value bucket; it needs no Kubernetes API
+func (o resolverAgentOptions) runKV(builder *resolver.Builder) error {
+	if o.natsURL == "" || o.bucket == "" {
+		return fmt.Errorf("--resolver-agent-nats-url and --resolver-agent-kv-bucket must be set")
+	}
+	source := &resolver.KVSource{
+		Options: natsclient.Options{URL: o.natsURL, Name: "nats-auth-operator-resolver-agent"},
+		Bucket:  o.bucket,
+		Builder: builder,
+	}
+	if o.natsCreds != "" {
+		creds, err := os.ReadFile(o.natsCreds)
+		if err != nil {
+			return fmt.Errorf("failed to read resolver agent NATS creds: %w", err)
+		}
+		source.Options.Creds = creds
+	}
+	if o.natsCA != "" {
+		ca, err := os.ReadFile(o.natsCA)
+		if err != nil {
+			return fmt.Errorf("failed to read resolver agent NATS CA bundle: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(ca) {
+			return fmt.Errorf("--resolver-agent-nats-ca holds no PEM certificates")
+		}
+		source.Options.TLSConfig = &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}
+	}
+
+	setupLog.Info("starting resolver agent", "bucket", o.bucket, "dir", o.dir)
+	return source.Run(ctrl.SetupSignalHandler())
+}
+
+// csiProviderOptions holds the --csi-provider-* flags
+type csiProviderOptions struct {
+	socket      string
+	exchangeURL string
+	exchangeCA  string
+}
+
+// run runs the Secrets Store CSI provider in place of the operator; it needs no Kubernetes API
+func (o csiProviderOptions) run() error {
+	if o.exchangeURL == "" {
+		return fmt.Errorf("--csi-provider-exchange-url must be set")
+	}
+	httpClient := &http.Client{Timeout: 30 * time.Second}
+	if o.exchangeCA != "" {
+		ca, err := os.ReadFile(o.exchangeCA)
+		if err != nil {
+			return fmt.Errorf("failed to read token exchange CA bundle: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(ca) {
+			return fmt.Errorf("--csi-provider-exchange-ca holds no PEM certificates")
+		}
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.TLSClientConfig = &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}
+		httpClient.Transport = transport
+	}
+
+	setupLog.Info("starting CSI provider", "socket", o.socket, "exchange", o.exchangeURL)
+	return (&csiprovider.Server{
+		Socket:   o.socket,
+		Provider: &csiprovider.Provider{Exchange: &exchange.Client{URL: o.exchangeURL, HTTPClient: httpClient}},
+	}).Start(ctrl.SetupSignalHandler())
+}
+
+// tokenExchangeOptions holds the --token-exchange-* flags
+type tokenExchangeOptions struct {
+	bindAddress string
+	certDir     string
+	insecure    bool
+	audiences   string
+	maxTTL      time.Duration
+}
+
+// validate refuses to serve tokens and credentials over plain HTTP unless asked to
+func (o tokenExchangeOptions) validate() error {
+	if o.bindAddress != "" && o.certDir == "" && !o.insecure {
+		return fmt.Errorf("--token-exchange-cert-dir must be set, or --token-exchange-insecure to serve plain HTTP")
+	}
+	return nil
+}
+
+// server builds the token exchange endpoint, which verifies tokens with the TokenReview API.
+// Exchanged claims go through claimHooks, the mutator of the NatsUser reconciler, so they match
+// the user JWTs the operator issues.
+func (o tokenExchangeOptions) server(c client.Client, sink audit.Sink, generator random.Generator, claimHooks hooks.Mutator) *exchange.Server {
+	var audiences []string
+	for _, aud := range strings.Split(o.audiences, ",") {
+		if aud = strings.TrimSpace(aud); aud != "" {
+			audiences = append(audiences, aud)
+		}
+	}
+	return &exchange.Server{
+		Addr:     o.bindAddress,
+		CertDir:  o.certDir,
+		Insecure: o.insecure,
+		Handler: &exchange.Handler{
+			Reviewer: &exchange.TokenReviewer{Client: c, Audiences: audiences},
+			Issuer:   &controller.TokenExchangeIssuer{Client: c, Audit: sink, ClaimHooks: claimHooks, Random: generator},
+			MaxTTL:   o.maxTTL,
+		},
+	}
+}
+
+// watchOptions holds the --watch-* flags
+type watchOptions struct {
+	namespaces string
+	selector   string
+}
+
+// cacheOptions restricts the manager cache to the watched namespaces. Namespaces matching the
+// selector are listed once at startup; cluster-scoped objects are always cached.
+func (o watchOptions) cacheOptions(cfg *rest.Config) (cache.Options, error) {
+	namespaces := map[string]cache.Config{}
+	for _, ns := range strings.Split(o.namespaces, ",") {
+		if ns = strings.TrimSpace(ns); ns != "" {
+			namespaces[ns] = cache.Config{}
+		}
+	}
+
+	if o.selector != "" {
+		selector, err := labels.Parse(o.selector)
+		if err != nil {
+			return cache.Options{}, fmt.Errorf("invalid --watch-namespace-selector: %w", err)
+		}
+		c, err := client.New(cfg, client.Options{Scheme: scheme})
+		if err != nil {
+			return cache.Options{}, err
+		}
+		list := &corev1.NamespaceList{}
+		if err := c.List(context.Background(), list, client.MatchingLabelsSelector{Selector: selector}); err != nil {
+			return cache.Options{}, fmt.Errorf("failed to list namespaces matching %q: %w", o.selector, err)
+		}
+		if len(list.Items) == 0 && len(namespaces) == 0 {
+			return cache.Options{}, fmt.Errorf("no namespaces match --watch-namespace-selector %q", o.selector)
+		}
+		for _, ns := range list.Items {
+			namespaces[ns.Name] = cache.Config{}
+		}
+	}
+
+	if len(namespaces) == 0 {
+		return cache.Options{}, nil
+	}
+	return cache.Options{DefaultNamespaces: namespaces}, nil
+}
+
+// sortedNamespaces returns the watched namespace names in order for logging
+func sortedNamespaces(namespaces map[string]cache.Config) []string {
+	names := make([]string, 0, len(namespaces))
+	for ns := range namespaces {
+		names = append(names, ns)
+	}
+	sort.Strings(names)
+	return names
+}
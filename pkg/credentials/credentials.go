@@ -0,0 +1,223 @@
+// Package credentials loads the credentials the NATS auth operator writes for a NatsUser, from
+// Secret data, a mounted Secret directory or the environment, in any of the output formats
+// (creds, split, env, bundle) and for token users. It depends only on the NATS jwt and nkeys
+// packages; the handlers plug straight into nats.go:
+//
+//	creds, err := credentials.FromDir("/etc/nats/creds")
+//	if err != nil { ... }
+//	var opts []nats.Option
+//	if creds.JWT != "" {
+//		opts = append(opts, nats.UserJWT(creds.UserJWTHandler(), creds.SignatureHandler()))
+//	} else {
+//		opts = append(opts, nats.UserInfo(creds.Username, creds.Password))
+//	}
+//	nc, err := nats.Connect(creds.URL, opts...)
+package credentials
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/nats-io/jwt/v2"
+	"github.com/nats-io/nkeys"
+)
+
+// Keys of the credentials Secret, shared with the operator so both sides agree on the layout
+const (
+	CredsKey          = "user.creds"
+	JWTKey            = "user.jwt"
+	SeedKey           = "seed.nk"
+	SplitSeedKey      = "user.seed"
+	URLKey            = "NATS_URL"
+	EnvJWTKey         = "NATS_JWT"
+	EnvSeedKey        = "NATS_NKEY_SEED"
+	BundleKey         = "bundle.json"
+	WellKnownCredsKey = "nats.creds"
+	UsernameKey       = "USERNAME"
+	PasswordKey       = "PASSWORD"
+)
+
+// CredsPathEnv names the environment variable pointing at a mounted creds file
+const CredsPathEnv = "NATS_CREDS_PATH"
+
+// ErrNoCredentials is returned when the data holds neither a user JWT nor a username
+var ErrNoCredentials = errors.New("no NATS credentials found")
+
+// TLS is the PEM material carried in the bundle format
+type TLS struct {
+	CA   string `json:"ca,omitempty"`
+	Cert string `json:"cert,omitempty"`
+	Key  string `json:"key,omitempty"`
+}
+
+// Bundle is the JSON document of the bundle format
+type Bundle struct {
+	URL  string `json:"url"`
+	JWT  string `json:"jwt"`
+	Seed string `json:"seed"`
+	TLS  *TLS   `json:"tls,omitempty"`
+}
+
+// Credentials are the connection details of one NATS user. JWT users have JWT and Seed set,
+// token users Username and Password.
+type Credentials struct {
+	URL      string
+	JWT      string
+	Seed     []byte
+	Username string
+	Password string
+	TLS      *TLS
+}
+
+// FromSecretData loads credentials from the data of a credentials Secret in any format
+func FromSecretData(data map[string][]byte) (*Credentials, error) {
+	if raw, ok := data[BundleKey]; ok {
+		var bundle Bundle
+		if err := json.Unmarshal(raw, &bundle); err != nil {
+			return nil, fmt.Errorf("failed to decode %s: %w", BundleKey, err)
+		}
+		return validate(&Credentials{URL: bundle.URL, JWT: bundle.JWT, Seed: []byte(bundle.Seed), TLS: bundle.TLS})
+	}
+
+	c := &Credentials{
+		URL:      value(data, URLKey),
+		JWT:      value(data, JWTKey, EnvJWTKey),
+		Seed:     []byte(value(data, SeedKey, SplitSeedKey, EnvSeedKey)),
+		Username: value(data, UsernameKey),
+		Password: value(data, PasswordKey),
+	}
+	for _, key := range []string{CredsKey, WellKnownCredsKey} {
+		if creds, ok := data[key]; ok && (c.JWT == "" || len(c.Seed) == 0) {
+			userJWT, seed, err := parseCredsFile(creds)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse %s: %w", key, err)
+			}
+			c.JWT, c.Seed = userJWT, seed
+		}
+	}
+	return validate(c)
+}
+
+// FromDir loads credentials from a directory a credentials Secret is mounted at
+func FromDir(dir string) (*Credentials, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	data := make(map[string][]byte, len(entries))
+	for _, entry := range entries {
+		// Mounted Secrets link every key to a file in a hidden ..data directory
+		if strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		if info, err := os.Stat(path); err != nil || info.IsDir() {
+			continue
+		}
+		if data[entry.Name()], err = os.ReadFile(path); err != nil {
+			return nil, err
+		}
+	}
+	return FromSecretData(data)
+}
+
+// FromEnv loads credentials from the environment: the creds file named by NATS_CREDS_PATH, or
+// the NATS_JWT and NATS_NKEY_SEED variables of the env format. NATS_URL is read in both cases.
+func FromEnv() (*Credentials, error) {
+	data := map[string][]byte{}
+	for _, key := range []string{URLKey, EnvJWTKey, EnvSeedKey} {
+		if v, ok := os.LookupEnv(key); ok {
+			data[key] = []byte(v)
+		}
+	}
+	if path := os.Getenv(CredsPathEnv); path != "" {
+		creds, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		data[CredsKey] = creds
+	}
+	return FromSecretData(data)
+}
+
+// PublicKey returns the public key of the user (JWT users only)
+func (c *Credentials) PublicKey() (string, error) {
+	kp, err := nkeys.FromSeed(c.Seed)
+	if err != nil {
+		return "", err
+	}
+	defer kp.Wipe()
+	return kp.PublicKey()
+}
+
+// UserJWTHandler returns the user JWT; it matches nats.UserJWTHandler
+func (c *Credentials) UserJWTHandler() func() (string, error) {
+	return func() (string, error) {
+		return c.JWT, nil
+	}
+}
+
+// SignatureHandler signs the server nonce with the user seed; it matches nats.SignatureHandler
+func (c *Credentials) SignatureHandler() func([]byte) ([]byte, error) {
+	return func(nonce []byte) ([]byte, error) {
+		kp, err := nkeys.FromSeed(c.Seed)
+		if err != nil {
+			return nil, fmt.Errorf("invalid user seed: %w", err)
+		}
+		defer kp.Wipe()
+		return kp.Sign(nonce)
+	}
+}
+
+// validate checks that the credentials hold a usable JWT and seed pair or a username
+func validate(c *Credentials) (*Credentials, error) {
+	c.Seed = []byte(strings.TrimSpace(string(c.Seed)))
+	switch {
+	case c.JWT != "":
+		claims, err := jwt.DecodeUserClaims(c.JWT)
+		if err != nil {
+			return nil, fmt.Errorf("invalid user JWT: %w", err)
+		}
+		pubKey, err := c.PublicKey()
+		if err != nil {
+			return nil, fmt.Errorf("invalid user seed: %w", err)
+		}
+		if pubKey != claims.Subject {
+			return nil, fmt.Errorf("user seed %s does not match the JWT subject %s", pubKey, claims.Subject)
+		}
+	case c.Username == "":
+		return nil, ErrNoCredentials
+	}
+	return c, nil
+}
+
+// parseCredsFile returns the JWT and seed of a decorated creds file
+func parseCredsFile(creds []byte) (string, []byte, error) {
+	userJWT, err := jwt.ParseDecoratedJWT(creds)
+	if err != nil {
+		return "", nil, err
+	}
+	kp, err := jwt.ParseDecoratedUserNKey(creds)
+	if err != nil {
+		return "", nil, err
+	}
+	seed, err := kp.Seed()
+	if err != nil {
+		return "", nil, err
+	}
+	return userJWT, seed, nil
+}
+
+// value returns the trimmed value of the first of keys present in data
+func value(data map[string][]byte, keys ...string) string {
+	for _, key := range keys {
+		if v, ok := data[key]; ok && len(v) > 0 {
+			return strings.TrimSpace(string(v))
+		}
+	}
+	return ""
+}
@@ -0,0 +1,143 @@
+package credentials_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/nats-io/jwt/v2"
+	"github.com/nats-io/nkeys"
+
+	natsv1alpha1 "github.com/jradikk/nats-auth-operator/api/v1alpha1"
+	jwtpkg "github.com/jradikk/nats-auth-operator/internal/jwt"
+	"github.com/jradikk/nats-auth-operator/pkg/credentials"
+)
+
+// issueUser returns a user JWT signed by a new account and the seed of the user
+func issueUser(t *testing.T) (string, []byte) {
+	t.Helper()
+	account, _ := nkeys.CreateAccount()
+	user, _ := nkeys.CreateUser()
+	pub, _ := user.PublicKey()
+	seed, _ := user.Seed()
+	token, err := jwt.NewUserClaims(pub).Encode(account)
+	if err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+	return token, seed
+}
+
+func TestFromSecretDataFormats(t *testing.T) {
+	userJWT, seed := issueUser(t)
+	const url = "nats://nats:4222"
+
+	for _, format := range []natsv1alpha1.CredentialsFormat{
+		natsv1alpha1.CredentialsFormatCreds,
+		natsv1alpha1.CredentialsFormatSplit,
+		natsv1alpha1.CredentialsFormatEnv,
+		natsv1alpha1.CredentialsFormatBundle,
+	} {
+		t.Run(string(format), func(t *testing.T) {
+			data, err := jwtpkg.RenderCredentials(format, url, userJWT, seed, nil)
+			if err != nil {
+				t.Fatalf("RenderCredentials() error = %v", err)
+			}
+			creds, err := credentials.FromSecretData(data)
+			if err != nil {
+				t.Fatalf("FromSecretData() error = %v", err)
+			}
+			if creds.URL != url || creds.JWT != userJWT || string(creds.Seed) != string(seed) {
+				t.Errorf("FromSecretData() = %+v, want the rendered credentials", creds)
+			}
+
+			got, _ := creds.UserJWTHandler()()
+			sig, err := creds.SignatureHandler()([]byte("nonce"))
+			if err != nil || got != userJWT {
+				t.Fatalf("handlers = %q, %v", got, err)
+			}
+			claims, _ := jwt.DecodeUserClaims(userJWT)
+			verifier, _ := nkeys.FromPublicKey(claims.Subject)
+			if err := verifier.Verify([]byte("nonce"), sig); err != nil {
+				t.Errorf("signature does not verify: %v", err)
+			}
+		})
+	}
+}
+
+func TestFromSecretData(t *testing.T) {
+	userJWT, _ := issueUser(t)
+	_, otherSeed := issueUser(t)
+
+	tests := []struct {
+		name    string
+		data    map[string][]byte
+		wantErr bool
+		wantIs  error
+	}{
+		{
+			name: "Token user",
+			data: map[string][]byte{
+				credentials.UsernameKey: []byte("alice"),
+				credentials.PasswordKey: []byte("secret"),
+				credentials.URLKey:      []byte("nats://nats:4222"),
+			},
+		},
+		{
+			name: "Seed of another user",
+			data: map[string][]byte{
+				credentials.JWTKey:  []byte(userJWT),
+				credentials.SeedKey: otherSeed,
+			},
+			wantErr: true,
+		},
+		{
+			name:    "Nothing to connect with",
+			data:    map[string][]byte{credentials.URLKey: []byte("nats://nats:4222")},
+			wantErr: true,
+			wantIs:  credentials.ErrNoCredentials,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := credentials.FromSecretData(tt.data)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("FromSecretData() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantIs != nil && !errors.Is(err, tt.wantIs) {
+				t.Errorf("FromSecretData() error = %v, want %v", err, tt.wantIs)
+			}
+		})
+	}
+}
+
+func TestFromDir(t *testing.T) {
+	userJWT, seed := issueUser(t)
+	data, err := jwtpkg.RenderCredentials(natsv1alpha1.CredentialsFormatCreds, "nats://nats:4222", userJWT, seed, nil)
+	if err != nil {
+		t.Fatalf("RenderCredentials() error = %v", err)
+	}
+
+	// Lay the directory out like a mounted Secret, with the keys linked into ..data
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, "..data"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for key, value := range data {
+		if err := os.WriteFile(filepath.Join(dir, "..data", key), value, 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Symlink(filepath.Join("..data", key), filepath.Join(dir, key)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	creds, err := credentials.FromDir(dir)
+	if err != nil {
+		t.Fatalf("FromDir() error = %v", err)
+	}
+	if creds.JWT != userJWT {
+		t.Errorf("FromDir() JWT = %q, want %q", creds.JWT, userJWT)
+	}
+}
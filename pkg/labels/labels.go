@@ -0,0 +1,106 @@
+// Package labels defines the well-known labels the NATS auth operator puts on every Secret and
+// ConfigMap it generates, and lists generated objects by them. Bulk queries and cleanup jobs
+// select by these labels rather than by Secret names, which are configurable:
+//
+//	creds, err := labels.ListSecrets(ctx, c, labels.Owners{AuthConfig: "main", Account: "orders"}, client.InNamespace("apps"))
+//
+// The values are resource names. Resources of the same name in other namespaces share them, so
+// narrow a query with client.InNamespace where that matters.
+package labels
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// ManagedBy marks every object generated by the operator, with the value ManagedByValue
+	ManagedBy      = "nats.jradikk/managed-by"
+	ManagedByValue = "nats-auth-operator"
+
+	// AuthConfig names the NatsAuthConfig an object belongs to, directly or through its account or user
+	AuthConfig = "nats.jradikk/auth-config"
+
+	// Account names the NatsAccount an object belongs to, directly or through its user
+	Account = "nats.jradikk/account"
+
+	// User names the NatsUser an object belongs to
+	User = "nats.jradikk/user"
+)
+
+// Owners are the resources a generated object belongs to; empty names are left out
+type Owners struct {
+	AuthConfig string
+	Account    string
+	User       string
+}
+
+// Labels returns the well-known labels of an object generated for the owners
+func (o Owners) Labels() map[string]string {
+	set := map[string]string{ManagedBy: ManagedByValue}
+	for key, name := range map[string]string{AuthConfig: o.AuthConfig, Account: o.Account, User: o.User} {
+		if name != "" {
+			set[key] = name
+		}
+	}
+	return set
+}
+
+// Stamp adds the well-known labels of the owners to obj, keeping its other labels
+func Stamp(obj metav1.Object, owners Owners) {
+	set := obj.GetLabels()
+	if set == nil {
+		set = map[string]string{}
+	}
+	for k, v := range owners.Labels() {
+		set[k] = v
+	}
+	obj.SetLabels(set)
+}
+
+// ListSecrets returns the generated Secrets of the owners; an empty Owners selects every
+// generated Secret
+func ListSecrets(ctx context.Context, c client.Reader, owners Owners, opts ...client.ListOption) ([]corev1.Secret, error) {
+	var found []corev1.Secret
+	err := list(ctx, c, func() client.ObjectList { return &corev1.SecretList{} }, owners, opts, func(l client.ObjectList) {
+		found = append(found, l.(*corev1.SecretList).Items...)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list secrets: %w", err)
+	}
+	return found, nil
+}
+
+// ListConfigMaps returns the generated ConfigMaps of the owners; an empty Owners selects every
+// generated ConfigMap
+func ListConfigMaps(ctx context.Context, c client.Reader, owners Owners, opts ...client.ListOption) ([]corev1.ConfigMap, error) {
+	var found []corev1.ConfigMap
+	err := list(ctx, c, func() client.ObjectList { return &corev1.ConfigMapList{} }, owners, opts, func(l client.ObjectList) {
+		found = append(found, l.(*corev1.ConfigMapList).Items...)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list configmaps: %w", err)
+	}
+	return found, nil
+}
+
+// list pages through the objects matching the labels of the owners, following the continue token
+// when opts hold a client.Limit
+func list(ctx context.Context, c client.Reader, newList func() client.ObjectList, owners Owners, opts []client.ListOption, collect func(client.ObjectList)) error {
+	opts = append([]client.ListOption{client.MatchingLabels(owners.Labels())}, opts...)
+	for {
+		page := newList()
+		if err := c.List(ctx, page, opts...); err != nil {
+			return err
+		}
+		collect(page)
+		if page.GetContinue() == "" {
+			return nil
+		}
+		opts = append(opts, client.Continue(page.GetContinue()))
+	}
+}
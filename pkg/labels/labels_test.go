@@ -0,0 +1,113 @@
+package labels_test
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/jradikk/nats-auth-operator/pkg/labels"
+)
+
+func TestOwnersLabels(t *testing.T) {
+	got := labels.Owners{AuthConfig: "main", User: "orders-app"}.Labels()
+	want := map[string]string{
+		labels.ManagedBy:  labels.ManagedByValue,
+		labels.AuthConfig: "main",
+		labels.User:       "orders-app",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Labels() = %v, want %v", got, want)
+	}
+}
+
+func TestStamp(t *testing.T) {
+	secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"team": "payments", labels.Account: "old"}}}
+	labels.Stamp(secret, labels.Owners{AuthConfig: "main", Account: "orders"})
+
+	want := map[string]string{
+		"team":            "payments",
+		labels.ManagedBy:  labels.ManagedByValue,
+		labels.AuthConfig: "main",
+		labels.Account:    "orders",
+	}
+	if !reflect.DeepEqual(secret.Labels, want) {
+		t.Errorf("labels = %v, want %v", secret.Labels, want)
+	}
+}
+
+func TestList(t *testing.T) {
+	object := func(namespace, name string, owners *labels.Owners) metav1.ObjectMeta {
+		meta := metav1.ObjectMeta{Namespace: namespace, Name: name}
+		if owners != nil {
+			labels.Stamp(&meta, *owners)
+		}
+		return meta
+	}
+	c := fake.NewClientBuilder().WithObjects(
+		&corev1.Secret{ObjectMeta: object("nats", "nats-auth", &labels.Owners{AuthConfig: "main"})},
+		&corev1.Secret{ObjectMeta: object("apps", "orders-account-jwt", &labels.Owners{AuthConfig: "main", Account: "orders"})},
+		&corev1.Secret{ObjectMeta: object("apps", "orders-app-user-creds", &labels.Owners{AuthConfig: "main", Account: "orders", User: "orders-app"})},
+		&corev1.Secret{ObjectMeta: object("other", "orders-account-jwt", &labels.Owners{AuthConfig: "edge", Account: "orders"})},
+		&corev1.Secret{ObjectMeta: object("apps", "unrelated", nil)},
+		&corev1.ConfigMap{ObjectMeta: object("apps", "orders-app-user-creds-mount", &labels.Owners{AuthConfig: "main", Account: "orders", User: "orders-app"})},
+	).Build()
+
+	tests := []struct {
+		name   string
+		owners labels.Owners
+		opts   []client.ListOption
+		want   []string
+	}{
+		{
+			name: "All generated",
+			want: []string{"apps/orders-account-jwt", "apps/orders-app-user-creds", "nats/nats-auth", "other/orders-account-jwt"},
+		},
+		{
+			name:   "Auth config",
+			owners: labels.Owners{AuthConfig: "main"},
+			want:   []string{"apps/orders-account-jwt", "apps/orders-app-user-creds", "nats/nats-auth"},
+		},
+		{
+			name:   "Account in namespace",
+			owners: labels.Owners{Account: "orders"},
+			opts:   []client.ListOption{client.InNamespace("apps")},
+			want:   []string{"apps/orders-account-jwt", "apps/orders-app-user-creds"},
+		},
+		{
+			name:   "User",
+			owners: labels.Owners{User: "orders-app"},
+			want:   []string{"apps/orders-app-user-creds"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			found, err := labels.ListSecrets(context.Background(), c, tt.owners, tt.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, secret := range found {
+				got = append(got, secret.Namespace+"/"+secret.Name)
+			}
+			sort.Strings(got)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ListSecrets() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	configMaps, err := labels.ListConfigMaps(context.Background(), c, labels.Owners{User: "orders-app"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(configMaps) != 1 || configMaps[0].Name != "orders-app-user-creds-mount" {
+		t.Errorf("ListConfigMaps() = %v", configMaps)
+	}
+}